@@ -0,0 +1,146 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/logsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/secrets"
+	"github.com/TimeSnap/distributed-scheduler/internal/resultsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/runner"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/instance"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	devxCfg "github.com/xBlaz3kx/DevX/configuration"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.uber.org/zap"
+)
+
+var allInOneServiceInfo = observability.ServiceInfo{
+	Name:    "scheduler",
+	Version: serviceInfo.Version,
+}
+
+// runnerConfig is the configuration of the runner in the all-in-one mode, with the same keys as the runner service.
+type runnerConfig struct {
+	ID                   string                      `mapstructure:"id" yaml:"id" json:"id,omitempty"`
+	JobExecutionSettings runner.JobExecutionSettings `mapstructure:"jobExecutionSettings" yaml:"jobExecutionSettings" json:"jobExecutionSettings"`
+	Secrets              secrets.Settings            `mapstructure:"secrets" yaml:"secrets" json:"secrets"`
+}
+
+var allInOneCmd = &cobra.Command{
+	Use:   "all-in-one",
+	Short: "Run the manager and a runner in a single process",
+	Long: "Runs the manager API and a runner in a single process sharing the database pool and observability setup, " +
+		"for small deployments and local development.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// the event, wakeup and cancellation listeners each hold one of the connections
+		viper.SetDefault("db.maxOpenConns", 10)
+
+		viper.SetDefault("jobExecutionSettings.maxConcurrentJobs", 100)
+		viper.SetDefault("jobExecutionSettings.maxConcurrentJobsPerType", map[string]int{})
+		viper.SetDefault("jobExecutionSettings.interval", time.Second*10)
+		viper.SetDefault("jobExecutionSettings.maxJobLockTime", time.Minute)
+		viper.SetDefault("jobExecutionSettings.maxDrift", time.Duration(0))
+		viper.SetDefault("jobExecutionSettings.cancellationPollInterval", time.Second*5)
+		viper.SetDefault("jobExecutionSettings.maxExecutionLogSize", 64*1024)
+		viper.SetDefault("jobExecutionSettings.maxExecutionOutputSize", 1024*1024)
+		viper.SetDefault("jobExecutionSettings.namespaces", []string{})
+		viper.SetDefault("jobExecutionSettings.capabilities", []string{})
+		viper.SetDefault("jobExecutionSettings.heartbeatInterval", time.Second*10)
+		viper.SetDefault("jobExecutionSettings.shutdownGracePeriod", time.Second*20)
+		viper.SetDefault("jobExecutionSettings.listenForWakeups", true)
+		viper.SetDefault("jobExecutionSettings.listenForCancellations", true)
+		viper.SetDefault("jobExecutionSettings.fairDistribution", true)
+		viper.SetDefault("jobExecutionSettings.lookahead", time.Duration(0))
+		viper.SetDefault("jobExecutionSettings.maxLabelledJobs", 1000)
+		viper.SetDefault("jobExecutionSettings.egress.enabled", false)
+		viper.SetDefault("jobExecutionSettings.egress.allowHosts", []string{})
+		viper.SetDefault("jobExecutionSettings.egress.denyHosts", []string{})
+		viper.SetDefault("jobExecutionSettings.egress.allowCidrs", []string{})
+		viper.SetDefault("jobExecutionSettings.egress.denyCidrs", []string{})
+		viper.SetDefault("jobExecutionSettings.middleware.chain", []string{executor.MiddlewareRetry})
+		viper.SetDefault("jobExecutionSettings.middleware.maxRetries", 3)
+
+		viper.SetDefault("secrets.vault.address", "")
+		viper.SetDefault("secrets.vault.namespace", "")
+		viper.SetDefault("secrets.vault.cacheTtl", time.Minute*5)
+		viper.SetDefault("secrets.vault.auth.method", secrets.VaultAuthToken)
+		viper.SetDefault("secrets.vault.auth.mount", "")
+		viper.SetDefault("secrets.vault.auth.token", "")
+		viper.SetDefault("secrets.vault.auth.role", "")
+		viper.SetDefault("secrets.vault.auth.jwtPath", "")
+		viper.SetDefault("secrets.vault.auth.roleId", "")
+		viper.SetDefault("secrets.vault.auth.secretId", "")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := &config{}
+		devxCfg.GetConfiguration(viper.GetViper(), cfg)
+
+		runnerCfg := &runnerConfig{}
+		devxCfg.GetConfiguration(viper.GetViper(), runnerCfg)
+
+		// Runners are identified by their ID in job locks and the instance registry
+		if runnerCfg.ID == "" {
+			runnerCfg.ID = uuid.NewString()
+		}
+
+		if err := model.ValidateCapabilities(runnerCfg.JobExecutionSettings.Capabilities); err != nil {
+			otelzap.L().Fatal("Invalid runner capabilities", zap.Error(err))
+		}
+
+		run(allInOneServiceInfo, cfg, runnerCfg)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(allInOneCmd)
+}
+
+// startRunner starts a runner on the storage backend of the manager.
+func startRunner(backend *store.Backend, log *otelzap.Logger, info observability.ServiceInfo, metricsCfg observability.MetricsConfig, cfg *runnerConfig) *runner.Runner {
+	log.Info("Starting the runner", zap.String("id", cfg.ID), zap.Any("config", cfg))
+
+	// Job credentials referring to secrets are resolved before each execution
+	secretResolver, err := secrets.NewResolverFromSettings(cfg.Secrets, &http.Client{Timeout: 10 * time.Second})
+	if err != nil {
+		log.Fatal("Invalid secrets configuration", zap.Error(err))
+	}
+
+	// Ship the execution logs and outputs to the configured sinks, in addition to the database
+	logSink, err := logsink.New(cfg.JobExecutionSettings.LogShipping, &http.Client{Timeout: 30 * time.Second})
+	if err != nil {
+		log.Fatal("Invalid log shipping configuration", zap.Error(err))
+	}
+
+	// HTTP jobs only reach the destinations allowed by the egress policy
+	jobClient, err := egress.NewClient(cfg.JobExecutionSettings.Egress, 30*time.Second)
+	if err != nil {
+		log.Fatal("Invalid egress configuration", zap.Error(err))
+	}
+
+	jobRunner := runner.New(runner.Config{
+		JobService:      job.NewService(backend.Jobs, log),
+		InstanceService: instance.NewService(backend.Instances, log),
+		Metrics:         metrics.NewRunnerMetrics(metricsCfg),
+		Log:             log,
+		ExecutorFactory: executor.NewFactory(jobClient, executor.WithSecretResolver(secretResolver)),
+		LogSink:         logSink,
+		// the results reach the Kafka REST proxies through the egress policy, like HTTP jobs
+		ResultPublisher: resultsink.New(jobClient, resultsink.WithSecretResolver(secretResolver)),
+		InstanceId:      cfg.ID,
+		Version:         info.Version,
+		JobExecution:    cfg.JobExecutionSettings,
+	})
+	jobRunner.Start()
+
+	return jobRunner
+}
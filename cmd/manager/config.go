@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	api "github.com/TimeSnap/distributed-scheduler/internal/api/http"
+	"github.com/TimeSnap/distributed-scheduler/internal/eventbus"
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/configcheck"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/retention"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration of the manager",
+	// the configuration is only read, the encryption isn't set up
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadConfig()
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration of the manager and print it, with the credentials masked",
+	Long: "Loads the configuration like the manager does, checks the settings of its components and prints the " +
+		"effective configuration with the credentials masked. With --online, the connections to the database and " +
+		"the event bus are checked too. Exits with a non-zero status if the configuration is invalid.",
+	Args: cobra.NoArgs,
+	Run:  configValidateRun,
+}
+
+var validateOnline bool
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configValidateCmd.Flags().BoolVar(&validateOnline, "online", false, "check the connections to the database and the event bus")
+}
+
+func configValidateRun(cmd *cobra.Command, args []string) {
+	cfg := &config{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		otelzap.L().Sugar().Fatalf("invalid configuration: %v", err)
+	}
+
+	var kms security.KMSSettings
+	if err := viper.UnmarshalKey("storage.encryption.kms", &kms); err != nil {
+		otelzap.L().Sugar().Fatalf("invalid key provider configuration: %v", err)
+	}
+
+	checks := []configcheck.Check{
+		configcheck.Encryption(viper.GetString("storage.encryption.key"), kms),
+		{Name: "http server", Run: func(context.Context) error {
+			_, err := api.NewServer(cfg.Http, nil, otelzap.L())
+			return err
+		}},
+		{Name: "plugins", Run: func(context.Context) error {
+			return executor.LoadPlugins(cfg.Plugins)
+		}},
+		{Name: "egress policy", Run: func(context.Context) error {
+			_, err := egress.NewClient(cfg.Egress, cfg.Webhooks.Timeout)
+			return err
+		}},
+		{Name: "execution retention archive", Run: func(context.Context) error {
+			_, err := retention.NewArchiver(cfg.Retention.Archive, nil)
+			return err
+		}},
+	}
+
+	if cfg.JobRetention.Enabled {
+		checks = append(checks, configcheck.Check{Name: "job retention", Run: func(context.Context) error {
+			return cfg.JobRetention.Validate()
+		}})
+	}
+
+	if cfg.ZombieReaper.Enabled {
+		checks = append(checks, configcheck.Check{Name: "zombie reaper", Run: func(context.Context) error {
+			return cfg.ZombieReaper.Validate()
+		}})
+	}
+
+	if cfg.Partitions.Enabled {
+		checks = append(checks, configcheck.Check{Name: "execution partitions", Run: func(context.Context) error {
+			return cfg.Partitions.Validate()
+		}})
+	}
+
+	if cfg.KeyRotation.Enabled {
+		checks = append(checks, configcheck.Check{Name: "namespace key rotation", Run: func(context.Context) error {
+			return cfg.KeyRotation.Validate()
+		}})
+	}
+
+	if cfg.Retention.Enabled {
+		checks = append(checks, configcheck.Check{Name: "execution retention", Run: func(context.Context) error {
+			return cfg.Retention.Validate()
+		}})
+	}
+
+	if cfg.Webhooks.Enabled {
+		checks = append(checks, configcheck.Check{Name: "webhooks", Run: func(context.Context) error {
+			return cfg.Webhooks.Validate()
+		}})
+	}
+
+	if cfg.Notifications.Enabled {
+		checks = append(checks, configcheck.Check{Name: "notifications", Run: func(context.Context) error {
+			return cfg.Notifications.Validate()
+		}})
+	}
+
+	checks = append(checks, configcheck.Check{Name: "event outbox", Run: func(context.Context) error {
+		return cfg.Outbox.Validate()
+	}})
+
+	if cfg.MissedRuns.Enabled {
+		checks = append(checks, configcheck.Check{Name: "missed run watchdog", Run: func(context.Context) error {
+			return cfg.MissedRuns.Validate()
+		}})
+	}
+
+	if cfg.SLA.Enabled {
+		checks = append(checks, configcheck.Check{Name: "SLA monitor", Run: func(context.Context) error {
+			return cfg.SLA.Validate()
+		}})
+	}
+
+	// the SQLite database is created and migrated when the manager opens it
+	if viper.GetString("storage.sqlite.path") == "" {
+		checks = append(checks, configcheck.DatabaseSettings(cfg.DB), configcheck.DatabaseConnection(cfg.DB))
+	}
+
+	if cfg.EventBus.Enabled {
+		checks = append(checks, configcheck.Check{Name: "event bus", Online: true, Run: func(context.Context) error {
+			bus, err := eventbus.New(cfg.EventBus, &http.Client{Timeout: cfg.EventBus.Timeout}, otelzap.L())
+			if err != nil {
+				return err
+			}
+
+			return bus.Close()
+		}})
+	}
+
+	// the credentials of the observability library can't be tagged
+	printed := *cfg
+	if printed.Observability.Profiling.AuthToken != "" {
+		printed.Observability.Profiling.AuthToken = redact.Mask
+	}
+
+	if err := configcheck.PrintConfig(os.Stdout, &printed); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to print the configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !configcheck.Report(os.Stderr, configcheck.Run(ctx, checks, validateOnline)) {
+		os.Exit(1)
+	}
+}
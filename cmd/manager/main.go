@@ -2,14 +2,46 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"os/signal"
 	"syscall"
+	"time"
+	// Embed the time zone database, as the container image doesn't ship one
+	_ "time/tzdata"
 
 	api "github.com/TimeSnap/distributed-scheduler/internal/api/http"
+	"github.com/TimeSnap/distributed-scheduler/internal/eventbus"
+	"github.com/TimeSnap/distributed-scheduler/internal/events"
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/notification"
+	"github.com/TimeSnap/distributed-scheduler/internal/outbox"
+	"github.com/TimeSnap/distributed-scheduler/internal/partitioner"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database/dbmigrate"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/debug"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/logger"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/mtls"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/reaper"
+	"github.com/TimeSnap/distributed-scheduler/internal/rekeyer"
+	"github.com/TimeSnap/distributed-scheduler/internal/retention"
+	"github.com/TimeSnap/distributed-scheduler/internal/runner"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	notificationService "github.com/TimeSnap/distributed-scheduler/internal/service/notification"
+	webhookService "github.com/TimeSnap/distributed-scheduler/internal/service/webhook"
+	"github.com/TimeSnap/distributed-scheduler/internal/sla"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
 	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/sqlite"
+	"github.com/TimeSnap/distributed-scheduler/internal/sweeper"
+	"github.com/TimeSnap/distributed-scheduler/internal/watchdog"
+	"github.com/TimeSnap/distributed-scheduler/internal/webhook"
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/jmoiron/sqlx"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
@@ -28,10 +60,41 @@ var serviceInfo = observability.ServiceInfo{
 
 var configFilePath string
 
+// migrationSettings configure the migrations of the database schema.
+type migrationSettings struct {
+	// AutoMigrate applies the pending migrations when the manager starts, instead of running the migrate command of
+	// the tooling CLI before.
+	AutoMigrate bool `mapstructure:"autoMigrate" yaml:"autoMigrate" json:"autoMigrate"`
+}
+
 type config struct {
-	Observability observability.Config   `mapstructure:"observability" yaml:"observability" json:"observability"`
-	Http          devxHttp.Configuration `mapstructure:"http" yaml:"http" json:"http"`
-	DB            database.Config        `mapstructure:"db" yaml:"db" json:"db"`
+	Observability observability.Config             `mapstructure:"observability" yaml:"observability" json:"observability"`
+	Http          api.ServerConfig                 `mapstructure:"http" yaml:"http" json:"http"`
+	DB            database.Config                  `mapstructure:"db" yaml:"db" json:"db"`
+	Migrations    migrationSettings                `mapstructure:"migrations" yaml:"migrations" json:"migrations"`
+	JobRetention  sweeper.Settings                 `mapstructure:"jobRetention" yaml:"jobRetention" json:"jobRetention"`
+	Leader        leader.Settings                  `mapstructure:"leaderElection" yaml:"leaderElection" json:"leaderElection"`
+	ZombieReaper  reaper.Settings                  `mapstructure:"zombieReaper" yaml:"zombieReaper" json:"zombieReaper"`
+	MissedRuns    watchdog.Settings                `mapstructure:"missedRuns" yaml:"missedRuns" json:"missedRuns"`
+	SLA           sla.Settings                     `mapstructure:"sla" yaml:"sla" json:"sla"`
+	Partitions    partitioner.Settings             `mapstructure:"executionPartitions" yaml:"executionPartitions" json:"executionPartitions"`
+	KeyRotation   rekeyer.Settings                 `mapstructure:"namespaceKeyRotation" yaml:"namespaceKeyRotation" json:"namespaceKeyRotation"`
+	Retention     retention.Settings               `mapstructure:"executionRetention" yaml:"executionRetention" json:"executionRetention"`
+	Webhooks      webhook.Settings                 `mapstructure:"webhooks" yaml:"webhooks" json:"webhooks"`
+	Egress        egress.Settings                  `mapstructure:"egress" yaml:"egress" json:"egress"`
+	Notifications notification.Settings            `mapstructure:"notifications" yaml:"notifications" json:"notifications"`
+	AutoDisable   notification.AutoDisableSettings `mapstructure:"autoDisable" yaml:"autoDisable" json:"autoDisable"`
+	Outbox        outbox.Settings                  `mapstructure:"outbox" yaml:"outbox" json:"outbox"`
+	EventBus      eventbus.Settings                `mapstructure:"eventBus" yaml:"eventBus" json:"eventBus"`
+	GraphQL       api.GraphQLConfig                `mapstructure:"graphql" yaml:"graphql" json:"graphql"`
+	Dashboard     api.DashboardConfig              `mapstructure:"dashboard" yaml:"dashboard" json:"dashboard"`
+	Auth          api.AuthConfig                   `mapstructure:"auth" yaml:"auth" json:"auth"`
+	Readiness     api.ReadinessConfig              `mapstructure:"readiness" yaml:"readiness" json:"readiness"`
+	CORS          api.CORSConfig                   `mapstructure:"cors" yaml:"cors" json:"cors"`
+	Security      api.SecurityHeadersConfig        `mapstructure:"securityHeaders" yaml:"securityHeaders" json:"securityHeaders"`
+	Limits        api.LimitsConfig                 `mapstructure:"limits" yaml:"limits" json:"limits"`
+	Plugins       []string                         `mapstructure:"plugins" yaml:"plugins" json:"plugins,omitempty"`
+	Debug         debug.Settings                   `mapstructure:"debug" yaml:"debug" json:"debug"`
 	OpenAPI       struct {
 		Scheme string `conf:"default:http" json:"scheme,omitempty"`
 		Enable bool   `conf:"default:true" json:"enable,omitempty"`
@@ -43,22 +106,164 @@ var rootCmd = &cobra.Command{
 	Use:   "scheduler",
 	Short: "Scheduler manager",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		devxCfg.SetDefaults(serviceName)
-		devxCfg.SetupEnv(serviceName)
-
-		viper.SetDefault("storage.encryption.key", "ishouldreallybechanged")
-		viper.SetDefault("db.disable_tls", true)
-		viper.SetDefault("db.max_open_conns", 1)
-		viper.SetDefault("db.max_idle_conns", 10)
-		viper.SetDefault("observability.logging.level", observability.LogLevelInfo)
+		loadConfig()
 
-		devxCfg.InitConfig("", "./config", ".")
-
-		postgres.SetEncryptor(security.NewEncryptorFromEnv())
+		codec.SetEncryptor(security.NewEncryptorFromEnv())
+		codec.SetPayloadEncryption(viper.GetBool("storage.encryption.httpPayloads"))
 	},
 	Run: runCmd,
 }
 
+// loadConfig sets the defaults of the configuration, and reads it from the config file and the environment.
+func loadConfig() {
+	devxCfg.SetDefaults(serviceName)
+	devxCfg.SetupEnv(serviceName)
+
+	viper.SetDefault("storage.encryption.key", "ishouldreallybechanged")
+	viper.SetDefault("storage.encryption.namespaceKeys", false)
+	viper.SetDefault("storage.encryption.httpPayloads", false)
+	viper.SetDefault("storage.encryption.kms.provider", "")
+	viper.SetDefault("storage.encryption.kms.keyId", "")
+	viper.SetDefault("storage.encryption.kms.dataKeyTtl", time.Hour)
+	viper.SetDefault("storage.encryption.kms.aws.region", "")
+	viper.SetDefault("storage.encryption.kms.aws.endpoint", "")
+	viper.SetDefault("storage.encryption.kms.gcp.endpoint", "")
+	viper.SetDefault("storage.encryption.kms.azure.algorithm", "")
+	viper.SetDefault("storage.sqlite.path", "")
+	viper.SetDefault("db.disable_tls", true)
+	viper.SetDefault("db.max_open_conns", 1)
+	viper.SetDefault("db.max_idle_conns", 10)
+	viper.SetDefault("db.dsn", "")
+	viper.SetDefault("db.sslMode", "")
+	viper.SetDefault("db.sslRootCert", "")
+	viper.SetDefault("db.sslCert", "")
+	viper.SetDefault("db.sslKey", "")
+	viper.SetDefault("db.connMaxLifetime", time.Duration(0))
+	viper.SetDefault("db.connMaxIdleTime", time.Duration(0))
+	viper.SetDefault("db.statementTimeout", time.Duration(0))
+	viper.SetDefault("db.slowQueryThreshold", time.Second)
+	viper.SetDefault("db.queryTimeouts.default", time.Duration(0))
+	viper.SetDefault("migrations.autoMigrate", false)
+	viper.SetDefault("observability.logging.level", observability.LogLevelInfo)
+
+	viper.SetDefault("jobRetention.enabled", true)
+	viper.SetDefault("jobRetention.interval", time.Minute)
+	viper.SetDefault("jobRetention.mode", sweeper.ModeArchive)
+
+	viper.SetDefault("leaderElection.interval", time.Second*10)
+
+	viper.SetDefault("debug.enabled", false)
+	viper.SetDefault("debug.address", "localhost:6060")
+	viper.SetDefault("debug.mutexProfileFraction", 0)
+	viper.SetDefault("debug.blockProfileRate", 0)
+
+	viper.SetDefault("zombieReaper.enabled", true)
+	viper.SetDefault("zombieReaper.interval", time.Second*30)
+	viper.SetDefault("zombieReaper.gracePeriod", time.Second*30)
+
+	viper.SetDefault("missedRuns.enabled", true)
+	viper.SetDefault("missedRuns.interval", time.Minute)
+	viper.SetDefault("missedRuns.threshold", time.Minute*5)
+
+	viper.SetDefault("sla.enabled", true)
+	viper.SetDefault("sla.interval", time.Minute)
+
+	viper.SetDefault("executionPartitions.enabled", true)
+	viper.SetDefault("executionPartitions.interval", time.Hour)
+	viper.SetDefault("executionPartitions.premake", 3)
+	viper.SetDefault("executionPartitions.retention", time.Duration(0))
+
+	viper.SetDefault("namespaceKeyRotation.enabled", true)
+	viper.SetDefault("namespaceKeyRotation.interval", time.Minute)
+
+	viper.SetDefault("executionRetention.enabled", false)
+	viper.SetDefault("executionRetention.interval", time.Minute*10)
+	viper.SetDefault("executionRetention.maxAge", time.Duration(0))
+	viper.SetDefault("executionRetention.keepLast", 0)
+	viper.SetDefault("executionRetention.batchSize", 1000)
+	viper.SetDefault("executionRetention.archive.type", "")
+
+	viper.SetDefault("webhooks.enabled", true)
+	viper.SetDefault("webhooks.interval", time.Second*5)
+	viper.SetDefault("webhooks.maxAttempts", 5)
+	viper.SetDefault("webhooks.timeout", time.Second*10)
+	viper.SetDefault("webhooks.cloudEvents.source", "/scheduler")
+	viper.SetDefault("webhooks.cloudEvents.typePrefix", "")
+
+	viper.SetDefault("egress.enabled", false)
+	viper.SetDefault("egress.allowHosts", []string{})
+	viper.SetDefault("egress.denyHosts", []string{})
+	viper.SetDefault("egress.allowCidrs", []string{})
+	viper.SetDefault("egress.denyCidrs", []string{})
+
+	viper.SetDefault("notifications.enabled", true)
+	viper.SetDefault("notifications.interval", time.Second*5)
+	viper.SetDefault("notifications.maxAttempts", 5)
+	viper.SetDefault("notifications.timeout", time.Second*10)
+	viper.SetDefault("notifications.smtp.host", "")
+	viper.SetDefault("notifications.smtp.port", 25)
+	viper.SetDefault("notifications.smtp.username", "")
+	viper.SetDefault("notifications.smtp.password", "")
+	viper.SetDefault("notifications.smtp.from", "")
+
+	viper.SetDefault("autoDisable.failures", 0)
+
+	viper.SetDefault("outbox.interval", time.Second)
+	viper.SetDefault("outbox.batchSize", 100)
+
+	viper.SetDefault("eventBus.enabled", false)
+	viper.SetDefault("eventBus.type", "")
+	viper.SetDefault("eventBus.source", "/scheduler")
+	viper.SetDefault("eventBus.typePrefix", "")
+	viper.SetDefault("eventBus.timeout", time.Second*10)
+	viper.SetDefault("eventBus.kafka.restProxyUrl", "")
+	viper.SetDefault("eventBus.kafka.topic", "")
+	viper.SetDefault("eventBus.kafka.username", "")
+	viper.SetDefault("eventBus.kafka.password", "")
+	viper.SetDefault("eventBus.nats.url", "")
+	viper.SetDefault("eventBus.nats.subjectPrefix", "scheduler.events")
+	viper.SetDefault("eventBus.nats.credentialsFile", "")
+	viper.SetDefault("eventBus.amqp.url", "")
+	viper.SetDefault("eventBus.amqp.exchange", "")
+
+	viper.SetDefault("graphql.enabled", false)
+
+	viper.SetDefault("dashboard.enabled", false)
+
+	viper.SetDefault("auth.enabled", true)
+
+	viper.SetDefault("readiness.maxSchedulerLag", time.Minute)
+
+	viper.SetDefault("http.tls.enabled", false)
+	viper.SetDefault("http.tls.certPath", "")
+	viper.SetDefault("http.tls.keyPath", "")
+	viper.SetDefault("http.clientAuth.mode", mtls.ClientAuthNone)
+	viper.SetDefault("http.clientAuth.caPath", "")
+	viper.SetDefault("http.clientAuth.crlPath", "")
+	viper.SetDefault("http.clientAuth.ocsp.enabled", false)
+	viper.SetDefault("http.clientAuth.ocsp.failClosed", false)
+	viper.SetDefault("http.clientAuth.ocsp.timeout", 5*time.Second)
+
+	viper.SetDefault("cors.allowedOrigins", []string{})
+	viper.SetDefault("cors.allowedMethods", []string{"GET", "POST", "PUT", "PATCH", "DELETE"})
+	viper.SetDefault("cors.allowedHeaders", []string{"Content-Type", "Authorization", "X-API-Key", "X-Namespace", "If-Match"})
+	viper.SetDefault("cors.exposedHeaders", []string{"ETag"})
+	viper.SetDefault("cors.allowCredentials", false)
+	viper.SetDefault("cors.maxAge", time.Hour)
+
+	viper.SetDefault("securityHeaders.enabled", true)
+	viper.SetDefault("securityHeaders.contentSecurityPolicy", "default-src 'none'; frame-ancestors 'none'")
+	viper.SetDefault("securityHeaders.hstsMaxAge", time.Duration(0))
+
+	viper.SetDefault("limits.maxRequestBodySize", 10<<20)
+	viper.SetDefault("limits.jobs.maxBodySize", model.DefaultPayloadLimits.MaxBodySize)
+	viper.SetDefault("limits.jobs.maxHeadersSize", model.DefaultPayloadLimits.MaxHeadersSize)
+
+	viper.SetDefault("plugins", []string{})
+
+	devxCfg.InitConfig(configFilePath, "./config", ".")
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVar(&configFilePath, "config", "", "config file (default is $HOME/.config/runner.yaml)")
 	_ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
@@ -73,38 +278,95 @@ func main() {
 }
 
 func runCmd(cmd *cobra.Command, args []string) {
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
-	defer cancel()
-
 	// Configuration
 	cfg := &config{}
 	devxCfg.GetConfiguration(viper.GetViper(), cfg)
 
+	run(serviceInfo, cfg, nil)
+}
+
+// run runs the manager until the process is signalled to stop, along with a runner sharing its database pool and
+// observability setup if the runner configuration is given.
+func run(info observability.ServiceInfo, cfg *config, runnerCfg *runnerConfig) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
+	defer cancel()
+
 	// Setup observability
-	obs, err := observability.NewObservability(ctx, serviceInfo, cfg.Observability)
+	obs, err := observability.NewObservability(ctx, info, cfg.Observability)
 	if err != nil {
 		otelzap.L().Fatal("failed to initialize observability", zap.Error(err))
 	}
 
 	log := otelzap.L()
 
+	// Enforce the payload limits when validating jobs
+	model.SetPayloadLimits(cfg.Limits.Jobs)
+
+	// Register the custom job types, so their jobs pass validation
+	if err := executor.LoadPlugins(cfg.Plugins); err != nil {
+		log.Fatal("Unable to load the executor plugins", zap.Error(err))
+	}
+
+	// Plugins can register the custom middlewares of the runner
+	if runnerCfg != nil {
+		if err := runnerCfg.JobExecutionSettings.ValidateMiddleware(); err != nil {
+			log.Fatal("Invalid executor middleware", zap.Error(err))
+		}
+	}
+
 	// App Starting
-	log.Info("Starting the manager", zap.String("version", serviceInfo.Version), zap.Any("config", cfg))
+	log.Info("Starting the manager", zap.String("version", info.Version), zap.Any("config", cfg))
 	defer log.Info("shutdown complete")
 
 	// Database Support
-	log.Info("Connecting to the database", zap.String("host", cfg.DB.Host))
-	db, err := database.Open(database.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		Host:         cfg.DB.Host,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
-	})
-	if err != nil {
-		log.Fatal("failed to connect to the database", zap.Error(err))
+	var (
+		db      *sqlx.DB
+		backend *store.Backend
+	)
+
+	sqlitePath := viper.GetString("storage.sqlite.path")
+	if sqlitePath != "" {
+		log.Info("Opening the SQLite database", zap.String("path", sqlitePath))
+		db, err = sqlite.Open(ctx, sqlitePath)
+		if err != nil {
+			log.Fatal("failed to open the database", zap.Error(err))
+		}
+
+		if viper.GetBool("storage.encryption.namespaceKeys") {
+			log.Warn("Namespace keys aren't supported by the SQLite database, credentials are encrypted with the master key")
+		}
+
+		backend = sqlite.NewBackend(db, log, sqlite.WithQueryTimeouts(cfg.DB.QueryTimeouts))
+	} else {
+		log.Info("Connecting to the database", zap.String("host", cfg.DB.Host))
+		pool, err := database.OpenPool(cfg.DB,
+			database.WithLogger(log),
+			database.WithQueryMetrics(metrics.NewDBQueryMetrics(cfg.Observability.Metrics)),
+		)
+		if err != nil {
+			log.Fatal("failed to connect to the database", zap.Error(err))
+		}
+
+		// the stores use database/sql through the pool, the pool is closed along with the database
+		db = database.NewDB(pool)
+
+		metrics.RegisterDBPoolMetrics(cfg.Observability.Metrics, pool)
+
+		if cfg.Migrations.AutoMigrate {
+			log.Info("Migrating the database")
+
+			migrateCtx, cancelMigrate := context.WithTimeout(ctx, time.Minute)
+			err := dbmigrate.Migrate(migrateCtx, db)
+			cancelMigrate()
+			if err != nil {
+				log.Fatal("failed to migrate the database", zap.Error(err))
+			}
+		}
+
+		backend = postgres.NewBackend(db, log, postgres.WithQueryTimeouts(cfg.DB.QueryTimeouts))
+
+		// Credentials are encrypted with the data key of their namespace if enabled
+		postgres.SetNamespaceKeys(db, log, viper.GetBool("storage.encryption.namespaceKeys"))
 	}
 
 	defer func() {
@@ -112,23 +374,384 @@ func runCmd(cmd *cobra.Command, args []string) {
 		_ = db.Close()
 	}()
 
-	httpServer := devxHttp.NewServer(cfg.Http, obs)
+	// Encrypt the payloads of the HTTP jobs stored in plain text before payload encryption was enabled
+	if viper.GetBool("storage.encryption.httpPayloads") {
+		encryptPayloads := postgres.EncryptPayloads
+		if sqlitePath != "" {
+			encryptPayloads = sqlite.EncryptPayloads
+		}
+
+		go func() {
+			encrypted, err := encryptPayloads(ctx, db, log)
+			if err != nil {
+				log.Error("Failed to encrypt the HTTP job payloads stored in plain text", zap.Error(err))
+				return
+			}
+
+			log.Info("Encrypted the HTTP job payloads stored in plain text", zap.Int64("rows", encrypted))
+		}()
+	}
+
+	// Listen for job lifecycle events published by the manager and the runners
+	eventBroker := events.NewBroker(events.Config{
+		Listener: backend.Jobs,
+		Log:      log,
+	})
+	eventBroker.Start()
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		eventBroker.Stop(ctx)
+	}()
+
+	httpServer := devxHttp.NewServer(cfg.Http.Configuration, obs)
 	api.Api(httpServer.Router(), api.APIMuxConfig{
-		Log: log,
-		DB:  db,
+		Log:   log,
+		DB:    db,
+		Store: backend,
 		OpenApi: api.OpenApiConfig{
 			Enabled: cfg.OpenAPI.Enable,
 			Scheme:  cfg.OpenAPI.Scheme,
 			Host:    cfg.OpenAPI.Host,
 		},
+		Events:    eventBroker,
+		GraphQL:   cfg.GraphQL,
+		Dashboard: cfg.Dashboard,
+		Auth:      cfg.Auth,
+		Readiness: cfg.Readiness,
+		CORS:      cfg.CORS,
+		Security:  cfg.Security,
+		Limits:    cfg.Limits,
+		Metrics:   cfg.Observability.Metrics,
+	})
+
+	// Serve the API, verifying the client certificates if configured
+	apiServer, err := api.NewServer(cfg.Http, httpServer.Router(), log)
+	if err != nil {
+		log.Fatal("invalid HTTP server configuration", zap.Error(err))
+	}
+
+	if err := apiServer.Start(database.NewHealthChecker(db)); err != nil {
+		log.Fatal("failed to start the HTTP server", zap.Error(err))
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		apiServer.Stop(ctx)
+	}()
+
+	// Elect the manager instance performing the periodic maintenance tasks, the single manager of a SQLite
+	// database always performs them
+	var maintenanceLeader leader.Leader = leader.Static(true)
+	if sqlitePath == "" {
+		elector := leader.New(leader.Config{
+			DB:       db,
+			Log:      log,
+			Name:     "maintenance",
+			Settings: cfg.Leader,
+		})
+		elector.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			elector.Stop(ctx)
+		}()
+
+		maintenanceLeader = elector
+	}
+
+	// Serve pprof, expvar and the internal state on a separate listener
+	var debugServer *debug.Server
+	if cfg.Debug.Enabled {
+		debugServer = debug.New(cfg.Debug, log)
+		debugServer.Register("maintenance_leader", func() any {
+			return maintenanceLeader.IsLeader()
+		})
+		debugServer.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			debugServer.Stop(ctx)
+		}()
+	}
+
+	// Sweep completed jobs with an elapsed TTL
+	if cfg.JobRetention.Enabled {
+		jobSweeper, err := sweeper.New(sweeper.Config{
+			JobService: job.NewService(backend.Jobs, log),
+			Leader:     maintenanceLeader,
+			Log:        log,
+			Settings:   cfg.JobRetention,
+		})
+		if err != nil {
+			log.Fatal("Invalid job retention settings", zap.Error(err))
+		}
+		jobSweeper.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			jobSweeper.Stop(ctx)
+		}()
+	}
+
+	// Fail the executions abandoned by crashed runners
+	if cfg.ZombieReaper.Enabled {
+		zombieReaper, err := reaper.New(reaper.Config{
+			JobService: job.NewService(backend.Jobs, log),
+			Leader:     maintenanceLeader,
+			Metrics:    metrics.NewReaperMetrics(cfg.Observability.Metrics),
+			Log:        log,
+			Settings:   cfg.ZombieReaper,
+		})
+		if err != nil {
+			log.Fatal("Invalid zombie reaper settings", zap.Error(err))
+		}
+		zombieReaper.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			zombieReaper.Stop(ctx)
+		}()
+	}
+
+	// Alert on the jobs missing their runs, e.g. because no runner is executing them
+	if cfg.MissedRuns.Enabled {
+		missedRunWatchdog, err := watchdog.New(watchdog.Config{
+			JobService: job.NewService(backend.Jobs, log),
+			Leader:     maintenanceLeader,
+			Metrics:    metrics.NewWatchdogMetrics(cfg.Observability.Metrics),
+			Log:        log,
+			Settings:   cfg.MissedRuns,
+		})
+		if err != nil {
+			log.Fatal("Invalid missed run watchdog settings", zap.Error(err))
+		}
+		missedRunWatchdog.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			missedRunWatchdog.Stop(ctx)
+		}()
+	}
+
+	// Evaluate the SLAs of the jobs and report their breaches
+	if cfg.SLA.Enabled {
+		slaMonitor, err := sla.New(sla.Config{
+			JobService: job.NewService(backend.Jobs, log),
+			Leader:     maintenanceLeader,
+			Metrics:    metrics.NewSLAMetrics(cfg.Observability.Metrics),
+			Log:        log,
+			Settings:   cfg.SLA,
+		})
+		if err != nil {
+			log.Fatal("Invalid SLA monitor settings", zap.Error(err))
+		}
+		slaMonitor.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			slaMonitor.Stop(ctx)
+		}()
+	}
+
+	// Archive and delete the executions expired by the retention policy
+	if cfg.Retention.Enabled {
+		archiver, err := retention.NewArchiver(cfg.Retention.Archive, &http.Client{Timeout: time.Minute})
+		if err != nil {
+			log.Fatal("Invalid execution archive", zap.Error(err))
+		}
+
+		retentionSweeper, err := retention.New(retention.Config{
+			JobService: job.NewService(backend.Jobs, log),
+			Leader:     maintenanceLeader,
+			Archiver:   archiver,
+			Log:        log,
+			Settings:   cfg.Retention,
+		})
+		if err != nil {
+			log.Fatal("Invalid execution retention settings", zap.Error(err))
+		}
+		retentionSweeper.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			retentionSweeper.Stop(ctx)
+		}()
+	}
+
+	// Create the monthly partitions of the executions ahead of time and drop the expired ones
+	if partitions, ok := backend.Jobs.(store.ExecutionPartitioner); ok && cfg.Partitions.Enabled {
+		executionPartitioner, err := partitioner.New(partitioner.Config{
+			Store:    partitions,
+			Leader:   maintenanceLeader,
+			Log:      log,
+			Settings: cfg.Partitions,
+		})
+		if err != nil {
+			log.Fatal("Invalid execution partition settings", zap.Error(err))
+		}
+		executionPartitioner.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			executionPartitioner.Stop(ctx)
+		}()
+	}
+
+	// Encrypt the credentials of the namespaces whose key was rotated again with the new key
+	if rotator, ok := backend.Jobs.(store.NamespaceKeyRotator); ok && cfg.KeyRotation.Enabled {
+		keyRotation, err := rekeyer.New(rekeyer.Config{
+			Store:    rotator,
+			Leader:   maintenanceLeader,
+			Log:      log,
+			Settings: cfg.KeyRotation,
+		})
+		if err != nil {
+			log.Fatal("Invalid namespace key rotation settings", zap.Error(err))
+		}
+		keyRotation.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			keyRotation.Stop(ctx)
+		}()
+	}
+
+	// Deliver job lifecycle events to the registered webhooks
+	var outboxSinks []outbox.Sink
+	if cfg.Webhooks.Enabled {
+		webhookClient, err := egress.NewClient(cfg.Egress, cfg.Webhooks.Timeout)
+		if err != nil {
+			log.Fatal("Invalid egress configuration", zap.Error(err))
+		}
+
+		webhookDispatcher, err := webhook.New(webhook.Config{
+			WebhookService: webhookService.NewService(backend.Webhooks, log),
+			Client:         webhookClient,
+			Log:            log,
+			Settings:       cfg.Webhooks,
+		})
+		if err != nil {
+			log.Fatal("Invalid webhook settings", zap.Error(err))
+		}
+		webhookDispatcher.Start()
+		outboxSinks = append(outboxSinks, webhookDispatcher.Enqueue)
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			webhookDispatcher.Stop(ctx)
+		}()
+	}
+
+	// Stop the jobs failing repeatedly
+	if cfg.AutoDisable.Failures > 0 {
+		outboxSinks = append(outboxSinks, notification.AutoDisable(job.NewService(backend.Jobs, log), cfg.AutoDisable))
+	}
+
+	// Notify of the failures of the jobs according to the notification rules
+	if cfg.Notifications.Enabled {
+		notificationClient, err := egress.NewClient(cfg.Egress, cfg.Notifications.Timeout)
+		if err != nil {
+			log.Fatal("Invalid egress configuration", zap.Error(err))
+		}
+
+		notifier, err := notification.New(notification.Config{
+			NotificationService: notificationService.NewService(backend.Notifications, backend.Jobs, log),
+			Client:              notificationClient,
+			Log:                 log,
+			Settings:            cfg.Notifications,
+		})
+		if err != nil {
+			log.Fatal("Invalid notification settings", zap.Error(err))
+		}
+		notifier.Start()
+		outboxSinks = append(outboxSinks, notifier.Enqueue)
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			notifier.Stop(ctx)
+		}()
+	}
+
+	// Publish the job lifecycle events to the event bus as CloudEvents
+	if cfg.EventBus.Enabled {
+		bus, err := eventbus.New(cfg.EventBus, &http.Client{Timeout: cfg.EventBus.Timeout}, log)
+		if err != nil {
+			log.Fatal("Unable to connect to the event bus", zap.Error(err))
+		}
+		outboxSinks = append(outboxSinks, bus.Publish)
+
+		defer func() {
+			if err := bus.Close(); err != nil {
+				log.Warn("Failed to close the event bus connection", zap.Error(err))
+			}
+		}()
+	}
+
+	// Relay the job lifecycle events written to the outbox to the listeners, the webhooks, the notifier and the event
+	// bus
+	outboxRelay, err := outbox.New(outbox.Config{
+		Store:    backend.Jobs,
+		Sinks:    outboxSinks,
+		Leader:   maintenanceLeader,
+		Log:      log,
+		Settings: cfg.Outbox,
 	})
+	if err != nil {
+		log.Fatal("Invalid event outbox settings", zap.Error(err))
+	}
+	outboxRelay.Start()
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
-	go func() {
-		log.Info("Starting HTTP server", zap.String("host", cfg.Http.Address))
-		databaseCheck := database.NewHealthChecker(db)
-		httpServer.Run(databaseCheck)
+		outboxRelay.Stop(ctx)
 	}()
 
+	// Execute the jobs in the same process in the all-in-one mode
+	if runnerCfg != nil {
+		jobRunner := startRunner(backend, log, info, cfg.Observability.Metrics, runnerCfg)
+		if debugServer != nil {
+			debugServer.Register("runner", func() any {
+				return jobRunner.DebugState()
+			})
+		}
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), runnerCfg.JobExecutionSettings.ShutdownGracePeriod+runner.InterruptTimeout)
+			defer cancel()
+
+			jobRunner.Stop(ctx)
+		}()
+	}
+
 	// Shutdown
 	<-ctx.Done()
 	log.Info("Shutting down the manager")
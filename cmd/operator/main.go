@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/client"
+	"github.com/TimeSnap/distributed-scheduler/internal/operator"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/logger"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/mtls"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	devxCfg "github.com/xBlaz3kx/DevX/configuration"
+	devxHttp "github.com/xBlaz3kx/DevX/http"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.uber.org/zap"
+)
+
+const serviceName = "operator"
+
+var serviceInfo = observability.ServiceInfo{
+	Name:    serviceName,
+	Version: "0.1.2",
+}
+
+var configFilePath string
+
+// apiSettings are the settings of the connection to the management API.
+type apiSettings struct {
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint" json:"endpoint"`
+	APIKey   string `mapstructure:"apiKey" yaml:"apiKey" json:"-"`
+	// Namespace of the jobs, the namespace of the API key if empty
+	Namespace string `mapstructure:"namespace" yaml:"namespace" json:"namespace,omitempty"`
+	// Client certificate, key and CA bundle, for a management API verifying the client certificates
+	CertFile string `mapstructure:"certFile" yaml:"certFile" json:"certFile,omitempty"`
+	KeyFile  string `mapstructure:"keyFile" yaml:"keyFile" json:"keyFile,omitempty"`
+	CAFile   string `mapstructure:"caFile" yaml:"caFile" json:"caFile,omitempty"`
+}
+
+type config struct {
+	Observability  observability.Config   `mapstructure:"observability" yaml:"observability" json:"observability"`
+	Http           devxHttp.Configuration `mapstructure:"http" yaml:"http" json:"http"`
+	API            apiSettings            `mapstructure:"api" yaml:"api" json:"api"`
+	Kubernetes     operator.KubeSettings  `mapstructure:"kubernetes" yaml:"kubernetes" json:"kubernetes"`
+	ResyncInterval time.Duration          `mapstructure:"resyncInterval" yaml:"resyncInterval" json:"resyncInterval"`
+}
+
+var rootCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Scheduler Kubernetes operator, reconciling the ScheduledJob resources into jobs",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadConfig()
+	},
+	Run: runCmd,
+}
+
+// loadConfig sets the defaults of the configuration, and reads it from the config file and the environment.
+func loadConfig() {
+	devxCfg.SetDefaults(serviceName)
+	devxCfg.SetupEnv(serviceName)
+
+	viper.SetDefault("observability.logging.level", observability.LogLevelInfo)
+	viper.SetDefault("http.address", "0.0.0.0:8000")
+
+	viper.SetDefault("api.endpoint", "http://localhost:8000")
+	viper.SetDefault("api.apiKey", "")
+	viper.SetDefault("api.namespace", "")
+	viper.SetDefault("api.certFile", "")
+	viper.SetDefault("api.keyFile", "")
+	viper.SetDefault("api.caFile", "")
+
+	viper.SetDefault("kubernetes.host", "")
+	viper.SetDefault("kubernetes.tokenFile", "")
+	viper.SetDefault("kubernetes.caFile", "")
+	viper.SetDefault("kubernetes.namespace", "")
+
+	viper.SetDefault("resyncInterval", 30*time.Second)
+
+	devxCfg.InitConfig(configFilePath, "./config", ".")
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFilePath, "config", "", "config file (default is $HOME/.config/operator.yaml)")
+	_ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+}
+
+func main() {
+	cobra.OnInitialize(logger.SetupLogging)
+	err := rootCmd.Execute()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func runCmd(cmd *cobra.Command, args []string) {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Read the configuration
+	cfg := &config{}
+	devxCfg.GetConfiguration(viper.GetViper(), cfg)
+
+	obs, err := observability.NewObservability(ctx, serviceInfo, cfg.Observability)
+	if err != nil {
+		otelzap.L().Fatal("failed to initialize observability", zap.Error(err))
+	}
+
+	log := otelzap.L()
+
+	// App Starting
+	log.Info("Starting the operator", zap.String("version", serviceInfo.Version))
+	defer log.Info("shutdown complete")
+
+	log.Info("Using config", zap.Any("config", cfg))
+
+	if cfg.ResyncInterval <= 0 {
+		log.Fatal("The resync interval must be positive", zap.Duration("resyncInterval", cfg.ResyncInterval))
+	}
+
+	kube, err := operator.NewKubeClient(cfg.Kubernetes)
+	if err != nil {
+		log.Fatal("Invalid Kubernetes configuration", zap.Error(err))
+	}
+
+	tlsConfig, err := mtls.ClientConfig(cfg.API.CertFile, cfg.API.KeyFile, cfg.API.CAFile)
+	if err != nil {
+		log.Fatal("Invalid management API TLS configuration", zap.Error(err))
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	apiClient := client.New(client.Config{
+		Endpoint:   cfg.API.Endpoint,
+		APIKey:     cfg.API.APIKey,
+		Namespace:  cfg.API.Namespace,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	})
+
+	httpServer := devxHttp.NewServer(cfg.Http, obs)
+	httpServer.Run()
+
+	// Reconcile the ScheduledJobs until the operator is stopped
+	log.Info("Reconciling the ScheduledJobs", zap.String("namespace", cfg.Kubernetes.Namespace), zap.Duration("resyncInterval", cfg.ResyncInterval))
+	operator.New(kube, apiClient, log).Run(ctx, cfg.ResyncInterval)
+
+	log.Info("Shutting down the operator")
+	httpServer.Shutdown()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/logsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/configcheck"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/secrets"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration of the runner",
+	// the configuration is only read, the encryption isn't set up
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		loadConfig()
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration of the runner and print it, with the credentials masked",
+	Long: "Loads the configuration like the runner does, checks the settings of its components and prints the " +
+		"effective configuration with the credentials masked. With --online, the connection to the database is " +
+		"checked too. Exits with a non-zero status if the configuration is invalid.",
+	Args: cobra.NoArgs,
+	Run:  configValidateRun,
+}
+
+var validateOnline bool
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configValidateCmd.Flags().BoolVar(&validateOnline, "online", false, "check the connection to the database")
+}
+
+func configValidateRun(cmd *cobra.Command, args []string) {
+	cfg := &config{}
+	if err := viper.Unmarshal(cfg); err != nil {
+		otelzap.L().Sugar().Fatalf("invalid configuration: %v", err)
+	}
+
+	var kms security.KMSSettings
+	if err := viper.UnmarshalKey("storage.encryption.kms", &kms); err != nil {
+		otelzap.L().Sugar().Fatalf("invalid key provider configuration: %v", err)
+	}
+
+	checks := []configcheck.Check{
+		configcheck.DatabaseSettings(cfg.DB),
+		configcheck.Encryption(viper.GetString("storage.encryption.key"), kms),
+		{Name: "capabilities", Run: func(context.Context) error {
+			return model.ValidateCapabilities(cfg.JobExecutionSettings.Capabilities)
+		}},
+		// plugins register the custom middlewares, so they are loaded first
+		{Name: "plugins", Run: func(context.Context) error {
+			return executor.LoadPlugins(cfg.Plugins)
+		}},
+		{Name: "executor middleware", Run: func(context.Context) error {
+			return cfg.JobExecutionSettings.ValidateMiddleware()
+		}},
+		{Name: "secrets", Run: func(context.Context) error {
+			_, err := secrets.NewResolverFromSettings(cfg.Secrets, nil)
+			return err
+		}},
+		{Name: "egress policy", Run: func(context.Context) error {
+			_, err := egress.NewClient(cfg.JobExecutionSettings.Egress, 30*time.Second)
+			return err
+		}},
+		{Name: "log shipping", Run: func(context.Context) error {
+			_, err := logsink.New(cfg.JobExecutionSettings.LogShipping, nil)
+			return err
+		}},
+		configcheck.DatabaseConnection(cfg.DB),
+	}
+
+	// the credentials of the observability library can't be tagged
+	printed := *cfg
+	if printed.Observability.Profiling.AuthToken != "" {
+		printed.Observability.Profiling.AuthToken = redact.Mask
+	}
+
+	if err := configcheck.PrintConfig(os.Stdout, &printed); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to print the configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if !configcheck.Report(os.Stderr, configcheck.Run(ctx, checks, validateOnline)) {
+		os.Exit(1)
+	}
+}
@@ -8,13 +8,23 @@ import (
 	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/logsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/debug"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/logger"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/secrets"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/resultsink"
 	"github.com/TimeSnap/distributed-scheduler/internal/runner"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/instance"
 	"github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
 	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
@@ -38,31 +48,99 @@ type config struct {
 	Http                 devxHttp.Configuration      `mapstructure:"http" yaml:"http" json:"http"`
 	DB                   database.Config             `mapstructure:"db" yaml:"db" json:"db"`
 	ID                   string                      `mapstructure:"id" yaml:"id" json:"id,omitempty"`
+	Plugins              []string                    `mapstructure:"plugins" yaml:"plugins" json:"plugins,omitempty"`
 	JobExecutionSettings runner.JobExecutionSettings `mapstructure:"jobExecutionSettings" yaml:"jobExecutionSettings" json:"jobExecutionSettings"`
+	Secrets              secrets.Settings            `mapstructure:"secrets" yaml:"secrets" json:"secrets"`
+	Debug                debug.Settings              `mapstructure:"debug" yaml:"debug" json:"debug"`
 }
 
 var rootCmd = &cobra.Command{
 	Use:   "runner",
 	Short: "Scheduler runner",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		devxCfg.SetDefaults(serviceName)
-		devxCfg.SetupEnv(serviceName)
+		loadConfig()
 
-		viper.SetDefault("storage.encryption.key", "ishouldreallybechanged")
-		viper.SetDefault("db.disableTls", true)
-		viper.SetDefault("db.maxOpenConns", 1)
-		viper.SetDefault("db.maxIdleConns", 10)
-		viper.SetDefault("observability.logging.level", observability.LogLevelInfo)
+		codec.SetEncryptor(security.NewEncryptorFromEnv())
+		codec.SetPayloadEncryption(viper.GetBool("storage.encryption.httpPayloads"))
+	},
+	Run: runCmd,
+}
 
-		viper.SetDefault("jobExecutionSettings.maxConcurrentJobs", 100)
-		viper.SetDefault("jobExecutionSettings.interval", time.Second*10)
-		viper.SetDefault("jobExecutionSettings.maxJobLockTime", time.Minute)
+// loadConfig sets the defaults of the configuration, and reads it from the config file and the environment.
+func loadConfig() {
+	devxCfg.SetDefaults(serviceName)
+	devxCfg.SetupEnv(serviceName)
 
-		devxCfg.InitConfig(configFilePath, "./config", ".")
+	viper.SetDefault("storage.encryption.key", "ishouldreallybechanged")
+	viper.SetDefault("storage.encryption.namespaceKeys", false)
+	viper.SetDefault("storage.encryption.httpPayloads", false)
+	viper.SetDefault("storage.encryption.kms.provider", "")
+	viper.SetDefault("storage.encryption.kms.keyId", "")
+	viper.SetDefault("storage.encryption.kms.dataKeyTtl", time.Hour)
+	viper.SetDefault("storage.encryption.kms.aws.region", "")
+	viper.SetDefault("storage.encryption.kms.aws.endpoint", "")
+	viper.SetDefault("storage.encryption.kms.gcp.endpoint", "")
+	viper.SetDefault("storage.encryption.kms.azure.algorithm", "")
+	viper.SetDefault("db.disableTls", true)
+	// one of the connections is held by the wakeups listener
+	viper.SetDefault("db.maxOpenConns", 2)
+	viper.SetDefault("db.maxIdleConns", 10)
+	viper.SetDefault("db.dsn", "")
+	viper.SetDefault("db.sslMode", "")
+	viper.SetDefault("db.sslRootCert", "")
+	viper.SetDefault("db.sslCert", "")
+	viper.SetDefault("db.sslKey", "")
+	viper.SetDefault("db.connMaxLifetime", time.Duration(0))
+	viper.SetDefault("db.connMaxIdleTime", time.Duration(0))
+	viper.SetDefault("db.statementTimeout", time.Duration(0))
+	viper.SetDefault("db.slowQueryThreshold", time.Second)
+	viper.SetDefault("db.queryTimeouts.default", time.Duration(0))
+	viper.SetDefault("observability.logging.level", observability.LogLevelInfo)
 
-		postgres.SetEncryptor(security.NewEncryptorFromEnv())
-	},
-	Run: runCmd,
+	viper.SetDefault("plugins", []string{})
+
+	viper.SetDefault("jobExecutionSettings.maxConcurrentJobs", 100)
+	viper.SetDefault("jobExecutionSettings.maxConcurrentJobsPerType", map[string]int{})
+	viper.SetDefault("jobExecutionSettings.interval", time.Second*10)
+	viper.SetDefault("jobExecutionSettings.maxJobLockTime", time.Minute)
+	viper.SetDefault("jobExecutionSettings.maxDrift", time.Duration(0))
+	viper.SetDefault("jobExecutionSettings.cancellationPollInterval", time.Second*5)
+	viper.SetDefault("jobExecutionSettings.maxExecutionLogSize", 64*1024)
+	viper.SetDefault("jobExecutionSettings.maxExecutionOutputSize", 1024*1024)
+	viper.SetDefault("jobExecutionSettings.namespaces", []string{})
+	viper.SetDefault("jobExecutionSettings.capabilities", []string{})
+	viper.SetDefault("jobExecutionSettings.heartbeatInterval", time.Second*10)
+	viper.SetDefault("jobExecutionSettings.shutdownGracePeriod", time.Second*20)
+	viper.SetDefault("jobExecutionSettings.listenForWakeups", true)
+	viper.SetDefault("jobExecutionSettings.listenForCancellations", true)
+	viper.SetDefault("jobExecutionSettings.fairDistribution", true)
+	viper.SetDefault("jobExecutionSettings.lookahead", time.Duration(0))
+	viper.SetDefault("jobExecutionSettings.maxLabelledJobs", 1000)
+	viper.SetDefault("jobExecutionSettings.egress.enabled", false)
+	viper.SetDefault("jobExecutionSettings.egress.allowHosts", []string{})
+	viper.SetDefault("jobExecutionSettings.egress.denyHosts", []string{})
+	viper.SetDefault("jobExecutionSettings.egress.allowCidrs", []string{})
+	viper.SetDefault("jobExecutionSettings.egress.denyCidrs", []string{})
+	viper.SetDefault("jobExecutionSettings.middleware.chain", []string{executor.MiddlewareRetry})
+	viper.SetDefault("jobExecutionSettings.middleware.maxRetries", 3)
+
+	viper.SetDefault("debug.enabled", false)
+	viper.SetDefault("debug.address", "localhost:6060")
+	viper.SetDefault("debug.mutexProfileFraction", 0)
+	viper.SetDefault("debug.blockProfileRate", 0)
+
+	viper.SetDefault("secrets.vault.address", "")
+	viper.SetDefault("secrets.vault.namespace", "")
+	viper.SetDefault("secrets.vault.cacheTtl", time.Minute*5)
+	viper.SetDefault("secrets.vault.auth.method", secrets.VaultAuthToken)
+	viper.SetDefault("secrets.vault.auth.mount", "")
+	viper.SetDefault("secrets.vault.auth.token", "")
+	viper.SetDefault("secrets.vault.auth.role", "")
+	viper.SetDefault("secrets.vault.auth.jwtPath", "")
+	viper.SetDefault("secrets.vault.auth.roleId", "")
+	viper.SetDefault("secrets.vault.auth.secretId", "")
+
+	devxCfg.InitConfig(configFilePath, "./config", ".")
 }
 
 func init() {
@@ -86,6 +164,11 @@ func runCmd(cmd *cobra.Command, args []string) {
 	cfg := &config{}
 	devxCfg.GetConfiguration(viper.GetViper(), cfg)
 
+	// Runners are identified by their ID in job locks and the instance registry
+	if cfg.ID == "" {
+		cfg.ID = uuid.NewString()
+	}
+
 	obs, err := observability.NewObservability(ctx, serviceInfo, cfg.Observability)
 	if err != nil {
 		otelzap.L().Fatal("failed to initialize observability", zap.Error(err))
@@ -99,44 +182,112 @@ func runCmd(cmd *cobra.Command, args []string) {
 
 	log.Info("Using config", zap.Any("config", cfg))
 
+	if err := model.ValidateCapabilities(cfg.JobExecutionSettings.Capabilities); err != nil {
+		log.Fatal("Invalid runner capabilities", zap.Error(err))
+	}
+
+	// Register the custom job types, executors and middlewares
+	if err := executor.LoadPlugins(cfg.Plugins); err != nil {
+		log.Fatal("Unable to load the executor plugins", zap.Error(err))
+	}
+
+	if err := cfg.JobExecutionSettings.ValidateMiddleware(); err != nil {
+		log.Fatal("Invalid executor middleware", zap.Error(err))
+	}
+
 	// Database
 	log.Info("Connecting to the database", zap.String("host", cfg.DB.Host))
-	db, err := database.Open(database.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		Host:         cfg.DB.Host,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
-	})
+	pool, err := database.OpenPool(cfg.DB,
+		database.WithLogger(log),
+		database.WithQueryMetrics(metrics.NewDBQueryMetrics(cfg.Observability.Metrics)),
+	)
 	if err != nil {
 		log.Fatal("Unable to establish DB connection", zap.Error(err))
 	}
 
+	// the stores use database/sql through the pool, the pool is closed along with the database
+	db := database.NewDB(pool)
 	defer func() {
 		log.Info("Closing the database connection")
 		_ = db.Close()
 	}()
 
+	metrics.RegisterDBPoolMetrics(cfg.Observability.Metrics, pool)
+
 	// Start Runner Service
 	log.Info("Starting runner service")
 
-	store := postgres.New(db, log)
+	backend := postgres.NewBackend(db, log, postgres.WithQueryTimeouts(cfg.DB.QueryTimeouts))
+
+	// Credentials are encrypted with the data key of their namespace if enabled
+	postgres.SetNamespaceKeys(db, log, viper.GetBool("storage.encryption.namespaceKeys"))
+
+	jobService := job.NewService(backend.Jobs, log)
+
+	// Job credentials referring to secrets are resolved before each execution
+	secretResolver, err := secrets.NewResolverFromSettings(cfg.Secrets, &http.Client{Timeout: 10 * time.Second})
+	if err != nil {
+		log.Fatal("Invalid secrets configuration", zap.Error(err))
+	}
+
+	// HTTP jobs only reach the destinations allowed by the egress policy
+	jobClient, err := egress.NewClient(cfg.JobExecutionSettings.Egress, 30*time.Second)
+	if err != nil {
+		log.Fatal("Invalid egress configuration", zap.Error(err))
+	}
 
-	jobService := job.NewService(store, log)
+	executorFactory := executor.NewFactory(jobClient, executor.WithSecretResolver(secretResolver))
 
-	executorFactory := executor.NewFactory(&http.Client{Timeout: 30 * time.Second})
+	// Ship the execution logs and outputs to the configured sinks, in addition to the database
+	logSink, err := logsink.New(cfg.JobExecutionSettings.LogShipping, &http.Client{Timeout: 30 * time.Second})
+	if err != nil {
+		log.Fatal("Invalid log shipping configuration", zap.Error(err))
+	}
 
-	runner := runner.New(runner.Config{
+	jobRunner := runner.New(runner.Config{
 		JobService:      jobService,
+		InstanceService: instance.NewService(backend.Instances, log),
 		Metrics:         metrics.NewRunnerMetrics(cfg.Observability.Metrics),
 		Log:             log,
 		ExecutorFactory: executorFactory,
+		LogSink:         logSink,
+		// the results reach the Kafka REST proxies through the egress policy, like HTTP jobs
+		ResultPublisher: resultsink.New(jobClient, resultsink.WithSecretResolver(secretResolver)),
 		InstanceId:      cfg.ID,
+		Version:         serviceInfo.Version,
 		JobExecution:    cfg.JobExecutionSettings,
 	})
-	runner.Start()
+	jobRunner.Start()
+
+	// Serve pprof, expvar and the internal state of the runner on a separate listener
+	if cfg.Debug.Enabled {
+		debugServer := debug.New(cfg.Debug, log)
+		debugServer.Register("runner", func() any {
+			return jobRunner.DebugState()
+		})
+		debugServer.Start()
+
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			debugServer.Stop(ctx)
+		}()
+	}
+
+	// Apply the changes of the poll interval and concurrency limits in the config file without restarting
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(_ fsnotify.Event) {
+			reloaded := &config{}
+			if err := viper.Unmarshal(reloaded); err != nil {
+				log.Error("Failed to reload the configuration", zap.Error(err))
+				return
+			}
+
+			jobRunner.Reconfigure(reloaded.JobExecutionSettings)
+		})
+		viper.WatchConfig()
+	}
 
 	httpServer := devxHttp.NewServer(cfg.Http, obs)
 	databaseCheck := database.NewHealthChecker(db)
@@ -148,9 +299,11 @@ func runCmd(cmd *cobra.Command, args []string) {
 
 	httpServer.Shutdown()
 
-	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
+	// Drain the running executions for the grace period before interrupting them. The termination grace period of
+	// the pod (terminationGracePeriodSeconds in Kubernetes) must cover both.
+	ctx, cancel = context.WithTimeout(context.Background(), cfg.JobExecutionSettings.ShutdownGracePeriod+runner.InterruptTimeout)
 	defer cancel()
 
 	// stop the runner
-	runner.Stop(ctx)
+	jobRunner.Stop(ctx)
 }
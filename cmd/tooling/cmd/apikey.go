@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/apikey"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"gopkg.in/guregu/null.v4"
+)
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys of the management API.",
+}
+
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key. The key is printed once and cannot be retrieved again.",
+	Run:   apiKeyCreateRun,
+}
+
+var apiKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys.",
+	Run:   apiKeyListRun,
+}
+
+var apiKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke an API key.",
+	Args:  cobra.ExactArgs(1),
+	Run:   apiKeyRevokeRun,
+}
+
+var (
+	apiKeyCreate model.APIKeyCreate
+	apiKeyTeam   string
+)
+
+func init() {
+	rootCmd.AddCommand(apiKeyCmd)
+	apiKeyCmd.AddCommand(apiKeyCreateCmd, apiKeyListCmd, apiKeyRevokeCmd)
+
+	addDBFlags(apiKeyCmd)
+
+	apiKeyCreateCmd.Flags().StringVar(&apiKeyCreate.Name, "key-name", "", "name of the API key")
+	apiKeyCreateCmd.Flags().BoolVar(&apiKeyCreate.Admin, "admin", false, "allow the key to manage API keys and namespaces, and to access all namespaces")
+	apiKeyCreateCmd.Flags().StringVar(&apiKeyCreate.Namespace, "namespace", model.DefaultNamespace, "namespace of the key")
+	apiKeyCreateCmd.Flags().StringVar(&apiKeyTeam, "team", "", "team of the key, restricting a non-admin key to the jobs of the team")
+}
+
+func apiKeyService() (*apikey.Service, func()) {
+	logger := otelzap.L()
+	db, err := database.Open(dbConfig)
+	if err != nil {
+		logger.Sugar().Fatalf("unable to create database connection: %v", err)
+	}
+
+	return apikey.NewService(postgres.NewAPIKeyStore(db, logger), logger), func() { _ = db.Close() }
+}
+
+func apiKeyCreateRun(cmd *cobra.Command, args []string) {
+	service, closeDB := apiKeyService()
+	defer closeDB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if apiKeyTeam != "" {
+		apiKeyCreate.Team = null.StringFrom(apiKeyTeam)
+	}
+
+	key, err := service.CreateAPIKey(ctx, &apiKeyCreate)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to create the API key: %v", err)
+		return
+	}
+
+	fmt.Printf("Created API key %s (%s)\n%s\n", key.ID, key.Name, key.Key)
+}
+
+func apiKeyListRun(cmd *cobra.Command, args []string) {
+	service, closeDB := apiKeyService()
+	defer closeDB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := service.ListAPIKeys(ctx)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to list the API keys: %v", err)
+		return
+	}
+
+	for _, key := range keys {
+		status := "active"
+		if key.RevokedAt.Valid {
+			status = "revoked"
+		}
+
+		fmt.Printf("%s\t%s\t%s...\tnamespace=%s\tteam=%s\tadmin=%t\t%s\n", key.ID, key.Name, key.Prefix, key.Namespace, key.Team.String, key.Admin, status)
+	}
+}
+
+func apiKeyRevokeRun(cmd *cobra.Command, args []string) {
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("invalid API key ID: %v", err)
+		return
+	}
+
+	service, closeDB := apiKeyService()
+	defer closeDB()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := service.RevokeAPIKey(ctx, id); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to revoke the API key: %v", err)
+		return
+	}
+
+	fmt.Printf("Revoked API key %s\n", id)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// maxBackfillRuns limits the number of runs backfilled by a single command, sent to the API in batches.
+const maxBackfillRuns = 100000
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Execute the runs of a recurring job scheduled in a past range.",
+	Long: `Execute the runs of a recurring job scheduled in [from, to), e.g. the runs missed while the
+job was paused, skipping the runs which were already executed. The runs are sent to the
+management API in batches, and the progress is printed to stderr. With --dry-run, the runs
+are only listed.
+
+The endpoint, API key and namespace are set like for the job commands.`,
+	Args: cobra.NoArgs,
+	Run:  backfillRun,
+}
+
+var backfillOptions struct {
+	jobID     string
+	from      string
+	to        string
+	dryRun    bool
+	batchSize int
+}
+
+func init() {
+	rootCmd.AddCommand(backfillCmd)
+	addClientFlags(backfillCmd)
+
+	backfillCmd.Flags().StringVar(&backfillOptions.jobID, "job", "", "ID of the job")
+	backfillCmd.Flags().StringVar(&backfillOptions.from, "from", "", "start of the range (RFC3339), included")
+	backfillCmd.Flags().StringVar(&backfillOptions.to, "to", "", "end of the range (RFC3339), excluded, now if empty")
+	backfillCmd.Flags().BoolVar(&backfillOptions.dryRun, "dry-run", false, "list the runs without executing them")
+	backfillCmd.Flags().IntVar(&backfillOptions.batchSize, "batch-size", 100, fmt.Sprintf("runs per request, at most %d", model.MaxBackfillRuns))
+	_ = backfillCmd.MarkFlagRequired("job")
+	_ = backfillCmd.MarkFlagRequired("from")
+}
+
+func parseTimeFlag(name, value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("invalid --%s: %v", name, err)
+	}
+	return t
+}
+
+func backfillRun(cmd *cobra.Command, args []string) {
+	id := parseJobID(backfillOptions.jobID)
+	from := parseTimeFlag("from", backfillOptions.from)
+	to := time.Now()
+	if backfillOptions.to != "" {
+		to = parseTimeFlag("to", backfillOptions.to)
+	}
+
+	if backfillOptions.batchSize <= 0 || backfillOptions.batchSize > model.MaxBackfillRuns {
+		otelzap.L().Sugar().Fatalf("--batch-size must be between 1 and %d", model.MaxBackfillRuns)
+	}
+
+	c := apiClient()
+
+	ctx, cancel := requestContext()
+	job, err := c.GetJob(ctx, id)
+	cancel()
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to get the job: %v", err)
+		return
+	}
+
+	// the runs are listed locally to split them in batches, the API skipping the ones already executed
+	runs, err := job.RunsBetween(from, to, maxBackfillRuns)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to list the runs of the job: %v", err)
+		return
+	}
+
+	total := &model.JobBackfillResult{Runs: []time.Time{}, Skipped: []time.Time{}, Executions: []*model.JobExecution{}, DryRun: backfillOptions.dryRun}
+	for start := 0; start < len(runs); start += backfillOptions.batchSize {
+		end := min(start+backfillOptions.batchSize, len(runs))
+
+		// each batch covers the runs up to the first run of the next batch
+		batch := model.JobBackfill{From: runs[start], To: to}
+		if end < len(runs) {
+			batch.To = runs[end]
+		}
+
+		ctx, cancel := requestContext()
+		result, err := c.BackfillJob(ctx, id, batch, backfillOptions.dryRun)
+		cancel()
+		if err != nil {
+			otelzap.L().Sugar().Fatalf("unable to backfill the runs from %s: %v", batch.From.Format(time.RFC3339), err)
+			return
+		}
+
+		total.Runs = append(total.Runs, result.Runs...)
+		total.Skipped = append(total.Skipped, result.Skipped...)
+		total.Executions = append(total.Executions, result.Executions...)
+
+		_, _ = fmt.Fprintf(os.Stderr, "[%d/%d] %d runs to execute, %d already executed\n", end, len(runs), len(total.Runs), len(total.Skipped))
+	}
+
+	printBackfill(total)
+}
+
+// printBackfill prints the runs of the backfill in the output format.
+func printBackfill(result *model.JobBackfillResult) {
+	if output == outputJSON {
+		printJSON(result)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if result.DryRun {
+		_, _ = fmt.Fprintln(w, "SCHEDULED TIME")
+		for _, run := range result.Runs {
+			_, _ = fmt.Fprintln(w, run.Format(time.RFC3339))
+		}
+	} else {
+		_, _ = fmt.Fprintln(w, "EXECUTION\tSCHEDULED TIME")
+		for _, execution := range result.Executions {
+			_, _ = fmt.Fprintf(w, "%d\t%s\n", execution.ID, execution.ScheduledTime.Time.Format(time.RFC3339))
+		}
+	}
+	_ = w.Flush()
+
+	verb := "Scheduled"
+	if result.DryRun {
+		verb = "Would schedule"
+	}
+	fmt.Printf("\n%s %d runs, skipped %d runs already executed\n", verb, len(result.Runs), len(result.Skipped))
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/doctor"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/configcheck"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/jmoiron/sqlx"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the scheduler from its database, and print the problems found with how to fix them.",
+	Long: `Check that the database is reachable and its schema up to date, that the due jobs are
+picked up by the runners within --max-lag, that no job lock was left behind by a stopped
+runner, that the next run of the jobs matches their schedule, and that the encryption key
+decrypts the credentials of the --sample most recently updated jobs.
+
+The encryption key and key provider are read from the storage.encryption section of the
+manager configuration file given with --config, or from $MANAGER_STORAGE_ENCRYPTION_KEY.
+The command exits with a non-zero status if a problem was found, stale locks excepted.`,
+	Args: cobra.NoArgs,
+	Run:  doctorRun,
+}
+
+var doctorOptions struct {
+	config string
+	maxLag time.Duration
+	sample uint
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	addDBFlags(doctorCmd)
+
+	doctorCmd.Flags().StringVar(&doctorOptions.config, "config", "", "configuration file of the manager, with the encryption settings")
+	doctorCmd.Flags().DurationVar(&doctorOptions.maxLag, "max-lag", time.Minute, "age of the oldest due job above which the scheduler isn't keeping up")
+	doctorCmd.Flags().UintVar(&doctorOptions.sample, "sample", 1000, "number of jobs whose credentials are decrypted")
+}
+
+func doctorRun(cmd *cobra.Command, args []string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// the other checks query the database, so they only run once it is reachable and migrated
+	if !configcheck.Report(os.Stdout, configcheck.Run(ctx, []configcheck.Check{configcheck.DatabaseConnection(dbConfig)}, true)) {
+		os.Exit(1)
+	}
+
+	db, err := database.Open(dbConfig)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to create database connection: %v", err)
+		return
+	}
+	defer db.Close()
+
+	diagnostics := postgres.NewDiagnosticsStore(db, otelzap.L())
+	checks := []configcheck.Check{
+		doctor.SchedulerLag(diagnostics, doctorOptions.maxLag),
+		doctor.StaleLocks(diagnostics),
+		doctor.NextRuns(diagnostics),
+		credentialsCheck(ctx, db, diagnostics),
+	}
+
+	if !configcheck.Report(os.Stdout, configcheck.Run(ctx, checks, true)) {
+		os.Exit(1)
+	}
+}
+
+// credentialsCheck sets up the encryption like the manager does, and returns the check decrypting the credentials.
+func credentialsCheck(ctx context.Context, db *sqlx.DB, diagnostics store.DiagnosticsStorer) configcheck.Check {
+	viper.SetEnvPrefix("manager")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if doctorOptions.config != "" {
+		viper.SetConfigFile(doctorOptions.config)
+		if err := viper.ReadInConfig(); err != nil {
+			otelzap.L().Sugar().Fatalf("unable to read the configuration: %v", err)
+		}
+	}
+
+	key := viper.GetString("storage.encryption.key")
+	if key == "" {
+		return configcheck.Check{Name: "encryption key", Warning: true, Run: func(context.Context) error {
+			return errors.New("no encryption key, the credentials weren't checked\n   → set --config or $MANAGER_STORAGE_ENCRYPTION_KEY")
+		}}
+	}
+
+	var kms security.KMSSettings
+	if err := viper.UnmarshalKey("storage.encryption.kms", &kms); err != nil {
+		otelzap.L().Sugar().Fatalf("invalid key provider configuration: %v", err)
+	}
+
+	// an invalid key is reported instead of decrypting with it
+	encryption := configcheck.Encryption(key, kms)
+	if err := encryption.Run(ctx); err != nil {
+		return encryption
+	}
+
+	// the namespace keys decrypt the credentials encrypted with them, whether or not the manager still uses them
+	codec.SetEncryptor(security.NewEncryptorFromEnv())
+	postgres.SetNamespaceKeys(db, otelzap.L(), false)
+
+	return doctor.Credentials(diagnostics, doctorOptions.sample)
+}
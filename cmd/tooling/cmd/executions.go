@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/retention"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"gopkg.in/guregu/null.v4"
+)
+
+var executionsCmd = &cobra.Command{
+	Use:   "executions",
+	Short: "Manage the job executions.",
+}
+
+var executionsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the finished executions older than a given age, archiving them first if an archive is set.",
+	Long: `Delete the finished executions created longer ago than --older-than, along with their logs,
+outputs and timelines, optionally only for one job, and keeping the --keep-last latest
+executions of each job whatever their age. The executions are deleted in batches, at
+most --rate executions per second to limit the load on the database, so the command can
+run from a cron job.
+
+With --archive-type, each batch is written to the archive as gzip-compressed NDJSON
+before it is deleted, like with the execution retention of the manager. The credentials
+of the s3 archive are read from $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY.`,
+	Args: cobra.NoArgs,
+	Run:  executionsPruneRun,
+}
+
+var pruneOptions struct {
+	olderThan string
+	jobID     string
+	keepLast  uint
+	batchSize uint
+	rate      uint
+	archive   retention.ArchiveSettings
+}
+
+func init() {
+	rootCmd.AddCommand(executionsCmd)
+	executionsCmd.AddCommand(executionsPruneCmd)
+	addDBFlags(executionsPruneCmd)
+
+	flags := executionsPruneCmd.Flags()
+	flags.StringVar(&pruneOptions.olderThan, "older-than", "", "minimum age of the deleted executions, e.g. 90d or 36h")
+	flags.StringVar(&pruneOptions.jobID, "job", "", "only delete the executions of the job")
+	flags.UintVar(&pruneOptions.keepLast, "keep-last", 0, "number of latest executions of each job to keep")
+	flags.UintVar(&pruneOptions.batchSize, "batch-size", 1000, "number of executions deleted at once")
+	flags.UintVar(&pruneOptions.rate, "rate", 5000, "maximum number of executions deleted per second, unlimited if 0")
+	flags.StringVar(&pruneOptions.archive.Type, "archive-type", "", "archive of the deleted executions, one of: s3, file, none if empty")
+	flags.StringVar(&pruneOptions.archive.Prefix, "archive-prefix", "", "prefix of the archived batches")
+	flags.StringVar(&pruneOptions.archive.Dir, "archive-dir", "", "directory of the file archive")
+	flags.StringVar(&pruneOptions.archive.S3.Bucket, "archive-s3-bucket", "", "bucket of the s3 archive")
+	flags.StringVar(&pruneOptions.archive.S3.Region, "archive-s3-region", "", "region of the s3 archive, us-east-1 if empty")
+	flags.StringVar(&pruneOptions.archive.S3.Endpoint, "archive-s3-endpoint", "", "endpoint of the s3 archive, the AWS endpoint of the region if empty")
+	flags.BoolVar(&pruneOptions.archive.S3.PathStyle, "archive-s3-path-style", false, "address the bucket of the s3 archive in the path of the URLs")
+	_ = executionsPruneCmd.MarkFlagRequired("older-than")
+}
+
+// parseAge parses an age in days such as 90d, or a duration such as 36h.
+func parseAge(age string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		n, err := strconv.ParseUint(days, 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days: %q", age)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(age)
+}
+
+func executionsPruneRun(cmd *cobra.Command, args []string) {
+	logger := otelzap.L().Sugar()
+
+	age, err := parseAge(pruneOptions.olderThan)
+	if err != nil || age <= 0 {
+		logger.Fatalf("invalid --older-than: %q", pruneOptions.olderThan)
+		return
+	}
+
+	// the keepLast latest executions of a job are kept even when they are old enough
+	expiry := model.ExecutionExpiry{Before: null.TimeFrom(time.Now().Add(-age)), KeepLast: pruneOptions.keepLast, MatchAll: true}
+	if pruneOptions.jobID != "" {
+		id, err := uuid.Parse(pruneOptions.jobID)
+		if err != nil {
+			logger.Fatalf("invalid job ID: %v", err)
+			return
+		}
+		expiry.JobID = &id
+	}
+
+	archiver, err := retention.NewArchiver(pruneOptions.archive, &http.Client{Timeout: time.Minute})
+	if err != nil {
+		logger.Fatalf("unable to create the archive: %v", err)
+		return
+	}
+
+	db, err := database.Open(dbConfig)
+	if err != nil {
+		logger.Fatalf("unable to create database connection: %v", err)
+		return
+	}
+	defer db.Close()
+
+	// an interrupted prune stops after the current batch, the deleted batches staying deleted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	deleted, err := retention.Prune(ctx, postgres.New(db, otelzap.L()), archiver, retention.PruneOptions{
+		Expiry:    expiry,
+		BatchSize: pruneOptions.batchSize,
+		Rate:      pruneOptions.rate,
+		Progress: func(deleted int64) {
+			_, _ = fmt.Fprintf(os.Stderr, "Deleted %d executions\n", deleted)
+		},
+	})
+	if err != nil {
+		logger.Fatalf("unable to prune the executions after deleting %d: %v", deleted, err)
+		return
+	}
+
+	fmt.Printf("Deleted %d executions created before %s\n", deleted, expiry.Before.Time.Format(time.RFC3339))
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/client"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// tailReconnectDelay is how long the tail waits before reconnecting to an interrupted event stream.
+const tailReconnectDelay = 2 * time.Second
+
+var executionsTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream the executions of the namespace as they start and finish, until interrupted.",
+	Long: `Stream the execution events of the namespace from the event stream of the management API,
+one line per event, optionally only for some jobs or the jobs with all the given tags. The
+stream is reconnected when interrupted, e.g. while the management API restarts, the events
+published in between being missed. With -o json, the events are printed as NDJSON.`,
+	Args: cobra.NoArgs,
+	Run:  executionsTailRun,
+}
+
+var tailOptions struct {
+	jobIDs     []string
+	tags       []string
+	failedOnly bool
+}
+
+// tailStyles are the symbol and color of the execution events.
+var tailStyles = map[model.EventType]struct {
+	symbol string
+	color  *color.Color
+}{
+	model.EventExecutionStarted:   {"▶", color.New(color.FgCyan)},
+	model.EventExecutionFinished:  {"✓", color.New(color.FgGreen)},
+	model.EventExecutionFailed:    {"✗", color.New(color.FgRed)},
+	model.EventExecutionCancelled: {"■", color.New(color.FgYellow)},
+}
+
+func init() {
+	executionsCmd.AddCommand(executionsTailCmd)
+	addClientFlags(executionsTailCmd)
+
+	executionsTailCmd.Flags().StringSliceVar(&tailOptions.jobIDs, "job", nil, "only the executions of the jobs")
+	executionsTailCmd.Flags().StringSliceVar(&tailOptions.tags, "tag", nil, "only the executions of the jobs with all of the tags")
+	executionsTailCmd.Flags().BoolVar(&tailOptions.failedOnly, "failed", false, "only the failed executions")
+}
+
+func executionsTailRun(cmd *cobra.Command, args []string) {
+	options := client.StreamEventsOptions{
+		Types: []model.EventType{model.EventExecutionStarted, model.EventExecutionFinished, model.EventExecutionFailed, model.EventExecutionCancelled},
+		Tags:  tailOptions.tags,
+	}
+	if tailOptions.failedOnly {
+		options.Types = []model.EventType{model.EventExecutionFailed}
+	}
+	for _, jobID := range tailOptions.jobIDs {
+		options.JobIDs = append(options.JobIDs, parseJobID(jobID))
+	}
+
+	c := apiClient()
+	names := jobNames{client: c, names: map[uuid.UUID]string{}}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for {
+		err := c.StreamEvents(ctx, options, func(event model.Event) error {
+			printTailEvent(event, names)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		// errors of the API, e.g. an invalid API key, aren't solved by reconnecting
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) {
+			otelzap.L().Sugar().Fatalf("unable to stream the executions: %v", err)
+			return
+		}
+
+		_, _ = fmt.Fprintf(os.Stderr, "Event stream interrupted: %v, reconnecting in %s\n", err, tailReconnectDelay)
+		select {
+		case <-time.After(tailReconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// printTailEvent prints an execution event on a line in the output format.
+func printTailEvent(event model.Event, names jobNames) {
+	if output == outputJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(event); err != nil {
+			otelzap.L().Sugar().Fatalf("unable to encode the output: %v", err)
+		}
+		return
+	}
+
+	style, ok := tailStyles[event.Type]
+	if !ok {
+		return
+	}
+
+	execution := "-"
+	if event.ExecutionID != nil {
+		execution = fmt.Sprintf("#%d", *event.ExecutionID)
+	}
+
+	line := fmt.Sprintf("%s %s %-10s %s %s", event.Time.Local().Format(time.TimeOnly), style.symbol,
+		strings.TrimPrefix(string(event.Type), "execution."), names.get(event.JobID), execution)
+	if event.Error != "" {
+		line += "  " + event.Error
+	}
+
+	_, _ = style.color.Fprintln(os.Stdout, line)
+}
+
+// jobNames resolves the names of the jobs of the events, falling back to their ID.
+type jobNames struct {
+	client *client.Client
+	names  map[uuid.UUID]string
+}
+
+func (n jobNames) get(id uuid.UUID) string {
+	if name, ok := n.names[id]; ok {
+		return name
+	}
+
+	name := id.String()
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	if job, err := n.client.GetJob(ctx, id); err == nil && job.Name.Valid {
+		name = job.Name.String
+	}
+
+	n.names[id] = name
+	return name
+}
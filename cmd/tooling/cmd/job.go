@@ -0,0 +1,444 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/client"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/mtls"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var jobCmd = &cobra.Command{
+	Use:     "job",
+	Aliases: []string{"jobs"},
+	Short:   "Manage jobs through the management API.",
+	Long: `Manage jobs through the management API.
+
+The endpoint, API key and namespace default to the $SCHEDULER_ENDPOINT, $SCHEDULER_API_KEY
+and $SCHEDULER_NAMESPACE environment variables. With an HTTPS endpoint verifying the client
+certificates, the certificate, key and CA bundle default to $SCHEDULER_CERT, $SCHEDULER_KEY
+and $SCHEDULER_CACERT.`,
+}
+
+var jobCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a job from a JSON job create request.",
+	Args:  cobra.NoArgs,
+	Run:   jobCreateRun,
+}
+
+var jobGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Get a job.",
+	Args:  cobra.ExactArgs(1),
+	Run:   jobGetRun,
+}
+
+var jobListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List jobs, newest first.",
+	Args:  cobra.NoArgs,
+	Run:   jobListRun,
+}
+
+var jobUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Update a job from a JSON job update request.",
+	Args:  cobra.ExactArgs(1),
+	Run:   jobUpdateRun,
+}
+
+var jobDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a job.",
+	Args:  cobra.ExactArgs(1),
+	Run:   jobDeleteRun,
+}
+
+var jobPauseCmd = &cobra.Command{
+	Use:   "pause <id>...",
+	Short: "Pause jobs.",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   jobPauseRun,
+}
+
+var jobResumeCmd = &cobra.Command{
+	Use:   "resume <id>...",
+	Short: "Resume paused jobs.",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   jobResumeRun,
+}
+
+var jobRunCmd = &cobra.Command{
+	Use:   "run <id>",
+	Short: "Run a job now, regardless of its schedule.",
+	Long: `Run a job now, regardless of its schedule.
+
+The definition of the execution can be overridden, for this run only, with a file of JSON overrides, e.g.
+{"http": {"body": "{\"full\": true}", "headers": {"X-Replay": "1"}}}, or with the parameters of the template the
+job was instantiated from.`,
+	Args: cobra.ExactArgs(1),
+	Run:  jobRunRun,
+}
+
+var (
+	clientConfig client.Config
+	clientTLS    struct {
+		certPath string
+		keyPath  string
+		caPath   string
+	}
+	output      string
+	jobFile     string
+	listOptions struct {
+		client.ListJobsOptions
+		statuses []string
+		types    []string
+	}
+	jobRunOptions struct {
+		file       string
+		parameters map[string]string
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(jobCmd)
+	jobCmd.AddCommand(jobCreateCmd, jobGetCmd, jobListCmd, jobUpdateCmd, jobDeleteCmd, jobPauseCmd, jobResumeCmd, jobRunCmd)
+
+	addClientFlags(jobCmd)
+
+	jobCreateCmd.Flags().StringVarP(&jobFile, "file", "f", "-", "file with the job create request, - for stdin")
+	jobUpdateCmd.Flags().StringVarP(&jobFile, "file", "f", "-", "file with the job update request, - for stdin")
+	jobRunCmd.Flags().StringVarP(&jobRunOptions.file, "file", "f", "", "file with the overrides of the execution, - for stdin")
+	jobRunCmd.Flags().StringToStringVar(&jobRunOptions.parameters, "param", nil, "parameter of the template of the job, e.g. customer=acme")
+
+	jobListCmd.Flags().Uint64Var(&listOptions.Limit, "limit", 0, "maximum number of jobs, the API default if 0")
+	jobListCmd.Flags().StringVar(&listOptions.Cursor, "cursor", "", "cursor of the page, returned with the previous page")
+	jobListCmd.Flags().StringVarP(&listOptions.Query, "query", "q", "", "search the jobs")
+	jobListCmd.Flags().StringSliceVar(&listOptions.statuses, "status", nil, "only jobs with one of the statuses")
+	jobListCmd.Flags().StringSliceVar(&listOptions.types, "type", nil, "only jobs of one of the types")
+	jobListCmd.Flags().StringSliceVar(&listOptions.Tags, "tag", nil, "only jobs with all of the tags")
+	jobListCmd.Flags().StringVar(&listOptions.Selector, "selector", "", "only jobs matching the tag selector, e.g. 'env=prod AND team=payments'")
+}
+
+// addClientFlags adds the flags configuring the client of the management API to the command and its subcommands.
+func addClientFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&clientConfig.Endpoint, "endpoint", envOrDefault("SCHEDULER_ENDPOINT", "http://localhost:8000"), "management API endpoint")
+	cmd.PersistentFlags().StringVar(&clientConfig.APIKey, "api-key", os.Getenv("SCHEDULER_API_KEY"), "API key")
+	cmd.PersistentFlags().StringVar(&clientConfig.Namespace, "namespace", os.Getenv("SCHEDULER_NAMESPACE"), "namespace, the namespace of the API key if empty")
+	cmd.PersistentFlags().StringVar(&clientTLS.certPath, "cert", os.Getenv("SCHEDULER_CERT"), "client certificate presented to the management API")
+	cmd.PersistentFlags().StringVar(&clientTLS.keyPath, "key", os.Getenv("SCHEDULER_KEY"), "key of the client certificate")
+	cmd.PersistentFlags().StringVar(&clientTLS.caPath, "cacert", os.Getenv("SCHEDULER_CACERT"), "CA bundle verifying the management API, the system CAs if empty")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", outputTable, "output format, one of: table, json")
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// jobClient returns a client of the management API, and a context to send the requests with.
+func jobClient() (*client.Client, context.Context, context.CancelFunc) {
+	ctx, cancel := requestContext()
+	return apiClient(), ctx, cancel
+}
+
+// requestContext returns a context to send a request to the management API with.
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 30*time.Second)
+}
+
+// apiClient returns a client of the management API configured by the flags.
+func apiClient() *client.Client {
+	if output != outputTable && output != outputJSON {
+		otelzap.L().Sugar().Fatalf("unknown output format: %s", output)
+	}
+
+	if clientTLS.certPath != "" || clientTLS.keyPath != "" || clientTLS.caPath != "" {
+		tlsConfig, err := mtls.ClientConfig(clientTLS.certPath, clientTLS.keyPath, clientTLS.caPath)
+		if err != nil {
+			otelzap.L().Sugar().Fatalf("invalid TLS configuration: %v", err)
+		}
+
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		clientConfig.HTTPClient = &http.Client{Timeout: 30 * time.Second, Transport: transport}
+	}
+
+	return client.New(clientConfig)
+}
+
+func parseJobID(arg string) uuid.UUID {
+	id, err := uuid.Parse(arg)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("invalid job ID: %v", err)
+	}
+	return id
+}
+
+// readJobFile decodes the JSON request in the job file into v.
+func readJobFile(v interface{}) {
+	var reader io.Reader = os.Stdin
+	if jobFile != "-" {
+		file, err := os.Open(jobFile)
+		if err != nil {
+			otelzap.L().Sugar().Fatalf("unable to open the job file: %v", err)
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to decode the job file: %v", err)
+	}
+}
+
+func jobCreateRun(cmd *cobra.Command, args []string) {
+	var create model.JobCreate
+	readJobFile(&create)
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	job, err := c.CreateJob(ctx, &create)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to create the job: %v", err)
+		return
+	}
+
+	printJobs(job)
+}
+
+func jobGetRun(cmd *cobra.Command, args []string) {
+	id := parseJobID(args[0])
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	job, err := c.GetJob(ctx, id)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to get the job: %v", err)
+		return
+	}
+
+	printJobs(job)
+}
+
+func jobListRun(cmd *cobra.Command, args []string) {
+	opts := listOptions.ListJobsOptions
+	for _, status := range listOptions.statuses {
+		opts.Statuses = append(opts.Statuses, model.JobStatus(strings.ToUpper(status)))
+	}
+	for _, jobType := range listOptions.types {
+		opts.Types = append(opts.Types, model.JobType(strings.ToUpper(jobType)))
+	}
+	if output == outputTable {
+		// only fetch the columns of the table, so the API doesn't need to decrypt the job definitions
+		opts.Fields = []string{"name", "type", "status", "execute_at", "cron_schedule", "next_run", "tags"}
+	}
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	page, err := c.ListJobs(ctx, opts)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to list the jobs: %v", err)
+		return
+	}
+
+	if output == outputJSON {
+		printJSON(page)
+		return
+	}
+
+	jobs := make([]*model.Job, 0, len(page.Jobs))
+	for i := range page.Jobs {
+		jobs = append(jobs, &page.Jobs[i])
+	}
+	printJobs(jobs...)
+
+	if page.NextCursor.Valid {
+		fmt.Printf("\nNext page: --cursor=%s\n", page.NextCursor.String)
+	}
+}
+
+func jobUpdateRun(cmd *cobra.Command, args []string) {
+	id := parseJobID(args[0])
+
+	var update model.JobUpdate
+	readJobFile(&update)
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	job, err := c.UpdateJob(ctx, id, &update)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to update the job: %v", err)
+		return
+	}
+
+	printJobs(job)
+}
+
+func jobDeleteRun(cmd *cobra.Command, args []string) {
+	id := parseJobID(args[0])
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	if err := c.DeleteJob(ctx, id); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to delete the job: %v", err)
+		return
+	}
+
+	fmt.Printf("Deleted job %s\n", id)
+}
+
+func jobPauseRun(cmd *cobra.Command, args []string) {
+	jobBulkRun(args, "pause", (*client.Client).PauseJobs)
+}
+
+func jobResumeRun(cmd *cobra.Command, args []string) {
+	jobBulkRun(args, "resume", (*client.Client).ResumeJobs)
+}
+
+// jobBulkRun runs the bulk operation on the jobs with the IDs, and prints the outcome per job.
+func jobBulkRun(args []string, operation string, run func(*client.Client, context.Context, model.JobSelector) (*model.BulkResult, error)) {
+	selector := model.JobSelector{}
+	for _, arg := range args {
+		selector.IDs = append(selector.IDs, parseJobID(arg))
+	}
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	result, err := run(c, ctx, selector)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to %s the jobs: %v", operation, err)
+		return
+	}
+
+	if output == outputJSON {
+		printJSON(result)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tOUTCOME")
+	for _, item := range result.Results {
+		outcome := "ok"
+		if item.Error != "" {
+			outcome = item.Error
+		}
+
+		id := "-"
+		if item.ID != nil {
+			id = item.ID.String()
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", id, outcome)
+	}
+	_ = w.Flush()
+}
+
+func jobRunRun(cmd *cobra.Command, args []string) {
+	id := parseJobID(args[0])
+
+	var overrides *model.JobRunOverrides
+	if jobRunOptions.file != "" {
+		overrides = &model.JobRunOverrides{}
+		jobFile = jobRunOptions.file
+		readJobFile(overrides)
+	}
+	if len(jobRunOptions.parameters) > 0 {
+		if overrides == nil {
+			overrides = &model.JobRunOverrides{}
+		}
+		overrides.Parameters = jobRunOptions.parameters
+	}
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	execution, err := c.RunJob(ctx, id, overrides)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to run the job: %v", err)
+		return
+	}
+
+	if output == outputJSON {
+		printJSON(execution)
+		return
+	}
+
+	if execution.Overridden {
+		fmt.Printf("Scheduled execution %d of job %s with overrides\n", execution.ID, id)
+		return
+	}
+
+	fmt.Printf("Scheduled execution %d of job %s\n", execution.ID, id)
+}
+
+// printJobs prints the jobs in the output format, a single job is printed as a JSON object.
+func printJobs(jobs ...*model.Job) {
+	if output == outputJSON {
+		if len(jobs) == 1 {
+			printJSON(jobs[0])
+		} else {
+			printJSON(jobs)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tTYPE\tSTATUS\tSCHEDULE\tNEXT RUN\tTAGS")
+	for _, job := range jobs {
+		schedule := job.CronSchedule.String
+		if job.ExecuteAt.Valid {
+			schedule = "at " + job.ExecuteAt.Time.Format(time.RFC3339)
+		}
+
+		nextRun := "-"
+		if job.NextRun.Valid {
+			nextRun = job.NextRun.Time.Format(time.RFC3339)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			job.ID, orDash(job.Name.String), job.Type, job.Status, orDash(schedule), nextRun, orDash(strings.Join(job.Tags, ",")))
+	}
+	_ = w.Flush()
+}
+
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to encode the output: %v", err)
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
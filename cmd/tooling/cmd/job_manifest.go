@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+var jobExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the named jobs of the namespace as a YAML manifest, without their credentials.",
+	Args:  cobra.NoArgs,
+	Run:   jobExportRun,
+}
+
+var jobImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create or update the named jobs of a YAML or JSON manifest, e.g. exported from another environment.",
+	Long: `Create the jobs of the manifest missing from the namespace, and update the jobs of the same
+name whose definition differs, in a single transaction. The other jobs of the namespace are
+kept unless --prune is set, and the credentials left out of the manifest, like in exported
+manifests, are kept in the updated jobs unless --keep-credentials=false.`,
+	Args: cobra.NoArgs,
+	Run:  jobImportRun,
+}
+
+var importOptions struct {
+	prune           bool
+	keepCredentials bool
+}
+
+func init() {
+	jobCmd.AddCommand(jobExportCmd, jobImportCmd)
+
+	jobImportCmd.Flags().StringVarP(&jobFile, "file", "f", "-", "file with the manifest, - for stdin")
+	jobImportCmd.Flags().BoolVar(&importOptions.prune, "prune", false, "delete the named jobs missing from the manifest")
+	jobImportCmd.Flags().BoolVar(&importOptions.keepCredentials, "keep-credentials", true, "keep the credentials of the updated jobs which the manifest leaves out")
+}
+
+func jobExportRun(cmd *cobra.Command, args []string) {
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	manifest, err := c.ExportJobs(ctx)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to export the jobs: %v", err)
+		return
+	}
+
+	if output == outputJSON {
+		printJSON(manifest)
+		return
+	}
+
+	data, err := manifest.YAML()
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to encode the manifest: %v", err)
+		return
+	}
+
+	_, _ = os.Stdout.Write(data)
+}
+
+func jobImportRun(cmd *cobra.Command, args []string) {
+	manifest := readManifestFile()
+
+	c, ctx, cancel := jobClient()
+	defer cancel()
+
+	result, err := c.ApplyManifest(ctx, manifest, model.ManifestApplyOptions{
+		KeepMissing:     !importOptions.prune,
+		KeepCredentials: importOptions.keepCredentials,
+	})
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to import the jobs: %v", err)
+		return
+	}
+
+	if output == outputJSON {
+		printJSON(result)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tACTION")
+	for _, action := range []struct {
+		names []string
+		verb  string
+	}{{result.Created, "created"}, {result.Updated, "updated"}, {result.Deleted, "deleted"}, {result.Unchanged, "unchanged"}} {
+		for _, name := range action.names {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", name, action.verb)
+		}
+	}
+	_ = w.Flush()
+}
+
+// readManifestFile parses the YAML or JSON manifest in the job file.
+func readManifestFile() *model.JobManifest {
+	var data []byte
+	var err error
+	if jobFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(jobFile)
+	}
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to read the manifest: %v", err)
+	}
+
+	manifest, err := model.ParseJobManifest(data)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to parse the manifest: %v", err)
+	}
+
+	return manifest
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database/dbmigrate"
+	"github.com/jmoiron/sqlx"
 	"github.com/spf13/cobra"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 )
@@ -16,26 +20,76 @@ var migrateCmd = &cobra.Command{
 	Run:   migrateRun,
 }
 
-var dbConfig database.Config
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the pending migrations.",
+	Run:   migrateRun,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back the last applied migrations.",
+	Run:   migrateDownRun,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the migrations and whether they are applied.",
+	Run:   migrateStatusRun,
+}
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <description>",
+	Short: "Add an empty migration and its rollback to the migration files.",
+	Args:  cobra.ExactArgs(1),
+	Run:   migrateCreateRun,
+}
+
+var (
+	dbConfig database.Config
+
+	migrateSteps int
+	migrateDir   string
+)
 
 func init() {
 	rootCmd.AddCommand(migrateCmd)
-	migrateCmd.Flags().StringVar(&dbConfig.User, "user", "scheduler", "database user")
-	migrateCmd.Flags().StringVar(&dbConfig.Password, "pass", "scheduler", "database password")
-	migrateCmd.Flags().StringVar(&dbConfig.Host, "host", "localhost:5432", "database host")
-	migrateCmd.Flags().StringVar(&dbConfig.Name, "name", "scheduler", "database name")
-	migrateCmd.Flags().BoolVar(&dbConfig.DisableTLS, "disable_tls", true, "database sslmode disabled")
-	migrateCmd.Flags().IntVar(&dbConfig.MaxIdleConns, "max_idle_conns", 3, "database max idle connections")
-	migrateCmd.Flags().IntVar(&dbConfig.MaxOpenConns, "max_open_conns", 2, "database max open connections")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateCreateCmd)
+
+	addDBFlags(migrateCmd)
+	migrateCmd.PersistentFlags().IntVar(&dbConfig.MaxIdleConns, "max_idle_conns", 3, "database max idle connections")
+	migrateCmd.PersistentFlags().IntVar(&dbConfig.MaxOpenConns, "max_open_conns", 2, "database max open connections")
+
+	migrateDownCmd.Flags().IntVar(&migrateSteps, "steps", 1, "number of migrations to roll back")
+	migrateCreateCmd.Flags().StringVar(&migrateDir, "dir", "internal/pkg/database/dbmigrate/sql", "directory of the migration files")
 }
 
-func migrateRun(cmd *cobra.Command, args []string) {
-	logger := otelzap.L().Sugar()
+// addDBFlags adds the flags of the database connection to a command and its subcommands.
+func addDBFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&dbConfig.User, "user", "scheduler", "database user")
+	cmd.PersistentFlags().StringVar(&dbConfig.Password, "pass", "scheduler", "database password")
+	cmd.PersistentFlags().StringVar(&dbConfig.Host, "host", "localhost:5432", "database host")
+	cmd.PersistentFlags().StringVar(&dbConfig.Name, "name", "scheduler", "database name")
+	cmd.PersistentFlags().BoolVar(&dbConfig.DisableTLS, "disable_tls", true, "database sslmode disabled")
+	cmd.PersistentFlags().StringVar(&dbConfig.DSN, "dsn", "", "database connection string, replacing the user, password, host and name")
+	cmd.PersistentFlags().StringVar(&dbConfig.SSLMode, "sslmode", "", "database sslmode, overriding disable_tls")
+	cmd.PersistentFlags().StringVar(&dbConfig.SSLRootCert, "ssl_root_cert", "", "database root CA certificates path")
+	cmd.PersistentFlags().StringVar(&dbConfig.SSLCert, "ssl_cert", "", "database client certificate path")
+	cmd.PersistentFlags().StringVar(&dbConfig.SSLKey, "ssl_key", "", "database client certificate key path")
+}
+
+func openMigrationDB() *sqlx.DB {
 	db, err := database.Open(dbConfig)
 	if err != nil {
-		logger.Fatalf("unable to create database connection: %v", err)
-		return
+		otelzap.L().Sugar().Fatalf("unable to create database connection: %v", err)
 	}
+
+	return db
+}
+
+func migrateRun(cmd *cobra.Command, args []string) {
+	logger := otelzap.L().Sugar()
+	db := openMigrationDB()
 	defer db.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -48,3 +102,70 @@ func migrateRun(cmd *cobra.Command, args []string) {
 
 	logger.Info("Database migrations complete!")
 }
+
+func migrateDownRun(cmd *cobra.Command, args []string) {
+	logger := otelzap.L().Sugar()
+	if migrateSteps < 1 {
+		logger.Fatalf("the number of steps must be positive")
+		return
+	}
+
+	db := openMigrationDB()
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reverted, err := dbmigrate.Rollback(ctx, db, migrateSteps)
+	for _, version := range reverted {
+		logger.Infof("Rolled back migration %s", dbmigrate.FormatVersion(version))
+	}
+
+	if err != nil {
+		logger.Fatalf("unable to roll back the database: %v", err)
+		return
+	}
+
+	if len(reverted) == 0 {
+		logger.Info("No migrations to roll back")
+	}
+}
+
+func migrateStatusRun(cmd *cobra.Command, args []string) {
+	db := openMigrationDB()
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	statuses, err := dbmigrate.Status(ctx, db)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to get the status of the migrations: %v", err)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tSTATUS\tAPPLIED AT\tREVERSIBLE\tDESCRIPTION")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format(time.RFC3339)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\n",
+			dbmigrate.FormatVersion(status.Version), state, appliedAt, status.Reversible, status.Description)
+	}
+	_ = w.Flush()
+}
+
+func migrateCreateRun(cmd *cobra.Command, args []string) {
+	logger := otelzap.L().Sugar()
+
+	version, err := dbmigrate.Create(migrateDir, args[0])
+	if err != nil {
+		logger.Fatalf("unable to create the migration: %v", err)
+		return
+	}
+
+	logger.Infof("Created migration %s, write its script and rollback in %s", dbmigrate.FormatVersion(version), migrateDir)
+}
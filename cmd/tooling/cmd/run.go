@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/secrets"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+const (
+	// maxRunLogSize and maxRunOutputSize are the limits of the runner's defaults
+	maxRunLogSize    = 64 * 1024
+	maxRunOutputSize = 1024 * 1024
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a job definition once locally, without the management API or the database.",
+	Long: `Run the job of a YAML or JSON job definition once, with the executor the runner would
+use, and print its outcome, logs, timeline and output. Nothing is recorded, so job payloads
+and credentials can be tried out before the job is scheduled. The schedule of the job is
+ignored, and the definition may leave it out.
+
+References to Vault secrets are resolved with $VAULT_ADDR and $VAULT_TOKEN. Custom job
+types are run by loading the plugins registering them with --plugin. The command exits
+with a non-zero status if the execution failed.`,
+	Args: cobra.NoArgs,
+	Run:  runRun,
+}
+
+var runOptions struct {
+	timeout time.Duration
+	retries int
+	plugins []string
+}
+
+// runResult is the outcome of a local run.
+type runResult struct {
+	Status   model.JobExecutionStatus `json:"status"`
+	Duration string                   `json:"duration"`
+	Error    string                   `json:"error,omitempty"`
+	Logs     model.ExecutionLogs      `json:"logs"`
+	Events   []model.ExecutionEvent   `json:"events"`
+	Output   string                   `json:"output,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+
+	runCmd.Flags().StringVarP(&jobFile, "file", "f", "-", "file with the job definition, - for stdin")
+	runCmd.Flags().DurationVar(&runOptions.timeout, "timeout", time.Minute, "maximum duration of the execution, unless the job sets an execution timeout")
+	runCmd.Flags().IntVar(&runOptions.retries, "retries", 0, "number of times a failed execution is retried")
+	runCmd.Flags().StringSliceVar(&runOptions.plugins, "plugin", nil, "plugin registering custom job types or executor middlewares")
+	runCmd.Flags().StringVarP(&output, "output", "o", outputTable, "output format, one of: table, json")
+}
+
+func runRun(cmd *cobra.Command, args []string) {
+	if output != outputTable && output != outputJSON {
+		otelzap.L().Sugar().Fatalf("unknown output format: %s", output)
+	}
+
+	// plugins register their job types, which are validated with the job
+	if err := executor.LoadPlugins(runOptions.plugins); err != nil {
+		otelzap.L().Sugar().Fatalf("unable to load the plugins: %v", err)
+	}
+
+	job := readJobDefinition()
+
+	resolver, err := secrets.NewResolverFromSettings(secrets.Settings{}, nil)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to configure the secrets: %v", err)
+	}
+
+	factory := executor.NewFactory(&http.Client{Timeout: 30 * time.Second}, executor.WithSecretResolver(resolver))
+
+	var options []executor.Option
+	if runOptions.retries > 0 {
+		options = append(options, executor.Retry(runOptions.retries))
+	}
+
+	jobExecutor, err := factory.NewExecutor(job, options...)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to create the executor: %v", err)
+	}
+
+	result := runJob(jobExecutor, job)
+	printRunResult(result)
+
+	if result.Status != model.JobExecutionStatusSuccessful {
+		os.Exit(1)
+	}
+}
+
+// readJobDefinition parses and validates the job definition in the job file.
+func readJobDefinition() *model.Job {
+	var data []byte
+	var err error
+	if jobFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(jobFile)
+	}
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to read the job definition: %v", err)
+	}
+
+	definition, err := model.ParseJobDefinition(data)
+	if err != nil {
+		otelzap.L().Sugar().Fatalf("unable to parse the job definition: %v", err)
+	}
+
+	job := definition.ToJob()
+	for _, fieldErr := range job.FieldErrors() {
+		// the job isn't scheduled, so its schedule doesn't matter
+		switch fieldErr.Field {
+		case "cron_schedule", "execute_at", "ttl":
+			continue
+		}
+
+		otelzap.L().Sugar().Fatalf("invalid job definition: %s: %s", fieldErr.Field, fieldErr.Message)
+	}
+
+	return job
+}
+
+// runJob executes the job once, capturing its logs, timeline and output like the runner does.
+func runJob(jobExecutor executor.Executor, job *model.Job) *runResult {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	timeout := runOptions.timeout
+	if job.ExecutionTimeout.Valid {
+		timeout = time.Duration(job.ExecutionTimeout.Int64) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	log, logger := executor.NewExecutionLog(maxRunLogSize)
+	capturedOutput := executor.NewExecutionOutput(maxRunOutputSize)
+	timeline := executor.NewExecutionTimeline("local")
+
+	ctx = executor.WithLogger(ctx, logger)
+	ctx = executor.WithOutput(ctx, capturedOutput)
+	ctx = executor.WithTimeline(ctx, timeline)
+
+	startTime := time.Now()
+	executor.RecordEvent(ctx, model.ExecutionEventStarted, 0, nil)
+	err := jobExecutor.Execute(ctx, job)
+
+	result := &runResult{
+		Status:   model.JobExecutionStatusSuccessful,
+		Duration: time.Since(startTime).Round(time.Millisecond).String(),
+		Logs:     log.Logs(0),
+		Events:   timeline.Events(0),
+		Output:   decodeRunOutput(capturedOutput),
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			result.Status = model.JobExecutionStatusTimedOut
+		case ctx.Err() != nil:
+			result.Status = model.JobExecutionStatusCancelled
+		default:
+			result.Status = model.JobExecutionStatusFailed
+		}
+	}
+
+	return result
+}
+
+// decodeRunOutput decompresses the captured output, which is empty if the executor wrote none.
+func decodeRunOutput(capturedOutput *executor.ExecutionOutput) string {
+	output, ok := capturedOutput.Output(0)
+	if !ok {
+		return ""
+	}
+
+	decoder, err := zstd.NewReader(bytes.NewReader(output.Data), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return ""
+	}
+	defer decoder.Close()
+
+	var decoded strings.Builder
+	_, _ = io.Copy(&decoded, decoder)
+	if output.Truncated {
+		decoded.WriteString("\n[truncated]")
+	}
+
+	return decoded.String()
+}
+
+// printRunResult prints the outcome of the run in the output format.
+func printRunResult(result *runResult) {
+	if output == outputJSON {
+		printJSON(result)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "STATUS\t%s\n", result.Status)
+	_, _ = fmt.Fprintf(w, "DURATION\t%s\n", result.Duration)
+	if result.Error != "" {
+		_, _ = fmt.Fprintf(w, "ERROR\t%s\n", result.Error)
+	}
+	_ = w.Flush()
+
+	if len(result.Events) > 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "\nTIMELINE")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, event := range result.Events {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", event.Time.Format(time.RFC3339Nano), event.Type, event.Message.String)
+		}
+		_ = w.Flush()
+	}
+
+	if len(result.Logs.Entries) > 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "\nLOGS")
+		for _, entry := range result.Logs.Entries {
+			_, _ = fmt.Fprintf(os.Stdout, "%s %-5s %s", entry.Time.Format(time.RFC3339Nano), strings.ToUpper(entry.Level), entry.Message)
+			for _, key := range slices.Sorted(maps.Keys(entry.Fields)) {
+				_, _ = fmt.Fprintf(os.Stdout, " %s=%v", key, entry.Fields[key])
+			}
+			_, _ = fmt.Fprintln(os.Stdout)
+		}
+	}
+
+	if result.Output != "" {
+		_, _ = fmt.Fprintf(os.Stdout, "\nOUTPUT\n%s\n", result.Output)
+	}
+}
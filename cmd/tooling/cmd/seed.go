@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/seed"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/spf13/cobra"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Generate random jobs and the history of their executions in a database, for load tests and demos.",
+	Long: `Generate --jobs random but realistic jobs in the namespace, mostly recurring with a few one-off
+jobs, of the given types, teams, tags, priorities and capabilities, along with up to --executions
+past executions of each recurring job scheduled within --history. Most jobs rarely fail, a few
+fail often, and their executions vary around a typical duration, some timing out.
+
+The jobs are written directly to the database, in batches of --batch-size jobs and their
+executions, and are picked up by the runners polling the namespace like any other job. The
+names of the jobs are numbered from 0, so seeding the same namespace again fails: seed another
+namespace or delete the seeded jobs first. The credentials of the jobs are not encrypted, the
+jobs have none.`,
+	Args: cobra.NoArgs,
+	Run:  seedRun,
+}
+
+var seedOptions struct {
+	jobs       int
+	types      []string
+	namespace  string
+	history    string
+	executions int
+	batchSize  int
+	seed       uint64
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+	addDBFlags(seedCmd)
+
+	flags := seedCmd.Flags()
+	flags.IntVar(&seedOptions.jobs, "jobs", 100, "number of jobs")
+	flags.StringSliceVar(&seedOptions.types, "types", []string{"http", "amqp"}, "types of the jobs, picked at random")
+	flags.StringVar(&seedOptions.namespace, "namespace", model.DefaultNamespace, "namespace of the jobs")
+	flags.StringVar(&seedOptions.history, "history", "30d", "how far back the executions go, e.g. 30d or 12h")
+	flags.IntVar(&seedOptions.executions, "executions", 100, "maximum number of executions of each recurring job, none if 0")
+	flags.IntVar(&seedOptions.batchSize, "batch-size", 200, "number of jobs written at once with their executions")
+	flags.Uint64Var(&seedOptions.seed, "seed", 0, "seed of the random generator, random if 0")
+}
+
+func seedRun(cmd *cobra.Command, args []string) {
+	logger := otelzap.L().Sugar()
+
+	if seedOptions.jobs <= 0 || seedOptions.batchSize <= 0 {
+		logger.Fatalf("--jobs and --batch-size must be positive")
+		return
+	}
+
+	history, err := parseAge(seedOptions.history)
+	if err != nil || history <= 0 {
+		logger.Fatalf("invalid --history: %q", seedOptions.history)
+		return
+	}
+
+	types := make([]model.JobType, 0, len(seedOptions.types))
+	for _, name := range seedOptions.types {
+		jobType := model.JobType(strings.ToUpper(strings.TrimSpace(name)))
+		if !jobType.Valid() {
+			logger.Fatalf("invalid job type %q, must be one of: http, amqp", name)
+			return
+		}
+		types = append(types, jobType)
+	}
+
+	if seedOptions.seed == 0 {
+		seedOptions.seed = uint64(time.Now().UnixNano())
+	}
+
+	db, err := database.Open(dbConfig)
+	if err != nil {
+		logger.Fatalf("unable to create database connection: %v", err)
+		return
+	}
+	defer db.Close()
+
+	jobStore := postgres.New(db, otelzap.L())
+
+	// an interrupted seed stops after the current batch, the written batches staying written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	now := time.Now()
+	if partitions, ok := jobStore.(store.ExecutionPartitioner); ok {
+		// without their partitions, the executions of the past months would be stored in the default partition
+		if _, err := partitions.CreateExecutionPartitions(ctx, now.Add(-history), now); err != nil {
+			logger.Warnf("unable to create the execution partitions, the executions of the months without one are stored in the default partition: %v", err)
+		}
+	}
+
+	generator := seed.NewGenerator(seedOptions.seed, now, seed.Options{
+		Namespace:     seedOptions.namespace,
+		Types:         types,
+		History:       history,
+		MaxExecutions: seedOptions.executions,
+	})
+
+	created, executions := 0, 0
+	for created < seedOptions.jobs && ctx.Err() == nil {
+		size := min(seedOptions.batchSize, seedOptions.jobs-created)
+		jobs := make([]*model.Job, 0, size)
+		var pastExecutions []*model.JobExecution
+		for i := created; i < created+size; i++ {
+			job := generator.Job(i)
+			jobs = append(jobs, job)
+			pastExecutions = append(pastExecutions, generator.Executions(job)...)
+		}
+
+		err := jobStore.InTx(ctx, func(ctx context.Context) error {
+			if err := jobStore.CreateJobs(ctx, jobs); err != nil {
+				return err
+			}
+
+			return jobStore.CreateJobExecutions(ctx, pastExecutions)
+		})
+		if errors.Is(err, errs.ErrJobNameTaken) {
+			logger.Fatalf("the namespace %s was already seeded, seed another namespace with --namespace or delete the seeded jobs", seedOptions.namespace)
+			return
+		}
+		if err != nil {
+			logger.Fatalf("unable to seed the jobs after creating %d: %v", created, err)
+			return
+		}
+
+		created += size
+		executions += len(pastExecutions)
+		_, _ = fmt.Fprintf(os.Stderr, "Created %d jobs and %d executions\n", created, executions)
+	}
+
+	fmt.Printf("Seeded %d jobs and %d executions in the namespace %s (seed %d)\n", created, executions, seedOptions.namespace, seedOptions.seed)
+}
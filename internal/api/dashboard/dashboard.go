@@ -0,0 +1,27 @@
+// Package dashboard embeds the static assets of the built-in web dashboard. The dashboard runs in the browser and
+// calls the /v1 API with the API key entered by the user, so it doesn't need any endpoint of its own.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// ContentSecurityPolicy of the dashboard, which only loads its own scripts and styles, and calls the API of its origin.
+const ContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' data:; " +
+	"connect-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+//go:embed static
+var static embed.FS
+
+// FileSystem returns the assets of the dashboard, with the index.html page at the root.
+func FileSystem() http.FileSystem {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		// the directory is embedded at build time
+		panic(err)
+	}
+
+	return http.FS(assets)
+}
@@ -0,0 +1,338 @@
+"use strict";
+
+// The dashboard calls the API of the manager serving it. The API key and the namespace are kept for the browser
+// session only.
+const pageSize = 50;
+const refreshInterval = 15000;
+
+const state = {
+    apiKey: sessionStorage.getItem("apiKey") || "",
+    namespace: sessionStorage.getItem("namespace") || "",
+    jobs: [],
+    jobsCursor: null,
+    selectedJob: null,
+    executionsCursor: null,
+};
+
+const $ = (id) => document.getElementById(id);
+
+async function api(method, path, body) {
+    const headers = {"Accept": "application/json"};
+    if (state.apiKey) {
+        headers["X-API-Key"] = state.apiKey;
+    }
+    if (state.namespace) {
+        headers["X-Namespace"] = state.namespace;
+    }
+    if (body !== undefined) {
+        headers["Content-Type"] = "application/json";
+    }
+
+    const response = await fetch("/v1" + path, {
+        method,
+        headers,
+        body: body === undefined ? undefined : JSON.stringify(body),
+    });
+    const text = await response.text();
+    const payload = text ? JSON.parse(text) : null;
+    if (!response.ok) {
+        throw new Error(errorMessage(payload) || response.status + " " + response.statusText);
+    }
+
+    return payload;
+}
+
+// errorMessage returns the error of an error response, or the errors of the items of a failed bulk operation.
+function errorMessage(payload) {
+    if (!payload) {
+        return "";
+    }
+    if (payload.results) {
+        return payload.results.filter((result) => result.error).map((result) => result.error).join(", ");
+    }
+
+    return payload.error;
+}
+
+function showError(err) {
+    const banner = $("error");
+    banner.textContent = err ? err.message : "";
+    banner.hidden = !err;
+}
+
+function el(tag, text, className) {
+    const node = document.createElement(tag);
+    if (text !== undefined && text !== null) {
+        node.textContent = text;
+    }
+    if (className) {
+        node.className = className;
+    }
+
+    return node;
+}
+
+function badge(status) {
+    return status ? el("span", status, "badge " + status) : el("span", "—", "muted");
+}
+
+function formatTime(value) {
+    return value ? new Date(value).toLocaleString() : "—";
+}
+
+function formatDuration(start, end) {
+    if (!end) {
+        return "running";
+    }
+
+    const ms = new Date(end) - new Date(start);
+    if (ms < 1000) {
+        return ms + " ms";
+    }
+    if (ms < 60000) {
+        return (ms / 1000).toFixed(1) + " s";
+    }
+
+    return Math.floor(ms / 60000) + " min " + Math.round((ms % 60000) / 1000) + " s";
+}
+
+function jobLabel(job) {
+    return job.name || job.id;
+}
+
+function button(label, onClick) {
+    const node = el("button", label);
+    node.type = "button";
+    node.addEventListener("click", async (event) => {
+        event.stopPropagation();
+        node.disabled = true;
+        try {
+            await onClick();
+            showError(null);
+        } catch (err) {
+            showError(err);
+        } finally {
+            node.disabled = false;
+        }
+    });
+
+    return node;
+}
+
+// Jobs
+
+async function loadJobs(append) {
+    const params = new URLSearchParams({limit: String(pageSize)});
+    const status = $("status-filter").value;
+    if (status) {
+        params.set("status", status);
+    }
+    const selector = $("selector").value.trim();
+    if (selector) {
+        params.set("selector", selector);
+    }
+    if ($("failed-only").checked) {
+        params.set("failed", "true");
+    }
+    if (append && state.jobsCursor) {
+        params.set("cursor", state.jobsCursor);
+    }
+
+    const page = await api("GET", "/jobs?" + params);
+    state.jobs = append ? state.jobs.concat(page.jobs) : page.jobs;
+    state.jobsCursor = page.next_cursor;
+
+    $("jobs-total").textContent = "(" + (page.total_estimated ? "~" : "") + page.total + ")";
+    $("more-jobs").hidden = !state.jobsCursor;
+    renderJobs();
+    renderUpcoming();
+}
+
+function renderJobs() {
+    const rows = state.jobs.map((job) => {
+        const row = el("tr");
+        if (state.selectedJob && state.selectedJob.id === job.id) {
+            row.className = "selected";
+        }
+
+        const name = el("td");
+        name.append(el("div", jobLabel(job)));
+        if (job.tags && job.tags.length) {
+            name.append(el("div", job.tags.join(", "), "muted"));
+        }
+
+        const status = el("td");
+        status.append(badge(job.status));
+        const lastExecution = el("td");
+        lastExecution.append(badge(job.last_execution_status));
+
+        const actions = el("td", null, "actions");
+        if (job.status === "STOPPED") {
+            actions.append(button("Resume", () => bulk("resume", job)));
+        } else if (job.status !== "ARCHIVED") {
+            actions.append(button("Pause", () => bulk("pause", job)));
+        }
+        actions.append(button("Run now", () => runNow(job)));
+
+        row.append(
+            name,
+            el("td", job.type),
+            el("td", job.cron_schedule || formatTime(job.execute_at)),
+            status,
+            lastExecution,
+            el("td", formatTime(job.next_run)),
+            actions,
+        );
+        row.addEventListener("click", () => selectJob(job));
+
+        return row;
+    });
+
+    $("jobs").replaceChildren(...rows);
+}
+
+function renderUpcoming() {
+    const now = Date.now();
+    const upcoming = state.jobs
+        .filter((job) => job.next_run && job.status !== "STOPPED" && new Date(job.next_run) >= now)
+        .sort((a, b) => new Date(a.next_run) - new Date(b.next_run))
+        .slice(0, 10)
+        .map((job) => {
+            const item = el("li");
+            item.append(el("span", formatTime(job.next_run) + " "), el("span", jobLabel(job), "muted"));
+            return item;
+        });
+
+    $("upcoming").replaceChildren(...(upcoming.length ? upcoming : [el("li", "No upcoming runs", "muted")]));
+}
+
+async function bulk(action, job) {
+    await api("POST", "/jobs/bulk/" + action, {ids: [job.id]});
+    await loadJobs(false);
+}
+
+async function runNow(job) {
+    await api("POST", "/jobs/" + encodeURIComponent(job.id) + "/run");
+    await selectJob(job);
+}
+
+// Executions
+
+async function selectJob(job) {
+    state.selectedJob = job;
+    $("executions-section").hidden = false;
+    $("executions-job").textContent = jobLabel(job);
+    renderJobs();
+
+    try {
+        await loadExecutions(false);
+        showError(null);
+    } catch (err) {
+        showError(err);
+    }
+}
+
+async function loadExecutions(append) {
+    const job = state.selectedJob;
+    const params = new URLSearchParams({limit: String(pageSize)});
+    if ($("failed-executions").checked) {
+        params.set("failedOnly", "true");
+    }
+    if (append && state.executionsCursor) {
+        params.set("cursor", state.executionsCursor);
+    }
+
+    const page = await api("GET", "/jobs/" + encodeURIComponent(job.id) + "/executions?" + params);
+    state.executionsCursor = page.next_cursor;
+    $("more-executions").hidden = !state.executionsCursor;
+
+    const rows = [];
+    for (const execution of page.executions || []) {
+        const row = el("tr");
+        const status = el("td");
+        status.append(badge(execution.status));
+        row.append(
+            el("td", formatTime(execution.start_time)),
+            el("td", formatDuration(execution.start_time, execution.end_time)),
+            status,
+            el("td", String(execution.number_of_retries)),
+        );
+        rows.push(row);
+
+        if (execution.error_message) {
+            const errorRow = el("tr");
+            const cell = el("td", execution.error_message, "execution-error");
+            cell.colSpan = 4;
+            errorRow.append(cell);
+            rows.push(errorRow);
+        }
+    }
+
+    if (append) {
+        $("executions").append(...rows);
+    } else {
+        $("executions").replaceChildren(...rows);
+    }
+}
+
+// Wiring
+
+async function refresh() {
+    try {
+        await loadJobs(false);
+        if (state.selectedJob) {
+            await loadExecutions(false);
+        }
+        showError(null);
+    } catch (err) {
+        showError(err);
+    }
+}
+
+function onClick(id, action) {
+    $(id).addEventListener("click", async () => {
+        try {
+            await action();
+            showError(null);
+        } catch (err) {
+            showError(err);
+        }
+    });
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+    $("api-key").value = state.apiKey;
+    $("namespace").value = state.namespace;
+
+    $("connect").addEventListener("submit", (event) => {
+        event.preventDefault();
+        state.apiKey = $("api-key").value.trim();
+        state.namespace = $("namespace").value.trim();
+        sessionStorage.setItem("apiKey", state.apiKey);
+        sessionStorage.setItem("namespace", state.namespace);
+        state.selectedJob = null;
+        $("executions-section").hidden = true;
+        refresh();
+    });
+
+    for (const id of ["status-filter", "failed-only"]) {
+        $(id).addEventListener("change", refresh);
+    }
+    $("selector").addEventListener("keydown", (event) => {
+        if (event.key === "Enter") {
+            refresh();
+        }
+    });
+    $("failed-executions").addEventListener("change", () => selectJob(state.selectedJob));
+
+    onClick("refresh", refresh);
+    onClick("more-jobs", () => loadJobs(true));
+    onClick("more-executions", () => loadExecutions(true));
+
+    refresh();
+    setInterval(() => {
+        if (!document.hidden) {
+            refresh();
+        }
+    }, refreshInterval);
+});
@@ -0,0 +1,29 @@
+package graphql
+
+import (
+	_ "embed"
+	"net/http"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/graph-gophers/graphql-go/trace/otel"
+)
+
+const (
+	// maxDepth limits the nesting of queries, which is otherwise only bounded by the schema.
+	maxDepth = 10
+)
+
+//go:embed schema.graphql
+var schema string
+
+// NewHandler creates a http.Handler serving GraphQL queries over the job service.
+// Queries are sent as JSON POST requests with the query, operationName and variables fields.
+func NewHandler(service JobService) http.Handler {
+	parsed := gql.MustParseSchema(schema, &Resolver{service: service},
+		gql.MaxDepth(maxDepth),
+		gql.Tracer(otel.DefaultTracer()),
+	)
+
+	return &relay.Handler{Schema: parsed}
+}
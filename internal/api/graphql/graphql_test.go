@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+type mockJobService struct {
+	sync.Mutex
+	jobs            []model.Job
+	executionCalls  int
+	statsCalls      int
+	requestedLimits []uint64
+}
+
+func (m *mockJobService) GetJob(_ context.Context, id uuid.UUID) (*model.Job, error) {
+	for _, job := range m.jobs {
+		if job.ID == id {
+			return &job, nil
+		}
+	}
+
+	return nil, errs.ErrJobNotFound
+}
+
+func (m *mockJobService) ListJobs(_ context.Context, limit uint64, _ *model.Cursor, _ model.JobFilter) (*model.JobPage, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.requestedLimits = append(m.requestedLimits, limit)
+
+	return &model.JobPage{Jobs: m.jobs, Pagination: model.Pagination{Total: uint64(len(m.jobs))}}, nil
+}
+
+func (m *mockJobService) GetLatestJobExecutions(_ context.Context, jobIDs []uuid.UUID, limit uint64) (map[uuid.UUID][]*model.JobExecution, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.executionCalls++
+
+	executions := map[uuid.UUID][]*model.JobExecution{}
+	for _, id := range jobIDs {
+		for i := 0; i < int(limit); i++ {
+			executions[id] = append(executions[id], &model.JobExecution{
+				ID:        i + 1,
+				JobID:     id,
+				Status:    model.JobExecutionStatusSuccessful,
+				StartTime: time.Now(),
+				EndTime:   null.TimeFrom(time.Now()),
+			})
+		}
+	}
+
+	return executions, nil
+}
+
+func (m *mockJobService) GetJobExecutionStats(_ context.Context, jobIDs []uuid.UUID) (map[uuid.UUID]model.JobExecutionStats, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.statsCalls++
+
+	stats := map[uuid.UUID]model.JobExecutionStats{}
+	for _, id := range jobIDs {
+		stats[id] = model.JobExecutionStats{JobID: id, Total: 3, Successful: 2, Failed: 1, AverageDuration: null.FloatFrom(12.5)}
+	}
+
+	for _, job := range m.jobs {
+		if stat, ok := stats[job.ID]; ok && job.SLA != nil {
+			stat.SLA = &model.JobSLAStatus{JobID: job.ID, SLA: *job.SLA, Executions: 3, Successful: 2, EvaluatedAt: time.Now()}
+			stat.SLA.Evaluate()
+			stats[job.ID] = stat
+		}
+	}
+
+	return stats, nil
+}
+
+func query(t *testing.T, handler http.Handler, body string) string {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestGraphQL(t *testing.T) {
+	service := &mockJobService{}
+	for i := 0; i < 3; i++ {
+		service.jobs = append(service.jobs, model.Job{
+			ID:        uuid.New(),
+			Type:      model.JobTypeHTTP,
+			Status:    model.JobStatusRunning,
+			Tags:      []string{"billing"},
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+	service.jobs[0].SLA = &model.JobSLA{MinSuccessRate: null.FloatFrom(90)}
+	service.jobs[0].Metadata = map[string]string{"ticket": "OPS-1234", "cost-center": "research"}
+	handler := NewHandler(service)
+
+	t.Run("Jobs with executions and stats", func(t *testing.T) {
+		body := query(t, handler, `{"query": "{ jobs(limit: 500) { total totalEstimated jobs { id tags executions(limit: 2) { id status } stats { total failed averageDurationMs } } } }"}`)
+
+		assert.NotContains(t, body, `"errors"`)
+		assert.Contains(t, body, `"total":3`)
+		assert.Contains(t, body, `"totalEstimated":false`)
+		assert.Equal(t, 6, strings.Count(body, `"status":"SUCCESSFUL"`))
+		assert.Equal(t, 3, strings.Count(body, `"averageDurationMs":12.5`))
+
+		service.Lock()
+		defer service.Unlock()
+		// executions and stats are loaded once for the whole page
+		assert.Equal(t, 1, service.executionCalls)
+		assert.Equal(t, 1, service.statsCalls)
+		assert.Equal(t, []uint64{maxLimit}, service.requestedLimits)
+	})
+
+	t.Run("Single job", func(t *testing.T) {
+		body := query(t, handler, `{"query": "query($id: ID!) { job(id: $id) { id type } }", "variables": {"id": "`+service.jobs[0].ID.String()+`"}}`)
+		assert.Contains(t, body, service.jobs[0].ID.String())
+		assert.Contains(t, body, `"type":"HTTP"`)
+	})
+
+	t.Run("Job metadata", func(t *testing.T) {
+		body := query(t, handler, `{"query": "query($id: ID!) { job(id: $id) { metadata { key value } } }", "variables": {"id": "`+service.jobs[0].ID.String()+`"}}`)
+		assert.JSONEq(t, `{"data": {"job": {"metadata": [
+			{"key": "cost-center", "value": "research"}, {"key": "ticket", "value": "OPS-1234"}
+		]}}}`, body)
+	})
+
+	t.Run("Job with an SLA", func(t *testing.T) {
+		body := query(t, handler, `{"query": "query($id: ID!) { job(id: $id) { stats { sla { minSuccessRate maxDelay window compliant breaches } } } }", "variables": {"id": "`+service.jobs[0].ID.String()+`"}}`)
+		assert.JSONEq(t, `{"data": {"job": {"stats": {"sla": {
+			"minSuccessRate": 90, "maxDelay": null, "window": 86400, "compliant": false, "breaches": ["min_success_rate"]
+		}}}}}`, body)
+
+		body = query(t, handler, `{"query": "query($id: ID!) { job(id: $id) { stats { sla { compliant } } } }", "variables": {"id": "`+service.jobs[1].ID.String()+`"}}`)
+		assert.JSONEq(t, `{"data": {"job": {"stats": {"sla": null}}}}`, body)
+	})
+
+	t.Run("Missing job", func(t *testing.T) {
+		body := query(t, handler, `{"query": "{ job(id: \"`+uuid.NewString()+`\") { id } }"}`)
+		assert.JSONEq(t, `{"data": {"job": null}}`, body)
+	})
+
+	t.Run("Invalid query", func(t *testing.T) {
+		body := query(t, handler, `{"query": "{ jobs { unknown } }"}`)
+		assert.Contains(t, body, `"errors"`)
+	})
+}
@@ -0,0 +1,459 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	gql "github.com/graph-gophers/graphql-go"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+type JobService interface {
+	GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	ListJobs(ctx context.Context, limit uint64, cursor *model.Cursor, filter model.JobFilter) (*model.JobPage, error)
+	GetLatestJobExecutions(ctx context.Context, jobIDs []uuid.UUID, limit uint64) (map[uuid.UUID][]*model.JobExecution, error)
+	GetJobExecutionStats(ctx context.Context, jobIDs []uuid.UUID) (map[uuid.UUID]model.JobExecutionStats, error)
+}
+
+// Resolver is the root resolver of the schema.
+type Resolver struct {
+	service JobService
+}
+
+func (r *Resolver) Job(ctx context.Context, args struct{ ID gql.ID }) (*jobResolver, error) {
+	id, err := uuid.Parse(string(args.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := r.service.GetJob(ctx, id)
+	if err != nil {
+		if errors.Is(err, errs.ErrJobNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	loader := newJobLoader(r.service, []uuid.UUID{job.ID})
+	return &jobResolver{job: job, loader: loader}, nil
+}
+
+type jobsArgs struct {
+	Limit   int32
+	Cursor  *string
+	Status  *[]string
+	Type    *[]string
+	Tags    *[]string
+	AnyTags *[]string
+	Query   *string
+	Exact   bool
+}
+
+func (r *Resolver) Jobs(ctx context.Context, args jobsArgs) (*jobPageResolver, error) {
+	cursor, err := model.DecodeCursor(null.StringFromPtr(args.Cursor).ValueOrZero())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.JobFilter{
+		Query:      strings.TrimSpace(null.StringFromPtr(args.Query).ValueOrZero()),
+		ExactTotal: args.Exact,
+	}
+
+	if args.Tags != nil {
+		filter.Tags = *args.Tags
+	}
+
+	if args.AnyTags != nil {
+		filter.AnyTags = *args.AnyTags
+	}
+
+	if args.Status != nil {
+		for _, status := range *args.Status {
+			filter.Statuses = append(filter.Statuses, model.JobStatus(strings.ToUpper(status)))
+		}
+	}
+
+	if args.Type != nil {
+		for _, jobType := range *args.Type {
+			filter.Types = append(filter.Types, model.JobType(strings.ToUpper(jobType)))
+		}
+	}
+
+	page, err := r.service.ListJobs(ctx, limit(args.Limit), cursor, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// all jobs of the page share a loader, so their executions and stats are fetched with a single query
+	ids := make([]uuid.UUID, 0, len(page.Jobs))
+	for _, job := range page.Jobs {
+		ids = append(ids, job.ID)
+	}
+	loader := newJobLoader(r.service, ids)
+
+	resolver := &jobPageResolver{page: page}
+	for i := range page.Jobs {
+		resolver.jobs = append(resolver.jobs, &jobResolver{job: &page.Jobs[i], loader: loader})
+	}
+
+	return resolver, nil
+}
+
+// limit returns the requested limit, bounded to (0, maxLimit].
+func limit(requested int32) uint64 {
+	if requested <= 0 {
+		return defaultLimit
+	}
+
+	return uint64(min(requested, maxLimit))
+}
+
+type jobPageResolver struct {
+	page *model.JobPage
+	jobs []*jobResolver
+}
+
+func (r *jobPageResolver) Jobs() []*jobResolver {
+	return r.jobs
+}
+
+func (r *jobPageResolver) NextCursor() *string {
+	return r.page.NextCursor.Ptr()
+}
+
+func (r *jobPageResolver) Total() int32 {
+	return int32(r.page.Total)
+}
+
+func (r *jobPageResolver) TotalEstimated() bool {
+	return r.page.TotalEstimated
+}
+
+type jobResolver struct {
+	job    *model.Job
+	loader *jobLoader
+}
+
+func (r *jobResolver) ID() gql.ID {
+	return gql.ID(r.job.ID.String())
+}
+
+func (r *jobResolver) Namespace() string {
+	return r.job.Namespace
+}
+
+func (r *jobResolver) Name() *string {
+	return r.job.Name.Ptr()
+}
+
+func (r *jobResolver) Owner() *string {
+	return r.job.Owner.Ptr()
+}
+
+func (r *jobResolver) Team() *string {
+	return r.job.Team.Ptr()
+}
+
+func (r *jobResolver) Type() string {
+	return string(r.job.Type)
+}
+
+func (r *jobResolver) Status() string {
+	return string(r.job.Status)
+}
+
+func (r *jobResolver) ExecuteAt() *gql.Time {
+	return toTime(r.job.ExecuteAt)
+}
+
+func (r *jobResolver) CronSchedule() *string {
+	return r.job.CronSchedule.Ptr()
+}
+
+func (r *jobResolver) NextRun() *gql.Time {
+	return toTime(r.job.NextRun)
+}
+
+func (r *jobResolver) Tags() []string {
+	if r.job.Tags == nil {
+		return []string{}
+	}
+
+	return r.job.Tags
+}
+
+func (r *jobResolver) Metadata() []*metadataEntryResolver {
+	keys := make([]string, 0, len(r.job.Metadata))
+	for key := range r.job.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]*metadataEntryResolver, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, &metadataEntryResolver{key: key, value: r.job.Metadata[key]})
+	}
+
+	return entries
+}
+
+func (r *jobResolver) CreatedAt() gql.Time {
+	return gql.Time{Time: r.job.CreatedAt}
+}
+
+func (r *jobResolver) UpdatedAt() gql.Time {
+	return gql.Time{Time: r.job.UpdatedAt}
+}
+
+func (r *jobResolver) CompletedAt() *gql.Time {
+	return toTime(r.job.CompletedAt)
+}
+
+func (r *jobResolver) LastExecutionStatus() *string {
+	if r.job.LastExecutionStatus == nil {
+		return nil
+	}
+
+	status := string(*r.job.LastExecutionStatus)
+	return &status
+}
+
+func (r *jobResolver) Executions(ctx context.Context, args struct{ Limit int32 }) ([]*executionResolver, error) {
+	executions, err := r.loader.executions(ctx, limit(args.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := []*executionResolver{}
+	for _, execution := range executions[r.job.ID] {
+		resolvers = append(resolvers, &executionResolver{execution: execution})
+	}
+
+	return resolvers, nil
+}
+
+func (r *jobResolver) Stats(ctx context.Context) (*statsResolver, error) {
+	stats, err := r.loader.stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &statsResolver{stats: stats[r.job.ID]}, nil
+}
+
+type metadataEntryResolver struct {
+	key   string
+	value string
+}
+
+func (r *metadataEntryResolver) Key() string {
+	return r.key
+}
+
+func (r *metadataEntryResolver) Value() string {
+	return r.value
+}
+
+type executionResolver struct {
+	execution *model.JobExecution
+}
+
+func (r *executionResolver) ID() int32 {
+	return int32(r.execution.ID)
+}
+
+func (r *executionResolver) Status() string {
+	return string(r.execution.Status)
+}
+
+func (r *executionResolver) StartTime() gql.Time {
+	return gql.Time{Time: r.execution.StartTime}
+}
+
+func (r *executionResolver) EndTime() *gql.Time {
+	return toTime(r.execution.EndTime)
+}
+
+func (r *executionResolver) ScheduledTime() *gql.Time {
+	return toTime(r.execution.ScheduledTime)
+}
+
+func (r *executionResolver) DriftMs() *float64 {
+	if !r.execution.Drift.Valid {
+		return nil
+	}
+
+	drift := float64(r.execution.Drift.Int64)
+	return &drift
+}
+
+func (r *executionResolver) ErrorMessage() *string {
+	return r.execution.ErrorMessage.Ptr()
+}
+
+type statsResolver struct {
+	stats model.JobExecutionStats
+}
+
+func (r *statsResolver) Total() int32 {
+	return int32(r.stats.Total)
+}
+
+func (r *statsResolver) Successful() int32 {
+	return int32(r.stats.Successful)
+}
+
+func (r *statsResolver) Failed() int32 {
+	return int32(r.stats.Failed)
+}
+
+func (r *statsResolver) Cancelled() int32 {
+	return int32(r.stats.Cancelled)
+}
+
+func (r *statsResolver) TimedOut() int32 {
+	return int32(r.stats.TimedOut)
+}
+
+func (r *statsResolver) AverageDurationMs() *float64 {
+	return r.stats.AverageDuration.Ptr()
+}
+
+func (r *statsResolver) LastExecutionAt() *gql.Time {
+	return toTime(r.stats.LastExecutionAt)
+}
+
+func (r *statsResolver) SLA() *slaStatusResolver {
+	if r.stats.SLA == nil {
+		return nil
+	}
+
+	return &slaStatusResolver{status: r.stats.SLA}
+}
+
+type slaStatusResolver struct {
+	status *model.JobSLAStatus
+}
+
+func (r *slaStatusResolver) MaxDelay() *int32 {
+	if !r.status.SLA.MaxDelay.Valid {
+		return nil
+	}
+
+	maxDelay := int32(r.status.SLA.MaxDelay.Int64)
+	return &maxDelay
+}
+
+func (r *slaStatusResolver) MinSuccessRate() *float64 {
+	return r.status.SLA.MinSuccessRate.Ptr()
+}
+
+func (r *slaStatusResolver) Window() int32 {
+	return int32(r.status.SLA.WindowDuration().Seconds())
+}
+
+func (r *slaStatusResolver) Executions() int32 {
+	return int32(r.status.Executions)
+}
+
+func (r *slaStatusResolver) Successful() int32 {
+	return int32(r.status.Successful)
+}
+
+func (r *slaStatusResolver) Late() int32 {
+	return int32(r.status.Late)
+}
+
+func (r *slaStatusResolver) SuccessRate() *float64 {
+	return r.status.SuccessRate.Ptr()
+}
+
+func (r *slaStatusResolver) Compliant() bool {
+	return r.status.Compliant
+}
+
+func (r *slaStatusResolver) Breaches() []string {
+	breaches := make([]string, 0, len(r.status.Breaches))
+	for _, breach := range r.status.Breaches {
+		breaches = append(breaches, string(breach))
+	}
+
+	return breaches
+}
+
+func (r *slaStatusResolver) EvaluatedAt() gql.Time {
+	return gql.Time{Time: r.status.EvaluatedAt}
+}
+
+func toTime(t null.Time) *gql.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &gql.Time{Time: t.Time}
+}
+
+// jobLoader loads the executions and stats of a set of jobs on first use, so resolving them
+// for every job of a page takes a single query instead of one per job.
+type jobLoader struct {
+	service JobService
+	ids     []uuid.UUID
+
+	mu              sync.Mutex
+	executionsByJob map[uint64]map[uuid.UUID][]*model.JobExecution
+	statsByJob      map[uuid.UUID]model.JobExecutionStats
+}
+
+func newJobLoader(service JobService, ids []uuid.UUID) *jobLoader {
+	return &jobLoader{
+		service:         service,
+		ids:             ids,
+		executionsByJob: map[uint64]map[uuid.UUID][]*model.JobExecution{},
+	}
+}
+
+func (l *jobLoader) executions(ctx context.Context, limit uint64) (map[uuid.UUID][]*model.JobExecution, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if executions, ok := l.executionsByJob[limit]; ok {
+		return executions, nil
+	}
+
+	executions, err := l.service.GetLatestJobExecutions(ctx, l.ids, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	l.executionsByJob[limit] = executions
+	return executions, nil
+}
+
+func (l *jobLoader) stats(ctx context.Context) (map[uuid.UUID]model.JobExecutionStats, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.statsByJob != nil {
+		return l.statsByJob, nil
+	}
+
+	stats, err := l.service.GetJobExecutionStats(ctx, l.ids)
+	if err != nil {
+		return nil, err
+	}
+
+	l.statsByJob = stats
+	return stats, nil
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	apiKeyService "github.com/TimeSnap/distributed-scheduler/internal/service/apikey"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func APIKeysRoutesV1(router *gin.Engine, apiKeysHandler *APIKeys) {
+	apiKeysRouter := router.Group("/v1/admin/api-keys", RequireAdmin())
+	{
+		apiKeysRouter.POST("", apiKeysHandler.CreateAPIKey())
+		apiKeysRouter.GET("", apiKeysHandler.ListAPIKeys())
+		apiKeysRouter.DELETE("/:id", apiKeysHandler.RevokeAPIKey())
+	}
+}
+
+func NewAPIKeysHandler(service *apiKeyService.Service) *APIKeys {
+	return &APIKeys{
+		service: service,
+	}
+}
+
+type APIKeys struct {
+	service *apiKeyService.Service
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key. The key is only returned in this response, store it securely. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body model.APIKeyCreate true "API key"
+// @Success 201 {object} model.CreatedAPIKey
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/api-keys [post]
+func (a *APIKeys) CreateAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.APIKeyCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		key, err := a.service.CreateAPIKey(ctx.Request.Context(), create)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, key)
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List all API keys, including the revoked ones. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.APIKey
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/api-keys [get]
+func (a *APIKeys) ListAPIKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		keys, err := a.service.ListAPIKeys(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, keys)
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key with the given ID. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/api-keys/{id} [delete]
+func (a *APIKeys) RevokeAPIKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := a.service.RevokeAPIKey(ctx.Request.Context(), id); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	apiKeyService "github.com/TimeSnap/distributed-scheduler/internal/service/apikey"
+	"github.com/gin-gonic/gin"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyHeader     = "X-API-Key"
+	apiKeyContextKey = "apiKey"
+
+	// protectedPrefix is the prefix of the authenticated routes. Health checks and the
+	// OpenAPI documentation are served without authentication.
+	protectedPrefix = "/v1/"
+)
+
+type AuthConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+}
+
+// Authenticate rejects requests without a valid API key, passed either in the X-API-Key header
+// or as a bearer token. Every request is attributed to its key in the audit log.
+func Authenticate(service *apiKeyService.Service, log *otelzap.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !strings.HasPrefix(ctx.Request.URL.Path, protectedPrefix) {
+			ctx.Next()
+			return
+		}
+
+		key := ctx.GetHeader(apiKeyHeader)
+		if key == "" {
+			key, _ = strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		}
+
+		apiKey, err := service.Authenticate(ctx.Request.Context(), key)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.AbortWithStatusJSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Set(apiKeyContextKey, apiKey)
+		ctx.Next()
+
+		log.Ctx(ctx.Request.Context()).Info("Audit",
+			zap.String("api_key_id", apiKey.ID.String()),
+			zap.String("api_key_name", apiKey.Name),
+			zap.String("method", ctx.Request.Method),
+			zap.String("path", ctx.Request.URL.Path),
+			zap.Int("status", ctx.Writer.Status()),
+		)
+	}
+}
+
+// RequireAdmin rejects requests authenticated with a non-admin API key.
+// Requests pass through when authentication is disabled.
+func RequireAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, ok := ctx.Get(apiKeyContextKey)
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		if apiKey, ok := value.(*model.APIKey); !ok || !apiKey.Admin {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: errors.ErrForbidden.Error()})
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,187 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	apiKeyService "github.com/TimeSnap/distributed-scheduler/internal/service/apikey"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+type mockAPIKeyStore struct {
+	keys map[string]*model.APIKey
+}
+
+func (m *mockAPIKeyStore) CreateAPIKey(_ context.Context, key *model.APIKey, keyHash string) error {
+	m.keys[keyHash] = key
+	return nil
+}
+
+func (m *mockAPIKeyStore) ListAPIKeys(_ context.Context) ([]model.APIKey, error) {
+	keys := []model.APIKey{}
+	for _, key := range m.keys {
+		keys = append(keys, *key)
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyStore) RevokeAPIKey(_ context.Context, id uuid.UUID) error {
+	for hash, key := range m.keys {
+		if key.ID == id {
+			delete(m.keys, hash)
+			return nil
+		}
+	}
+	return errs.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyStore) GetAPIKeyByHash(_ context.Context, keyHash string) (*model.APIKey, error) {
+	key, ok := m.keys[keyHash]
+	if !ok {
+		return nil, errs.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	zapL, _ := zap.NewDevelopment()
+	log := otelzap.New(zapL)
+
+	service := apiKeyService.NewService(&mockAPIKeyStore{keys: map[string]*model.APIKey{}}, log)
+	adminKey, err := service.CreateAPIKey(context.Background(), &model.APIKeyCreate{Name: "admin", Admin: true})
+	require.NoError(t, err)
+	userKey, err := service.CreateAPIKey(context.Background(), &model.APIKeyCreate{Name: "user"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Authenticate(service, log))
+	router.GET("/v1/jobs", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	router.GET("/healthz", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	APIKeysRoutesV1(router, NewAPIKeysHandler(service))
+
+	tests := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{"Missing key", "/v1/jobs", nil, http.StatusUnauthorized},
+		{"Invalid key", "/v1/jobs", map[string]string{apiKeyHeader: "dsk_invalid"}, http.StatusUnauthorized},
+		{"Key header", "/v1/jobs", map[string]string{apiKeyHeader: userKey.Key}, http.StatusOK},
+		{"Bearer token", "/v1/jobs", map[string]string{"Authorization": "Bearer " + userKey.Key}, http.StatusOK},
+		{"Unprotected route", "/healthz", nil, http.StatusOK},
+		{"Admin route with user key", "/v1/admin/api-keys", map[string]string{apiKeyHeader: userKey.Key}, http.StatusForbidden},
+		{"Admin route with admin key", "/v1/admin/api-keys", map[string]string{apiKeyHeader: adminKey.Key}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+
+	t.Run("Namespaces", func(t *testing.T) {
+		tenantKey, err := service.CreateAPIKey(context.Background(), &model.APIKeyCreate{Name: "tenant", Namespace: "tenant"})
+		require.NoError(t, err)
+
+		router := gin.New()
+		router.Use(Authenticate(service, log), Namespace())
+		router.GET("/v1/jobs", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, model.NamespaceFromContext(ctx.Request.Context()))
+		})
+
+		tests := []struct {
+			name              string
+			key               string
+			namespace         string
+			expectedStatus    int
+			expectedNamespace string
+		}{
+			{"Namespace of the key", tenantKey.Key, "", http.StatusOK, "tenant"},
+			{"Own namespace", tenantKey.Key, "tenant", http.StatusOK, "tenant"},
+			{"Other namespace", tenantKey.Key, "default", http.StatusForbidden, ""},
+			{"Admin in other namespace", adminKey.Key, "tenant", http.StatusOK, "tenant"},
+			{"Invalid namespace", adminKey.Key, "Not_Valid", http.StatusBadRequest, ""},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/v1/jobs", nil)
+				req.Header.Set(apiKeyHeader, tt.key)
+				if tt.namespace != "" {
+					req.Header.Set(namespaceHeader, tt.namespace)
+				}
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				if tt.expectedNamespace != "" {
+					assert.Equal(t, tt.expectedNamespace, rec.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("Teams", func(t *testing.T) {
+		teamKey, err := service.CreateAPIKey(context.Background(), &model.APIKeyCreate{Name: "ci", Team: null.StringFrom("payments")})
+		require.NoError(t, err)
+		teamAdminKey, err := service.CreateAPIKey(context.Background(), &model.APIKeyCreate{Name: "ops", Admin: true, Team: null.StringFrom("payments")})
+		require.NoError(t, err)
+
+		router := gin.New()
+		router.Use(Authenticate(service, log), Namespace())
+		router.GET("/v1/jobs", func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, model.PrincipalFromContext(ctx.Request.Context()))
+		})
+
+		tests := []struct {
+			name     string
+			key      string
+			expected model.Principal
+		}{
+			{"Key of a team", teamKey.Key, model.Principal{Name: "ci", Team: "payments", Restricted: true}},
+			{"Admin key of a team", teamAdminKey.Key, model.Principal{Name: "ops", Team: "payments"}},
+			{"Key without a team", userKey.Key, model.Principal{Name: "user"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, "/v1/jobs", nil)
+				req.Header.Set(apiKeyHeader, tt.key)
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var principal model.Principal
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &principal))
+				assert.Equal(t, tt.expected, principal)
+			})
+		}
+	})
+
+	t.Run("Only the hash is stored", func(t *testing.T) {
+		_, err := service.Authenticate(context.Background(), security.HashAPIKey(userKey.Key))
+		assert.ErrorIs(t, err, errs.ErrUnauthorized)
+	})
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	instanceService "github.com/TimeSnap/distributed-scheduler/internal/service/instance"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+)
+
+func BacklogRoutesV1(router *gin.Engine, backlogHandler *Backlog) {
+	router.GET("/v1/backlog", RequireAdmin(), backlogHandler.GetBacklog())
+}
+
+func NewBacklogHandler(jobs *jobService.Service, instances *instanceService.Service) *Backlog {
+	return &Backlog{
+		jobs:      jobs,
+		instances: instances,
+	}
+}
+
+type Backlog struct {
+	jobs      *jobService.Service
+	instances *instanceService.Service
+}
+
+// GetBacklog godoc
+// @Summary Get the backlog of the runners
+// @Description Get the jobs that are due but not locked by any runner, how long the oldest of them waits for a
+// @Description runner, the pending retries and running executions, and the capacity of the live runners, as flat
+// @Description numbers to autoscale the runners on, e.g. with the KEDA metrics-api scaler. Requires an admin key.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} model.Backlog
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /backlog [get]
+func (b *Backlog) GetBacklog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		backlog, err := b.Observe(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, backlog)
+	}
+}
+
+// Observe returns the current backlog of the runners.
+func (b *Backlog) Observe(ctx context.Context) (*model.Backlog, error) {
+	now := time.Now()
+	counts, err := b.jobs.GetBacklog(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := b.instances.ListInstances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backlog := model.NewBacklog(now, *counts, instances)
+	return &backlog, nil
+}
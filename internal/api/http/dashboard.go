@@ -0,0 +1,33 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/api/dashboard"
+	"github.com/gin-gonic/gin"
+)
+
+const dashboardPath = "/dashboard"
+
+type DashboardConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+}
+
+// DashboardRoute serves the built-in web dashboard on /dashboard, if enabled. The assets are served without
+// authentication, the dashboard authenticates its API calls with the API key entered by the user.
+func DashboardRoute(cfg DashboardConfig, router *gin.Engine) {
+	if !cfg.Enabled {
+		return
+	}
+
+	router.GET(dashboardPath, func(ctx *gin.Context) {
+		ctx.Redirect(http.StatusMovedPermanently, dashboardPath+"/")
+	})
+
+	dashboardRouter := router.Group(dashboardPath, func(ctx *gin.Context) {
+		ctx.Header("Content-Security-Policy", dashboard.ContentSecurityPolicy)
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Next()
+	})
+	dashboardRouter.StaticFS("/", dashboard.FileSystem())
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/api/dashboard"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardRoute(t *testing.T) {
+	newRouter := func(cfg DashboardConfig) *gin.Engine {
+		router := gin.New()
+		DashboardRoute(cfg, router)
+		return router
+	}
+
+	tests := []struct {
+		name           string
+		cfg            DashboardConfig
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Disabled", cfg: DashboardConfig{}, path: "/dashboard/", expectedStatus: http.StatusNotFound},
+		{name: "Redirect", cfg: DashboardConfig{Enabled: true}, path: "/dashboard", expectedStatus: http.StatusMovedPermanently},
+		{name: "Index", cfg: DashboardConfig{Enabled: true}, path: "/dashboard/", expectedStatus: http.StatusOK, expectedBody: "<title>Scheduler Dashboard</title>"},
+		{name: "Script", cfg: DashboardConfig{Enabled: true}, path: "/dashboard/app.js", expectedStatus: http.StatusOK, expectedBody: "/jobs/bulk/"},
+		{name: "Missing asset", cfg: DashboardConfig{Enabled: true}, path: "/dashboard/missing.js", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			newRouter(tt.cfg).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), tt.expectedBody)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, dashboard.ContentSecurityPolicy, recorder.Header().Get("Content-Security-Policy"))
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/events"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// keepAliveInterval is how often a comment is sent on idle event streams, to keep proxies from closing them.
+const keepAliveInterval = time.Second * 15
+
+func EventsRoutesV1(router *gin.Engine, eventsHandler *Events) {
+	eventsRouter := router.Group("/v1/events")
+	{
+		eventsRouter.GET("", eventsHandler.StreamEvents())
+	}
+}
+
+func NewEventsHandler(broker *events.Broker) *Events {
+	return &Events{
+		broker: broker,
+	}
+}
+
+type Events struct {
+	broker *events.Broker
+}
+
+// StreamEvents godoc
+// @Summary Stream job lifecycle events
+// @Description Stream job and execution lifecycle events as server-sent events. Each event is named after its type. API keys bound to a team only receive the events of the jobs of their team.
+// @Tags events
+// @Produce text/event-stream
+// @Param type query array false "Event types, e.g. job.created or execution.failed"
+// @Param job query array false "Job IDs, events must be for one of them"
+// @Param tags query array false "Tags, events must be for jobs with all of them"
+// @Success 200 {object} model.Event
+// @Failure 400 {object} ErrorResponse
+// @Router /events [get]
+func (e *Events) StreamEvents() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		filter := model.EventFilter{
+			Namespace: model.NamespaceFromContext(ctx.Request.Context()),
+			Team:      model.PrincipalFromContext(ctx.Request.Context()).TeamScope(),
+			Tags:      ctx.QueryArray("tags"),
+		}
+		for _, eventType := range ctx.QueryArray("type") {
+			filter.Types = append(filter.Types, model.EventType(eventType))
+		}
+		for _, jobID := range ctx.QueryArray("job") {
+			id, err := uuid.Parse(jobID)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+				return
+			}
+			filter.JobIDs = append(filter.JobIDs, id)
+		}
+
+		if err := filter.Validate(); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		subscription, unsubscribe := e.broker.Subscribe(filter)
+		defer unsubscribe()
+
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		// Disable response buffering in nginx
+		ctx.Header("X-Accel-Buffering", "no")
+
+		ctx.Stream(func(w io.Writer) bool {
+			select {
+			case event := <-subscription:
+				ctx.SSEvent(string(event.Type), event)
+				return true
+			case <-keepAlive.C:
+				_, err := io.WriteString(w, ": keep-alive\n\n")
+				return err == nil
+			case <-ctx.Request.Context().Done():
+				return false
+			}
+		})
+	}
+}
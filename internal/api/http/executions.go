@@ -0,0 +1,137 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+)
+
+func ExecutionsRoutesV1(router *gin.Engine, executionsHandler *Executions) {
+	executionsRouter := router.Group("/v1/executions")
+	{
+		executionsRouter.POST("/:id/cancel", executionsHandler.CancelExecution())
+		executionsRouter.POST("/:id/retry", executionsHandler.RetryExecution())
+		executionsRouter.GET("/:id/events", executionsHandler.GetExecutionEvents())
+	}
+}
+
+func NewExecutionsHandler(service *jobService.Service) *Executions {
+	return &Executions{
+		service: service,
+	}
+}
+
+type Executions struct {
+	service *jobService.Service
+}
+
+// CancelExecution godoc
+// @Summary Cancel a job execution
+// @Description Request the cancellation of a running job execution. The runner executing it cancels the execution
+// @Description once it notices the request, and records it with the CANCELLED status.
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path int true "Execution ID"
+// @Success 202
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /executions/{id}/cancel [post]
+func (e *Executions) CancelExecution() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := e.service.CancelJobExecution(ctx.Request.Context(), id); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// RetryExecution godoc
+// @Summary Retry a job execution
+// @Description Schedule a new execution of the job of a past execution, with either the job definition the execution
+// @Description ran with (default) or the current one. The new execution is PENDING until a runner picks it up, and links
+// @Description to the retried execution with retry_of. Retrying doesn't change the schedule of the job.
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path int true "Execution ID"
+// @Param definition query string false "Job definition to run with" Enums(original, current)
+// @Success 202 {object} model.JobExecution
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /executions/{id}/retry [post]
+func (e *Executions) RetryExecution() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		definition := model.RetryDefinition(ctx.DefaultQuery("definition", string(model.RetryDefinitionOriginal)))
+
+		execution, err := e.service.RetryJobExecution(ctx.Request.Context(), id, definition)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, execution)
+	}
+}
+
+// GetExecutionEvents godoc
+// @Summary Get the timeline of a job execution
+// @Description Get the events of a job execution, oldest first: queued, claimed by a runner, started, every failed
+// @Description attempt and retry of the executor, cancellation requested, and finished with its status.
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path int true "Execution ID"
+// @Success 200 {array} model.ExecutionEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /executions/{id}/events [get]
+func (e *Executions) GetExecutionEvents() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := strconv.Atoi(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		events, err := e.service.GetJobExecutionEvents(ctx.Request.Context(), id)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, events)
+	}
+}
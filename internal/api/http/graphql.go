@@ -0,0 +1,20 @@
+package http
+
+import (
+	"github.com/TimeSnap/distributed-scheduler/internal/api/graphql"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+}
+
+// GraphQLRoute mounts the GraphQL endpoint, if enabled.
+func GraphQLRoute(cfg GraphQLConfig, router *gin.Engine, service *jobService.Service) {
+	if !cfg.Enabled {
+		return
+	}
+
+	router.POST("/v1/graphql", gin.WrapH(graphql.NewHandler(service)))
+}
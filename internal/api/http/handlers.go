@@ -1,38 +1,136 @@
 package http
 
 import (
+	"github.com/TimeSnap/distributed-scheduler/internal/events"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/apikey"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/instance"
 	"github.com/TimeSnap/distributed-scheduler/internal/service/job"
-	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/notification"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/webhook"
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/xBlaz3kx/DevX/observability"
 )
 
 // APIMuxConfig contains all the mandatory systems required by handlers.
 type APIMuxConfig struct {
-	Log     *otelzap.Logger
-	DB      *sqlx.DB
-	OpenApi OpenApiConfig
+	Log *otelzap.Logger
+	DB  *sqlx.DB
+	// Store is the storage backend of the services
+	Store     *store.Backend
+	OpenApi   OpenApiConfig
+	Events    *events.Broker
+	GraphQL   GraphQLConfig
+	Dashboard DashboardConfig
+	Auth      AuthConfig
+	Readiness ReadinessConfig
+	CORS      CORSConfig
+	Security  SecurityHeadersConfig
+	Limits    LimitsConfig
+	Metrics   observability.MetricsConfig
 }
 
 // Api constructs a http.Handler with all application routes defined.
 func Api(router *gin.Engine, cfg APIMuxConfig) {
+	// ==================
+	// Security headers and CORS (CORS only applies if origins are allowed)
+	if cfg.Security.Enabled {
+		router.Use(SecurityHeaders(cfg.Security))
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		router.Use(CORS(cfg.CORS))
+	}
+
+	// ==================
+	// Request size limits (the payload limits of jobs are enforced when validating them)
+	router.Use(LimitRequestBody(cfg.Limits.MaxRequestBodySize))
+
 	// ==================
 	// OpenAPI (will only mount if enabled)
 	OpenApiRoute(cfg.OpenApi, router)
 
 	// ==================
-	// Jobs
+	// Web dashboard (will only mount if enabled)
+	DashboardRoute(cfg.Dashboard, router)
 
-	// Create a new PostgresSQL job store
-	jobStore := postgres.New(cfg.DB, cfg.Log)
+	// ==================
+	// Authentication (applies to all /v1 routes)
+	apiKeyService := apikey.NewService(cfg.Store.APIKeys, cfg.Log)
+	if cfg.Auth.Enabled {
+		router.Use(Authenticate(apiKeyService, cfg.Log))
+	}
+
+	// ==================
+	// Namespaces (applies to all /v1 routes, scoping them to the namespace of the request)
+	router.Use(Namespace())
+
+	// ==================
+	// API keys
+	APIKeysRoutesV1(router, NewAPIKeysHandler(apiKeyService))
+
+	// ==================
+	// Jobs
 
 	// Create a new job service with the job store and logger
-	jobService := job.NewService(jobStore, cfg.Log)
+	jobService := job.NewService(cfg.Store.Jobs, cfg.Log)
 
 	// Create a new jobs handler with the job service
 	jobsHandler := NewJobsHandler(jobService)
 
 	// Define a group of routes for the jobs endpoint
 	JobsRoutesV1(router, jobsHandler)
+
+	// ==================
+	// Tags
+	TagsRoutesV1(router, NewTagsHandler(jobService))
+
+	// ==================
+	// Job templates
+	TemplatesRoutesV1(router, NewTemplatesHandler(jobService))
+
+	// ==================
+	// Namespace quotas
+	NamespacesRoutesV1(router, NewNamespacesHandler(jobService))
+
+	// ==================
+	// Runner instances
+	instanceService := instance.NewService(cfg.Store.Instances, cfg.Log)
+	InstancesRoutesV1(router, NewInstancesHandler(instanceService))
+
+	// ==================
+	// Backlog of the runners, also exported as metrics to autoscale the runners on
+	backlogHandler := NewBacklogHandler(jobService, instanceService)
+	BacklogRoutesV1(router, backlogHandler)
+	metrics.RegisterBacklogMetrics(cfg.Metrics, backlogHandler.Observe)
+
+	// ==================
+	// Readiness
+	HealthRoutes(router, NewHealthHandler(cfg.Readiness, cfg.DB, jobService, instanceService))
+
+	// ==================
+	// GraphQL (will only mount if enabled)
+	GraphQLRoute(cfg.GraphQL, router, jobService)
+
+	// ==================
+	// Executions
+	ExecutionsRoutesV1(router, NewExecutionsHandler(jobService))
+
+	// ==================
+	// Events
+	EventsRoutesV1(router, NewEventsHandler(cfg.Events))
+
+	// ==================
+	// Webhooks
+	WebhooksRoutesV1(router, NewWebhooksHandler(webhook.NewService(cfg.Store.Webhooks, cfg.Log)))
+
+	// ==================
+	// Notifications
+	NotificationRulesRoutesV1(router, NewNotificationRulesHandler(notification.NewService(cfg.Store.Notifications, cfg.Store.Jobs, cfg.Log)))
+
+	// ==================
+	// Schedules
+	ScheduleRoutesV1(router, NewScheduleHandler())
 }
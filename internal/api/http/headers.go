@@ -0,0 +1,123 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig lets browser-based dashboards call the API from other origins. CORS is disabled unless origins are allowed.
+type CORSConfig struct {
+	// Origins allowed to call the API, e.g. https://dashboard.example.com, or * for any origin
+	AllowedOrigins []string `mapstructure:"allowedOrigins" yaml:"allowedOrigins" json:"allowedOrigins"`
+	AllowedMethods []string `mapstructure:"allowedMethods" yaml:"allowedMethods" json:"allowedMethods"`
+	AllowedHeaders []string `mapstructure:"allowedHeaders" yaml:"allowedHeaders" json:"allowedHeaders"`
+	// Response headers readable by the browser, such as the ETag of jobs
+	ExposedHeaders []string `mapstructure:"exposedHeaders" yaml:"exposedHeaders" json:"exposedHeaders"`
+	// Whether requests can include cookies or HTTP authentication, only honored for explicitly allowed origins
+	AllowCredentials bool `mapstructure:"allowCredentials" yaml:"allowCredentials" json:"allowCredentials"`
+	// How long browsers can cache the result of preflight requests
+	MaxAge time.Duration `mapstructure:"maxAge" yaml:"maxAge" json:"maxAge"`
+}
+
+// SecurityHeadersConfig configures the security headers added to every response.
+type SecurityHeadersConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Content-Security-Policy of the API responses. The OpenAPI documentation is served without it, since it loads scripts.
+	ContentSecurityPolicy string `mapstructure:"contentSecurityPolicy" yaml:"contentSecurityPolicy" json:"contentSecurityPolicy"`
+	// Max age of the Strict-Transport-Security header, which is only sent if positive, i.e. when served over HTTPS
+	HSTSMaxAge time.Duration `mapstructure:"hstsMaxAge" yaml:"hstsMaxAge" json:"hstsMaxAge"`
+}
+
+// CORS answers preflight requests and adds the CORS headers to the responses to requests from allowed origins.
+// Preflight requests are answered before authentication, since browsers send them without credentials.
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	anyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		ctx.Writer.Header().Add("Vary", "Origin")
+
+		explicitlyAllowed := slices.Contains(cfg.AllowedOrigins, origin)
+		if !anyOrigin && !explicitlyAllowed {
+			// let the browser block the response, but reject preflight requests right away
+			if isPreflight(ctx.Request) {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			ctx.Next()
+			return
+		}
+
+		headers := ctx.Writer.Header()
+		if explicitlyAllowed {
+			headers.Set("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				headers.Set("Access-Control-Allow-Credentials", "true")
+			}
+		} else {
+			headers.Set("Access-Control-Allow-Origin", "*")
+		}
+
+		if !isPreflight(ctx.Request) {
+			if exposedHeaders != "" {
+				headers.Set("Access-Control-Expose-Headers", exposedHeaders)
+			}
+			ctx.Next()
+			return
+		}
+
+		headers.Add("Vary", "Access-Control-Request-Method")
+		headers.Add("Vary", "Access-Control-Request-Headers")
+		headers.Set("Access-Control-Allow-Methods", allowedMethods)
+		headers.Set("Access-Control-Allow-Headers", allowedHeaders)
+		if cfg.MaxAge > 0 {
+			headers.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+		}
+
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// isPreflight reports whether the request is a CORS preflight request.
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// SecurityHeaders adds standard security headers to the responses: no MIME type sniffing, no framing, no referrer,
+// and, for the API routes, a restrictive content security policy.
+func SecurityHeaders(cfg SecurityHeadersConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds())) + "; includeSubDomains"
+	}
+
+	return func(ctx *gin.Context) {
+		headers := ctx.Writer.Header()
+		headers.Set("X-Content-Type-Options", "nosniff")
+		headers.Set("X-Frame-Options", "DENY")
+		headers.Set("Referrer-Policy", "no-referrer")
+
+		if cfg.ContentSecurityPolicy != "" && strings.HasPrefix(ctx.Request.URL.Path, protectedPrefix) {
+			headers.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+
+		if hsts != "" {
+			headers.Set("Strict-Transport-Security", hsts)
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	newRouter := func(cfg CORSConfig) *gin.Engine {
+		router := gin.New()
+		router.Use(CORS(cfg))
+		router.GET("/v1/jobs", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+		return router
+	}
+
+	cfg := CORSConfig{
+		AllowedOrigins:   []string{"https://dashboard.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "X-API-Key"},
+		ExposedHeaders:   []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+
+	tests := []struct {
+		name            string
+		cfg             CORSConfig
+		method          string
+		origin          string
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "Same origin", cfg: cfg, method: http.MethodGet, origin: "",
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "Allowed origin", cfg: cfg, method: http.MethodGet, origin: "https://dashboard.example.com",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://dashboard.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "ETag",
+			},
+		},
+		{
+			name: "Other origin", cfg: cfg, method: http.MethodGet, origin: "https://evil.example.com",
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "Preflight", cfg: cfg, method: http.MethodOptions, origin: "https://dashboard.example.com",
+			expectedStatus: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://dashboard.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, X-API-Key",
+				"Access-Control-Max-Age":       "3600",
+			},
+		},
+		{
+			name: "Preflight from other origin", cfg: cfg, method: http.MethodOptions, origin: "https://evil.example.com",
+			expectedStatus:  http.StatusForbidden,
+			expectedHeaders: map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			name: "Any origin", cfg: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, method: http.MethodGet, origin: "https://other.example.com",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/jobs", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			rec := httptest.NewRecorder()
+			newRouter(tt.cfg).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			for header, value := range tt.expectedHeaders {
+				assert.Equal(t, value, rec.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	router := gin.New()
+	router.Use(SecurityHeaders(SecurityHeadersConfig{Enabled: true, ContentSecurityPolicy: "default-src 'none'", HSTSMaxAge: 24 * time.Hour}))
+	router.GET("/v1/jobs", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	router.GET("/swagger/index.html", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/jobs", nil))
+
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", rec.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "default-src 'none'", rec.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "max-age=86400; includeSubDomains", rec.Header().Get("Strict-Transport-Security"))
+
+	// the OpenAPI documentation loads scripts and styles
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil))
+	assert.Empty(t, rec.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+}
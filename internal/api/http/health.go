@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	instanceService "github.com/TimeSnap/distributed-scheduler/internal/service/instance"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// readinessTimeout bounds the time spent checking the readiness, so probes don't pile up on a slow database.
+const readinessTimeout = 5 * time.Second
+
+type ReadinessConfig struct {
+	// MaxSchedulerLag is the age of the oldest due job above which the scheduler is not ready (0 disables the check).
+	MaxSchedulerLag time.Duration `mapstructure:"maxSchedulerLag" yaml:"maxSchedulerLag" json:"maxSchedulerLag"`
+}
+
+// HealthRoutes mounts the readiness endpoint, next to the /healthz liveness endpoint. It is not versioned, and
+// doesn't require authentication.
+func HealthRoutes(router *gin.Engine, healthHandler *Health) {
+	router.GET("/readyz", healthHandler.Ready())
+}
+
+func NewHealthHandler(cfg ReadinessConfig, db *sqlx.DB, jobs *jobService.Service, instances *instanceService.Service) *Health {
+	return &Health{
+		cfg:       cfg,
+		db:        db,
+		jobs:      jobs,
+		instances: instances,
+	}
+}
+
+type Health struct {
+	cfg       ReadinessConfig
+	db        *sqlx.DB
+	jobs      *jobService.Service
+	instances *instanceService.Service
+}
+
+// Ready godoc
+// @Summary Readiness check
+// @Description Report whether the scheduler is keeping up: database connectivity and latency, the age of the oldest
+// @Description job that is due but not picked up by any runner, and the freshness of the heartbeats of the runners.
+// @Description Responds with 503 if the database is unreachable or the scheduler lags behind.
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.Readiness
+// @Failure 503 {object} model.Readiness
+// @Router /readyz [get]
+func (h *Health) Ready() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		readiness := model.Readiness{Runners: []model.RunnerStatus{}}
+
+		start := time.Now()
+		if err := database.StatusCheck(reqCtx, h.db); err != nil {
+			readiness.Database.Error = err.Error()
+			ctx.JSON(http.StatusServiceUnavailable, readiness)
+			return
+		}
+		readiness.Database = model.DatabaseStatus{
+			Connected: true,
+			LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		}
+
+		now := time.Now()
+		scheduler, err := h.jobs.GetSchedulerStatus(reqCtx, now, h.cfg.MaxSchedulerLag)
+		if err != nil {
+			readiness.Database.Error = err.Error()
+			ctx.JSON(http.StatusServiceUnavailable, readiness)
+			return
+		}
+		readiness.Scheduler = *scheduler
+
+		instances, err := h.instances.ListInstances(reqCtx)
+		if err != nil {
+			readiness.Database.Error = err.Error()
+			ctx.JSON(http.StatusServiceUnavailable, readiness)
+			return
+		}
+
+		for _, instance := range instances {
+			readiness.Runners = append(readiness.Runners, model.NewRunnerStatus(now, instance))
+		}
+
+		readiness.Ready = scheduler.KeepingUp
+		if !readiness.Ready {
+			ctx.JSON(http.StatusServiceUnavailable, readiness)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, readiness)
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	instanceService "github.com/TimeSnap/distributed-scheduler/internal/service/instance"
+	"github.com/gin-gonic/gin"
+)
+
+func InstancesRoutesV1(router *gin.Engine, instancesHandler *Instances) {
+	instancesRouter := router.Group("/v1/instances", RequireAdmin())
+	{
+		instancesRouter.GET("", instancesHandler.ListInstances())
+	}
+}
+
+func NewInstancesHandler(service *instanceService.Service) *Instances {
+	return &Instances{
+		service: service,
+	}
+}
+
+type Instances struct {
+	service *instanceService.Service
+}
+
+// ListInstances godoc
+// @Summary List runner instances
+// @Description List the runners registered in the instance registry, with their capacity and current load.
+// @Description Runners that stopped sending heartbeats are listed as not alive for a day. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.Instance
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /instances [get]
+func (i *Instances) ListInstances() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		instances, err := i.service.ListInstances(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, instances)
+	}
+}
@@ -1,14 +1,21 @@
 package http
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
 	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
 	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 )
 
 func JobsRoutesV1(router *gin.Engine, jobsHandler *Jobs) {
@@ -17,9 +24,33 @@ func JobsRoutesV1(router *gin.Engine, jobsHandler *Jobs) {
 		jobsRouter.POST("", jobsHandler.CreateJob())
 		jobsRouter.GET("/:id", jobsHandler.GetJob())
 		jobsRouter.PUT("/:id", jobsHandler.UpdateJob())
+		jobsRouter.PATCH("/:id", jobsHandler.PatchJob())
 		jobsRouter.DELETE("/:id", jobsHandler.DeleteJob())
 		jobsRouter.GET("", jobsHandler.ListJobs())
+		jobsRouter.GET("/search", jobsHandler.SearchJobs())
+		jobsRouter.GET("/external/:externalID", jobsHandler.GetJobByExternalID())
+		jobsRouter.PUT("/external/:externalID", jobsHandler.UpdateJobByExternalID())
+		jobsRouter.DELETE("/external/:externalID", jobsHandler.DeleteJobByExternalID())
+		jobsRouter.POST("/batchGet", jobsHandler.BatchGetJobs())
+		jobsRouter.GET("/export", jobsHandler.ExportJobs())
+		jobsRouter.POST("/apply", jobsHandler.ApplyManifest())
+		jobsRouter.POST("/validate", jobsHandler.ValidateJob())
+		jobsRouter.POST("/fromTemplate", jobsHandler.CreateJobFromTemplate())
+		jobsRouter.POST("/bulk/create", jobsHandler.CreateJobs())
+		jobsRouter.POST("/bulk/update", jobsHandler.UpdateJobs())
+		jobsRouter.POST("/bulk/pause", jobsHandler.PauseJobs())
+		jobsRouter.POST("/bulk/resume", jobsHandler.ResumeJobs())
+		jobsRouter.POST("/bulk/delete", jobsHandler.DeleteJobs())
+		jobsRouter.POST("/import/crontab", jobsHandler.ImportCrontab())
+		jobsRouter.POST("/import/cronjobs", jobsHandler.ImportCronJobs())
+		jobsRouter.POST("/:id/run", jobsHandler.RunJob())
+		jobsRouter.POST("/:id/backfill", jobsHandler.BackfillJob())
+		jobsRouter.POST("/:id/clone", jobsHandler.CloneJob())
+		jobsRouter.GET("/:id/sla", jobsHandler.GetJobSLA())
 		jobsRouter.GET("/:id/executions", jobsHandler.GetJobExecutions())
+		jobsRouter.GET("/:id/executions/export", jobsHandler.ExportJobExecutions())
+		jobsRouter.GET("/:id/executions/:execID/logs", jobsHandler.GetJobExecutionLogs())
+		jobsRouter.GET("/:id/executions/:execID/output", jobsHandler.GetJobExecutionOutput())
 	}
 }
 
@@ -40,12 +71,15 @@ type ErrorResponse struct {
 // CreateJob godoc
 // @Summary Create a job
 // @Description Create a job with the given job create request
+// @Description With dryRun=true, the job is only validated, see /jobs/validate.
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Param job body model.JobCreate true "Job Create"
+// @Param dryRun query bool false "Validate the job without creating it"
 // @Success 201 {object} model.Job
 // @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.JobValidation
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs [post]
 func (j *Jobs) CreateJob() gin.HandlerFunc {
@@ -57,6 +91,11 @@ func (j *Jobs) CreateJob() gin.HandlerFunc {
 			return
 		}
 
+		if ctx.Query("dryRun") == "true" {
+			respondValidation(ctx, j.service.ValidateJob(ctx.Request.Context(), create))
+			return
+		}
+
 		job, err := j.service.CreateJob(ctx.Request.Context(), create)
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
@@ -67,6 +106,45 @@ func (j *Jobs) CreateJob() gin.HandlerFunc {
 
 		job.RemoveCredentials()
 
+		ctx.Header("ETag", etag(job))
+		ctx.JSON(http.StatusCreated, job)
+	}
+}
+
+// CreateJobFromTemplate godoc
+// @Summary Create a job from a template
+// @Description Create a job from the definition of a template, with its {{parameter}} placeholders replaced by the given
+// @Description parameters. Parameters without a default value are required. The job is tagged template=<name>.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param job body model.JobFromTemplate true "Template and parameters"
+// @Success 201 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/fromTemplate [post]
+func (j *Jobs) CreateJobFromTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		fromTemplate := model.JobFromTemplate{}
+		if err := ctx.BindJSON(&fromTemplate); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		job, err := j.service.CreateJobFromTemplate(ctx.Request.Context(), fromTemplate)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		job.RemoveCredentials()
+
+		ctx.Header("ETag", etag(job))
 		ctx.JSON(http.StatusCreated, job)
 	}
 }
@@ -74,13 +152,19 @@ func (j *Jobs) CreateJob() gin.HandlerFunc {
 // UpdateJob godoc
 // @Summary Update a job
 // @Description Update a job with the given job update request
+// @Description With dryRun=true, the updated job is only validated, see /jobs/validate.
+// @Description Pass the ETag of the job in the If-Match header to reject the update if the job was modified since it was read.
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Param id path string true "Job ID"
 // @Param job body model.JobUpdate true "Job Update"
+// @Param dryRun query bool false "Validate the updated job without updating it"
+// @Param If-Match header string false "ETag of the job the update is based on"
 // @Success 200 {object} model.Job
 // @Failure 400 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 422 {object} model.JobValidation
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs/{id} [put]
 func (j *Jobs) UpdateJob() gin.HandlerFunc {
@@ -92,13 +176,68 @@ func (j *Jobs) UpdateJob() gin.HandlerFunc {
 			return
 		}
 
-		update := model.JobUpdate{}
-		if err := ctx.BindJSON(&update); err != nil {
-			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		j.updateJob(ctx, id)
+	}
+}
+
+// updateJob updates the job with the given ID with the update request of the body.
+func (j *Jobs) updateJob(ctx *gin.Context, id uuid.UUID) {
+	update := model.JobUpdate{}
+	if err := ctx.BindJSON(&update); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return
+	}
+	update.Version = version
+
+	if ctx.Query("dryRun") == "true" {
+		validation, err := j.service.ValidateJobUpdate(ctx.Request.Context(), id, update)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
 			return
 		}
 
-		job, err := j.service.UpdateJob(ctx.Request.Context(), id, update)
+		respondValidation(ctx, validation)
+		return
+	}
+
+	job, err := j.service.UpdateJob(ctx.Request.Context(), id, update)
+	if err != nil {
+		jobErr := errors.ToCustomJobError(err)
+
+		ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+		return
+	}
+
+	job.RemoveCredentials()
+
+	ctx.Header("ETag", etag(job))
+	ctx.JSON(http.StatusOK, job)
+}
+
+// GetJobByExternalID godoc
+// @Summary Get a job by external ID
+// @Description Get the job with the given external ID, the ID set by the client when creating the job, e.g. by an
+// @Description infrastructure-as-code tool which doesn't keep track of the generated job IDs
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param externalID path string true "External ID"
+// @Success 200 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/external/{externalID} [get]
+func (j *Jobs) GetJobByExternalID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		job, err := j.service.GetJobByExternalID(ctx.Request.Context(), ctx.Param("externalID"))
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
@@ -108,11 +247,78 @@ func (j *Jobs) UpdateJob() gin.HandlerFunc {
 
 		job.RemoveCredentials()
 
+		ctx.Header("ETag", etag(job))
 		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// UpdateJobByExternalID godoc
+// @Summary Update a job by external ID
+// @Description Update the job with the given external ID with the given job update request, like /jobs/{id}
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param externalID path string true "External ID"
+// @Param job body model.JobUpdate true "Job Update"
+// @Param dryRun query bool false "Validate the updated job without updating it"
+// @Param If-Match header string false "ETag of the job the update is based on"
+// @Success 200 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 422 {object} model.JobValidation
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/external/{externalID} [put]
+func (j *Jobs) UpdateJobByExternalID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		job, err := j.service.GetJobByExternalID(ctx.Request.Context(), ctx.Param("externalID"))
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		j.updateJob(ctx, job.ID)
+	}
+}
+
+// ValidateJob godoc
+// @Summary Validate a job
+// @Description Validate a job create request without creating the job. The response lists the errors of all invalid
+// @Description fields, the upcoming runs of the job, and hints about the reachability of its target.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param job body model.JobCreate true "Job Create"
+// @Success 200 {object} model.JobValidation
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.JobValidation
+// @Router /jobs/validate [post]
+func (j *Jobs) ValidateJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.JobCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 
+		respondValidation(ctx, j.service.ValidateJob(ctx.Request.Context(), create))
 	}
 }
 
+// respondValidation writes the outcome of a dry run, reporting invalid jobs with 422 Unprocessable Entity.
+func respondValidation(ctx *gin.Context, validation *model.JobValidation) {
+	if !validation.Valid {
+		ctx.JSON(http.StatusUnprocessableEntity, validation)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, validation)
+}
+
 // GetJob godoc
 // @Summary Get a job
 // @Description Get a job with the given job ID
@@ -143,10 +349,290 @@ func (j *Jobs) GetJob() gin.HandlerFunc {
 
 		job.RemoveCredentials()
 
+		ctx.Header("ETag", etag(job))
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// BatchGetJobs godoc
+// @Summary Get jobs by IDs
+// @Description Get up to 500 jobs by their IDs in a single request. Jobs are returned in the requested order,
+// @Description the IDs of missing jobs are listed in not_found.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param ids body model.JobBatchGet true "IDs of the jobs"
+// @Success 200 {object} model.JobBatch
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/batchGet [post]
+func (j *Jobs) BatchGetJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		batch := model.JobBatchGet{}
+		if err := ctx.BindJSON(&batch); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := j.service.BatchGetJobs(ctx.Request.Context(), batch)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		for i := range result.Jobs {
+			result.Jobs[i].RemoveCredentials()
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
+
+// PatchJob godoc
+// @Summary Patch a job
+// @Description Update a job with a JSON merge patch (RFC 7386). Fields set to null are cleared, e.g.
+// @Description {"cron_schedule": null, "execute_at": "2030-01-01T00:00:00Z"} turns a recurring job into a one-off job.
+// @Description Pass the ETag of the job in the If-Match header to reject the patch if the job was modified since it was read.
+// @Tags jobs
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param patch body model.JobCreate true "Merge patch of the job definition"
+// @Param If-Match header string false "ETag of the job the patch is based on"
+// @Success 200 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id} [patch]
+func (j *Jobs) PatchJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		// plain JSON is accepted as well, since merge patches are JSON documents
+		if contentType := ctx.ContentType(); contentType != model.MergePatchContentType && contentType != gin.MIMEJSON {
+			ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "content type must be " + model.MergePatchContentType})
+			return
+		}
+
+		patch, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		version, ok := ifMatchVersion(ctx)
+		if !ok {
+			return
+		}
+
+		job, err := j.service.PatchJob(ctx.Request.Context(), id, patch, version)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		job.RemoveCredentials()
+
+		ctx.Header("ETag", etag(job))
 		ctx.JSON(http.StatusOK, job)
 	}
 }
 
+// ifMatchVersion returns the job version of the If-Match header, if any. A header that can't match
+// any version is answered with 412 Precondition Failed, in which case ok is false.
+func ifMatchVersion(ctx *gin.Context) (version *int64, ok bool) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, true
+	}
+
+	parsed, err := parseETag(ifMatch)
+	if err != nil {
+		ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: errors.ErrJobVersionMismatch.Error()})
+		return nil, false
+	}
+
+	return &parsed, true
+}
+
+// etag returns the ETag of the job, identifying its version.
+func etag(job *model.Job) string {
+	return strconv.Quote(strconv.FormatInt(job.Version, 10))
+}
+
+// parseETag returns the job version identified by the ETag. Weak ETags are accepted as well.
+func parseETag(tag string) (int64, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(unquoted, 10, 64)
+}
+
+// RunJob godoc
+// @Summary Run a job now
+// @Description Schedule an execution of the job with its current definition, regardless of its schedule, which is
+// @Description left unchanged. The execution is PENDING until a runner picks it up. The optional body overrides the
+// @Description definition for this execution only, e.g. {"http": {"body": "{\"full\": true}"}} to replay the job
+// @Description with a tweaked payload, or {"parameters": {"customer": "acme"}} to render the template the job was
+// @Description instantiated from again.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param overrides body model.JobRunOverrides false "Overrides of the definition of the execution"
+// @Success 202 {object} model.JobExecution
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/run [post]
+func (j *Jobs) RunJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		var overrides *model.JobRunOverrides
+		if len(bytes.TrimSpace(body)) > 0 {
+			overrides = &model.JobRunOverrides{}
+			if err := json.Unmarshal(body, overrides); err != nil {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+				return
+			}
+		}
+
+		execution, err := j.service.RunJob(ctx.Request.Context(), id, overrides)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, execution)
+	}
+}
+
+// BackfillJob godoc
+// @Summary Backfill a job
+// @Description Schedule an execution of each run of a recurring job scheduled in [from, to), e.g. the runs missed while
+// @Description the job was paused, skipping the runs which were already executed. The executions run the current
+// @Description definition of the job, and are PENDING until a runner picks them up. A backfill covers at most 1000 runs.
+// @Description With dryRun=true, the runs are only listed.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param backfill body model.JobBackfill true "Range of the runs"
+// @Param dryRun query bool false "List the runs without executing them"
+// @Success 200 {object} model.JobBackfillResult "Dry run"
+// @Success 202 {object} model.JobBackfillResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/backfill [post]
+func (j *Jobs) BackfillJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		backfill := model.JobBackfill{}
+		if err := ctx.BindJSON(&backfill); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		dryRun := ctx.Query("dryRun") == "true"
+		result, err := j.service.BackfillJob(ctx.Request.Context(), id, backfill, dryRun)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		if dryRun {
+			ctx.JSON(http.StatusOK, result)
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, result)
+	}
+}
+
+// CloneJob godoc
+// @Summary Clone a job
+// @Description Create a copy of a job with a new ID and no execution history. The optional body is a JSON merge patch
+// @Description (RFC 7386) overriding fields of the copy, e.g. {"name": "nightly-copy", "cron_schedule": "0 3 * * *"}.
+// @Description The name is not copied unless it is overridden, and copies of paused jobs are paused.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param overrides body model.JobCreate false "JSON merge patch of the copy"
+// @Success 201 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/clone [post]
+func (j *Jobs) CloneJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		overrides, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		job, err := j.service.CloneJob(ctx.Request.Context(), id, overrides)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		job.RemoveCredentials()
+
+		ctx.Header("ETag", etag(job))
+		ctx.JSON(http.StatusCreated, job)
+	}
+}
+
 // DeleteJob godoc
 // @Summary Delete a job
 // @Description Delete a job with the given job ID
@@ -178,52 +664,168 @@ func (j *Jobs) DeleteJob() gin.HandlerFunc {
 	}
 }
 
+// DeleteJobByExternalID godoc
+// @Summary Delete a job by external ID
+// @Description Delete the job with the given external ID. Deleting a missing job succeeds, so deletions can be retried.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param externalID path string true "External ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/external/{externalID} [delete]
+func (j *Jobs) DeleteJobByExternalID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		if err := j.service.DeleteJobByExternalID(ctx.Request.Context(), ctx.Param("externalID")); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
 // ListJobs godoc
 // @Summary List jobs
-// @Description List jobs with the given limit and offset
+// @Description List jobs matching the given filters with the given limit, starting after the given cursor
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Param limit query int false "Limit"
-// @Param offset query int false "Offset"
-// @Param tags query array false "Tags"
-// @Success 200 {object} []model.Job
+// @Param cursor query string false "Cursor"
+// @Param status query array false "Statuses (RUNNING, STOPPED, ARCHIVED)"
+// @Param type query array false "Types (HTTP, AMQP)"
+// @Param tags query array false "Tags, jobs must have all of them"
+// @Param anyTags query array false "Tags, jobs must have at least one of them"
+// @Param selector query string false "Tag selector, e.g. env=prod AND team=payments (also key!=value, key and !key)"
+// @Param nextRunFrom query string false "Next run from (RFC3339)"
+// @Param nextRunTo query string false "Next run to (RFC3339)"
+// @Param createdFrom query string false "Created from (RFC3339)"
+// @Param createdTo query string false "Created to (RFC3339)"
+// @Param failed query bool false "Whether the last execution of the job failed"
+// @Param team query string false "Team owning the jobs"
+// @Param fields query string false "Comma-separated fields of the jobs to return, e.g. id,status,next_run"
+// @Param exact query bool false "Count the jobs exactly, the total of many jobs is estimated otherwise"
+// @Success 200 {object} model.JobPage
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs [get]
 func (j *Jobs) ListJobs() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 
-		limit, offset := LimitAndOffset(ctx)
+		limit, cursor, err := LimitAndCursor(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		filter, err := JobFilterFromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		page, err := j.service.ListJobs(ctx.Request.Context(), limit, cursor, *filter)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
 
-		tags := ctx.QueryArray("tags")
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		respondJobPage(ctx, page, filter.Fields)
+	}
+}
 
-		jobs, err := j.service.ListJobs(ctx.Request.Context(), limit, offset, tags)
+// SearchJobs godoc
+// @Summary Search jobs
+// @Description Search jobs by their URL, exchange, routing key and tags. Accepts the same filters as the job listing.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param limit query int false "Limit"
+// @Param cursor query string false "Cursor"
+// @Param status query array false "Statuses (RUNNING, STOPPED, ARCHIVED)"
+// @Param type query array false "Types (HTTP, AMQP)"
+// @Param tags query array false "Tags, jobs must have all of them"
+// @Param selector query string false "Tag selector, e.g. env=prod AND team=payments"
+// @Param fields query string false "Comma-separated fields of the jobs to return, e.g. id,status,next_run"
+// @Param exact query bool false "Count the jobs exactly, the total of many jobs is estimated otherwise"
+// @Success 200 {object} model.JobPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/search [get]
+func (j *Jobs) SearchJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		limit, cursor, err := LimitAndCursor(ctx)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		filter, err := JobFilterFromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		filter.Query = strings.TrimSpace(ctx.Query("q"))
+		if filter.Query == "" {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "missing search query"})
 			return
 		}
 
-		// Remove credentials from the jobs
-		for i := range jobs {
-			jobs[i].RemoveCredentials()
+		page, err := j.service.ListJobs(ctx.Request.Context(), limit, cursor, *filter)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
 		}
 
-		ctx.JSON(http.StatusOK, jobs)
+		respondJobPage(ctx, page, filter.Fields)
 	}
 }
 
+// respondJobPage responds with the page of jobs without their credentials, and with only the selected fields if any.
+func respondJobPage(ctx *gin.Context, page *model.JobPage, fields model.JobFields) {
+	// Remove credentials from the jobs
+	for i := range page.Jobs {
+		page.Jobs[i].RemoveCredentials()
+	}
+
+	if len(fields) == 0 {
+		ctx.JSON(http.StatusOK, page)
+		return
+	}
+
+	sparse, err := page.Sparse(fields)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sparse)
+}
+
 // GetJobExecutions godoc
 // @Summary Get job executions
-// @Description Get job executions with the given job ID, failed only flag, limit and offset
+// @Description Get job executions with the given job ID, failed only flag and limit, starting after the given cursor
 // @Tags jobs
 // @Accept json
 // @Produce json
 // @Param id path string true "Job ID"
 // @Param failedOnly query bool false "Failed Only"
 // @Param limit query int false "Limit"
-// @Param offset query int false "Offset"
-// @Success 200 {object} []model.JobExecution
+// @Param cursor query string false "Cursor"
+// @Param exact query bool false "Count the executions exactly, the total of many executions is estimated otherwise"
+// @Success 200 {object} model.JobExecutionPage
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /jobs/{id}/executions [get]
@@ -237,10 +839,15 @@ func (j *Jobs) GetJobExecutions() gin.HandlerFunc {
 		}
 
 		failedOnly, _ := strconv.ParseBool(ctx.Query("failedOnly"))
+		exact, _ := strconv.ParseBool(ctx.Query("exact"))
 
-		limit, offset := LimitAndOffset(ctx)
+		limit, cursor, err := LimitAndCursor(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 
-		executions, err := j.service.GetJobExecutions(ctx.Request.Context(), jobID, failedOnly, limit, offset)
+		page, err := j.service.GetJobExecutions(ctx.Request.Context(), jobID, failedOnly, limit, cursor, exact)
 		if err != nil {
 			jobErr := errors.ToCustomJobError(err)
 
@@ -248,29 +855,244 @@ func (j *Jobs) GetJobExecutions() gin.HandlerFunc {
 			return
 		}
 
-		ctx.JSON(http.StatusOK, map[string]interface {
-		}{
-			"executions": executions,
-		})
+		ctx.JSON(http.StatusOK, page)
 	}
 }
 
-func LimitAndOffset(ctx *gin.Context) (uint64, uint64) {
-	limitStr := ctx.Query("limit")
-	offsetStr := ctx.Query("offset")
+// GetJobSLA godoc
+// @Summary Get the SLA compliance of a job
+// @Description Get the compliance of the job with its SLA, over the executions started within the window of the SLA
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.JobSLAStatus
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/sla [get]
+func (j *Jobs) GetJobSLA() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
 
-	// convert limit and offset to uint
-	var limit, offset uint64
-	var err error
-	limit, err = strconv.ParseUint(limitStr, 10, 32)
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		status, err := j.service.GetJobSLAStatus(ctx.Request.Context(), jobID, time.Now())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, status)
+	}
+}
+
+// GetJobExecutionLogs godoc
+// @Summary Get job execution logs
+// @Description Get the logs captured during the given execution of the job
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param execID path int true "Execution ID"
+// @Success 200 {object} model.ExecutionLogs
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/executions/{execID}/logs [get]
+func (j *Jobs) GetJobExecutionLogs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		executionID, err := strconv.Atoi(ctx.Param("execID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		logs, err := j.service.GetJobExecutionLogs(ctx.Request.Context(), jobID, executionID)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, logs)
+	}
+}
+
+// GetJobExecutionOutput godoc
+// @Summary Get job execution output
+// @Description Stream the output captured during the given execution of the job, e.g. the body of the HTTP response.
+// @Description The output is sent zstd-compressed if the client accepts the zstd encoding, decompressed otherwise.
+// @Tags jobs
+// @Produce octet-stream
+// @Param id path string true "Job ID"
+// @Param execID path int true "Execution ID"
+// @Success 200 {file} file
+// @Header 200 {int} X-Output-Size "Size of the decompressed output"
+// @Header 200 {bool} X-Output-Truncated "Whether the output exceeded the maximum size"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/executions/{execID}/output [get]
+func (j *Jobs) GetJobExecutionOutput() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		executionID, err := strconv.Atoi(ctx.Param("execID"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		output, err := j.service.GetJobExecutionOutput(ctx.Request.Context(), jobID, executionID)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		contentType := output.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("X-Output-Size", strconv.FormatInt(output.Size, 10))
+		ctx.Header("X-Output-Truncated", strconv.FormatBool(output.Truncated))
+		ctx.Header("Vary", "Accept-Encoding")
+
+		if acceptsEncoding(ctx.GetHeader("Accept-Encoding"), output.Encoding) {
+			ctx.Header("Content-Encoding", output.Encoding)
+			ctx.Data(http.StatusOK, contentType, output.Data)
+			return
+		}
+
+		decoder, err := zstd.NewReader(bytes.NewReader(output.Data), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		defer decoder.Close()
+
+		// the output is decompressed while it's sent, so it's never held decompressed in memory
+		ctx.Header("Content-Length", strconv.FormatInt(output.Size, 10))
+		ctx.Status(http.StatusOK)
+		_, _ = io.Copy(ctx.Writer, decoder)
+	}
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header accepts the given content encoding.
+func acceptsEncoding(header, encoding string) bool {
+	for _, accepted := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(accepted), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+
+		// q=0 rejects the encoding
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+
+	return false
+}
+
+// LimitAndCursor parses the page size and the opaque cursor of the previous page from the query.
+func LimitAndCursor(ctx *gin.Context) (uint64, *model.Cursor, error) {
+	limit, err := strconv.ParseUint(ctx.Query("limit"), 10, 32)
 	if err != nil || limit == 0 {
 		limit = 10
 	}
 
-	offset, err = strconv.ParseUint(offsetStr, 10, 32)
+	cursor, err := model.DecodeCursor(ctx.Query("cursor"))
 	if err != nil {
-		offset = 0
+		return 0, nil, err
+	}
+
+	return limit, cursor, nil
+}
+
+// JobFilterFromQuery parses the job listing filters from the query parameters.
+func JobFilterFromQuery(ctx *gin.Context) (*model.JobFilter, error) {
+	selector, err := model.ParseTagSelector(ctx.Query("selector"))
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := model.ParseJobFields(ctx.Query("fields"))
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &model.JobFilter{
+		Tags:     ctx.QueryArray("tags"),
+		AnyTags:  ctx.QueryArray("anyTags"),
+		Team:     ctx.Query("team"),
+		Selector: selector,
+		Fields:   fields,
+	}
+
+	for _, status := range ctx.QueryArray("status") {
+		filter.Statuses = append(filter.Statuses, model.JobStatus(strings.ToUpper(status)))
+	}
+
+	for _, jobType := range ctx.QueryArray("type") {
+		filter.Types = append(filter.Types, model.JobType(strings.ToUpper(jobType)))
+	}
+
+	times := map[string]**time.Time{
+		"nextRunFrom": &filter.NextRunFrom,
+		"nextRunTo":   &filter.NextRunTo,
+		"createdFrom": &filter.CreatedFrom,
+		"createdTo":   &filter.CreatedTo,
+	}
+	for param, target := range times {
+		value := ctx.Query(param)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", param, err)
+		}
+		*target = &t
+	}
+
+	if value := ctx.Query("failed"); value != "" {
+		failed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid failed: %w", err)
+		}
+		filter.Failed = &failed
+	}
+
+	if value := ctx.Query("exact"); value != "" {
+		exact, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exact: %w", err)
+		}
+		filter.ExactTotal = exact
 	}
 
-	return limit, offset
+	return filter, nil
 }
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateJobs godoc
+// @Summary Create jobs in bulk
+// @Description Create multiple jobs in a single transaction. If any of the jobs is invalid, none are created.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param jobs body model.BulkJobCreate true "Jobs to create"
+// @Success 201 {object} model.BulkResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResult
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/bulk/create [post]
+func (j *Jobs) CreateJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		bulk := model.BulkJobCreate{}
+		if err := ctx.BindJSON(&bulk); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := j.service.CreateJobs(ctx.Request.Context(), bulk)
+		respondBulk(ctx, http.StatusCreated, result, err)
+	}
+}
+
+// UpdateJobs godoc
+// @Summary Update jobs in bulk
+// @Description Update the listed jobs, or apply the same update to all jobs matching the selector, in a single transaction.
+// @Description If any of the jobs is invalid or does not exist, none are updated.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param jobs body model.BulkJobUpdate true "Jobs to update"
+// @Success 200 {object} model.BulkResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResult
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/bulk/update [post]
+func (j *Jobs) UpdateJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		bulk := model.BulkJobUpdate{}
+		if err := ctx.BindJSON(&bulk); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := j.service.UpdateJobs(ctx.Request.Context(), bulk)
+		respondBulk(ctx, http.StatusOK, result, err)
+	}
+}
+
+// PauseJobs godoc
+// @Summary Pause jobs in bulk
+// @Description Stop all jobs selected by IDs, tags or a tag selector in a single transaction
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param selector body model.JobSelector true "Job selector"
+// @Success 200 {object} model.BulkResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResult
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/bulk/pause [post]
+func (j *Jobs) PauseJobs() gin.HandlerFunc {
+	return j.selectorHandler(j.service.PauseJobs)
+}
+
+// ResumeJobs godoc
+// @Summary Resume jobs in bulk
+// @Description Resume all jobs selected by IDs, tags or a tag selector in a single transaction
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param selector body model.JobSelector true "Job selector"
+// @Success 200 {object} model.BulkResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResult
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/bulk/resume [post]
+func (j *Jobs) ResumeJobs() gin.HandlerFunc {
+	return j.selectorHandler(j.service.ResumeJobs)
+}
+
+// DeleteJobs godoc
+// @Summary Delete jobs in bulk
+// @Description Delete all jobs selected by IDs, tags or a tag selector in a single transaction
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param selector body model.JobSelector true "Job selector"
+// @Success 200 {object} model.BulkResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.BulkResult
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/bulk/delete [post]
+func (j *Jobs) DeleteJobs() gin.HandlerFunc {
+	return j.selectorHandler(j.service.DeleteJobs)
+}
+
+func (j *Jobs) selectorHandler(exec func(context.Context, model.JobSelector) (*model.BulkResult, error)) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		selector := model.JobSelector{}
+		if err := ctx.BindJSON(&selector); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := exec(ctx.Request.Context(), selector)
+		respondBulk(ctx, http.StatusOK, result, err)
+	}
+}
+
+// respondBulk writes the result of a bulk operation. Operations where any of the items failed
+// are rolled back and reported with 422 Unprocessable Entity.
+func respondBulk(ctx *gin.Context, successCode int, result *model.BulkResult, err error) {
+	if err != nil {
+		jobErr := errors.ToCustomJobError(err)
+
+		ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+		return
+	}
+
+	if !result.Succeeded {
+		ctx.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	ctx.JSON(successCode, result)
+}
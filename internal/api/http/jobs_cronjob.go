@@ -0,0 +1,72 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportCronJobs godoc
+// @Summary Import Kubernetes CronJobs
+// @Description Convert the Kubernetes CronJobs of the manifests into jobs named <namespace>.<name>, and create them in a
+// @Description single transaction. If any of the CronJobs is invalid, none are created. With the HTTP strategy, each
+// @Description CronJob becomes an HTTP job calling the http_job of the mapping definition. With the KUBERNETES strategy,
+// @Description it becomes a job of a custom type (KUBERNETES_JOB by default) executed by a plugin creating the Job.
+// @Description The jobs are tagged source=cronjob along with the labels of the CronJobs, and the jobs of suspended
+// @Description CronJobs are stopped. With dryRun=true, the converted jobs are returned without being created.
+// @Tags jobs
+// @Accept application/yaml
+// @Accept json
+// @Produce json
+// @Param import body model.CronJobImport true "CronJob manifests and mapping, in YAML or JSON"
+// @Param dryRun query bool false "Preview the jobs without creating them"
+// @Success 200 {object} model.CronJobImportResult "Dry run"
+// @Success 201 {object} model.CronJobImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} model.CronJobImportResult
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/import/cronjobs [post]
+func (j *Jobs) ImportCronJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		data, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		request, err := model.ParseCronJobImport(data)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := j.service.ImportCronJobs(ctx.Request.Context(), *request, ctx.Query("dryRun") == "true")
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		for _, item := range result.Jobs {
+			if item.Job != nil {
+				item.Job.RemoveCredentials()
+			}
+		}
+
+		switch {
+		case !result.Succeeded():
+			ctx.JSON(http.StatusUnprocessableEntity, result)
+		case result.Created:
+			ctx.JSON(http.StatusCreated, result)
+		default:
+			ctx.JSON(http.StatusOK, result)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+)
+
+// ImportCrontab godoc
+// @Summary Import a crontab
+// @Description Convert the commands of a crontab file into jobs, and create them in a single transaction. If any of the
+// @Description lines is invalid, none are created. With the HTTP strategy, each command becomes an HTTP job calling the
+// @Description http_job of the mapping definition. With the SHELL strategy, it becomes a job of a custom type (SHELL by
+// @Description default) executed by a plugin. The jobs are tagged source=crontab.
+// @Description With dryRun=true, the converted jobs are returned without being created.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param import body model.CrontabImport true "Crontab and mapping"
+// @Param dryRun query bool false "Preview the jobs without creating them"
+// @Success 200 {object} model.CrontabImportResult "Dry run"
+// @Success 201 {object} model.CrontabImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} model.CrontabImportResult
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/import/crontab [post]
+func (j *Jobs) ImportCrontab() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		request := model.CrontabImport{}
+		if err := ctx.BindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := j.service.ImportCrontab(ctx.Request.Context(), request, ctx.Query("dryRun") == "true")
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		for _, item := range result.Jobs {
+			if item.Job != nil {
+				item.Job.RemoveCredentials()
+			}
+		}
+
+		switch {
+		case !result.Succeeded():
+			ctx.JSON(http.StatusUnprocessableEntity, result)
+		case result.Created:
+			ctx.JSON(http.StatusCreated, result)
+		default:
+			ctx.JSON(http.StatusOK, result)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportFlushInterval is the number of exported executions after which the response is flushed to the client.
+const exportFlushInterval = 500
+
+// ExportJobExecutions godoc
+// @Summary Export job executions
+// @Description Stream the executions of a job started within a time range, oldest first, as CSV or NDJSON (one JSON
+// @Description execution per line). The range defaults to the last 30 days. Executions are streamed as they are read,
+// @Description so large ranges can be exported without paging.
+// @Tags jobs
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param id path string true "Job ID"
+// @Param format query string false "Export format, csv (default) or ndjson"
+// @Param from query string false "Start of the range, inclusive (RFC3339)"
+// @Param to query string false "End of the range, exclusive (RFC3339), now by default"
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/executions/export [get]
+func (j *Jobs) ExportJobExecutions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		jobID, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		format := model.ExecutionExportFormat(ctx.DefaultQuery("format", string(model.ExecutionExportFormatCSV)))
+		if !format.Valid() {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be either csv or ndjson"})
+			return
+		}
+
+		exportRange, err := exportRangeFromQuery(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		csvWriter := csv.NewWriter(ctx.Writer)
+		encoder := json.NewEncoder(ctx.Writer)
+
+		// the response is only started with the first execution, so errors occurring before can still be returned
+		started := false
+		start := func() error {
+			if started {
+				return nil
+			}
+			started = true
+
+			ctx.Header("Content-Type", format.ContentType())
+			ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="executions-%s.%s"`, jobID, format))
+			ctx.Status(http.StatusOK)
+
+			if format == model.ExecutionExportFormatCSV {
+				return csvWriter.Write(model.ExecutionCSVHeader)
+			}
+			return nil
+		}
+
+		exported := 0
+		err = j.service.ExportJobExecutions(ctx.Request.Context(), jobID, exportRange, func(execution *model.JobExecution) error {
+			if err := start(); err != nil {
+				return err
+			}
+
+			if format == model.ExecutionExportFormatCSV {
+				if err := csvWriter.Write(execution.CSVRecord()); err != nil {
+					return err
+				}
+			} else if err := encoder.Encode(execution); err != nil {
+				return err
+			}
+
+			exported++
+			if exported%exportFlushInterval == 0 {
+				csvWriter.Flush()
+				ctx.Writer.Flush()
+			}
+
+			return csvWriter.Error()
+		})
+		if err != nil {
+			if !started {
+				jobErr := errors.ToCustomJobError(err)
+
+				ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+				return
+			}
+
+			// the export can't be reported as failed anymore, the client gets a truncated export
+			_ = ctx.Error(err)
+			return
+		}
+
+		if err := start(); err != nil {
+			_ = ctx.Error(err)
+			return
+		}
+		csvWriter.Flush()
+	}
+}
+
+// exportRangeFromQuery returns the range of the from and to query parameters, the last 30 days by default.
+func exportRangeFromQuery(ctx *gin.Context) (model.ExecutionExportRange, error) {
+	exportRange := model.ExecutionExportRange{To: time.Now()}
+
+	if value := ctx.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return exportRange, fmt.Errorf("invalid to: %w", err)
+		}
+		exportRange.To = to
+	}
+
+	exportRange.From = exportRange.To.Add(-model.DefaultExecutionExportPeriod)
+	if value := ctx.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return exportRange, fmt.Errorf("invalid from: %w", err)
+		}
+		exportRange.From = from
+	}
+
+	return exportRange, nil
+}
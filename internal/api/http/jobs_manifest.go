@@ -0,0 +1,108 @@
+package http
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+)
+
+const yamlContentType = "application/yaml"
+
+// ExportJobs godoc
+// @Summary Export jobs as a manifest
+// @Description Export the named jobs of the namespace as a manifest, which can be applied with /jobs/apply.
+// @Description Credentials are not exported.
+// @Tags jobs
+// @Produce application/yaml
+// @Produce json
+// @Param format query string false "Manifest format, yaml (default) or json"
+// @Success 200 {object} model.JobManifest
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/export [get]
+func (j *Jobs) ExportJobs() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		format := ctx.DefaultQuery("format", "yaml")
+		if format != "yaml" && format != "json" {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be either yaml or json"})
+			return
+		}
+
+		manifest, err := j.service.ExportJobs(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		if format == "json" {
+			ctx.JSON(http.StatusOK, manifest)
+			return
+		}
+
+		data, err := manifest.YAML()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		ctx.Data(http.StatusOK, yamlContentType, data)
+	}
+}
+
+// ApplyManifest godoc
+// @Summary Apply a job manifest
+// @Description Create, update and delete the named jobs of the namespace so they match the manifest, in a single transaction.
+// @Description Jobs are identified by name. Named jobs missing from the manifest are deleted, unnamed jobs are left alone.
+// @Description Applying the same manifest more than once is a no-op.
+// @Description With keepMissing and keepCredentials, e.g. to import the jobs exported from another environment, the named jobs
+// @Description missing from the manifest are kept, and the credentials the manifest leaves out are kept in the updated jobs.
+// @Tags jobs
+// @Accept application/yaml
+// @Accept json
+// @Produce json
+// @Param manifest body model.JobManifest true "Job manifest, in YAML or JSON"
+// @Param keepMissing query bool false "Keep the named jobs missing from the manifest instead of deleting them"
+// @Param keepCredentials query bool false "Keep the credentials of the updated jobs which the manifest leaves out"
+// @Success 200 {object} model.ManifestApplyResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/apply [post]
+func (j *Jobs) ApplyManifest() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		data, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		manifest, err := model.ParseJobManifest(data)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		options := model.ManifestApplyOptions{
+			KeepMissing:     ctx.Query("keepMissing") == "true",
+			KeepCredentials: ctx.Query("keepCredentials") == "true",
+		}
+
+		result, err := j.service.ApplyManifest(ctx.Request.Context(), *manifest, options)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
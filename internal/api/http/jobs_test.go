@@ -0,0 +1,38 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	tag := etag(&model.Job{Version: 3})
+	assert.Equal(t, `"3"`, tag)
+
+	tests := []struct {
+		name    string
+		tag     string
+		version int64
+		wantErr bool
+	}{
+		{"Strong", `"3"`, 3, false},
+		{"Weak", `W/"3"`, 3, false},
+		{"Unquoted", `3`, 0, true},
+		{"Not a version", `"abc"`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := parseETag(tt.tag)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
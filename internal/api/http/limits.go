@@ -0,0 +1,56 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+)
+
+// LimitsConfig bounds the size of the requests and of the payloads of jobs, in bytes. A limit of 0 disables it.
+type LimitsConfig struct {
+	// Maximum size of request bodies, which also bounds manifests and bulk requests
+	MaxRequestBodySize int64 `mapstructure:"maxRequestBodySize" yaml:"maxRequestBodySize" json:"maxRequestBodySize"`
+	// Limits of the bodies and headers of jobs, also enforced when validating jobs
+	Jobs model.PayloadLimits `mapstructure:"jobs" yaml:"jobs" json:"jobs"`
+}
+
+// LimitRequestBody rejects requests with a body larger than maxSize with 413 Request Entity Too Large. The body is
+// read before the request is handled, so requests without a Content-Length are rejected before any handler runs.
+func LimitRequestBody(maxSize int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if maxSize <= 0 || ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+			ctx.Next()
+			return
+		}
+
+		tooLarge := func() {
+			jobErr := errors.ToCustomJobError(errors.ErrRequestBodyTooLarge)
+			ctx.AbortWithStatusJSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+		}
+
+		if ctx.Request.ContentLength > maxSize {
+			tooLarge()
+			return
+		}
+
+		// read one extra byte to tell whether the body exceeds the limit
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxSize+1))
+		_ = ctx.Request.Body.Close()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if int64(len(body)) > maxSize {
+			tooLarge()
+			return
+		}
+
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+		ctx.Next()
+	}
+}
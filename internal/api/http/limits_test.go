@@ -0,0 +1,49 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitRequestBody(t *testing.T) {
+	router := gin.New()
+	router.Use(LimitRequestBody(16))
+	router.POST("/v1/jobs", func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		ctx.String(http.StatusOK, string(body))
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{"Empty body", "", false, http.StatusOK},
+		{"Small body", `{"type": "HTTP"}`, false, http.StatusOK},
+		{"Large body", strings.Repeat("a", 17), false, http.StatusRequestEntityTooLarge},
+		{"Large body without content length", strings.Repeat("a", 17), true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/jobs", strings.NewReader(tt.body))
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+		})
+	}
+}
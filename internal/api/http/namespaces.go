@@ -0,0 +1,228 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+)
+
+const namespaceHeader = "X-Namespace"
+
+// Namespace scopes the requests to the namespace given in the X-Namespace header. Without the header,
+// requests are scoped to the namespace of their API key, or to the default namespace if authentication
+// is disabled. Only admin keys can access other namespaces than their own. The requests are also attributed to the
+// principal of their API key, restricting the non-admin keys of a team to the jobs of their team.
+func Namespace() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !strings.HasPrefix(ctx.Request.URL.Path, protectedPrefix) {
+			ctx.Next()
+			return
+		}
+
+		namespace := ctx.GetHeader(namespaceHeader)
+		var principal model.Principal
+
+		if value, ok := ctx.Get(apiKeyContextKey); ok {
+			apiKey, ok := value.(*model.APIKey)
+			if !ok {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: errors.ErrForbidden.Error()})
+				return
+			}
+
+			if namespace == "" {
+				namespace = apiKey.Namespace
+			}
+
+			if !apiKey.Admin && namespace != apiKey.Namespace {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Error: errors.ErrForbidden.Error()})
+				return
+			}
+
+			principal = apiKey.Principal()
+		}
+
+		if namespace == "" {
+			namespace = model.DefaultNamespace
+		}
+
+		if err := model.ValidateNamespace(namespace); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		requestCtx := model.WithNamespace(ctx.Request.Context(), namespace)
+		ctx.Request = ctx.Request.WithContext(model.WithPrincipal(requestCtx, principal))
+		ctx.Next()
+	}
+}
+
+func NamespacesRoutesV1(router *gin.Engine, namespacesHandler *Namespaces) {
+	namespacesRouter := router.Group("/v1/admin/namespaces", RequireAdmin())
+	{
+		namespacesRouter.GET("", namespacesHandler.ListNamespaces())
+		namespacesRouter.GET("/:name", namespacesHandler.GetNamespace())
+		namespacesRouter.PUT("/:name", namespacesHandler.SetNamespaceQuotas())
+		namespacesRouter.GET("/:name/keys", namespacesHandler.ListNamespaceKeys())
+		namespacesRouter.POST("/:name/keys/rotate", namespacesHandler.RotateNamespaceKey())
+	}
+}
+
+func NewNamespacesHandler(service *jobService.Service) *Namespaces {
+	return &Namespaces{
+		service: service,
+	}
+}
+
+type Namespaces struct {
+	service *jobService.Service
+}
+
+// ListNamespaces godoc
+// @Summary List namespaces
+// @Description List all namespaces having jobs or quotas. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.Namespace
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/namespaces [get]
+func (n *Namespaces) ListNamespaces() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		namespaces, err := n.service.ListNamespaces(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, namespaces)
+	}
+}
+
+// GetNamespace godoc
+// @Summary Get a namespace
+// @Description Get a namespace with its quotas and number of jobs. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Namespace name"
+// @Success 200 {object} model.Namespace
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/namespaces/{name} [get]
+func (n *Namespaces) GetNamespace() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		namespace, err := n.service.GetNamespace(ctx.Request.Context(), ctx.Param("name"))
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, namespace)
+	}
+}
+
+// SetNamespaceQuotas godoc
+// @Summary Set the quotas of a namespace
+// @Description Set the maximum number of jobs and concurrent executions of a namespace, null meaning unlimited. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Namespace name"
+// @Param quotas body model.NamespaceQuotas true "Quotas"
+// @Success 200 {object} model.Namespace
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/namespaces/{name} [put]
+func (n *Namespaces) SetNamespaceQuotas() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		quotas := model.NamespaceQuotas{}
+		if err := ctx.BindJSON(&quotas); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		namespace, err := n.service.SetNamespaceQuotas(ctx.Request.Context(), ctx.Param("name"), quotas)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, namespace)
+	}
+}
+
+// ListNamespaceKeys godoc
+// @Summary List the keys of a namespace
+// @Description List the versions of the data key encrypting the credentials of a namespace, the active one first. The keys themselves are never returned. Requires an admin key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Namespace name"
+// @Success 200 {array} model.NamespaceKey
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/namespaces/{name}/keys [get]
+func (n *Namespaces) ListNamespaceKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		keys, err := n.service.ListNamespaceKeys(ctx.Request.Context(), ctx.Param("name"))
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, keys)
+	}
+}
+
+// RotateNamespaceKey godoc
+// @Summary Rotate the key of a namespace
+// @Description Create a new version of the data key of a namespace, which encrypts its new credentials. The credentials of its jobs, executions, webhooks and templates are encrypted again with it in the background, and the previous versions are deleted once no credential is encrypted with them anymore. Requires an admin key and namespace keys to be enabled.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Namespace name"
+// @Success 202 {object} model.NamespaceKey
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/namespaces/{name}/keys/rotate [post]
+func (n *Namespaces) RotateNamespaceKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		key, err := n.service.RotateNamespaceKey(ctx.Request.Context(), ctx.Param("name"))
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, key)
+	}
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	notificationService "github.com/TimeSnap/distributed-scheduler/internal/service/notification"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func NotificationRulesRoutesV1(router *gin.Engine, rulesHandler *NotificationRules) {
+	rulesRouter := router.Group("/v1/notification-rules")
+	{
+		rulesRouter.POST("", rulesHandler.CreateNotificationRule())
+		rulesRouter.GET("", rulesHandler.ListNotificationRules())
+		rulesRouter.GET("/:id", rulesHandler.GetNotificationRule())
+		rulesRouter.DELETE("/:id", rulesHandler.DeleteNotificationRule())
+		rulesRouter.GET("/:id/notifications", rulesHandler.GetNotifications())
+	}
+}
+
+func NewNotificationRulesHandler(service *notificationService.Service) *NotificationRules {
+	return &NotificationRules{
+		service: service,
+	}
+}
+
+type NotificationRules struct {
+	service *notificationService.Service
+}
+
+// CreateNotificationRule godoc
+// @Summary Create a notification rule
+// @Description Notify of the failures, recoveries and auto-disabling of a job, or of the jobs with some tags, on Slack,
+// @Description by email or through a webhook. The failure notifications of each job are throttled.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param rule body model.NotificationRuleCreate true "Notification rule"
+// @Success 201 {object} model.NotificationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notification-rules [post]
+func (n *NotificationRules) CreateNotificationRule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.NotificationRuleCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		rule, err := n.service.CreateNotificationRule(ctx.Request.Context(), create)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, rule)
+	}
+}
+
+// ListNotificationRules godoc
+// @Summary List notification rules
+// @Description List all notification rules
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.NotificationRule
+// @Failure 500 {object} ErrorResponse
+// @Router /notification-rules [get]
+func (n *NotificationRules) ListNotificationRules() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		rules, err := n.service.ListNotificationRules(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rules)
+	}
+}
+
+// GetNotificationRule godoc
+// @Summary Get a notification rule
+// @Description Get a notification rule with the given rule ID
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification rule ID"
+// @Success 200 {object} model.NotificationRule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notification-rules/{id} [get]
+func (n *NotificationRules) GetNotificationRule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		rule, err := n.service.GetNotificationRule(ctx.Request.Context(), id)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rule)
+	}
+}
+
+// DeleteNotificationRule godoc
+// @Summary Delete a notification rule
+// @Description Delete a notification rule with the given rule ID, along with its notifications
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification rule ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notification-rules/{id} [delete]
+func (n *NotificationRules) DeleteNotificationRule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := n.service.DeleteNotificationRule(ctx.Request.Context(), id); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// GetNotifications godoc
+// @Summary Get notifications
+// @Description Get the notifications of a rule, newest first, with the given limit, starting after the given cursor
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Notification rule ID"
+// @Param limit query int false "Limit"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} model.NotificationPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /notification-rules/{id}/notifications [get]
+func (n *NotificationRules) GetNotifications() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		limit, cursor, err := LimitAndCursor(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		page, err := n.service.GetNotifications(ctx.Request.Context(), id, limit, cursor)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, page)
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/gin-gonic/gin"
+)
+
+func ScheduleRoutesV1(router *gin.Engine, scheduleHandler *Schedule) {
+	scheduleRouter := router.Group("/v1/schedule")
+	{
+		scheduleRouter.POST("/preview", scheduleHandler.PreviewSchedule())
+	}
+}
+
+func NewScheduleHandler() *Schedule {
+	return &Schedule{}
+}
+
+type Schedule struct{}
+
+// PreviewSchedule godoc
+// @Summary Preview a schedule
+// @Description Get the next occurrences of a schedule expression, without creating a job. The occurrences are computed
+// @Description in the time zone of the CRON_TZ= or TZ= prefix of the expression, in UTC without one. RRULE expressions
+// @Description are not supported.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param preview body model.SchedulePreviewRequest true "Schedule Preview Request"
+// @Success 200 {object} model.SchedulePreview
+// @Failure 400 {object} ErrorResponse
+// @Router /schedule/preview [post]
+func (s *Schedule) PreviewSchedule() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		req := model.SchedulePreviewRequest{}
+		if err := ctx.BindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		preview, err := model.PreviewSchedule(req)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, preview)
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/mtls"
+	"github.com/gin-gonic/gin"
+	healthcheck "github.com/tavsec/gin-healthcheck"
+	"github.com/tavsec/gin-healthcheck/checks"
+	healthcheckConfig "github.com/tavsec/gin-healthcheck/config"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	devxHttp "github.com/xBlaz3kx/DevX/http"
+	"go.uber.org/zap"
+)
+
+// ServerConfig configures the listener of the API: the DevX configuration (address and TLS certificate), along with
+// the verification of the client certificates.
+type ServerConfig struct {
+	devxHttp.Configuration `mapstructure:",squash" yaml:",inline" json:",inline"`
+	// ClientAuth verifies the certificates of the clients, TLS must be enabled
+	ClientAuth mtls.Settings `mapstructure:"clientAuth" yaml:"clientAuth" json:"clientAuth"`
+}
+
+// Server serves the API, over TLS if enabled.
+type Server struct {
+	server *http.Server
+	router *gin.Engine
+	log    *otelzap.Logger
+}
+
+// NewServer creates the listener of the router. It only listens once started.
+func NewServer(cfg ServerConfig, router *gin.Engine, log *otelzap.Logger) (*Server, error) {
+	s := &Server{
+		router: router,
+		log:    log,
+		server: &http.Server{
+			Addr:              cfg.Address,
+			Handler:           router,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+
+	if !cfg.TLS.IsEnabled {
+		if cfg.ClientAuth.Mode != "" && cfg.ClientAuth.Mode != mtls.ClientAuthNone {
+			return nil, errors.New("client certificates can only be verified with TLS enabled")
+		}
+
+		return s, nil
+	}
+
+	tlsConfig, err := mtls.ServerConfig(cfg.TLS.CertificatePath, cfg.TLS.PrivateKeyPath, cfg.ClientAuth)
+	if err != nil {
+		return nil, err
+	}
+	s.server.TLSConfig = tlsConfig
+
+	return s, nil
+}
+
+// Start mounts the /healthz liveness endpoint with the checks, and listens in a separate goroutine.
+func (s *Server) Start(checks ...checks.Check) error {
+	if err := healthcheck.New(s.router, healthcheckConfig.DefaultConfig(), checks); err != nil {
+		return err
+	}
+
+	go func() {
+		var err error
+		if s.server.TLSConfig != nil {
+			s.log.Info("Starting HTTPS server", zap.String("address", s.server.Addr))
+			// the certificate is already loaded in the TLS configuration
+			err = s.server.ListenAndServeTLS("", "")
+		} else {
+			s.log.Info("Starting HTTP server", zap.String("address", s.server.Addr))
+			err = s.server.ListenAndServe()
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Fatal("HTTP server failed", zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+// Stop closes the listener, waiting for the requests in progress to finish or the context to expire.
+func (s *Server) Stop(ctx context.Context) {
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.log.Warn("Timeout while stopping the HTTP server", zap.Error(err))
+		_ = s.server.Close()
+		return
+	}
+
+	s.log.Info("HTTP server stopped")
+}
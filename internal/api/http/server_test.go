@@ -0,0 +1,26 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/mtls"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	devxHttp "github.com/xBlaz3kx/DevX/http"
+	"go.uber.org/zap"
+)
+
+func TestNewServer(t *testing.T) {
+	log := otelzap.New(zap.NewNop())
+
+	_, err := NewServer(ServerConfig{Configuration: devxHttp.Configuration{Address: "localhost:0"}}, gin.New(), log)
+	assert.NoError(t, err)
+
+	// client certificates are only verified over TLS
+	_, err = NewServer(ServerConfig{
+		Configuration: devxHttp.Configuration{Address: "localhost:0"},
+		ClientAuth:    mtls.Settings{Mode: mtls.ClientAuthRequired, CAPath: "ca.crt"},
+	}, gin.New(), log)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+)
+
+func TagsRoutesV1(router *gin.Engine, tagsHandler *Tags) {
+	tagsRouter := router.Group("/v1/tags")
+	{
+		tagsRouter.GET("", tagsHandler.ListTags())
+		tagsRouter.POST("/rename", tagsHandler.RenameTag())
+	}
+}
+
+func NewTagsHandler(service *jobService.Service) *Tags {
+	return &Tags{
+		service: service,
+	}
+}
+
+type Tags struct {
+	service *jobService.Service
+}
+
+// ListTags godoc
+// @Summary List tags
+// @Description List the tags of the jobs of the namespace, with the number of jobs having each of them.
+// @Description Tags of the form key=value are returned with their key and value.
+// @Description API keys bound to a team only see the tags of the jobs of their team.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.TagCount
+// @Failure 500 {object} ErrorResponse
+// @Router /tags [get]
+func (t *Tags) ListTags() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		tags, err := t.service.ListTags(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, tags)
+	}
+}
+
+// RenameTag godoc
+// @Summary Rename a tag
+// @Description Rename a tag on all jobs of the namespace having it, e.g. from "prod" to "env=prod".
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param rename body model.TagRename true "Tag rename"
+// @Success 200 {object} model.TagRenameResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /tags/rename [post]
+func (t *Tags) RenameTag() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		rename := model.TagRename{}
+		if err := ctx.BindJSON(&rename); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := t.service.RenameTag(ctx.Request.Context(), rename)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, result)
+	}
+}
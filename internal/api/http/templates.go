@@ -0,0 +1,185 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	jobService "github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/gin-gonic/gin"
+)
+
+func TemplatesRoutesV1(router *gin.Engine, templatesHandler *Templates) {
+	templatesRouter := router.Group("/v1/templates")
+	{
+		templatesRouter.POST("", templatesHandler.CreateTemplate())
+		templatesRouter.GET("", templatesHandler.ListTemplates())
+		templatesRouter.GET("/:name", templatesHandler.GetTemplate())
+		templatesRouter.PUT("/:name", templatesHandler.UpdateTemplate())
+		templatesRouter.DELETE("/:name", templatesHandler.DeleteTemplate())
+	}
+}
+
+func NewTemplatesHandler(service *jobService.Service) *Templates {
+	return &Templates{
+		service: service,
+	}
+}
+
+type Templates struct {
+	service *jobService.Service
+}
+
+// CreateTemplate godoc
+// @Summary Create a job template
+// @Description Create a named job template. The definition has the fields of a job create request, with {{parameter}}
+// @Description placeholders in its string values, replaced when instantiating jobs with /jobs/fromTemplate.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param template body model.JobTemplateCreate true "Job template"
+// @Success 201 {object} model.JobTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [post]
+func (t *Templates) CreateTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.JobTemplateCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		template, err := t.service.CreateTemplate(ctx.Request.Context(), create)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		template.RemoveCredentials()
+
+		ctx.JSON(http.StatusCreated, template)
+	}
+}
+
+// ListTemplates godoc
+// @Summary List job templates
+// @Description List the job templates of the namespace, ordered by name
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.JobTemplate
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [get]
+func (t *Templates) ListTemplates() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		templates, err := t.service.ListTemplates(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		for i := range templates {
+			templates[i].RemoveCredentials()
+		}
+
+		ctx.JSON(http.StatusOK, templates)
+	}
+}
+
+// GetTemplate godoc
+// @Summary Get a job template
+// @Description Get the job template with the given name
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 200 {object} model.JobTemplate
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [get]
+func (t *Templates) GetTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		template, err := t.service.GetTemplate(ctx.Request.Context(), ctx.Param("name"))
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		template.RemoveCredentials()
+
+		ctx.JSON(http.StatusOK, template)
+	}
+}
+
+// UpdateTemplate godoc
+// @Summary Update a job template
+// @Description Replace the description, parameters and definition of the job template with the given name.
+// @Description Jobs already instantiated from the template are not changed.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param template body model.JobTemplateCreate true "Job template"
+// @Success 200 {object} model.JobTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [put]
+func (t *Templates) UpdateTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		update := &model.JobTemplateCreate{}
+		if err := ctx.BindJSON(update); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		template, err := t.service.UpdateTemplate(ctx.Request.Context(), ctx.Param("name"), update)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		template.RemoveCredentials()
+
+		ctx.JSON(http.StatusOK, template)
+	}
+}
+
+// DeleteTemplate godoc
+// @Summary Delete a job template
+// @Description Delete the job template with the given name. Jobs instantiated from the template are kept.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{name} [delete]
+func (t *Templates) DeleteTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		if err := t.service.DeleteTemplate(ctx.Request.Context(), ctx.Param("name")); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
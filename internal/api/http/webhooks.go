@@ -0,0 +1,203 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	webhookService "github.com/TimeSnap/distributed-scheduler/internal/service/webhook"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func WebhooksRoutesV1(router *gin.Engine, webhooksHandler *Webhooks) {
+	webhooksRouter := router.Group("/v1/webhooks")
+	{
+		webhooksRouter.POST("", webhooksHandler.CreateWebhook())
+		webhooksRouter.GET("", webhooksHandler.ListWebhooks())
+		webhooksRouter.GET("/:id", webhooksHandler.GetWebhook())
+		webhooksRouter.DELETE("/:id", webhooksHandler.DeleteWebhook())
+		webhooksRouter.GET("/:id/deliveries", webhooksHandler.GetWebhookDeliveries())
+	}
+}
+
+func NewWebhooksHandler(service *webhookService.Service) *Webhooks {
+	return &Webhooks{
+		service: service,
+	}
+}
+
+type Webhooks struct {
+	service *webhookService.Service
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook
+// @Description Register a webhook receiving job lifecycle events. Deliveries are signed with the secret,
+// @Description see the X-Webhook-Signature header.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body model.WebhookCreate true "Webhook"
+// @Success 201 {object} model.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks [post]
+func (w *Webhooks) CreateWebhook() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.WebhookCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		webhook, err := w.service.CreateWebhook(ctx.Request.Context(), create)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		webhook.RemoveCredentials()
+
+		ctx.JSON(http.StatusCreated, webhook)
+	}
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description List all webhooks
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.Webhook
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks [get]
+func (w *Webhooks) ListWebhooks() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		webhooks, err := w.service.ListWebhooks(ctx.Request.Context())
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		for i := range webhooks {
+			webhooks[i].RemoveCredentials()
+		}
+
+		ctx.JSON(http.StatusOK, webhooks)
+	}
+}
+
+// GetWebhook godoc
+// @Summary Get a webhook
+// @Description Get a webhook with the given webhook ID
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} model.Webhook
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id} [get]
+func (w *Webhooks) GetWebhook() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		webhook, err := w.service.GetWebhook(ctx.Request.Context(), id)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		webhook.RemoveCredentials()
+
+		ctx.JSON(http.StatusOK, webhook)
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete a webhook with the given webhook ID, along with its deliveries
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (w *Webhooks) DeleteWebhook() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := w.service.DeleteWebhook(ctx.Request.Context(), id); err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Get webhook deliveries
+// @Description Get the deliveries of a webhook, newest first, with the given limit, starting after the given cursor
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Limit"
+// @Param cursor query string false "Cursor"
+// @Success 200 {object} model.WebhookDeliveryPage
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (w *Webhooks) GetWebhookDeliveries() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		limit, cursor, err := LimitAndCursor(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		page, err := w.service.GetWebhookDeliveries(ctx.Request.Context(), id, limit, cursor)
+		if err != nil {
+			jobErr := errors.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, page)
+	}
+}
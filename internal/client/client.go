@@ -0,0 +1,305 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+)
+
+const (
+	apiKeyHeader    = "X-API-Key"
+	namespaceHeader = "X-Namespace"
+)
+
+// Config configures the client of the management API.
+type Config struct {
+	// Endpoint is the base URL of the management API, e.g. http://localhost:8000
+	Endpoint string
+	// APIKey authenticates the requests, if set
+	APIKey string
+	// Namespace scopes the requests, the namespace of the API key is used if empty
+	Namespace string
+	// HTTPClient sends the requests, a client with a 30s timeout is used if nil
+	HTTPClient *http.Client
+}
+
+// Client is a client of the management API.
+type Client struct {
+	endpoint   string
+	apiKey     string
+	namespace  string
+	httpClient *http.Client
+}
+
+// Error is returned when the management API responds with an error.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+}
+
+// New creates a new client of the management API.
+func New(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Client{
+		endpoint:   strings.TrimSuffix(cfg.Endpoint, "/"),
+		apiKey:     cfg.APIKey,
+		namespace:  cfg.Namespace,
+		httpClient: httpClient,
+	}
+}
+
+// ListJobsOptions filters and paginates the jobs listed by ListJobs.
+type ListJobsOptions struct {
+	Limit    uint64
+	Cursor   string
+	Statuses []model.JobStatus
+	Types    []model.JobType
+	// Jobs must have all of the tags
+	Tags []string
+	// Selector is a tag selector expression, e.g. "env=prod AND team=payments"
+	Selector string
+	// Query searches the jobs (see GET /jobs/search), along with the other filters
+	Query string
+	// Fields of the jobs to return, all of them if empty. The other fields of the returned jobs are left empty.
+	Fields []string
+	// Exact counts the matching jobs exactly, the total of many jobs is estimated otherwise
+	Exact bool
+}
+
+// CreateJob creates a job.
+func (c *Client) CreateJob(ctx context.Context, create *model.JobCreate) (*model.Job, error) {
+	job := &model.Job{}
+	return job, c.do(ctx, http.MethodPost, "/v1/jobs", nil, create, job)
+}
+
+// GetJob returns the job with the ID.
+func (c *Client) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	job := &model.Job{}
+	return job, c.do(ctx, http.MethodGet, "/v1/jobs/"+id.String(), nil, nil, job)
+}
+
+// GetJobByExternalID returns the job with the external ID.
+func (c *Client) GetJobByExternalID(ctx context.Context, externalID string) (*model.Job, error) {
+	job := &model.Job{}
+	return job, c.do(ctx, http.MethodGet, "/v1/jobs/external/"+url.PathEscape(externalID), nil, nil, job)
+}
+
+// BatchGetJobs returns the jobs with the IDs in a single request.
+func (c *Client) BatchGetJobs(ctx context.Context, ids []uuid.UUID) (*model.JobBatch, error) {
+	batch := &model.JobBatch{}
+	return batch, c.do(ctx, http.MethodPost, "/v1/jobs/batchGet", nil, model.JobBatchGet{IDs: ids}, batch)
+}
+
+// ListJobs returns a page of jobs matching the options, newest first.
+func (c *Client) ListJobs(ctx context.Context, opts ListJobsOptions) (*model.JobPage, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", fmt.Sprint(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+
+	path := "/v1/jobs"
+	if opts.Query != "" {
+		path = "/v1/jobs/search"
+		query.Set("q", opts.Query)
+	}
+
+	for _, status := range opts.Statuses {
+		query.Add("status", string(status))
+	}
+	for _, jobType := range opts.Types {
+		query.Add("type", string(jobType))
+	}
+	for _, tag := range opts.Tags {
+		query.Add("tags", tag)
+	}
+	if opts.Selector != "" {
+		query.Set("selector", opts.Selector)
+	}
+	if len(opts.Fields) > 0 {
+		query.Set("fields", strings.Join(opts.Fields, ","))
+	}
+	if opts.Exact {
+		query.Set("exact", "true")
+	}
+
+	page := &model.JobPage{}
+	return page, c.do(ctx, http.MethodGet, path, query, nil, page)
+}
+
+// UpdateJob updates the job with the ID, and returns the updated job.
+func (c *Client) UpdateJob(ctx context.Context, id uuid.UUID, update *model.JobUpdate) (*model.Job, error) {
+	job := &model.Job{}
+	return job, c.do(ctx, http.MethodPut, "/v1/jobs/"+id.String(), nil, update, job)
+}
+
+// UpdateJobByExternalID updates the job with the external ID, and returns the updated job.
+func (c *Client) UpdateJobByExternalID(ctx context.Context, externalID string, update *model.JobUpdate) (*model.Job, error) {
+	job := &model.Job{}
+	return job, c.do(ctx, http.MethodPut, "/v1/jobs/external/"+url.PathEscape(externalID), nil, update, job)
+}
+
+// GetJobExecutions returns up to limit executions of the job, latest first.
+func (c *Client) GetJobExecutions(ctx context.Context, id uuid.UUID, limit uint64) (*model.JobExecutionPage, error) {
+	page := &model.JobExecutionPage{}
+	return page, c.do(ctx, http.MethodGet, "/v1/jobs/"+id.String()+"/executions", url.Values{"limit": {fmt.Sprint(limit)}}, nil, page)
+}
+
+// DeleteJob deletes the job with the ID.
+func (c *Client) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/v1/jobs/"+id.String(), nil, nil, nil)
+}
+
+// DeleteJobByExternalID deletes the job with the external ID.
+func (c *Client) DeleteJobByExternalID(ctx context.Context, externalID string) error {
+	return c.do(ctx, http.MethodDelete, "/v1/jobs/external/"+url.PathEscape(externalID), nil, nil, nil)
+}
+
+// PauseJobs pauses the selected jobs in a single transaction.
+func (c *Client) PauseJobs(ctx context.Context, selector model.JobSelector) (*model.BulkResult, error) {
+	result := &model.BulkResult{}
+	return result, c.do(ctx, http.MethodPost, "/v1/jobs/bulk/pause", nil, selector, result)
+}
+
+// ResumeJobs resumes the selected jobs in a single transaction.
+func (c *Client) ResumeJobs(ctx context.Context, selector model.JobSelector) (*model.BulkResult, error) {
+	result := &model.BulkResult{}
+	return result, c.do(ctx, http.MethodPost, "/v1/jobs/bulk/resume", nil, selector, result)
+}
+
+// RunJob schedules an execution of the job now, regardless of its schedule. The overrides, if not nil, apply to the
+// definition of this execution only.
+func (c *Client) RunJob(ctx context.Context, id uuid.UUID, overrides *model.JobRunOverrides) (*model.JobExecution, error) {
+	var body interface{}
+	if overrides != nil {
+		body = overrides
+	}
+
+	execution := &model.JobExecution{}
+	return execution, c.do(ctx, http.MethodPost, "/v1/jobs/"+id.String()+"/run", nil, body, execution)
+}
+
+// BackfillJob schedules an execution of each run of the recurring job scheduled in the range, except the runs already
+// executed. With dryRun, the runs are only listed.
+func (c *Client) BackfillJob(ctx context.Context, id uuid.UUID, backfill model.JobBackfill, dryRun bool) (*model.JobBackfillResult, error) {
+	var query url.Values
+	if dryRun {
+		query = url.Values{"dryRun": {"true"}}
+	}
+
+	result := &model.JobBackfillResult{}
+	return result, c.do(ctx, http.MethodPost, "/v1/jobs/"+id.String()+"/backfill", query, backfill, result)
+}
+
+// ExportJobs exports the named jobs of the namespace as a manifest, without their credentials.
+func (c *Client) ExportJobs(ctx context.Context) (*model.JobManifest, error) {
+	manifest := &model.JobManifest{}
+	return manifest, c.do(ctx, http.MethodGet, "/v1/jobs/export", url.Values{"format": {"json"}}, nil, manifest)
+}
+
+// ApplyManifest creates, updates and deletes the named jobs of the namespace until they match the manifest, as far
+// as the options allow.
+func (c *Client) ApplyManifest(ctx context.Context, manifest *model.JobManifest, options model.ManifestApplyOptions) (*model.ManifestApplyResult, error) {
+	query := url.Values{}
+	if options.KeepMissing {
+		query.Set("keepMissing", "true")
+	}
+	if options.KeepCredentials {
+		query.Set("keepCredentials", "true")
+	}
+
+	result := &model.ManifestApplyResult{}
+	return result, c.do(ctx, http.MethodPost, "/v1/jobs/apply", query, manifest, result)
+}
+
+// do sends a request with the JSON encoded body, and decodes the JSON response into result, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return responseError(res)
+	}
+
+	if result == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
+
+// newRequest creates an authenticated request with the JSON encoded body, if not nil.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	u := c.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if c.namespace != "" {
+		req.Header.Set(namespaceHeader, c.namespace)
+	}
+
+	return req, nil
+}
+
+// responseError returns the error of an error response of the management API.
+func responseError(res *http.Response) error {
+	apiErr := &Error{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+
+	var errorResponse struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&errorResponse); err == nil && errorResponse.Error != "" {
+		apiErr.Message = errorResponse.Error
+	}
+
+	return apiErr
+}
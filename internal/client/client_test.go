@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestClient(t *testing.T) {
+	id := uuid.New()
+
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/jobs/"+id.String():
+			_ = json.NewEncoder(w).Encode(model.Job{ID: id, Type: model.JobTypeHTTP})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/jobs/external/terraform:billing":
+			_ = json.NewEncoder(w).Encode(model.Job{ID: id, ExternalID: null.StringFrom("terraform:billing")})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/jobs/search":
+			_ = json.NewEncoder(w).Encode(model.JobPage{Jobs: []model.Job{{ID: id}}})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/jobs/"+id.String()+"/executions":
+			_ = json.NewEncoder(w).Encode(model.JobExecutionPage{Executions: []*model.JobExecution{{ID: 7, JobID: id, Status: model.JobExecutionStatusFailed}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/jobs/"+id.String()+"/run":
+			var overrides *model.JobRunOverrides
+			if r.ContentLength > 0 {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&overrides))
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(model.JobExecution{ID: 1, JobID: id, Status: model.JobExecutionStatusPending, Overridden: overrides != nil})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/jobs/"+id.String()+"/backfill":
+			var backfill model.JobBackfill
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&backfill))
+			_ = json.NewEncoder(w).Encode(model.JobBackfillResult{Runs: []time.Time{backfill.From}, DryRun: r.URL.Query().Get("dryRun") == "true"})
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/jobs/export":
+			_ = json.NewEncoder(w).Encode(model.JobManifest{Jobs: []model.JobCreate{{Name: null.StringFrom("billing"), Type: model.JobTypeHTTP}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/jobs/apply":
+			var manifest model.JobManifest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&manifest))
+			_ = json.NewEncoder(w).Encode(model.ManifestApplyResult{Created: []string{manifest.Jobs[0].Name.String}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/jobs/batchGet":
+			_ = json.NewEncoder(w).Encode(model.JobBatch{Jobs: []model.Job{{ID: id}}, NotFound: []uuid.UUID{}})
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "job not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := New(Config{Endpoint: server.URL + "/", APIKey: "key", Namespace: "team-a"})
+	ctx := context.Background()
+
+	t.Run("Get job", func(t *testing.T) {
+		job, err := client.GetJob(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, job.ID)
+
+		req := requests[len(requests)-1]
+		assert.Equal(t, "key", req.Header.Get(apiKeyHeader))
+		assert.Equal(t, "team-a", req.Header.Get(namespaceHeader))
+	})
+
+	t.Run("Get job by external ID", func(t *testing.T) {
+		job, err := client.GetJobByExternalID(ctx, "terraform:billing")
+		require.NoError(t, err)
+		assert.Equal(t, id, job.ID)
+		assert.Equal(t, "terraform:billing", job.ExternalID.String)
+	})
+
+	t.Run("Search jobs", func(t *testing.T) {
+		page, err := client.ListJobs(ctx, ListJobsOptions{Limit: 5, Query: "billing", Statuses: []model.JobStatus{model.JobStatusRunning}, Tags: []string{"a", "b"}, Fields: []string{"status", "next_run"}})
+		require.NoError(t, err)
+		assert.Len(t, page.Jobs, 1)
+
+		query := requests[len(requests)-1].URL.Query()
+		assert.Equal(t, "billing", query.Get("q"))
+		assert.Equal(t, "5", query.Get("limit"))
+		assert.Equal(t, []string{"RUNNING"}, query["status"])
+		assert.Equal(t, []string{"a", "b"}, query["tags"])
+		assert.Equal(t, "status,next_run", query.Get("fields"))
+	})
+
+	t.Run("Batch get jobs", func(t *testing.T) {
+		batch, err := client.BatchGetJobs(ctx, []uuid.UUID{id})
+		require.NoError(t, err)
+		assert.Len(t, batch.Jobs, 1)
+		assert.Equal(t, "application/json", requests[len(requests)-1].Header.Get("Content-Type"))
+	})
+
+	t.Run("Backfill job", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		result, err := client.BackfillJob(ctx, id, model.JobBackfill{From: from, To: from.Add(time.Hour)}, true)
+		require.NoError(t, err)
+		assert.True(t, result.DryRun)
+		require.Len(t, result.Runs, 1)
+		assert.True(t, from.Equal(result.Runs[0]))
+	})
+
+	t.Run("Export and import jobs", func(t *testing.T) {
+		manifest, err := client.ExportJobs(ctx)
+		require.NoError(t, err)
+		require.Len(t, manifest.Jobs, 1)
+		assert.Equal(t, "json", requests[len(requests)-1].URL.Query().Get("format"))
+
+		result, err := client.ApplyManifest(ctx, manifest, model.ManifestApplyOptions{KeepMissing: true, KeepCredentials: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"billing"}, result.Created)
+
+		query := requests[len(requests)-1].URL.Query()
+		assert.Equal(t, "true", query.Get("keepMissing"))
+		assert.Equal(t, "true", query.Get("keepCredentials"))
+	})
+
+	t.Run("Run job", func(t *testing.T) {
+		execution, err := client.RunJob(ctx, id, nil)
+		require.NoError(t, err)
+		assert.Equal(t, id, execution.JobID)
+		assert.Equal(t, model.JobExecutionStatusPending, execution.Status)
+		assert.False(t, execution.Overridden)
+
+		execution, err = client.RunJob(ctx, id, &model.JobRunOverrides{HTTP: &model.HTTPRunOverrides{Body: null.StringFrom("{}")}})
+		require.NoError(t, err)
+		assert.True(t, execution.Overridden)
+	})
+
+	t.Run("Get job executions", func(t *testing.T) {
+		page, err := client.GetJobExecutions(ctx, id, 1)
+		require.NoError(t, err)
+		require.Len(t, page.Executions, 1)
+		assert.Equal(t, model.JobExecutionStatusFailed, page.Executions[0].Status)
+		assert.Equal(t, "1", requests[len(requests)-1].URL.Query().Get("limit"))
+	})
+
+	t.Run("Delete job", func(t *testing.T) {
+		assert.NoError(t, client.DeleteJob(ctx, id))
+	})
+
+	t.Run("Error response", func(t *testing.T) {
+		_, err := client.GetJob(ctx, uuid.New())
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, "job not found", apiErr.Message)
+	})
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+)
+
+// maxEventSize is the maximum size of a line of the event stream.
+const maxEventSize = 1024 * 1024
+
+// ErrStreamClosed is returned when the management API closes the event stream, e.g. because it is shutting down.
+var ErrStreamClosed = errors.New("event stream closed")
+
+// StreamEventsOptions filters the events streamed by StreamEvents. Empty fields match all events.
+type StreamEventsOptions struct {
+	Types  []model.EventType
+	JobIDs []uuid.UUID
+	// Events must be for jobs with all of the tags
+	Tags []string
+}
+
+// StreamEvents streams the events of the namespace published from now on, calling fn for each of them, until the
+// context is done, fn returns an error or the stream is closed.
+func (c *Client) StreamEvents(ctx context.Context, opts StreamEventsOptions, fn func(model.Event) error) error {
+	query := url.Values{}
+	for _, eventType := range opts.Types {
+		query.Add("type", string(eventType))
+	}
+	for _, id := range opts.JobIDs {
+		query.Add("job", id.String())
+	}
+	for _, tag := range opts.Tags {
+		query.Add("tags", tag)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/events", query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// the stream lasts as long as the context, whatever the timeout of the client
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	res, err := streamClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return responseError(res)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// a blank line dispatches the event, the comments keeping the stream alive have no data
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event model.Event
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return err
+			}
+			data.Reset()
+
+			if err := fn(event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return ErrStreamClosed
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamEvents(t *testing.T) {
+	jobID := uuid.New()
+
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Query().Get("type") == "job.exploded" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "invalid event filter"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+		for i := 1; i <= 2; i++ {
+			_, _ = fmt.Fprintf(w, "event:execution.finished\ndata:{\"type\":\"execution.finished\",\"job_id\":\"%s\",\"execution_id\":%d}\n\n", jobID, i)
+		}
+	}))
+	defer server.Close()
+
+	// the timeout of the client doesn't apply to the stream
+	client := New(Config{Endpoint: server.URL, HTTPClient: &http.Client{Timeout: time.Nanosecond}})
+
+	t.Run("Stream", func(t *testing.T) {
+		var events []model.Event
+		err := client.StreamEvents(context.Background(), StreamEventsOptions{JobIDs: []uuid.UUID{jobID}, Tags: []string{"billing"}}, func(event model.Event) error {
+			events = append(events, event)
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrStreamClosed)
+
+		require.Len(t, events, 2)
+		assert.Equal(t, model.EventExecutionFinished, events[0].Type)
+		assert.Equal(t, jobID, events[1].JobID)
+		assert.Equal(t, 2, *events[1].ExecutionID)
+
+		assert.Equal(t, []string{jobID.String()}, query["job"])
+		assert.Equal(t, []string{"billing"}, query["tags"])
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := client.StreamEvents(context.Background(), StreamEventsOptions{}, func(event model.Event) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Invalid filter", func(t *testing.T) {
+		err := client.StreamEvents(context.Background(), StreamEventsOptions{Types: []model.EventType{"job.exploded"}}, func(event model.Event) error {
+			return nil
+		})
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	})
+}
@@ -0,0 +1,178 @@
+// Package doctor diagnoses a running scheduler from its database, for the doctor command of the tooling CLI. Each
+// check reports what it found along with the action fixing it.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/configcheck"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxListed is the number of jobs listed by a finding, the others being counted
+	maxListed = 10
+	// schedulePageSize is the number of job schedules checked at once
+	schedulePageSize = 1000
+)
+
+// SchedulerLag checks that the due jobs are picked up by the runners within the maximum lag.
+func SchedulerLag(diagnostics store.DiagnosticsStorer, maxLag time.Duration) configcheck.Check {
+	return configcheck.Check{Name: "scheduler lag", Run: func(ctx context.Context) error {
+		now := time.Now()
+		dueJobs, oldestDueAt, err := diagnostics.GetDueJobs(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		if !oldestDueAt.Valid || now.Sub(oldestDueAt.Time) <= maxLag {
+			return nil
+		}
+
+		instances, err := diagnostics.ListInstances(ctx)
+		if err != nil {
+			return err
+		}
+
+		alive := 0
+		for _, instance := range instances {
+			if instance.Alive {
+				alive++
+			}
+		}
+
+		summary := fmt.Sprintf("%d jobs are due, the oldest for %s, with %d runners registered", dueJobs,
+			now.Sub(oldestDueAt.Time).Round(time.Second), alive)
+		if alive == 0 {
+			return finding(summary, nil, "start the runners")
+		}
+
+		return finding(summary, nil, "check that the runners poll the namespaces of the due jobs and have their "+
+			"capabilities, or add runners")
+	}}
+}
+
+// StaleLocks checks for job locks that weren't released by the runners holding them. The jobs are picked up again
+// once their locks expire, so the stale locks are reported as warnings.
+func StaleLocks(diagnostics store.DiagnosticsStorer) configcheck.Check {
+	return configcheck.Check{Name: "stale locks", Warning: true, Run: func(ctx context.Context) error {
+		now := time.Now()
+		locks, err := diagnostics.GetStaleJobLocks(ctx, now, maxListed+1)
+		if err != nil {
+			return err
+		}
+
+		if len(locks) == 0 {
+			return nil
+		}
+
+		lines := make([]string, 0, len(locks))
+		for _, lock := range locks[:min(len(locks), maxListed)] {
+			state := "expired " + now.Sub(lock.LockedUntil).Round(time.Second).String() + " ago"
+			if !lock.RunnerAlive {
+				state = "held by a runner that is no longer registered"
+			}
+			lines = append(lines, fmt.Sprintf("job %s (%s) locked by %s, %s", lock.JobID, jobLabel(lock.Namespace, lock.Name.String), lock.LockedBy, state))
+		}
+
+		return finding(fmt.Sprintf("%s locks weren't released", countAtLeast(len(locks))), lines,
+			"the runners holding them stopped without releasing them, check their logs. The jobs are run again once "+
+				"the locks expire, and the zombie reaper of the manager fails their executions")
+	}}
+}
+
+// NextRuns checks that the next run of the running jobs matches their schedule, so they aren't run late or never.
+func NextRuns(diagnostics store.DiagnosticsStorer) configcheck.Check {
+	return configcheck.Check{Name: "next runs", Run: func(ctx context.Context) error {
+		var lines []string
+		invalid := 0
+		after := uuid.Nil
+		for {
+			jobs, err := diagnostics.ListJobSchedules(ctx, after, schedulePageSize)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			for _, job := range jobs {
+				if err := job.CheckNextRun(now); err != nil {
+					invalid++
+					if len(lines) < maxListed {
+						lines = append(lines, fmt.Sprintf("job %s (%s): %v", job.ID, jobLabel(job.Namespace, job.Name.String), err))
+					}
+				}
+			}
+
+			if len(jobs) < schedulePageSize {
+				break
+			}
+			after = jobs[len(jobs)-1].ID
+		}
+
+		if invalid == 0 {
+			return nil
+		}
+
+		return finding(fmt.Sprintf("%d jobs have an invalid next run", invalid), lines,
+			"update the jobs, e.g. with scheduler job update, which recomputes their next run from their schedule")
+	}}
+}
+
+// Credentials checks that the encryption key decrypts the credentials of the sample most recently updated jobs
+// storing some.
+func Credentials(diagnostics store.DiagnosticsStorer, sample uint) configcheck.Check {
+	return configcheck.Check{Name: "encryption key", Run: func(ctx context.Context) error {
+		checked, failures, err := diagnostics.CheckJobCredentials(ctx, sample)
+		if err != nil {
+			return err
+		}
+
+		if len(failures) == 0 {
+			return nil
+		}
+
+		lines := make([]string, 0, maxListed)
+		for _, failure := range failures[:min(len(failures), maxListed)] {
+			lines = append(lines, fmt.Sprintf("job %s (%s): %v", failure.JobID, failure.Namespace, failure.Err))
+		}
+
+		return finding(fmt.Sprintf("the credentials of %d of the %d jobs checked can't be decrypted", len(failures), checked), lines,
+			"check that storage.encryption.key and storage.encryption.kms match the configuration of the manager")
+	}}
+}
+
+// finding formats a problem, the jobs it affects and the action fixing it as a multi-line error.
+func finding(summary string, lines []string, action string) error {
+	var b strings.Builder
+	b.WriteString(summary)
+	for _, line := range lines {
+		b.WriteString("\n     ")
+		b.WriteString(line)
+	}
+	b.WriteString("\n   → ")
+	b.WriteString(action)
+
+	return errors.New(b.String())
+}
+
+// countAtLeast formats a count of items listed up to maxListed.
+func countAtLeast(count int) string {
+	if count > maxListed {
+		return fmt.Sprintf("more than %d", maxListed)
+	}
+
+	return fmt.Sprint(count)
+}
+
+func jobLabel(namespace, name string) string {
+	if name == "" {
+		return namespace
+	}
+
+	return namespace + "/" + name
+}
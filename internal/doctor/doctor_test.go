@@ -0,0 +1,120 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+type fakeStore struct {
+	oldestDueAt null.Time
+	instances   []model.Instance
+	locks       []model.JobLock
+	jobs        []model.Job
+	failures    []model.JobCredentialsError
+}
+
+func (f *fakeStore) GetDueJobs(ctx context.Context, at time.Time) (uint64, null.Time, error) {
+	if !f.oldestDueAt.Valid {
+		return 0, null.Time{}, nil
+	}
+	return 3, f.oldestDueAt, nil
+}
+
+func (f *fakeStore) ListInstances(ctx context.Context) ([]model.Instance, error) {
+	return f.instances, nil
+}
+
+func (f *fakeStore) GetStaleJobLocks(ctx context.Context, at time.Time, limit uint) ([]model.JobLock, error) {
+	return f.locks[:min(len(f.locks), int(limit))], nil
+}
+
+func (f *fakeStore) ListJobSchedules(ctx context.Context, after uuid.UUID, limit uint) ([]model.Job, error) {
+	var page []model.Job
+	for _, job := range f.jobs {
+		if job.ID.String() > after.String() && len(page) < int(limit) {
+			page = append(page, job)
+		}
+	}
+	return page, nil
+}
+
+func (f *fakeStore) CheckJobCredentials(ctx context.Context, limit uint) (int, []model.JobCredentialsError, error) {
+	return 5, f.failures, nil
+}
+
+func TestSchedulerLag(t *testing.T) {
+	ctx := context.Background()
+
+	store := &fakeStore{oldestDueAt: null.TimeFrom(time.Now().Add(-10 * time.Second))}
+	assert.NoError(t, SchedulerLag(store, time.Minute).Run(ctx))
+
+	store.oldestDueAt = null.TimeFrom(time.Now().Add(-5 * time.Minute))
+	err := SchedulerLag(store, time.Minute).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start the runners")
+
+	store.instances = []model.Instance{{ID: "runner-1", Alive: true}}
+	err = SchedulerLag(store, time.Minute).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 runners registered")
+}
+
+func TestStaleLocks(t *testing.T) {
+	ctx := context.Background()
+
+	check := StaleLocks(&fakeStore{})
+	assert.True(t, check.Warning)
+	assert.NoError(t, check.Run(ctx))
+
+	store := &fakeStore{locks: []model.JobLock{
+		{JobID: uuid.New(), Namespace: "default", LockedBy: "runner-1", LockedUntil: time.Now().Add(-time.Minute), RunnerAlive: true},
+		{JobID: uuid.New(), Namespace: "default", LockedBy: "runner-2", LockedUntil: time.Now().Add(time.Minute)},
+	}}
+	err := StaleLocks(store).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 locks weren't released")
+	assert.Contains(t, err.Error(), "runner-1, expired")
+	assert.Contains(t, err.Error(), "runner-2, held by a runner that is no longer registered")
+}
+
+func TestNextRuns(t *testing.T) {
+	ctx := context.Background()
+	hourly := null.StringFrom("0 * * * *")
+
+	var jobs []model.Job
+	for i := 0; i < schedulePageSize+5; i++ {
+		jobs = append(jobs, model.Job{ID: uuid.New(), Status: model.JobStatusRunning, CronSchedule: hourly, NextRun: null.TimeFrom(time.Now())})
+	}
+	// the jobs are listed by ID, like the store does
+	slices.SortFunc(jobs, func(a, b model.Job) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+	assert.NoError(t, NextRuns(&fakeStore{jobs: jobs}).Run(ctx))
+
+	// the invalid jobs of every page are counted
+	for i := range jobs {
+		jobs[i].NextRun = null.Time{}
+	}
+	err := NextRuns(&fakeStore{jobs: jobs}).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1005 jobs have an invalid next run")
+}
+
+func TestCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, Credentials(&fakeStore{}, 5).Run(ctx))
+
+	store := &fakeStore{failures: []model.JobCredentialsError{{JobID: uuid.New(), Namespace: "default", Err: errors.New("cipher: message authentication failed")}}}
+	err := Credentials(store, 5).Run(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of the 5 jobs checked")
+}
@@ -0,0 +1,126 @@
+// Package eventbus publishes the job lifecycle events to a message bus, e.g. a Kafka topic, a NATS subject or an AMQP
+// exchange, as CloudEvents, for downstream systems such as a data warehouse or an audit pipeline.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Types of the buses.
+const (
+	TypeKafka = "kafka"
+	TypeNATS  = "nats"
+	TypeAMQP  = "amqp"
+)
+
+// Settings configure the event bus.
+type Settings struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Type of the bus: kafka, nats or amqp
+	Type string `mapstructure:"type" yaml:"type" json:"type,omitempty"`
+	// Source of the CloudEvents, identifying the scheduler deployment, e.g. /scheduler/prod
+	Source string `mapstructure:"source" yaml:"source" json:"source,omitempty"`
+	// TypePrefix is prepended to the types of the events to form the types of the CloudEvents, e.g. scheduler.
+	// gives scheduler.job.created
+	TypePrefix string `mapstructure:"typePrefix" yaml:"typePrefix" json:"typePrefix,omitempty"`
+	// Timeout of the publication of an event
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout" json:"timeout,omitempty"`
+
+	Kafka KafkaSettings `mapstructure:"kafka" yaml:"kafka" json:"kafka"`
+	NATS  NATSSettings  `mapstructure:"nats" yaml:"nats" json:"nats"`
+	AMQP  AMQPSettings  `mapstructure:"amqp" yaml:"amqp" json:"amqp"`
+}
+
+// Publisher publishes the encoded events to the bus.
+type Publisher interface {
+	// Publish publishes the event, returning once the bus acknowledged it.
+	Publish(ctx context.Context, event model.CloudEvent, data []byte) error
+	Close() error
+}
+
+// Bus publishes the job lifecycle events relayed from the outbox.
+type Bus struct {
+	publisher  Publisher
+	source     string
+	typePrefix string
+	timeout    time.Duration
+	log        *otelzap.Logger
+}
+
+// New connects to the bus configured by the settings. The HTTP client is used by the Kafka REST proxy.
+func New(settings Settings, client *http.Client, log *otelzap.Logger) (*Bus, error) {
+	var (
+		publisher Publisher
+		err       error
+	)
+
+	switch settings.Type {
+	case TypeKafka:
+		publisher, err = newKafkaPublisher(settings.Kafka, client)
+	case TypeNATS:
+		publisher, err = newNATSPublisher(settings.NATS)
+	case TypeAMQP:
+		publisher, err = newAMQPPublisher(settings.AMQP)
+	default:
+		return nil, fmt.Errorf("unknown event bus type: %q", settings.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newBus(publisher, settings, log), nil
+}
+
+func newBus(publisher Publisher, settings Settings, log *otelzap.Logger) *Bus {
+	source := settings.Source
+	if source == "" {
+		source = "/scheduler"
+	}
+
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	return &Bus{
+		publisher:  publisher,
+		source:     source,
+		typePrefix: settings.TypePrefix,
+		timeout:    timeout,
+		log:        log,
+	}
+}
+
+// Publish publishes the event as a CloudEvent. It is an outbox sink: the event is left in the outbox and published
+// again if the bus doesn't acknowledge it, so consumers may receive an event more than once and must deduplicate
+// the events by ID.
+func (b *Bus) Publish(ctx context.Context, event model.Event) error {
+	ce := model.NewCloudEvent(event, b.source, b.typePrefix)
+	data, err := json.Marshal(ce)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	if err := b.publisher.Publish(ctx, ce, data); err != nil {
+		return fmt.Errorf("failed to publish event %s to the event bus: %w", event.ID, err)
+	}
+
+	b.log.Debug("Published event to the event bus", zap.Any("event_id", event.ID), zap.String("type", ce.Type))
+	return nil
+}
+
+// Close closes the connection to the bus.
+func (b *Bus) Close() error {
+	return b.publisher.Close()
+}
@@ -0,0 +1,127 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/kafka"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockPublisher struct {
+	events []model.CloudEvent
+	data   [][]byte
+	err    error
+}
+
+func (m *mockPublisher) Publish(_ context.Context, event model.CloudEvent, data []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.events = append(m.events, event)
+	m.data = append(m.data, data)
+	return nil
+}
+
+func (m *mockPublisher) Close() error {
+	return nil
+}
+
+func executionEvent() model.Event {
+	executionID := 7
+	return model.Event{
+		ID:          uuid.MustParse("0b0e2c3e-3f5a-4b8e-9c1d-2e3f4a5b6c7d"),
+		Type:        model.EventExecutionFailed,
+		Time:        time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		JobID:       uuid.MustParse("6a8e1f0c-1d2b-4c3d-8e9f-0a1b2c3d4e5f"),
+		Namespace:   "billing",
+		ExecutionID: &executionID,
+		Error:       "connection refused",
+	}
+}
+
+func TestBusPublish(t *testing.T) {
+	publisher := &mockPublisher{}
+	bus := newBus(publisher, Settings{}, otelzap.New(zap.NewNop()))
+
+	require.NoError(t, bus.Publish(context.Background(), executionEvent()))
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "/scheduler", publisher.events[0].Source)
+	assert.Equal(t, string(model.EventExecutionFailed), publisher.events[0].Type)
+
+	// a failed publication leaves the event in the outbox
+	publisher.err = errors.New("broker unavailable")
+	assert.ErrorContains(t, bus.Publish(context.Background(), executionEvent()), "broker unavailable")
+}
+
+func TestNew(t *testing.T) {
+	log := otelzap.New(zap.NewNop())
+
+	_, err := New(Settings{Type: "sqs"}, nil, log)
+	assert.Error(t, err)
+
+	_, err = New(Settings{Type: TypeKafka, Kafka: KafkaSettings{RestProxyURL: "http://kafka-rest:8082"}}, nil, log)
+	assert.ErrorContains(t, err, "topic")
+
+	_, err = New(Settings{Type: TypeNATS}, nil, log)
+	assert.ErrorContains(t, err, "url")
+
+	_, err = New(Settings{Type: TypeAMQP, AMQP: AMQPSettings{URL: "amqp://localhost:5672/"}}, nil, log)
+	assert.ErrorContains(t, err, "exchange")
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var (
+		path        string
+		contentType string
+		records     kafka.Records
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &records))
+
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":12}]}`))
+	}))
+	defer server.Close()
+
+	bus, err := New(Settings{Type: TypeKafka, Kafka: KafkaSettings{RestProxyURL: server.URL, Topic: "scheduler-events"}},
+		server.Client(), otelzap.New(zap.NewNop()))
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(context.Background(), executionEvent()))
+	assert.Equal(t, "/topics/scheduler-events", path)
+	assert.Equal(t, "application/vnd.kafka.json.v2+json", contentType)
+	require.Len(t, records.Records, 1)
+	assert.Equal(t, "6a8e1f0c-1d2b-4c3d-8e9f-0a1b2c3d4e5f", records.Records[0].Key)
+
+	var ce model.CloudEvent
+	require.NoError(t, json.Unmarshal(records.Records[0].Value, &ce))
+	assert.Equal(t, "0b0e2c3e-3f5a-4b8e-9c1d-2e3f4a5b6c7d", ce.ID)
+}
+
+func TestKafkaPublisherError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"offsets":[{"error_code":50003,"error":"Kafka error"}]}`))
+	}))
+	defer server.Close()
+
+	publisher, err := newKafkaPublisher(KafkaSettings{RestProxyURL: server.URL, Topic: "events"}, server.Client())
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), model.NewCloudEvent(executionEvent(), "/scheduler", ""), []byte(`{}`))
+	assert.ErrorContains(t, err, "50003")
+}
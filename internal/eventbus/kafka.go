@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/kafka"
+)
+
+// KafkaSettings configure the publication to a Kafka topic, through a Confluent REST Proxy or a compatible API such
+// as Redpanda's HTTP Proxy.
+type KafkaSettings struct {
+	// RestProxyURL is the URL of the REST proxy, e.g. http://kafka-rest:8082
+	RestProxyURL string `mapstructure:"restProxyUrl" yaml:"restProxyUrl" json:"restProxyUrl,omitempty"`
+	Topic        string `mapstructure:"topic" yaml:"topic" json:"topic,omitempty"`
+	// Username and Password authenticate to the proxy with basic auth, if set
+	Username string `mapstructure:"username" yaml:"username" json:"username,omitempty"`
+	Password string `mapstructure:"password" yaml:"password" json:"-" redact:"true"`
+}
+
+// kafkaPublisher produces the events to the topic with the v2 API of the REST proxy. The records are keyed by the ID
+// of the job, so the events of a job land on the same partition, in order.
+type kafkaPublisher struct {
+	client *http.Client
+	proxy  kafka.Proxy
+	topic  string
+}
+
+func newKafkaPublisher(settings KafkaSettings, client *http.Client) (*kafkaPublisher, error) {
+	parsed, err := url.Parse(settings.RestProxyURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid kafka rest proxy url: %q", settings.RestProxyURL)
+	}
+
+	if settings.Topic == "" {
+		return nil, fmt.Errorf("the kafka event bus requires a topic")
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &kafkaPublisher{
+		client: client,
+		proxy:  kafka.Proxy{URL: settings.RestProxyURL, Username: settings.Username, Password: settings.Password},
+		topic:  settings.Topic,
+	}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, event model.CloudEvent, data []byte) error {
+	return kafka.Produce(ctx, p.client, p.proxy, p.topic, event.Subject, data)
+}
+
+func (p *kafkaPublisher) Close() error {
+	return nil
+}
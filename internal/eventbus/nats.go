@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSettings configure the publication to NATS subjects.
+type NATSSettings struct {
+	// URL of the NATS servers, e.g. nats://nats:4222, comma separated
+	URL string `mapstructure:"url" yaml:"url" json:"url,omitempty"`
+	// SubjectPrefix is prepended to the types of the events to form their subjects, e.g. scheduler.events gives
+	// scheduler.events.job.created
+	SubjectPrefix string `mapstructure:"subjectPrefix" yaml:"subjectPrefix" json:"subjectPrefix,omitempty"`
+	// CredentialsFile is the path of the user credentials file, if the servers require authentication
+	CredentialsFile string `mapstructure:"credentialsFile" yaml:"credentialsFile" json:"credentialsFile,omitempty"`
+}
+
+// natsPublisher publishes the events to a subject per event type. The publication waits for the server to process
+// it, but core NATS doesn't retain the events: only the subscribers connected at the time receive them, or a
+// JetStream stream capturing the subjects.
+type natsPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+func newNATSPublisher(settings NATSSettings) (*natsPublisher, error) {
+	if settings.URL == "" {
+		return nil, fmt.Errorf("the nats event bus requires a url")
+	}
+
+	prefix := settings.SubjectPrefix
+	if prefix == "" {
+		prefix = "scheduler.events"
+	}
+
+	options := []nats.Option{nats.Name("scheduler"), nats.MaxReconnects(-1)}
+	if settings.CredentialsFile != "" {
+		options = append(options, nats.UserCredentials(settings.CredentialsFile))
+	}
+
+	conn, err := nats.Connect(settings.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+
+	return &natsPublisher{conn: conn, prefix: prefix}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, event model.CloudEvent, data []byte) error {
+	msg := nats.NewMsg(p.prefix + "." + string(event.Data.Type))
+	msg.Header.Set("Content-Type", model.CloudEventContentType)
+	// lets JetStream streams deduplicate the events published again
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Data = data
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// subscriberBuffer is the number of events buffered per subscriber. Events for slow subscribers are dropped.
+	subscriberBuffer = 100
+
+	reconnectDelay = time.Second * 5
+)
+
+// Broker listens for job lifecycle events published by all scheduler instances
+// and fans them out to the subscribers in this instance.
+type Broker struct {
+	listener Listener
+	log      *otelzap.Logger
+
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+
+	// Add a context and cancel function to stop the broker
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Add a wait group to wait for the broker to stop
+	stopWg sync.WaitGroup
+
+	// Add a sync.Once to ensure the broker only starts once
+	startOnce sync.Once
+}
+
+type Listener interface {
+	ListenEvents(ctx context.Context, handler func(model.Event)) error
+}
+
+type Config struct {
+	Listener Listener
+	Log      *otelzap.Logger
+}
+
+type subscriber struct {
+	filter model.EventFilter
+	events chan model.Event
+}
+
+func NewBroker(cfg Config) *Broker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &Broker{
+		listener:    cfg.Listener,
+		log:         cfg.Log,
+		subscribers: map[*subscriber]struct{}{},
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	b.stopWg.Add(1)
+
+	return b
+}
+
+// Start starts listening for events in a separate goroutine, reconnecting if the connection fails.
+// Only the first call will start the broker, subsequent calls are ignored.
+func (b *Broker) Start() {
+	b.startOnce.Do(func() {
+		go func() {
+			defer b.stopWg.Done()
+
+			for {
+				err := b.listener.ListenEvents(b.ctx, b.dispatch)
+				if b.ctx.Err() != nil {
+					return
+				}
+
+				b.log.Error("Stopped listening for events, reconnecting", zap.Error(err))
+
+				select {
+				case <-time.After(reconnectDelay):
+				case <-b.ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop stops the broker and waits for it to stop or the context to expire.
+func (b *Broker) Stop(ctx context.Context) {
+	b.cancel()
+
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		b.stopWg.Wait()
+	}()
+
+	select {
+	case <-c:
+		b.log.Info("Event broker stopped")
+	case <-ctx.Done():
+		b.log.Warn("Timeout while stopping the event broker")
+	}
+}
+
+// Subscribe returns a channel receiving the events matching the filter.
+// The returned function must be called to unsubscribe once the events are no longer consumed.
+func (b *Broker) Subscribe(filter model.EventFilter) (<-chan model.Event, func()) {
+	sub := &subscriber{
+		filter: filter,
+		events: make(chan model.Event, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+		})
+	}
+}
+
+func (b *Broker) dispatch(event model.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			b.log.Warn("Dropping event for a slow subscriber", zap.String("type", string(event.Type)), zap.Any("jobID", event.JobID))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockListener struct {
+	events []model.Event
+}
+
+func (m *mockListener) ListenEvents(ctx context.Context, handler func(model.Event)) error {
+	// wait for the subscribers to subscribe
+	time.Sleep(time.Millisecond * 50)
+
+	for _, event := range m.events {
+		handler(event)
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestBroker(t *testing.T) {
+	listener := &mockListener{events: []model.Event{
+		{Type: model.EventJobCreated, JobID: uuid.New(), Tags: []string{"billing"}},
+		{Type: model.EventExecutionFailed, JobID: uuid.New(), Tags: []string{"billing"}},
+		{Type: model.EventExecutionFailed, JobID: uuid.New()},
+	}}
+
+	zapL, _ := zap.NewDevelopment()
+	broker := NewBroker(Config{Listener: listener, Log: otelzap.New(zapL)})
+
+	all, unsubscribeAll := broker.Subscribe(model.EventFilter{})
+	defer unsubscribeAll()
+
+	billingFailures, unsubscribe := broker.Subscribe(model.EventFilter{
+		Types: []model.EventType{model.EventExecutionFailed},
+		Tags:  []string{"billing"},
+	})
+	defer unsubscribe()
+
+	broker.Start()
+	defer broker.Stop(context.Background())
+
+	for _, expected := range listener.events {
+		select {
+		case event := <-all:
+			assert.Equal(t, expected, event)
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for an event")
+		}
+	}
+
+	select {
+	case event := <-billingFailures:
+		assert.Equal(t, listener.events[1], event)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for an event")
+	}
+
+	assert.Empty(t, billingFailures)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
 	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
 type amqpExecutor struct{}
@@ -44,6 +45,9 @@ func (ae *amqpExecutor) Execute(ctx context.Context, j *model.Job) error {
 		body = []byte(j.AMQPJob.Body)
 	}
 
+	log := Logger(ctx)
+	log.Info("Publishing AMQP message", zap.String("exchange", j.AMQPJob.Exchange), zap.String("routingKey", j.AMQPJob.RoutingKey), zap.Int("size", len(body)))
+
 	// Publish a message to the exchange
 	err = ch.PublishWithContext(
 		ctx,
@@ -53,13 +57,16 @@ func (ae *amqpExecutor) Execute(ctx context.Context, j *model.Job) error {
 		false,                // immediate
 		amqp.Publishing{
 			ContentType: j.AMQPJob.ContentType,
-			Headers:     j.AMQPJob.Headers,
+			Headers:     amqpHeaders(ctx, j.AMQPJob.Headers),
 			Body:        body,
 		},
 	)
 	if err != nil {
+		log.Error("Failed to publish AMQP message", zap.Error(err))
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
+	log.Info("Published AMQP message")
+
 	return nil
 }
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// CircuitBreakerSettings configure the circuit breaker middleware.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failed executions calling a target after which its circuit opens.
+	FailureThreshold int `mapstructure:"failureThreshold" yaml:"failureThreshold" json:"failureThreshold,omitempty"`
+	// Cooldown is how long the circuit of a target stays open. The next execution afterward closes the circuit if it
+	// succeeds, or opens it again if it fails.
+	Cooldown time.Duration `mapstructure:"cooldown" yaml:"cooldown" json:"cooldown,omitempty"`
+}
+
+// circuit tracks the consecutive failures of the executions calling a target.
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+type circuitBreaker struct {
+	settings CircuitBreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// CircuitBreaker returns a middleware failing the executions calling a target that failed repeatedly right away,
+// with ErrCircuitOpen, instead of calling it until the cooldown elapsed.
+func CircuitBreaker(settings CircuitBreakerSettings) Middleware {
+	breaker := &circuitBreaker{settings: settings, now: time.Now, circuits: map[string]*circuit{}}
+
+	return func(next Executor) Executor {
+		return executorFunc(func(ctx context.Context, job *model.Job) error {
+			target := circuitTarget(job)
+			if !breaker.allow(target) {
+				return errs.ErrCircuitOpen
+			}
+
+			err := next.Execute(ctx, job)
+			breaker.record(target, err)
+			return err
+		})
+	}
+}
+
+func (b *circuitBreaker) allow(target string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[target]
+	return !ok || !b.now().Before(c.openUntil)
+}
+
+func (b *circuitBreaker) record(target string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		delete(b.circuits, target)
+		return
+	}
+
+	c, ok := b.circuits[target]
+	if !ok {
+		c = &circuit{}
+		b.circuits[target] = c
+	}
+
+	c.failures++
+	if c.failures >= b.settings.FailureThreshold {
+		c.openUntil = b.now().Add(b.settings.Cooldown)
+	}
+}
+
+// circuitTarget identifies the target called by the job: the host of HTTP jobs, the broker and exchange of AMQP jobs,
+// and the job itself for custom job types.
+func circuitTarget(job *model.Job) string {
+	switch {
+	case job.HTTPJob != nil:
+		if parsed, err := url.Parse(job.HTTPJob.URL); err == nil {
+			return "http:" + parsed.Host
+		}
+	case job.AMQPJob != nil:
+		if parsed, err := url.Parse(job.AMQPJob.Connection); err == nil {
+			return "amqp:" + parsed.Host + "/" + job.AMQPJob.Exchange
+		}
+	}
+
+	return "job:" + job.ID.String()
+}
@@ -11,17 +11,34 @@ type Factory interface {
 }
 
 type factory struct {
-	client HttpClient
+	client  HttpClient
+	secrets SecretResolver
 }
 
-func NewFactory(client HttpClient) Factory {
-	return &factory{
+// FactoryOption configures the executor factory.
+type FactoryOption func(f *factory)
+
+// WithSecretResolver resolves the credentials of the jobs referring to secrets before each execution.
+func WithSecretResolver(resolver SecretResolver) FactoryOption {
+	return func(f *factory) {
+		f.secrets = resolver
+	}
+}
+
+func NewFactory(client HttpClient, options ...FactoryOption) Factory {
+	f := &factory{
 		client: client,
 	}
+
+	for _, option := range options {
+		option(f)
+	}
+
+	return f
 }
 
-// Option is a function that modifies an executor before it is returned (e.g. WithRetry)
-type Option func(executor Executor) Executor
+// Option is a middleware applied to an executor before it is returned (e.g. WithRetry)
+type Option = Middleware
 
 func (f *factory) NewExecutor(job *model.Job, options ...Option) (Executor, error) {
 
@@ -32,7 +49,21 @@ func (f *factory) NewExecutor(job *model.Job, options ...Option) (Executor, erro
 	case model.JobTypeAMQP:
 		executor = &amqpExecutor{}
 	default:
-		return nil, fmt.Errorf("unknown job type: %v", job.Type)
+		constructor, ok := registered(job.Type)
+		if !ok {
+			return nil, fmt.Errorf("unknown job type: %v", job.Type)
+		}
+
+		var err error
+		executor, err = constructor(job)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the executor of job type %v: %w", job.Type, err)
+		}
+	}
+
+	// the secrets are resolved on each attempt, within the middlewares of the options
+	if f.secrets != nil {
+		executor = resolveSecrets(f.secrets)(executor)
 	}
 
 	for _, option := range options {
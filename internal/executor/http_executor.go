@@ -9,6 +9,8 @@ import (
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
 	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
 )
 
 // HTTPSPrefix and HTTPPrefix are prefixes for HTTP and HTTPS protocols
@@ -33,15 +35,27 @@ func (he *httpExecutor) Execute(ctx context.Context, j *model.Job) error {
 		return err
 	}
 
+	log := Logger(ctx)
+	log.Info("Sending HTTP request", zap.String("method", req.Method), zap.String("url", req.URL.Redacted()))
+
 	// Send the request and get the response
 	resp, err := he.Client.Do(req)
 	if err != nil {
+		log.Error("HTTP request failed", zap.Error(err))
 		return err
 	}
 	defer resp.Body.Close()
 
+	log.Info("Received HTTP response", zap.Int("status", resp.StatusCode))
+
+	// Capture the response body as the output, of failed requests too
+	if err := WriteOutput(ctx, resp.Header.Get("Content-Type"), resp.Body); err != nil {
+		log.Warn("Failed to read the HTTP response body", zap.Error(err))
+	}
+
 	// Check if status code is one of the valid response codes
 	if !he.validResponseCode(resp.StatusCode, j.HTTPJob.ValidResponseCodes) {
+		log.Error("Invalid HTTP response code", zap.Int("status", resp.StatusCode), zap.Ints("validCodes", j.HTTPJob.ValidResponseCodes))
 		return errors.ErrInvalidResponseCode
 	}
 
@@ -83,6 +97,9 @@ func (he *httpExecutor) createHTTPRequest(ctx context.Context, j *model.Job) (*h
 	// Set the auth
 	he.setHTTPRequestAuth(req, j.HTTPJob.Auth)
 
+	// Link the traces of the target to the execution
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
 	return req, nil
 }
 
@@ -4,12 +4,17 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -55,6 +60,28 @@ func TestHTTPExecutor_Execute(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("captures the response body", func(t *testing.T) {
+		mockHttpClient := &MockHttpClient{
+			DoFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": {"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"status":"ok"}`)),
+				}, nil
+			},
+		}
+
+		output := NewExecutionOutput(1024)
+		httpExecutor := &httpExecutor{Client: mockHttpClient}
+		err := httpExecutor.Execute(WithOutput(ctx, output), j)
+		assert.Nil(t, err)
+
+		result, ok := output.Output(1)
+		assert.True(t, ok)
+		assert.Equal(t, "application/json", result.ContentType)
+		assert.Equal(t, `{"status":"ok"}`, decompress(t, result.Data))
+	})
+
 	t.Run("client error", func(t *testing.T) {
 		mockHttpClient := &MockHttpClient{
 			DoFunc: func(req *http.Request) (*http.Response, error) {
@@ -139,3 +166,22 @@ func TestHTTPExecutor_validResponseCode(t *testing.T) {
 	assert.True(t, httpExecutor.validResponseCode(http.StatusOK, validResponseCodes))
 	assert.False(t, httpExecutor.validResponseCode(http.StatusInternalServerError, validResponseCodes))
 }
+
+func TestHTTPExecutorEgressPolicy(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	client, err := egress.NewClient(egress.Settings{Enabled: true}, time.Second)
+	require.NoError(t, err)
+
+	j := &model.Job{Type: model.JobTypeHTTP, HTTPJob: &model.HTTPJob{Method: http.MethodGet, URL: server.URL}}
+
+	// the loopback address of the server is denied, and the denial isn't retried
+	executor := Retry(3)(&httpExecutor{Client: client})
+	err = executor.Execute(context.Background(), j)
+	assert.ErrorIs(t, err, egress.ErrDenied)
+	assert.Equal(t, 0, calls)
+}
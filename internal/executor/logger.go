@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying the execution logger, which executors retrieve with Logger.
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the execution logger from the context, or a no-op logger if there is none.
+func Logger(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+
+	return zap.NewNop()
+}
+
+// ExecutionLog collects the log entries of a single execution, up to a maximum size in bytes.
+// Once the limit is reached, further entries are dropped and the logs are marked as truncated.
+type ExecutionLog struct {
+	mu        sync.Mutex
+	maxSize   int
+	size      int
+	entries   []model.ExecutionLogEntry
+	truncated bool
+}
+
+// NewExecutionLog creates an execution log with the given size limit and a logger writing to it.
+func NewExecutionLog(maxSize int) (*ExecutionLog, *zap.Logger) {
+	log := &ExecutionLog{maxSize: maxSize, entries: []model.ExecutionLogEntry{}}
+	return log, zap.New(&executionLogCore{log: log, level: zapcore.DebugLevel})
+}
+
+// Logs returns the collected entries of the execution.
+func (l *ExecutionLog) Logs(executionID int) model.ExecutionLogs {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return model.ExecutionLogs{
+		ExecutionID: executionID,
+		Entries:     append([]model.ExecutionLogEntry{}, l.entries...),
+		Truncated:   l.truncated,
+	}
+}
+
+func (l *ExecutionLog) add(entry model.ExecutionLogEntry) {
+	// approximate the stored size of the entry by its JSON representation
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.truncated || l.size+len(data) > l.maxSize {
+		l.truncated = true
+		return
+	}
+
+	l.size += len(data)
+	l.entries = append(l.entries, entry)
+}
+
+// executionLogCore is a zapcore.Core writing the entries to an ExecutionLog.
+type executionLogCore struct {
+	log    *ExecutionLog
+	level  zapcore.Level
+	fields []zapcore.Field
+}
+
+func (c *executionLogCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *executionLogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &executionLogCore{
+		log:    c.log,
+		level:  c.level,
+		fields: append(append([]zapcore.Field{}, c.fields...), fields...),
+	}
+}
+
+func (c *executionLogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *executionLogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range append(c.fields, fields...) {
+		field.AddTo(encoder)
+	}
+
+	logEntry := model.ExecutionLogEntry{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+	}
+	if len(encoder.Fields) > 0 {
+		logEntry.Fields = encoder.Fields
+	}
+
+	c.log.add(logEntry)
+	return nil
+}
+
+func (c *executionLogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestExecutionLog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Collects structured entries", func(t *testing.T) {
+		log, logger := NewExecutionLog(1024)
+
+		logger.With(zap.String("job", "test")).Info("Sending request", zap.Int("attempt", 1))
+		logger.Error("Request failed")
+
+		logs := log.Logs(42)
+		assert.Equal(t, 42, logs.ExecutionID)
+		assert.False(t, logs.Truncated)
+		assert.Len(t, logs.Entries, 2)
+
+		assert.Equal(t, "info", logs.Entries[0].Level)
+		assert.Equal(t, "Sending request", logs.Entries[0].Message)
+		assert.Equal(t, map[string]interface{}{"job": "test", "attempt": int64(1)}, logs.Entries[0].Fields)
+
+		assert.Equal(t, "error", logs.Entries[1].Level)
+		assert.Nil(t, logs.Entries[1].Fields)
+	})
+
+	t.Run("Truncates logs exceeding the size limit", func(t *testing.T) {
+		log, logger := NewExecutionLog(200)
+
+		for i := 0; i < 10; i++ {
+			logger.Info("Sending request", zap.Int("attempt", i))
+		}
+
+		logs := log.Logs(1)
+		assert.True(t, logs.Truncated)
+		assert.NotEmpty(t, logs.Entries)
+		assert.Less(t, len(logs.Entries), 10)
+	})
+
+	t.Run("Missing logger in context", func(t *testing.T) {
+		assert.NotNil(t, Logger(context.Background()))
+
+		_, logger := NewExecutionLog(1024)
+		assert.Equal(t, logger, Logger(WithLogger(context.Background(), logger)))
+	})
+}
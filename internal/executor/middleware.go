@@ -0,0 +1,188 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Middleware wraps an executor to add a cross-cutting behavior to its executions, e.g. retries or timeouts.
+type Middleware func(next Executor) Executor
+
+// Chain composes the middlewares into one, the first middleware being the outermost.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Executor) Executor {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+
+		return next
+	}
+}
+
+// executorFunc adapts a function to an Executor.
+type executorFunc func(ctx context.Context, job *model.Job) error
+
+func (f executorFunc) Execute(ctx context.Context, job *model.Job) error {
+	return f(ctx, job)
+}
+
+// Names of the built-in middlewares, as used in the middleware settings.
+const (
+	MiddlewareRetry          = "retry"
+	MiddlewareTimeout        = "timeout"
+	MiddlewareCircuitBreaker = "circuitBreaker"
+	MiddlewareMetrics        = "metrics"
+)
+
+var (
+	middlewaresMu sync.RWMutex
+	middlewares   = map[string]Middleware{}
+)
+
+// RegisterMiddleware registers a custom middleware, which the middleware settings refer to by its name.
+// Registering a middleware again replaces it.
+func RegisterMiddleware(name string, middleware Middleware) error {
+	switch name {
+	case "", MiddlewareRetry, MiddlewareTimeout, MiddlewareCircuitBreaker, MiddlewareMetrics:
+		return fmt.Errorf("invalid middleware name: %q", name)
+	}
+
+	middlewaresMu.Lock()
+	defer middlewaresMu.Unlock()
+
+	middlewares[name] = middleware
+	return nil
+}
+
+func registeredMiddleware(name string) (Middleware, bool) {
+	middlewaresMu.RLock()
+	defer middlewaresMu.RUnlock()
+
+	middleware, ok := middlewares[name]
+	return middleware, ok
+}
+
+// MiddlewareSettings configure the middleware chain wrapping the executors of a runner.
+type MiddlewareSettings struct {
+	// Chain lists the middlewares wrapping the executors, from the outermost: retry, timeout, circuitBreaker, metrics
+	// or the name of a registered custom middleware. An unset chain only retries the executions.
+	Chain []string `mapstructure:"chain" yaml:"chain" json:"chain,omitempty"`
+	// MaxRetries is the number of times the retry middleware retries a failed execution, 3 if unset.
+	MaxRetries int `mapstructure:"maxRetries" yaml:"maxRetries" json:"maxRetries,omitempty"`
+	// Timeout is the time the timeout middleware allows the wrapped executor, per attempt if it is wrapped in the
+	// retry middleware.
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout" json:"timeout,omitempty"`
+	// CircuitBreaker configures the circuit breaker middleware.
+	CircuitBreaker CircuitBreakerSettings `mapstructure:"circuitBreaker" yaml:"circuitBreaker" json:"circuitBreaker"`
+}
+
+// Validate reports unknown middlewares and invalid middleware parameters.
+func (s MiddlewareSettings) Validate() error {
+	for _, name := range s.Chain {
+		switch name {
+		case MiddlewareRetry, MiddlewareMetrics:
+		case MiddlewareTimeout:
+			if s.Timeout <= 0 {
+				return errors.New("the timeout middleware requires a positive timeout")
+			}
+		case MiddlewareCircuitBreaker:
+			if s.CircuitBreaker.FailureThreshold <= 0 || s.CircuitBreaker.Cooldown <= 0 {
+				return errors.New("the circuit breaker middleware requires a positive failure threshold and cooldown")
+			}
+		default:
+			if _, ok := registeredMiddleware(name); !ok {
+				return fmt.Errorf("unknown middleware: %q", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Build builds the middleware chain. The state of the stateful middlewares, e.g. the circuit breaker, is shared by
+// the executors wrapped by the chain.
+func (s MiddlewareSettings) Build(runnerMetrics *metrics.RunnerMetrics) (Middleware, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	names := s.Chain
+	if names == nil {
+		names = []string{MiddlewareRetry}
+	}
+
+	chain := make([]Middleware, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case MiddlewareRetry:
+			chain = append(chain, Retry(s.MaxRetries))
+		case MiddlewareTimeout:
+			chain = append(chain, Timeout(s.Timeout))
+		case MiddlewareCircuitBreaker:
+			chain = append(chain, CircuitBreaker(s.CircuitBreaker))
+		case MiddlewareMetrics:
+			chain = append(chain, Metrics(runnerMetrics))
+		default:
+			middleware, _ := registeredMiddleware(name)
+			chain = append(chain, middleware)
+		}
+	}
+
+	return Chain(chain...), nil
+}
+
+// Timeout returns a middleware aborting the executions taking longer than the timeout, which are recorded as
+// timed out.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Executor) Executor {
+		return executorFunc(func(ctx context.Context, job *model.Job) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := next.Execute(ctx, job)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w: %w", errs.ErrExecutionTimedOut, err)
+			}
+
+			return err
+		})
+	}
+}
+
+// Metrics returns a middleware counting the execution attempts by outcome.
+func Metrics(runnerMetrics *metrics.RunnerMetrics) Middleware {
+	if runnerMetrics == nil {
+		runnerMetrics = &metrics.RunnerMetrics{}
+	}
+
+	return func(next Executor) Executor {
+		return executorFunc(func(ctx context.Context, job *model.Job) error {
+			start := time.Now()
+			err := next.Execute(ctx, job)
+
+			outcome := "success"
+			switch {
+			case errors.Is(err, errs.ErrCircuitOpen):
+				outcome = "circuit_open"
+			case err != nil:
+				outcome = "failure"
+			}
+
+			runnerMetrics.RecordExecutorAttempt(ctx, time.Since(start).Seconds(),
+				attribute.String("job_type", string(job.Type)),
+				attribute.String("namespace", job.Namespace),
+				attribute.String("outcome", outcome),
+			)
+
+			return err
+		})
+	}
+}
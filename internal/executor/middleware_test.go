@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Executor) Executor {
+			return executorFunc(func(ctx context.Context, job *model.Job) error {
+				calls = append(calls, name)
+				return next.Execute(ctx, job)
+			})
+		}
+	}
+
+	executor := Chain(record("outer"), record("inner"))(&MockExecutor{})
+	assert.NoError(t, executor.Execute(context.Background(), &model.Job{}))
+	assert.Equal(t, []string{"outer", "inner"}, calls)
+}
+
+func TestTimeout(t *testing.T) {
+	blocking := executorFunc(func(ctx context.Context, job *model.Job) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := Timeout(10*time.Millisecond)(blocking).Execute(context.Background(), &model.Job{})
+	assert.ErrorIs(t, err, errs.ErrExecutionTimedOut)
+
+	err = Timeout(time.Second)(&MockExecutor{}).Execute(context.Background(), &model.Job{})
+	assert.NoError(t, err)
+}
+
+func TestMiddlewareSettings(t *testing.T) {
+	require.NoError(t, RegisterMiddleware("audit", func(next Executor) Executor { return next }))
+	assert.Error(t, RegisterMiddleware(MiddlewareRetry, func(next Executor) Executor { return next }))
+
+	tests := []struct {
+		name     string
+		settings MiddlewareSettings
+		valid    bool
+	}{
+		{name: "unset", settings: MiddlewareSettings{}, valid: true},
+		{name: "built-in middlewares", settings: MiddlewareSettings{
+			Chain:          []string{MiddlewareMetrics, MiddlewareRetry, MiddlewareCircuitBreaker, MiddlewareTimeout},
+			Timeout:        time.Second,
+			CircuitBreaker: CircuitBreakerSettings{FailureThreshold: 5, Cooldown: time.Minute},
+		}, valid: true},
+		{name: "custom middleware", settings: MiddlewareSettings{Chain: []string{"audit", MiddlewareRetry}}, valid: true},
+		{name: "unknown middleware", settings: MiddlewareSettings{Chain: []string{"unknown"}}},
+		{name: "timeout without duration", settings: MiddlewareSettings{Chain: []string{MiddlewareTimeout}}},
+		{name: "circuit breaker without threshold", settings: MiddlewareSettings{
+			Chain:          []string{MiddlewareCircuitBreaker},
+			CircuitBreaker: CircuitBreakerSettings{Cooldown: time.Minute},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			middleware, err := tc.settings.Build(nil)
+			if !tc.valid {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, middleware(&MockExecutor{}))
+		})
+	}
+}
+
+func TestUnsetChainRetries(t *testing.T) {
+	middleware, err := MiddlewareSettings{}.Build(nil)
+	require.NoError(t, err)
+	assert.IsType(t, &retryExecutor{}, middleware(&MockExecutor{}))
+
+	// an empty chain leaves the executor as is
+	middleware, err = MiddlewareSettings{Chain: []string{}}.Build(nil)
+	require.NoError(t, err)
+	assert.IsType(t, &MockExecutor{}, middleware(&MockExecutor{}))
+}
+
+func TestRetryStopsOnOpenCircuit(t *testing.T) {
+	calls := 0
+	failing := executorFunc(func(ctx context.Context, job *model.Job) error {
+		calls++
+		return errors.New("unreachable")
+	})
+
+	executor := Chain(Retry(3), CircuitBreaker(CircuitBreakerSettings{FailureThreshold: 1, Cooldown: time.Minute}))(failing)
+	err := executor.Execute(context.Background(), &model.Job{})
+	assert.ErrorIs(t, err, errs.ErrCircuitOpen)
+	assert.Equal(t, 1, calls)
+}
@@ -2,31 +2,67 @@ package executor
 
 import (
 	"context"
+	"errors"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
 	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
 )
 
 // RetryExecutor struct encapsulates an executor and adds retry functionality
 type retryExecutor struct {
-	executor Executor
+	executor   Executor
+	maxRetries uint64
 }
 
 // WithRetry wraps an executor with a retry mechanism
 func WithRetry(executor Executor) Executor {
-	return &retryExecutor{executor: executor}
+	return Retry(defaultMaxRetries)(executor)
 }
 
-const maxRetries = 3
+// Retry returns a middleware retrying failed executions up to maxRetries times, with an exponential backoff.
+// The default number of retries is used if maxRetries isn't positive.
+func Retry(maxRetries int) Middleware {
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	return func(executor Executor) Executor {
+		return &retryExecutor{executor: executor, maxRetries: uint64(maxRetries)}
+	}
+}
+
+const defaultMaxRetries = 3
 
 // Execute applies the retry mechanism on the execution of the job
 func (re *retryExecutor) Execute(ctx context.Context, job *model.Job) error {
-	// Define your backoff strategy
-	bo := backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries)
+	// Define your backoff strategy, stopping the retries once the context is cancelled
+	bo := backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), re.maxRetries), ctx)
+
+	log := Logger(ctx)
+	attempt := 0
 
 	// Use the backoff.Retry function with your execute function
 	err := backoff.Retry(func() error {
-		return re.executor.Execute(ctx, job)
+		attempt++
+		if attempt > 1 {
+			RecordEvent(ctx, model.ExecutionEventRetried, attempt, nil)
+		}
+
+		err := re.executor.Execute(ctx, job)
+		if err != nil {
+			log.Warn("Execution attempt failed", zap.Int("attempt", attempt), zap.Error(err))
+			RecordEvent(ctx, model.ExecutionEventAttemptFailed, attempt, err)
+		}
+
+		// the target is not called while the circuit is open or if the egress policy denies it, retrying would only
+		// delay the failure
+		if errors.Is(err, errs.ErrCircuitOpen) || errors.Is(err, egress.ErrDenied) {
+			return backoff.Permanent(err)
+		}
+		return err
 	}, bo)
 
 	return err
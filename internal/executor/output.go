@@ -0,0 +1,123 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/klauspost/compress/zstd"
+)
+
+// errOutputFull stops copying the output once the size limit is reached.
+var errOutputFull = errors.New("execution output size limit reached")
+
+type outputKey struct{}
+
+// WithOutput returns a context carrying the output of the execution, which executors write to with WriteOutput.
+func WithOutput(ctx context.Context, output *ExecutionOutput) context.Context {
+	return context.WithValue(ctx, outputKey{}, output)
+}
+
+// WriteOutput copies the output of the execution from r, e.g. the body of an HTTP response, and records its content
+// type. The output beyond the size limit is dropped without being read. It is a no-op if the context carries no
+// output.
+func WriteOutput(ctx context.Context, contentType string, r io.Reader) error {
+	output, ok := ctx.Value(outputKey{}).(*ExecutionOutput)
+	if !ok {
+		return nil
+	}
+
+	output.setContentType(contentType)
+
+	_, err := io.Copy(output, r)
+	if errors.Is(err, errOutputFull) {
+		return nil
+	}
+
+	return err
+}
+
+// ExecutionOutput captures the output of a single execution, compressed with zstd as it is written, up to a maximum
+// size in bytes before compression. Once the limit is reached, the rest of the output is dropped and the output is
+// marked as truncated.
+type ExecutionOutput struct {
+	mu          sync.Mutex
+	maxSize     int64
+	size        int64
+	truncated   bool
+	contentType string
+	buf         bytes.Buffer
+	encoder     *zstd.Encoder
+}
+
+// NewExecutionOutput creates an execution output with the given size limit.
+func NewExecutionOutput(maxSize int64) *ExecutionOutput {
+	return &ExecutionOutput{maxSize: maxSize}
+}
+
+func (o *ExecutionOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.encoder == nil {
+		// a single goroutine and a small window, as many executions may capture their output at once
+		encoder, err := zstd.NewWriter(&o.buf, zstd.WithEncoderConcurrency(1), zstd.WithLowerEncoderMem(true),
+			zstd.WithWindowSize(1<<20))
+		if err != nil {
+			return 0, err
+		}
+		o.encoder = encoder
+	}
+
+	n := len(p)
+	if remaining := o.maxSize - o.size; int64(n) > remaining {
+		n = int(max(remaining, 0))
+	}
+
+	written, err := o.encoder.Write(p[:n])
+	o.size += int64(written)
+	if err != nil {
+		return written, err
+	}
+
+	if written < len(p) {
+		o.truncated = true
+		return written, errOutputFull
+	}
+
+	return written, nil
+}
+
+func (o *ExecutionOutput) setContentType(contentType string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.contentType = contentType
+}
+
+// Output returns the compressed output of the execution, false if the executor wrote none.
+func (o *ExecutionOutput) Output(executionID int) (model.ExecutionOutput, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.encoder == nil {
+		return model.ExecutionOutput{}, false
+	}
+
+	// closing flushes the frame, the output can't be written to afterward
+	if err := o.encoder.Close(); err != nil {
+		return model.ExecutionOutput{}, false
+	}
+
+	return model.ExecutionOutput{
+		ExecutionID: executionID,
+		ContentType: o.contentType,
+		Size:        o.size,
+		Truncated:   o.truncated,
+		Encoding:    model.ExecutionOutputEncodingZstd,
+		Data:        bytes.Clone(o.buf.Bytes()),
+	}, true
+}
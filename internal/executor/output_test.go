@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decompress(t *testing.T, data []byte) string {
+	decoder, err := zstd.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer decoder.Close()
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(decoder)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func TestExecutionOutput(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Compresses the output", func(t *testing.T) {
+		output := NewExecutionOutput(1024 * 1024)
+		body := strings.Repeat(`{"status":"ok"}`, 1000)
+
+		ctx := WithOutput(context.Background(), output)
+		assert.NoError(t, WriteOutput(ctx, "application/json", strings.NewReader(body)))
+
+		result, ok := output.Output(42)
+		assert.True(t, ok)
+		assert.Equal(t, 42, result.ExecutionID)
+		assert.Equal(t, "application/json", result.ContentType)
+		assert.Equal(t, model.ExecutionOutputEncodingZstd, result.Encoding)
+		assert.Equal(t, int64(len(body)), result.Size)
+		assert.False(t, result.Truncated)
+		assert.Less(t, len(result.Data), len(body))
+		assert.Equal(t, body, decompress(t, result.Data))
+	})
+
+	t.Run("Truncates the output exceeding the size limit", func(t *testing.T) {
+		output := NewExecutionOutput(100)
+
+		ctx := WithOutput(context.Background(), output)
+		assert.NoError(t, WriteOutput(ctx, "text/plain", strings.NewReader(strings.Repeat("a", 1000))))
+
+		result, ok := output.Output(1)
+		assert.True(t, ok)
+		assert.True(t, result.Truncated)
+		assert.Equal(t, int64(100), result.Size)
+		assert.Equal(t, strings.Repeat("a", 100), decompress(t, result.Data))
+	})
+
+	t.Run("No output written", func(t *testing.T) {
+		_, ok := NewExecutionOutput(100).Output(1)
+		assert.False(t, ok)
+
+		// executions not capturing their output ignore it
+		assert.NoError(t, WriteOutput(context.Background(), "text/plain", strings.NewReader("ignored")))
+	})
+}
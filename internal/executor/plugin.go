@@ -0,0 +1,19 @@
+package executor
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugins opens the Go plugins at the paths. Plugins register their job types and executors from their init
+// functions, with RegisterExecutor of the scheduler package. They must be built with the same Go version and
+// dependencies as the binary loading them.
+func LoadPlugins(paths []string) error {
+	for _, path := range paths {
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("failed to load executor plugin %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
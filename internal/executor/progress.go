@@ -0,0 +1,24 @@
+package executor
+
+import "context"
+
+// ProgressReporter records the progress of an execution, as a percentage of the work done and an optional message.
+type ProgressReporter func(percent int, message string) error
+
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a context carrying the progress reporter of the execution, which executors call
+// through ReportProgress.
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, reporter)
+}
+
+// ReportProgress reports the progress of the execution, e.g. after each processed batch of a long-running job.
+// It is a no-op if the context carries no progress reporter.
+func ReportProgress(ctx context.Context, percent int, message string) error {
+	if reporter, ok := ctx.Value(progressReporterKey{}).(ProgressReporter); ok {
+		return reporter(percent, message)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportProgress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("No-op without a reporter", func(t *testing.T) {
+		assert.NoError(t, ReportProgress(context.Background(), 50, "Halfway"))
+	})
+
+	t.Run("Calls the reporter of the context", func(t *testing.T) {
+		var percent int
+		var message string
+		ctx := WithProgressReporter(context.Background(), func(p int, m string) error {
+			percent, message = p, m
+			return nil
+		})
+
+		assert.NoError(t, ReportProgress(ctx, 50, "Halfway"))
+		assert.Equal(t, 50, percent)
+		assert.Equal(t, "Halfway", message)
+	})
+}
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// Constructor creates the executor of a job of a custom type.
+type Constructor func(job *model.Job) (Executor, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[model.JobType]Constructor{}
+)
+
+// Register registers a custom job type along with the constructor of its executors, so the factory creates them
+// without changes to its built-in job types. Registering a job type again replaces its constructor.
+func Register(jobType model.JobType, constructor Constructor) error {
+	if err := model.RegisterJobType(jobType); err != nil {
+		return err
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[jobType] = constructor
+	return nil
+}
+
+// registered returns the constructor registered for the job type.
+func registered(jobType model.JobType) (Constructor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	constructor, ok := registry[jobType]
+	return constructor, ok
+}
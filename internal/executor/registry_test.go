@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type customExecutor struct{}
+
+func (e *customExecutor) Execute(ctx context.Context, job *model.Job) error {
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	factory := NewFactory(&http.Client{})
+	j := &model.Job{Type: "EXECUTOR_TEST"}
+
+	_, err := factory.NewExecutor(j)
+	assert.Error(t, err)
+
+	require.NoError(t, Register("EXECUTOR_TEST", func(job *model.Job) (Executor, error) {
+		return &customExecutor{}, nil
+	}))
+	assert.True(t, j.Type.Valid())
+
+	executor, err := factory.NewExecutor(j)
+	assert.NoError(t, err)
+	assert.IsType(t, &customExecutor{}, executor)
+
+	executor, err = factory.NewExecutor(j, WithRetry)
+	assert.NoError(t, err)
+	assert.IsType(t, &retryExecutor{}, executor)
+
+	// constructor errors are returned by the factory
+	require.NoError(t, Register("EXECUTOR_TEST", func(job *model.Job) (Executor, error) {
+		return nil, errors.New("invalid definition")
+	}))
+	_, err = factory.NewExecutor(j)
+	assert.ErrorContains(t, err, "invalid definition")
+
+	assert.Error(t, Register(model.JobTypeHTTP, func(job *model.Job) (Executor, error) {
+		return &customExecutor{}, nil
+	}))
+}
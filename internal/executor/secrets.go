@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"gopkg.in/guregu/null.v4"
+)
+
+// SecretResolver resolves the credentials of the jobs that refer to secrets of a secret manager, e.g.
+// vault:secret/data/payments#token, and returns the other credentials as they are.
+type SecretResolver interface {
+	Resolve(ctx context.Context, value string) (string, error)
+}
+
+// resolveSecrets resolves the credentials of the job before each execution of the executor, so the secrets are only
+// held in memory for the execution and their rotations are picked up. The job itself is left untouched.
+func resolveSecrets(resolver SecretResolver) Middleware {
+	return func(next Executor) Executor {
+		return executorFunc(func(ctx context.Context, job *model.Job) error {
+			resolved, err := resolveJobSecrets(ctx, resolver, job)
+			if err != nil {
+				return fmt.Errorf("failed to resolve the secrets of the job: %w", err)
+			}
+
+			return next.Execute(ctx, resolved)
+		})
+	}
+}
+
+// resolveJobSecrets returns a copy of the job with its basic auth, bearer token and AMQP connection resolved.
+func resolveJobSecrets(ctx context.Context, resolver SecretResolver, job *model.Job) (*model.Job, error) {
+	resolved := *job
+
+	if job.HTTPJob != nil {
+		httpJob := *job.HTTPJob
+		for _, field := range []*null.String{&httpJob.Auth.Username, &httpJob.Auth.Password, &httpJob.Auth.BearerToken} {
+			if !field.Valid {
+				continue
+			}
+
+			secret, err := resolver.Resolve(ctx, field.String)
+			if err != nil {
+				return nil, err
+			}
+			*field = null.StringFrom(secret)
+		}
+		resolved.HTTPJob = &httpJob
+	}
+
+	if job.AMQPJob != nil {
+		amqpJob := *job.AMQPJob
+		connection, err := resolver.Resolve(ctx, amqpJob.Connection)
+		if err != nil {
+			return nil, err
+		}
+		amqpJob.Connection = connection
+		resolved.AMQPJob = &amqpJob
+	}
+
+	return &resolved, nil
+}
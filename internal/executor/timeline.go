@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"gopkg.in/guregu/null.v4"
+)
+
+type timelineKey struct{}
+
+// WithTimeline returns a context carrying the timeline of the execution, which executors record events to with
+// RecordEvent.
+func WithTimeline(ctx context.Context, timeline *ExecutionTimeline) context.Context {
+	return context.WithValue(ctx, timelineKey{}, timeline)
+}
+
+// RecordEvent records an event to the timeline of the execution, with the number of the attempt of the executor if
+// it is positive and the error if any. It is a no-op if the context carries no timeline.
+func RecordEvent(ctx context.Context, eventType model.ExecutionEventType, attempt int, err error) {
+	timeline, ok := ctx.Value(timelineKey{}).(*ExecutionTimeline)
+	if !ok {
+		return
+	}
+
+	timeline.Record(eventType, attempt, err)
+}
+
+// ExecutionTimeline collects the events of a single execution recorded by the runner.
+type ExecutionTimeline struct {
+	mu         sync.Mutex
+	instanceID string
+	events     []model.ExecutionEvent
+}
+
+// NewExecutionTimeline creates the timeline of an execution run by the given runner instance.
+func NewExecutionTimeline(instanceID string) *ExecutionTimeline {
+	return &ExecutionTimeline{instanceID: instanceID}
+}
+
+// Record records an event, with the number of the attempt of the executor if it is positive and the error if any.
+func (t *ExecutionTimeline) Record(eventType model.ExecutionEventType, attempt int, err error) {
+	event := model.ExecutionEvent{
+		Type:       eventType,
+		Time:       time.Now(),
+		Attempt:    null.NewInt(int64(attempt), attempt > 0),
+		InstanceID: null.NewString(t.instanceID, t.instanceID != ""),
+	}
+	if err != nil {
+		event.Message = null.StringFrom(err.Error())
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+// Events returns the recorded events of the execution, oldest first.
+func (t *ExecutionTimeline) Events(executionID int) []model.ExecutionEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]model.ExecutionEvent, 0, len(t.events))
+	for _, event := range t.events {
+		event.ExecutionID = executionID
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionTimeline(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Records the attempts of the executor", func(t *testing.T) {
+		timeline := NewExecutionTimeline("runner-1")
+		ctx := WithTimeline(context.Background(), timeline)
+
+		RecordEvent(ctx, model.ExecutionEventStarted, 0, nil)
+		err := WithRetry(&MockExecutor{ShouldFail: true, FailuresLeft: 1}).Execute(ctx, &model.Job{})
+		assert.NoError(t, err)
+
+		events := timeline.Events(42)
+		types := make([]model.ExecutionEventType, 0, len(events))
+		for _, event := range events {
+			assert.Equal(t, 42, event.ExecutionID)
+			assert.Equal(t, "runner-1", event.InstanceID.String)
+			types = append(types, event.Type)
+		}
+		assert.Equal(t, []model.ExecutionEventType{model.ExecutionEventStarted, model.ExecutionEventAttemptFailed, model.ExecutionEventRetried}, types)
+
+		assert.False(t, events[0].Attempt.Valid)
+		assert.Equal(t, int64(1), events[1].Attempt.Int64)
+		assert.Equal(t, "execute error", events[1].Message.String)
+		assert.Equal(t, int64(2), events[2].Attempt.Int64)
+		assert.False(t, events[2].Message.Valid)
+	})
+
+	t.Run("Missing timeline in context", func(t *testing.T) {
+		RecordEvent(context.Background(), model.ExecutionEventAttemptFailed, 1, errors.New("ignored"))
+
+		assert.Empty(t, NewExecutionTimeline("").Events(1))
+	})
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// propagator injects the trace context of the execution into the requests and messages sent to the job targets as
+// W3C traceparent and tracestate headers, so the traces of the targets link back to the execution. Nothing is
+// injected if the context doesn't carry a span, e.g. when tracing is disabled.
+var propagator = propagation.TraceContext{}
+
+// amqpHeaderCarrier adapts the headers of an AMQP message to a propagation.TextMapCarrier.
+type amqpHeaderCarrier amqp.Table
+
+func (c amqpHeaderCarrier) Get(key string) string {
+	value, ok := c[key]
+	if !ok {
+		return ""
+	}
+
+	if s, ok := value.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(value)
+}
+
+func (c amqpHeaderCarrier) Set(key string, value string) {
+	c[key] = value
+}
+
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// amqpHeaders returns the headers of the job with the trace context of the execution, leaving the job unchanged.
+func amqpHeaders(ctx context.Context, headers map[string]interface{}) amqp.Table {
+	table := make(amqp.Table, len(headers)+2)
+	for key, value := range headers {
+		table[key] = value
+	}
+
+	propagator.Inject(ctx, amqpHeaderCarrier(table))
+
+	return table
+}
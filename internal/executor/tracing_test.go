@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func tracedContext() context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTracePropagation(t *testing.T) {
+	t.Run("HTTP requests carry the trace context", func(t *testing.T) {
+		j := &model.Job{HTTPJob: &model.HTTPJob{Method: "GET", URL: "www.example.com"}}
+
+		req, err := (&httpExecutor{}).createHTTPRequest(tracedContext(), j)
+		assert.NoError(t, err)
+		assert.Equal(t, traceparent, req.Header.Get("traceparent"))
+	})
+
+	t.Run("HTTP requests without a span are left alone", func(t *testing.T) {
+		j := &model.Job{HTTPJob: &model.HTTPJob{Method: "GET", URL: "www.example.com"}}
+
+		req, err := (&httpExecutor{}).createHTTPRequest(context.Background(), j)
+		assert.NoError(t, err)
+		assert.Empty(t, req.Header.Get("traceparent"))
+	})
+
+	t.Run("AMQP messages carry the trace context", func(t *testing.T) {
+		jobHeaders := map[string]interface{}{"x-delay": 10000}
+
+		headers := amqpHeaders(tracedContext(), jobHeaders)
+		assert.Equal(t, traceparent, headers["traceparent"])
+		assert.Equal(t, 10000, headers["x-delay"])
+
+		// the headers of the job are unchanged
+		assert.NotContains(t, jobHeaders, "traceparent")
+	})
+}
@@ -0,0 +1,182 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiSettings configure the Loki sink.
+type LokiSettings struct {
+	// URL of Loki, e.g. http://loki:3100. The logs are pushed to its /loki/api/v1/push endpoint.
+	URL string `mapstructure:"url" yaml:"url" json:"url,omitempty"`
+	// TenantID is sent in the X-Scope-OrgID header, for multi-tenant Loki deployments
+	TenantID string `mapstructure:"tenantId" yaml:"tenantId" json:"tenantId,omitempty"`
+	// Labels added to the streams, e.g. the environment
+	Labels map[string]string `mapstructure:"labels" yaml:"labels" json:"labels,omitempty"`
+	// Username and Password authenticate the pushes with basic auth, if set
+	Username string `mapstructure:"username" yaml:"username" json:"username,omitempty"`
+	Password string `mapstructure:"password" yaml:"password" json:"-" redact:"true"`
+}
+
+// lokiSink pushes the logs and outputs to Loki, in streams labelled with the job type, namespace and stream (log or
+// output) of the executions. The job and execution IDs are in the lines, to keep the cardinality of the streams low.
+type lokiSink struct {
+	client   *http.Client
+	url      string
+	settings LokiSettings
+	now      func() time.Time
+}
+
+func newLokiSink(settings LokiSettings, client *http.Client) (*lokiSink, error) {
+	parsed, err := url.Parse(settings.URL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid loki url: %q", settings.URL)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &lokiSink{
+		client:   client,
+		url:      strings.TrimSuffix(settings.URL, "/") + "/loki/api/v1/push",
+		settings: settings,
+		now:      time.Now,
+	}, nil
+}
+
+// lokiPush is the body of a push request.
+type lokiPush struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are pairs of a timestamp in nanoseconds and a line
+	Values [][2]string `json:"values"`
+}
+
+// lokiLine is a line pushed to Loki.
+type lokiLine struct {
+	JobID       string         `json:"job_id"`
+	ExecutionID int            `json:"execution_id"`
+	Instance    string         `json:"instance,omitempty"`
+	Level       string         `json:"level,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty"`
+	ContentType string         `json:"content_type,omitempty"`
+	Truncated   bool           `json:"truncated,omitempty"`
+	Output      string         `json:"output,omitempty"`
+}
+
+func (s *lokiSink) Ship(ctx context.Context, execution Execution) error {
+	push := lokiPush{}
+	base := lokiLine{JobID: execution.JobID.String(), ExecutionID: execution.ExecutionID, Instance: execution.Instance}
+
+	if execution.Logs != nil && len(execution.Logs.Entries) > 0 {
+		stream := lokiStream{Stream: s.labels(execution, streamLog)}
+		for _, entry := range execution.Logs.Entries {
+			line := base
+			line.Level = entry.Level
+			line.Message = entry.Message
+			line.Fields = entry.Fields
+
+			value, err := lokiValue(entry.Time, line)
+			if err != nil {
+				return err
+			}
+			stream.Values = append(stream.Values, value)
+		}
+		push.Streams = append(push.Streams, stream)
+	}
+
+	if execution.Output != nil {
+		data, err := decodeOutput(execution.Output)
+		if err != nil {
+			return err
+		}
+
+		line := base
+		line.ContentType = execution.Output.ContentType
+		line.Truncated = execution.Output.Truncated
+		line.Output = string(data)
+
+		value, err := lokiValue(s.now(), line)
+		if err != nil {
+			return err
+		}
+		push.Streams = append(push.Streams, lokiStream{Stream: s.labels(execution, streamOutput), Values: [][2]string{value}})
+	}
+
+	if len(push.Streams) == 0 {
+		return nil
+	}
+
+	return s.push(ctx, push)
+}
+
+func (s *lokiSink) labels(execution Execution, stream string) map[string]string {
+	labels := make(map[string]string, len(s.settings.Labels)+4)
+	for name, value := range s.settings.Labels {
+		labels[name] = value
+	}
+
+	labels["service_name"] = "scheduler"
+	labels["job_type"] = string(execution.JobType)
+	labels["stream"] = stream
+	if execution.Namespace != "" {
+		labels["namespace"] = execution.Namespace
+	}
+
+	return labels
+}
+
+func lokiValue(at time.Time, line lokiLine) ([2]string, error) {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return [2]string{}, fmt.Errorf("encode loki line: %w", err)
+	}
+
+	return [2]string{strconv.FormatInt(at.UnixNano(), 10), string(data)}, nil
+}
+
+func (s *lokiSink) push(ctx context.Context, push lokiPush) error {
+	body, err := json.Marshal(push)
+	if err != nil {
+		return fmt.Errorf("encode loki push: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create loki request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.settings.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.settings.TenantID)
+	}
+	if s.settings.Username != "" {
+		req.SetBasicAuth(s.settings.Username, s.settings.Password)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push logs to loki: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("push logs to loki: %s: %s", res.Status, strings.TrimSpace(string(data)))
+	}
+
+	return nil
+}
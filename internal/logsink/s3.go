@@ -0,0 +1,45 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/objectstore"
+)
+
+// s3Sink uploads the logs of each execution as an NDJSON object, one entry per line, and its output as a
+// zstd-compressed object, under <prefix><job id>/<execution id>/.
+type s3Sink struct {
+	bucket *objectstore.S3Bucket
+	prefix string
+}
+
+func (s *s3Sink) Ship(ctx context.Context, execution Execution) error {
+	key := fmt.Sprintf("%s%s/%d/", strings.TrimPrefix(s.prefix, "/"), execution.JobID, execution.ExecutionID)
+
+	if execution.Logs != nil && len(execution.Logs.Entries) > 0 {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		for _, entry := range execution.Logs.Entries {
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("encode execution logs: %w", err)
+			}
+		}
+
+		if err := s.bucket.Put(ctx, key+"logs.ndjson", "application/x-ndjson", buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if execution.Output != nil {
+		// the output is uploaded as captured, compressed
+		if err := s.bucket.Put(ctx, key+"output.zst", "application/zstd", execution.Output.Data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+// Package logsink ships the logs and outputs of the executions to external sinks, e.g. Loki or object storage, in
+// addition to or instead of the database.
+package logsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/objectstore"
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Types of the sinks.
+const (
+	TypeLoki   = "loki"
+	TypeS3     = "s3"
+	TypeStdout = "stdout"
+)
+
+// Execution is what an execution logged and output, shipped to the sinks once it finished.
+type Execution struct {
+	JobID       uuid.UUID
+	JobType     model.JobType
+	Namespace   string
+	ExecutionID int
+	// Instance is the ID of the runner which ran the execution
+	Instance string
+	// Logs of the execution, nil if log capture is disabled
+	Logs *model.ExecutionLogs
+	// Output of the execution, nil if the executor wrote none or output capture is disabled
+	Output *model.ExecutionOutput
+}
+
+// Sink ships the logs and outputs of the executions somewhere.
+type Sink interface {
+	Ship(ctx context.Context, execution Execution) error
+}
+
+// Settings configure the shipping of the execution logs and outputs.
+type Settings struct {
+	// Sinks the logs and outputs are shipped to, in addition to the database
+	Sinks []SinkSettings `mapstructure:"sinks" yaml:"sinks" json:"sinks,omitempty"`
+	// SkipDatabase stops storing the logs and outputs in the database, leaving them to the sinks
+	SkipDatabase bool `mapstructure:"skipDatabase" yaml:"skipDatabase" json:"skipDatabase,omitempty"`
+}
+
+// SinkSettings configure a sink.
+type SinkSettings struct {
+	// Type of the sink: loki, s3 or stdout
+	Type string       `mapstructure:"type" yaml:"type" json:"type"`
+	Loki LokiSettings `mapstructure:"loki" yaml:"loki" json:"loki"`
+	// Prefix of the keys of the objects of the s3 sink, e.g. scheduler/executions/
+	Prefix string                 `mapstructure:"prefix" yaml:"prefix" json:"prefix,omitempty"`
+	S3     objectstore.S3Settings `mapstructure:"s3" yaml:"s3" json:"s3"`
+}
+
+// New creates the sink configured by the settings, shipping to all the configured sinks, nil if there are none.
+func New(settings Settings, client *http.Client) (Sink, error) {
+	if len(settings.Sinks) == 0 {
+		if settings.SkipDatabase {
+			return nil, fmt.Errorf("skipping the database requires a log sink")
+		}
+		return nil, nil
+	}
+
+	sinks := make(multiSink, 0, len(settings.Sinks))
+	for _, sinkSettings := range settings.Sinks {
+		sink, err := newSink(sinkSettings, client)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+
+	return sinks, nil
+}
+
+func newSink(settings SinkSettings, client *http.Client) (Sink, error) {
+	switch settings.Type {
+	case TypeLoki:
+		return newLokiSink(settings.Loki, client)
+	case TypeS3:
+		bucket, err := objectstore.NewS3Bucket(settings.S3, client)
+		if err != nil {
+			return nil, fmt.Errorf("s3 log sink: %w", err)
+		}
+
+		return &s3Sink{bucket: bucket, prefix: settings.Prefix}, nil
+	case TypeStdout:
+		return &stdoutSink{w: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("unknown log sink type: %q", settings.Type)
+	}
+}
+
+// multiSink ships to several sinks, a failing sink doesn't keep the others from shipping.
+type multiSink []Sink
+
+func (m multiSink) Ship(ctx context.Context, execution Execution) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Ship(ctx, execution); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// decoder decompresses the outputs, DecodeAll is safe for concurrent use.
+var decoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+
+// decodeOutput returns the output decompressed.
+func decodeOutput(output *model.ExecutionOutput) ([]byte, error) {
+	if output.Encoding != model.ExecutionOutputEncodingZstd {
+		return output.Data, nil
+	}
+
+	data, err := decoder.DecodeAll(output.Data, make([]byte, 0, output.Size))
+	if err != nil {
+		return nil, fmt.Errorf("decompress execution output: %w", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,204 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/objectstore"
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func execution(t *testing.T) Execution {
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	data := encoder.EncodeAll([]byte(`{"status":"ok"}`), nil)
+
+	return Execution{
+		JobID:       uuid.MustParse("5f1b1f1e-7c1a-4a8e-9a4e-6b8f0f2d3c4b"),
+		JobType:     model.JobTypeHTTP,
+		Namespace:   "billing",
+		ExecutionID: 42,
+		Instance:    "runner-1",
+		Logs: &model.ExecutionLogs{
+			ExecutionID: 42,
+			Entries: []model.ExecutionLogEntry{
+				{Time: time.Unix(1700000000, 0).UTC(), Level: "info", Message: "sending request"},
+				{Time: time.Unix(1700000001, 0).UTC(), Level: "warn", Message: "slow response", Fields: map[string]any{"duration": "2s"}},
+			},
+		},
+		Output: &model.ExecutionOutput{
+			ExecutionID: 42,
+			ContentType: "application/json",
+			Size:        15,
+			Encoding:    model.ExecutionOutputEncodingZstd,
+			Data:        data,
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	sink, err := New(Settings{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, sink)
+
+	_, err = New(Settings{SkipDatabase: true}, nil)
+	assert.Error(t, err)
+
+	_, err = New(Settings{Sinks: []SinkSettings{{Type: "kafka"}}}, nil)
+	assert.Error(t, err)
+
+	_, err = New(Settings{Sinks: []SinkSettings{{Type: TypeLoki}}}, nil)
+	assert.Error(t, err)
+
+	sink, err = New(Settings{Sinks: []SinkSettings{{Type: TypeStdout}}}, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &stdoutSink{}, sink)
+
+	sink, err = New(Settings{Sinks: []SinkSettings{{Type: TypeStdout}, {Type: TypeLoki, Loki: LokiSettings{URL: "http://loki:3100"}}}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, sink, 2)
+}
+
+func TestStdoutSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := &stdoutSink{w: &buf}
+
+	require.NoError(t, sink.Ship(context.Background(), execution(t)))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	var line stdoutLine
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+	assert.Equal(t, streamLog, line.Stream)
+	assert.Equal(t, "slow response", line.Message)
+	assert.Equal(t, "billing", line.Namespace)
+	assert.Equal(t, 42, line.ExecutionID)
+
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &line))
+	assert.Equal(t, streamOutput, line.Stream)
+	assert.Equal(t, `{"status":"ok"}`, line.Output)
+	assert.Equal(t, "application/json", line.ContentType)
+}
+
+func TestLokiSink(t *testing.T) {
+	var (
+		push     lokiPush
+		tenantID string
+		username string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		tenantID = r.Header.Get("X-Scope-OrgID")
+		username, _, _ = r.BasicAuth()
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&push))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := newLokiSink(LokiSettings{
+		URL:      server.URL + "/",
+		TenantID: "team-a",
+		Labels:   map[string]string{"env": "prod"},
+		Username: "scheduler",
+		Password: "secret",
+	}, server.Client())
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Ship(context.Background(), execution(t)))
+	assert.Equal(t, "team-a", tenantID)
+	assert.Equal(t, "scheduler", username)
+
+	require.Len(t, push.Streams, 2)
+	assert.Equal(t, map[string]string{
+		"env":          "prod",
+		"service_name": "scheduler",
+		"job_type":     "HTTP",
+		"namespace":    "billing",
+		"stream":       streamLog,
+	}, push.Streams[0].Stream)
+	require.Len(t, push.Streams[0].Values, 2)
+	assert.Equal(t, "1700000000000000000", push.Streams[0].Values[0][0])
+
+	var line lokiLine
+	require.NoError(t, json.Unmarshal([]byte(push.Streams[0].Values[0][1]), &line))
+	assert.Equal(t, "sending request", line.Message)
+	assert.Equal(t, "5f1b1f1e-7c1a-4a8e-9a4e-6b8f0f2d3c4b", line.JobID)
+
+	assert.Equal(t, streamOutput, push.Streams[1].Stream["stream"])
+	require.NoError(t, json.Unmarshal([]byte(push.Streams[1].Values[0][1]), &line))
+	assert.Equal(t, `{"status":"ok"}`, line.Output)
+}
+
+func TestLokiSinkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "entry too far behind", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sink, err := newLokiSink(LokiSettings{URL: server.URL}, server.Client())
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, sink.Ship(context.Background(), execution(t)), "entry too far behind")
+}
+
+func TestS3Sink(t *testing.T) {
+	objects := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		objects[r.URL.Path] = r.Header.Get("Content-Type")
+		if strings.HasSuffix(r.URL.Path, ".ndjson") {
+			assert.Equal(t, 2, strings.Count(string(data), "\n"))
+		}
+	}))
+	defer server.Close()
+
+	sink, err := New(Settings{Sinks: []SinkSettings{{
+		Type:   TypeS3,
+		Prefix: "executions/",
+		S3: objectstore.S3Settings{
+			Endpoint:        server.URL,
+			Bucket:          "logs",
+			PathStyle:       true,
+			AccessKeyID:     "key",
+			SecretAccessKey: "secret",
+		},
+	}}}, server.Client())
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Ship(context.Background(), execution(t)))
+	assert.Equal(t, map[string]string{
+		"/logs/executions/5f1b1f1e-7c1a-4a8e-9a4e-6b8f0f2d3c4b/42/logs.ndjson": "application/x-ndjson",
+		"/logs/executions/5f1b1f1e-7c1a-4a8e-9a4e-6b8f0f2d3c4b/42/output.zst":  "application/zstd",
+	}, objects)
+}
+
+type failingSink struct {
+	shipped int
+}
+
+func (f *failingSink) Ship(context.Context, Execution) error {
+	f.shipped++
+	return errors.New("unavailable")
+}
+
+func TestMultiSink(t *testing.T) {
+	first, second := &failingSink{}, &failingSink{}
+
+	err := multiSink{first, second}.Ship(context.Background(), execution(t))
+	assert.Error(t, err)
+	assert.Equal(t, 1, first.shipped)
+	assert.Equal(t, 1, second.shipped)
+}
@@ -0,0 +1,93 @@
+package logsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+)
+
+// stdoutSink writes the logs and outputs as JSON lines, one per log entry and one per output, for log collectors
+// tailing the output of the runner.
+type stdoutSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// stdoutLine is a line written by the stdout sink. The stream tells the log entries and the outputs apart.
+type stdoutLine struct {
+	Time        time.Time      `json:"time"`
+	Stream      string         `json:"stream"`
+	JobID       uuid.UUID      `json:"job_id"`
+	JobType     model.JobType  `json:"job_type"`
+	Namespace   string         `json:"namespace,omitempty"`
+	ExecutionID int            `json:"execution_id"`
+	Instance    string         `json:"instance,omitempty"`
+	Level       string         `json:"level,omitempty"`
+	Message     string         `json:"message,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty"`
+	ContentType string         `json:"content_type,omitempty"`
+	Truncated   bool           `json:"truncated,omitempty"`
+	Output      string         `json:"output,omitempty"`
+}
+
+// Streams of the lines of the stdout and Loki sinks.
+const (
+	streamLog    = "log"
+	streamOutput = "output"
+)
+
+func (s *stdoutSink) Ship(_ context.Context, execution Execution) error {
+	base := stdoutLine{
+		JobID:       execution.JobID,
+		JobType:     execution.JobType,
+		Namespace:   execution.Namespace,
+		ExecutionID: execution.ExecutionID,
+		Instance:    execution.Instance,
+	}
+
+	var lines []stdoutLine
+	if execution.Logs != nil {
+		for _, entry := range execution.Logs.Entries {
+			line := base
+			line.Time = entry.Time
+			line.Stream = streamLog
+			line.Level = entry.Level
+			line.Message = entry.Message
+			line.Fields = entry.Fields
+			lines = append(lines, line)
+		}
+	}
+
+	if execution.Output != nil {
+		data, err := decodeOutput(execution.Output)
+		if err != nil {
+			return err
+		}
+
+		line := base
+		line.Time = time.Now()
+		line.Stream = streamOutput
+		line.ContentType = execution.Output.ContentType
+		line.Truncated = execution.Output.Truncated
+		line.Output = string(data)
+		lines = append(lines, line)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the lines of an execution are written together, so they aren't interleaved with the ones of other executions
+	encoder := json.NewEncoder(s.w)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
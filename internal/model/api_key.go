@@ -0,0 +1,94 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// APIKey authenticates requests to the management API. Only the hash of the key is stored.
+//
+// swagger:model APIKey
+type APIKey struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// First characters of the key, to help identify it
+	Prefix string `json:"prefix"`
+	// Admin keys can manage API keys and namespaces, and access all namespaces
+	Admin bool `json:"admin"`
+	// Namespace the key has access to, and the default namespace of its requests
+	Namespace string `json:"namespace"`
+	// Team of the key, the default team of the jobs it creates. Non-admin keys of a team can only access the jobs
+	// of their team.
+	Team null.String `json:"team,omitempty" swaggertype:"string"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt null.Time `json:"last_used_at,omitempty" swaggertype:"string"`
+	RevokedAt  null.Time `json:"revoked_at,omitempty" swaggertype:"string"`
+}
+
+// swagger:model APIKeyCreate
+type APIKeyCreate struct {
+	Name  string `json:"name"`
+	Admin bool   `json:"admin"`
+	// Namespace of the key (default: "default")
+	Namespace string `json:"namespace"`
+	// Optional team of the key
+	Team null.String `json:"team,omitempty" swaggertype:"string"`
+}
+
+// Validate validates an APIKeyCreate struct.
+func (c *APIKeyCreate) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return error2.ErrInvalidAPIKeyName
+	}
+
+	if c.Namespace != "" {
+		if err := ValidateNamespace(c.Namespace); err != nil {
+			return err
+		}
+	}
+
+	if c.Team.Valid {
+		return ValidateTeam(c.Team.String)
+	}
+
+	return nil
+}
+
+func (c *APIKeyCreate) ToAPIKey(prefix string) *APIKey {
+	namespace := c.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	return &APIKey{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(c.Name),
+		Prefix:    prefix,
+		Admin:     c.Admin,
+		Namespace: namespace,
+		Team:      c.Team,
+		CreatedAt: time.Now(),
+	}
+}
+
+// CreatedAPIKey is returned when an API key is created. It is the only time the key is available.
+//
+// swagger:model CreatedAPIKey
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Principal returns the principal of the requests made with the key.
+func (k *APIKey) Principal() Principal {
+	return Principal{
+		Name:       k.Name,
+		Team:       k.Team.String,
+		Restricted: !k.Admin && k.Team.Valid,
+	}
+}
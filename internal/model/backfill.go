@@ -0,0 +1,89 @@
+package model
+
+import (
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// MaxBackfillRuns is the maximum number of runs a single backfill can schedule.
+const MaxBackfillRuns = 1000
+
+// JobBackfill requests the execution of the runs of a recurring job scheduled in [From, To), e.g. the runs missed
+// while the job was paused or its target was down.
+//
+// swagger:model JobBackfill
+type JobBackfill struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Validate validates a JobBackfill struct. Only past runs can be backfilled, the next ones being run by the schedule.
+func (b *JobBackfill) Validate() error {
+	if !b.From.Before(b.To) || b.To.After(time.Now()) {
+		return error2.ErrInvalidBackfill
+	}
+
+	return nil
+}
+
+// JobBackfillResult lists the runs of a backfill.
+//
+// swagger:model JobBackfillResult
+type JobBackfillResult struct {
+	// Scheduled times of the runs executed by the backfill, or which would be without a dry run
+	Runs []time.Time `json:"runs"`
+	// Scheduled times of the runs in the range which were already executed, and are skipped
+	Skipped []time.Time `json:"skipped"`
+	// Pending executions of the runs, none with a dry run
+	Executions []*JobExecution `json:"executions"`
+	DryRun     bool            `json:"dry_run"`
+}
+
+// NewJobBackfillResult splits the runs between the ones to execute and the ones already executed.
+func NewJobBackfillResult(runs, executed []time.Time, dryRun bool) *JobBackfillResult {
+	// scheduled times are stored with a microsecond precision
+	done := make(map[int64]bool, len(executed))
+	for _, t := range executed {
+		done[t.UnixMicro()] = true
+	}
+
+	result := &JobBackfillResult{Runs: []time.Time{}, Skipped: []time.Time{}, Executions: []*JobExecution{}, DryRun: dryRun}
+	for _, run := range runs {
+		if done[run.UnixMicro()] {
+			result.Skipped = append(result.Skipped, run)
+		} else {
+			result.Runs = append(result.Runs, run)
+		}
+	}
+
+	return result
+}
+
+// RunsBetween returns the runs of the recurring job scheduled in [from, to), oldest first. It returns
+// ErrInvalidBackfill if the job isn't recurring, or if there are more than max runs.
+func (j *Job) RunsBetween(from, to time.Time, max int) ([]time.Time, error) {
+	if !j.CronSchedule.Valid {
+		return nil, error2.ErrInvalidBackfill
+	}
+
+	schedule, err := ParseSchedule(j.CronSchedule.String)
+	if err != nil {
+		return nil, error2.ErrInvalidBackfill
+	}
+
+	runs := []time.Time{}
+	// schedules return the runs strictly after the given time, to the second
+	for next := schedule.Next(from.Add(-time.Second)); !next.IsZero() && next.Before(to); next = schedule.Next(next) {
+		if next.Before(from) {
+			continue
+		}
+
+		if len(runs) == max {
+			return nil, error2.ErrInvalidBackfill
+		}
+		runs = append(runs, next)
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobRunsBetween(t *testing.T) {
+	job := &Job{CronSchedule: null.StringFrom("0 * * * *")}
+	from := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	runs, err := job.RunsBetween(from, from.Add(3*time.Hour), MaxBackfillRuns)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{from, from.Add(time.Hour), from.Add(2 * time.Hour)}, runs)
+
+	runs, err = job.RunsBetween(from.Add(time.Minute), from.Add(time.Hour), MaxBackfillRuns)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	_, err = job.RunsBetween(from, from.Add(3*time.Hour), 2)
+	assert.Equal(t, error2.ErrInvalidBackfill, err)
+
+	_, err = (&Job{ExecuteAt: null.TimeFrom(from)}).RunsBetween(from, from.Add(time.Hour), MaxBackfillRuns)
+	assert.Equal(t, error2.ErrInvalidBackfill, err)
+}
+
+func TestJobBackfillValidate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, (&JobBackfill{From: now.Add(-time.Hour), To: now.Add(-time.Minute)}).Validate())
+	assert.Equal(t, error2.ErrInvalidBackfill, (&JobBackfill{From: now.Add(-time.Hour), To: now.Add(-time.Hour)}).Validate())
+	assert.Equal(t, error2.ErrInvalidBackfill, (&JobBackfill{From: now.Add(-time.Hour), To: now.Add(time.Hour)}).Validate())
+}
+
+func TestNewJobBackfillResult(t *testing.T) {
+	run := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	runs := []time.Time{run, run.Add(time.Hour)}
+
+	result := NewJobBackfillResult(runs, []time.Time{run.Add(time.Hour).Local()}, true)
+	assert.Equal(t, []time.Time{run}, result.Runs)
+	assert.Equal(t, []time.Time{run.Add(time.Hour)}, result.Skipped)
+	assert.Empty(t, result.Executions)
+	assert.True(t, result.DryRun)
+}
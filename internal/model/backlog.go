@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// Backlog reports the demand on the runners as flat numbers, so autoscalers such as the KEDA metrics-api scaler or
+// an HPA external metric can scale the runner fleet on it directly.
+//
+// swagger:model Backlog
+type Backlog struct {
+	// Jobs that are due but not locked by any runner yet
+	DueJobs     uint64    `json:"due_jobs"`
+	OldestDueAt null.Time `json:"oldest_due_at,omitempty" swaggertype:"string"`
+	// Age of the oldest due job, i.e. how long it waits for a runner to lock it, in seconds
+	LockWaitSeconds float64 `json:"lock_wait_seconds"`
+	// Retries waiting for a runner with spare capacity
+	PendingRetries uint64 `json:"pending_retries"`
+	// Executions currently running
+	RunningExecutions uint64 `json:"running_executions"`
+	// Demand is the number of executions the runners should be running, the running ones and the ones waiting
+	Demand uint64 `json:"demand"`
+
+	// Live runners, with their total capacity and load
+	Runners  int `json:"runners"`
+	Capacity int `json:"capacity"`
+	Load     int `json:"load"`
+	// Utilization is the demand relative to the capacity of the live runners, above 1 if they can't keep up
+	Utilization float64 `json:"utilization"`
+}
+
+// NewBacklog completes the backlog counted at the given time with the lock wait and the live runner instances.
+func NewBacklog(at time.Time, backlog Backlog, instances []Instance) Backlog {
+	if backlog.OldestDueAt.Valid && backlog.OldestDueAt.Time.Before(at) {
+		backlog.LockWaitSeconds = at.Sub(backlog.OldestDueAt.Time).Seconds()
+	}
+
+	backlog.Demand = backlog.DueJobs + backlog.PendingRetries + backlog.RunningExecutions
+
+	for _, instance := range instances {
+		if !instance.Alive {
+			continue
+		}
+
+		backlog.Runners++
+		backlog.Capacity += instance.Capacity
+		backlog.Load += instance.Load
+	}
+
+	switch {
+	case backlog.Capacity > 0:
+		backlog.Utilization = float64(backlog.Demand) / float64(backlog.Capacity)
+	case backlog.Demand > 0:
+		// no runner to execute the demand, scale up from zero
+		backlog.Utilization = float64(backlog.Demand)
+	}
+
+	return backlog
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestNewBacklog(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Live runners", func(t *testing.T) {
+		backlog := NewBacklog(now, Backlog{
+			DueJobs:           10,
+			OldestDueAt:       null.TimeFrom(now.Add(-30 * time.Second)),
+			PendingRetries:    2,
+			RunningExecutions: 8,
+		}, []Instance{
+			{ID: "runner1", Capacity: 10, Load: 5, Alive: true},
+			{ID: "runner2", Capacity: 10, Load: 3, Alive: true},
+			{ID: "runner3", Capacity: 10, Load: 0, Alive: false},
+		})
+
+		assert.InDelta(t, 30, backlog.LockWaitSeconds, 0.001)
+		assert.EqualValues(t, 20, backlog.Demand)
+		assert.Equal(t, 2, backlog.Runners)
+		assert.Equal(t, 20, backlog.Capacity)
+		assert.Equal(t, 8, backlog.Load)
+		assert.InDelta(t, 1, backlog.Utilization, 0.001)
+	})
+
+	t.Run("No runners", func(t *testing.T) {
+		backlog := NewBacklog(now, Backlog{DueJobs: 3, OldestDueAt: null.TimeFrom(now.Add(time.Second))}, nil)
+
+		assert.Zero(t, backlog.LockWaitSeconds)
+		assert.Zero(t, backlog.Runners)
+		assert.InDelta(t, 3, backlog.Utilization, 0.001)
+	})
+
+	t.Run("Idle", func(t *testing.T) {
+		backlog := NewBacklog(now, Backlog{}, nil)
+
+		assert.Zero(t, backlog.Demand)
+		assert.Zero(t, backlog.Utilization)
+	})
+}
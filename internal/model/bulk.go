@@ -0,0 +1,177 @@
+package model
+
+import (
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+)
+
+// MaxBulkItems is the maximum number of jobs a single bulk operation can affect.
+const MaxBulkItems = 500
+
+// JobSelector selects the jobs affected by a bulk operation, either by their IDs, by tags or by a tag selector
+// expression (see TagSelector). Exactly one of the fields must be set.
+//
+// swagger:model JobSelector
+type JobSelector struct {
+	IDs []uuid.UUID `json:"ids,omitempty"`
+	// Jobs must have all of the tags
+	Tags []string `json:"tags,omitempty"`
+	// Jobs must match the tag selector expression, e.g. "env=prod AND team=payments"
+	Selector string `json:"selector,omitempty"`
+
+	// Namespace is set by the job service from the request context
+	Namespace string `json:"-"`
+	// Team is set by the job service for the API keys restricted to their team
+	Team string `json:"-"`
+}
+
+// Validate validates a JobSelector struct.
+func (s *JobSelector) Validate() error {
+	set := 0
+	for _, isSet := range []bool{len(s.IDs) > 0, len(s.Tags) > 0, s.Selector != ""} {
+		if isSet {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return error2.ErrInvalidBulkRequest
+	}
+
+	if _, err := ParseTagSelector(s.Selector); err != nil {
+		return err
+	}
+
+	if len(s.IDs) > MaxBulkItems {
+		return error2.ErrInvalidBulkRequest
+	}
+
+	seen := make(map[uuid.UUID]bool, len(s.IDs))
+	for _, id := range s.IDs {
+		if seen[id] {
+			return error2.ErrInvalidBulkRequest
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// Filter returns the job filter matching the selected jobs. The selector must be valid.
+func (s *JobSelector) Filter() JobFilter {
+	tagSelector, _ := ParseTagSelector(s.Selector)
+	return JobFilter{Namespace: s.Namespace, Team: s.Team, IDs: s.IDs, Tags: s.Tags, Selector: tagSelector}
+}
+
+// KnownTags returns the tags that the selected jobs are known to have.
+func (s *JobSelector) KnownTags() []string {
+	if s.Selector != "" {
+		tagSelector, _ := ParseTagSelector(s.Selector)
+		return tagSelector.Tags()
+	}
+
+	return s.Tags
+}
+
+// JobBatchGet lists the jobs to get in a single request.
+//
+// swagger:model JobBatchGet
+type JobBatchGet struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// Validate validates a JobBatchGet struct.
+func (b *JobBatchGet) Validate() error {
+	if len(b.IDs) == 0 || len(b.IDs) > MaxBulkItems {
+		return error2.ErrInvalidBulkRequest
+	}
+
+	return nil
+}
+
+// JobBatch holds the jobs found by a batch get, in the order they were requested,
+// and the IDs of the jobs that were not found.
+//
+// swagger:model JobBatch
+type JobBatch struct {
+	Jobs     []Job       `json:"jobs"`
+	NotFound []uuid.UUID `json:"not_found"`
+}
+
+// swagger:model BulkJobCreate
+type BulkJobCreate struct {
+	Jobs []JobCreate `json:"jobs"`
+}
+
+// Validate validates a BulkJobCreate struct.
+func (b *BulkJobCreate) Validate() error {
+	if len(b.Jobs) == 0 || len(b.Jobs) > MaxBulkItems {
+		return error2.ErrInvalidBulkRequest
+	}
+
+	return nil
+}
+
+// BulkJobUpdateItem is an update of a single job, identified by its ID.
+type BulkJobUpdateItem struct {
+	ID uuid.UUID `json:"id"`
+	JobUpdate
+}
+
+// BulkJobUpdate either updates each of the listed jobs with their own update,
+// or applies the same update to all jobs matching the selector.
+//
+// swagger:model BulkJobUpdate
+type BulkJobUpdate struct {
+	Jobs []BulkJobUpdateItem `json:"jobs,omitempty"`
+
+	Selector *JobSelector `json:"selector,omitempty"`
+	Update   *JobUpdate   `json:"update,omitempty"`
+}
+
+// Validate validates a BulkJobUpdate struct.
+func (b *BulkJobUpdate) Validate() error {
+	if len(b.Jobs) > 0 {
+		if b.Selector != nil || b.Update != nil || len(b.Jobs) > MaxBulkItems {
+			return error2.ErrInvalidBulkRequest
+		}
+
+		return nil
+	}
+
+	if b.Selector == nil || b.Update == nil {
+		return error2.ErrInvalidBulkRequest
+	}
+
+	return b.Selector.Validate()
+}
+
+// BulkItemResult is the outcome of a bulk operation for a single job.
+type BulkItemResult struct {
+	// Index of the item in the request, when the jobs were listed explicitly
+	Index *int       `json:"index,omitempty"`
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// BulkResult is the outcome of a bulk operation. Bulk operations are executed in a single
+// transaction: if any of the items fails, none of the changes are applied.
+//
+// swagger:model BulkResult
+type BulkResult struct {
+	Succeeded bool             `json:"succeeded"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// NewBulkResult creates a bulk result, which succeeded if none of the items failed.
+func NewBulkResult(results []BulkItemResult) *BulkResult {
+	succeeded := true
+	for _, result := range results {
+		if result.Error != "" {
+			succeeded = false
+			break
+		}
+	}
+
+	return &BulkResult{Succeeded: succeeded, Results: results}
+}
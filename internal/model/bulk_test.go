@@ -0,0 +1,70 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobSelectorValidate(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name     string
+		selector JobSelector
+		want     error
+	}{
+		{name: "by IDs", selector: JobSelector{IDs: []uuid.UUID{id}}, want: nil},
+		{name: "by tags", selector: JobSelector{Tags: []string{"billing"}}, want: nil},
+		{name: "by tag selector", selector: JobSelector{Selector: "env=prod AND team=payments"}, want: nil},
+		{name: "tags and tag selector", selector: JobSelector{Tags: []string{"billing"}, Selector: "env=prod"}, want: error2.ErrInvalidBulkRequest},
+		{name: "invalid tag selector", selector: JobSelector{Selector: "env=prod AND"}, want: error2.ErrInvalidTagSelector},
+		{name: "empty selector", selector: JobSelector{}, want: error2.ErrInvalidBulkRequest},
+		{name: "both IDs and tags", selector: JobSelector{IDs: []uuid.UUID{id}, Tags: []string{"billing"}}, want: error2.ErrInvalidBulkRequest},
+		{name: "duplicate IDs", selector: JobSelector{IDs: []uuid.UUID{id, id}}, want: error2.ErrInvalidBulkRequest},
+		{name: "too many IDs", selector: JobSelector{IDs: make([]uuid.UUID, MaxBulkItems+1)}, want: error2.ErrInvalidBulkRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.selector.Validate())
+		})
+	}
+}
+
+func TestJobBatchGetValidate(t *testing.T) {
+	assert.NoError(t, (&JobBatchGet{IDs: []uuid.UUID{uuid.New()}}).Validate())
+	assert.Equal(t, error2.ErrInvalidBulkRequest, (&JobBatchGet{}).Validate())
+	assert.Equal(t, error2.ErrInvalidBulkRequest, (&JobBatchGet{IDs: make([]uuid.UUID, MaxBulkItems+1)}).Validate())
+}
+
+func TestBulkJobUpdateValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		bulk BulkJobUpdate
+		want error
+	}{
+		{name: "listed jobs", bulk: BulkJobUpdate{Jobs: []BulkJobUpdateItem{{ID: uuid.New()}}}, want: nil},
+		{name: "selector", bulk: BulkJobUpdate{Selector: &JobSelector{Tags: []string{"billing"}}, Update: &JobUpdate{}}, want: nil},
+		{name: "empty", bulk: BulkJobUpdate{}, want: error2.ErrInvalidBulkRequest},
+		{name: "selector without update", bulk: BulkJobUpdate{Selector: &JobSelector{Tags: []string{"billing"}}}, want: error2.ErrInvalidBulkRequest},
+		{name: "listed jobs and selector", bulk: BulkJobUpdate{
+			Jobs:     []BulkJobUpdateItem{{ID: uuid.New()}},
+			Selector: &JobSelector{Tags: []string{"billing"}},
+			Update:   &JobUpdate{},
+		}, want: error2.ErrInvalidBulkRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.bulk.Validate())
+		})
+	}
+}
+
+func TestNewBulkResult(t *testing.T) {
+	assert.True(t, NewBulkResult([]BulkItemResult{{}, {}}).Succeeded)
+	assert.False(t, NewBulkResult([]BulkItemResult{{}, {Error: "job not found"}}).Succeeded)
+}
@@ -0,0 +1,8 @@
+package model
+
+// JobCancellation notifies the runners that the cancellation of a running execution was requested, so the runner
+// executing it cancels it right away instead of on its next cancellation poll.
+type JobCancellation struct {
+	ExecutionID int    `json:"execution_id"`
+	Namespace   string `json:"namespace"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"slices"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// maxCapabilities limits the number of capabilities of a runner, or required by a job.
+const maxCapabilities = 32
+
+// ValidateCapabilities validates the capabilities of a runner, or the capabilities required by a job. Capabilities
+// are labels, e.g. region=eu or gpu.
+func ValidateCapabilities(capabilities []string) error {
+	if len(capabilities) > maxCapabilities {
+		return error2.ErrInvalidCapabilities
+	}
+
+	for _, capability := range capabilities {
+		if !ParseLabel(capability).Valid() {
+			return error2.ErrInvalidCapabilities
+		}
+	}
+
+	return nil
+}
+
+// HasCapabilities reports whether the capabilities include all the required ones.
+func HasCapabilities(capabilities, required []string) bool {
+	for _, capability := range required {
+		if !slices.Contains(capabilities, capability) {
+			return false
+		}
+	}
+
+	return true
+}
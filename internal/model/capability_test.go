@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCapabilities(t *testing.T) {
+	assert.NoError(t, ValidateCapabilities(nil))
+	assert.NoError(t, ValidateCapabilities([]string{"region=eu", "gpu"}))
+	assert.Equal(t, error2.ErrInvalidCapabilities, ValidateCapabilities([]string{"region=eu west"}))
+	assert.Equal(t, error2.ErrInvalidCapabilities, ValidateCapabilities([]string{"=eu"}))
+	assert.Equal(t, error2.ErrInvalidCapabilities, ValidateCapabilities(make([]string, maxCapabilities+1)))
+}
+
+func TestHasCapabilities(t *testing.T) {
+	capabilities := []string{"region=eu", "network=dmz"}
+
+	assert.True(t, HasCapabilities(capabilities, nil))
+	assert.True(t, HasCapabilities(capabilities, []string{"network=dmz"}))
+	assert.True(t, HasCapabilities(capabilities, []string{"network=dmz", "region=eu"}))
+	assert.False(t, HasCapabilities(capabilities, []string{"region=us"}))
+	assert.False(t, HasCapabilities(nil, []string{"gpu"}))
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// CloudEventContentType is the content type of the events in the CloudEvents structured JSON mode.
+const CloudEventContentType = "application/cloudevents+json"
+
+// CloudEvent is an event in the CloudEvents 1.0 structured JSON format. The namespace of the job and the ID of the
+// execution are extension attributes, so consumers can route the events without parsing their data.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Namespace       string    `json:"namespace,omitempty"`
+	ExecutionID     string    `json:"executionid,omitempty"`
+	Data            Event     `json:"data"`
+}
+
+// NewCloudEvent wraps the event in a CloudEvent. The subject is the ID of the job, and the data is the event as
+// delivered to the webhooks in the JSON format.
+func NewCloudEvent(event Event, source, typePrefix string) CloudEvent {
+	ce := CloudEvent{
+		SpecVersion:     "1.0",
+		ID:              event.ID.String(),
+		Source:          source,
+		Type:            typePrefix + string(event.Type),
+		Subject:         event.JobID.String(),
+		Time:            event.Time,
+		DataContentType: "application/json",
+		Namespace:       event.Namespace,
+		Data:            event,
+	}
+
+	if event.ExecutionID != nil {
+		ce.ExecutionID = strconv.Itoa(*event.ExecutionID)
+	}
+
+	return ce
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	executionID := 7
+	event := Event{
+		ID:          uuid.MustParse("0b0e2c3e-3f5a-4b8e-9c1d-2e3f4a5b6c7d"),
+		Type:        EventExecutionFailed,
+		Time:        time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+		JobID:       uuid.MustParse("6a8e1f0c-1d2b-4c3d-8e9f-0a1b2c3d4e5f"),
+		Namespace:   "billing",
+		ExecutionID: &executionID,
+		Error:       "connection refused",
+	}
+
+	data, err := json.Marshal(NewCloudEvent(event, "/scheduler/prod", "scheduler."))
+	require.NoError(t, err)
+
+	var encoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &encoded))
+	assert.Equal(t, "1.0", encoded["specversion"])
+	assert.Equal(t, "0b0e2c3e-3f5a-4b8e-9c1d-2e3f4a5b6c7d", encoded["id"])
+	assert.Equal(t, "/scheduler/prod", encoded["source"])
+	assert.Equal(t, "scheduler."+string(EventExecutionFailed), encoded["type"])
+	assert.Equal(t, "6a8e1f0c-1d2b-4c3d-8e9f-0a1b2c3d4e5f", encoded["subject"])
+	assert.Equal(t, "2026-10-16T12:00:00Z", encoded["time"])
+	assert.Equal(t, "billing", encoded["namespace"])
+	assert.Equal(t, "7", encoded["executionid"])
+	assert.Equal(t, "connection refused", encoded["data"].(map[string]any)["error"])
+
+	// the execution ID is omitted for job events
+	event.ExecutionID = nil
+	assert.Empty(t, NewCloudEvent(event, "/scheduler", "").ExecutionID)
+}
@@ -0,0 +1,356 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+	"gopkg.in/yaml.v3"
+)
+
+// CronJobTag is added to the jobs imported from Kubernetes CronJobs, so they can be listed or updated with a tag
+// selector.
+const CronJobTag = "source=cronjob"
+
+// DefaultKubernetesJobType is the job type of the CronJobs imported with the KUBERNETES strategy, executed by a plugin
+// creating a Kubernetes Job from the job template of the CronJob.
+const DefaultKubernetesJobType JobType = "KUBERNETES_JOB"
+
+// defaultKubernetesNamespace is the namespace of the CronJobs whose manifest doesn't set one, like kubectl does.
+const defaultKubernetesNamespace = "default"
+
+// cronJobPlaceholders are the placeholders of the mapping definitions, replaced by the values of each CronJob
+var cronJobPlaceholders = map[string]bool{"name": true, "namespace": true}
+
+type CronJobMappingStrategy string
+
+const (
+	// CronJobMappingHTTP converts each CronJob into an HTTP job triggering an endpoint, e.g. one creating the Job
+	CronJobMappingHTTP CronJobMappingStrategy = "HTTP"
+	// CronJobMappingKubernetes converts each CronJob into a job of a custom type creating the Job, e.g. with a plugin
+	CronJobMappingKubernetes CronJobMappingStrategy = "KUBERNETES"
+)
+
+// Concurrency policies of Kubernetes CronJobs.
+const (
+	cronJobConcurrencyAllow   = "Allow"
+	cronJobConcurrencyForbid  = "Forbid"
+	cronJobConcurrencyReplace = "Replace"
+)
+
+// CronJobImport converts Kubernetes CronJobs into jobs.
+//
+// swagger:model CronJobImport
+type CronJobImport struct {
+	// Manifests of the CronJobs, in YAML or JSON. Documents of other kinds are skipped, and lists are expanded, so the
+	// output of kubectl get cronjobs -o yaml can be imported as is.
+	Manifests string         `json:"manifests"`
+	Mapping   CronJobMapping `json:"mapping"`
+}
+
+// CronJobMapping is the strategy converting the CronJobs into jobs.
+type CronJobMapping struct {
+	// Strategy of the conversion, HTTP or KUBERNETES
+	Strategy CronJobMappingStrategy `json:"strategy"`
+	// Definition of the jobs, with the fields of a job create request and {{name}} and {{namespace}} placeholders in
+	// its string values. The HTTP strategy requires the http_job, whose body is the CronJob as JSON if empty. The
+	// KUBERNETES strategy defines the custom_job as the name, namespace and job template of the CronJob.
+	Definition json.RawMessage `json:"definition,omitempty" swaggertype:"object"`
+	// Job type of the KUBERNETES strategy, a custom type registered by an executor plugin (default: KUBERNETES_JOB)
+	KubernetesType JobType `json:"kubernetes_type,omitempty"`
+}
+
+// KubernetesCronJob is the subset of a Kubernetes CronJob converted into a job.
+type KubernetesCronJob struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string            `json:"name"`
+		Namespace string            `json:"namespace"`
+		Labels    map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Schedule                string          `json:"schedule"`
+		TimeZone                string          `json:"timeZone"`
+		ConcurrencyPolicy       string          `json:"concurrencyPolicy"`
+		Suspend                 bool            `json:"suspend"`
+		StartingDeadlineSeconds *int64          `json:"startingDeadlineSeconds"`
+		JobTemplate             json.RawMessage `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// swagger:model CronJobImportResult
+type CronJobImportResult struct {
+	// Whether the jobs were created. They are not for dry runs, and when any of the CronJobs is invalid.
+	Created bool                `json:"created"`
+	Jobs    []CronJobImportItem `json:"jobs"`
+}
+
+// CronJobImportItem is the job converted from a CronJob, or the reason it couldn't be.
+type CronJobImportItem struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Job       *Job   `json:"job,omitempty"`
+	// Warnings about the settings of the CronJob which the job doesn't have, e.g. its concurrency policy
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Succeeded reports whether all the CronJobs were converted into valid jobs.
+func (r *CronJobImportResult) Succeeded() bool {
+	for _, item := range r.Jobs {
+		if item.Error != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseCronJobImport parses a YAML or JSON CronJob import request.
+func ParseCronJobImport(data []byte) (*CronJobImport, error) {
+	encoded, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &CronJobImport{}
+	if err := json.Unmarshal(encoded, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// Validate validates a CronJobImport struct. The CronJobs are validated when they are converted.
+func (c *CronJobImport) Validate() error {
+	if strings.TrimSpace(c.Manifests) == "" {
+		return error2.ErrInvalidCronJobImport
+	}
+
+	switch c.Mapping.Strategy {
+	case CronJobMappingHTTP:
+		if len(c.Mapping.Definition) == 0 {
+			return error2.ErrInvalidCronJobImport
+		}
+	case CronJobMappingKubernetes:
+	default:
+		return error2.ErrInvalidCronJobImport
+	}
+
+	if len(c.Mapping.Definition) > 0 && !validPlaceholders(c.Mapping.Definition, cronJobPlaceholders) {
+		return error2.ErrInvalidCronJobImport
+	}
+
+	return nil
+}
+
+// ParseCronJobs returns the CronJobs of the manifests, which can be separated by ---, and be lists of resources.
+// Resources of other kinds are skipped.
+func ParseCronJobs(manifests string) ([]KubernetesCronJob, error) {
+	var cronJobs []KubernetesCronJob
+
+	decoder := yaml.NewDecoder(strings.NewReader(manifests))
+	for {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if document == nil {
+			continue
+		}
+
+		encoded, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := parseCronJobResource(encoded)
+		if err != nil {
+			return nil, err
+		}
+		cronJobs = append(cronJobs, found...)
+	}
+
+	return cronJobs, nil
+}
+
+func parseCronJobResource(encoded []byte) ([]KubernetesCronJob, error) {
+	var resource struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(encoded, &resource); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resource.Kind == "CronJob":
+		cronJob := KubernetesCronJob{}
+		if err := json.Unmarshal(encoded, &cronJob); err != nil {
+			return nil, err
+		}
+		return []KubernetesCronJob{cronJob}, nil
+	case strings.HasSuffix(resource.Kind, "List"):
+		var cronJobs []KubernetesCronJob
+		for _, item := range resource.Items {
+			found, err := parseCronJobResource(item)
+			if err != nil {
+				return nil, err
+			}
+			cronJobs = append(cronJobs, found...)
+		}
+		return cronJobs, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Namespace returns the namespace of the CronJob, the default namespace if its manifest doesn't set one.
+func (c *KubernetesCronJob) Namespace() string {
+	if c.Metadata.Namespace == "" {
+		return defaultKubernetesNamespace
+	}
+
+	return c.Metadata.Namespace
+}
+
+// ToJob converts the CronJob into the definition of a job with the mapping, along with warnings about the settings of
+// the CronJob which the job doesn't have. The job is named <namespace>.<name>, tagged source=cronjob along with the
+// labels of the CronJob, and stopped if the CronJob is suspended.
+func (m *CronJobMapping) ToJob(cronJob KubernetesCronJob) (JobCreate, []string, error) {
+	var warnings []string
+
+	if cronJob.Metadata.Name == "" || cronJob.Spec.Schedule == "" {
+		return JobCreate{}, nil, errors.New("the CronJob must have a name and a schedule")
+	}
+
+	if tz := cronJob.Spec.TimeZone; tz != "" && tz != "UTC" && tz != "Etc/UTC" {
+		return JobCreate{}, nil, fmt.Errorf("time zone %s is not supported, jobs are scheduled in UTC", tz)
+	}
+	if strings.HasPrefix(cronJob.Spec.Schedule, "CRON_TZ=") || strings.HasPrefix(cronJob.Spec.Schedule, "TZ=") {
+		return JobCreate{}, nil, errors.New("time zones in the schedule are not supported, jobs are scheduled in UTC")
+	}
+
+	switch cronJob.Spec.ConcurrencyPolicy {
+	case cronJobConcurrencyForbid:
+	case "", cronJobConcurrencyAllow, cronJobConcurrencyReplace:
+		policy := cronJob.Spec.ConcurrencyPolicy
+		if policy == "" {
+			policy = cronJobConcurrencyAllow
+		}
+		warnings = append(warnings, fmt.Sprintf("concurrency policy %s is not supported, executions of a job never overlap like with Forbid", policy))
+	default:
+		return JobCreate{}, nil, fmt.Errorf("unknown concurrency policy %s", cronJob.Spec.ConcurrencyPolicy)
+	}
+
+	namespace := cronJob.Namespace()
+
+	definition := JobCreate{}
+	if len(m.Definition) > 0 {
+		template := JobTemplate{Definition: m.Definition}
+		for name := range cronJobPlaceholders {
+			template.Parameters = append(template.Parameters, TemplateParameter{Name: name})
+		}
+
+		rendered, err := template.render(map[string]string{"name": cronJob.Metadata.Name, "namespace": namespace})
+		if err != nil {
+			return JobCreate{}, nil, error2.ErrInvalidCronJobImport
+		}
+		definition = rendered
+	}
+
+	payload, err := json.Marshal(kubernetesJob{Name: cronJob.Metadata.Name, Namespace: namespace, JobTemplate: cronJob.Spec.JobTemplate})
+	if err != nil {
+		return JobCreate{}, nil, err
+	}
+
+	switch m.Strategy {
+	case CronJobMappingHTTP:
+		definition.Type = JobTypeHTTP
+		if definition.HTTPJob != nil && definition.HTTPJob.Body.String == "" {
+			definition.HTTPJob.Body = null.StringFrom(string(payload))
+			if definition.HTTPJob.Headers == nil {
+				definition.HTTPJob.Headers = map[string]string{}
+			}
+			if _, ok := definition.HTTPJob.Headers["Content-Type"]; !ok {
+				definition.HTTPJob.Headers["Content-Type"] = "application/json"
+			}
+		}
+	case CronJobMappingKubernetes:
+		definition.Type = m.KubernetesType
+		if definition.Type == "" {
+			definition.Type = DefaultKubernetesJobType
+		}
+		if len(definition.CustomJob) == 0 {
+			definition.CustomJob = payload
+		}
+	}
+
+	definition.Name = null.StringFrom(namespace + "." + cronJob.Metadata.Name)
+	definition.CronSchedule = null.StringFrom(cronJob.Spec.Schedule)
+	definition.ExecuteAt = null.Time{}
+
+	// the Job is killed once it runs for longer than its active deadline
+	if deadline, ok := activeDeadlineSeconds(cronJob.Spec.JobTemplate); ok && !definition.ExecutionTimeout.Valid {
+		definition.ExecutionTimeout = null.IntFrom(deadline)
+	}
+
+	// runs missed by more than the starting deadline are skipped, instead of being started late
+	if cronJob.Spec.StartingDeadlineSeconds != nil && definition.MisfirePolicy == "" {
+		definition.MisfirePolicy = MisfirePolicySkip
+	}
+
+	keys := make([]string, 0, len(cronJob.Metadata.Labels))
+	for key := range cronJob.Metadata.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		label := Label{Key: key, Value: cronJob.Metadata.Labels[key]}
+		if !label.Valid() {
+			warnings = append(warnings, fmt.Sprintf("label %s is not a valid tag, it is not added to the job", label))
+			continue
+		}
+		if !hasLabel(definition.Tags, label.Key, label.Value) {
+			definition.Tags = append(definition.Tags, label.String())
+		}
+	}
+	if !hasLabel(definition.Tags, "source", "cronjob") {
+		definition.Tags = append(definition.Tags, CronJobTag)
+	}
+
+	return definition, warnings, nil
+}
+
+// activeDeadlineSeconds returns the active deadline of the Jobs of the job template, if it has one.
+func activeDeadlineSeconds(jobTemplate json.RawMessage) (int64, bool) {
+	var template struct {
+		Spec struct {
+			ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds"`
+		} `json:"spec"`
+	}
+	if len(bytes.TrimSpace(jobTemplate)) == 0 || json.Unmarshal(jobTemplate, &template) != nil || template.Spec.ActiveDeadlineSeconds == nil {
+		return 0, false
+	}
+
+	return *template.Spec.ActiveDeadlineSeconds, true
+}
+
+// kubernetesJob is the Job to create for a CronJob, sent in the body of the HTTP jobs and defining the custom jobs of
+// the KUBERNETES strategy.
+type kubernetesJob struct {
+	Name        string          `json:"name"`
+	Namespace   string          `json:"namespace"`
+	JobTemplate json.RawMessage `json:"job_template,omitempty"`
+}
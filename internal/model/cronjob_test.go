@@ -0,0 +1,177 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCronJobs = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly-report
+  namespace: billing
+  labels:
+    team: billing
+    app.kubernetes.io/name: report
+spec:
+  schedule: "0 2 * * *"
+  concurrencyPolicy: Forbid
+  startingDeadlineSeconds: 300
+  suspend: true
+  jobTemplate:
+    spec:
+      activeDeadlineSeconds: 600
+      template:
+        spec:
+          containers:
+            - name: report
+              image: billing/report:1.2
+          restartPolicy: OnFailure
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: batch/v1
+    kind: CronJob
+    metadata:
+      name: cleanup
+    spec:
+      schedule: "*/15 * * * *"
+      jobTemplate:
+        spec:
+          template:
+            spec:
+              containers:
+                - name: cleanup
+                  image: busybox
+`
+
+func TestParseCronJobs(t *testing.T) {
+	cronJobs, err := ParseCronJobs(testCronJobs)
+	require.NoError(t, err)
+	require.Len(t, cronJobs, 2)
+
+	assert.Equal(t, "nightly-report", cronJobs[0].Metadata.Name)
+	assert.Equal(t, "billing", cronJobs[0].Namespace())
+	assert.True(t, cronJobs[0].Spec.Suspend)
+
+	assert.Equal(t, "cleanup", cronJobs[1].Metadata.Name)
+	assert.Equal(t, "default", cronJobs[1].Namespace())
+	assert.Equal(t, "*/15 * * * *", cronJobs[1].Spec.Schedule)
+
+	_, err = ParseCronJobs("kind: CronJob\n  spec: [")
+	assert.Error(t, err)
+}
+
+func TestCronJobImportValidate(t *testing.T) {
+	valid := CronJobImport{
+		Manifests: testCronJobs,
+		Mapping: CronJobMapping{
+			Strategy:   CronJobMappingHTTP,
+			Definition: json.RawMessage(`{"http_job": {"url": "https://jobs.example.com/{{namespace}}/{{name}}", "method": "POST"}}`),
+		},
+	}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(request *CronJobImport)
+	}{
+		{"No manifests", func(request *CronJobImport) { request.Manifests = "" }},
+		{"Unknown strategy", func(request *CronJobImport) { request.Mapping.Strategy = "HELM" }},
+		{"HTTP without definition", func(request *CronJobImport) { request.Mapping.Definition = nil }},
+		{"Unknown placeholder", func(request *CronJobImport) {
+			request.Mapping.Definition = json.RawMessage(`{"http_job": {"url": "https://{{image}}"}}`)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := valid
+			tt.modify(&request)
+			assert.ErrorIs(t, request.Validate(), error2.ErrInvalidCronJobImport)
+		})
+	}
+}
+
+func TestCronJobMappingToJob(t *testing.T) {
+	cronJobs, err := ParseCronJobs(testCronJobs)
+	require.NoError(t, err)
+
+	t.Run("Kubernetes", func(t *testing.T) {
+		mapping := CronJobMapping{Strategy: CronJobMappingKubernetes}
+
+		definition, warnings, err := mapping.ToJob(cronJobs[0])
+		require.NoError(t, err)
+		assert.Empty(t, warnings)
+
+		assert.Equal(t, DefaultKubernetesJobType, definition.Type)
+		assert.Equal(t, "billing.nightly-report", definition.Name.String)
+		assert.Equal(t, "0 2 * * *", definition.CronSchedule.String)
+		assert.Equal(t, int64(600), definition.ExecutionTimeout.Int64)
+		assert.Equal(t, MisfirePolicySkip, definition.MisfirePolicy)
+		assert.Equal(t, []string{"app.kubernetes.io/name=report", "team=billing", CronJobTag}, definition.Tags)
+
+		var custom map[string]any
+		require.NoError(t, json.Unmarshal(definition.CustomJob, &custom))
+		assert.Equal(t, "nightly-report", custom["name"])
+		assert.Equal(t, "billing", custom["namespace"])
+		assert.Contains(t, custom["job_template"], "spec")
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		mapping := CronJobMapping{
+			Strategy: CronJobMappingHTTP,
+			Definition: json.RawMessage(`{
+				"http_job": {"url": "https://jobs.example.com/{{namespace}}/{{name}}", "method": "POST", "auth": {"type": "none"}},
+				"execution_timeout": 60
+			}`),
+		}
+
+		definition, warnings, err := mapping.ToJob(cronJobs[1])
+		require.NoError(t, err)
+
+		// the default concurrency policy of CronJobs is Allow
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], "Allow")
+
+		assert.Equal(t, JobTypeHTTP, definition.Type)
+		assert.Equal(t, "default.cleanup", definition.Name.String)
+		assert.Equal(t, "https://jobs.example.com/default/cleanup", definition.HTTPJob.URL)
+		assert.Equal(t, int64(60), definition.ExecutionTimeout.Int64)
+		assert.Equal(t, MisfirePolicy(""), definition.MisfirePolicy)
+		assert.Contains(t, definition.HTTPJob.Body.String, `"name":"cleanup"`)
+
+		assert.NoError(t, definition.ToJob().Validate())
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		mapping := CronJobMapping{Strategy: CronJobMappingKubernetes}
+
+		timeZone := cronJobs[1]
+		timeZone.Spec.TimeZone = "Europe/Ljubljana"
+		_, _, err := mapping.ToJob(timeZone)
+		assert.ErrorContains(t, err, "Europe/Ljubljana")
+
+		policy := cronJobs[1]
+		policy.Spec.ConcurrencyPolicy = "Sometimes"
+		_, _, err = mapping.ToJob(policy)
+		assert.ErrorContains(t, err, "Sometimes")
+
+		invalidLabel := cronJobs[1]
+		invalidLabel.Metadata.Labels = map[string]string{"note": "has spaces"}
+		definition, warnings, err := mapping.ToJob(invalidLabel)
+		require.NoError(t, err)
+		assert.Len(t, warnings, 2)
+		assert.Equal(t, []string{CronJobTag}, definition.Tags)
+	})
+}
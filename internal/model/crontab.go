@@ -0,0 +1,367 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// CrontabTag is added to the jobs imported from a crontab, so they can be listed or updated with a tag selector.
+const CrontabTag = "source=crontab"
+
+// DefaultShellJobType is the job type of the commands imported with the SHELL strategy, executed by a plugin.
+const DefaultShellJobType JobType = "SHELL"
+
+// crontabFields is the number of fields of a crontab schedule.
+const crontabFields = 5
+
+var (
+	// environment assignments, e.g. PATH=/usr/bin or MAILTO = "ops@example.com"
+	crontabAssignmentPattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+	// crontabPlaceholders are the placeholders of the mapping definitions, replaced by the values of each line
+	crontabPlaceholders = map[string]bool{"command": true, "input": true, "user": true, "line": true}
+)
+
+type CrontabMappingStrategy string
+
+const (
+	// CrontabMappingHTTP converts each line into an HTTP job calling an endpoint which runs the command
+	CrontabMappingHTTP CrontabMappingStrategy = "HTTP"
+	// CrontabMappingShell converts each line into a job of a custom type running the command, e.g. with a plugin
+	CrontabMappingShell CrontabMappingStrategy = "SHELL"
+)
+
+// CrontabImport converts the lines of a crontab file into jobs.
+//
+// swagger:model CrontabImport
+type CrontabImport struct {
+	// Contents of the crontab file
+	Crontab string         `json:"crontab"`
+	Mapping CrontabMapping `json:"mapping"`
+}
+
+// CrontabMapping is the strategy converting the lines of a crontab into jobs.
+type CrontabMapping struct {
+	// Strategy of the conversion, HTTP or SHELL
+	Strategy CrontabMappingStrategy `json:"strategy"`
+	// Definition of the jobs, with the fields of a job create request and {{command}}, {{input}}, {{user}} and
+	// {{line}} placeholders in its string values. The HTTP strategy requires the http_job, whose body is the command
+	// as JSON if empty. The SHELL strategy defines the custom_job as the command, its input, user and environment.
+	Definition json.RawMessage `json:"definition,omitempty" swaggertype:"object"`
+	// Job type of the SHELL strategy, a custom type registered by an executor plugin (default: SHELL)
+	ShellType JobType `json:"shell_type,omitempty"`
+	// System reads the crontab in the format of /etc/crontab, with the user running the command after the schedule
+	System bool `json:"system,omitempty"`
+	// NamePrefix names the jobs <prefix>-<line>, they are unnamed if empty
+	NamePrefix string `json:"name_prefix,omitempty"`
+}
+
+// CrontabEntry is a command of a crontab, with the environment assigned by the lines before it.
+type CrontabEntry struct {
+	// Line number of the entry in the crontab, starting at 1
+	Line int
+	// Text of the line
+	Text     string
+	Schedule string
+	// User running the command, only set in system crontabs
+	User    string
+	Command string
+	// Input of the command, the text after the first unescaped % of the line, with the other % turned into newlines
+	Input       string
+	Environment map[string]string
+}
+
+// CrontabLineError is a line of a crontab which couldn't be parsed.
+type CrontabLineError struct {
+	Line  int
+	Text  string
+	Error string
+}
+
+// swagger:model CrontabImportResult
+type CrontabImportResult struct {
+	// Whether the jobs were created. They are not for dry runs, and when any of the lines is invalid.
+	Created bool                `json:"created"`
+	Jobs    []CrontabImportItem `json:"jobs"`
+}
+
+// CrontabImportItem is the job converted from a line of the crontab, or the reason it couldn't be.
+type CrontabImportItem struct {
+	// Line number in the crontab, starting at 1
+	Line int `json:"line"`
+	// Text of the line
+	Entry string `json:"entry"`
+	Job   *Job   `json:"job,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded reports whether all the lines were converted into valid jobs.
+func (r *CrontabImportResult) Succeeded() bool {
+	for _, item := range r.Jobs {
+		if item.Error != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate validates a CrontabImport struct. The lines are validated when they are converted.
+func (c *CrontabImport) Validate() error {
+	if strings.TrimSpace(c.Crontab) == "" {
+		return error2.ErrInvalidCrontabImport
+	}
+
+	switch c.Mapping.Strategy {
+	case CrontabMappingHTTP:
+		if len(c.Mapping.Definition) == 0 {
+			return error2.ErrInvalidCrontabImport
+		}
+	case CrontabMappingShell:
+	default:
+		return error2.ErrInvalidCrontabImport
+	}
+
+	if len(c.Mapping.Definition) > 0 && !validPlaceholders(c.Mapping.Definition, crontabPlaceholders) {
+		return error2.ErrInvalidCrontabImport
+	}
+
+	if c.Mapping.NamePrefix != "" && ValidateJobName(c.Mapping.NamePrefix+"-1") != nil {
+		return error2.ErrInvalidCrontabImport
+	}
+
+	return nil
+}
+
+// ParseCrontab parses the commands of a crontab, skipping the comments and blank lines, and returns the lines which
+// couldn't be parsed. The @reboot schedule is not supported, and neither are time zones other than UTC, since the
+// jobs are scheduled in UTC.
+func ParseCrontab(crontab string, system bool) ([]CrontabEntry, []CrontabLineError) {
+	var (
+		entries     []CrontabEntry
+		errs        []CrontabLineError
+		environment = map[string]string{}
+	)
+
+	for i, text := range strings.Split(crontab, "\n") {
+		text = strings.TrimRight(text, "\r")
+		line := strings.TrimSpace(text)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if match := crontabAssignmentPattern.FindStringSubmatch(line); match != nil {
+			// each command runs with the environment assigned before it
+			environment = cloneEnvironment(environment)
+			environment[match[1]] = unquote(strings.TrimSpace(match[2]))
+			continue
+		}
+
+		entry, err := parseCrontabLine(line, system)
+		if err == nil {
+			err = checkCrontabTimezone(environment)
+		}
+		if err != nil {
+			errs = append(errs, CrontabLineError{Line: i + 1, Text: text, Error: err.Error()})
+			continue
+		}
+
+		entry.Line = i + 1
+		entry.Text = text
+		entry.Environment = environment
+		entries = append(entries, entry)
+	}
+
+	return entries, errs
+}
+
+func parseCrontabLine(line string, system bool) (CrontabEntry, error) {
+	entry := CrontabEntry{}
+
+	var rest string
+	if strings.HasPrefix(line, "@") {
+		fields, remainder := cutFields(line, 1)
+		if fields[0] == "@reboot" {
+			return entry, fmt.Errorf("the @reboot schedule is not supported")
+		}
+		entry.Schedule = fields[0]
+		rest = remainder
+	} else {
+		fields, remainder := cutFields(line, crontabFields)
+		if len(fields) < crontabFields {
+			return entry, fmt.Errorf("expected %d schedule fields followed by a command", crontabFields)
+		}
+		entry.Schedule = strings.Join(fields, " ")
+		rest = remainder
+	}
+
+	if _, err := ParseSchedule(entry.Schedule); err != nil {
+		return entry, fmt.Errorf("invalid schedule %q: %w", entry.Schedule, err)
+	}
+
+	if system {
+		fields, remainder := cutFields(rest, 1)
+		if len(fields) == 0 {
+			return entry, fmt.Errorf("expected the user running the command after the schedule")
+		}
+		entry.User = fields[0]
+		rest = remainder
+	}
+
+	entry.Command, entry.Input = splitCrontabInput(strings.TrimSpace(rest))
+	if entry.Command == "" {
+		return entry, fmt.Errorf("missing command")
+	}
+
+	return entry, nil
+}
+
+// checkCrontabTimezone rejects the commands scheduled in another time zone than UTC.
+func checkCrontabTimezone(environment map[string]string) error {
+	for _, key := range []string{"CRON_TZ", "TZ"} {
+		if tz, ok := environment[key]; ok && tz != "" && tz != "UTC" && tz != "Etc/UTC" {
+			return fmt.Errorf("%s=%s is not supported, jobs are scheduled in UTC", key, tz)
+		}
+	}
+
+	return nil
+}
+
+// cutFields returns the first n whitespace separated fields of the line, and the rest of the line.
+func cutFields(line string, n int) ([]string, string) {
+	fields := make([]string, 0, n)
+	rest := strings.TrimLeft(line, " \t")
+	for len(fields) < n && rest != "" {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			fields = append(fields, rest)
+			return fields, ""
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimLeft(rest[end:], " \t")
+	}
+
+	return fields, rest
+}
+
+// splitCrontabInput splits the command at its first unescaped %: the rest is the input of the command, with the
+// other unescaped % turned into newlines, like cron does. Escaped \% are kept as %.
+func splitCrontabInput(command string) (string, string) {
+	var (
+		parts   []string
+		current strings.Builder
+	)
+	for i := 0; i < len(command); i++ {
+		switch {
+		case command[i] == '\\' && i+1 < len(command) && command[i+1] == '%':
+			current.WriteByte('%')
+			i++
+		case command[i] == '%':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(command[i])
+		}
+	}
+	parts = append(parts, current.String())
+
+	return strings.TrimSpace(parts[0]), strings.Join(parts[1:], "\n")
+}
+
+func cloneEnvironment(environment map[string]string) map[string]string {
+	cloned := make(map[string]string, len(environment)+1)
+	for key, value := range environment {
+		cloned[key] = value
+	}
+
+	return cloned
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// ToJob converts the entry of a crontab into the definition of a job with the mapping. The job is tagged
+// source=crontab, and named after the line if the mapping has a name prefix.
+func (m *CrontabMapping) ToJob(entry CrontabEntry) (JobCreate, error) {
+	definition := JobCreate{}
+	if len(m.Definition) > 0 {
+		template := JobTemplate{Definition: m.Definition}
+		for name := range crontabPlaceholders {
+			template.Parameters = append(template.Parameters, TemplateParameter{Name: name})
+		}
+
+		rendered, err := template.render(map[string]string{
+			"command": entry.Command,
+			"input":   entry.Input,
+			"user":    entry.User,
+			"line":    strconv.Itoa(entry.Line),
+		})
+		if err != nil {
+			return JobCreate{}, error2.ErrInvalidCrontabImport
+		}
+		definition = rendered
+	}
+
+	command, err := json.Marshal(crontabCommand{
+		Command:     entry.Command,
+		Input:       entry.Input,
+		User:        entry.User,
+		Environment: entry.Environment,
+	})
+	if err != nil {
+		return JobCreate{}, err
+	}
+
+	switch m.Strategy {
+	case CrontabMappingHTTP:
+		definition.Type = JobTypeHTTP
+		if definition.HTTPJob != nil && definition.HTTPJob.Body.String == "" {
+			definition.HTTPJob.Body = null.StringFrom(string(command))
+			if definition.HTTPJob.Headers == nil {
+				definition.HTTPJob.Headers = map[string]string{}
+			}
+			if _, ok := definition.HTTPJob.Headers["Content-Type"]; !ok {
+				definition.HTTPJob.Headers["Content-Type"] = "application/json"
+			}
+		}
+	case CrontabMappingShell:
+		definition.Type = m.ShellType
+		if definition.Type == "" {
+			definition.Type = DefaultShellJobType
+		}
+		if len(definition.CustomJob) == 0 {
+			definition.CustomJob = command
+		}
+	}
+
+	definition.CronSchedule = null.StringFrom(entry.Schedule)
+	definition.ExecuteAt = null.Time{}
+	if m.NamePrefix != "" {
+		definition.Name = null.StringFrom(m.NamePrefix + "-" + strconv.Itoa(entry.Line))
+	}
+	if !hasLabel(definition.Tags, "source", "crontab") {
+		definition.Tags = append(definition.Tags, CrontabTag)
+	}
+
+	return definition, nil
+}
+
+// crontabCommand is the command of a crontab entry, sent in the body of the HTTP jobs and defining the custom jobs of
+// the SHELL strategy.
+type crontabCommand struct {
+	Command     string            `json:"command"`
+	Input       string            `json:"input,omitempty"`
+	User        string            `json:"user,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+}
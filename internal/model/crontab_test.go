@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCrontab = `# m h dom mon dow command
+SHELL=/bin/bash
+MAILTO="ops@example.com"
+
+*/5 * * * * /usr/local/bin/sync --all
+0 2 * * mon-fri	pg_dump billing > /backups/billing.sql
+@daily echo "rotated on $(date +\%F)" | mail -s logs%line one%line two
+@reboot /usr/local/bin/warmup
+61 * * * * /bin/true
+PATH=/usr/local/bin
+30 4 1 * * cleanup
+CRON_TZ=Europe/Ljubljana
+0 3 * * * report
+`
+
+func TestParseCrontab(t *testing.T) {
+	entries, errs := ParseCrontab(testCrontab, false)
+
+	require.Len(t, entries, 4)
+	assert.Equal(t, CrontabEntry{
+		Line:        5,
+		Text:        "*/5 * * * * /usr/local/bin/sync --all",
+		Schedule:    "*/5 * * * *",
+		Command:     "/usr/local/bin/sync --all",
+		Environment: map[string]string{"SHELL": "/bin/bash", "MAILTO": "ops@example.com"},
+	}, entries[0])
+
+	assert.Equal(t, "0 2 * * mon-fri", entries[1].Schedule)
+	assert.Equal(t, "pg_dump billing > /backups/billing.sql", entries[1].Command)
+
+	assert.Equal(t, "@daily", entries[2].Schedule)
+	assert.Equal(t, `echo "rotated on $(date +%F)" | mail -s logs`, entries[2].Command)
+	assert.Equal(t, "line one\nline two", entries[2].Input)
+
+	// assignments only apply to the commands after them
+	assert.Equal(t, 11, entries[3].Line)
+	assert.Equal(t, "/usr/local/bin", entries[3].Environment["PATH"])
+	assert.NotContains(t, entries[0].Environment, "PATH")
+
+	require.Len(t, errs, 3)
+	assert.Equal(t, 8, errs[0].Line)
+	assert.Contains(t, errs[0].Error, "@reboot")
+	assert.Equal(t, 9, errs[1].Line)
+	assert.Contains(t, errs[1].Error, "invalid schedule")
+	assert.Equal(t, 13, errs[2].Line)
+	assert.Contains(t, errs[2].Error, "CRON_TZ")
+}
+
+func TestParseSystemCrontab(t *testing.T) {
+	entries, errs := ParseCrontab("17 * * * * root cd / && run-parts --report /etc/cron.hourly\n0 * * * *\n", true)
+
+	require.Len(t, entries, 1)
+	assert.Equal(t, "root", entries[0].User)
+	assert.Equal(t, "cd / && run-parts --report /etc/cron.hourly", entries[0].Command)
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, 2, errs[0].Line)
+}
+
+func TestCrontabImportValidate(t *testing.T) {
+	valid := CrontabImport{
+		Crontab: "* * * * * true",
+		Mapping: CrontabMapping{
+			Strategy:   CrontabMappingHTTP,
+			Definition: json.RawMessage(`{"http_job": {"url": "https://runner.example.com/run?line={{line}}", "method": "POST"}}`),
+		},
+	}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(request *CrontabImport)
+	}{
+		{"Empty crontab", func(request *CrontabImport) { request.Crontab = " \n" }},
+		{"Unknown strategy", func(request *CrontabImport) { request.Mapping.Strategy = "FTP" }},
+		{"HTTP without definition", func(request *CrontabImport) { request.Mapping.Definition = nil }},
+		{"Definition not an object", func(request *CrontabImport) { request.Mapping.Definition = json.RawMessage(`[]`) }},
+		{"Unknown placeholder", func(request *CrontabImport) {
+			request.Mapping.Definition = json.RawMessage(`{"http_job": {"url": "https://{{host}}/run"}}`)
+		}},
+		{"Invalid name prefix", func(request *CrontabImport) { request.Mapping.NamePrefix = "legacy jobs" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := valid
+			tt.modify(&request)
+			assert.ErrorIs(t, request.Validate(), error2.ErrInvalidCrontabImport)
+		})
+	}
+}
+
+func TestCrontabMappingToJob(t *testing.T) {
+	entry := CrontabEntry{
+		Line:        12,
+		Schedule:    "0 2 * * *",
+		User:        "backup",
+		Command:     `pg_dump "billing"`,
+		Input:       "yes",
+		Environment: map[string]string{"PATH": "/usr/bin"},
+	}
+
+	t.Run("HTTP", func(t *testing.T) {
+		mapping := CrontabMapping{
+			Strategy:   CrontabMappingHTTP,
+			NamePrefix: "legacy",
+			Definition: json.RawMessage(`{
+				"http_job": {"url": "https://runner.example.com/run/{{line}}", "method": "POST", "auth": {"type": "none"}},
+				"tags": ["team=billing"]
+			}`),
+		}
+
+		definition, err := mapping.ToJob(entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, JobTypeHTTP, definition.Type)
+		assert.Equal(t, "0 2 * * *", definition.CronSchedule.String)
+		assert.Equal(t, "legacy-12", definition.Name.String)
+		assert.Equal(t, []string{"team=billing", CrontabTag}, definition.Tags)
+		assert.Equal(t, "https://runner.example.com/run/12", definition.HTTPJob.URL)
+		assert.Equal(t, "application/json", definition.HTTPJob.Headers["Content-Type"])
+		assert.JSONEq(t, `{"command": "pg_dump \"billing\"", "input": "yes", "user": "backup", "environment": {"PATH": "/usr/bin"}}`,
+			definition.HTTPJob.Body.String)
+
+		assert.NoError(t, definition.ToJob().Validate())
+	})
+
+	t.Run("HTTP with a body", func(t *testing.T) {
+		mapping := CrontabMapping{
+			Strategy:   CrontabMappingHTTP,
+			Definition: json.RawMessage(`{"http_job": {"url": "https://runner.example.com/run", "method": "POST", "body": "{{command}}"}}`),
+		}
+
+		definition, err := mapping.ToJob(entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, `pg_dump "billing"`, definition.HTTPJob.Body.String)
+		assert.False(t, definition.Name.Valid)
+	})
+
+	t.Run("Shell", func(t *testing.T) {
+		mapping := CrontabMapping{Strategy: CrontabMappingShell, ShellType: "EXEC"}
+
+		definition, err := mapping.ToJob(entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, JobType("EXEC"), definition.Type)
+		assert.JSONEq(t, `{"command": "pg_dump \"billing\"", "input": "yes", "user": "backup", "environment": {"PATH": "/usr/bin"}}`,
+			string(definition.CustomJob))
+		assert.Equal(t, []string{CrontabTag}, definition.Tags)
+	})
+}
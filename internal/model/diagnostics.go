@@ -0,0 +1,62 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// JobLock is the lock a runner holds on a job while executing it.
+type JobLock struct {
+	JobID       uuid.UUID   `json:"job_id"`
+	Namespace   string      `json:"namespace"`
+	Name        null.String `json:"name,omitempty" swaggertype:"string"`
+	LockedBy    string      `json:"locked_by"`
+	LockedUntil time.Time   `json:"locked_until"`
+	// RunnerAlive tells whether the runner holding the lock is still registered
+	RunnerAlive bool `json:"runner_alive"`
+}
+
+// JobCredentialsError is the error decrypting the stored credentials of a job.
+type JobCredentialsError struct {
+	JobID     uuid.UUID
+	Namespace string
+	Err       error
+}
+
+// CheckNextRun reports why the next run of a running job is inconsistent with its schedule at the given time, so
+// the job runs late or never, and nil if it isn't.
+func (j *Job) CheckNextRun(at time.Time) error {
+	if j.Status != JobStatusRunning {
+		return nil
+	}
+
+	if j.CronSchedule.Valid {
+		schedule, err := ParseSchedule(j.CronSchedule.String)
+		if err != nil {
+			return fmt.Errorf("invalid cron schedule %q", j.CronSchedule.String)
+		}
+
+		if !j.NextRun.Valid {
+			return errors.New("the recurring job has no next run, it never runs again")
+		}
+
+		// a next run past the next occurrence skips the occurrences in between
+		if next := schedule.Next(at); j.NextRun.Time.After(next) {
+			return fmt.Errorf("the next run %s is after the next occurrence of the schedule %s",
+				j.NextRun.Time.Format(time.RFC3339), next.Format(time.RFC3339))
+		}
+
+		return nil
+	}
+
+	// executed one-off jobs are completed, the others are waiting for their next run
+	if !j.NextRun.Valid && !j.CompletedAt.Valid {
+		return errors.New("the one-off job has no next run but isn't completed, it never runs")
+	}
+
+	return nil
+}
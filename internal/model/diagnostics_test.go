@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobCheckNextRun(t *testing.T) {
+	at := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	hourly := null.StringFrom("0 * * * *")
+
+	tests := []struct {
+		name  string
+		job   Job
+		valid bool
+	}{
+		{"Due recurring job", Job{Status: JobStatusRunning, CronSchedule: hourly, NextRun: null.TimeFrom(at.Add(-time.Hour))}, true},
+		{"Next occurrence", Job{Status: JobStatusRunning, CronSchedule: hourly, NextRun: null.TimeFrom(at.Add(30 * time.Minute))}, true},
+		{"Skipped occurrences", Job{Status: JobStatusRunning, CronSchedule: hourly, NextRun: null.TimeFrom(at.Add(5 * time.Hour))}, false},
+		{"Recurring job without a next run", Job{Status: JobStatusRunning, CronSchedule: hourly}, false},
+		{"Invalid schedule", Job{Status: JobStatusRunning, CronSchedule: null.StringFrom("nope"), NextRun: null.TimeFrom(at)}, false},
+		{"Paused job", Job{Status: JobStatusStopped, CronSchedule: hourly}, true},
+		{"Pending one-off job", Job{Status: JobStatusRunning, ExecuteAt: null.TimeFrom(at), NextRun: null.TimeFrom(at)}, true},
+		{"Completed one-off job", Job{Status: JobStatusRunning, ExecuteAt: null.TimeFrom(at), CompletedAt: null.TimeFrom(at)}, true},
+		{"One-off job without a next run", Job{Status: JobStatusRunning, ExecuteAt: null.TimeFrom(at)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.job.CheckNextRun(at)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventJobCreated         EventType = "job.created"
+	EventJobUpdated         EventType = "job.updated"
+	EventJobDeleted         EventType = "job.deleted"
+	EventExecutionStarted   EventType = "execution.started"
+	EventExecutionFinished  EventType = "execution.finished"
+	EventExecutionFailed    EventType = "execution.failed"
+	EventExecutionCancelled EventType = "execution.cancelled"
+	// EventJobMissed is published when a job is overdue by more than the missed run threshold without being executed
+	EventJobMissed EventType = "job.missed"
+	// EventJobDisabled is published when a job is stopped after failing too many times in a row
+	EventJobDisabled EventType = "job.disabled"
+	// EventJobSLABreached is published when a job stops meeting its SLA
+	EventJobSLABreached EventType = "job.sla_breached"
+)
+
+func (et EventType) Valid() bool {
+	switch et {
+	case EventJobCreated, EventJobUpdated, EventJobDeleted, EventJobMissed, EventJobDisabled, EventJobSLABreached,
+		EventExecutionStarted, EventExecutionFinished, EventExecutionFailed, EventExecutionCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event is a job lifecycle event.
+//
+// swagger:model Event
+type Event struct {
+	ID    uuid.UUID `json:"id"`
+	Type  EventType `json:"type"`
+	Time  time.Time `json:"time"`
+	JobID uuid.UUID `json:"job_id"`
+	// Namespace of the job
+	Namespace string `json:"namespace"`
+	// Team of the job, if known
+	Team string `json:"team,omitempty"`
+	// Tags of the job at the time of the event
+	Tags []string `json:"tags,omitempty"`
+	// Metadata of the job at the time of the event
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// ExecutionID is set for execution events
+	ExecutionID *int `json:"execution_id,omitempty"`
+	// Error is set for failed executions
+	Error string `json:"error,omitempty"`
+	// ScheduledTime is the missed run of missed run events
+	ScheduledTime *time.Time `json:"scheduled_time,omitempty"`
+	// SLA is the compliance of the job with its SLA of SLA breach events
+	SLA *JobSLAStatus `json:"sla,omitempty"`
+}
+
+// NewJobEvent creates an event of the given type for the job.
+func NewJobEvent(eventType EventType, job *Job) Event {
+	return Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		Time:      time.Now(),
+		JobID:     job.ID,
+		Namespace: job.Namespace,
+		Team:      job.Team.String,
+		Tags:      job.Tags,
+		Metadata:  job.Metadata,
+	}
+}
+
+// EventFilter selects the events a subscriber is interested in. Empty fields match all events.
+type EventFilter struct {
+	// Namespace is set from the request context, events of other namespaces are never matched
+	Namespace string
+	// Team is set from the principal of the request, only events of jobs of the team are matched if set
+	Team string
+
+	Types []EventType
+	// Events must be for one of the jobs
+	JobIDs []uuid.UUID
+	// Events must be for jobs with all of the tags
+	Tags []string
+}
+
+// Validate validates an EventFilter struct.
+func (f *EventFilter) Validate() error {
+	for _, eventType := range f.Types {
+		if !eventType.Valid() {
+			return error2.ErrInvalidEventFilter
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the event passes the filter.
+func (f *EventFilter) Matches(event Event) bool {
+	if f.Namespace != "" && f.Namespace != event.Namespace {
+		return false
+	}
+
+	if f.Team != "" && f.Team != event.Team {
+		return false
+	}
+
+	if len(f.Types) > 0 && !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+
+	if len(f.JobIDs) > 0 && !slices.Contains(f.JobIDs, event.JobID) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !slices.Contains(event.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilter(t *testing.T) {
+	event := Event{Type: EventExecutionFailed, JobID: uuid.New(), Team: "payments", Tags: []string{"billing", "nightly"}}
+
+	tests := []struct {
+		name    string
+		filter  EventFilter
+		matches bool
+	}{
+		{name: "empty filter", filter: EventFilter{}, matches: true},
+		{name: "matching type", filter: EventFilter{Types: []EventType{EventExecutionFailed, EventJobCreated}}, matches: true},
+		{name: "other type", filter: EventFilter{Types: []EventType{EventJobCreated}}, matches: false},
+		{name: "matching job", filter: EventFilter{JobIDs: []uuid.UUID{uuid.New(), event.JobID}}, matches: true},
+		{name: "other job", filter: EventFilter{JobIDs: []uuid.UUID{uuid.New()}}, matches: false},
+		{name: "matching tags", filter: EventFilter{Tags: []string{"billing", "nightly"}}, matches: true},
+		{name: "missing tag", filter: EventFilter{Tags: []string{"billing", "hourly"}}, matches: false},
+		{name: "matching team", filter: EventFilter{Team: "payments"}, matches: true},
+		{name: "other team", filter: EventFilter{Team: "search"}, matches: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.matches, tc.filter.Matches(event))
+		})
+	}
+}
+
+func TestEventFilterValidate(t *testing.T) {
+	valid := EventFilter{Types: []EventType{EventJobCreated}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := EventFilter{Types: []EventType{"job.exploded"}}
+	assert.ErrorIs(t, invalid.Validate(), error2.ErrInvalidEventFilter)
+}
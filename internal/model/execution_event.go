@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// ExecutionEventType is the type of a step of the timeline of an execution.
+type ExecutionEventType string
+
+const (
+	// ExecutionEventQueued is recorded when a retry, a manual or a backfilled run is scheduled, pending until a runner
+	// claims it
+	ExecutionEventQueued ExecutionEventType = "QUEUED"
+	// ExecutionEventClaimed is recorded when a runner claims the execution
+	ExecutionEventClaimed ExecutionEventType = "CLAIMED"
+	// ExecutionEventStarted is recorded when the runner starts the executor
+	ExecutionEventStarted ExecutionEventType = "STARTED"
+	// ExecutionEventAttemptFailed is recorded when an attempt of the executor fails, with its number and error
+	ExecutionEventAttemptFailed ExecutionEventType = "ATTEMPT_FAILED"
+	// ExecutionEventRetried is recorded when the executor makes another attempt after a failed one
+	ExecutionEventRetried ExecutionEventType = "RETRIED"
+	// ExecutionEventCancelRequested is recorded when the cancellation of the execution is requested
+	ExecutionEventCancelRequested ExecutionEventType = "CANCEL_REQUESTED"
+	// ExecutionEventFinished is recorded when the outcome of the execution is recorded, with its status
+	ExecutionEventFinished ExecutionEventType = "FINISHED"
+	// ExecutionEventAbandoned is recorded when the execution is failed because its runner stopped renewing the lock
+	ExecutionEventAbandoned ExecutionEventType = "ABANDONED"
+)
+
+// ExecutionEvent is a step of the timeline of an execution.
+//
+// swagger:model ExecutionEvent
+type ExecutionEvent struct {
+	ExecutionID int                `json:"execution_id"`
+	Type        ExecutionEventType `json:"type"`
+	Time        time.Time          `json:"time"`
+	// Attempt is the number of the attempt of the executor, starting at 1
+	Attempt null.Int `json:"attempt,omitempty" swaggertype:"integer"`
+	// Status of the execution, once it finished
+	Status JobExecutionStatus `json:"status,omitempty"`
+	// Message is the error of a failed attempt or execution, or a description of the event
+	Message null.String `json:"message,omitempty" swaggertype:"string"`
+	// InstanceID is the runner that recorded the event, if it was recorded by a runner
+	InstanceID null.String `json:"instance_id,omitempty" swaggertype:"string"`
+}
+
+// NewExecutionEvent creates an event of the timeline of the execution.
+func NewExecutionEvent(executionID int, eventType ExecutionEventType, at time.Time) ExecutionEvent {
+	return ExecutionEvent{ExecutionID: executionID, Type: eventType, Time: at}
+}
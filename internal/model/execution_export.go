@@ -0,0 +1,83 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// DefaultExecutionExportPeriod is the period of the executions exported when no start of the range is given.
+const DefaultExecutionExportPeriod = 30 * 24 * time.Hour
+
+type ExecutionExportFormat string
+
+const (
+	ExecutionExportFormatCSV    ExecutionExportFormat = "csv"
+	ExecutionExportFormatNDJSON ExecutionExportFormat = "ndjson"
+)
+
+// ContentType returns the media type of the exports in the format.
+func (f ExecutionExportFormat) ContentType() string {
+	if f == ExecutionExportFormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// Valid reports whether the format is supported.
+func (f ExecutionExportFormat) Valid() bool {
+	return f == ExecutionExportFormatCSV || f == ExecutionExportFormatNDJSON
+}
+
+// ExecutionExportRange selects the executions started in [From, To).
+type ExecutionExportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Validate validates an ExecutionExportRange struct.
+func (r ExecutionExportRange) Validate() error {
+	if !r.From.Before(r.To) {
+		return error2.ErrInvalidExportRange
+	}
+
+	return nil
+}
+
+// ExecutionCSVHeader is the header of the CSV export of executions, matching the columns of CSVRecord.
+var ExecutionCSVHeader = []string{
+	"id", "job_id", "status", "scheduled_time", "start_time", "end_time", "duration_ms", "drift_ms",
+	"job_version", "retry_of", "error_message",
+}
+
+// CSVRecord returns the execution as a row of the CSV export. Times are formatted as RFC 3339,
+// and missing values are left empty.
+func (e *JobExecution) CSVRecord() []string {
+	formatTime := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	record := []string{
+		strconv.Itoa(e.ID), e.JobID.String(), string(e.Status), "", formatTime(e.StartTime), "", "", "", "", "", e.ErrorMessage.String,
+	}
+
+	if e.ScheduledTime.Valid {
+		record[3] = formatTime(e.ScheduledTime.Time)
+	}
+	if e.EndTime.Valid {
+		record[5] = formatTime(e.EndTime.Time)
+		record[6] = strconv.FormatInt(e.EndTime.Time.Sub(e.StartTime).Milliseconds(), 10)
+	}
+	if e.Drift.Valid {
+		record[7] = strconv.FormatInt(e.Drift.Int64, 10)
+	}
+	if e.JobVersion.Valid {
+		record[8] = strconv.FormatInt(e.JobVersion.Int64, 10)
+	}
+	if e.RetryOf.Valid {
+		record[9] = strconv.FormatInt(e.RetryOf.Int64, 10)
+	}
+
+	return record
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestExecutionExportRangeValidate(t *testing.T) {
+	now := time.Now()
+
+	assert.NoError(t, ExecutionExportRange{From: now.Add(-time.Hour), To: now}.Validate())
+	assert.Equal(t, error2.ErrInvalidExportRange, ExecutionExportRange{From: now, To: now}.Validate())
+	assert.Equal(t, error2.ErrInvalidExportRange, ExecutionExportRange{From: now, To: now.Add(-time.Hour)}.Validate())
+}
+
+func TestJobExecutionCSVRecord(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	execution := &JobExecution{
+		ID:            42,
+		JobID:         uuid.MustParse("a787fa30-2cbe-40de-9a51-f7c9fc43a747"),
+		Status:        JobExecutionStatusFailed,
+		StartTime:     start,
+		EndTime:       null.TimeFrom(start.Add(1500 * time.Millisecond)),
+		ErrorMessage:  null.StringFrom("unexpected status code 500"),
+		ScheduledTime: null.TimeFrom(start.Add(-time.Second)),
+		Drift:         null.IntFrom(1000),
+	}
+
+	assert.Equal(t, []string{
+		"42", "a787fa30-2cbe-40de-9a51-f7c9fc43a747", "FAILED", "2024-05-01T09:59:59Z", "2024-05-01T10:00:00Z",
+		"2024-05-01T10:00:01.5Z", "1500", "1000", "", "", "unexpected status code 500",
+	}, execution.CSVRecord())
+	assert.Len(t, ExecutionCSVHeader, len(execution.CSVRecord()))
+
+	running := &JobExecution{ID: 1, JobID: execution.JobID, Status: JobExecutionStatusRunning, StartTime: start}
+	assert.Equal(t, []string{
+		"1", "a787fa30-2cbe-40de-9a51-f7c9fc43a747", "RUNNING", "", "2024-05-01T10:00:00Z", "", "", "", "", "", "",
+	}, running.CSVRecord())
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// ExecutionLogEntry is a single structured log entry written by an executor during an execution.
+type ExecutionLogEntry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// swagger:model ExecutionLogs
+type ExecutionLogs struct {
+	ExecutionID int                 `json:"execution_id"`
+	Entries     []ExecutionLogEntry `json:"entries"`
+	// Truncated is set when entries were dropped, because the logs exceeded the size limit
+	Truncated bool `json:"truncated"`
+}
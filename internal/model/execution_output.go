@@ -0,0 +1,21 @@
+package model
+
+// ExecutionOutputEncodingZstd is the encoding of the stored outputs, compressed with zstd.
+const ExecutionOutputEncodingZstd = "zstd"
+
+// ExecutionOutput is the output of an execution, e.g. the body of the HTTP response, stored compressed apart from the
+// execution.
+// swagger:model ExecutionOutput
+type ExecutionOutput struct {
+	ExecutionID int `json:"execution_id"`
+	// ContentType of the output, e.g. the content type of the HTTP response
+	ContentType string `json:"content_type,omitempty"`
+	// Size of the output in bytes, before compression
+	Size int64 `json:"size"`
+	// Truncated is set when the output exceeded the size limit, and only its beginning was kept
+	Truncated bool `json:"truncated"`
+	// Encoding of the data, zstd
+	Encoding string `json:"encoding"`
+	// Data is the encoded output
+	Data []byte `json:"-"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// Instance is a runner registered in the instance registry. Runners register on start, send heartbeats
+// with their current load while running, and deregister when they stop.
+//
+// swagger:model Instance
+type Instance struct {
+	ID       string `json:"id"`
+	Hostname string `json:"hostname"`
+	Version  string `json:"version"`
+	// Namespaces whose jobs the runner executes, all of them if empty
+	Namespaces []string `json:"namespaces"`
+
+	// Maximum number of jobs executed at the same time
+	Capacity int `json:"capacity"`
+	// Number of jobs being executed
+	Load int `json:"load"`
+
+	StartedAt  time.Time `json:"started_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Alive is false if the runner missed its heartbeats, e.g. because it crashed
+	Alive bool `json:"alive"`
+}
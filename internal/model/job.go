@@ -1,28 +1,42 @@
 package model
 
 import (
+	"encoding/json"
+	"regexp"
 	"time"
 
 	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
 	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
 	"gopkg.in/guregu/null.v4"
 )
 
+var jobNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// externalIDPattern leaves out '/', so external IDs can be used as a path segment.
+var externalIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._:@-]{1,255}$`)
+
+// maxExecutionTimeout is the longest execution timeout of a job.
+const maxExecutionTimeout = 24 * time.Hour
+
 type JobType string
 
-// JobType is the type of job. Currently, only HTTP and AMQP jobs are supported.
+// JobType is the type of job. HTTP and AMQP jobs are built in, other job types are registered with RegisterJobType.
 const (
 	JobTypeHTTP JobType = "HTTP"
 	JobTypeAMQP JobType = "AMQP"
 )
 
+// JobTypes are the supported job types, including the registered custom job types.
+var JobTypes = []JobType{JobTypeHTTP, JobTypeAMQP}
+
 func (jt JobType) Valid() bool {
 	switch jt {
 	case JobTypeHTTP, JobTypeAMQP:
 		return true
 	default:
-		return false
+		return jt.Custom()
 	}
 }
 
@@ -36,6 +50,7 @@ const (
 	JobStatusCompleted             JobStatus = "COMPLETED"
 	JobStatusAwaitingNextExecution JobStatus = "AWAITING_NEXT_EXECUTION"
 	JobStatusStopped               JobStatus = "STOPPED"
+	JobStatusArchived              JobStatus = "ARCHIVED"
 )
 
 func (js JobStatus) Valid() bool {
@@ -49,9 +64,23 @@ func (js JobStatus) Valid() bool {
 
 // swagger:model Job
 type Job struct {
-	ID     uuid.UUID `json:"id"`
-	Type   JobType   `json:"type"`
-	Status JobStatus `json:"status"`
+	ID uuid.UUID `json:"id"`
+	// Namespace the job belongs to, determined by the API key or the X-Namespace header
+	Namespace string `json:"namespace"`
+	// Optional name, unique within the namespace, used to identify the job in manifests
+	Name null.String `json:"name,omitempty" swaggertype:"string"`
+	// Optional ID set by the client, unique within the namespace, so infrastructure-as-code tools can address the
+	// job without keeping track of its generated ID
+	ExternalID null.String `json:"external_id,omitempty" swaggertype:"string"`
+	// Owner of the job, e.g. a user or a service, the name of the API key that created it by default
+	Owner null.String `json:"owner,omitempty" swaggertype:"string"`
+	// Team owning the job, the team of the API key that created it by default. API keys bound to a team can only
+	// access the jobs of their team.
+	Team   null.String `json:"team,omitempty" swaggertype:"string"`
+	Type   JobType     `json:"type"`
+	Status JobStatus   `json:"status"`
+	// Version is incremented on every change of the job, and returned as its ETag
+	Version int64 `json:"version"`
 
 	ExecuteAt    null.Time   `json:"execute_at" swaggertype:"string"`    // for one-off jobs
 	CronSchedule null.String `json:"cron_schedule" swaggertype:"string"` // for recurring jobs
@@ -60,6 +89,9 @@ type Job struct {
 
 	AMQPJob *AMQPJob `json:"amqp_job,omitempty"`
 
+	// Definition of a job of a custom type, passed as is to the executor of the type
+	CustomJob json.RawMessage `json:"custom_job,omitempty" swaggertype:"object"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
@@ -70,6 +102,44 @@ type Job struct {
 
 	// Custom user tags that can be used to filter jobs
 	Tags []string `json:"tags"`
+
+	// Free-form metadata of the job, e.g. a runbook link, a cost center or a ticket ID, selectable with
+	// metadata.<key> requirements in tag selectors
+	Metadata map[string]string `json:"metadata"`
+
+	// Priority of the job among the due jobs, NORMAL by default
+	Priority JobPriority `json:"priority"`
+
+	// Capabilities a runner must have to execute the job, e.g. region=eu
+	RequiredCapabilities []string `json:"required_capabilities"`
+
+	// ExecutionTimeout is the number of seconds an execution can take before it is aborted and recorded as timed out
+	ExecutionTimeout null.Int `json:"execution_timeout" swaggertype:"integer"`
+
+	// MisfirePolicy decides whether an occurrence missed because its execution was abandoned is run once more,
+	// RUN_ONCE by default
+	MisfirePolicy MisfirePolicy `json:"misfire_policy"`
+
+	// SLA the executions of the job must meet, tracked and reported when breached
+	SLA *JobSLA `json:"sla,omitempty"`
+
+	// ResultSink the result of each execution of the job is published to once it finished
+	ResultSink *JobResultSink `json:"result_sink,omitempty"`
+
+	// MetricLabels the job opts into adding to its execution metrics: job_id, job_name or tag:<key>
+	MetricLabels []string `json:"metric_labels"`
+
+	// TTL is the number of seconds a one-off job is retained after it has completed.
+	// Once it elapses, the job is archived or deleted by the sweeper.
+	TTL         null.Int  `json:"ttl" swaggertype:"integer"`
+	CompletedAt null.Time `json:"completed_at,omitempty" swaggertype:"string"`
+
+	// Status of the most recent execution, if the job was executed at least once
+	LastExecutionStatus *JobExecutionStatus `json:"last_execution_status,omitempty"`
+
+	// ReclaimedFrom is the instance whose lock on the job had expired when the job was fetched to run, e.g. a runner
+	// which died or lost its lock, empty if the job wasn't locked. Only set on the jobs fetched to run.
+	ReclaimedFrom string `json:"-"`
 }
 
 // swagger:model JobUpdate
@@ -77,11 +147,41 @@ type JobUpdate struct {
 	Type *JobType `json:"type,omitempty"`
 	HTTP *HTTPJob `json:"http,omitempty"`
 	AMQP *AMQPJob `json:"amqp,omitempty"`
+	// Definition of a job of a custom type
+	Custom json.RawMessage `json:"custom,omitempty" swaggertype:"object"`
 
 	CronSchedule *string    `json:"cron_schedule,omitempty"`
 	ExecuteAt    *time.Time `json:"execute_at,omitempty"`
 
 	Tags *[]string `json:"tags,omitempty"`
+
+	// Metadata of the job, replacing the current metadata
+	Metadata *map[string]string `json:"metadata,omitempty"`
+
+	Priority *JobPriority `json:"priority,omitempty"`
+
+	RequiredCapabilities *[]string `json:"required_capabilities,omitempty"`
+
+	ExecutionTimeout *int64 `json:"execution_timeout,omitempty"`
+
+	MisfirePolicy *MisfirePolicy `json:"misfire_policy,omitempty"`
+
+	// SLA of the job, removed if it sets no objective
+	SLA *JobSLA `json:"sla,omitempty"`
+
+	// ResultSink of the job, removed if it has no type
+	ResultSink *JobResultSink `json:"result_sink,omitempty"`
+
+	MetricLabels *[]string `json:"metric_labels,omitempty"`
+
+	TTL *int64 `json:"ttl,omitempty"`
+
+	Owner *string `json:"owner,omitempty"`
+	// Team of the job, API keys bound to a team can't move jobs to another team
+	Team *string `json:"team,omitempty"`
+
+	// Version the update is based on, set from the If-Match header. The update is rejected if the job changed since.
+	Version *int64 `json:"-"`
 }
 
 func (j *Job) ApplyUpdate(update JobUpdate) {
@@ -92,11 +192,19 @@ func (j *Job) ApplyUpdate(update JobUpdate) {
 	if update.HTTP != nil {
 		j.HTTPJob = update.HTTP
 		j.AMQPJob = nil
+		j.CustomJob = nil
 	}
 
 	if update.AMQP != nil {
 		j.AMQPJob = update.AMQP
 		j.HTTPJob = nil
+		j.CustomJob = nil
+	}
+
+	if update.Custom != nil {
+		j.CustomJob = update.Custom
+		j.HTTPJob = nil
+		j.AMQPJob = nil
 	}
 
 	if update.CronSchedule != nil {
@@ -111,6 +219,56 @@ func (j *Job) ApplyUpdate(update JobUpdate) {
 		j.Tags = *update.Tags
 	}
 
+	if update.Metadata != nil {
+		j.Metadata = *update.Metadata
+	}
+
+	if update.Priority != nil {
+		j.Priority = update.Priority.OrDefault()
+	}
+
+	if update.RequiredCapabilities != nil {
+		j.RequiredCapabilities = *update.RequiredCapabilities
+	}
+
+	if update.ExecutionTimeout != nil {
+		j.ExecutionTimeout = null.IntFromPtr(update.ExecutionTimeout)
+	}
+
+	if update.MisfirePolicy != nil {
+		j.MisfirePolicy = update.MisfirePolicy.OrDefault()
+	}
+
+	if update.MetricLabels != nil {
+		j.MetricLabels = *update.MetricLabels
+	}
+
+	if update.SLA != nil {
+		j.SLA = update.SLA
+		if update.SLA.Empty() {
+			j.SLA = nil
+		}
+	}
+
+	if update.ResultSink != nil {
+		j.ResultSink = update.ResultSink
+		if update.ResultSink.Empty() {
+			j.ResultSink = nil
+		}
+	}
+
+	if update.TTL != nil {
+		j.TTL = null.IntFromPtr(update.TTL)
+	}
+
+	if update.Owner != nil {
+		j.Owner = null.StringFromPtr(update.Owner)
+	}
+
+	if update.Team != nil {
+		j.Team = null.StringFromPtr(update.Team)
+	}
+
 	j.UpdatedAt = time.Now()
 
 	j.SetInitialRunTime()
@@ -118,79 +276,200 @@ func (j *Job) ApplyUpdate(update JobUpdate) {
 
 // Validate validates a Job struct.
 func (j *Job) Validate() error {
+	if errs := j.validate(); len(errs) > 0 {
+		return errs[0].err
+	}
+
+	return nil
+}
+
+// FieldErrors validates the job like Validate, but reports the errors of all invalid fields instead of the first one.
+func (j *Job) FieldErrors() []FieldError {
+	fieldErrors := []FieldError{}
+	for _, fieldErr := range j.validate() {
+		fieldErrors = append(fieldErrors, FieldError{Field: fieldErr.field, Message: fieldErr.err.Error()})
+	}
+
+	return fieldErrors
+}
+
+type jobFieldError struct {
+	field string
+	err   error
+}
+
+// validate returns the validation errors of the job, in the order they are reported by Validate.
+func (j *Job) validate() []jobFieldError {
+	var errs []jobFieldError
+	add := func(field string, err error) {
+		if err != nil {
+			// nested errors are reported on the field they are about
+			if nested, ok := errorFields[err]; ok {
+				field = nested
+			}
+			errs = append(errs, jobFieldError{field: field, err: err})
+		}
+	}
+
 	if j.ID == uuid.Nil {
-		return error2.ErrInvalidJobID
+		add("id", error2.ErrInvalidJobID)
+	}
+
+	if j.Name.Valid {
+		add("name", ValidateJobName(j.Name.String))
+	}
+
+	if j.ExternalID.Valid {
+		add("external_id", ValidateExternalID(j.ExternalID.String))
+	}
+
+	if j.Owner.Valid {
+		add("owner", ValidateOwner(j.Owner.String))
+	}
+
+	if j.Team.Valid {
+		add("team", ValidateTeam(j.Team.String))
 	}
 
 	if !j.Type.Valid() {
-		return error2.ErrInvalidJobType
+		add("type", error2.ErrInvalidJobType)
 	}
 
 	if !j.Status.Valid() {
-		return error2.ErrInvalidJobStatus
+		add("status", error2.ErrInvalidJobStatus)
+	}
+
+	if !j.Priority.OrDefault().Valid() {
+		add("priority", error2.ErrInvalidJobPriority)
+	}
+
+	add("required_capabilities", ValidateCapabilities(j.RequiredCapabilities))
+
+	if j.ExecutionTimeout.Valid && (j.ExecutionTimeout.Int64 <= 0 || j.ExecutionTimeout.Int64 > int64(maxExecutionTimeout.Seconds())) {
+		add("execution_timeout", error2.ErrInvalidExecutionTimeout)
+	}
+
+	if !j.MisfirePolicy.OrDefault().Valid() {
+		add("misfire_policy", error2.ErrInvalidMisfirePolicy)
+	}
+
+	if j.SLA != nil {
+		add("sla", j.SLA.Validate())
+	}
+
+	if j.ResultSink != nil {
+		add("result_sink", j.ResultSink.Validate())
 	}
 
+	add("metric_labels", ValidateMetricLabels(j.MetricLabels))
+
+	add("metadata", ValidateMetadata(j.Metadata))
+
 	if j.Type == JobTypeHTTP {
-		if err := j.HTTPJob.Validate(); err != nil {
-			return err
-		}
+		add("http_job", j.HTTPJob.Validate())
 
 		if j.AMQPJob != nil {
-			return error2.ErrInvalidJobFields
+			add("amqp_job", error2.ErrInvalidJobFields)
 		}
 	}
 
 	if j.Type == JobTypeAMQP {
-		if err := j.AMQPJob.Validate(); err != nil {
-			return err
-		}
+		add("amqp_job", j.AMQPJob.Validate())
 
 		if j.HTTPJob != nil {
-			return error2.ErrInvalidJobFields
+			add("http_job", error2.ErrInvalidJobFields)
 		}
 	}
 
+	if j.Type.Custom() {
+		add("custom_job", validateCustomJob(j.CustomJob))
+
+		if j.HTTPJob != nil || j.AMQPJob != nil {
+			add("custom_job", error2.ErrInvalidJobFields)
+		}
+	} else if j.CustomJob != nil {
+		add("custom_job", error2.ErrInvalidJobFields)
+	}
+
 	// only one of execute_at or cron_schedule can be defined
 	if j.ExecuteAt.Valid == j.CronSchedule.Valid {
-		return error2.ErrInvalidJobSchedule
+		add("cron_schedule", error2.ErrInvalidJobSchedule)
 	}
 
 	if j.CronSchedule.Valid {
-		if _, err := cron.ParseStandard(j.CronSchedule.String); err != nil {
-			return error2.ErrInvalidCronSchedule
+		// schedules which never fire again, e.g. in a past year or on February 30, are invalid as well
+		if schedule, err := ParseSchedule(j.CronSchedule.String); err != nil || schedule.Next(time.Now()).IsZero() {
+			add("cron_schedule", error2.ErrInvalidCronSchedule)
 		}
-		cron.NewChain()
 	}
 
 	if j.ExecuteAt.Valid {
 		if j.ExecuteAt.Time.Before(time.Now()) {
-			return error2.ErrInvalidExecuteAt
+			add("execute_at", error2.ErrInvalidExecuteAt)
 		}
 	}
 
-	return nil
+	// TTL only makes sense for one-off jobs, as recurring jobs never complete
+	if j.TTL.Valid && (!j.ExecuteAt.Valid || j.TTL.Int64 <= 0) {
+		add("ttl", error2.ErrInvalidJobTTL)
+	}
+
+	return errs
 }
 
-// RemoveCredentials removes sensitive information from the job, when returning it to the user.
-func (j *Job) RemoveCredentials() {
-	if j.HTTPJob != nil {
-		j.HTTPJob.RemoveCredentials()
+// ValidateJobName validates a job name: alphanumeric characters, '.', '_' or '-', at most 128 characters.
+func ValidateJobName(name string) error {
+	if !jobNamePattern.MatchString(name) {
+		return error2.ErrInvalidJobName
 	}
 
-	if j.AMQPJob != nil {
-		j.AMQPJob.RemoveCredentials()
+	return nil
+}
+
+// ValidateExternalID validates an external ID: alphanumeric characters, '.', '_', '-', ':' or '@', at most 255
+// characters.
+func ValidateExternalID(externalID string) error {
+	if !externalIDPattern.MatchString(externalID) {
+		return error2.ErrInvalidExternalID
 	}
+
+	return nil
 }
 
+// RemoveCredentials removes sensitive information from the job, when returning it to the user. The credentials are
+// the fields of the job tagged with redact.
+func (j *Job) RemoveCredentials() {
+	redact.Apply(j)
+}
+
+// maxDriftCompensationSteps limits how many missed occurrences are skipped when compensating for drift.
+const maxDriftCompensationSteps = 1000
+
 func (j *Job) SetNextRunTime() {
+	j.setNextRunTimeAfter(time.Now())
+}
+
+// SkipRun moves NextRun past the given run even if it isn't due yet, e.g. when its execution was abandoned, along
+// with the occurrences missed since.
+func (j *Job) SkipRun(run time.Time) {
+	j.NextRun = null.TimeFrom(run)
+
+	now := time.Now()
+	if run.After(now) {
+		now = run
+	}
+	j.setNextRunTimeAfter(now)
+}
+
+func (j *Job) setNextRunTimeAfter(now time.Time) {
 	// if the job is a recurring job, set NextRun to the next time the job should run
 	if j.CronSchedule.Valid {
-		schedule, err := cron.ParseStandard(j.CronSchedule.String)
+		schedule, err := ParseSchedule(j.CronSchedule.String)
 		if err != nil {
 			return
 		}
 
-		j.NextRun = null.TimeFrom(schedule.Next(time.Now()))
+		j.NextRun = nextRunTime(nextRunAfter(schedule, j.NextRun, now))
 	}
 
 	// if the job is a one-off job, set NextRun to null
@@ -201,14 +480,45 @@ func (j *Job) SetNextRunTime() {
 	j.UpdatedAt = time.Now()
 }
 
+// nextRunTime returns the next run of the job, null if its schedule never fires again, which schedules report with the
+// zero time.
+func nextRunTime(next time.Time) null.Time {
+	if next.IsZero() {
+		return null.Time{}
+	}
+
+	return null.TimeFrom(next)
+}
+
+// nextRunAfter computes the next run based on the previously scheduled run instead of the current time,
+// so that late executions don't shift the schedule. Occurrences that were missed are skipped. The zero time is
+// returned if the schedule never fires again.
+func nextRunAfter(schedule cron.Schedule, scheduled null.Time, now time.Time) time.Time {
+	// the zero time was stored as the next run of schedules which never fire again
+	if !scheduled.Valid || scheduled.Time.IsZero() || scheduled.Time.After(now) {
+		return schedule.Next(now)
+	}
+
+	next := schedule.Next(scheduled.Time)
+	for i := 0; !next.IsZero() && !next.After(now); i++ {
+		if i == maxDriftCompensationSteps {
+			return schedule.Next(now)
+		}
+
+		next = schedule.Next(next)
+	}
+
+	return next
+}
+
 func (j *Job) SetInitialRunTime() {
 	if j.CronSchedule.Valid {
-		schedule, err := cron.ParseStandard(j.CronSchedule.String)
+		schedule, err := ParseSchedule(j.CronSchedule.String)
 		if err != nil {
 			return
 		}
 
-		j.NextRun = null.TimeFrom(schedule.Next(time.Now()))
+		j.NextRun = nextRunTime(schedule.Next(time.Now()))
 	}
 
 	if j.ExecuteAt.Valid {
@@ -218,6 +528,16 @@ func (j *Job) SetInitialRunTime() {
 
 type JobCreate struct {
 
+	// Optional name, unique within the namespace
+	Name null.String `json:"name,omitempty" swaggertype:"string"`
+
+	// Optional ID set by the client, unique within the namespace
+	ExternalID null.String `json:"external_id,omitempty" swaggertype:"string"`
+
+	// Owner and team of the job, the name and team of the API key by default
+	Owner null.String `json:"owner,omitempty" swaggertype:"string"`
+	Team  null.String `json:"team,omitempty" swaggertype:"string"`
+
 	// Job type
 	Type JobType `json:"type"`
 
@@ -225,25 +545,70 @@ type JobCreate struct {
 	ExecuteAt    null.Time   `json:"execute_at" swaggertype:"string"`    // for one-off jobs
 	CronSchedule null.String `json:"cron_schedule" swaggertype:"string"` // for recurring jobs
 
-	// HTTPJob and AMQPJob are mutually exclusive.
-	HTTPJob *HTTPJob `json:"http_job,omitempty"`
-	AMQPJob *AMQPJob `json:"amqp_job,omitempty"`
+	// HTTPJob, AMQPJob and CustomJob are mutually exclusive.
+	HTTPJob   *HTTPJob        `json:"http_job,omitempty"`
+	AMQPJob   *AMQPJob        `json:"amqp_job,omitempty"`
+	CustomJob json.RawMessage `json:"custom_job,omitempty" swaggertype:"object"`
 
 	Tags []string `json:"tags"`
+
+	// Free-form metadata of the job, e.g. a runbook link, a cost center or a ticket ID
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Priority of the job among the due jobs, NORMAL by default
+	Priority JobPriority `json:"priority,omitempty"`
+
+	// Capabilities a runner must have to execute the job, e.g. region=eu
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+
+	// Number of seconds an execution can take before it is aborted and recorded as timed out
+	ExecutionTimeout null.Int `json:"execution_timeout" swaggertype:"integer"`
+
+	// Whether an occurrence missed because its execution was abandoned is run once more, RUN_ONCE by default
+	MisfirePolicy MisfirePolicy `json:"misfire_policy,omitempty"`
+
+	// SLA the executions of the job must meet
+	SLA *JobSLA `json:"sla,omitempty"`
+
+	// ResultSink the result of each execution of the job is published to
+	ResultSink *JobResultSink `json:"result_sink,omitempty"`
+
+	// Labels added to the execution metrics of the job: job_id, job_name or tag:<key>
+	MetricLabels []string `json:"metric_labels,omitempty"`
+
+	// TTL (in seconds) after which a completed one-off job is archived or deleted.
+	TTL null.Int `json:"ttl" swaggertype:"integer"`
 }
 
 func (j *JobCreate) ToJob() *Job {
 	job := &Job{
 		ID:           uuid.New(),
+		Namespace:    DefaultNamespace,
+		Name:         j.Name,
+		ExternalID:   j.ExternalID,
+		Owner:        j.Owner,
+		Team:         j.Team,
 		Type:         j.Type,
 		Status:       JobStatusRunning,
+		Version:      1,
 		ExecuteAt:    j.ExecuteAt,
 		CronSchedule: j.CronSchedule,
 		HTTPJob:      j.HTTPJob,
 		AMQPJob:      j.AMQPJob,
+		CustomJob:    j.CustomJob,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 		Tags:         j.Tags,
+		Metadata:     j.Metadata,
+		Priority:     j.Priority.OrDefault(),
+		TTL:          j.TTL,
+
+		RequiredCapabilities: j.RequiredCapabilities,
+		ExecutionTimeout:     j.ExecutionTimeout,
+		MisfirePolicy:        j.MisfirePolicy.OrDefault(),
+		SLA:                  j.SLA,
+		ResultSink:           j.ResultSink,
+		MetricLabels:         j.MetricLabels,
 	}
 
 	job.SetInitialRunTime()
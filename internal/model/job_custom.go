@@ -0,0 +1,61 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sync"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// customJobTypePattern restricts custom job types to uppercase identifiers, like the built-in HTTP and AMQP types.
+var customJobTypePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,63}$`)
+
+var (
+	customJobTypesMu sync.RWMutex
+	customJobTypes   = map[JobType]bool{}
+)
+
+// RegisterJobType registers a custom job type, whose jobs are defined by an arbitrary JSON object in custom_job and
+// executed by the executor registered for the type. Job types are registered when the process starts, before jobs
+// are validated or executed, and registering a type twice is a no-op.
+func RegisterJobType(jobType JobType) error {
+	if jobType == JobTypeHTTP || jobType == JobTypeAMQP || !customJobTypePattern.MatchString(string(jobType)) {
+		return error2.ErrInvalidJobType
+	}
+
+	customJobTypesMu.Lock()
+	defer customJobTypesMu.Unlock()
+
+	if customJobTypes[jobType] {
+		return nil
+	}
+
+	customJobTypes[jobType] = true
+	JobTypes = append(JobTypes, jobType)
+
+	return nil
+}
+
+// Custom reports whether the job type is a registered custom job type.
+func (jt JobType) Custom() bool {
+	customJobTypesMu.RLock()
+	defer customJobTypesMu.RUnlock()
+
+	return customJobTypes[jt]
+}
+
+// validateCustomJob validates the definition of a job of a custom type, which must be a JSON object.
+func validateCustomJob(customJob json.RawMessage) error {
+	trimmed := bytes.TrimSpace(customJob)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return error2.ErrCustomJobNotDefined
+	}
+
+	if exceeds(len(trimmed), GetPayloadLimits().MaxBodySize) {
+		return error2.ErrCustomJobTooLarge
+	}
+
+	return nil
+}
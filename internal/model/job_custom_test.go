@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestRegisterJobType(t *testing.T) {
+	assert.ErrorIs(t, RegisterJobType(JobTypeHTTP), error2.ErrInvalidJobType)
+	assert.ErrorIs(t, RegisterJobType("lowercase"), error2.ErrInvalidJobType)
+	assert.ErrorIs(t, RegisterJobType(""), error2.ErrInvalidJobType)
+
+	jobType := JobType("MODEL_TEST")
+	assert.False(t, jobType.Valid())
+
+	require.NoError(t, RegisterJobType(jobType))
+	require.NoError(t, RegisterJobType(jobType))
+
+	assert.True(t, jobType.Valid())
+	assert.True(t, jobType.Custom())
+	assert.False(t, JobTypeHTTP.Custom())
+	assert.Equal(t, 1, countJobType(jobType))
+}
+
+func TestValidateCustomJob(t *testing.T) {
+	jobType := JobType("MODEL_VALIDATE_TEST")
+	require.NoError(t, RegisterJobType(jobType))
+
+	newJob := func() Job {
+		return Job{
+			ID:        uuid.New(),
+			Type:      jobType,
+			Status:    JobStatusRunning,
+			ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+			CustomJob: json.RawMessage(`{"queue": "reports"}`),
+		}
+	}
+
+	job := newJob()
+	assert.NoError(t, job.Validate())
+
+	job = newJob()
+	job.CustomJob = nil
+	assert.ErrorIs(t, job.Validate(), error2.ErrCustomJobNotDefined)
+
+	job = newJob()
+	job.CustomJob = json.RawMessage(`["reports"]`)
+	assert.ErrorIs(t, job.Validate(), error2.ErrCustomJobNotDefined)
+
+	job = newJob()
+	job.HTTPJob = &HTTPJob{URL: "https://example.com", Method: "GET", Auth: Auth{Type: AuthTypeNone}}
+	assert.ErrorIs(t, job.Validate(), error2.ErrInvalidJobFields)
+
+	// built-in job types can't have a custom definition
+	job = newJob()
+	job.Type = JobTypeHTTP
+	job.HTTPJob = &HTTPJob{URL: "https://example.com", Method: "GET", Auth: Auth{Type: AuthTypeNone}}
+	assert.ErrorIs(t, job.Validate(), error2.ErrInvalidJobFields)
+
+	limits := GetPayloadLimits()
+	defer SetPayloadLimits(limits)
+	SetPayloadLimits(PayloadLimits{MaxBodySize: 8})
+
+	job = newJob()
+	assert.ErrorIs(t, job.Validate(), error2.ErrCustomJobTooLarge)
+}
+
+func countJobType(jobType JobType) int {
+	count := 0
+	for _, registered := range JobTypes {
+		if registered == jobType {
+			count++
+		}
+	}
+	return count
+}
@@ -1,21 +1,44 @@
 package model
 
 import (
+	"errors"
 	"time"
 
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
 	"github.com/google/uuid"
 	"gopkg.in/guregu/null.v4"
 )
 
 type JobExecution struct {
-	ID                 int         `json:"id"`
-	JobID              uuid.UUID   `json:"job_id"`
-	StartTime          time.Time   `json:"start_time"`
-	EndTime            time.Time   `json:"end_time"`
+	ID        int                `json:"id"`
+	JobID     uuid.UUID          `json:"job_id"`
+	Namespace string             `json:"namespace"`
+	Status    JobExecutionStatus `json:"status"`
+	StartTime time.Time          `json:"start_time"`
+	// EndTime is null while the execution is running
+	EndTime            null.Time   `json:"end_time" swaggertype:"string"`
 	Success            bool        `json:"success"`
 	NumberOfExecutions int         `json:"number_of_executions"`
 	NumberOfRetries    int         `json:"number_of_retries"`
 	ErrorMessage       null.String `json:"error_message,omitempty" swaggertype:"string"`
+
+	// ScheduledTime is the time the execution was scheduled to start at.
+	ScheduledTime null.Time `json:"scheduled_time,omitempty" swaggertype:"string"`
+	// Drift is the delay between the scheduled and the actual start time, in milliseconds.
+	Drift null.Int `json:"drift_ms,omitempty" swaggertype:"integer"`
+	// CancelRequestedAt is the time the cancellation of a running execution was requested.
+	CancelRequestedAt null.Time `json:"cancel_requested_at,omitempty" swaggertype:"string"`
+
+	// JobVersion is the version of the job the execution ran with, if it was recorded.
+	JobVersion null.Int `json:"job_version,omitempty" swaggertype:"integer"`
+	// RetryOf is the ID of the execution this execution re-runs.
+	RetryOf null.Int `json:"retry_of,omitempty" swaggertype:"integer"`
+	// Overridden reports whether the execution is a manual run with an overridden definition.
+	Overridden bool `json:"overridden,omitempty"`
+
+	// Progress is the latest progress reported by the executor, if it reported any.
+	Progress *ExecutionProgress `json:"progress,omitempty"`
 }
 
 type JobExecutionStatus string
@@ -23,4 +46,92 @@ type JobExecutionStatus string
 const (
 	JobExecutionStatusSuccessful JobExecutionStatus = "SUCCESSFUL"
 	JobExecutionStatusFailed     JobExecutionStatus = "FAILED"
+	JobExecutionStatusRunning    JobExecutionStatus = "RUNNING"
+	JobExecutionStatusCancelled  JobExecutionStatus = "CANCELLED"
+	// JobExecutionStatusTimedOut is the status of executions that exceeded the execution timeout of their job
+	JobExecutionStatusTimedOut JobExecutionStatus = "TIMED_OUT"
+	// JobExecutionStatusPending is the status of retries waiting for a runner
+	JobExecutionStatusPending JobExecutionStatus = "PENDING"
 )
+
+// ExecutionOutcome returns the status and error message of an execution that finished with the error, masking the
+// secrets found in the message.
+func ExecutionOutcome(err error, secrets []string) (JobExecutionStatus, null.String) {
+	if err == nil {
+		return JobExecutionStatusSuccessful, null.String{}
+	}
+
+	message := null.StringFrom(redact.Message(err.Error(), secrets))
+
+	if errors.Is(err, error2.ErrExecutionCancelled) || errors.Is(err, error2.ErrExecutionInterrupted) {
+		return JobExecutionStatusCancelled, message
+	}
+
+	if errors.Is(err, error2.ErrExecutionTimedOut) {
+		return JobExecutionStatusTimedOut, message
+	}
+
+	return JobExecutionStatusFailed, message
+}
+
+// RetryDefinition selects the job definition a retried execution runs with.
+type RetryDefinition string
+
+const (
+	// RetryDefinitionOriginal re-runs the execution with the job definition it ran with.
+	RetryDefinitionOriginal RetryDefinition = "original"
+	// RetryDefinitionCurrent re-runs the execution with the current job definition.
+	RetryDefinitionCurrent RetryDefinition = "current"
+)
+
+// Validate validates a RetryDefinition.
+func (d RetryDefinition) Validate() error {
+	switch d {
+	case RetryDefinitionOriginal, RetryDefinitionCurrent:
+		return nil
+	default:
+		return error2.ErrInvalidRetryDefinition
+	}
+}
+
+// ZombieExecution is an execution left running by a runner that stopped renewing the lock of the job, e.g. because
+// it crashed.
+type ZombieExecution struct {
+	ExecutionID   int
+	ScheduledTime null.Time
+	Job           *Job
+}
+
+// ExecutionRetry is a retry of an execution, claimed by a runner. The job has the definition the retry runs with.
+type ExecutionRetry struct {
+	ExecutionID int
+	Job         *Job
+}
+
+// JobExecutionStats aggregates the executions of a job.
+type JobExecutionStats struct {
+	JobID      uuid.UUID `json:"job_id"`
+	Total      uint64    `json:"total"`
+	Successful uint64    `json:"successful"`
+	Failed     uint64    `json:"failed"`
+	Cancelled  uint64    `json:"cancelled"`
+	TimedOut   uint64    `json:"timed_out"`
+	// Average duration of the finished executions, in milliseconds
+	AverageDuration null.Float `json:"average_duration_ms,omitempty" swaggertype:"number"`
+	LastExecutionAt null.Time  `json:"last_execution_at,omitempty" swaggertype:"string"`
+	// SLA is the compliance of the job with its SLA, if it has one
+	SLA *JobSLAStatus `json:"sla,omitempty"`
+}
+
+// ExecutionExpiry selects the finished executions expired by a retention policy.
+type ExecutionExpiry struct {
+	// JobID restricts the expiry to the executions of a job, if set
+	JobID *uuid.UUID
+	// Before expires the executions created before the time, if set
+	Before null.Time
+	// KeepLast expires the executions of a job beyond its latest ones, if positive
+	KeepLast uint
+	// MatchAll only expires the executions expired by both the age and the count, e.g. the old executions of a job
+	// which are not among its latest ones, instead of the executions expired by either
+	MatchAll bool
+}
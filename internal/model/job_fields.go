@@ -0,0 +1,98 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// jobFieldNames are the JSON names of the fields of a job.
+var jobFieldNames = jsonFieldNames(reflect.TypeOf(Job{}))
+
+// JobFields are the fields of the jobs returned by a listing, by their JSON names, e.g. id,status,next_run.
+// An empty set selects all fields. The ID is always included, so sparse jobs can be told apart.
+type JobFields []string
+
+// ParseJobFields parses a comma-separated list of job fields.
+func ParseJobFields(expression string) (JobFields, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, nil
+	}
+
+	fields := JobFields{"id"}
+	for _, field := range strings.Split(expression, ",") {
+		field = strings.TrimSpace(field)
+		if !jobFieldNames[field] {
+			return nil, error2.ErrInvalidJobFieldSelection
+		}
+		if !fields.Includes(field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+// Includes reports whether the field is selected.
+func (f JobFields) Includes(field string) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	for _, selected := range f {
+		if selected == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IncludesPayloads reports whether the HTTP or AMQP definitions of the jobs are selected. Their credentials are
+// encrypted, so they are only decrypted when selected.
+func (f JobFields) IncludesPayloads() bool {
+	return f.Includes("http_job") || f.Includes("amqp_job")
+}
+
+// Select returns the selected fields of the job, encoded as in the full job. Fields omitted from the JSON
+// encoding of the job when empty, such as http_job, are omitted as well.
+func (f JobFields) Select(job Job) (map[string]json.RawMessage, error) {
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]json.RawMessage, len(f))
+	for name, value := range all {
+		if f.Includes(name) {
+			selected[name] = value
+		}
+	}
+
+	return selected, nil
+}
+
+// jsonFieldNames returns the JSON names of the exported fields of the struct type.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[name] = true
+	}
+
+	return names
+}
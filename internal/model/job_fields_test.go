@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestParseJobFields(t *testing.T) {
+	fields, err := ParseJobFields("")
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+	assert.True(t, fields.Includes("http_job"))
+	assert.True(t, fields.IncludesPayloads())
+
+	fields, err = ParseJobFields("status, next_run,status")
+	require.NoError(t, err)
+	assert.Equal(t, JobFields{"id", "status", "next_run"}, fields)
+	assert.False(t, fields.IncludesPayloads())
+
+	fields, err = ParseJobFields("id,amqp_job")
+	require.NoError(t, err)
+	assert.Equal(t, JobFields{"id", "amqp_job"}, fields)
+	assert.True(t, fields.IncludesPayloads())
+
+	_, err = ParseJobFields("id,password")
+	assert.Equal(t, error2.ErrInvalidJobFieldSelection, err)
+
+	_, err = ParseJobFields("id,")
+	assert.Equal(t, error2.ErrInvalidJobFieldSelection, err)
+}
+
+func TestJobPageSparse(t *testing.T) {
+	id := uuid.MustParse("a787fa30-2cbe-40de-9a51-f7c9fc43a747")
+	page := &JobPage{
+		Jobs:       []Job{{ID: id, Status: JobStatusRunning, CronSchedule: null.StringFrom("@daily"), Tags: []string{"a"}}},
+		Pagination: Pagination{Total: 1},
+	}
+
+	fields, err := ParseJobFields("status,next_run,cron_schedule")
+	require.NoError(t, err)
+
+	sparse, err := page.Sparse(fields)
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(sparse)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"jobs": [{"id": "a787fa30-2cbe-40de-9a51-f7c9fc43a747", "status": "RUNNING", "next_run": null, "cron_schedule": "@daily"}],
+		"next_cursor": null,
+		"total": 1
+	}`, string(encoded))
+}
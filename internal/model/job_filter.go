@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+	"unicode/utf8"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+)
+
+const maxSearchQueryLength = 256
+
+// JobFilter narrows down the jobs returned by a listing. Empty fields are ignored.
+type JobFilter struct {
+	// Namespace is set by the job service from the request context
+	Namespace string
+	// Team of the jobs, enforced by the job service for the API keys restricted to their team
+	Team string
+
+	IDs []uuid.UUID
+
+	// Query is a free text search over the job URL, exchange, routing key and tags
+	Query string
+
+	Statuses []JobStatus
+	Types    []JobType
+
+	// Jobs must have all of the Tags and at least one of the AnyTags
+	Tags    []string
+	AnyTags []string
+	// Jobs must match the tag selector
+	Selector TagSelector
+
+	NextRunFrom *time.Time
+	NextRunTo   *time.Time
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	// Failed filters jobs by whether their last execution failed
+	Failed *bool
+
+	// Fields doesn't narrow down the jobs but the fields returned for each of them, all of them if empty
+	Fields JobFields
+	// ExactTotal counts the matching jobs exactly, instead of estimating their number when there are many of them
+	ExactTotal bool
+}
+
+// Validate validates a JobFilter struct.
+func (f *JobFilter) Validate() error {
+	if utf8.RuneCountInString(f.Query) > maxSearchQueryLength {
+		return error2.ErrInvalidJobFilter
+	}
+
+	if f.Team != "" && ValidateTeam(f.Team) != nil {
+		return error2.ErrInvalidJobFilter
+	}
+
+	for _, status := range f.Statuses {
+		switch status {
+		case JobStatusRunning, JobStatusStopped, JobStatusArchived:
+		default:
+			return error2.ErrInvalidJobFilter
+		}
+	}
+
+	for _, jobType := range f.Types {
+		if !jobType.Valid() {
+			return error2.ErrInvalidJobFilter
+		}
+	}
+
+	if f.NextRunFrom != nil && f.NextRunTo != nil && f.NextRunFrom.After(*f.NextRunTo) {
+		return error2.ErrInvalidJobFilter
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return error2.ErrInvalidJobFilter
+	}
+
+	return nil
+}
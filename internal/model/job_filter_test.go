@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobFilterValidate(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		filter JobFilter
+		want   error
+	}{
+		{name: "empty filter", filter: JobFilter{}, want: nil},
+		{name: "valid filter", filter: JobFilter{
+			Statuses:    []JobStatus{JobStatusRunning, JobStatusArchived},
+			Types:       []JobType{JobTypeHTTP},
+			CreatedFrom: &before,
+			CreatedTo:   &now,
+		}, want: nil},
+		{name: "invalid status", filter: JobFilter{Statuses: []JobStatus{"INVALID"}}, want: error2.ErrInvalidJobFilter},
+		{name: "invalid type", filter: JobFilter{Types: []JobType{"INVALID"}}, want: error2.ErrInvalidJobFilter},
+		{name: "invalid next run range", filter: JobFilter{NextRunFrom: &now, NextRunTo: &before}, want: error2.ErrInvalidJobFilter},
+		{name: "too long search query", filter: JobFilter{Query: strings.Repeat("a", 257)}, want: error2.ErrInvalidJobFilter},
+		{name: "invalid team", filter: JobFilter{Team: "Payments"}, want: error2.ErrInvalidJobFilter},
+		{name: "invalid created range", filter: JobFilter{CreatedFrom: &now, CreatedTo: &before}, want: error2.ErrInvalidJobFilter},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.filter.Validate())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
 	"gopkg.in/guregu/null.v4"
 )
 
@@ -45,6 +46,15 @@ func (httpJob *HTTPJob) Validate() error {
 		return error2.ErrEmptyHTTPJobMethod
 	}
 
+	limits := GetPayloadLimits()
+	if exceeds(len(httpJob.Body.String), limits.MaxBodySize) {
+		return error2.ErrHTTPJobBodyTooLarge
+	}
+
+	if exceeds(httpHeadersSize(httpJob.Headers), limits.MaxHeadersSize) {
+		return error2.ErrHTTPJobHeadersTooLarge
+	}
+
 	if err := httpJob.Auth.Validate(); err != nil {
 		return err
 	}
@@ -52,17 +62,16 @@ func (httpJob *HTTPJob) Validate() error {
 	return nil
 }
 
+// RemoveCredentials removes the credentials from the HTTPJob struct.
 func (httpJob *HTTPJob) RemoveCredentials() {
-	httpJob.Auth.Username = null.String{}
-	httpJob.Auth.Password = null.String{}
-	httpJob.Auth.BearerToken = null.String{}
+	redact.Apply(httpJob)
 }
 
 type Auth struct {
-	Type        AuthType    `json:"type"`                                        // e.g., "none", "basic", "bearer"
-	Username    null.String `json:"username,omitempty" swaggertype:"string"`     // for "basic"
-	Password    null.String `json:"password,omitempty" swaggertype:"string"`     // for "basic"
-	BearerToken null.String `json:"bearer_token,omitempty" swaggertype:"string"` // for "bearer"
+	Type        AuthType    `json:"type"`                                                      // e.g., "none", "basic", "bearer"
+	Username    null.String `json:"username,omitempty" swaggertype:"string" redact:"true"`     // for "basic"
+	Password    null.String `json:"password,omitempty" swaggertype:"string" redact:"true"`     // for "basic"
+	BearerToken null.String `json:"bearer_token,omitempty" swaggertype:"string" redact:"true"` // for "bearer"
 }
 
 func (auth *Auth) Validate() error {
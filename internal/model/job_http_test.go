@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 
 	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
@@ -61,6 +62,30 @@ func TestHTTPJobValidate(t *testing.T) {
 			},
 			want: error2.ErrEmptyHTTPJobMethod,
 		},
+		{
+			name: "invalid job: body too large",
+			job: HTTPJob{
+				URL:    "https://example.com",
+				Method: "POST",
+				Body:   null.StringFrom(strings.Repeat("a", DefaultPayloadLimits.MaxBodySize+1)),
+				Auth: Auth{
+					Type: AuthTypeNone,
+				},
+			},
+			want: error2.ErrHTTPJobBodyTooLarge,
+		},
+		{
+			name: "invalid job: headers too large",
+			job: HTTPJob{
+				URL:     "https://example.com",
+				Method:  "GET",
+				Headers: map[string]string{"X-Large": strings.Repeat("a", DefaultPayloadLimits.MaxHeadersSize)},
+				Auth: Auth{
+					Type: AuthTypeNone,
+				},
+			},
+			want: error2.ErrHTTPJobHeadersTooLarge,
+		},
 	}
 
 	for _, tc := range tests {
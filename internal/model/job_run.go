@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/json"
+	"maps"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// JobRunOverrides overrides the definition of a job for a single manual run, e.g. to replay it with a tweaked
+// payload. The execution records the overridden definition, the job itself is left unchanged.
+//
+// swagger:model JobRunOverrides
+type JobRunOverrides struct {
+	// Values of the parameters of the template the job was instantiated from, whose definition is rendered again
+	// with them. Parameters with a default value can be omitted.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	HTTP       *HTTPRunOverrides `json:"http,omitempty"`
+	AMQP       *AMQPRunOverrides `json:"amqp,omitempty"`
+	// JSON merge patch of the definition of a custom job
+	Custom json.RawMessage `json:"custom,omitempty" swaggertype:"object"`
+}
+
+// HTTPRunOverrides overrides the request of an HTTP job.
+type HTTPRunOverrides struct {
+	// Body replacing the body of the request
+	Body null.String `json:"body,omitempty" swaggertype:"string"`
+	// Headers merged into the headers of the request, headers with an empty value are removed
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// AMQPRunOverrides overrides the message of an AMQP job.
+type AMQPRunOverrides struct {
+	// Body replacing the body of the message, in the body encoding of the job
+	Body null.String `json:"body,omitempty" swaggertype:"string"`
+	// Headers merged into the headers of the message, headers set to null are removed
+	Headers map[string]interface{} `json:"headers,omitempty"`
+}
+
+// IsEmpty reports whether the run doesn't override anything, and runs the current definition of the job.
+func (o *JobRunOverrides) IsEmpty() bool {
+	return o == nil || (o.Parameters == nil && o.HTTP == nil && o.AMQP == nil && len(o.Custom) == 0)
+}
+
+// Template returns the name of the template the job was instantiated from, if the overrides render it again.
+func (o *JobRunOverrides) Template(job *Job) (string, error) {
+	if o.Parameters == nil {
+		return "", nil
+	}
+
+	for _, tag := range job.Tags {
+		if label := ParseLabel(tag); label.Key == TemplateTagKey && label.Value != "" {
+			return label.Value, nil
+		}
+	}
+
+	return "", error2.ErrInvalidRunOverrides
+}
+
+// Apply returns a copy of the job with its definition overridden, rendered from the template first if the overrides
+// have parameters. Only the definition of the HTTP, AMQP or custom job is overridden, so the copy keeps the
+// version, schedule and settings of the job.
+func (o *JobRunOverrides) Apply(job *Job, template *JobTemplate) (*Job, error) {
+	overridden := *job
+	if overridden.HTTPJob != nil {
+		httpJob := *overridden.HTTPJob
+		httpJob.Headers = maps.Clone(httpJob.Headers)
+		overridden.HTTPJob = &httpJob
+	}
+	if overridden.AMQPJob != nil {
+		amqpJob := *overridden.AMQPJob
+		amqpJob.Headers = maps.Clone(amqpJob.Headers)
+		overridden.AMQPJob = &amqpJob
+	}
+
+	if o.Parameters != nil {
+		if template == nil {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+
+		definition, err := template.Render(o.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		if definition.Type != job.Type {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+		overridden.HTTPJob = definition.HTTPJob
+		overridden.AMQPJob = definition.AMQPJob
+		overridden.CustomJob = definition.CustomJob
+	}
+
+	if o.HTTP != nil {
+		if overridden.HTTPJob == nil {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+
+		if o.HTTP.Body.Valid {
+			overridden.HTTPJob.Body = o.HTTP.Body
+		}
+		for name, value := range o.HTTP.Headers {
+			if overridden.HTTPJob.Headers == nil {
+				overridden.HTTPJob.Headers = map[string]string{}
+			}
+			if value == "" {
+				delete(overridden.HTTPJob.Headers, name)
+			} else {
+				overridden.HTTPJob.Headers[name] = value
+			}
+		}
+	}
+
+	if o.AMQP != nil {
+		if overridden.AMQPJob == nil {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+
+		if o.AMQP.Body.Valid {
+			overridden.AMQPJob.Body = o.AMQP.Body.String
+		}
+		for name, value := range o.AMQP.Headers {
+			if overridden.AMQPJob.Headers == nil {
+				overridden.AMQPJob.Headers = map[string]interface{}{}
+			}
+			if value == nil {
+				delete(overridden.AMQPJob.Headers, name)
+			} else {
+				overridden.AMQPJob.Headers[name] = value
+			}
+		}
+	}
+
+	if len(o.Custom) > 0 {
+		if overridden.CustomJob == nil {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+
+		var patch, document map[string]interface{}
+		if err := json.Unmarshal(o.Custom, &patch); err != nil || patch == nil {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+		if err := json.Unmarshal(overridden.CustomJob, &document); err != nil {
+			return nil, error2.ErrInvalidRunOverrides
+		}
+
+		patched, err := json.Marshal(mergePatch(document, patch))
+		if err != nil {
+			return nil, err
+		}
+		overridden.CustomJob = patched
+	}
+
+	// the rest of the job is not validated again, e.g. one-off jobs can be run once their time has passed
+	var err error
+	switch overridden.Type {
+	case JobTypeHTTP:
+		err = overridden.HTTPJob.Validate()
+	case JobTypeAMQP:
+		err = overridden.AMQPJob.Validate()
+	default:
+		err = validateCustomJob(overridden.CustomJob)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &overridden, nil
+}
@@ -145,6 +145,163 @@ func TestJobValidate(t *testing.T) {
 			},
 			want: error2.ErrInvalidJobSchedule,
 		},
+		{
+			name: "valid job: one-off job with ttl",
+			job: Job{
+				ID:        uuid.New(),
+				Type:      JobTypeHTTP,
+				Status:    JobStatusRunning,
+				ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				TTL:       null.IntFrom(3600),
+				CreatedAt: time.Now(),
+			},
+			want: nil,
+		},
+		{
+			name: "invalid job: recurring job with ttl",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("* * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				TTL:       null.IntFrom(3600),
+				CreatedAt: time.Now(),
+			},
+			want: error2.ErrInvalidJobTTL,
+		},
+		{
+			name: "invalid job: non-positive ttl",
+			job: Job{
+				ID:        uuid.New(),
+				Type:      JobTypeHTTP,
+				Status:    JobStatusRunning,
+				ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				TTL:       null.IntFrom(0),
+				CreatedAt: time.Now(),
+			},
+			want: error2.ErrInvalidJobTTL,
+		},
+		{
+			name: "valid job: execution timeout",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("* * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				ExecutionTimeout: null.IntFrom(30),
+				CreatedAt:        time.Now(),
+			},
+			want: nil,
+		},
+		{
+			name: "invalid job: execution timeout over 24 hours",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("* * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				ExecutionTimeout: null.IntFrom(86401),
+				CreatedAt:        time.Now(),
+			},
+			want: error2.ErrInvalidExecutionTimeout,
+		},
+		{
+			name: "invalid job: unknown misfire policy",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("* * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				MisfirePolicy: "RUN_TWICE",
+				CreatedAt:     time.Now(),
+			},
+			want: error2.ErrInvalidMisfirePolicy,
+		},
+		{
+			name: "invalid job: SLA without objective",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("* * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				SLA:       &JobSLA{Window: 3600},
+				CreatedAt: time.Now(),
+			},
+			want: error2.ErrInvalidJobSLA,
+		},
+		{
+			name: "invalid job: schedule in a past year",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("0 0 12 1 1 ? 2020"),
+				HTTPJob:      &HTTPJob{URL: "https://example.com", Method: "GET", Auth: Auth{Type: AuthTypeNone}},
+				CreatedAt:    time.Now(),
+			},
+			want: error2.ErrInvalidCronSchedule,
+		},
+		{
+			name: "invalid job: schedule on February 30",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("0 0 0 30 2 ?"),
+				HTTPJob:      &HTTPJob{URL: "https://example.com", Method: "GET", Auth: Auth{Type: AuthTypeNone}},
+				CreatedAt:    time.Now(),
+			},
+			want: error2.ErrInvalidCronSchedule,
+		},
 	}
 
 	for _, tc := range tests {
@@ -266,3 +423,119 @@ func TestRemoveCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestSetNextRunTime(t *testing.T) {
+	t.Run("late execution does not shift the schedule", func(t *testing.T) {
+		scheduled := time.Now().Add(-time.Minute * 90).Truncate(time.Second)
+		job := Job{
+			CronSchedule: null.StringFrom("@every 1h"),
+			NextRun:      null.TimeFrom(scheduled),
+		}
+
+		job.SetNextRunTime()
+
+		assert.Equal(t, scheduled.Add(2*time.Hour), job.NextRun.Time)
+	})
+
+	t.Run("future next run is computed from now", func(t *testing.T) {
+		job := Job{
+			CronSchedule: null.StringFrom("@every 1h"),
+		}
+
+		job.SetNextRunTime()
+
+		assert.True(t, job.NextRun.Time.After(time.Now().Add(time.Minute*59)))
+	})
+
+	t.Run("schedule which never fires again has no next run", func(t *testing.T) {
+		for _, expression := range []string{"0 0 12 1 1 ? 2020", "0 0 0 30 2 ?"} {
+			job := Job{CronSchedule: null.StringFrom(expression)}
+			job.SetInitialRunTime()
+			assert.False(t, job.NextRun.Valid, expression)
+
+			// including jobs whose zero next run was stored as a valid time
+			job.NextRun = null.TimeFrom(time.Time{})
+			job.SetNextRunTime()
+			assert.False(t, job.NextRun.Valid, expression)
+		}
+	})
+
+	t.Run("one-off job has no next run", func(t *testing.T) {
+		job := Job{
+			ExecuteAt: null.TimeFrom(time.Now()),
+			NextRun:   null.TimeFrom(time.Now()),
+		}
+
+		job.SetNextRunTime()
+
+		assert.False(t, job.NextRun.Valid)
+	})
+}
+
+func TestSkipRun(t *testing.T) {
+	t.Run("missed run moves to the next occurrence", func(t *testing.T) {
+		run := time.Now().Add(-time.Minute * 90).Truncate(time.Second)
+		job := Job{CronSchedule: null.StringFrom("@every 1h")}
+
+		job.SkipRun(run)
+
+		assert.Equal(t, run.Add(2*time.Hour), job.NextRun.Time)
+	})
+
+	t.Run("run not due yet moves to the occurrence after it", func(t *testing.T) {
+		run := time.Now().Add(time.Minute * 30).Truncate(time.Second)
+		job := Job{CronSchedule: null.StringFrom("@every 1h")}
+
+		job.SkipRun(run)
+
+		assert.Equal(t, run.Add(time.Hour), job.NextRun.Time)
+	})
+}
+
+func TestJobFieldErrors(t *testing.T) {
+	job := Job{
+		ID:         uuid.New(),
+		Name:       null.StringFrom("invalid name"),
+		ExternalID: null.StringFrom("stack/report"),
+		Type:       JobTypeHTTP,
+		Status:     JobStatusRunning,
+		HTTPJob: &HTTPJob{
+			Method: "GET",
+			Auth:   Auth{Type: AuthTypeNone},
+		},
+		CronSchedule: null.StringFrom("not a schedule"),
+		TTL:          null.IntFrom(60),
+	}
+
+	assert.Equal(t, []FieldError{
+		{Field: "name", Message: error2.ErrInvalidJobName.Error()},
+		{Field: "external_id", Message: error2.ErrInvalidExternalID.Error()},
+		{Field: "http_job.url", Message: error2.ErrEmptyHTTPJobURL.Error()},
+		{Field: "cron_schedule", Message: error2.ErrInvalidCronSchedule.Error()},
+		{Field: "ttl", Message: error2.ErrInvalidJobTTL.Error()},
+	}, job.FieldErrors())
+
+	// Validate reports the first of the errors
+	assert.Equal(t, error2.ErrInvalidJobName, job.Validate())
+}
+
+func TestNewJobValidation(t *testing.T) {
+	create := JobCreate{
+		Type:         JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1h"),
+		HTTPJob:      &HTTPJob{URL: "https://example.com", Method: "GET", Auth: Auth{Type: AuthTypeNone}},
+	}
+
+	validation := NewJobValidation(create.ToJob())
+	assert.True(t, validation.Valid)
+	assert.Empty(t, validation.Errors)
+	if assert.Len(t, validation.NextRuns, validationNextRuns) {
+		assert.Equal(t, time.Hour, validation.NextRuns[1].Sub(validation.NextRuns[0]))
+	}
+
+	create.CronSchedule = null.String{}
+	validation = NewJobValidation(create.ToJob())
+	assert.False(t, validation.Valid)
+	assert.Equal(t, []FieldError{{Field: "cron_schedule", Message: error2.ErrInvalidJobSchedule.Error()}}, validation.Errors)
+	assert.Empty(t, validation.NextRuns)
+}
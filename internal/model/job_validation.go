@@ -0,0 +1,91 @@
+package model
+
+import (
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// validationNextRuns is the number of upcoming runs reported when validating a recurring job.
+const validationNextRuns = 5
+
+// errorFields maps the errors of nested structs to the field they are about.
+var errorFields = map[error]string{
+	error2.ErrHTTPJobNotDefined:      "http_job",
+	error2.ErrEmptyHTTPJobURL:        "http_job.url",
+	error2.ErrEmptyHTTPJobMethod:     "http_job.method",
+	error2.ErrAuthMethodNotDefined:   "http_job.auth",
+	error2.ErrInvalidAuthType:        "http_job.auth.type",
+	error2.ErrEmptyUsername:          "http_job.auth.username",
+	error2.ErrEmptyPassword:          "http_job.auth.password",
+	error2.ErrEmptyBearerToken:       "http_job.auth.bearer_token",
+	error2.ErrAMQPJobNotDefined:      "amqp_job",
+	error2.ErrAMQPConnectionInvalid:  "amqp_job.connection",
+	error2.ErrEmptyExchange:          "amqp_job.exchange",
+	error2.ErrEmptyRoutingKey:        "amqp_job.routing_key",
+	error2.ErrInvalidBodyEncoding:    "amqp_job.body_encoding",
+	error2.ErrHTTPJobBodyTooLarge:    "http_job.body",
+	error2.ErrHTTPJobHeadersTooLarge: "http_job.headers",
+	error2.ErrAMQPJobBodyTooLarge:    "amqp_job.body",
+	error2.ErrAMQPJobHeadersTooLarge: "amqp_job.headers",
+}
+
+// FieldError is the validation error of a single field of a job.
+type FieldError struct {
+	// Path of the field in the job, e.g. "http_job.url"
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// JobValidation is the outcome of validating a job without persisting it.
+//
+// swagger:model JobValidation
+type JobValidation struct {
+	Valid  bool         `json:"valid"`
+	Errors []FieldError `json:"errors"`
+	// Upcoming runs of the job, if it is valid
+	NextRuns []time.Time `json:"next_runs"`
+	// Hints about the reachability of the job target. They don't make the job invalid,
+	// since the target may only be reachable from the runners.
+	Hints []string `json:"hints"`
+}
+
+// NewJobValidation validates the job and computes its upcoming runs.
+func NewJobValidation(job *Job) *JobValidation {
+	validation := &JobValidation{Errors: job.FieldErrors(), NextRuns: []time.Time{}, Hints: []string{}}
+	validation.Valid = len(validation.Errors) == 0
+
+	if validation.Valid {
+		validation.NextRuns = job.NextRuns(validationNextRuns)
+	}
+
+	return validation
+}
+
+// NextRuns returns up to count upcoming runs of the job, starting with its next run.
+func (j *Job) NextRuns(count int) []time.Time {
+	runs := []time.Time{}
+	if !j.NextRun.Valid {
+		return runs
+	}
+
+	runs = append(runs, j.NextRun.Time)
+	if !j.CronSchedule.Valid {
+		return runs
+	}
+
+	schedule, err := ParseSchedule(j.CronSchedule.String)
+	if err != nil {
+		return runs
+	}
+
+	for next := j.NextRun.Time; len(runs) < count; {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+
+	return runs
+}
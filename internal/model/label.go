@@ -0,0 +1,247 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// labelSeparator separates the key and the value of a label in a tag, e.g. env=prod.
+const labelSeparator = "="
+
+// maxTagSelectorRequirements limits the number of requirements of a tag selector.
+const maxTagSelectorRequirements = 32
+
+var labelPartRegex = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,126}[A-Za-z0-9])?$`)
+
+// Label is the typed form of a job tag. Tags of the form key=value are labels with a value,
+// any other tag is a label with only a key.
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// ParseLabel returns the label of the tag.
+func ParseLabel(tag string) Label {
+	key, value, _ := strings.Cut(tag, labelSeparator)
+	return Label{Key: key, Value: value}
+}
+
+// String returns the tag of the label.
+func (l Label) String() string {
+	if l.Value == "" {
+		return l.Key
+	}
+	return l.Key + labelSeparator + l.Value
+}
+
+// Valid reports whether the key, and the value if any, consist of alphanumeric characters, '.', '_', '/' or '-'.
+func (l Label) Valid() bool {
+	return labelPartRegex.MatchString(l.Key) && (l.Value == "" || labelPartRegex.MatchString(l.Value))
+}
+
+// TagCount is a tag along with the number of jobs having it.
+//
+// swagger:model TagCount
+type TagCount struct {
+	Tag string `json:"tag"`
+	Label
+	Count uint64 `json:"count"`
+}
+
+// TagRename renames a tag on all the jobs of a namespace.
+//
+// swagger:model TagRename
+type TagRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Validate validates a TagRename struct.
+func (r *TagRename) Validate() error {
+	if r.From == "" || r.From == r.To || !ParseLabel(r.To).Valid() {
+		return error2.ErrInvalidTagRename
+	}
+
+	return nil
+}
+
+// swagger:model TagRenameResult
+type TagRenameResult struct {
+	// Number of jobs the tag was renamed on
+	Renamed int64 `json:"renamed"`
+}
+
+type SelectorOperator string
+
+const (
+	// SelectorOperatorEquals matches jobs with the key=value tag
+	SelectorOperatorEquals SelectorOperator = "="
+	// SelectorOperatorNotEquals matches jobs without the key=value tag, including jobs without the key
+	SelectorOperatorNotEquals SelectorOperator = "!="
+	// SelectorOperatorExists matches jobs with the key tag, or a key=value tag with any value
+	SelectorOperatorExists SelectorOperator = "exists"
+	// SelectorOperatorNotExists matches jobs with neither the key tag nor any key=value tag
+	SelectorOperatorNotExists SelectorOperator = "!exists"
+)
+
+// TagRequirement is a single condition of a tag selector.
+type TagRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	Value    string
+}
+
+// Matches reports whether the tags, or the metadata for the requirements on the metadata, satisfy the requirement.
+func (r TagRequirement) Matches(tags []string, metadata map[string]string) bool {
+	if key, ok := r.MetadataKey(); ok {
+		return r.matchesMetadata(key, metadata)
+	}
+
+	switch r.Operator {
+	case SelectorOperatorEquals:
+		return hasLabel(tags, r.Key, r.Value)
+	case SelectorOperatorNotEquals:
+		return !hasLabel(tags, r.Key, r.Value)
+	case SelectorOperatorExists:
+		return hasKey(tags, r.Key)
+	case SelectorOperatorNotExists:
+		return !hasKey(tags, r.Key)
+	}
+
+	return false
+}
+
+func (r TagRequirement) String() string {
+	switch r.Operator {
+	case SelectorOperatorExists:
+		return r.Key
+	case SelectorOperatorNotExists:
+		return "!" + r.Key
+	}
+
+	return r.Key + string(r.Operator) + r.Value
+}
+
+// TagSelector selects jobs by their tags with an expression of requirements joined by AND, e.g.
+// "env=prod AND team=payments". Requirements are either key=value, key!=value, key (the key is set,
+// with any value or none) or !key (the key is not set). Requirements on keys prefixed with metadata. apply to
+// the metadata of the jobs instead, e.g. "metadata.cost-center=research".
+type TagSelector []TagRequirement
+
+// ParseTagSelector parses a tag selector expression. An empty expression yields an empty selector,
+// which matches all jobs.
+func ParseTagSelector(expression string) (TagSelector, error) {
+	fields := strings.Fields(expression)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	selector := TagSelector{}
+	for i, field := range fields {
+		// requirements are separated by AND
+		if i%2 == 1 {
+			if !strings.EqualFold(field, "AND") {
+				return nil, error2.ErrInvalidTagSelector
+			}
+			continue
+		}
+
+		requirement, err := parseTagRequirement(field)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, requirement)
+	}
+
+	if len(fields)%2 == 0 || len(selector) > maxTagSelectorRequirements {
+		return nil, error2.ErrInvalidTagSelector
+	}
+
+	return selector, nil
+}
+
+func parseTagRequirement(field string) (TagRequirement, error) {
+	var requirement TagRequirement
+
+	switch {
+	case strings.Contains(field, "!="):
+		key, value, _ := strings.Cut(field, "!=")
+		requirement = TagRequirement{Key: key, Operator: SelectorOperatorNotEquals, Value: value}
+	case strings.Contains(field, labelSeparator):
+		key, value, _ := strings.Cut(field, labelSeparator)
+		requirement = TagRequirement{Key: key, Operator: SelectorOperatorEquals, Value: value}
+	case strings.HasPrefix(field, "!"):
+		requirement = TagRequirement{Key: field[1:], Operator: SelectorOperatorNotExists}
+	default:
+		requirement = TagRequirement{Key: field, Operator: SelectorOperatorExists}
+	}
+
+	if !labelPartRegex.MatchString(requirement.Key) {
+		return TagRequirement{}, error2.ErrInvalidTagSelector
+	}
+
+	if key, ok := requirement.MetadataKey(); ok && !labelPartRegex.MatchString(key) {
+		return TagRequirement{}, error2.ErrInvalidTagSelector
+	}
+
+	if (requirement.Operator == SelectorOperatorEquals || requirement.Operator == SelectorOperatorNotEquals) &&
+		!labelPartRegex.MatchString(requirement.Value) {
+		return TagRequirement{}, error2.ErrInvalidTagSelector
+	}
+
+	return requirement, nil
+}
+
+// Matches reports whether the tags and the metadata satisfy all requirements of the selector.
+func (s TagSelector) Matches(tags []string, metadata map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.Matches(tags, metadata) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Tags returns the tags that jobs matching the selector are known to have.
+func (s TagSelector) Tags() []string {
+	var tags []string
+	for _, requirement := range s {
+		if _, ok := requirement.MetadataKey(); !ok && requirement.Operator == SelectorOperatorEquals {
+			tags = append(tags, Label{Key: requirement.Key, Value: requirement.Value}.String())
+		}
+	}
+
+	return tags
+}
+
+func (s TagSelector) String() string {
+	requirements := make([]string, 0, len(s))
+	for _, requirement := range s {
+		requirements = append(requirements, requirement.String())
+	}
+
+	return strings.Join(requirements, " AND ")
+}
+
+func hasLabel(tags []string, key, value string) bool {
+	for _, tag := range tags {
+		if label := ParseLabel(tag); label.Key == key && label.Value == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasKey(tags []string, key string) bool {
+	for _, tag := range tags {
+		if ParseLabel(tag).Key == key {
+			return true
+		}
+	}
+
+	return false
+}
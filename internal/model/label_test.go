@@ -0,0 +1,98 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabel(t *testing.T) {
+	assert.Equal(t, Label{Key: "env", Value: "prod"}, ParseLabel("env=prod"))
+	assert.Equal(t, Label{Key: "billing"}, ParseLabel("billing"))
+	assert.Equal(t, "env=prod", ParseLabel("env=prod").String())
+	assert.Equal(t, "billing", ParseLabel("billing").String())
+}
+
+func TestParseTagSelector(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       TagSelector
+		wantErr    error
+	}{
+		{name: "empty", expression: " ", want: nil},
+		{name: "equals", expression: "env=prod", want: TagSelector{
+			{Key: "env", Operator: SelectorOperatorEquals, Value: "prod"},
+		}},
+		{name: "all operators", expression: "env=prod AND team!=payments and billing AND !canary", want: TagSelector{
+			{Key: "env", Operator: SelectorOperatorEquals, Value: "prod"},
+			{Key: "team", Operator: SelectorOperatorNotEquals, Value: "payments"},
+			{Key: "billing", Operator: SelectorOperatorExists},
+			{Key: "canary", Operator: SelectorOperatorNotExists},
+		}},
+		{name: "OR is not supported", expression: "env=prod OR env=dev", wantErr: error2.ErrInvalidTagSelector},
+		{name: "dangling AND", expression: "env=prod AND", wantErr: error2.ErrInvalidTagSelector},
+		{name: "missing AND", expression: "env=prod team=payments", wantErr: error2.ErrInvalidTagSelector},
+		{name: "empty value", expression: "env=", wantErr: error2.ErrInvalidTagSelector},
+		{name: "empty key", expression: "=prod", wantErr: error2.ErrInvalidTagSelector},
+		{name: "invalid characters", expression: "env=pr*d", wantErr: error2.ErrInvalidTagSelector},
+		{name: "missing metadata key", expression: "metadata.=prod", wantErr: error2.ErrInvalidTagSelector},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			selector, err := ParseTagSelector(tc.expression)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, selector)
+		})
+	}
+}
+
+func TestTagSelectorMatches(t *testing.T) {
+	tags := []string{"env=prod", "team=payments", "billing"}
+	metadata := map[string]string{"cost-center": "research", "runbook": "https://wiki.example.com/billing"}
+
+	tests := []struct {
+		expression string
+		matches    bool
+	}{
+		{expression: "", matches: true},
+		{expression: "env=prod AND team=payments", matches: true},
+		{expression: "env=dev", matches: false},
+		{expression: "team!=search", matches: true},
+		{expression: "team!=payments", matches: false},
+		{expression: "env AND billing", matches: true},
+		{expression: "region", matches: false},
+		{expression: "!region", matches: true},
+		{expression: "!billing", matches: false},
+		{expression: "metadata.cost-center=research AND env=prod", matches: true},
+		{expression: "metadata.cost-center!=research", matches: false},
+		{expression: "metadata.runbook AND !metadata.ticket", matches: true},
+		{expression: "metadata.env=prod", matches: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expression, func(t *testing.T) {
+			selector, err := ParseTagSelector(tc.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tc.matches, selector.Matches(tags, metadata))
+		})
+	}
+}
+
+func TestTagSelectorString(t *testing.T) {
+	selector, err := ParseTagSelector("env=prod and team!=payments AND  billing AND !canary")
+	require.NoError(t, err)
+
+	assert.Equal(t, "env=prod AND team!=payments AND billing AND !canary", selector.String())
+	assert.Equal(t, []string{"env=prod"}, selector.Tags())
+}
+
+func TestTagRenameValidate(t *testing.T) {
+	assert.NoError(t, (&TagRename{From: "prod", To: "env=prod"}).Validate())
+	assert.Equal(t, error2.ErrInvalidTagRename, (&TagRename{To: "env=prod"}).Validate())
+	assert.Equal(t, error2.ErrInvalidTagRename, (&TagRename{From: "prod", To: "prod"}).Validate())
+	assert.Equal(t, error2.ErrInvalidTagRename, (&TagRename{From: "prod", To: "env prod"}).Validate())
+}
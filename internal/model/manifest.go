@@ -0,0 +1,209 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxManifestJobs is the maximum number of jobs a manifest can declare.
+const MaxManifestJobs = 1000
+
+// JobManifest declares the named jobs of a namespace, so job definitions can be kept in version control.
+// Applying a manifest creates, updates and deletes jobs by name until the namespace matches it. Jobs with an
+// external ID are matched by their external ID first, so they can be renamed.
+//
+// swagger:model JobManifest
+type JobManifest struct {
+	Jobs []JobCreate `json:"jobs"`
+}
+
+// ParseJobManifest parses a YAML or JSON manifest. Since JSON is valid YAML, both are parsed the same way.
+// The document is converted to JSON before being decoded, so manifests use the same fields as the API.
+func ParseJobManifest(data []byte) (*JobManifest, error) {
+	encoded, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &JobManifest{}
+	if err := json.Unmarshal(encoded, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// ParseJobDefinition parses a single YAML or JSON job definition, with the fields of a job create request. Unlike
+// manifests, unknown fields are rejected, so a misspelt field isn't silently ignored.
+func ParseJobDefinition(data []byte) (*JobCreate, error) {
+	encoded, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+
+	definition := &JobCreate{}
+	if err := decoder.Decode(definition); err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// yamlToJSON converts a YAML document to JSON, so it is decoded with the JSON fields of the API.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(document)
+}
+
+// YAML encodes the manifest as YAML, with the same fields as its JSON encoding.
+func (m *JobManifest) YAML() ([]byte, error) {
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(document)
+}
+
+// Validate validates a JobManifest struct. The jobs themselves are validated when they are applied.
+func (m *JobManifest) Validate() error {
+	if len(m.Jobs) > MaxManifestJobs {
+		return error2.ErrInvalidManifest
+	}
+
+	names := make(map[string]bool, len(m.Jobs))
+	externalIDs := make(map[string]bool, len(m.Jobs))
+	for _, job := range m.Jobs {
+		if !job.Name.Valid || names[job.Name.String] {
+			return error2.ErrInvalidManifest
+		}
+		names[job.Name.String] = true
+
+		if job.ExternalID.Valid {
+			if externalIDs[job.ExternalID.String] {
+				return error2.ErrInvalidManifest
+			}
+			externalIDs[job.ExternalID.String] = true
+		}
+	}
+
+	return nil
+}
+
+// ManifestApplyOptions change how a manifest is applied, e.g. to import the jobs exported from another environment
+// without deleting the other jobs of the namespace or the credentials of the updated jobs.
+type ManifestApplyOptions struct {
+	// KeepMissing leaves the named jobs missing from the manifest alone instead of deleting them
+	KeepMissing bool
+	// KeepCredentials keeps the credentials of the updated jobs which the manifest leaves out, like exported manifests
+	KeepCredentials bool
+}
+
+// ManifestApplyResult lists the names of the jobs affected by applying a manifest.
+//
+// swagger:model ManifestApplyResult
+type ManifestApplyResult struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Deleted   []string `json:"deleted"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// ToManifest returns the definition of the job, as declared in a manifest.
+func (j *Job) ToManifest() JobCreate {
+	return JobCreate{
+		Name:         j.Name,
+		ExternalID:   j.ExternalID,
+		Owner:        j.Owner,
+		Team:         j.Team,
+		Type:         j.Type,
+		ExecuteAt:    j.ExecuteAt,
+		CronSchedule: j.CronSchedule,
+		HTTPJob:      j.HTTPJob,
+		AMQPJob:      j.AMQPJob,
+		CustomJob:    j.CustomJob,
+		Tags:         j.Tags,
+		Metadata:     j.Metadata,
+		Priority:     j.Priority,
+		TTL:          j.TTL,
+
+		RequiredCapabilities: j.RequiredCapabilities,
+		ExecutionTimeout:     j.ExecutionTimeout,
+		MisfirePolicy:        j.MisfirePolicy,
+		SLA:                  j.SLA,
+		ResultSink:           j.ResultSink,
+		MetricLabels:         j.MetricLabels,
+	}
+}
+
+// ApplyManifest replaces the definition of the job with the one declared in a manifest. Like the name, the external
+// ID, owner and team of the job are not part of its definition, and are left unchanged.
+func (j *Job) ApplyManifest(definition JobCreate) {
+	j.Type = definition.Type
+	j.ExecuteAt = definition.ExecuteAt
+	j.CronSchedule = definition.CronSchedule
+	j.HTTPJob = definition.HTTPJob
+	j.AMQPJob = definition.AMQPJob
+	j.CustomJob = definition.CustomJob
+	j.Tags = definition.Tags
+	j.Metadata = definition.Metadata
+	j.Priority = definition.Priority.OrDefault()
+	j.RequiredCapabilities = definition.RequiredCapabilities
+	j.ExecutionTimeout = definition.ExecutionTimeout
+	j.MisfirePolicy = definition.MisfirePolicy.OrDefault()
+	j.SLA = definition.SLA
+	j.ResultSink = definition.ResultSink
+	j.MetricLabels = definition.MetricLabels
+	j.TTL = definition.TTL
+	j.UpdatedAt = time.Now()
+
+	j.SetInitialRunTime()
+}
+
+// SameDefinition reports whether both job definitions are equivalent, ignoring the time zone and
+// sub-microsecond precision of execute_at, the difference between missing and empty tags, metadata or required
+// capabilities, and between a missing and the default priority or misfire policy.
+func SameDefinition(a, b JobCreate) bool {
+	normalize := func(definition JobCreate) ([]byte, error) {
+		if definition.ExecuteAt.Valid {
+			definition.ExecuteAt = null.TimeFrom(definition.ExecuteAt.Time.UTC().Truncate(time.Microsecond))
+		}
+		if len(definition.Tags) == 0 {
+			definition.Tags = nil
+		}
+		if len(definition.Metadata) == 0 {
+			definition.Metadata = nil
+		}
+		if len(definition.RequiredCapabilities) == 0 {
+			definition.RequiredCapabilities = nil
+		}
+		if len(definition.MetricLabels) == 0 {
+			definition.MetricLabels = nil
+		}
+		definition.Priority = definition.Priority.OrDefault()
+		definition.MisfirePolicy = definition.MisfirePolicy.OrDefault()
+		return json.Marshal(definition)
+	}
+
+	encodedA, errA := normalize(a)
+	encodedB, errB := normalize(b)
+
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
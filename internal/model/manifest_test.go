@@ -0,0 +1,128 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+const yamlManifest = `
+jobs:
+  - name: nightly-report
+    type: HTTP
+    cron_schedule: "0 2 * * *"
+    http_job:
+      url: https://example.com/report
+      method: POST
+      auth:
+        type: none
+    tags: [reports]
+  - name: reminder
+    type: HTTP
+    execute_at: 2030-01-02T03:04:05Z
+    http_job:
+      url: https://example.com/remind
+      method: GET
+      auth:
+        type: none
+`
+
+func TestParseJobManifest(t *testing.T) {
+	manifest, err := ParseJobManifest([]byte(yamlManifest))
+	require.NoError(t, err)
+	require.Len(t, manifest.Jobs, 2)
+
+	assert.Equal(t, "nightly-report", manifest.Jobs[0].Name.String)
+	assert.Equal(t, "0 2 * * *", manifest.Jobs[0].CronSchedule.String)
+	assert.Equal(t, "https://example.com/report", manifest.Jobs[0].HTTPJob.URL)
+	assert.Equal(t, []string{"reports"}, manifest.Jobs[0].Tags)
+	assert.True(t, manifest.Jobs[1].ExecuteAt.Time.Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	t.Run("Round trip", func(t *testing.T) {
+		data, err := manifest.YAML()
+		require.NoError(t, err)
+
+		parsed, err := ParseJobManifest(data)
+		require.NoError(t, err)
+		for i := range manifest.Jobs {
+			assert.True(t, SameDefinition(manifest.Jobs[i], parsed.Jobs[i]))
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		parsed, err := ParseJobManifest([]byte(`{"jobs": [{"name": "a", "type": "AMQP"}]}`))
+		require.NoError(t, err)
+		assert.Equal(t, JobTypeAMQP, parsed.Jobs[0].Type)
+	})
+}
+
+func TestParseJobDefinition(t *testing.T) {
+	definition, err := ParseJobDefinition([]byte(`
+type: HTTP
+http_job:
+  url: https://example.com/report
+  method: POST
+  auth:
+    type: none
+execution_timeout: 30
+`))
+	require.NoError(t, err)
+	assert.Equal(t, JobTypeHTTP, definition.Type)
+	assert.Equal(t, "https://example.com/report", definition.HTTPJob.URL)
+	assert.Equal(t, int64(30), definition.ExecutionTimeout.Int64)
+
+	_, err = ParseJobDefinition([]byte("type: HTTP\nhttp_jobs: {}\n"))
+	assert.Error(t, err)
+}
+
+func TestJobManifestValidate(t *testing.T) {
+	named := func(names ...string) *JobManifest {
+		manifest := &JobManifest{}
+		for _, name := range names {
+			manifest.Jobs = append(manifest.Jobs, JobCreate{Name: null.StringFrom(name)})
+		}
+		return manifest
+	}
+
+	assert.NoError(t, (&JobManifest{}).Validate())
+	assert.NoError(t, named("a", "b").Validate())
+	assert.Equal(t, error2.ErrInvalidManifest, named("a", "a").Validate())
+	assert.Equal(t, error2.ErrInvalidManifest, (&JobManifest{Jobs: []JobCreate{{}}}).Validate())
+
+	// external IDs are unique as well
+	manifest := named("a", "b")
+	manifest.Jobs[0].ExternalID = null.StringFrom("stack:report")
+	assert.NoError(t, manifest.Validate())
+	manifest.Jobs[1].ExternalID = null.StringFrom("stack:report")
+	assert.Equal(t, error2.ErrInvalidManifest, manifest.Validate())
+}
+
+func TestSameDefinition(t *testing.T) {
+	at := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	definition := JobCreate{Name: null.StringFrom("a"), Type: JobTypeHTTP, ExecuteAt: null.TimeFrom(at)}
+
+	other := definition
+	other.ExecuteAt = null.TimeFrom(at.In(time.FixedZone("UTC+2", 2*60*60)))
+	other.Tags = []string{}
+	other.RequiredCapabilities = []string{}
+	assert.True(t, SameDefinition(definition, other))
+
+	// a missing priority is the default one
+	other.Priority = JobPriorityNormal
+	assert.True(t, SameDefinition(definition, other))
+
+	// a missing misfire policy is the default one
+	other.MisfirePolicy = MisfirePolicyRunOnce
+	assert.True(t, SameDefinition(definition, other))
+
+	other.Tags = []string{"billing"}
+	assert.False(t, SameDefinition(definition, other))
+
+	other.Tags = nil
+	other.RequiredCapabilities = []string{"region=eu"}
+	assert.False(t, SameDefinition(definition, other))
+}
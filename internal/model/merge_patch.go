@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// MergePatchContentType is the media type of JSON merge patches.
+const MergePatchContentType = "application/merge-patch+json"
+
+// ApplyMergePatch applies a JSON merge patch (RFC 7386) to the job definition: fields set to null are
+// removed, objects are merged recursively, and any other value replaces the current one.
+func ApplyMergePatch(definition JobCreate, patch []byte) (JobCreate, error) {
+	var patchDocument map[string]interface{}
+	if err := json.Unmarshal(patch, &patchDocument); err != nil || patchDocument == nil {
+		return JobCreate{}, error2.ErrInvalidMergePatch
+	}
+
+	encoded, err := json.Marshal(definition)
+	if err != nil {
+		return JobCreate{}, err
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(encoded, &document); err != nil {
+		return JobCreate{}, err
+	}
+
+	patched, err := json.Marshal(mergePatch(document, patchDocument))
+	if err != nil {
+		return JobCreate{}, err
+	}
+
+	result := JobCreate{}
+	if err := json.Unmarshal(patched, &result); err != nil {
+		return JobCreate{}, error2.ErrInvalidMergePatch
+	}
+
+	return result, nil
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	definition := JobCreate{
+		Name:         null.StringFrom("report"),
+		Type:         JobTypeHTTP,
+		CronSchedule: null.StringFrom("0 2 * * *"),
+		HTTPJob: &HTTPJob{
+			URL:     "https://example.com",
+			Method:  "POST",
+			Headers: map[string]string{"Content-Type": "application/json", "X-Trace": "1"},
+			Auth:    Auth{Type: AuthTypeNone},
+		},
+		Tags: []string{"reports"},
+	}
+
+	t.Run("Clear the cron schedule", func(t *testing.T) {
+		patched, err := ApplyMergePatch(definition, []byte(`{"cron_schedule": null, "execute_at": "2030-01-02T03:04:05Z"}`))
+		require.NoError(t, err)
+
+		assert.False(t, patched.CronSchedule.Valid)
+		assert.Equal(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), patched.ExecuteAt.Time.UTC())
+		assert.Equal(t, definition.HTTPJob, patched.HTTPJob)
+		assert.Equal(t, definition.Tags, patched.Tags)
+	})
+
+	t.Run("Merge nested objects", func(t *testing.T) {
+		patched, err := ApplyMergePatch(definition, []byte(`{"http_job": {"method": "GET", "headers": {"X-Trace": null}}}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "GET", patched.HTTPJob.Method)
+		assert.Equal(t, "https://example.com", patched.HTTPJob.URL)
+		assert.Equal(t, map[string]string{"Content-Type": "application/json"}, patched.HTTPJob.Headers)
+		// the original definition is left untouched
+		assert.Len(t, definition.HTTPJob.Headers, 2)
+	})
+
+	t.Run("Replace arrays", func(t *testing.T) {
+		patched, err := ApplyMergePatch(definition, []byte(`{"tags": ["a", "b"]}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, patched.Tags)
+	})
+
+	t.Run("Invalid patch", func(t *testing.T) {
+		for _, patch := range []string{`[]`, `null`, `"cron_schedule"`, `{`, `{"tags": "a"}`} {
+			_, err := ApplyMergePatch(definition, []byte(patch))
+			assert.ErrorIs(t, err, error2.ErrInvalidMergePatch, patch)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"strings"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// MetadataSelectorPrefix prefixes the keys of the selector requirements on the metadata of the jobs instead of their
+// tags, e.g. metadata.cost-center=research.
+const MetadataSelectorPrefix = "metadata."
+
+const (
+	// maxMetadataEntries limits the number of metadata entries of a job.
+	maxMetadataEntries = 64
+	// maxMetadataValueLength limits the length of a metadata value in bytes, enough for links and ticket IDs.
+	maxMetadataValueLength = 1024
+)
+
+// ValidateMetadata validates the metadata of a job: at most 64 entries, whose keys have the format of the keys of
+// labels so they can be selected, and whose values are at most 1024 bytes long.
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > maxMetadataEntries {
+		return error2.ErrInvalidJobMetadata
+	}
+
+	for key, value := range metadata {
+		if !labelPartRegex.MatchString(key) || len(value) > maxMetadataValueLength {
+			return error2.ErrInvalidJobMetadata
+		}
+	}
+
+	return nil
+}
+
+// MetadataKey returns the metadata key the requirement is about, if it selects the metadata of the jobs.
+func (r TagRequirement) MetadataKey() (string, bool) {
+	return strings.CutPrefix(r.Key, MetadataSelectorPrefix)
+}
+
+// matchesMetadata reports whether the metadata satisfies the requirement on the metadata key.
+func (r TagRequirement) matchesMetadata(key string, metadata map[string]string) bool {
+	value, ok := metadata[key]
+
+	switch r.Operator {
+	case SelectorOperatorEquals:
+		return ok && value == r.Value
+	case SelectorOperatorNotEquals:
+		return !ok || value != r.Value
+	case SelectorOperatorExists:
+		return ok
+	case SelectorOperatorNotExists:
+		return !ok
+	}
+
+	return false
+}
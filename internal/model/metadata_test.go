@@ -0,0 +1,43 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i <= maxMetadataEntries; i++ {
+		tooMany[fmt.Sprintf("key-%d", i)] = "value"
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     error
+	}{
+		{name: "no metadata", metadata: nil, want: nil},
+		{name: "valid", metadata: map[string]string{"runbook": "https://wiki.example.com/runbooks/billing#restart", "ticket": "OPS-1234"}, want: nil},
+		{name: "empty value", metadata: map[string]string{"ticket": ""}, want: nil},
+		{name: "invalid key", metadata: map[string]string{"cost center": "research"}, want: error2.ErrInvalidJobMetadata},
+		{name: "empty key", metadata: map[string]string{"": "research"}, want: error2.ErrInvalidJobMetadata},
+		{name: "value too long", metadata: map[string]string{"notes": strings.Repeat("a", maxMetadataValueLength+1)}, want: error2.ErrInvalidJobMetadata},
+		{name: "too many entries", metadata: tooMany, want: error2.ErrInvalidJobMetadata},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ValidateMetadata(tc.metadata))
+		})
+	}
+}
+
+func TestTagSelectorTagsIgnoresMetadata(t *testing.T) {
+	selector, err := ParseTagSelector("env=prod AND metadata.cost-center=research")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"env=prod"}, selector.Tags())
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// maxMetricLabels limits the number of metric labels a job opts into.
+const maxMetricLabels = 8
+
+const (
+	// MetricLabelJobID labels the execution metrics of the job with its ID
+	MetricLabelJobID = "job_id"
+	// MetricLabelJobName labels the execution metrics of the job with its name
+	MetricLabelJobName = "job_name"
+	// MetricLabelTagPrefix prefixes the key of a tag labelling the execution metrics of the job, e.g. tag:team
+	MetricLabelTagPrefix = "tag:"
+)
+
+// metricLabelNameRegex replaces the characters of tag keys that are not allowed in metric label names.
+var metricLabelNameRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// MetricLabel is a label added to the execution metrics of a job that opted into it.
+type MetricLabel struct {
+	Name  string
+	Value string
+}
+
+// ValidateMetricLabels validates the metric labels a job opts into: job_id, job_name or tag:<key>, at most once each.
+func ValidateMetricLabels(labels []string) error {
+	if len(labels) > maxMetricLabels {
+		return error2.ErrInvalidMetricLabels
+	}
+
+	for i, label := range labels {
+		if slices.Contains(labels[:i], label) {
+			return error2.ErrInvalidMetricLabels
+		}
+
+		if label == MetricLabelJobID || label == MetricLabelJobName {
+			continue
+		}
+
+		key, ok := strings.CutPrefix(label, MetricLabelTagPrefix)
+		if !ok || !labelPartRegex.MatchString(key) {
+			return error2.ErrInvalidMetricLabels
+		}
+	}
+
+	return nil
+}
+
+// MetricLabelValues returns the metric labels the job opted into, with their value. A tag key labels the metrics as
+// tag_<key>, with the characters other than alphanumerics and '_' replaced by '_', and the value of the tag of the job
+// with the key, empty if the job doesn't have it.
+func (j *Job) MetricLabelValues() []MetricLabel {
+	labels := make([]MetricLabel, 0, len(j.MetricLabels))
+	for _, label := range j.MetricLabels {
+		switch label {
+		case MetricLabelJobID:
+			labels = append(labels, MetricLabel{Name: MetricLabelJobID, Value: j.ID.String()})
+		case MetricLabelJobName:
+			labels = append(labels, MetricLabel{Name: MetricLabelJobName, Value: j.Name.String})
+		default:
+			key := strings.TrimPrefix(label, MetricLabelTagPrefix)
+			metricLabel := MetricLabel{Name: "tag_" + metricLabelNameRegex.ReplaceAllString(key, "_")}
+			for _, tag := range j.Tags {
+				if tagLabel := ParseLabel(tag); tagLabel.Key == key {
+					metricLabel.Value = tagLabel.Value
+					break
+				}
+			}
+			labels = append(labels, metricLabel)
+		}
+	}
+
+	return labels
+}
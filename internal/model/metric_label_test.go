@@ -0,0 +1,51 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestValidateMetricLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		want   error
+	}{
+		{name: "no labels", labels: nil, want: nil},
+		{name: "job and tags", labels: []string{"job_id", "job_name", "tag:team", "tag:app.kubernetes.io/name"}, want: nil},
+		{name: "unknown label", labels: []string{"namespace"}, want: error2.ErrInvalidMetricLabels},
+		{name: "empty tag key", labels: []string{"tag:"}, want: error2.ErrInvalidMetricLabels},
+		{name: "duplicate label", labels: []string{"job_id", "job_id"}, want: error2.ErrInvalidMetricLabels},
+		{name: "too many labels", labels: []string{"job_id", "tag:a", "tag:b", "tag:c", "tag:d", "tag:e", "tag:f", "tag:g", "tag:h"},
+			want: error2.ErrInvalidMetricLabels},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ValidateMetricLabels(tc.labels))
+		})
+	}
+}
+
+func TestJobMetricLabelValues(t *testing.T) {
+	job := &Job{
+		ID:           uuid.New(),
+		Name:         null.StringFrom("nightly-report"),
+		Tags:         []string{"team=payments", "app.kubernetes.io/name=reports", "critical"},
+		MetricLabels: []string{"job_id", "job_name", "tag:team", "tag:app.kubernetes.io/name", "tag:env"},
+	}
+
+	assert.Equal(t, []MetricLabel{
+		{Name: "job_id", Value: job.ID.String()},
+		{Name: "job_name", Value: "nightly-report"},
+		{Name: "tag_team", Value: "payments"},
+		{Name: "tag_app_kubernetes_io_name", Value: "reports"},
+		{Name: "tag_env", Value: ""},
+	}, job.MetricLabelValues())
+
+	assert.Empty(t, (&Job{Tags: []string{"team=payments"}}).MetricLabelValues())
+}
@@ -0,0 +1,31 @@
+package model
+
+// MisfirePolicy decides what happens to an occurrence of a job that was missed because its execution was abandoned,
+// e.g. because the runner executing it crashed.
+type MisfirePolicy string
+
+const (
+	// MisfirePolicyRunOnce runs the missed occurrence once more, as soon as possible
+	MisfirePolicyRunOnce MisfirePolicy = "RUN_ONCE"
+	// MisfirePolicySkip skips the missed occurrence: recurring jobs wait for their next occurrence, and one-off
+	// jobs are completed
+	MisfirePolicySkip MisfirePolicy = "SKIP"
+)
+
+func (p MisfirePolicy) Valid() bool {
+	switch p {
+	case MisfirePolicyRunOnce, MisfirePolicySkip:
+		return true
+	default:
+		return false
+	}
+}
+
+// OrDefault returns the misfire policy, or RUN_ONCE if it is not set.
+func (p MisfirePolicy) OrDefault() MisfirePolicy {
+	if p == "" {
+		return MisfirePolicyRunOnce
+	}
+
+	return p
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMisfirePolicy(t *testing.T) {
+	assert.True(t, MisfirePolicyRunOnce.Valid())
+	assert.True(t, MisfirePolicySkip.Valid())
+	assert.False(t, MisfirePolicy("").Valid())
+	assert.False(t, MisfirePolicy("RETRY").Valid())
+
+	assert.Equal(t, MisfirePolicyRunOnce, MisfirePolicy("").OrDefault())
+	assert.Equal(t, MisfirePolicySkip, MisfirePolicySkip.OrDefault())
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// DefaultNamespace is the namespace of requests that don't specify one.
+const DefaultNamespace = "default"
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type namespaceKey struct{}
+
+// WithNamespace returns a context scoping job service calls to the namespace.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace of the context, or the default namespace if it has none.
+func NamespaceFromContext(ctx context.Context) string {
+	if namespace, ok := ctx.Value(namespaceKey{}).(string); ok && namespace != "" {
+		return namespace
+	}
+
+	return DefaultNamespace
+}
+
+// ValidateNamespace validates a namespace name: lowercase alphanumeric characters or '-', at most 63 characters.
+func ValidateNamespace(namespace string) error {
+	if !namespacePattern.MatchString(namespace) {
+		return error2.ErrInvalidNamespace
+	}
+
+	return nil
+}
+
+// Namespace isolates the jobs of a tenant. Namespaces exist implicitly, they are only stored to set their quotas.
+//
+// swagger:model Namespace
+type Namespace struct {
+	Name string `json:"name"`
+
+	// Maximum number of jobs (not counting archived jobs), unlimited if null
+	MaxJobs null.Int `json:"max_jobs" swaggertype:"integer"`
+	// Maximum number of jobs executed at the same time, unlimited if null
+	MaxConcurrentExecutions null.Int `json:"max_concurrent_executions" swaggertype:"integer"`
+
+	// Number of jobs in the namespace, not counting archived jobs
+	Jobs uint64 `json:"jobs"`
+
+	CreatedAt null.Time `json:"created_at,omitempty" swaggertype:"string"`
+	UpdatedAt null.Time `json:"updated_at,omitempty" swaggertype:"string"`
+}
+
+// swagger:model NamespaceQuotas
+type NamespaceQuotas struct {
+	MaxJobs                 null.Int `json:"max_jobs" swaggertype:"integer"`
+	MaxConcurrentExecutions null.Int `json:"max_concurrent_executions" swaggertype:"integer"`
+}
+
+// NamespaceKey is a version of the data key encrypting the credentials of a namespace. The key itself is never
+// returned.
+//
+// swagger:model NamespaceKey
+type NamespaceKey struct {
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	// Whether the key encrypts the new credentials, the other versions only decrypting the former ones
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate validates a NamespaceQuotas struct.
+func (q *NamespaceQuotas) Validate() error {
+	if (q.MaxJobs.Valid && q.MaxJobs.Int64 < 0) || (q.MaxConcurrentExecutions.Valid && q.MaxConcurrentExecutions.Int64 < 0) {
+		return error2.ErrInvalidNamespaceQuotas
+	}
+
+	return nil
+}
+
+// AllowsJobs reports whether the given number of jobs can be added to the namespace.
+func (n *Namespace) AllowsJobs(count int) bool {
+	return !n.MaxJobs.Valid || n.Jobs+uint64(count) <= uint64(n.MaxJobs.Int64)
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+	"encoding/json"
+	"net/mail"
+	"net/url"
+	"slices"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// defaultNotificationThrottle is the throttle window of the rules created without one.
+const defaultNotificationThrottle = 3600
+
+// NotificationChannel is where the notifications of a rule are sent.
+type NotificationChannel string
+
+const (
+	// NotificationChannelSlack posts the notifications to a Slack incoming webhook
+	NotificationChannelSlack NotificationChannel = "SLACK"
+	// NotificationChannelEmail mails the notifications to the recipients, through the configured SMTP server
+	NotificationChannelEmail NotificationChannel = "EMAIL"
+	// NotificationChannelWebhook posts the notifications as JSON to a URL
+	NotificationChannelWebhook NotificationChannel = "WEBHOOK"
+)
+
+// NotificationTrigger is what a notification rule fires on.
+type NotificationTrigger string
+
+const (
+	// NotificationTriggerFailure fires when an execution of the job fails or times out
+	NotificationTriggerFailure NotificationTrigger = "FAILURE"
+	// NotificationTriggerRecovery fires when an execution of a job succeeds after its failure was notified
+	NotificationTriggerRecovery NotificationTrigger = "RECOVERY"
+	// NotificationTriggerDisabled fires when the job is stopped after failing too many times in a row
+	NotificationTriggerDisabled NotificationTrigger = "DISABLED"
+)
+
+func (t NotificationTrigger) Valid() bool {
+	switch t {
+	case NotificationTriggerFailure, NotificationTriggerRecovery, NotificationTriggerDisabled:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationTriggerOf returns the trigger of a job lifecycle event, if any.
+func NotificationTriggerOf(event Event) (NotificationTrigger, bool) {
+	switch event.Type {
+	case EventExecutionFailed:
+		return NotificationTriggerFailure, true
+	case EventExecutionFinished:
+		return NotificationTriggerRecovery, true
+	case EventJobDisabled:
+		return NotificationTriggerDisabled, true
+	default:
+		return "", false
+	}
+}
+
+// NotificationRule notifies people of the failures of a job, or of the jobs with some tags, on Slack, by email or
+// through a webhook. The notifications of each job are throttled, so a flapping job doesn't notify on every run.
+//
+// swagger:model NotificationRule
+type NotificationRule struct {
+	ID uuid.UUID `json:"id"`
+	// Only jobs in the namespace of the rule are notified of
+	Namespace string `json:"namespace"`
+	// Only jobs of the team are notified of, if set. Set to the team of the API key bound to a team that created the
+	// rule.
+	Team    null.String         `json:"team,omitempty" swaggertype:"string"`
+	Name    string              `json:"name"`
+	Channel NotificationChannel `json:"channel"`
+	// URL of the Slack incoming webhook or of the webhook
+	URL string `json:"url,omitempty"`
+	// Recipients of the emails
+	Recipients []string `json:"recipients,omitempty"`
+
+	// Triggers the rule fires on
+	Triggers []NotificationTrigger `json:"triggers"`
+	// Only the job with the ID is notified of, if set
+	JobID *uuid.UUID `json:"job_id,omitempty"`
+	// Only jobs with all of the tags are notified of
+	Tags []string `json:"tags"`
+	// Only jobs matching the tag selector expression are notified of, e.g. "env=prod AND team=payments"
+	Selector string `json:"selector,omitempty"`
+	// Throttle is the minimum number of seconds between two failure notifications of a job, the failures in between
+	// are counted and reported with the next notification
+	Throttle int `json:"throttle"`
+
+	Enabled bool `json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model NotificationRuleCreate
+type NotificationRuleCreate struct {
+	Name       string                `json:"name"`
+	Channel    NotificationChannel   `json:"channel"`
+	URL        string                `json:"url,omitempty"`
+	Recipients []string              `json:"recipients,omitempty"`
+	Triggers   []NotificationTrigger `json:"triggers"`
+	JobID      *uuid.UUID            `json:"job_id,omitempty"`
+	Tags       []string              `json:"tags"`
+	Selector   string                `json:"selector,omitempty"`
+	// Throttle in seconds, 1 hour if unset
+	Throttle *int `json:"throttle,omitempty"`
+}
+
+func (r *NotificationRuleCreate) ToNotificationRule() *NotificationRule {
+	now := time.Now()
+
+	rule := &NotificationRule{
+		ID:         uuid.New(),
+		Namespace:  DefaultNamespace,
+		Name:       r.Name,
+		Channel:    r.Channel,
+		URL:        r.URL,
+		Recipients: r.Recipients,
+		Triggers:   r.Triggers,
+		JobID:      r.JobID,
+		Tags:       r.Tags,
+		Selector:   r.Selector,
+		Throttle:   defaultNotificationThrottle,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if r.Throttle != nil {
+		rule.Throttle = *r.Throttle
+	}
+
+	if rule.Recipients == nil {
+		rule.Recipients = []string{}
+	}
+
+	if rule.Triggers == nil {
+		rule.Triggers = []NotificationTrigger{NotificationTriggerFailure, NotificationTriggerRecovery, NotificationTriggerDisabled}
+	}
+
+	if rule.Tags == nil {
+		rule.Tags = []string{}
+	}
+
+	return rule
+}
+
+// Validate validates a NotificationRule struct.
+func (r *NotificationRule) Validate() error {
+	switch r.Channel {
+	case NotificationChannelSlack, NotificationChannelWebhook:
+		u, err := url.Parse(r.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return error2.ErrInvalidNotificationRule
+		}
+	case NotificationChannelEmail:
+		if len(r.Recipients) == 0 {
+			return error2.ErrInvalidNotificationRule
+		}
+		for _, recipient := range r.Recipients {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return error2.ErrInvalidNotificationRule
+			}
+		}
+	default:
+		return error2.ErrInvalidNotificationRule
+	}
+
+	if len(r.Triggers) == 0 || r.Throttle < 0 {
+		return error2.ErrInvalidNotificationRule
+	}
+
+	for _, trigger := range r.Triggers {
+		if !trigger.Valid() {
+			return error2.ErrInvalidNotificationRule
+		}
+	}
+
+	// recoveries are only notified after failures
+	if r.HasTrigger(NotificationTriggerRecovery) && !r.HasTrigger(NotificationTriggerFailure) {
+		return error2.ErrInvalidNotificationRule
+	}
+
+	if _, err := ParseTagSelector(r.Selector); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// HasTrigger reports whether the rule fires on the trigger.
+func (r *NotificationRule) HasTrigger(trigger NotificationTrigger) bool {
+	return slices.Contains(r.Triggers, trigger)
+}
+
+// Matches reports whether the event is for a job the rule notifies of. Events of bulk operations only carry the tags
+// the jobs were selected by.
+func (r *NotificationRule) Matches(event Event) bool {
+	if r.Namespace != event.Namespace || (r.JobID != nil && *r.JobID != event.JobID) {
+		return false
+	}
+
+	for _, tag := range r.Tags {
+		if !slices.Contains(event.Tags, tag) {
+			return false
+		}
+	}
+
+	selector, err := ParseTagSelector(r.Selector)
+	return err == nil && selector.Matches(event.Tags, event.Metadata)
+}
+
+// NotificationState is the state of the notifications of a job by a rule, used to throttle them.
+type NotificationState struct {
+	RuleID uuid.UUID
+	JobID  uuid.UUID
+	// Alerting is set once a failure of the job is notified, until its recovery is
+	Alerting bool
+	// LastNotifiedAt is when the last notification of the job was sent
+	LastNotifiedAt null.Time
+	// Suppressed is the number of failures not notified since the last notification
+	Suppressed int
+}
+
+// Record records the trigger at the given time and reports whether to notify of it, with the number of failures
+// suppressed since the last notification. Failures are notified at most once per throttle window, and a recovery
+// only if the failure was notified, so a flapping job notifies at most of a failure and a recovery per window.
+// Disabled jobs are always notified.
+func (s *NotificationState) Record(trigger NotificationTrigger, at time.Time, throttle time.Duration) (bool, int) {
+	switch trigger {
+	case NotificationTriggerFailure:
+		if s.LastNotifiedAt.Valid && at.Before(s.LastNotifiedAt.Time.Add(throttle)) {
+			s.Suppressed++
+			return false, 0
+		}
+		s.Alerting = true
+	case NotificationTriggerRecovery:
+		if !s.Alerting {
+			return false, 0
+		}
+		s.Alerting = false
+	}
+
+	suppressed := s.Suppressed
+	s.Suppressed = 0
+	s.LastNotifiedAt = null.TimeFrom(at)
+
+	return true, suppressed
+}
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "PENDING"
+	NotificationStatusSent    NotificationStatus = "SENT"
+	NotificationStatusFailed  NotificationStatus = "FAILED"
+)
+
+// Notification is a notification of a rule, sent with retries.
+type Notification struct {
+	ID      int                 `json:"id"`
+	RuleID  uuid.UUID           `json:"rule_id"`
+	JobID   uuid.UUID           `json:"job_id"`
+	Trigger NotificationTrigger `json:"trigger"`
+	// Event notified of
+	Event json.RawMessage `json:"event" swaggertype:"object"`
+	// Suppressed is the number of failures of the job not notified since the previous notification
+	Suppressed int                `json:"suppressed"`
+	Status     NotificationStatus `json:"status"`
+	Attempts   int                `json:"attempts"`
+
+	// Error of the last attempt
+	Error null.String `json:"error,omitempty" swaggertype:"string"`
+
+	// When the next attempt is made, for pending notifications
+	NextAttemptAt null.Time `json:"next_attempt_at,omitempty" swaggertype:"string"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model NotificationPage
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Pagination
+}
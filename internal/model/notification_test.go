@@ -0,0 +1,164 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRuleValidate(t *testing.T) {
+	negative := -1
+
+	tests := []struct {
+		name string
+		rule NotificationRuleCreate
+		want error
+	}{
+		{name: "valid slack rule", rule: NotificationRuleCreate{Channel: NotificationChannelSlack, URL: "https://hooks.slack.com/services/T0/B0/X"}, want: nil},
+		{name: "valid webhook rule", rule: NotificationRuleCreate{
+			Channel:  NotificationChannelWebhook,
+			URL:      "http://example.com/alerts",
+			Triggers: []NotificationTrigger{NotificationTriggerDisabled},
+		}, want: nil},
+		{name: "valid email rule", rule: NotificationRuleCreate{
+			Channel:    NotificationChannelEmail,
+			Recipients: []string{"oncall@example.com", "Payments <payments@example.com>"},
+		}, want: nil},
+		{name: "unknown channel", rule: NotificationRuleCreate{Channel: "PAGER", URL: "https://example.com"}, want: error2.ErrInvalidNotificationRule},
+		{name: "relative URL", rule: NotificationRuleCreate{Channel: NotificationChannelSlack, URL: "/alerts"}, want: error2.ErrInvalidNotificationRule},
+		{name: "no recipients", rule: NotificationRuleCreate{Channel: NotificationChannelEmail}, want: error2.ErrInvalidNotificationRule},
+		{name: "invalid recipient", rule: NotificationRuleCreate{
+			Channel:    NotificationChannelEmail,
+			Recipients: []string{"oncall"},
+		}, want: error2.ErrInvalidNotificationRule},
+		{name: "invalid trigger", rule: NotificationRuleCreate{
+			Channel:  NotificationChannelWebhook,
+			URL:      "https://example.com",
+			Triggers: []NotificationTrigger{"EXPLODED"},
+		}, want: error2.ErrInvalidNotificationRule},
+		{name: "recovery without failure", rule: NotificationRuleCreate{
+			Channel:  NotificationChannelWebhook,
+			URL:      "https://example.com",
+			Triggers: []NotificationTrigger{NotificationTriggerRecovery},
+		}, want: error2.ErrInvalidNotificationRule},
+		{name: "negative throttle", rule: NotificationRuleCreate{
+			Channel:  NotificationChannelWebhook,
+			URL:      "https://example.com",
+			Throttle: &negative,
+		}, want: error2.ErrInvalidNotificationRule},
+		{name: "invalid selector", rule: NotificationRuleCreate{
+			Channel:  NotificationChannelWebhook,
+			URL:      "https://example.com",
+			Selector: "env=prod OR env=dev",
+		}, want: error2.ErrInvalidTagSelector},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.ToNotificationRule().Validate())
+		})
+	}
+}
+
+func TestNotificationRuleMatches(t *testing.T) {
+	jobID := uuid.New()
+	event := Event{Type: EventExecutionFailed, JobID: jobID, Namespace: DefaultNamespace, Tags: []string{"env=prod", "team=payments"}}
+	otherJobID := uuid.New()
+
+	tests := []struct {
+		name string
+		rule NotificationRule
+		want bool
+	}{
+		{name: "all jobs", rule: NotificationRule{Namespace: DefaultNamespace}, want: true},
+		{name: "other namespace", rule: NotificationRule{Namespace: "other"}, want: false},
+		{name: "job", rule: NotificationRule{Namespace: DefaultNamespace, JobID: &jobID}, want: true},
+		{name: "other job", rule: NotificationRule{Namespace: DefaultNamespace, JobID: &otherJobID}, want: false},
+		{name: "tags", rule: NotificationRule{Namespace: DefaultNamespace, Tags: []string{"team=payments"}}, want: true},
+		{name: "missing tag", rule: NotificationRule{Namespace: DefaultNamespace, Tags: []string{"team=search"}}, want: false},
+		{name: "selector", rule: NotificationRule{Namespace: DefaultNamespace, Selector: "env=prod AND !canary"}, want: true},
+		{name: "unmatched selector", rule: NotificationRule{Namespace: DefaultNamespace, Selector: "env!=prod"}, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.Matches(event))
+		})
+	}
+}
+
+func TestNotificationTriggerOf(t *testing.T) {
+	trigger, ok := NotificationTriggerOf(Event{Type: EventExecutionFailed})
+	assert.True(t, ok)
+	assert.Equal(t, NotificationTriggerFailure, trigger)
+
+	trigger, ok = NotificationTriggerOf(Event{Type: EventExecutionFinished})
+	assert.True(t, ok)
+	assert.Equal(t, NotificationTriggerRecovery, trigger)
+
+	trigger, ok = NotificationTriggerOf(Event{Type: EventJobDisabled})
+	assert.True(t, ok)
+	assert.Equal(t, NotificationTriggerDisabled, trigger)
+
+	_, ok = NotificationTriggerOf(Event{Type: EventExecutionCancelled})
+	assert.False(t, ok)
+}
+
+func TestNotificationStateRecord(t *testing.T) {
+	throttle := time.Hour
+	start := time.Now()
+
+	type step struct {
+		trigger    NotificationTrigger
+		after      time.Duration
+		notify     bool
+		suppressed int
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "Recovery of a healthy job", steps: []step{
+			{trigger: NotificationTriggerRecovery, notify: false},
+		}},
+		{name: "Failure and recovery", steps: []step{
+			{trigger: NotificationTriggerFailure, notify: true},
+			{trigger: NotificationTriggerRecovery, after: time.Minute, notify: true},
+			{trigger: NotificationTriggerRecovery, after: time.Minute * 2, notify: false},
+		}},
+		{name: "Repeated failures", steps: []step{
+			{trigger: NotificationTriggerFailure, notify: true},
+			{trigger: NotificationTriggerFailure, after: time.Minute, notify: false},
+			{trigger: NotificationTriggerFailure, after: time.Minute * 2, notify: false},
+			{trigger: NotificationTriggerFailure, after: time.Hour, notify: true, suppressed: 2},
+		}},
+		{name: "Flapping job", steps: []step{
+			{trigger: NotificationTriggerFailure, notify: true},
+			{trigger: NotificationTriggerRecovery, after: time.Minute, notify: true},
+			{trigger: NotificationTriggerFailure, after: time.Minute * 2, notify: false},
+			{trigger: NotificationTriggerRecovery, after: time.Minute * 3, notify: false},
+			{trigger: NotificationTriggerFailure, after: time.Minute * 4, notify: false},
+			{trigger: NotificationTriggerFailure, after: time.Minute * 61, notify: true, suppressed: 2},
+		}},
+		{name: "Disabled job", steps: []step{
+			{trigger: NotificationTriggerFailure, notify: true},
+			{trigger: NotificationTriggerFailure, after: time.Minute, notify: false},
+			{trigger: NotificationTriggerDisabled, after: time.Minute * 2, notify: true, suppressed: 1},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &NotificationState{}
+			for i, step := range tc.steps {
+				notify, suppressed := state.Record(step.trigger, start.Add(step.after), throttle)
+				assert.Equal(t, step.notify, notify, "step %d", i)
+				assert.Equal(t, step.suppressed, suppressed, "step %d", i)
+			}
+		})
+	}
+}
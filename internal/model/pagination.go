@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Cursor points at the last item of a page. The next page contains the items
+// strictly after the cursor in the listing order.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   string    `json:"id"`
+}
+
+// Encode returns the opaque representation of the cursor, as returned by the API.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses an opaque cursor. An empty string yields a nil cursor (first page).
+func DecodeCursor(encoded string) (*Cursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, error2.ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID == "" {
+		return nil, error2.ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+type Pagination struct {
+	// Cursor for the next page, null when there are no more items
+	NextCursor null.String `json:"next_cursor" swaggertype:"string"`
+	// Total number of items matching the query, estimated for large listings unless an exact total is requested
+	Total uint64 `json:"total"`
+	// Whether the total is an estimate
+	TotalEstimated bool `json:"total_estimated,omitempty"`
+}
+
+// swagger:model JobPage
+type JobPage struct {
+	Jobs []Job `json:"jobs"`
+	Pagination
+}
+
+// SparseJobPage is a page of jobs with only the fields selected by the fields parameter.
+//
+// swagger:model SparseJobPage
+type SparseJobPage struct {
+	Jobs []map[string]json.RawMessage `json:"jobs" swaggertype:"array,object"`
+	Pagination
+}
+
+// Sparse returns the page with only the given fields of the jobs.
+func (p *JobPage) Sparse(fields JobFields) (*SparseJobPage, error) {
+	sparse := &SparseJobPage{Jobs: make([]map[string]json.RawMessage, 0, len(p.Jobs)), Pagination: p.Pagination}
+	for _, job := range p.Jobs {
+		selected, err := fields.Select(job)
+		if err != nil {
+			return nil, err
+		}
+		sparse.Jobs = append(sparse.Jobs, selected)
+	}
+
+	return sparse, nil
+}
+
+// swagger:model JobExecutionPage
+type JobExecutionPage struct {
+	Executions []*JobExecution `json:"executions"`
+	Pagination
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), ID: "a787fa30-2cbe-40de-9a51-f7c9fc43a747"}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor.ID, decoded.ID)
+	assert.True(t, cursor.Time.Equal(decoded.Time))
+
+	decoded, err = DecodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	_, err = DecodeCursor("not a cursor")
+	assert.ErrorIs(t, err, error2.ErrInvalidCursor)
+
+	_, err = DecodeCursor(Cursor{}.Encode())
+	assert.ErrorIs(t, err, error2.ErrInvalidCursor)
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// PayloadLimits bounds the size of the payloads jobs send, in bytes, so a single job can't blow up the store or
+// the runners. A limit of 0 disables it.
+type PayloadLimits struct {
+	// Maximum size of the body of HTTP jobs and of the messages of AMQP jobs
+	MaxBodySize int `mapstructure:"maxBodySize" yaml:"maxBodySize" json:"maxBodySize"`
+	// Maximum total size of the header names and values of HTTP jobs, and of the JSON encoded headers of AMQP jobs
+	MaxHeadersSize int `mapstructure:"maxHeadersSize" yaml:"maxHeadersSize" json:"maxHeadersSize"`
+}
+
+// DefaultPayloadLimits are the payload limits enforced unless configured otherwise.
+var DefaultPayloadLimits = PayloadLimits{
+	MaxBodySize:    1 << 20,
+	MaxHeadersSize: 64 << 10,
+}
+
+var payloadLimits atomic.Pointer[PayloadLimits]
+
+func init() {
+	SetPayloadLimits(DefaultPayloadLimits)
+}
+
+// SetPayloadLimits sets the payload limits enforced when validating jobs.
+func SetPayloadLimits(limits PayloadLimits) {
+	payloadLimits.Store(&limits)
+}
+
+// GetPayloadLimits returns the payload limits enforced when validating jobs.
+func GetPayloadLimits() PayloadLimits {
+	return *payloadLimits.Load()
+}
+
+// exceeds reports whether the size exceeds the limit, if any.
+func exceeds(size, limit int) bool {
+	return limit > 0 && size > limit
+}
+
+// httpHeadersSize returns the total size of the names and values of the headers.
+func httpHeadersSize(headers map[string]string) int {
+	size := 0
+	for name, value := range headers {
+		size += len(name) + len(value)
+	}
+	return size
+}
+
+// amqpHeadersSize returns the size of the JSON encoding of the headers.
+func amqpHeadersSize(headers map[string]interface{}) int {
+	if len(headers) == 0 {
+		return 0
+	}
+
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
@@ -0,0 +1,46 @@
+package model
+
+// JobPriority is the priority of a job. Due jobs are started by order of priority, but jobs of every priority get
+// a share of the runners proportional to the weight of their priority, so low-priority jobs still make progress
+// in a backlog of high-priority ones.
+type JobPriority string
+
+const (
+	JobPriorityLow    JobPriority = "LOW"
+	JobPriorityNormal JobPriority = "NORMAL"
+	JobPriorityHigh   JobPriority = "HIGH"
+)
+
+// JobPriorities are the priorities, from the highest to the lowest.
+var JobPriorities = []JobPriority{JobPriorityHigh, JobPriorityNormal, JobPriorityLow}
+
+func (p JobPriority) Valid() bool {
+	switch p {
+	case JobPriorityLow, JobPriorityNormal, JobPriorityHigh:
+		return true
+	default:
+		return false
+	}
+}
+
+// OrDefault returns the priority, or the normal priority if it is not set.
+func (p JobPriority) OrDefault() JobPriority {
+	if p == "" {
+		return JobPriorityNormal
+	}
+
+	return p
+}
+
+// Weight returns the share of the runners given to the jobs of the priority, relative to the other priorities:
+// with a backlog of every priority, 16 high-priority jobs are started for every 4 normal and 1 low-priority job.
+func (p JobPriority) Weight() int {
+	switch p.OrDefault() {
+	case JobPriorityHigh:
+		return 16
+	case JobPriorityLow:
+		return 1
+	default:
+		return 4
+	}
+}
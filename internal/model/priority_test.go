@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobPriority(t *testing.T) {
+	assert.Equal(t, JobPriorityNormal, JobPriority("").OrDefault())
+	assert.Equal(t, JobPriorityHigh, JobPriorityHigh.OrDefault())
+
+	assert.True(t, JobPriorityLow.Valid())
+	assert.False(t, JobPriority("").Valid())
+	assert.False(t, JobPriority("URGENT").Valid())
+
+	// Priorities are listed from the highest to the lowest weight
+	for i := 1; i < len(JobPriorities); i++ {
+		assert.Greater(t, JobPriorities[i-1].Weight(), JobPriorities[i].Weight())
+	}
+	assert.Equal(t, JobPriorityNormal.Weight(), JobPriority("").Weight())
+}
+
+func TestJobPriorityValidation(t *testing.T) {
+	create := JobCreate{
+		Type:         JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1m"),
+		HTTPJob:      &HTTPJob{URL: "https://example.com", Method: "GET", Auth: Auth{Type: AuthTypeNone}},
+	}
+
+	job := create.ToJob()
+	assert.Equal(t, JobPriorityNormal, job.Priority)
+	assert.NoError(t, job.Validate())
+
+	job.ApplyUpdate(JobUpdate{Priority: lo.ToPtr(JobPriority("URGENT"))})
+	assert.Equal(t, []FieldError{{Field: "priority", Message: "priority must be one of LOW, NORMAL or HIGH"}}, job.FieldErrors())
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+	"unicode/utf8"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"gopkg.in/guregu/null.v4"
+)
+
+// maxProgressMessageLength is the maximum length of a progress message, in characters.
+const maxProgressMessageLength = 1024
+
+// ExecutionProgress is the latest progress reported by a running execution.
+type ExecutionProgress struct {
+	// Percent is the share of the work done, between 0 and 100
+	Percent null.Int `json:"percent" swaggertype:"integer"`
+	// Message describes the current step of the execution
+	Message   null.String `json:"message,omitempty" swaggertype:"string"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Validate validates the progress of an execution.
+func (p ExecutionProgress) Validate() error {
+	if p.Percent.Valid && (p.Percent.Int64 < 0 || p.Percent.Int64 > 100) {
+		return error2.ErrInvalidExecutionProgress
+	}
+
+	if utf8.RuneCountInString(p.Message.String) > maxProgressMessageLength {
+		return error2.ErrInvalidExecutionProgress
+	}
+
+	return nil
+}
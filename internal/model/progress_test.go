@@ -0,0 +1,20 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestExecutionProgressValidate(t *testing.T) {
+	assert.NoError(t, ExecutionProgress{Percent: null.IntFrom(0)}.Validate())
+	assert.NoError(t, ExecutionProgress{Percent: null.IntFrom(100), Message: null.StringFrom("Done")}.Validate())
+	assert.NoError(t, ExecutionProgress{Message: null.StringFrom(strings.Repeat("é", 1024))}.Validate())
+
+	assert.ErrorIs(t, ExecutionProgress{Percent: null.IntFrom(-1)}.Validate(), error2.ErrInvalidExecutionProgress)
+	assert.ErrorIs(t, ExecutionProgress{Percent: null.IntFrom(101)}.Validate(), error2.ErrInvalidExecutionProgress)
+	assert.ErrorIs(t, ExecutionProgress{Message: null.StringFrom(strings.Repeat("a", 1025))}.Validate(), error2.ErrInvalidExecutionProgress)
+}
@@ -0,0 +1,443 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Quartz cron expressions have 6 or 7 fields: seconds, minutes, hours, day of month, month,
+// day of week and an optional year. Besides the usual cron syntax, they support:
+//   - "?" in the day of month or day of week field, meaning "no specific value"
+//   - "L" in the day of month field (last day of month, optionally with an offset, e.g. "L-3")
+//   - "W" in the day of month field (nearest weekday to the given day, e.g. "15W", or "LW")
+//   - "L" in the day of week field (last given weekday of the month, e.g. "6L")
+//   - "#" in the day of week field (nth given weekday of the month, e.g. "6#3")
+const (
+	quartzFields         = 6
+	quartzFieldsWithYear = 7
+	quartzMinYear        = 1970
+	quartzMaxYear        = 2099
+)
+
+var (
+	quartzMonthNames = map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}
+	quartzDayNames = map[string]int{
+		"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+	}
+)
+
+// isQuartzExpression reports whether the expression has the number of fields of a Quartz expression, not counting
+// the time zone prefix.
+func isQuartzExpression(expression string) bool {
+	_, expression = cutTimezone(expression)
+	fields := len(strings.Fields(expression))
+	return fields == quartzFields || fields == quartzFieldsWithYear
+}
+
+// cutTimezone cuts the CRON_TZ= or TZ= time zone prefix off a cron expression, e.g. "CRON_TZ=Europe/Berlin 0 3 * * *",
+// and returns the time zone, empty without a prefix, and the rest of the expression.
+func cutTimezone(expression string) (string, string) {
+	expression = strings.TrimSpace(expression)
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(expression, prefix) {
+			timezone, rest, _ := strings.Cut(strings.TrimPrefix(expression, prefix), " ")
+			return timezone, rest
+		}
+	}
+
+	return "", expression
+}
+
+// QuartzSchedule is a schedule parsed from a Quartz cron expression.
+type QuartzSchedule struct {
+	seconds, minutes, hours, months uint64
+	// years is nil when any year matches
+	years map[int]bool
+
+	dom quartzDayOfMonth
+	dow quartzDayOfWeek
+
+	// location the expression is evaluated in, the one of the given times if nil
+	location *time.Location
+}
+
+type quartzDayOfMonth struct {
+	any  bool
+	days uint64
+	// last day of month, minus lastOffset
+	last       bool
+	lastOffset int
+	// nearest weekday to the given day, or to the last day of the month
+	nearestWeekday int
+	lastWeekday    bool
+}
+
+type quartzDayOfWeek struct {
+	any bool
+	// days is a bitset of weekdays, where Sunday is 0
+	days uint64
+	// last given weekday in the month (-1 if not set)
+	last int
+	// nth given weekday in the month (nth is 0 if not set)
+	nthDay int
+	nth    int
+}
+
+// ParseQuartz parses a Quartz cron expression, optionally prefixed with a time zone like standard cron expressions,
+// e.g. "CRON_TZ=Europe/Berlin 0 0 3 * * ?".
+func ParseQuartz(expression string) (*QuartzSchedule, error) {
+	timezone, expression := cutTimezone(expression)
+	fields := strings.Fields(strings.ToUpper(expression))
+	if len(fields) != quartzFields && len(fields) != quartzFieldsWithYear {
+		return nil, fmt.Errorf("expected %d or %d fields, got %d", quartzFields, quartzFieldsWithYear, len(fields))
+	}
+
+	var (
+		schedule = &QuartzSchedule{}
+		err      error
+	)
+
+	if timezone != "" {
+		if schedule.location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("time zone: %w", err)
+		}
+	}
+
+	if schedule.seconds, err = parseQuartzField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("seconds: %w", err)
+	}
+
+	if schedule.minutes, err = parseQuartzField(fields[1], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minutes: %w", err)
+	}
+
+	if schedule.hours, err = parseQuartzField(fields[2], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hours: %w", err)
+	}
+
+	if schedule.dom, err = parseQuartzDayOfMonth(fields[3]); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+
+	if schedule.months, err = parseQuartzField(fields[4], 1, 12, quartzMonthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+
+	if schedule.dow, err = parseQuartzDayOfWeek(fields[5]); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+
+	if !schedule.dom.any && !schedule.dow.any {
+		return nil, fmt.Errorf("specifying both a day of month and a day of week is not supported, use '?' for one of them")
+	}
+
+	if len(fields) == quartzFieldsWithYear && fields[6] != "*" {
+		years, err := parseQuartzYears(fields[6])
+		if err != nil {
+			return nil, fmt.Errorf("year: %w", err)
+		}
+		schedule.years = years
+	}
+
+	return schedule, nil
+}
+
+// parseQuartzField parses a list of values, ranges and steps into a bitset.
+func parseQuartzField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		start, end, step, err := parseQuartzRange(part, min, max, names)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseQuartzRange(part string, min, max int, names map[string]int) (int, int, int, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, 0, 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+	}
+
+	var start, end int
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		start, end = min, max
+	case strings.Contains(rangePart, "-"):
+		from, to, _ := strings.Cut(rangePart, "-")
+
+		var err error
+		if start, err = parseQuartzValue(from, min, max, names); err != nil {
+			return 0, 0, 0, err
+		}
+
+		if end, err = parseQuartzValue(to, min, max, names); err != nil {
+			return 0, 0, 0, err
+		}
+
+		if start > end {
+			return 0, 0, 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+	default:
+		value, err := parseQuartzValue(rangePart, min, max, names)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		start, end = value, value
+		// "5/15" means starting at 5, every 15 units
+		if hasStep {
+			end = max
+		}
+	}
+
+	return start, end, step, nil
+}
+
+func parseQuartzValue(value string, min, max int, names map[string]int) (int, error) {
+	if n, ok := names[value]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if n < min || n > max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", n, min, max)
+	}
+
+	return n, nil
+}
+
+func parseQuartzDayOfMonth(field string) (quartzDayOfMonth, error) {
+	dom := quartzDayOfMonth{}
+
+	switch {
+	case field == "?" || field == "*":
+		dom.any = true
+	case field == "LW":
+		dom.lastWeekday = true
+	case strings.HasPrefix(field, "L"):
+		dom.last = true
+		if offset := strings.TrimPrefix(field, "L"); offset != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(offset, "-"))
+			if err != nil || !strings.HasPrefix(offset, "-") || n < 0 || n > 30 {
+				return dom, fmt.Errorf("invalid last day offset %q", field)
+			}
+			dom.lastOffset = n
+		}
+	case strings.HasSuffix(field, "W"):
+		n, err := parseQuartzValue(strings.TrimSuffix(field, "W"), 1, 31, nil)
+		if err != nil {
+			return dom, err
+		}
+		dom.nearestWeekday = n
+	default:
+		days, err := parseQuartzField(field, 1, 31, nil)
+		if err != nil {
+			return dom, err
+		}
+		dom.days = days
+	}
+
+	return dom, nil
+}
+
+func parseQuartzDayOfWeek(field string) (quartzDayOfWeek, error) {
+	dow := quartzDayOfWeek{last: -1}
+
+	switch {
+	case field == "?" || field == "*":
+		dow.any = true
+	case field == "L":
+		// "L" alone means the last day of the week (Saturday)
+		dow.days = 1 << 6
+	case strings.HasSuffix(field, "L"):
+		day, err := parseQuartzValue(strings.TrimSuffix(field, "L"), 1, 7, quartzDayNames)
+		if err != nil {
+			return dow, err
+		}
+		dow.last = day - 1
+	case strings.Contains(field, "#"):
+		dayPart, nthPart, _ := strings.Cut(field, "#")
+
+		day, err := parseQuartzValue(dayPart, 1, 7, quartzDayNames)
+		if err != nil {
+			return dow, err
+		}
+
+		nth, err := parseQuartzValue(nthPart, 1, 5, nil)
+		if err != nil {
+			return dow, err
+		}
+
+		dow.nthDay, dow.nth = day-1, nth
+	default:
+		days, err := parseQuartzField(field, 1, 7, quartzDayNames)
+		if err != nil {
+			return dow, err
+		}
+
+		// Quartz numbers the days from 1 (Sunday) to 7 (Saturday)
+		dow.days = days >> 1
+	}
+
+	return dow, nil
+}
+
+func parseQuartzYears(field string) (map[int]bool, error) {
+	years := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+		start, end, step, err := parseQuartzRange(part, quartzMinYear, quartzMaxYear, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := start; i <= end; i += step {
+			years[i] = true
+		}
+	}
+
+	return years, nil
+}
+
+// Next returns the first activation time strictly after the given time,
+// or the zero time if the schedule can never be satisfied.
+func (s *QuartzSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	if s.location != nil {
+		loc = s.location
+	}
+	start := t.In(loc).Add(time.Second).Truncate(time.Second)
+
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); day.Year() <= quartzMaxYear; day = day.AddDate(0, 0, 1) {
+		if !s.matchesDay(day) {
+			continue
+		}
+
+		if next, ok := s.firstTimeOfDay(day, start); ok {
+			return next.In(t.Location())
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *QuartzSchedule) firstTimeOfDay(day, notBefore time.Time) (time.Time, bool) {
+	for h := 0; h < 24; h++ {
+		if s.hours&(1<<uint(h)) == 0 {
+			continue
+		}
+
+		for m := 0; m < 60; m++ {
+			if s.minutes&(1<<uint(m)) == 0 {
+				continue
+			}
+
+			for sec := 0; sec < 60; sec++ {
+				if s.seconds&(1<<uint(sec)) == 0 {
+					continue
+				}
+
+				candidate := time.Date(day.Year(), day.Month(), day.Day(), h, m, sec, 0, day.Location())
+				if !candidate.Before(notBefore) {
+					return candidate, true
+				}
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (s *QuartzSchedule) matchesDay(day time.Time) bool {
+	if s.years != nil && !s.years[day.Year()] {
+		return false
+	}
+
+	if s.months&(1<<uint(day.Month())) == 0 {
+		return false
+	}
+
+	return s.matchesDayOfMonth(day) && s.matchesDayOfWeek(day)
+}
+
+func (s *QuartzSchedule) matchesDayOfMonth(day time.Time) bool {
+	dom := s.dom
+	lastDay := daysInMonth(day)
+
+	switch {
+	case dom.any:
+		return true
+	case dom.last:
+		return day.Day() == lastDay-dom.lastOffset
+	case dom.lastWeekday:
+		return day.Day() == nearestWeekday(day, lastDay)
+	case dom.nearestWeekday > 0:
+		return day.Day() == nearestWeekday(day, min(dom.nearestWeekday, lastDay))
+	default:
+		return dom.days&(1<<uint(day.Day())) != 0
+	}
+}
+
+func (s *QuartzSchedule) matchesDayOfWeek(day time.Time) bool {
+	dow := s.dow
+	weekday := int(day.Weekday())
+
+	switch {
+	case dow.any:
+		return true
+	case dow.last >= 0:
+		return weekday == dow.last && day.Day()+7 > daysInMonth(day)
+	case dow.nth > 0:
+		return weekday == dow.nthDay && (day.Day()-1)/7+1 == dow.nth
+	default:
+		return dow.days&(1<<uint(weekday)) != 0
+	}
+}
+
+// nearestWeekday returns the weekday closest to the target day within the month of the given day.
+func nearestWeekday(day time.Time, target int) int {
+	date := time.Date(day.Year(), day.Month(), target, 0, 0, 0, 0, day.Location())
+	lastDay := daysInMonth(day)
+
+	switch date.Weekday() {
+	case time.Saturday:
+		if target == 1 {
+			return target + 2
+		}
+		return target - 1
+	case time.Sunday:
+		if target == lastDay {
+			return target - 2
+		}
+		return target + 1
+	default:
+		return target
+	}
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuartz(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{name: "every day at noon", expression: "0 0 12 * * ?"},
+		{name: "weekdays with names", expression: "0 15 10 ? * MON-FRI"},
+		{name: "with year", expression: "0 15 10 ? * 6L 2024-2030"},
+		{name: "last day of month", expression: "0 0 0 L * ?"},
+		{name: "last day offset", expression: "0 0 0 L-3 * ?"},
+		{name: "nearest weekday", expression: "0 0 0 15W * ?"},
+		{name: "last weekday of month", expression: "0 0 0 LW * ?"},
+		{name: "nth weekday", expression: "0 0 0 ? * 6#3"},
+		{name: "steps", expression: "0/15 5/10 * * JAN-JUN ?"},
+		{name: "both day fields specified", expression: "0 0 12 1 * MON", wantErr: true},
+		{name: "out of range", expression: "60 0 12 * * ?", wantErr: true},
+		{name: "invalid nth", expression: "0 0 0 ? * 6#6", wantErr: true},
+		{name: "wrong number of fields", expression: "0 0 12 * *", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseQuartz(tc.expression)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestQuartzSchedule_Next(t *testing.T) {
+	// Monday, 15 January 2024
+	from := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		expression string
+		want       time.Time
+	}{
+		{name: "every day at noon", expression: "0 0 12 * * ?", want: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)},
+		{name: "every 15 seconds", expression: "0/15 * * * * ?", want: time.Date(2024, 1, 15, 10, 30, 15, 0, time.UTC)},
+		{name: "last day of month", expression: "0 0 0 L * ?", want: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "last day of month minus two", expression: "0 0 0 L-2 * ?", want: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)},
+		// 1 June 2024 is a Saturday, so the nearest weekday is Monday the 3rd
+		{name: "nearest weekday on first of month", expression: "0 0 0 1W 6 ?", want: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)},
+		// 31 March 2024 is a Sunday, so the last weekday is Friday the 29th
+		{name: "last weekday of month", expression: "0 0 0 LW 3 ?", want: time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "last friday of month", expression: "0 0 9 ? * 6L", want: time.Date(2024, 1, 26, 9, 0, 0, 0, time.UTC)},
+		{name: "third friday of month", expression: "0 0 9 ? * FRI#3", want: time.Date(2024, 1, 19, 9, 0, 0, 0, time.UTC)},
+		{name: "specific year", expression: "0 0 0 1 1 ? 2026", want: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "leap day", expression: "0 0 0 29 2 ?", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "unsatisfiable", expression: "0 0 0 1 1 ? 2020", want: time.Time{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tc.expression)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, schedule.Next(from))
+		})
+	}
+}
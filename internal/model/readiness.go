@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// Readiness reports whether the scheduler is keeping up, rather than only being up.
+//
+// swagger:model Readiness
+type Readiness struct {
+	// Ready is false if the database is unreachable or the scheduler lags behind
+	Ready     bool            `json:"ready"`
+	Database  DatabaseStatus  `json:"database"`
+	Scheduler SchedulerStatus `json:"scheduler"`
+	Runners   []RunnerStatus  `json:"runners"`
+}
+
+type DatabaseStatus struct {
+	Connected bool `json:"connected"`
+	// Round trip time of a query, in milliseconds
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// SchedulerStatus reports the jobs that are due but not picked up by any runner yet.
+type SchedulerStatus struct {
+	DueJobs     uint64    `json:"due_jobs"`
+	OldestDueAt null.Time `json:"oldest_due_at,omitempty" swaggertype:"string"`
+	// Age of the oldest due job, in seconds
+	LagSeconds float64 `json:"lag_seconds"`
+	// Lag above which the scheduler isn't keeping up, in seconds (0 if not checked)
+	MaxLagSeconds float64 `json:"max_lag_seconds,omitempty"`
+	KeepingUp     bool    `json:"keeping_up"`
+}
+
+// NewSchedulerStatus returns the status of a scheduler with due jobs at the given time. The scheduler is keeping up
+// unless the oldest due job is older than the maximum lag (0 disables the check).
+func NewSchedulerStatus(at time.Time, dueJobs uint64, oldestDueAt null.Time, maxLag time.Duration) SchedulerStatus {
+	status := SchedulerStatus{
+		DueJobs:       dueJobs,
+		OldestDueAt:   oldestDueAt,
+		MaxLagSeconds: maxLag.Seconds(),
+		KeepingUp:     true,
+	}
+
+	if oldestDueAt.Valid && oldestDueAt.Time.Before(at) {
+		lag := at.Sub(oldestDueAt.Time)
+		status.LagSeconds = lag.Seconds()
+		status.KeepingUp = maxLag <= 0 || lag <= maxLag
+	}
+
+	return status
+}
+
+// RunnerStatus reports the freshness of the heartbeats of a runner.
+type RunnerStatus struct {
+	ID    string `json:"id"`
+	Alive bool   `json:"alive"`
+	// Time since the last heartbeat, in seconds
+	HeartbeatAgeSeconds float64 `json:"heartbeat_age_seconds"`
+	Load                int     `json:"load"`
+	Capacity            int     `json:"capacity"`
+}
+
+// NewRunnerStatus returns the status of the runner instance at the given time.
+func NewRunnerStatus(at time.Time, instance Instance) RunnerStatus {
+	return RunnerStatus{
+		ID:                  instance.ID,
+		Alive:               instance.Alive,
+		HeartbeatAgeSeconds: max(at.Sub(instance.LastSeenAt), 0).Seconds(),
+		Load:                instance.Load,
+		Capacity:            instance.Capacity,
+	}
+}
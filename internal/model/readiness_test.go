@@ -0,0 +1,42 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestNewSchedulerStatus(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		oldestDueAt null.Time
+		maxLag      time.Duration
+		lag         float64
+		keepingUp   bool
+	}{
+		{"No due jobs", null.Time{}, time.Minute, 0, true},
+		{"Within the maximum lag", null.TimeFrom(now.Add(-30 * time.Second)), time.Minute, 30, true},
+		{"Above the maximum lag", null.TimeFrom(now.Add(-2 * time.Minute)), time.Minute, 120, false},
+		{"Check disabled", null.TimeFrom(now.Add(-time.Hour)), 0, 3600, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := NewSchedulerStatus(now, 3, tt.oldestDueAt, tt.maxLag)
+			assert.InDelta(t, tt.lag, status.LagSeconds, 0.001)
+			assert.Equal(t, tt.keepingUp, status.KeepingUp)
+			assert.Equal(t, uint64(3), status.DueJobs)
+		})
+	}
+}
+
+func TestNewRunnerStatus(t *testing.T) {
+	now := time.Now()
+	status := NewRunnerStatus(now, Instance{ID: "runner-1", Alive: true, LastSeenAt: now.Add(-5 * time.Second), Load: 2, Capacity: 10})
+
+	assert.Equal(t, RunnerStatus{ID: "runner-1", Alive: true, HeartbeatAgeSeconds: 5, Load: 2, Capacity: 10}, status)
+}
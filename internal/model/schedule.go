@@ -0,0 +1,150 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	everyKeyword   = "every "
+	anchorKeyword  = " starting "
+	minimumElapsed = time.Second
+
+	defaultPreviewCount = 10
+	maxPreviewCount     = 100
+)
+
+// anchorLayouts are the accepted formats of the anchor of the interval schedules: RFC 3339 times, with or without
+// seconds.
+var anchorLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00"}
+
+// ParseSchedule parses a schedule expression. Besides standard cron expressions and descriptors
+// (e.g. "@daily", "@every 1h"), it supports interval schedules anchored to a start time,
+// "[@]every <interval> starting <anchor>", e.g. "@every 6h starting 2024-01-01T03:00:00Z" or
+// "every 6h starting 2024-01-01T03:00Z". The interval is a Go duration of at least one second, and the anchor an
+// RFC 3339 time, with or without seconds, whose offset is required ("Z" for UTC). Anchored schedules always run at
+// anchor + n*interval, regardless of when the job was created or last executed.
+// Quartz cron expressions (6 or 7 fields) are supported as well, see ParseQuartz. Both standard and Quartz
+// expressions can be prefixed with a time zone, e.g. "CRON_TZ=Europe/Berlin 0 3 * * *", which is not counted as a field.
+func ParseSchedule(expression string) (cron.Schedule, error) {
+	if strings.HasPrefix(strings.TrimPrefix(expression, "@"), everyKeyword) && strings.Contains(expression, anchorKeyword) {
+		return parseAnchoredSchedule(expression)
+	}
+
+	if isQuartzExpression(expression) {
+		return ParseQuartz(expression)
+	}
+
+	return cron.ParseStandard(expression)
+}
+
+func parseAnchoredSchedule(expression string) (cron.Schedule, error) {
+	interval, anchor, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(expression, "@"), everyKeyword), anchorKeyword)
+
+	every, err := time.ParseDuration(strings.TrimSpace(interval))
+	if err != nil {
+		return nil, err
+	}
+
+	if every < minimumElapsed {
+		return nil, errors.New("interval must be at least one second")
+	}
+
+	anchor = strings.TrimSpace(anchor)
+	for _, layout := range anchorLayouts {
+		if start, err := time.Parse(layout, anchor); err == nil {
+			return AnchoredSchedule{Interval: every, Anchor: start}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid anchor %q: expected an RFC 3339 time, e.g. 2024-01-01T03:00:00Z or 2024-01-01T03:00Z", anchor)
+}
+
+// AnchoredSchedule represents an interval schedule that runs at Anchor + n*Interval.
+type AnchoredSchedule struct {
+	Interval time.Duration
+	Anchor   time.Time
+}
+
+// Next returns the first activation time strictly after the given time.
+func (s AnchoredSchedule) Next(t time.Time) time.Time {
+	if t.Before(s.Anchor) {
+		return s.Anchor
+	}
+
+	elapsed := t.Sub(s.Anchor)
+	periods := elapsed/s.Interval + 1
+
+	return s.Anchor.Add(periods * s.Interval)
+}
+
+// swagger:model SchedulePreviewRequest
+type SchedulePreviewRequest struct {
+	// Schedule expression, in any format supported by the job cron_schedule. The occurrences are computed in the time
+	// zone of the CRON_TZ= or TZ= prefix of the expression, e.g. "CRON_TZ=Europe/Ljubljana 0 9 * * *", in UTC without
+	// one, like the runs of the jobs. RRULE expressions are not supported.
+	Expression string `json:"expression"`
+	// Number of occurrences to return (default: 10, max: 100)
+	Count int `json:"count,omitempty"`
+	// Time after which occurrences are computed (default: now)
+	From *time.Time `json:"from,omitempty"`
+}
+
+// swagger:model SchedulePreview
+type SchedulePreview struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+
+// PreviewSchedule returns the next occurrences of the schedule, in the time zone of the expression.
+func PreviewSchedule(req SchedulePreviewRequest) (*SchedulePreview, error) {
+	if isRRule(req.Expression) {
+		return nil, error2.ErrUnsupportedRRule
+	}
+
+	schedule, err := ParseSchedule(req.Expression)
+	if err != nil {
+		return nil, error2.ErrInvalidCronSchedule
+	}
+
+	location := time.UTC
+	if timezone, _ := cutTimezone(req.Expression); timezone != "" {
+		// the expression would not parse with an unknown time zone
+		location, _ = time.LoadLocation(timezone)
+	}
+
+	count := req.Count
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	count = min(count, maxPreviewCount)
+
+	next := time.Now()
+	if req.From != nil {
+		next = *req.From
+	}
+	next = next.In(location)
+
+	preview := &SchedulePreview{Occurrences: []time.Time{}}
+	for i := 0; i < count; i++ {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			break
+		}
+
+		preview.Occurrences = append(preview.Occurrences, next)
+	}
+
+	return preview, nil
+}
+
+// isRRule reports whether the expression is an iCalendar recurrence rule, e.g. "RRULE:FREQ=DAILY;BYHOUR=3", which
+// is not a supported schedule format.
+func isRRule(expression string) bool {
+	expression = strings.ToUpper(strings.TrimSpace(expression))
+	return strings.HasPrefix(expression, "RRULE:") || strings.HasPrefix(expression, "DTSTART")
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{name: "standard cron", expression: "0 * * * *"},
+		{name: "descriptor", expression: "@daily"},
+		{name: "every", expression: "@every 1h"},
+		{name: "anchored every", expression: "@every 6h starting 2024-01-01T03:00:00Z"},
+		{name: "anchored every with invalid interval", expression: "@every 6x starting 2024-01-01T03:00:00Z", wantErr: true},
+		{name: "anchored every with sub-second interval", expression: "@every 10ms starting 2024-01-01T03:00:00Z", wantErr: true},
+		{name: "anchored every without seconds", expression: "@every 6h starting 2024-01-01T03:00Z"},
+		{name: "anchored every with offset", expression: "@every 6h starting 2024-01-01T03:00:00.5+02:00"},
+		{name: "anchored every without @", expression: "every 6h starting 2024-01-01T03:00Z"},
+		{name: "anchored every without offset", expression: "@every 6h starting 2024-01-01T03:00", wantErr: true},
+		{name: "anchored every with invalid anchor", expression: "@every 6h starting tomorrow", wantErr: true},
+		{name: "standard cron with CRON_TZ", expression: "CRON_TZ=Europe/Berlin 0 3 * * *"},
+		{name: "standard cron with TZ", expression: "TZ=UTC */5 * * * *"},
+		{name: "quartz with CRON_TZ", expression: "CRON_TZ=Europe/Berlin 0 0 3 * * ?"},
+		{name: "quartz with unknown time zone", expression: "CRON_TZ=Mars/Olympus 0 0 3 * * ?", wantErr: true},
+		{name: "invalid", expression: "invalid", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseSchedule(tc.expression)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Timezone(t *testing.T) {
+	// time zone prefixed 5 field expressions are standard cron expressions, not Quartz ones
+	berlin, err := ParseSchedule("CRON_TZ=Europe/Berlin 0 3 * * *")
+	require.NoError(t, err)
+	assert.IsType(t, &cron.SpecSchedule{}, berlin)
+
+	from := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 7, 2, 1, 0, 0, 0, time.UTC), berlin.Next(from).UTC())
+
+	utc, err := ParseSchedule("TZ=UTC */5 * * * *")
+	require.NoError(t, err)
+	assert.IsType(t, &cron.SpecSchedule{}, utc)
+	assert.Equal(t, time.Date(2024, 7, 1, 12, 5, 0, 0, time.UTC), utc.Next(from).UTC())
+
+	// Quartz expressions are evaluated in the time zone of the prefix
+	quartz, err := ParseSchedule("CRON_TZ=Europe/Berlin 0 0 3 * * ?")
+	require.NoError(t, err)
+	assert.IsType(t, &QuartzSchedule{}, quartz)
+	assert.Equal(t, time.Date(2024, 7, 2, 1, 0, 0, 0, time.UTC), quartz.Next(from).UTC())
+	assert.Equal(t, time.UTC, quartz.Next(from).Location())
+}
+
+func TestAnchoredSchedule_Next(t *testing.T) {
+	schedule, err := ParseSchedule("@every 6h starting 2024-01-01T03:00:00Z")
+	require.NoError(t, err)
+
+	anchor := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+
+	// Before the anchor, the anchor itself is the next run
+	assert.Equal(t, anchor, schedule.Next(anchor.Add(-time.Hour)))
+
+	// Exactly on an activation, the next activation is returned
+	assert.Equal(t, anchor.Add(6*time.Hour), schedule.Next(anchor))
+
+	// The anchor can be given without seconds
+	short, err := ParseSchedule("every 6h starting 2024-01-01T03:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, schedule, short)
+
+	// Runs stay aligned to the anchor, no matter when the schedule is evaluated
+	assert.Equal(t, anchor.Add(30*24*time.Hour+6*time.Hour), schedule.Next(anchor.Add(30*24*time.Hour+time.Minute)))
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("defaults", func(t *testing.T) {
+		preview, err := PreviewSchedule(SchedulePreviewRequest{Expression: "0 * * * *", From: &from})
+		require.NoError(t, err)
+
+		assert.Len(t, preview.Occurrences, defaultPreviewCount)
+		assert.Equal(t, from.Add(time.Hour), preview.Occurrences[0].UTC())
+	})
+
+	t.Run("time zone prefix", func(t *testing.T) {
+		preview, err := PreviewSchedule(SchedulePreviewRequest{Expression: "CRON_TZ=Europe/Ljubljana 0 9 * * *", Count: 2, From: &from})
+		require.NoError(t, err)
+
+		require.Len(t, preview.Occurrences, 2)
+		assert.Equal(t, time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), preview.Occurrences[0].UTC())
+		assert.Equal(t, "Europe/Ljubljana", preview.Occurrences[0].Location().String())
+	})
+
+	t.Run("UTC without prefix", func(t *testing.T) {
+		preview, err := PreviewSchedule(SchedulePreviewRequest{Expression: "0 9 * * *", Count: 1, From: &from})
+		require.NoError(t, err)
+
+		assert.Equal(t, []time.Time{time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}, preview.Occurrences)
+	})
+
+	t.Run("count is capped", func(t *testing.T) {
+		preview, err := PreviewSchedule(SchedulePreviewRequest{Expression: "* * * * *", Count: 1000})
+		require.NoError(t, err)
+
+		assert.Len(t, preview.Occurrences, maxPreviewCount)
+	})
+
+	t.Run("invalid expression", func(t *testing.T) {
+		_, err := PreviewSchedule(SchedulePreviewRequest{Expression: "invalid"})
+		assert.ErrorIs(t, err, error2.ErrInvalidCronSchedule)
+	})
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		_, err := PreviewSchedule(SchedulePreviewRequest{Expression: "CRON_TZ=Mars/Olympus @daily"})
+		assert.ErrorIs(t, err, error2.ErrInvalidCronSchedule)
+	})
+
+	t.Run("RRULE", func(t *testing.T) {
+		_, err := PreviewSchedule(SchedulePreviewRequest{Expression: "RRULE:FREQ=DAILY;BYHOUR=3"})
+		assert.ErrorIs(t, err, error2.ErrUnsupportedRRule)
+	})
+}
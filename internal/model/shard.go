@@ -0,0 +1,52 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// JobShard is the share of the due jobs of a runner. Jobs are spread over the live runners by a hash of their ID,
+// so runners polling at the same time don't compete for the same jobs, and a runner can't lock the whole batch
+// while the others idle. Jobs of the other shards are still taken once they are overdue by the grace period,
+// e.g. because their runner died or is busy.
+//
+// The zero value doesn't shard the jobs.
+type JobShard struct {
+	// Index of the shard of the runner, from 0 to Count-1
+	Index int
+	// Count is the number of shards, one per live runner
+	Count int
+	Grace time.Duration
+}
+
+// NewJobShard returns the shard of the instance among the live instances executing the same namespaces. The jobs
+// aren't sharded if the instance isn't registered yet.
+func NewJobShard(instanceID string, instances []Instance, grace time.Duration) JobShard {
+	var self *Instance
+	for i := range instances {
+		if instances[i].ID == instanceID {
+			self = &instances[i]
+		}
+	}
+	if self == nil {
+		return JobShard{}
+	}
+
+	var peers []string
+	for _, instance := range instances {
+		if instance.Alive && sameNamespaces(instance.Namespaces, self.Namespaces) || instance.ID == instanceID {
+			peers = append(peers, instance.ID)
+		}
+	}
+	slices.Sort(peers)
+
+	return JobShard{Index: slices.Index(peers, instanceID), Count: len(peers), Grace: grace}
+}
+
+func sameNamespaces(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJobShard(t *testing.T) {
+	instances := []Instance{
+		{ID: "c", Alive: true},
+		{ID: "a", Alive: true},
+		{ID: "b", Alive: true, Namespaces: []string{"billing"}},
+		{ID: "d", Alive: false},
+		{ID: "e", Alive: true, Namespaces: []string{"reports", "billing"}},
+		{ID: "f", Alive: true, Namespaces: []string{"billing", "reports"}},
+	}
+
+	// the shards are spread over the live instances executing the same namespaces
+	assert.Equal(t, JobShard{Index: 0, Count: 2, Grace: time.Minute}, NewJobShard("a", instances, time.Minute))
+	assert.Equal(t, JobShard{Index: 1, Count: 2, Grace: time.Minute}, NewJobShard("c", instances, time.Minute))
+	assert.Equal(t, JobShard{Index: 0, Count: 1, Grace: time.Minute}, NewJobShard("b", instances, time.Minute))
+	assert.Equal(t, JobShard{Index: 1, Count: 2, Grace: time.Minute}, NewJobShard("f", instances, time.Minute))
+
+	// an instance that missed its heartbeats still takes its own shard
+	assert.Equal(t, JobShard{Index: 2, Count: 3, Grace: time.Minute}, NewJobShard("d", instances, time.Minute))
+
+	// the jobs of an unregistered instance are not sharded
+	assert.Equal(t, JobShard{}, NewJobShard("g", instances, time.Minute))
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// DefaultSLAWindow is the window the compliance of a job with its SLA is computed over, unless the SLA sets one.
+	DefaultSLAWindow = 24 * time.Hour
+
+	minSLAWindow = time.Hour
+	maxSLAWindow = 90 * 24 * time.Hour
+)
+
+// SLAObjective is an objective of the SLA of a job.
+type SLAObjective string
+
+const (
+	// SLAObjectiveMaxDelay is breached by an execution that didn't finish within the max delay of its scheduled time
+	SLAObjectiveMaxDelay SLAObjective = "max_delay"
+	// SLAObjectiveMinSuccessRate is breached if less than the min success rate of the executions succeeded
+	SLAObjectiveMinSuccessRate SLAObjective = "min_success_rate"
+)
+
+// JobSLA is the service level a job must meet over a sliding window: every execution finishing within MaxDelay of
+// its scheduled time, and at least MinSuccessRate percent of the finished executions succeeding.
+type JobSLA struct {
+	// MaxDelay is the number of seconds after its scheduled time an execution must have finished by
+	MaxDelay null.Int `json:"max_delay" swaggertype:"integer"`
+	// MinSuccessRate is the minimum percentage of successful executions, between 0 and 100
+	MinSuccessRate null.Float `json:"min_success_rate" swaggertype:"number"`
+	// Window is the number of seconds the compliance is computed over, a day by default
+	Window int64 `json:"window,omitempty"`
+}
+
+// Validate validates the SLA, which must set at least one objective.
+func (s *JobSLA) Validate() error {
+	if !s.MaxDelay.Valid && !s.MinSuccessRate.Valid {
+		return error2.ErrInvalidJobSLA
+	}
+
+	if s.MaxDelay.Valid && s.MaxDelay.Int64 <= 0 {
+		return error2.ErrInvalidJobSLA
+	}
+
+	if s.MinSuccessRate.Valid && (s.MinSuccessRate.Float64 < 0 || s.MinSuccessRate.Float64 > 100) {
+		return error2.ErrInvalidJobSLA
+	}
+
+	if s.Window != 0 && (s.Window < int64(minSLAWindow.Seconds()) || s.Window > int64(maxSLAWindow.Seconds())) {
+		return error2.ErrInvalidJobSLA
+	}
+
+	return nil
+}
+
+// Empty reports whether the SLA sets no objective. Updating a job with an empty SLA removes its SLA.
+func (s *JobSLA) Empty() bool {
+	return !s.MaxDelay.Valid && !s.MinSuccessRate.Valid
+}
+
+// WindowDuration returns the window of the SLA, or DefaultSLAWindow if it is not set.
+func (s *JobSLA) WindowDuration() time.Duration {
+	if s.Window == 0 {
+		return DefaultSLAWindow
+	}
+
+	return time.Duration(s.Window) * time.Second
+}
+
+// JobSLAStatus is the compliance of a job with its SLA, over the executions started within the window of the SLA.
+type JobSLAStatus struct {
+	JobID uuid.UUID `json:"job_id"`
+	SLA   JobSLA    `json:"sla"`
+	// Executions is the number of finished executions, not counting the cancelled executions
+	Executions uint64 `json:"executions"`
+	Successful uint64 `json:"successful"`
+	// Late is the number of executions, including the running ones, that didn't finish within the max delay
+	Late uint64 `json:"late"`
+	// SuccessRate is the percentage of successful executions, if any execution finished
+	SuccessRate null.Float `json:"success_rate" swaggertype:"number"`
+	Compliant   bool       `json:"compliant"`
+	// Breaches are the objectives of the SLA the job doesn't meet
+	Breaches    []SLAObjective `json:"breaches"`
+	EvaluatedAt time.Time      `json:"evaluated_at"`
+}
+
+// Evaluate computes the success rate of the executions and the objectives of the SLA they breach.
+func (s *JobSLAStatus) Evaluate() {
+	s.SuccessRate = null.Float{}
+	if s.Executions > 0 {
+		s.SuccessRate = null.FloatFrom(float64(s.Successful) / float64(s.Executions) * 100)
+	}
+
+	s.Breaches = []SLAObjective{}
+	if s.SLA.MaxDelay.Valid && s.Late > 0 {
+		s.Breaches = append(s.Breaches, SLAObjectiveMaxDelay)
+	}
+
+	if s.SLA.MinSuccessRate.Valid && s.SuccessRate.Valid && s.SuccessRate.Float64 < s.SLA.MinSuccessRate.Float64 {
+		s.Breaches = append(s.Breaches, SLAObjectiveMinSuccessRate)
+	}
+
+	s.Compliant = len(s.Breaches) == 0
+}
+
+// SLABreach is a job that stopped meeting its SLA, with its status.
+type SLABreach struct {
+	Job    *Job
+	Status JobSLAStatus
+}
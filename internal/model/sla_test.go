@@ -0,0 +1,60 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobSLAValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		sla  JobSLA
+		want error
+	}{
+		{name: "max delay", sla: JobSLA{MaxDelay: null.IntFrom(300)}, want: nil},
+		{name: "min success rate", sla: JobSLA{MinSuccessRate: null.FloatFrom(99.5), Window: 7 * 24 * 3600}, want: nil},
+		{name: "no objective", sla: JobSLA{Window: 3600}, want: error2.ErrInvalidJobSLA},
+		{name: "negative max delay", sla: JobSLA{MaxDelay: null.IntFrom(-1)}, want: error2.ErrInvalidJobSLA},
+		{name: "success rate above 100", sla: JobSLA{MinSuccessRate: null.FloatFrom(101)}, want: error2.ErrInvalidJobSLA},
+		{name: "window too short", sla: JobSLA{MaxDelay: null.IntFrom(60), Window: 60}, want: error2.ErrInvalidJobSLA},
+		{name: "window too long", sla: JobSLA{MaxDelay: null.IntFrom(60), Window: 365 * 24 * 3600}, want: error2.ErrInvalidJobSLA},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.sla.Validate())
+		})
+	}
+}
+
+func TestJobSLAStatusEvaluate(t *testing.T) {
+	sla := JobSLA{MaxDelay: null.IntFrom(300), MinSuccessRate: null.FloatFrom(90)}
+
+	tests := []struct {
+		name        string
+		status      JobSLAStatus
+		successRate null.Float
+		breaches    []SLAObjective
+	}{
+		{name: "No executions", status: JobSLAStatus{SLA: sla}, breaches: []SLAObjective{}},
+		{name: "Compliant", status: JobSLAStatus{SLA: sla, Executions: 10, Successful: 9}, successRate: null.FloatFrom(90), breaches: []SLAObjective{}},
+		{name: "Late execution", status: JobSLAStatus{SLA: sla, Executions: 10, Successful: 10, Late: 1}, successRate: null.FloatFrom(100),
+			breaches: []SLAObjective{SLAObjectiveMaxDelay}},
+		{name: "Failing executions", status: JobSLAStatus{SLA: sla, Executions: 4, Successful: 3, Late: 1}, successRate: null.FloatFrom(75),
+			breaches: []SLAObjective{SLAObjectiveMaxDelay, SLAObjectiveMinSuccessRate}},
+		{name: "Late execution without max delay", status: JobSLAStatus{SLA: JobSLA{MinSuccessRate: null.FloatFrom(90)}, Executions: 1, Successful: 1, Late: 1},
+			successRate: null.FloatFrom(100), breaches: []SLAObjective{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.status.Evaluate()
+			assert.Equal(t, tc.successRate, tc.status.SuccessRate)
+			assert.Equal(t, tc.breaches, tc.status.Breaches)
+			assert.Equal(t, len(tc.breaches) == 0, tc.status.Compliant)
+		})
+	}
+}
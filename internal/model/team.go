@@ -0,0 +1,86 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// maxOwnerLength limits the length of the owner of a job.
+const maxOwnerLength = 128
+
+// Principal is the API key a request is made with, as far as the ownership of the jobs is concerned.
+type Principal struct {
+	// Name of the API key, the default owner of the jobs it creates
+	Name string
+	// Team of the API key, the default team of the jobs it creates
+	Team string
+	// Restricted principals can only access the jobs of their team, i.e. team-bound non-admin API keys
+	Restricted bool
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context attributing job service calls to the principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of the context. Without one, e.g. when authentication is disabled,
+// the zero principal is returned, which is not restricted.
+func PrincipalFromContext(ctx context.Context) Principal {
+	principal, _ := ctx.Value(principalKey{}).(Principal)
+	return principal
+}
+
+// CanAccess reports whether the principal can view and modify the job.
+func (p Principal) CanAccess(job *Job) bool {
+	return !p.Restricted || job.Team.String == p.Team
+}
+
+// TeamScope returns the team the jobs accessed by the principal are restricted to, empty if they aren't.
+func (p Principal) TeamScope() string {
+	if p.Restricted {
+		return p.Team
+	}
+
+	return ""
+}
+
+// AssignJob sets the owner and team of a job created or modified by the principal, unless the job sets them.
+// Restricted principals can only create jobs of their team, and can't move jobs to another team.
+func (p Principal) AssignJob(job *Job) error {
+	if !job.Owner.Valid && p.Name != "" {
+		job.Owner.SetValid(p.Name)
+	}
+
+	if !job.Team.Valid && p.Team != "" {
+		job.Team.SetValid(p.Team)
+	}
+
+	if !p.CanAccess(job) {
+		return error2.ErrForbidden
+	}
+
+	return nil
+}
+
+// ValidateTeam validates a team name, following the same rules as namespaces.
+func ValidateTeam(team string) error {
+	if !namespacePattern.MatchString(team) {
+		return error2.ErrInvalidTeam
+	}
+
+	return nil
+}
+
+// ValidateOwner validates the owner of a job: a non-blank name of at most 128 characters, e.g. a user or a service.
+func ValidateOwner(owner string) error {
+	if strings.TrimSpace(owner) == "" || utf8.RuneCountInString(owner) > maxOwnerLength {
+		return error2.ErrInvalidJobOwner
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestPrincipalAssignJob(t *testing.T) {
+	restricted := Principal{Name: "ci", Team: "payments", Restricted: true}
+	admin := Principal{Name: "ops", Team: "platform"}
+
+	tests := []struct {
+		name      string
+		principal Principal
+		job       Job
+		wantOwner null.String
+		wantTeam  null.String
+		wantErr   error
+	}{
+		{name: "defaults to the principal", principal: restricted, job: Job{},
+			wantOwner: null.StringFrom("ci"), wantTeam: null.StringFrom("payments")},
+		{name: "keeps the owner of the job", principal: restricted, job: Job{Owner: null.StringFrom("alice"), Team: null.StringFrom("payments")},
+			wantOwner: null.StringFrom("alice"), wantTeam: null.StringFrom("payments")},
+		{name: "restricted principal can't assign another team", principal: restricted, job: Job{Team: null.StringFrom("billing")},
+			wantOwner: null.StringFrom("ci"), wantTeam: null.StringFrom("billing"), wantErr: error2.ErrForbidden},
+		{name: "unrestricted principal can assign another team", principal: admin, job: Job{Team: null.StringFrom("billing")},
+			wantOwner: null.StringFrom("ops"), wantTeam: null.StringFrom("billing")},
+		{name: "no principal", principal: Principal{}, job: Job{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.principal.AssignJob(&tc.job)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantOwner, tc.job.Owner)
+			assert.Equal(t, tc.wantTeam, tc.job.Team)
+		})
+	}
+}
+
+func TestPrincipalCanAccess(t *testing.T) {
+	restricted := Principal{Team: "payments", Restricted: true}
+
+	assert.True(t, restricted.CanAccess(&Job{Team: null.StringFrom("payments")}))
+	assert.False(t, restricted.CanAccess(&Job{Team: null.StringFrom("billing")}))
+	assert.False(t, restricted.CanAccess(&Job{}))
+	assert.True(t, Principal{Team: "payments"}.CanAccess(&Job{Team: null.StringFrom("billing")}))
+
+	assert.Equal(t, "payments", restricted.TeamScope())
+	assert.Empty(t, Principal{Team: "payments"}.TeamScope())
+}
+
+func TestAPIKeyPrincipal(t *testing.T) {
+	assert.Equal(t, Principal{Name: "ci", Team: "payments", Restricted: true},
+		(&APIKey{Name: "ci", Team: null.StringFrom("payments")}).Principal())
+	assert.Equal(t, Principal{Name: "ops", Team: "payments"},
+		(&APIKey{Name: "ops", Admin: true, Team: null.StringFrom("payments")}).Principal())
+	assert.Equal(t, Principal{Name: "user"}, (&APIKey{Name: "user"}).Principal())
+}
+
+func TestValidateOwner(t *testing.T) {
+	assert.NoError(t, ValidateOwner("alice@example.com"))
+	assert.Equal(t, error2.ErrInvalidJobOwner, ValidateOwner(" "))
+	assert.Equal(t, error2.ErrInvalidJobOwner, ValidateOwner(strings.Repeat("a", 129)))
+
+	assert.NoError(t, ValidateTeam("payments"))
+	assert.Equal(t, error2.ErrInvalidTeam, ValidateTeam("Payments"))
+}
@@ -0,0 +1,250 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
+	"gopkg.in/guregu/null.v4"
+)
+
+// TemplateTagKey is the key of the label added to jobs instantiated from a template, e.g. template=nightly-sync,
+// so all jobs of a template can be listed or updated with a tag selector.
+const TemplateTagKey = "template"
+
+// maxTemplateParameters limits the number of parameters of a template.
+const maxTemplateParameters = 64
+
+var (
+	templateParameterPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+	// placeholders are written {{name}}, optionally with spaces around the name
+	templatePlaceholderPattern = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+)
+
+// JobTemplate is a named job definition with {{parameter}} placeholders in its string values, from which
+// jobs are instantiated with the values of the parameters. Since the definition can contain credentials,
+// it is encrypted at rest.
+//
+// swagger:model JobTemplate
+type JobTemplate struct {
+	// Name of the template, unique within its namespace
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Description string `json:"description,omitempty"`
+
+	Parameters []TemplateParameter `json:"parameters"`
+	// Job definition, with the fields of a job create request
+	Definition json.RawMessage `json:"definition" swaggertype:"object"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TemplateParameter is a parameter of a template. Parameters without a default value are required.
+type TemplateParameter struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Default     null.String `json:"default,omitempty" swaggertype:"string"`
+}
+
+// swagger:model JobTemplateCreate
+type JobTemplateCreate struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []TemplateParameter `json:"parameters"`
+	Definition  json.RawMessage     `json:"definition" swaggertype:"object"`
+}
+
+func (t *JobTemplateCreate) ToTemplate() *JobTemplate {
+	now := time.Now()
+
+	template := &JobTemplate{
+		Name:        t.Name,
+		Namespace:   DefaultNamespace,
+		Description: t.Description,
+		Parameters:  t.Parameters,
+		Definition:  t.Definition,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if template.Parameters == nil {
+		template.Parameters = []TemplateParameter{}
+	}
+
+	return template
+}
+
+// JobFromTemplate instantiates a job from a template.
+//
+// swagger:model JobFromTemplate
+type JobFromTemplate struct {
+	// Name of the template
+	Template string `json:"template"`
+	// Values of the parameters of the template, parameters with a default value can be omitted
+	Parameters map[string]string `json:"parameters"`
+	// Metadata added to the metadata of the definition of the template, e.g. the ticket the job was created for
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate validates a JobTemplate struct: the definition must be a JSON object whose placeholders are all
+// declared parameters. Jobs are validated when they are instantiated, once the placeholders are replaced.
+func (t *JobTemplate) Validate() error {
+	if err := ValidateJobName(t.Name); err != nil {
+		return error2.ErrInvalidTemplate
+	}
+
+	if len(t.Parameters) > maxTemplateParameters {
+		return error2.ErrInvalidTemplate
+	}
+
+	declared := make(map[string]bool, len(t.Parameters))
+	for _, parameter := range t.Parameters {
+		if !templateParameterPattern.MatchString(parameter.Name) || declared[parameter.Name] {
+			return error2.ErrInvalidTemplate
+		}
+		declared[parameter.Name] = true
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(t.Definition, &document); err != nil || document == nil {
+		return error2.ErrInvalidTemplate
+	}
+
+	valid := true
+	walkTemplateStrings(document, func(value string) string {
+		for _, match := range templatePlaceholderPattern.FindAllStringSubmatch(value, -1) {
+			if !declared[match[1]] {
+				valid = false
+			}
+		}
+		return value
+	})
+	if !valid {
+		return error2.ErrInvalidTemplate
+	}
+
+	return nil
+}
+
+// Render returns the definition of the template with its placeholders replaced by the values of the parameters,
+// or their default values. Values are substituted in string values and object keys only, so they can't change
+// the structure of the definition. The job is labeled with the name of the template.
+func (t *JobTemplate) Render(values map[string]string) (JobCreate, error) {
+	resolved := make(map[string]string, len(t.Parameters))
+	for _, parameter := range t.Parameters {
+		value, ok := values[parameter.Name]
+		if !ok {
+			if !parameter.Default.Valid {
+				return JobCreate{}, error2.ErrInvalidTemplateParameters
+			}
+			value = parameter.Default.String
+		}
+		resolved[parameter.Name] = value
+	}
+
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return JobCreate{}, error2.ErrInvalidTemplateParameters
+		}
+	}
+
+	definition, err := t.render(resolved)
+	if err != nil {
+		return JobCreate{}, err
+	}
+
+	if !hasLabel(definition.Tags, TemplateTagKey, t.Name) {
+		definition.Tags = append(definition.Tags, Label{Key: TemplateTagKey, Value: t.Name}.String())
+	}
+
+	return definition, nil
+}
+
+// render returns the definition with its placeholders replaced by the resolved values of the parameters.
+func (t *JobTemplate) render(resolved map[string]string) (JobCreate, error) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(t.Definition, &document); err != nil {
+		return JobCreate{}, error2.ErrInvalidTemplate
+	}
+
+	rendered, err := json.Marshal(walkTemplateStrings(document, func(value string) string {
+		return templatePlaceholderPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+			return resolved[templatePlaceholderPattern.FindStringSubmatch(placeholder)[1]]
+		})
+	}))
+	if err != nil {
+		return JobCreate{}, err
+	}
+
+	definition := JobCreate{}
+	if err := json.Unmarshal(rendered, &definition); err != nil {
+		return JobCreate{}, error2.ErrInvalidTemplateParameters
+	}
+
+	return definition, nil
+}
+
+// validPlaceholders reports whether the definition is a JSON object whose placeholders are all allowed.
+func validPlaceholders(definition json.RawMessage, allowed map[string]bool) bool {
+	var document map[string]interface{}
+	if err := json.Unmarshal(definition, &document); err != nil || document == nil {
+		return false
+	}
+
+	valid := true
+	walkTemplateStrings(document, func(value string) string {
+		for _, match := range templatePlaceholderPattern.FindAllStringSubmatch(value, -1) {
+			if !allowed[match[1]] {
+				valid = false
+			}
+		}
+		return value
+	})
+
+	return valid
+}
+
+// walkTemplateStrings replaces the string values and object keys of the decoded JSON document with the result of fn.
+func walkTemplateStrings(value interface{}, fn func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = walkTemplateStrings(item, fn)
+		}
+		return v
+	case map[string]interface{}:
+		walked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			walked[fn(key)] = walkTemplateStrings(item, fn)
+		}
+		return walked
+	}
+
+	return value
+}
+
+// RemoveCredentials removes the credentials from the definition of the template, when returning it to the user.
+// Credentials given by placeholders are kept, since they don't hold the actual values.
+func (t *JobTemplate) RemoveCredentials() {
+	var document map[string]interface{}
+	if err := json.Unmarshal(t.Definition, &document); err != nil {
+		return
+	}
+
+	isPlaceholder := func(value interface{}) bool {
+		s, ok := value.(string)
+		return ok && templatePlaceholderPattern.MatchString(s)
+	}
+
+	redact.Document(document, reflect.TypeOf(JobCreate{}), isPlaceholder)
+
+	if encoded, err := json.Marshal(document); err == nil {
+		t.Definition = encoded
+	}
+}
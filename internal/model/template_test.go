@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testTemplate() *JobTemplate {
+	return (&JobTemplateCreate{
+		Name: "customer-sync",
+		Parameters: []TemplateParameter{
+			{Name: "customer"},
+			{Name: "schedule", Default: null.StringFrom("0 * * * *")},
+		},
+		Definition: json.RawMessage(`{
+			"name": "sync-{{ customer }}",
+			"type": "HTTP",
+			"cron_schedule": "{{schedule}}",
+			"http_job": {
+				"url": "https://example.com/customers/{{customer}}/sync",
+				"method": "POST",
+				"headers": {"X-Customer-{{customer}}": "true"},
+				"auth": {"type": "basic", "username": "admin", "password": "secret"}
+			},
+			"tags": ["sync"],
+			"metadata": {"customer": "{{customer}}", "runbook": "https://wiki.example.com/sync"}
+		}`),
+	}).ToTemplate()
+}
+
+func TestJobTemplateValidate(t *testing.T) {
+	assert.NoError(t, testTemplate().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(template *JobTemplate)
+	}{
+		{name: "invalid name", modify: func(template *JobTemplate) { template.Name = "customer sync" }},
+		{name: "invalid parameter name", modify: func(template *JobTemplate) { template.Parameters[0].Name = "customer-id" }},
+		{name: "duplicate parameter", modify: func(template *JobTemplate) { template.Parameters[1].Name = "customer" }},
+		{name: "undeclared placeholder", modify: func(template *JobTemplate) { template.Parameters = template.Parameters[1:] }},
+		{name: "definition is not an object", modify: func(template *JobTemplate) { template.Definition = json.RawMessage(`[]`) }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			template := testTemplate()
+			tc.modify(template)
+			assert.Equal(t, error2.ErrInvalidTemplate, template.Validate())
+		})
+	}
+}
+
+func TestJobTemplateRender(t *testing.T) {
+	definition, err := testTemplate().Render(map[string]string{"customer": "acme"})
+	require.NoError(t, err)
+
+	assert.Equal(t, null.StringFrom("sync-acme"), definition.Name)
+	assert.Equal(t, null.StringFrom("0 * * * *"), definition.CronSchedule)
+	assert.Equal(t, "https://example.com/customers/acme/sync", definition.HTTPJob.URL)
+	assert.Equal(t, map[string]string{"X-Customer-acme": "true"}, definition.HTTPJob.Headers)
+	assert.Equal(t, []string{"sync", "template=customer-sync"}, definition.Tags)
+	assert.Equal(t, map[string]string{"customer": "acme", "runbook": "https://wiki.example.com/sync"}, definition.Metadata)
+
+	definition, err = testTemplate().Render(map[string]string{"customer": `"quoted"`, "schedule": "@daily"})
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom(`sync-"quoted"`), definition.Name)
+	assert.Equal(t, null.StringFrom("@daily"), definition.CronSchedule)
+
+	_, err = testTemplate().Render(map[string]string{})
+	assert.Equal(t, error2.ErrInvalidTemplateParameters, err)
+
+	_, err = testTemplate().Render(map[string]string{"customer": "acme", "region": "eu"})
+	assert.Equal(t, error2.ErrInvalidTemplateParameters, err)
+}
+
+func TestJobTemplateRemoveCredentials(t *testing.T) {
+	template := testTemplate()
+	template.RemoveCredentials()
+
+	definition, err := template.Render(map[string]string{"customer": "acme"})
+	require.NoError(t, err)
+	assert.False(t, definition.HTTPJob.Auth.Username.Valid)
+	assert.False(t, definition.HTTPJob.Auth.Password.Valid)
+
+	template.Definition = json.RawMessage(`{"http_job": {"auth": {"type": "bearer", "bearer_token": "{{token}}"}}}`)
+	template.RemoveCredentials()
+	assert.JSONEq(t, `{"http_job": {"auth": {"type": "bearer", "bearer_token": "{{token}}"}}}`, string(template.Definition))
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobWakeupHorizon is how far ahead runners are notified of the next run of a job. Jobs due later are
+// picked up by the regular polling of the runners.
+const JobWakeupHorizon = time.Minute
+
+// JobWakeup notifies the runners that a job was created or updated with a near-term next run,
+// so they can fetch it right when it is due instead of waiting for their next poll.
+type JobWakeup struct {
+	JobID     uuid.UUID `json:"job_id"`
+	Namespace string    `json:"namespace"`
+	NextRun   time.Time `json:"next_run"`
+	// Capabilities required by the job, runners without them ignore the wakeup
+	RequiredCapabilities []string `json:"required_capabilities,omitempty"`
+}
+
+// NewJobWakeup returns the wakeup for the job, or false if the job is not scheduled to run within the horizon.
+func NewJobWakeup(job *Job, now time.Time) (JobWakeup, bool) {
+	if job.Status != JobStatusRunning || !job.NextRun.Valid || job.NextRun.Time.After(now.Add(JobWakeupHorizon)) {
+		return JobWakeup{}, false
+	}
+
+	return JobWakeup{
+		JobID:                job.ID,
+		Namespace:            job.Namespace,
+		NextRun:              job.NextRun.Time,
+		RequiredCapabilities: job.RequiredCapabilities,
+	}, true
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestNewJobWakeup(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		job    Job
+		wakeUp bool
+	}{
+		{name: "due now", job: Job{Status: JobStatusRunning, NextRun: null.TimeFrom(now)}, wakeUp: true},
+		{name: "overdue", job: Job{Status: JobStatusRunning, NextRun: null.TimeFrom(now.Add(-time.Hour))}, wakeUp: true},
+		{name: "due within the horizon", job: Job{Status: JobStatusRunning, NextRun: null.TimeFrom(now.Add(JobWakeupHorizon))}, wakeUp: true},
+		{name: "due after the horizon", job: Job{Status: JobStatusRunning, NextRun: null.TimeFrom(now.Add(JobWakeupHorizon + time.Second))}},
+		{name: "not scheduled", job: Job{Status: JobStatusRunning}},
+		{name: "with required capabilities", job: Job{Status: JobStatusRunning, NextRun: null.TimeFrom(now), RequiredCapabilities: []string{"gpu"}}, wakeUp: true},
+		{name: "paused", job: Job{Status: JobStatusStopped, NextRun: null.TimeFrom(now)}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.job.ID = uuid.New()
+			tc.job.Namespace = DefaultNamespace
+
+			wakeup, ok := NewJobWakeup(&tc.job, now)
+			assert.Equal(t, tc.wakeUp, ok)
+			if ok {
+				assert.Equal(t, JobWakeup{
+					JobID:                tc.job.ID,
+					Namespace:            DefaultNamespace,
+					NextRun:              tc.job.NextRun.Time,
+					RequiredCapabilities: tc.job.RequiredCapabilities,
+				}, wakeup)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package model
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+const minWebhookSecretLength = 16
+
+// WebhookFormat is the format of the payloads delivered to a webhook.
+type WebhookFormat string
+
+const (
+	// WebhookFormatJSON delivers the events as they are, as JSON.
+	WebhookFormatJSON WebhookFormat = "json"
+	// WebhookFormatCloudEvents delivers the events as CloudEvents 1.0 in the structured JSON mode, for consumers such
+	// as Knative or EventBridge.
+	WebhookFormatCloudEvents WebhookFormat = "cloudevents"
+)
+
+// Valid reports whether the format is known.
+func (f WebhookFormat) Valid() bool {
+	return f == WebhookFormatJSON || f == WebhookFormatCloudEvents
+}
+
+// Webhook receives job lifecycle events as HTTP POST requests, signed with the webhook secret.
+//
+// swagger:model Webhook
+type Webhook struct {
+	ID uuid.UUID `json:"id"`
+	// Only events of jobs in the namespace of the webhook are delivered
+	Namespace string `json:"namespace"`
+	// Only events of jobs of the team are delivered, if set. Set to the team of the API key bound to a team that
+	// created the webhook.
+	Team null.String `json:"team,omitempty" swaggertype:"string"`
+	URL  string      `json:"url"`
+	// Secret used to sign the deliveries with HMAC-SHA256. It is never returned by the API.
+	Secret string `json:"secret,omitempty" redact:"true"`
+
+	// Event types the webhook receives (all if empty)
+	EventTypes []EventType `json:"event_types"`
+	// Only events for jobs with all of the tags are delivered
+	Tags []string `json:"tags"`
+	// Only events for jobs matching the tag selector expression are delivered, e.g. "env=prod AND team=payments"
+	Selector string `json:"selector,omitempty"`
+	// Format of the payloads: json (default) or cloudevents
+	Format WebhookFormat `json:"format"`
+
+	Enabled bool `json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model WebhookCreate
+type WebhookCreate struct {
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret" redact:"true"`
+	EventTypes []EventType `json:"event_types"`
+	Tags       []string    `json:"tags"`
+	Selector   string      `json:"selector,omitempty"`
+	// Format of the payloads: json (default) or cloudevents
+	Format WebhookFormat `json:"format,omitempty"`
+}
+
+func (w *WebhookCreate) ToWebhook() *Webhook {
+	now := time.Now()
+
+	webhook := &Webhook{
+		ID:         uuid.New(),
+		Namespace:  DefaultNamespace,
+		URL:        w.URL,
+		Secret:     w.Secret,
+		EventTypes: w.EventTypes,
+		Tags:       w.Tags,
+		Selector:   w.Selector,
+		Format:     w.Format,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []EventType{}
+	}
+
+	if webhook.Tags == nil {
+		webhook.Tags = []string{}
+	}
+
+	if webhook.Format == "" {
+		webhook.Format = WebhookFormatJSON
+	}
+
+	return webhook
+}
+
+// Validate validates a Webhook struct.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return error2.ErrInvalidWebhookURL
+	}
+
+	if len(w.Secret) < minWebhookSecretLength {
+		return error2.ErrInvalidWebhookSecret
+	}
+
+	for _, eventType := range w.EventTypes {
+		if !eventType.Valid() {
+			return error2.ErrInvalidEventFilter
+		}
+	}
+
+	if _, err := ParseTagSelector(w.Selector); err != nil {
+		return err
+	}
+
+	if !w.Format.Valid() {
+		return error2.ErrInvalidWebhookFormat
+	}
+
+	return nil
+}
+
+// MatchesSelector reports whether the event is for a job matching the tag selector of the webhook.
+// Events of bulk operations only carry the tags the jobs were selected by.
+func (w *Webhook) MatchesSelector(event Event) bool {
+	selector, err := ParseTagSelector(w.Selector)
+	return err == nil && selector.Matches(event.Tags, event.Metadata)
+}
+
+// RemoveCredentials removes the secret from the webhook, when returning it to the user.
+func (w *Webhook) RemoveCredentials() {
+	redact.Apply(w)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery is an attempted delivery of an event to a webhook.
+type WebhookDelivery struct {
+	ID        int                   `json:"id"`
+	WebhookID uuid.UUID             `json:"webhook_id"`
+	EventID   uuid.UUID             `json:"event_id"`
+	EventType EventType             `json:"event_type"`
+	Payload   json.RawMessage       `json:"payload" swaggertype:"object"`
+	Status    WebhookDeliveryStatus `json:"status"`
+	Attempts  int                   `json:"attempts"`
+
+	// Outcome of the last attempt
+	ResponseCode null.Int    `json:"response_code,omitempty" swaggertype:"integer"`
+	Error        null.String `json:"error,omitempty" swaggertype:"string"`
+
+	// When the next attempt is made, for pending deliveries
+	NextAttemptAt null.Time `json:"next_attempt_at,omitempty" swaggertype:"string"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// swagger:model WebhookDeliveryPage
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Pagination
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook WebhookCreate
+		want    error
+	}{
+		{name: "valid webhook", webhook: WebhookCreate{URL: "https://example.com/hooks", Secret: "averylongsecret!"}, want: nil},
+		{name: "valid webhook with event types", webhook: WebhookCreate{
+			URL:        "http://example.com/hooks",
+			Secret:     "averylongsecret!",
+			EventTypes: []EventType{EventExecutionFailed},
+		}, want: nil},
+		{name: "relative URL", webhook: WebhookCreate{URL: "/hooks", Secret: "averylongsecret!"}, want: error2.ErrInvalidWebhookURL},
+		{name: "unsupported scheme", webhook: WebhookCreate{URL: "ftp://example.com", Secret: "averylongsecret!"}, want: error2.ErrInvalidWebhookURL},
+		{name: "short secret", webhook: WebhookCreate{URL: "https://example.com/hooks", Secret: "short"}, want: error2.ErrInvalidWebhookSecret},
+		{name: "invalid event type", webhook: WebhookCreate{
+			URL:        "https://example.com/hooks",
+			Secret:     "averylongsecret!",
+			EventTypes: []EventType{"job.exploded"},
+		}, want: error2.ErrInvalidEventFilter},
+		{name: "valid selector", webhook: WebhookCreate{
+			URL:      "https://example.com/hooks",
+			Secret:   "averylongsecret!",
+			Selector: "env=prod AND !canary",
+		}, want: nil},
+		{name: "invalid selector", webhook: WebhookCreate{
+			URL:      "https://example.com/hooks",
+			Secret:   "averylongsecret!",
+			Selector: "env=prod OR env=dev",
+		}, want: error2.ErrInvalidTagSelector},
+		{name: "cloudevents format", webhook: WebhookCreate{
+			URL:    "https://example.com/hooks",
+			Secret: "averylongsecret!",
+			Format: WebhookFormatCloudEvents,
+		}, want: nil},
+		{name: "unknown format", webhook: WebhookCreate{
+			URL:    "https://example.com/hooks",
+			Secret: "averylongsecret!",
+			Format: "xml",
+		}, want: error2.ErrInvalidWebhookFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.webhook.ToWebhook().Validate())
+		})
+	}
+}
+
+func TestWebhookMatchesSelector(t *testing.T) {
+	event := Event{Type: EventJobUpdated, Tags: []string{"env=prod", "team=payments"}, Metadata: map[string]string{"ticket": "OPS-1234"}}
+
+	assert.True(t, (&Webhook{}).MatchesSelector(event))
+	assert.True(t, (&Webhook{Selector: "env=prod AND team"}).MatchesSelector(event))
+	assert.False(t, (&Webhook{Selector: "env=dev"}).MatchesSelector(event))
+	assert.True(t, (&Webhook{Selector: "env=prod AND metadata.ticket=OPS-1234"}).MatchesSelector(event))
+	assert.False(t, (&Webhook{Selector: "metadata.cost-center"}).MatchesSelector(event))
+}
@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// AutoDisableSettings configure the stopping of the jobs failing repeatedly, which the notification rules with the
+// DISABLED trigger notify of.
+type AutoDisableSettings struct {
+	// Failures is the number of executions in a row after which a failing job is stopped, never if 0
+	Failures int `mapstructure:"failures" yaml:"failures" json:"failures"`
+}
+
+type JobService interface {
+	DisableFailingJob(ctx context.Context, event model.Event, failures int) error
+}
+
+// AutoDisable returns the sink of the outbox relay stopping the job of a failed execution once it failed the
+// configured number of times in a row.
+func AutoDisable(jobService JobService, settings AutoDisableSettings) func(ctx context.Context, event model.Event) error {
+	return func(ctx context.Context, event model.Event) error {
+		return jobService.DisableFailingJob(ctx, event, settings.Failures)
+	}
+}
@@ -0,0 +1,229 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+)
+
+// SMTPSettings configure the SMTP server sending the email notifications.
+type SMTPSettings struct {
+	// Host of the SMTP server, the email notifications fail if it is empty
+	Host string `mapstructure:"host" yaml:"host" json:"host,omitempty"`
+	Port int    `mapstructure:"port" yaml:"port" json:"port,omitempty"`
+	// Username and Password authenticate with PLAIN auth, if the username is set
+	Username string `mapstructure:"username" yaml:"username" json:"username,omitempty"`
+	Password string `mapstructure:"password" yaml:"password" json:"-" redact:"true"`
+	// From is the sender of the emails
+	From string `mapstructure:"from" yaml:"from" json:"from,omitempty"`
+}
+
+// message is a notification rendered for people, along with the event it is about.
+type message struct {
+	RuleID     uuid.UUID                 `json:"rule_id"`
+	Trigger    model.NotificationTrigger `json:"trigger"`
+	Suppressed int                       `json:"suppressed"`
+	Subject    string                    `json:"subject"`
+	Text       string                    `json:"text"`
+	Event      json.RawMessage           `json:"event"`
+}
+
+func newMessage(rule *model.NotificationRule, notification model.Notification) (*message, error) {
+	var event model.Event
+	if err := json.Unmarshal(notification.Event, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	job := fmt.Sprintf("Job %s of namespace %s", event.JobID, event.Namespace)
+
+	var subject string
+	switch notification.Trigger {
+	case model.NotificationTriggerFailure:
+		subject = job + " failed"
+	case model.NotificationTriggerRecovery:
+		subject = job + " recovered"
+	case model.NotificationTriggerDisabled:
+		subject = job + " was disabled"
+	default:
+		subject = fmt.Sprintf("%s: %s", job, event.Type)
+	}
+
+	text := subject
+	if event.ExecutionID != nil {
+		text += fmt.Sprintf(" (execution %d)", *event.ExecutionID)
+	}
+	if event.Error != "" {
+		text += ": " + event.Error
+	}
+	if notification.Suppressed > 0 {
+		text += fmt.Sprintf("\n%d more failures since the previous notification were not notified.", notification.Suppressed)
+	}
+
+	if rule.Name != "" {
+		subject = fmt.Sprintf("[%s] %s", rule.Name, subject)
+	}
+
+	return &message{
+		RuleID:     rule.ID,
+		Trigger:    notification.Trigger,
+		Suppressed: notification.Suppressed,
+		Subject:    subject,
+		Text:       text,
+		Event:      notification.Event,
+	}, nil
+}
+
+// postSlack posts the message to a Slack incoming webhook.
+func (n *Notifier) postSlack(ctx context.Context, url string, msg *message) error {
+	return n.post(ctx, url, map[string]string{"text": msg.Text})
+}
+
+// postWebhook posts the message, with the event, as JSON to the URL.
+func (n *Notifier) postWebhook(ctx context.Context, url string, msg *message) error {
+	return n.post(ctx, url, msg)
+}
+
+func (n *Notifier) post(ctx context.Context, url string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+// sendEmail mails the message to the recipients through the SMTP server, upgrading the connection with STARTTLS if
+// the server supports it.
+func (n *Notifier) sendEmail(ctx context.Context, recipients []string, msg *message) error {
+	if n.smtp.Host == "" {
+		return fmt.Errorf("no SMTP server is configured")
+	}
+
+	// the SMTP commands take the bare addresses, the display names are only kept in the headers
+	from, err := envelopeAddress(n.smtp.From)
+	if err != nil {
+		return err
+	}
+	addresses := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		address, err := envelopeAddress(recipient)
+		if err != nil {
+			return err
+		}
+		addresses = append(addresses, address)
+	}
+
+	port := n.smtp.Port
+	if port == 0 {
+		port = 25
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.client.Timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(n.smtp.Host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.smtp.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.smtp.Host}); err != nil {
+			return err
+		}
+	}
+
+	if n.smtp.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, n.smtp.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if err := client.Rcpt(address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(emailBody(n.smtp.From, recipients, msg, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// envelopeAddress returns the bare address of an email address which may have a display name, e.g.
+// payments@example.com for "Payments <payments@example.com>".
+func envelopeAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", address, err)
+	}
+
+	return parsed.Address, nil
+}
+
+// emailBody returns the plain text email of the message.
+func emailBody(from string, recipients []string, msg *message, date time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(recipients, ", ") + "\r\n")
+	b.WriteString("Subject: " + strings.ReplaceAll(msg.Subject, "\n", " ") + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes()
+}
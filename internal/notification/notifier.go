@@ -0,0 +1,181 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/retry"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxErrorLength limits the size of the response body stored with a failed attempt.
+	maxErrorLength = 1024
+)
+
+// Notifier enqueues a notification for every notification rule firing on a job lifecycle event relayed from the
+// outbox, throttled per job, and sends them on Slack, by email or to webhooks, retrying failed notifications with an
+// exponential backoff.
+type Notifier struct {
+	notificationService NotificationService
+	log                 *otelzap.Logger
+	client              *http.Client
+	smtp                SMTPSettings
+	maxAttempts         int
+	interval            time.Duration
+	task                *periodic.Task
+}
+
+type NotificationService interface {
+	GetRule(ctx context.Context, id uuid.UUID) (*model.NotificationRule, error)
+	EnqueueNotifications(ctx context.Context, event model.Event) (int, error)
+	ClaimNotifications(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.Notification, error)
+	FinishNotification(ctx context.Context, notification model.Notification) error
+}
+
+type Config struct {
+	NotificationService NotificationService
+	// Client sends the Slack and webhook notifications, e.g. a client enforcing the egress policy of the manager. A
+	// client with the timeout of the settings is used if nil.
+	Client   *http.Client
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Interval between checks for due notifications
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// Number of attempts after which a notification is marked as failed
+	MaxAttempts int `mapstructure:"maxAttempts" yaml:"maxAttempts" json:"maxAttempts,omitempty"`
+	// Timeout of a single notification attempt
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout" json:"timeout,omitempty"`
+	// SMTP server sending the notifications of the email rules
+	SMTP SMTPSettings `mapstructure:"smtp" yaml:"smtp" json:"smtp"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Notifier, error) {
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Settings.Timeout}
+	}
+
+	n := &Notifier{
+		notificationService: cfg.NotificationService,
+		log:                 cfg.Log,
+		client:              client,
+		smtp:                cfg.Settings.SMTP,
+		maxAttempts:         max(cfg.Settings.MaxAttempts, 1),
+		interval:            cfg.Settings.Interval,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "notifier",
+		Interval: cfg.Settings.Interval,
+		Log:      cfg.Log,
+		Run:      n.sendDue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	n.task = task
+
+	return n, nil
+}
+
+// Start starts sending the enqueued notifications in a separate goroutine.
+// Only the first call will start the notifier, subsequent calls are ignored.
+func (n *Notifier) Start() {
+	n.task.Start()
+}
+
+// Stop stops the notifier and waits for the current notifications to be sent or the context to expire.
+func (n *Notifier) Stop(ctx context.Context) {
+	n.task.Stop(ctx)
+}
+
+// Enqueue enqueues a notification of the event for every rule firing on it. It is the sink of the outbox relay, so
+// the notifications are enqueued, and their throttling state updated, in the transaction removing the event from
+// the outbox.
+func (n *Notifier) Enqueue(ctx context.Context, event model.Event) error {
+	count, err := n.notificationService.EnqueueNotifications(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notifications of event %s: %w", event.ID, err)
+	}
+
+	if count > 0 {
+		n.log.Debug("Enqueued notifications", zap.Any("event_id", event.ID), zap.Int("count", count))
+	}
+	return nil
+}
+
+func (n *Notifier) sendDue(ctx context.Context) {
+	send := func(notification model.Notification) { n.send(ctx, notification) }
+	if err := retry.AttemptDue(ctx, n.client.Timeout, n.interval, n.notificationService.ClaimNotifications, send); err != nil {
+		n.log.Error("Failed to claim notifications", zap.Error(err))
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, notification model.Notification) {
+	rule, err := n.notificationService.GetRule(ctx, notification.RuleID)
+	if err != nil {
+		n.log.Error("Failed to get notification rule", zap.Any("rule_id", notification.RuleID), zap.Error(err))
+		return
+	}
+
+	notification.Attempts++
+	err = n.sendTo(ctx, rule, notification)
+
+	result := retry.NewResult(notification.Attempts, n.maxAttempts, err)
+	notification.Error = result.Error
+	notification.NextAttemptAt = result.NextAttemptAt
+
+	switch result.Outcome {
+	case retry.Succeeded:
+		notification.Status = model.NotificationStatusSent
+	case retry.Failed:
+		notification.Status = model.NotificationStatusFailed
+	}
+
+	if err != nil {
+		n.log.Warn("Failed to send notification", zap.Any("rule_id", rule.ID), zap.Int("notification_id", notification.ID),
+			zap.Int("attempt", notification.Attempts), zap.Error(err))
+	}
+
+	// record the outcome even if the notifier is stopping
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	if err := n.notificationService.FinishNotification(ctx, notification); err != nil {
+		n.log.Error("Failed to update notification", zap.Int("notification_id", notification.ID), zap.Error(err))
+	}
+}
+
+func (n *Notifier) sendTo(ctx context.Context, rule *model.NotificationRule, notification model.Notification) error {
+	msg, err := newMessage(rule, notification)
+	if err != nil {
+		return err
+	}
+
+	switch rule.Channel {
+	case model.NotificationChannelSlack:
+		return n.postSlack(ctx, rule.URL, msg)
+	case model.NotificationChannelWebhook:
+		return n.postWebhook(ctx, rule.URL, msg)
+	case model.NotificationChannelEmail:
+		return n.sendEmail(ctx, rule.Recipients, msg)
+	default:
+		return fmt.Errorf("unknown notification channel %q", rule.Channel)
+	}
+}
@@ -0,0 +1,345 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockNotificationService struct {
+	sync.Mutex
+	rule          *model.NotificationRule
+	notifications map[int]model.Notification
+	lastID        int
+}
+
+func (m *mockNotificationService) GetRule(_ context.Context, _ uuid.UUID) (*model.NotificationRule, error) {
+	return m.rule, nil
+}
+
+func (m *mockNotificationService) EnqueueNotifications(_ context.Context, event model.Event) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	trigger, ok := model.NotificationTriggerOf(event)
+	if !ok {
+		return 0, nil
+	}
+
+	payload, _ := json.Marshal(event)
+	m.lastID++
+	m.notifications[m.lastID] = model.Notification{
+		ID:         m.lastID,
+		RuleID:     m.rule.ID,
+		JobID:      event.JobID,
+		Trigger:    trigger,
+		Event:      payload,
+		Suppressed: 3,
+		Status:     model.NotificationStatusPending,
+	}
+
+	return 1, nil
+}
+
+func (m *mockNotificationService) ClaimNotifications(_ context.Context, at time.Time, _ time.Time, _ uint) ([]model.Notification, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	var due []model.Notification
+	for _, notification := range m.notifications {
+		if notification.Status == model.NotificationStatusPending && !notification.NextAttemptAt.Time.After(at) {
+			due = append(due, notification)
+		}
+	}
+
+	return due, nil
+}
+
+func (m *mockNotificationService) FinishNotification(_ context.Context, notification model.Notification) error {
+	m.Lock()
+	defer m.Unlock()
+
+	// retry immediately in tests
+	notification.NextAttemptAt.Time = time.Time{}
+	m.notifications[notification.ID] = notification
+
+	return nil
+}
+
+func createNotifier(t *testing.T, rule *model.NotificationRule, maxAttempts int) (*Notifier, *mockNotificationService) {
+	notificationService := &mockNotificationService{
+		rule:          rule,
+		notifications: map[int]model.Notification{},
+	}
+	zapL, _ := zap.NewDevelopment()
+
+	n, err := New(Config{
+		NotificationService: notificationService,
+		Log:                 otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:     true,
+			Interval:    time.Millisecond * 20,
+			MaxAttempts: maxAttempts,
+			Timeout:     time.Second,
+		},
+	})
+	require.NoError(t, err)
+
+	return n, notificationService
+}
+
+func TestNotifier(t *testing.T) {
+	executionID := 42
+	event := model.Event{
+		ID:          uuid.New(),
+		Type:        model.EventExecutionFailed,
+		Time:        time.Now(),
+		JobID:       uuid.New(),
+		Namespace:   model.DefaultNamespace,
+		ExecutionID: &executionID,
+		Error:       "unexpected status code 503",
+	}
+
+	receive := func() (*httptest.Server, func() []byte) {
+		var (
+			mu   sync.Mutex
+			body []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			body, _ = io.ReadAll(r.Body)
+		}))
+
+		return server, func() []byte {
+			mu.Lock()
+			defer mu.Unlock()
+			return body
+		}
+	}
+
+	t.Run("Slack notification", func(t *testing.T) {
+		server, body := receive()
+		defer server.Close()
+
+		rule := &model.NotificationRule{ID: uuid.New(), Name: "payments", Channel: model.NotificationChannelSlack, URL: server.URL}
+		n, notificationService := createNotifier(t, rule, 3)
+		n.Start()
+		require.NoError(t, n.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			notificationService.Lock()
+			defer notificationService.Unlock()
+			return notificationService.notifications[1].Status == model.NotificationStatusSent
+		}, time.Second, time.Millisecond*10)
+		n.Stop(context.Background())
+
+		var received map[string]string
+		require.NoError(t, json.Unmarshal(body(), &received))
+		assert.Contains(t, received["text"], event.JobID.String()+" of namespace default failed (execution 42): unexpected status code 503")
+		assert.Contains(t, received["text"], "3 more failures")
+	})
+
+	t.Run("Webhook notification", func(t *testing.T) {
+		server, body := receive()
+		defer server.Close()
+
+		rule := &model.NotificationRule{ID: uuid.New(), Channel: model.NotificationChannelWebhook, URL: server.URL}
+		n, notificationService := createNotifier(t, rule, 3)
+		n.Start()
+		require.NoError(t, n.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			notificationService.Lock()
+			defer notificationService.Unlock()
+			return notificationService.notifications[1].Status == model.NotificationStatusSent
+		}, time.Second, time.Millisecond*10)
+		n.Stop(context.Background())
+
+		var received struct {
+			RuleID     uuid.UUID                 `json:"rule_id"`
+			Trigger    model.NotificationTrigger `json:"trigger"`
+			Suppressed int                       `json:"suppressed"`
+			Event      model.Event               `json:"event"`
+		}
+		require.NoError(t, json.Unmarshal(body(), &received))
+		assert.Equal(t, rule.ID, received.RuleID)
+		assert.Equal(t, model.NotificationTriggerFailure, received.Trigger)
+		assert.Equal(t, 3, received.Suppressed)
+		assert.Equal(t, event.ID, received.Event.ID)
+	})
+
+	t.Run("Failed notification", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		rule := &model.NotificationRule{ID: uuid.New(), Channel: model.NotificationChannelSlack, URL: server.URL}
+		n, notificationService := createNotifier(t, rule, 2)
+		n.Start()
+		require.NoError(t, n.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			notificationService.Lock()
+			defer notificationService.Unlock()
+			return notificationService.notifications[1].Status == model.NotificationStatusFailed
+		}, time.Second, time.Millisecond*10)
+		n.Stop(context.Background())
+
+		notificationService.Lock()
+		defer notificationService.Unlock()
+		notification := notificationService.notifications[1]
+		assert.Equal(t, 2, notification.Attempts)
+		assert.True(t, notification.Error.Valid)
+	})
+
+	t.Run("Destination denied by the egress policy", func(t *testing.T) {
+		server, body := receive()
+		defer server.Close()
+
+		rule := &model.NotificationRule{ID: uuid.New(), Channel: model.NotificationChannelWebhook, URL: server.URL}
+		n, notificationService := createNotifier(t, rule, 1)
+		client, err := egress.NewClient(egress.Settings{Enabled: true}, time.Second)
+		require.NoError(t, err)
+		n.client = client
+		n.Start()
+		require.NoError(t, n.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			notificationService.Lock()
+			defer notificationService.Unlock()
+			return notificationService.notifications[1].Status == model.NotificationStatusFailed
+		}, time.Second, time.Millisecond*10)
+		n.Stop(context.Background())
+
+		notificationService.Lock()
+		defer notificationService.Unlock()
+		assert.Contains(t, notificationService.notifications[1].Error.String, egress.ErrDenied.Error())
+		assert.Empty(t, body())
+	})
+
+	t.Run("Email without SMTP server", func(t *testing.T) {
+		rule := &model.NotificationRule{ID: uuid.New(), Channel: model.NotificationChannelEmail, Recipients: []string{"oncall@example.com"}}
+		n, notificationService := createNotifier(t, rule, 1)
+		n.Start()
+		require.NoError(t, n.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			notificationService.Lock()
+			defer notificationService.Unlock()
+			return notificationService.notifications[1].Status == model.NotificationStatusFailed
+		}, time.Second, time.Millisecond*10)
+		n.Stop(context.Background())
+	})
+
+	t.Run("Zero interval", func(t *testing.T) {
+		_, err := New(Config{
+			NotificationService: &mockNotificationService{},
+			Log:                 otelzap.New(zap.NewNop()),
+			Settings:            Settings{Enabled: true, MaxAttempts: 3, Timeout: time.Second},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestEmailBody(t *testing.T) {
+	msg := &message{Subject: "[payments] Job failed", Text: "Job failed\n3 more failures"}
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	body := string(emailBody("scheduler@example.com", []string{"a@example.com", "b@example.com"}, msg, date))
+
+	assert.True(t, strings.HasPrefix(body, "From: scheduler@example.com\r\nTo: a@example.com, b@example.com\r\nSubject: [payments] Job failed\r\n"))
+	assert.Contains(t, body, "Date: Wed, 01 May 2024 12:00:00 +0000\r\n")
+	assert.True(t, strings.HasSuffix(body, "\r\n\r\nJob failed\r\n3 more failures\r\n"))
+}
+
+// fakeSMTPServer accepts a single SMTP session and records the commands of the client.
+func fakeSMTPServer(t *testing.T) (host string, port int, commands func() []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 localhost ESMTP")
+		for data := false; ; {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			if data {
+				if line == "." {
+					data = false
+					_ = text.PrintfLine("250 OK")
+				}
+				continue
+			}
+
+			mu.Lock()
+			received = append(received, line)
+			mu.Unlock()
+
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "DATA":
+				data = true
+				_ = text.PrintfLine("354 Go ahead")
+			case "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				return
+			default:
+				_ = text.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, received...)
+	}
+}
+
+func TestSendEmail(t *testing.T) {
+	host, port, commands := fakeSMTPServer(t)
+
+	n, _ := createNotifier(t, nil, 1)
+	n.smtp = SMTPSettings{Host: host, Port: port, From: "Scheduler <scheduler@example.com>"}
+
+	msg := &message{Subject: "[payments] Job failed", Text: "Job failed"}
+	require.NoError(t, n.sendEmail(context.Background(), []string{"Payments <payments@example.com>", "oncall@example.com"}, msg))
+
+	// the display names are left out of the envelope
+	received := commands()
+	assert.Contains(t, received, "MAIL FROM:<scheduler@example.com>")
+	assert.Contains(t, received, "RCPT TO:<payments@example.com>")
+	assert.Contains(t, received, "RCPT TO:<oncall@example.com>")
+}
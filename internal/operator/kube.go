@@ -0,0 +1,194 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/mtls"
+)
+
+const (
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAPath    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+
+	// listPageSize is the number of ScheduledJobs listed at once
+	listPageSize = 500
+)
+
+// KubeSettings are the settings of the connection to the Kubernetes API, the in-cluster configuration of the pod if
+// the host is empty.
+type KubeSettings struct {
+	// Host is the URL of the Kubernetes API, e.g. http://localhost:8001 for kubectl proxy
+	Host string `mapstructure:"host" yaml:"host" json:"host,omitempty"`
+	// TokenFile is the path of the bearer token, read before each request since the tokens of service accounts
+	// are rotated
+	TokenFile string `mapstructure:"tokenFile" yaml:"tokenFile" json:"tokenFile,omitempty"`
+	// CAFile is the path of the CA bundle verifying the Kubernetes API
+	CAFile string `mapstructure:"caFile" yaml:"caFile" json:"caFile,omitempty"`
+	// Namespace is the namespace whose ScheduledJobs are reconciled, all of them if empty
+	Namespace string `mapstructure:"namespace" yaml:"namespace" json:"namespace,omitempty"`
+}
+
+// KubeClient reads and updates the ScheduledJobs through the REST API of Kubernetes.
+type KubeClient struct {
+	host       string
+	tokenFile  string
+	namespace  string
+	httpClient *http.Client
+}
+
+// KubeError is returned when the Kubernetes API responds with an error.
+type KubeError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *KubeError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.StatusCode)
+}
+
+// NewKubeClient creates a client of the Kubernetes API with the settings.
+func NewKubeClient(settings KubeSettings) (*KubeClient, error) {
+	if settings.Host == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("kubernetes.host must be set outside of a Kubernetes cluster")
+		}
+
+		settings.Host = "https://" + net.JoinHostPort(host, port)
+		settings.TokenFile = serviceAccountTokenPath
+		settings.CAFile = serviceAccountCAPath
+	}
+
+	tlsConfig, err := mtls.ClientConfig("", "", settings.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &KubeClient{
+		host:       strings.TrimSuffix(settings.Host, "/"),
+		tokenFile:  settings.TokenFile,
+		namespace:  settings.Namespace,
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: transport},
+	}, nil
+}
+
+// List returns the ScheduledJobs of the namespace, of all namespaces if empty.
+func (k *KubeClient) List(ctx context.Context) ([]ScheduledJob, error) {
+	path := "/apis/" + Group + "/" + Version + "/" + Resource
+	if k.namespace != "" {
+		path = "/apis/" + Group + "/" + Version + "/namespaces/" + url.PathEscape(k.namespace) + "/" + Resource
+	}
+
+	var jobs []ScheduledJob
+	query := url.Values{"limit": {fmt.Sprint(listPageSize)}}
+	for {
+		var list struct {
+			Items    []ScheduledJob `json:"items"`
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+		}
+		if err := k.do(ctx, http.MethodGet, path, query, "", nil, &list); err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, list.Items...)
+		if list.Metadata.Continue == "" {
+			return jobs, nil
+		}
+		query.Set("continue", list.Metadata.Continue)
+	}
+}
+
+// UpdateStatus replaces the status of the ScheduledJob, and updates it with the response. It fails with a conflict if
+// the ScheduledJob changed since it was read.
+func (k *KubeClient) UpdateStatus(ctx context.Context, job *ScheduledJob) error {
+	return k.do(ctx, http.MethodPut, objectPath(job)+"/status", nil, "application/json", job, job)
+}
+
+// SetFinalizers replaces the finalizers of the ScheduledJob, and updates it with the response. It fails with a
+// conflict if the ScheduledJob changed since it was read.
+func (k *KubeClient) SetFinalizers(ctx context.Context, job *ScheduledJob, finalizers []string) error {
+	if finalizers == nil {
+		finalizers = []string{}
+	}
+
+	patch := map[string]any{"metadata": map[string]any{
+		"finalizers":      finalizers,
+		"resourceVersion": job.Metadata.ResourceVersion,
+	}}
+	return k.do(ctx, http.MethodPatch, objectPath(job), nil, "application/merge-patch+json", patch, job)
+}
+
+func objectPath(job *ScheduledJob) string {
+	return "/apis/" + Group + "/" + Version + "/namespaces/" + url.PathEscape(job.Metadata.Namespace) + "/" + Resource +
+		"/" + url.PathEscape(job.Metadata.Name)
+}
+
+// do sends a request with the JSON encoded body of the content type, and decodes the JSON response into result.
+func (k *KubeClient) do(ctx context.Context, method, path string, query url.Values, contentType string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	u := k.host + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if k.tokenFile != "" {
+		token, err := os.ReadFile(k.tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the Kubernetes token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	res, err := k.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		// errors are returned as Status objects
+		var status struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(res.Body, 64*1024))
+		if json.Unmarshal(data, &status) != nil || status.Message == "" {
+			status.Message = strings.TrimSpace(string(data))
+		}
+
+		return &KubeError{StatusCode: res.StatusCode, Message: status.Message}
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
@@ -0,0 +1,87 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubeClient(t *testing.T) {
+	const path = "/apis/scheduler.timesnap.io/v1alpha1/namespaces/billing/scheduledjobs"
+
+	var requests []*http.Request
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == path && r.URL.Query().Get("continue") == "":
+			_, _ = w.Write([]byte(`{"items": [{"metadata": {"name": "a", "namespace": "billing"}}], "metadata": {"continue": "next"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == path:
+			_, _ = w.Write([]byte(`{"items": [{"metadata": {"name": "b", "namespace": "billing"}}], "metadata": {}}`))
+		case r.Method == http.MethodPut && r.URL.Path == path+"/a/status":
+			_, _ = w.Write([]byte(`{"metadata": {"name": "a", "namespace": "billing", "resourceVersion": "2"}, "status": {"jobId": "job"}}`))
+		case r.Method == http.MethodPatch && r.URL.Path == path+"/a":
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"kind": "Status", "message": "the object has been modified", "code": 409}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0o600))
+
+	kube, err := NewKubeClient(KubeSettings{Host: server.URL, TokenFile: tokenFile, Namespace: "billing"})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("List", func(t *testing.T) {
+		jobs, err := kube.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, jobs, 2)
+		assert.Equal(t, "b", jobs[1].Metadata.Name)
+
+		assert.Equal(t, "Bearer secret", requests[0].Header.Get("Authorization"))
+		assert.Equal(t, "next", requests[1].URL.Query().Get("continue"))
+	})
+
+	t.Run("Update status", func(t *testing.T) {
+		job := &ScheduledJob{Metadata: ObjectMeta{Name: "a", Namespace: "billing", ResourceVersion: "1"}}
+		require.NoError(t, kube.UpdateStatus(ctx, job))
+		assert.Equal(t, "2", job.Metadata.ResourceVersion)
+		assert.Equal(t, "job", job.Status.JobID)
+	})
+
+	t.Run("Set finalizers", func(t *testing.T) {
+		job := &ScheduledJob{Metadata: ObjectMeta{Name: "a", Namespace: "billing", ResourceVersion: "2"}}
+		err := kube.SetFinalizers(ctx, job, nil)
+
+		var kubeErr *KubeError
+		require.ErrorAs(t, err, &kubeErr)
+		assert.Equal(t, http.StatusConflict, kubeErr.StatusCode)
+		assert.Equal(t, "the object has been modified", kubeErr.Message)
+
+		req := requests[len(requests)-1]
+		assert.Equal(t, "application/merge-patch+json", req.Header.Get("Content-Type"))
+		assert.Equal(t, map[string]any{"finalizers": []any{}, "resourceVersion": "2"}, bodies[len(bodies)-1]["metadata"])
+	})
+
+	t.Run("Outside of a cluster", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+		_, err := NewKubeClient(KubeSettings{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,234 @@
+// Package operator reconciles the ScheduledJob custom resources of Kubernetes into jobs of the scheduler through the
+// management API, and reports the state of the jobs back in the status of the resources, so the schedules of a team
+// are managed like its other Kubernetes resources, e.g. with GitOps.
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/client"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// ScheduledJobs reads and updates the ScheduledJob resources, see KubeClient.
+type ScheduledJobs interface {
+	List(ctx context.Context) ([]ScheduledJob, error)
+	UpdateStatus(ctx context.Context, job *ScheduledJob) error
+	SetFinalizers(ctx context.Context, job *ScheduledJob, finalizers []string) error
+}
+
+// JobAPI is the part of the client of the management API managing the jobs.
+type JobAPI interface {
+	ApplyManifest(ctx context.Context, manifest *model.JobManifest, options model.ManifestApplyOptions) (*model.ManifestApplyResult, error)
+	ListJobs(ctx context.Context, opts client.ListJobsOptions) (*model.JobPage, error)
+	GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	GetJobExecutions(ctx context.Context, id uuid.UUID, limit uint64) (*model.JobExecutionPage, error)
+	DeleteJob(ctx context.Context, id uuid.UUID) error
+}
+
+// Operator reconciles the ScheduledJobs into jobs.
+type Operator struct {
+	scheduledJobs ScheduledJobs
+	jobs          JobAPI
+	log           *otelzap.Logger
+	now           func() time.Time
+}
+
+// New creates an operator reconciling the ScheduledJobs into the jobs of the management API.
+func New(scheduledJobs ScheduledJobs, jobs JobAPI, log *otelzap.Logger) *Operator {
+	return &Operator{scheduledJobs: scheduledJobs, jobs: jobs, log: log, now: time.Now}
+}
+
+// Run reconciles all the ScheduledJobs every interval until the context is done. The status of the ScheduledJobs is
+// refreshed at the same interval.
+func (o *Operator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		o.ReconcileAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileAll reconciles the ScheduledJobs one after the other, logging those failing.
+func (o *Operator) ReconcileAll(ctx context.Context) {
+	scheduledJobs, err := o.scheduledJobs.List(ctx)
+	if err != nil {
+		o.log.Error("Failed to list the ScheduledJobs", zap.Error(err))
+		return
+	}
+
+	for i := range scheduledJobs {
+		scheduledJob := &scheduledJobs[i]
+		if err := o.Reconcile(ctx, scheduledJob); err != nil {
+			o.log.Warn("Failed to reconcile the ScheduledJob", zap.String("namespace", scheduledJob.Metadata.Namespace),
+				zap.String("name", scheduledJob.Metadata.Name), zap.Error(err))
+		}
+	}
+}
+
+// Reconcile applies the spec of the ScheduledJob to its job, or deletes the job of a deleted ScheduledJob, and
+// updates the status of the ScheduledJob with the state of its job.
+func (o *Operator) Reconcile(ctx context.Context, scheduledJob *ScheduledJob) error {
+	if scheduledJob.Metadata.DeletionTimestamp != nil {
+		if !scheduledJob.hasFinalizer() {
+			return nil
+		}
+
+		if err := o.deleteJob(ctx, scheduledJob); err != nil {
+			return fmt.Errorf("failed to delete the job: %w", err)
+		}
+
+		finalizers := slices.DeleteFunc(slices.Clone(scheduledJob.Metadata.Finalizers), func(f string) bool { return f == Finalizer })
+		return o.scheduledJobs.SetFinalizers(ctx, scheduledJob, finalizers)
+	}
+
+	// the finalizer is added before the job is created, so the job is deleted along with the ScheduledJob
+	if !scheduledJob.hasFinalizer() {
+		finalizers := append(slices.Clone(scheduledJob.Metadata.Finalizers), Finalizer)
+		if err := o.scheduledJobs.SetFinalizers(ctx, scheduledJob, finalizers); err != nil {
+			return fmt.Errorf("failed to add the finalizer: %w", err)
+		}
+	}
+
+	status := scheduledJob.Status
+	status.Conditions = slices.Clone(status.Conditions)
+
+	job, syncErr := o.sync(ctx, scheduledJob, &status)
+	if syncErr != nil {
+		status.setCondition(Condition{Type: ConditionReady, Status: "False", Reason: "SyncFailed",
+			Message: syncErr.Error(), LastTransitionTime: o.now().UTC()})
+	} else {
+		status.setCondition(Condition{Type: ConditionReady, Status: "True", Reason: "Synced",
+			LastTransitionTime: o.now().UTC()})
+		status.JobID = job.ID.String()
+		status.JobStatus = job.Status
+		status.NextRun = job.NextRun.Ptr()
+
+		lastExecution, err := o.lastExecution(ctx, job.ID)
+		if err != nil {
+			syncErr = err
+		} else {
+			status.LastExecution = lastExecution
+		}
+	}
+
+	// the status is only updated when it changed, so the ScheduledJobs aren't updated on every resync
+	if !sameStatus(status, scheduledJob.Status) {
+		scheduledJob.Status = status
+		if err := o.scheduledJobs.UpdateStatus(ctx, scheduledJob); err != nil {
+			return errors.Join(syncErr, fmt.Errorf("failed to update the status: %w", err))
+		}
+	}
+
+	return syncErr
+}
+
+// sync applies the spec of the ScheduledJob to its job if it changed since it was last applied, or if the job was
+// deleted since, and returns the job.
+func (o *Operator) sync(ctx context.Context, scheduledJob *ScheduledJob, status *ScheduledJobStatus) (*model.Job, error) {
+	if id, err := uuid.Parse(status.JobID); err == nil && status.ObservedGeneration == scheduledJob.Metadata.Generation {
+		job, err := o.jobs.GetJob(ctx, id)
+		if !isNotFound(err) {
+			return job, err
+		}
+	}
+
+	definition := scheduledJob.Spec
+	definition.Name.SetValid(scheduledJob.jobName())
+	definition.Tags = append(slices.Clone(definition.Tags), scheduledJob.ownerTag())
+
+	// the other jobs of the namespace are left alone, the job being identified by its name
+	manifest := &model.JobManifest{Jobs: []model.JobCreate{definition}}
+	if _, err := o.jobs.ApplyManifest(ctx, manifest, model.ManifestApplyOptions{KeepMissing: true}); err != nil {
+		return nil, err
+	}
+
+	job, err := o.findJob(ctx, scheduledJob)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("the job %s wasn't found after it was applied", definition.Name.String)
+	}
+
+	status.ObservedGeneration = scheduledJob.Metadata.Generation
+	return job, nil
+}
+
+// findJob returns the job of the ScheduledJob, nil if it has none.
+func (o *Operator) findJob(ctx context.Context, scheduledJob *ScheduledJob) (*model.Job, error) {
+	page, err := o.jobs.ListJobs(ctx, client.ListJobsOptions{Limit: 1, Tags: []string{scheduledJob.ownerTag()}})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(page.Jobs) == 0 {
+		return nil, nil
+	}
+
+	return &page.Jobs[0], nil
+}
+
+func (o *Operator) lastExecution(ctx context.Context, jobID uuid.UUID) (*LastExecution, error) {
+	page, err := o.jobs.GetJobExecutions(ctx, jobID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the last execution: %w", err)
+	}
+
+	if len(page.Executions) == 0 {
+		return nil, nil
+	}
+
+	execution := page.Executions[0]
+	return &LastExecution{
+		ID:        execution.ID,
+		Status:    execution.Status,
+		StartTime: execution.StartTime,
+		EndTime:   execution.EndTime.Ptr(),
+		Error:     execution.ErrorMessage.String,
+	}, nil
+}
+
+// deleteJob deletes the job of the ScheduledJob, if it has one.
+func (o *Operator) deleteJob(ctx context.Context, scheduledJob *ScheduledJob) error {
+	job, err := o.findJob(ctx, scheduledJob)
+	if err != nil || job == nil {
+		return err
+	}
+
+	if err := o.jobs.DeleteJob(ctx, job.ID); err != nil && !isNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// sameStatus compares the statuses as they are stored, the times read from Kubernetes and the API having different
+// locations.
+func sameStatus(a, b ScheduledJobStatus) bool {
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
+
+func isNotFound(err error) bool {
+	var apiErr *client.Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
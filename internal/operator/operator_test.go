@@ -0,0 +1,177 @@
+package operator
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/client"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+type fakeScheduledJobs struct {
+	items         []ScheduledJob
+	statusUpdates int
+}
+
+func (f *fakeScheduledJobs) List(ctx context.Context) ([]ScheduledJob, error) {
+	return slices.Clone(f.items), nil
+}
+
+func (f *fakeScheduledJobs) UpdateStatus(ctx context.Context, job *ScheduledJob) error {
+	f.statusUpdates++
+	f.store(job)
+	return nil
+}
+
+func (f *fakeScheduledJobs) SetFinalizers(ctx context.Context, job *ScheduledJob, finalizers []string) error {
+	job.Metadata.Finalizers = finalizers
+	f.store(job)
+	return nil
+}
+
+func (f *fakeScheduledJobs) store(job *ScheduledJob) {
+	for i := range f.items {
+		if f.items[i].Metadata.UID == job.Metadata.UID {
+			f.items[i] = *job
+		}
+	}
+}
+
+// fakeJobAPI applies the manifests to jobs identified by name, like the management API.
+type fakeJobAPI struct {
+	jobs       map[string]*model.Job
+	applied    int
+	executions []*model.JobExecution
+}
+
+func (f *fakeJobAPI) ApplyManifest(ctx context.Context, manifest *model.JobManifest, options model.ManifestApplyOptions) (*model.ManifestApplyResult, error) {
+	f.applied++
+	for _, definition := range manifest.Jobs {
+		job := definition.ToJob()
+		if existing, ok := f.jobs[definition.Name.String]; ok {
+			job.ID = existing.ID
+		}
+		job.NextRun = null.TimeFrom(time.Date(2030, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600)))
+		f.jobs[definition.Name.String] = job
+	}
+
+	return &model.ManifestApplyResult{}, nil
+}
+
+func (f *fakeJobAPI) ListJobs(ctx context.Context, opts client.ListJobsOptions) (*model.JobPage, error) {
+	page := &model.JobPage{}
+	for _, job := range f.jobs {
+		if slices.Contains(job.Tags, opts.Tags[0]) {
+			page.Jobs = append(page.Jobs, *job)
+		}
+	}
+
+	return page, nil
+}
+
+func (f *fakeJobAPI) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	for _, job := range f.jobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+
+	return nil, &client.Error{StatusCode: http.StatusNotFound, Message: "job not found"}
+}
+
+func (f *fakeJobAPI) GetJobExecutions(ctx context.Context, id uuid.UUID, limit uint64) (*model.JobExecutionPage, error) {
+	return &model.JobExecutionPage{Executions: f.executions}, nil
+}
+
+func (f *fakeJobAPI) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	for name, job := range f.jobs {
+		if job.ID == id {
+			delete(f.jobs, name)
+		}
+	}
+
+	return nil
+}
+
+func TestOperator(t *testing.T) {
+	ctx := context.Background()
+
+	scheduledJobs := &fakeScheduledJobs{items: []ScheduledJob{{
+		Metadata: ObjectMeta{Name: "nightly-report", Namespace: "billing", UID: uuid.NewString(), Generation: 1},
+		Spec: model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("0 2 * * *"),
+			HTTPJob:      &model.HTTPJob{URL: "https://reports.example.com", Method: http.MethodPost, Auth: model.Auth{Type: model.AuthTypeNone}},
+			Tags:         []string{"team=billing"},
+		},
+	}}}
+	jobs := &fakeJobAPI{jobs: map[string]*model.Job{}}
+	operator := New(scheduledJobs, jobs, otelzap.New(zap.NewNop()))
+
+	operator.ReconcileAll(ctx)
+
+	require.Contains(t, jobs.jobs, "billing.nightly-report")
+	job := jobs.jobs["billing.nightly-report"]
+	assert.Contains(t, job.Tags, "team=billing")
+
+	scheduledJob := scheduledJobs.items[0]
+	assert.Equal(t, []string{Finalizer}, scheduledJob.Metadata.Finalizers)
+	assert.Equal(t, job.ID.String(), scheduledJob.Status.JobID)
+	assert.Equal(t, int64(1), scheduledJob.Status.ObservedGeneration)
+	assert.Equal(t, model.JobStatusRunning, scheduledJob.Status.JobStatus)
+	require.Len(t, scheduledJob.Status.Conditions, 1)
+	assert.Equal(t, "True", scheduledJob.Status.Conditions[0].Status)
+
+	t.Run("Unchanged", func(t *testing.T) {
+		operator.ReconcileAll(ctx)
+		assert.Equal(t, 1, jobs.applied)
+		assert.Equal(t, 1, scheduledJobs.statusUpdates)
+	})
+
+	t.Run("Last execution", func(t *testing.T) {
+		jobs.executions = []*model.JobExecution{{ID: 7, Status: model.JobExecutionStatusFailed, ErrorMessage: null.StringFrom("invalid response code")}}
+		operator.ReconcileAll(ctx)
+
+		lastExecution := scheduledJobs.items[0].Status.LastExecution
+		require.NotNil(t, lastExecution)
+		assert.Equal(t, 7, lastExecution.ID)
+		assert.Equal(t, "invalid response code", lastExecution.Error)
+	})
+
+	t.Run("Spec changed", func(t *testing.T) {
+		scheduledJobs.items[0].Metadata.Generation = 2
+		scheduledJobs.items[0].Spec.CronSchedule = null.StringFrom("0 3 * * *")
+		operator.ReconcileAll(ctx)
+
+		assert.Equal(t, 2, jobs.applied)
+		assert.Equal(t, "0 3 * * *", jobs.jobs["billing.nightly-report"].CronSchedule.String)
+		assert.Equal(t, job.ID, jobs.jobs["billing.nightly-report"].ID)
+		assert.Equal(t, int64(2), scheduledJobs.items[0].Status.ObservedGeneration)
+	})
+
+	t.Run("Job deleted", func(t *testing.T) {
+		delete(jobs.jobs, "billing.nightly-report")
+		operator.ReconcileAll(ctx)
+
+		assert.Contains(t, jobs.jobs, "billing.nightly-report")
+		assert.Equal(t, jobs.jobs["billing.nightly-report"].ID.String(), scheduledJobs.items[0].Status.JobID)
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		deletedAt := time.Now()
+		scheduledJobs.items[0].Metadata.DeletionTimestamp = &deletedAt
+		operator.ReconcileAll(ctx)
+
+		assert.Empty(t, jobs.jobs)
+		assert.Empty(t, scheduledJobs.items[0].Metadata.Finalizers)
+	})
+}
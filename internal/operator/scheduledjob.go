@@ -0,0 +1,116 @@
+package operator
+
+import (
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// The group, version and resource of the ScheduledJob custom resource, see deployments/kubernetes/crd.yaml.
+const (
+	Group    = "scheduler.timesnap.io"
+	Version  = "v1alpha1"
+	Resource = "scheduledjobs"
+
+	// Finalizer keeps a ScheduledJob until the operator deleted its job.
+	Finalizer = Group + "/job"
+
+	// ownerTagKey is the key of the tag identifying the job of a ScheduledJob, whose value is the UID of the resource.
+	ownerTagKey = "scheduledjob"
+)
+
+// ScheduledJob is a job declared as a Kubernetes custom resource. Its spec is the definition of the job, with the
+// fields of a job create request.
+type ScheduledJob struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   ObjectMeta         `json:"metadata"`
+	Spec       model.JobCreate    `json:"spec"`
+	Status     ScheduledJobStatus `json:"status,omitempty"`
+}
+
+// ObjectMeta is the metadata of a Kubernetes object used by the operator. Unknown fields are dropped, so objects are
+// only updated with merge patches or through their status subresource.
+type ObjectMeta struct {
+	Name              string     `json:"name"`
+	Namespace         string     `json:"namespace"`
+	UID               string     `json:"uid,omitempty"`
+	ResourceVersion   string     `json:"resourceVersion,omitempty"`
+	Generation        int64      `json:"generation,omitempty"`
+	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty"`
+	Finalizers        []string   `json:"finalizers,omitempty"`
+}
+
+// ScheduledJobStatus reports the state of the job of a ScheduledJob in the scheduler.
+type ScheduledJobStatus struct {
+	// JobID is the ID of the job in the scheduler
+	JobID string `json:"jobId,omitempty"`
+	// ObservedGeneration is the generation of the spec last applied to the job
+	ObservedGeneration int64           `json:"observedGeneration,omitempty"`
+	JobStatus          model.JobStatus `json:"jobStatus,omitempty"`
+	NextRun            *time.Time      `json:"nextRun,omitempty"`
+	LastExecution      *LastExecution  `json:"lastExecution,omitempty"`
+	Conditions         []Condition     `json:"conditions,omitempty"`
+}
+
+// LastExecution is the latest execution of the job of a ScheduledJob.
+type LastExecution struct {
+	ID        int                      `json:"id"`
+	Status    model.JobExecutionStatus `json:"status"`
+	StartTime time.Time                `json:"startTime"`
+	EndTime   *time.Time               `json:"endTime,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// ConditionReady is the type of the condition reporting whether the job matches the spec of the ScheduledJob.
+const ConditionReady = "Ready"
+
+// Condition is a Kubernetes status condition.
+type Condition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// setCondition sets the condition of the type, keeping its transition time if its status didn't change.
+func (s *ScheduledJobStatus) setCondition(condition Condition) {
+	for i, existing := range s.Conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		s.Conditions[i] = condition
+		return
+	}
+
+	s.Conditions = append(s.Conditions, condition)
+}
+
+// jobName returns the name of the job of the ScheduledJob, the one of its spec or namespace.name by default.
+func (j *ScheduledJob) jobName() string {
+	if j.Spec.Name.Valid && j.Spec.Name.String != "" {
+		return j.Spec.Name.String
+	}
+
+	return j.Metadata.Namespace + "." + j.Metadata.Name
+}
+
+// ownerTag returns the tag identifying the job of the ScheduledJob.
+func (j *ScheduledJob) ownerTag() string {
+	return model.Label{Key: ownerTagKey, Value: j.Metadata.UID}.String()
+}
+
+func (j *ScheduledJob) hasFinalizer() bool {
+	for _, finalizer := range j.Metadata.Finalizers {
+		if finalizer == Finalizer {
+			return true
+		}
+	}
+
+	return false
+}
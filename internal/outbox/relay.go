@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Relay periodically relays the job lifecycle events written to the outbox along with the changes of the jobs, to the
+// listeners of the events and the sinks. The events are removed from the outbox in the transaction they are passed to
+// the sinks in, so an event is either relayed to all of them or left in the outbox to be relayed again.
+type Relay struct {
+	store     Store
+	sinks     []Sink
+	log       *otelzap.Logger
+	batchSize uint
+	task      *periodic.Task
+}
+
+type Store interface {
+	RelayEvents(ctx context.Context, limit uint, fn func(ctx context.Context, events []model.Event) error) (int, error)
+}
+
+// Sink receives the relayed events, within the transaction removing them from the outbox. Sinks writing to the store
+// with the given context write in that transaction, the others may receive an event more than once.
+type Sink func(ctx context.Context, event model.Event) error
+
+type Config struct {
+	Store Store
+	Sinks []Sink
+	// Leader restricts the relaying to the leader instance, so the events are relayed in order. Every instance
+	// relays if nil.
+	Leader   leader.Leader
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// BatchSize is the maximum number of events relayed in a transaction
+	BatchSize uint `mapstructure:"batchSize" yaml:"batchSize" json:"batchSize,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Relay, error) {
+	r := &Relay{
+		store:     cfg.Store,
+		sinks:     cfg.Sinks,
+		log:       cfg.Log,
+		batchSize: max(cfg.Settings.BatchSize, 1),
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "outbox relay",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      r.relay,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.task = task
+
+	return r, nil
+}
+
+// Start starts the relay in a separate goroutine.
+// Only the first call will start the relay, subsequent calls are ignored.
+func (r *Relay) Start() {
+	r.task.Start()
+}
+
+// Stop stops the relay and waits for the current batch to be relayed or the context to expire.
+func (r *Relay) Stop(ctx context.Context) {
+	r.task.Stop(ctx)
+}
+
+// relay relays the events of the outbox in batches, until it is empty.
+func (r *Relay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		count, err := r.relayBatch(ctx)
+		if err != nil {
+			r.log.Error("Failed to relay the outbox events", zap.Error(err))
+			return
+		}
+
+		if count > 0 {
+			r.log.Debug("Relayed outbox events", zap.Int("count", count))
+		}
+
+		if uint(count) < r.batchSize {
+			return
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	return r.store.RelayEvents(ctx, r.batchSize, func(ctx context.Context, events []model.Event) error {
+		for _, event := range events {
+			for _, sink := range r.sinks {
+				if err := sink(ctx, event); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// mockStore is an outbox whose events are only removed if they were relayed successfully.
+type mockStore struct {
+	sync.Mutex
+	events []model.Event
+}
+
+func (m *mockStore) RelayEvents(ctx context.Context, limit uint, fn func(ctx context.Context, events []model.Event) error) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	batch := m.events[:min(int(limit), len(m.events))]
+	if err := fn(ctx, batch); err != nil {
+		return 0, err
+	}
+
+	m.events = m.events[len(batch):]
+	return len(batch), nil
+}
+
+func (m *mockStore) pending() int {
+	m.Lock()
+	defer m.Unlock()
+
+	return len(m.events)
+}
+
+type mockSink struct {
+	sync.Mutex
+	received []model.Event
+	err      error
+}
+
+func (m *mockSink) deliver(_ context.Context, event model.Event) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+
+	m.received = append(m.received, event)
+	return nil
+}
+
+func createRelay(t *testing.T, events int) (*Relay, *mockStore, *mockSink) {
+	store := &mockStore{}
+	for range events {
+		store.events = append(store.events, model.Event{ID: uuid.New(), Type: model.EventJobCreated, JobID: uuid.New()})
+	}
+
+	sink := &mockSink{}
+	zapL, _ := zap.NewDevelopment()
+
+	r, err := New(Config{
+		Store: store,
+		Sinks: []Sink{sink.deliver},
+		Log:   otelzap.New(zapL),
+		Settings: Settings{
+			Interval:  time.Millisecond * 20,
+			BatchSize: 2,
+		},
+	})
+	assert.NoError(t, err)
+
+	return r, store, sink
+}
+
+func TestRelay(t *testing.T) {
+	t.Run("Relays the events in order", func(t *testing.T) {
+		r, store, sink := createRelay(t, 5)
+		events := append([]model.Event{}, store.events...)
+
+		r.relay(context.Background())
+
+		assert.Equal(t, 0, store.pending())
+		assert.Equal(t, events, sink.received)
+	})
+
+	t.Run("Keeps the events failing to be relayed", func(t *testing.T) {
+		r, store, sink := createRelay(t, 3)
+		sink.err = errors.New("unavailable")
+
+		r.relay(context.Background())
+
+		assert.Equal(t, 3, store.pending())
+	})
+
+	t.Run("Only the leader relays", func(t *testing.T) {
+		store := &mockStore{}
+		for range 3 {
+			store.events = append(store.events, model.Event{ID: uuid.New(), Type: model.EventJobCreated, JobID: uuid.New()})
+		}
+
+		r, err := New(Config{
+			Store:    store,
+			Sinks:    []Sink{(&mockSink{}).deliver},
+			Leader:   leader.Static(false),
+			Log:      otelzap.New(zap.NewNop()),
+			Settings: Settings{Interval: time.Millisecond * 20, BatchSize: 2},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		r.Start()
+		time.Sleep(time.Millisecond * 100)
+		r.Stop(context.Background())
+
+		assert.Equal(t, 3, store.pending())
+	})
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{BatchSize: 2}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package partitioner
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Partitioner periodically creates the monthly partitions of the job executions ahead of time, and drops the
+// partitions whose executions expired, which is much cheaper than deleting them.
+type Partitioner struct {
+	store     Store
+	log       *otelzap.Logger
+	premake   int
+	retention time.Duration
+	task      *periodic.Task
+}
+
+type Store interface {
+	CreateExecutionPartitions(ctx context.Context, from, until time.Time) ([]string, error)
+	DropExecutionPartitions(ctx context.Context, before time.Time) ([]string, error)
+}
+
+type Config struct {
+	Store Store
+	// Leader restricts the partition management to the leader instance, every instance manages them if nil
+	Leader   leader.Leader
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// Premake is the number of months after the current one whose partitions are created ahead of time
+	Premake int `mapstructure:"premake" yaml:"premake" json:"premake,omitempty"`
+	// Retention is how long the executions are kept, the partitions of the months ending earlier are dropped.
+	// The executions are kept forever if zero.
+	Retention time.Duration `mapstructure:"retention" yaml:"retention" json:"retention,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Partitioner, error) {
+	p := &Partitioner{
+		store:     cfg.Store,
+		log:       cfg.Log,
+		premake:   cfg.Settings.Premake,
+		retention: cfg.Settings.Retention,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "execution partitioner",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      p.partition,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.task = task
+
+	return p, nil
+}
+
+// Start starts the partitioner in a separate goroutine.
+// Only the first call will start the partitioner, subsequent calls are ignored.
+func (p *Partitioner) Start() {
+	p.task.Start()
+}
+
+// Stop stops the partitioner and waits for the current run to finish or the context to expire.
+func (p *Partitioner) Stop(ctx context.Context) {
+	p.task.Stop(ctx)
+}
+
+func (p *Partitioner) partition(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	now := time.Now()
+
+	created, err := p.store.CreateExecutionPartitions(ctx, now, now.AddDate(0, p.premake, 0))
+	if err != nil {
+		p.log.Error("Failed to create the job execution partitions", zap.Error(err))
+	}
+
+	if len(created) > 0 {
+		p.log.Info("Created job execution partitions", zap.Strings("partitions", created))
+	}
+
+	if p.retention <= 0 {
+		return
+	}
+
+	dropped, err := p.store.DropExecutionPartitions(ctx, now.Add(-p.retention))
+	if err != nil {
+		p.log.Error("Failed to drop the expired job execution partitions", zap.Error(err))
+	}
+
+	if len(dropped) > 0 {
+		p.log.Info("Dropped expired job execution partitions", zap.Strings("partitions", dropped))
+	}
+}
@@ -0,0 +1,100 @@
+package partitioner
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockStore struct {
+	sync.Mutex
+	created []time.Time
+	dropped []time.Time
+}
+
+func (m *mockStore) CreateExecutionPartitions(_ context.Context, _, until time.Time) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.created = append(m.created, until)
+
+	return nil, nil
+}
+
+func (m *mockStore) DropExecutionPartitions(_ context.Context, before time.Time) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.dropped = append(m.dropped, before)
+
+	return nil, nil
+}
+
+func createPartitioner(t *testing.T, retention time.Duration) (*Partitioner, *mockStore) {
+	store := &mockStore{}
+	zapL, _ := zap.NewDevelopment()
+
+	p, err := New(Config{
+		Store: store,
+		Log:   otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:   true,
+			Interval:  time.Millisecond * 20,
+			Premake:   3,
+			Retention: retention,
+		},
+	})
+	assert.NoError(t, err)
+
+	return p, store
+}
+
+func TestPartitioner(t *testing.T) {
+	t.Run("Creates the partitions ahead and drops the expired ones", func(t *testing.T) {
+		p, store := createPartitioner(t, time.Hour*24*90)
+		p.partition(context.Background())
+
+		require.Len(t, store.created, 1)
+		assert.WithinDuration(t, time.Now().AddDate(0, 3, 0), store.created[0], time.Second)
+
+		require.Len(t, store.dropped, 1)
+		assert.WithinDuration(t, time.Now().Add(-time.Hour*24*90), store.dropped[0], time.Second)
+	})
+
+	t.Run("Keeps the executions without a retention", func(t *testing.T) {
+		p, store := createPartitioner(t, 0)
+		p.partition(context.Background())
+
+		assert.NotEmpty(t, store.created)
+		assert.Empty(t, store.dropped)
+	})
+
+	t.Run("Only the leader manages the partitions", func(t *testing.T) {
+		store := &mockStore{}
+		p, err := New(Config{
+			Store:    store,
+			Leader:   leader.Static(false),
+			Log:      otelzap.New(zap.NewNop()),
+			Settings: Settings{Enabled: true, Interval: time.Millisecond * 20, Premake: 3, Retention: time.Hour},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		p.Start()
+		time.Sleep(time.Millisecond * 100)
+		p.Stop(context.Background())
+
+		assert.Empty(t, store.created)
+		assert.Empty(t, store.dropped)
+	})
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{Enabled: true, Premake: 3}})
+	assert.Error(t, err)
+}
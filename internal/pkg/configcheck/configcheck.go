@@ -0,0 +1,145 @@
+// Package configcheck validates the configuration of the manager and the runner before it is deployed, for their
+// config validate commands. The checks build the components the configuration sets up without starting them, and the
+// online checks connect to the systems they depend on, such as the database.
+package configcheck
+
+import (
+	"context"
+	"crypto/aes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database/dbmigrate"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"gopkg.in/yaml.v3"
+)
+
+// Check validates a part of the configuration.
+type Check struct {
+	Name string
+	// Online checks connect to external systems, and only run when asked to
+	Online bool
+	// Warning checks report a failure without failing the validation, e.g. for settings only unfit for production
+	Warning bool
+	Run     func(ctx context.Context) error
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Check   Check
+	Err     error
+	Skipped bool
+}
+
+// Failed reports whether the result fails the validation.
+func (r Result) Failed() bool {
+	return r.Err != nil && !r.Check.Warning
+}
+
+// Run runs the checks in order, skipping the online ones unless online is set.
+func Run(ctx context.Context, checks []Check, online bool) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		if check.Online && !online {
+			results = append(results, Result{Check: check, Skipped: true})
+			continue
+		}
+
+		results = append(results, Result{Check: check, Err: check.Run(ctx)})
+	}
+
+	return results
+}
+
+// Report prints the results, and returns whether the configuration is valid, i.e. none of the results failed.
+func Report(w io.Writer, results []Result) bool {
+	valid := true
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			_, _ = fmt.Fprintf(w, "-  %s: skipped, run with --online\n", result.Check.Name)
+		case result.Err == nil:
+			_, _ = fmt.Fprintf(w, "✓  %s\n", result.Check.Name)
+		case result.Check.Warning:
+			_, _ = fmt.Fprintf(w, "!  %s: %v\n", result.Check.Name, result.Err)
+		default:
+			_, _ = fmt.Fprintf(w, "✗  %s: %v\n", result.Check.Name, result.Err)
+		}
+
+		if result.Failed() {
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+// PrintConfig prints the effective configuration as YAML, with the credentials which are set masked.
+func PrintConfig[T any](w io.Writer, cfg T) error {
+	data, err := yaml.Marshal(redact.Masked(cfg))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// DatabaseSettings checks that the database configuration sets a server to connect to.
+func DatabaseSettings(cfg database.Config) Check {
+	return Check{Name: "database settings", Run: func(context.Context) error {
+		if cfg.DSN == "" && (cfg.Host == "" || cfg.Name == "") {
+			return errors.New("either db.dsn or db.host and db.name are required")
+		}
+
+		_, err := cfg.ConnString()
+		return err
+	}}
+}
+
+// DatabaseConnection checks that the database accepts connections, and that its schema is up to date.
+func DatabaseConnection(cfg database.Config) Check {
+	return Check{Name: "database connection", Online: true, Run: func(ctx context.Context) error {
+		db, err := database.Open(cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		if err := db.PingContext(ctx); err != nil {
+			return err
+		}
+
+		statuses, err := dbmigrate.Status(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		for _, status := range statuses {
+			if !status.Applied {
+				pending++
+			}
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d migrations are not applied", pending)
+		}
+
+		return nil
+	}}
+}
+
+// Encryption checks the key encrypting the credentials at rest, and the key provider encrypting the data keys.
+func Encryption(key string, kms security.KMSSettings) Check {
+	return Check{Name: "encryption", Run: func(context.Context) error {
+		if _, err := aes.NewCipher([]byte(key)); err != nil {
+			return errors.New("storage.encryption.key must be 16, 24 or 32 bytes long")
+		}
+
+		_, err := security.NewKeyProvider(kms, nil)
+		return err
+	}}
+}
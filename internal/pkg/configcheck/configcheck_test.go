@@ -0,0 +1,96 @@
+package configcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAndReport(t *testing.T) {
+	failing := func(context.Context) error { return errors.New("boom") }
+	passing := func(context.Context) error { return nil }
+
+	tests := []struct {
+		name   string
+		checks []Check
+		online bool
+		valid  bool
+		output string
+	}{
+		{
+			name:   "valid",
+			checks: []Check{{Name: "a", Run: passing}},
+			valid:  true,
+			output: "✓  a\n",
+		},
+		{
+			name:   "failure",
+			checks: []Check{{Name: "a", Run: failing}, {Name: "b", Run: passing}},
+			valid:  false,
+			output: "✗  a: boom\n✓  b\n",
+		},
+		{
+			name:   "warning",
+			checks: []Check{{Name: "a", Warning: true, Run: failing}},
+			valid:  true,
+			output: "!  a: boom\n",
+		},
+		{
+			name:   "online skipped",
+			checks: []Check{{Name: "a", Online: true, Run: failing}},
+			valid:  true,
+			output: "-  a: skipped, run with --online\n",
+		},
+		{
+			name:   "online",
+			checks: []Check{{Name: "a", Online: true, Run: failing}},
+			online: true,
+			valid:  false,
+			output: "✗  a: boom\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			valid := Report(&out, Run(context.Background(), tt.checks, tt.online))
+
+			assert.Equal(t, tt.valid, valid)
+			assert.Equal(t, tt.output, out.String())
+		})
+	}
+}
+
+func TestDatabaseSettings(t *testing.T) {
+	check := DatabaseSettings(database.Config{Host: "localhost:5432", Name: "scheduler"})
+	assert.NoError(t, check.Run(context.Background()))
+
+	check = DatabaseSettings(database.Config{Host: "localhost:5432"})
+	assert.Error(t, check.Run(context.Background()))
+}
+
+func TestEncryption(t *testing.T) {
+	check := Encryption("0123456789abcdef", security.KMSSettings{})
+	assert.NoError(t, check.Run(context.Background()))
+
+	check = Encryption("short", security.KMSSettings{})
+	assert.Error(t, check.Run(context.Background()))
+}
+
+func TestPrintConfig(t *testing.T) {
+	cfg := struct {
+		DB database.Config `yaml:"db"`
+	}{DB: database.Config{User: "scheduler", Password: "secret", Host: "localhost"}}
+
+	var out bytes.Buffer
+	assert.NoError(t, PrintConfig(&out, &cfg))
+
+	assert.Contains(t, out.String(), "user: scheduler")
+	assert.NotContains(t, out.String(), "secret")
+	assert.Equal(t, "secret", cfg.DB.Password)
+}
@@ -5,6 +5,11 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
 	"github.com/ardanlabs/darwin/v3"
@@ -16,20 +21,213 @@ import (
 var (
 	//go:embed sql/migrate.sql
 	migrateDoc string
+
+	// rollbackDoc holds the scripts reverting the migrations, in the same format and with the same versions as
+	// migrateDoc. They are kept apart so the checksums of the applied migrations don't change.
+	//go:embed sql/rollback.sql
+	rollbackDoc string
+)
+
+// Files of the migrations and their rollbacks, relative to the source directory of the package.
+const (
+	MigrateFile  = "migrate.sql"
+	RollbackFile = "rollback.sql"
 )
 
+// MigrationStatus is the status of a migration in the database.
+type MigrationStatus struct {
+	Version     float64
+	Description string
+	Applied     bool
+	// AppliedAt is when the migration was applied, zero if it is pending.
+	AppliedAt time.Time
+	// Reversible reports whether the migration has a rollback script.
+	Reversible bool
+}
+
 // Migrate attempts to bring the database up to date with the migrations
 // defined in this package.
 func Migrate(ctx context.Context, db *sqlx.DB) error {
+	d, _, err := newDarwin(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	return d.Migrate()
+}
+
+// Status returns the status of the migrations defined in this package, ordered by version.
+func Status(ctx context.Context, db *sqlx.DB) ([]MigrationStatus, error) {
+	_, driver, err := newDarwin(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := driver.All()
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+
+	applied := make(map[float64]time.Time, len(records))
+	for _, record := range records {
+		applied[record.Version] = record.AppliedAt
+	}
+
+	rollbacks := rollbackScripts()
+
+	migrations := darwin.ParseMigrations(migrateDoc)
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		appliedAt, ok := applied[migration.Version]
+		_, reversible := rollbacks[migration.Version]
+
+		statuses = append(statuses, MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     ok,
+			AppliedAt:   appliedAt,
+			Reversible:  reversible,
+		})
+	}
+
+	return statuses, nil
+}
+
+// Rollback reverts the last applied migrations, up to the given number of steps, and returns the reverted versions.
+// Each migration is reverted in a transaction along with the removal of its record. Rollback stops at the first
+// migration without a rollback script, e.g. because it added an enum value, which Postgres can't remove.
+func Rollback(ctx context.Context, db *sqlx.DB, steps int) ([]float64, error) {
+	_, driver, err := newDarwin(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := driver.All()
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+
+	rollbacks := rollbackScripts()
+
+	var reverted []float64
+	for i := len(records) - 1; i >= 0 && len(reverted) < steps; i-- {
+		version := records[i].Version
+
+		script, ok := rollbacks[version]
+		if !ok {
+			return reverted, fmt.Errorf("migration %s is not reversible", FormatVersion(version))
+		}
+
+		if err := rollback(ctx, db, version, script); err != nil {
+			return reverted, fmt.Errorf("roll back migration %s: %w", FormatVersion(version), err)
+		}
+
+		reverted = append(reverted, version)
+	}
+
+	return reverted, nil
+}
+
+func rollback(ctx context.Context, db *sqlx.DB, version float64, script string) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	// versions are stored as reals, compare them at that precision
+	if _, err := tx.ExecContext(ctx, `DELETE FROM darwin_migrations WHERE version = $1::real`, version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Create appends an empty migration and its rollback with the next version to the migration files in the directory,
+// and returns the version of the migration.
+func Create(dir, description string) (float64, error) {
+	description = strings.TrimSpace(description)
+	if description == "" || strings.ContainsAny(description, ":\n") {
+		return 0, fmt.Errorf("the description must be a single line without colons")
+	}
+
+	migrations, err := os.ReadFile(filepath.Join(dir, MigrateFile))
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+
+	version := NextVersion(darwin.ParseMigrations(string(migrations)))
+	header := fmt.Sprintf("\n-- Version: %s\n-- Description: %s\n\n", FormatVersion(version), description)
+
+	if err := appendFile(filepath.Join(dir, MigrateFile), header); err != nil {
+		return 0, fmt.Errorf("append migration: %w", err)
+	}
+
+	if err := appendFile(filepath.Join(dir, RollbackFile), header); err != nil {
+		return 0, fmt.Errorf("append rollback: %w", err)
+	}
+
+	return version, nil
+}
+
+// NextVersion returns the version following the latest of the migrations, incremented by 0.01.
+func NextVersion(migrations []darwin.Migration) float64 {
+	latest := 1.0
+	for _, migration := range migrations {
+		latest = math.Max(latest, migration.Version)
+	}
+
+	return math.Round(latest*100+1) / 100
+}
+
+// FormatVersion formats a migration version like in the migration files.
+func FormatVersion(version float64) string {
+	return fmt.Sprintf("%.2f", version)
+}
+
+func newDarwin(ctx context.Context, db *sqlx.DB) (darwin.Darwin, *generic.Driver, error) {
 	if err := database.StatusCheck(ctx, db); err != nil {
-		return fmt.Errorf("status check database: %w", err)
+		return darwin.Darwin{}, nil, fmt.Errorf("status check database: %w", err)
 	}
 
 	driver, err := generic.New(db.DB, postgres.Dialect{})
 	if err != nil {
-		return fmt.Errorf("construct darwin driver: %w", err)
+		return darwin.Darwin{}, nil, fmt.Errorf("construct darwin driver: %w", err)
 	}
 
-	d := darwin.New(driver, darwin.ParseMigrations(migrateDoc))
-	return d.Migrate()
+	if err := driver.Create(); err != nil {
+		return darwin.Darwin{}, nil, fmt.Errorf("create migrations table: %w", err)
+	}
+
+	return darwin.New(driver, darwin.ParseMigrations(migrateDoc)), driver, nil
+}
+
+// rollbackScripts returns the non-empty rollback scripts by migration version.
+func rollbackScripts() map[float64]string {
+	scripts := map[float64]string{}
+	for _, migration := range darwin.ParseMigrations(rollbackDoc) {
+		if migration.Script != "" {
+			scripts[migration.Version] = migration.Script
+		}
+	}
+
+	return scripts
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
 }
@@ -0,0 +1,59 @@
+package dbmigrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ardanlabs/darwin/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbacksMatchMigrations(t *testing.T) {
+	migrations := map[float64]string{}
+	for _, migration := range darwin.ParseMigrations(migrateDoc) {
+		migrations[migration.Version] = migration.Description
+	}
+
+	rollbacks := darwin.ParseMigrations(rollbackDoc)
+	require.NotEmpty(t, rollbacks)
+
+	for _, rollback := range rollbacks {
+		description, ok := migrations[rollback.Version]
+		if assert.True(t, ok, "rollback of unknown migration %s", FormatVersion(rollback.Version)) {
+			assert.Equal(t, description, rollback.Description)
+		}
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	assert.Equal(t, 1.01, NextVersion(nil))
+	assert.Equal(t, 1.25, NextVersion([]darwin.Migration{{Version: 1.24}, {Version: 1.02}}))
+	assert.Equal(t, 2.0, NextVersion([]darwin.Migration{{Version: 1.99}}))
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MigrateFile), []byte("-- Version: 1.01\n-- Description: Init\nSELECT 1;\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, RollbackFile), []byte("-- Rollbacks\n"), 0o644))
+
+	version, err := Create(dir, "Add a table")
+	require.NoError(t, err)
+	assert.Equal(t, 1.02, version)
+
+	migrations, err := os.ReadFile(filepath.Join(dir, MigrateFile))
+	require.NoError(t, err)
+
+	parsed := darwin.ParseMigrations(string(migrations))
+	require.Len(t, parsed, 2)
+	assert.Equal(t, 1.02, parsed[1].Version)
+	assert.Equal(t, "Add a table", parsed[1].Description)
+
+	rollbacks, err := os.ReadFile(filepath.Join(dir, RollbackFile))
+	require.NoError(t, err)
+	assert.Contains(t, string(rollbacks), "-- Version: 1.02\n-- Description: Add a table\n")
+
+	_, err = Create(dir, "Invalid: description")
+	assert.Error(t, err)
+}
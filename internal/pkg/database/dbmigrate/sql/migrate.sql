@@ -70,4 +70,552 @@ CREATE INDEX job_executions_start_time_index ON job_executions (start_time);
 -- Version: 1.02
 -- Description: Add tags column to jobs table
 
-ALTER TABLE jobs ADD tags TEXT[];
\ No newline at end of file
+ALTER TABLE jobs ADD tags TEXT[];
+
+-- Version: 1.03
+-- Description: Add TTL and completion time to jobs for automatic archival
+
+ALTER TYPE job_status_enum ADD VALUE 'ARCHIVED';
+
+ALTER TABLE jobs ADD ttl BIGINT;
+ALTER TABLE jobs ADD completed_at TIMESTAMPTZ;
+
+CREATE INDEX completed_at_index ON jobs (completed_at) WHERE ttl IS NOT NULL;
+
+-- Version: 1.04
+-- Description: Add scheduled time to job executions to track scheduling drift
+
+ALTER TABLE job_executions ADD scheduled_time TIMESTAMPTZ;
+
+-- Version: 1.05
+-- Description: Add indexes for keyset pagination of jobs and job executions
+
+CREATE INDEX jobs_created_at_id_index ON jobs (created_at DESC, id DESC);
+
+CREATE INDEX job_executions_job_id_start_time_id_index ON job_executions (job_id, start_time DESC, id DESC);
+
+-- Version: 1.06
+-- Description: Track the last execution status of jobs and add indexes for filtering jobs
+
+ALTER TABLE jobs ADD last_execution_status job_execution_status_enum;
+
+CREATE INDEX jobs_status_index ON jobs (status);
+
+CREATE INDEX jobs_type_index ON jobs (type);
+
+CREATE INDEX jobs_tags_index ON jobs USING GIN (tags);
+
+CREATE INDEX jobs_last_execution_status_index ON jobs (last_execution_status);
+
+-- Version: 1.07
+-- Description: Add a searchable text column and full-text and trigram indexes for searching jobs
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE FUNCTION jobs_search_text(http_job JSONB, amqp_job JSONB, tags TEXT[]) RETURNS TEXT
+    LANGUAGE sql IMMUTABLE AS
+$$
+SELECT concat_ws(' ', http_job ->> 'url', amqp_job ->> 'exchange', amqp_job ->> 'routing_key', array_to_string(tags, ' '))
+$$;
+
+ALTER TABLE jobs ADD search_text TEXT GENERATED ALWAYS AS (jobs_search_text(http_job, amqp_job, tags)) STORED;
+
+CREATE INDEX jobs_search_text_trgm_index ON jobs USING GIN (search_text gin_trgm_ops);
+
+CREATE INDEX jobs_search_text_fts_index ON jobs USING GIN (to_tsvector('simple', search_text));
+
+-- Version: 1.08
+-- Description: Record executions when they start and allow cancelling running executions
+
+ALTER TYPE job_execution_status_enum ADD VALUE 'RUNNING';
+ALTER TYPE job_execution_status_enum ADD VALUE 'CANCELLED';
+
+ALTER TABLE job_executions ALTER COLUMN end_time DROP NOT NULL;
+
+ALTER TABLE job_executions ADD cancel_requested_at TIMESTAMPTZ;
+
+-- Version: 1.09
+-- Description: Add a table for logs captured during job executions
+
+CREATE TABLE job_execution_logs (
+    execution_id INTEGER PRIMARY KEY,
+    entries JSONB NOT NULL,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (execution_id) REFERENCES job_executions (id) ON DELETE CASCADE
+);
+
+-- Version: 1.10
+-- Description: Add webhooks and the webhook delivery log
+
+CREATE TYPE webhook_delivery_status_enum AS ENUM (
+    'PENDING',
+    'SUCCEEDED',
+    'FAILED'
+);
+
+CREATE TABLE webhooks (
+    id uuid PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status webhook_delivery_status_enum NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER,
+    error TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE,
+    -- every manager instance receives each event, so deliveries are deduplicated by event
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_pending_index ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+
+CREATE INDEX webhook_deliveries_webhook_id_created_at_id_index ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
+
+-- Version: 1.11
+-- Description: Add API keys
+
+CREATE TABLE api_keys (
+    id uuid PRIMARY KEY,
+    name TEXT NOT NULL,
+    -- first characters of the key, to help users identify it
+    prefix TEXT NOT NULL,
+    -- SHA-256 hash of the key, the key itself is never stored
+    key_hash TEXT NOT NULL UNIQUE,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- Version: 1.12
+-- Description: Add namespaces and their quotas
+
+ALTER TABLE jobs ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE job_executions ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE webhooks ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE api_keys ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX jobs_namespace_created_at_id_index ON jobs (namespace, created_at DESC, id DESC);
+
+CREATE INDEX jobs_namespace_locked_until_index ON jobs (namespace, locked_until);
+
+CREATE TABLE namespaces (
+    name TEXT PRIMARY KEY,
+    max_jobs INTEGER,
+    max_concurrent_executions INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Version: 1.13
+-- Description: Add job names, used to identify jobs in manifests
+
+ALTER TABLE jobs ADD COLUMN name TEXT;
+
+CREATE UNIQUE INDEX jobs_namespace_name_unique_index ON jobs (namespace, name) WHERE name IS NOT NULL AND status <> 'ARCHIVED';
+
+-- Version: 1.14
+-- Description: Add job versions for optimistic concurrency
+
+ALTER TABLE jobs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- Version: 1.15
+-- Description: Record the job definition of executions and allow re-running past executions
+
+ALTER TYPE job_execution_status_enum ADD VALUE 'PENDING';
+
+-- the job version and target an execution ran with, so it can be re-run as it was
+ALTER TABLE job_executions ADD COLUMN job_version INTEGER;
+ALTER TABLE job_executions ADD COLUMN job_type job_type_enum;
+ALTER TABLE job_executions ADD COLUMN http_job JSONB;
+ALTER TABLE job_executions ADD COLUMN amqp_job JSONB;
+
+ALTER TABLE job_executions ADD COLUMN retry_of INTEGER REFERENCES job_executions (id) ON DELETE SET NULL;
+
+CREATE INDEX job_executions_unfinished_index ON job_executions (id) WHERE end_time IS NULL;
+
+-- Version: 1.16
+-- Description: Add the registry of runner instances
+
+CREATE TABLE instances (
+    id TEXT PRIMARY KEY,
+    hostname TEXT NOT NULL,
+    version TEXT NOT NULL,
+    namespaces TEXT[] NOT NULL DEFAULT '{}',
+    capacity INTEGER NOT NULL,
+    load INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    -- instances that didn't send a heartbeat by then are considered dead
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Version: 1.17
+-- Description: Add tag selectors to webhooks
+
+ALTER TABLE webhooks ADD COLUMN selector TEXT NOT NULL DEFAULT '';
+
+-- Version: 1.18
+-- Description: Add job templates
+
+CREATE TABLE job_templates (
+    namespace TEXT NOT NULL DEFAULT 'default',
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    parameters JSONB NOT NULL DEFAULT '[]',
+    -- encrypted, as job definitions can contain credentials
+    definition TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (namespace, name)
+);
+
+-- Version: 1.19
+-- Description: Add job priorities
+
+ALTER TABLE jobs ADD COLUMN priority TEXT NOT NULL DEFAULT 'NORMAL';
+
+-- Version: 1.20
+-- Description: Add the capabilities required by jobs
+
+ALTER TABLE jobs ADD COLUMN required_capabilities TEXT[] NOT NULL DEFAULT '{}';
+
+-- Version: 1.21
+-- Description: Add execution timeouts to jobs
+
+ALTER TYPE job_execution_status_enum ADD VALUE 'TIMED_OUT';
+
+ALTER TABLE jobs ADD COLUMN execution_timeout INTEGER;
+
+-- Version: 1.22
+-- Description: Add misfire policies to jobs and record the instance running an execution
+
+ALTER TABLE jobs ADD COLUMN misfire_policy TEXT NOT NULL DEFAULT 'RUN_ONCE';
+
+ALTER TABLE job_executions ADD COLUMN instance_id TEXT;
+
+-- Version: 1.23
+-- Description: Add custom job types, defined by a JSON object passed to the executor of the type
+
+ALTER TABLE jobs ADD COLUMN custom_job JSONB;
+
+ALTER TABLE job_executions ADD COLUMN custom_job JSONB;
+
+ALTER TABLE jobs DROP CONSTRAINT check_job_type;
+
+ALTER TABLE jobs ALTER COLUMN type TYPE TEXT USING type::text;
+
+ALTER TABLE job_executions ALTER COLUMN job_type TYPE TEXT USING job_type::text;
+
+DROP TYPE job_type_enum;
+
+-- Ensure that only the definition matching the type of the job is set
+ALTER TABLE jobs ADD CONSTRAINT
+    check_job_type CHECK (
+        (type = 'HTTP' AND http_job IS NOT NULL AND amqp_job IS NULL AND custom_job IS NULL) OR
+        (type = 'AMQP' AND http_job IS NULL AND amqp_job IS NOT NULL AND custom_job IS NULL) OR
+        (type NOT IN ('HTTP', 'AMQP') AND http_job IS NULL AND amqp_job IS NULL AND custom_job IS NOT NULL)
+    );
+
+-- Version: 1.24
+-- Description: Add the progress reported by running executions
+
+ALTER TABLE job_executions ADD COLUMN progress_percent SMALLINT;
+
+ALTER TABLE job_executions ADD COLUMN progress_message TEXT;
+
+ALTER TABLE job_executions ADD COLUMN progress_updated_at TIMESTAMPTZ;
+
+-- Version: 1.25
+-- Description: Partition the job executions by month of creation
+
+-- partitioned tables can't be referenced by the execution ID alone, logs are deleted along with their job instead
+ALTER TABLE job_execution_logs ADD COLUMN job_id uuid;
+
+UPDATE job_execution_logs l SET job_id = e.job_id FROM job_executions e WHERE e.id = l.execution_id;
+
+DELETE FROM job_execution_logs WHERE job_id IS NULL;
+
+ALTER TABLE job_execution_logs ALTER COLUMN job_id SET NOT NULL;
+
+ALTER TABLE job_execution_logs DROP CONSTRAINT job_execution_logs_execution_id_fkey;
+
+ALTER TABLE job_execution_logs ADD FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE;
+
+CREATE INDEX job_execution_logs_job_id_index ON job_execution_logs (job_id);
+
+ALTER TABLE job_executions RENAME TO job_executions_unpartitioned;
+
+CREATE TABLE job_executions (LIKE job_executions_unpartitioned INCLUDING DEFAULTS) PARTITION BY RANGE (created_at);
+
+ALTER SEQUENCE job_executions_id_seq OWNED BY job_executions.id;
+
+ALTER TABLE job_executions ADD PRIMARY KEY (id, created_at);
+
+ALTER TABLE job_executions ADD FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE;
+
+-- catches the executions created in months whose partition wasn't created in time
+CREATE TABLE job_executions_default PARTITION OF job_executions DEFAULT;
+
+-- monthly partitions in UTC, from the oldest execution until 3 months ahead
+DO
+$$
+DECLARE
+    partition_start TIMESTAMP := date_trunc('month', coalesce((SELECT min(created_at) FROM job_executions_unpartitioned), now()) AT TIME ZONE 'UTC');
+BEGIN
+    WHILE partition_start <= date_trunc('month', now() AT TIME ZONE 'UTC') + INTERVAL '3 months' LOOP
+        EXECUTE format('CREATE TABLE %I PARTITION OF job_executions FOR VALUES FROM (%L) TO (%L)',
+                       'job_executions_p' || to_char(partition_start, 'YYYYMM'),
+                       partition_start AT TIME ZONE 'UTC',
+                       (partition_start + INTERVAL '1 month') AT TIME ZONE 'UTC');
+        partition_start := partition_start + INTERVAL '1 month';
+    END LOOP;
+END
+$$;
+
+INSERT INTO job_executions SELECT * FROM job_executions_unpartitioned;
+
+DROP TABLE job_executions_unpartitioned;
+
+CREATE INDEX job_id_index ON job_executions (job_id);
+
+CREATE INDEX job_executions_start_time_index ON job_executions (start_time);
+
+CREATE INDEX job_executions_job_id_start_time_id_index ON job_executions (job_id, start_time DESC, id DESC);
+
+CREATE INDEX job_executions_unfinished_index ON job_executions (id) WHERE end_time IS NULL;
+
+-- Version: 1.26
+-- Description: Add the outbox of the job lifecycle events
+
+-- events are written in the transaction of the change of the job, and removed once relayed
+CREATE TABLE event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id uuid NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Version: 1.27
+-- Description: Add the outputs of the executions, compressed apart from the executions
+
+-- deleted along with their job like the logs, as the partitioned executions can't be referenced
+CREATE TABLE job_execution_outputs (
+    execution_id INTEGER PRIMARY KEY,
+    job_id uuid NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    size BIGINT NOT NULL,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    encoding TEXT NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+-- the data is compressed already, so it is stored out of line without compressing it again
+ALTER TABLE job_execution_outputs ALTER COLUMN data SET STORAGE EXTERNAL;
+
+CREATE INDEX job_execution_outputs_job_id_index ON job_execution_outputs (job_id);
+
+-- Version: 1.28
+-- Description: Add the timelines of the executions
+
+-- deleted along with their job like the logs, as the partitioned executions can't be referenced
+CREATE TABLE job_execution_events (
+    id BIGSERIAL PRIMARY KEY,
+    execution_id INTEGER NOT NULL,
+    job_id uuid NOT NULL,
+    type TEXT NOT NULL,
+    time TIMESTAMPTZ NOT NULL,
+    attempt INTEGER,
+    status TEXT,
+    message TEXT,
+    instance_id TEXT,
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX job_execution_events_execution_id_index ON job_execution_events (execution_id, time);
+CREATE INDEX job_execution_events_job_id_index ON job_execution_events (job_id);
+
+-- Version: 1.29
+-- Description: Add the notification rules and their notifications
+
+CREATE TABLE notification_rules (
+    id uuid PRIMARY KEY,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    name TEXT NOT NULL DEFAULT '',
+    channel TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    triggers TEXT[] NOT NULL DEFAULT '{}',
+    job_id uuid,
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    selector TEXT NOT NULL DEFAULT '',
+    -- seconds between two failure notifications of a job
+    throttle INTEGER NOT NULL DEFAULT 3600,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notification_rules_namespace_index ON notification_rules (namespace);
+
+-- throttling state of the notifications of each job by each rule
+CREATE TABLE notification_states (
+    rule_id uuid NOT NULL,
+    job_id uuid NOT NULL,
+    alerting BOOLEAN NOT NULL DEFAULT FALSE,
+    last_notified_at TIMESTAMPTZ,
+    suppressed INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (rule_id, job_id),
+    FOREIGN KEY (rule_id) REFERENCES notification_rules (id) ON DELETE CASCADE,
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notification_states_job_id_index ON notification_states (job_id);
+
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    rule_id uuid NOT NULL,
+    -- kept after the job is deleted, as a log of the notifications
+    job_id uuid NOT NULL,
+    trigger TEXT NOT NULL,
+    event JSONB NOT NULL,
+    suppressed INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (rule_id) REFERENCES notification_rules (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notifications_pending_index ON notifications (next_attempt_at) WHERE status = 'PENDING';
+
+CREATE INDEX notifications_rule_id_created_at_id_index ON notifications (rule_id, created_at DESC, id DESC);
+
+-- Version: 1.30
+-- Description: Add the SLAs of the jobs
+
+ALTER TABLE jobs ADD COLUMN sla JSONB;
+
+CREATE INDEX jobs_sla_index ON jobs (id) WHERE sla IS NOT NULL;
+
+-- Version: 1.31
+-- Description: Add the labels the jobs opt into adding to their execution metrics
+
+ALTER TABLE jobs ADD COLUMN metric_labels TEXT[] NOT NULL DEFAULT '{}';
+
+-- Version: 1.32
+-- Description: Add the payload format of the webhooks
+
+ALTER TABLE webhooks ADD COLUMN format TEXT NOT NULL DEFAULT 'json';
+
+-- Version: 1.33
+-- Description: Add the data keys of the namespaces
+
+CREATE TABLE namespace_keys (
+    namespace TEXT NOT NULL,
+    version INT NOT NULL,
+    encrypted_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (namespace, version)
+);
+
+-- Version: 1.34
+-- Description: Add the owner and team of the jobs, and the team of the API keys
+
+ALTER TABLE jobs ADD COLUMN owner TEXT;
+ALTER TABLE jobs ADD COLUMN team TEXT;
+ALTER TABLE api_keys ADD COLUMN team TEXT;
+
+CREATE INDEX jobs_namespace_team_index ON jobs (namespace, team);
+
+-- Version: 1.35
+-- Description: Record the executions of manual runs whose definition was overridden
+
+ALTER TABLE job_executions ADD COLUMN overridden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Version: 1.36
+-- Description: Add the metadata of the jobs
+
+ALTER TABLE jobs ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX jobs_metadata_index ON jobs USING GIN (metadata);
+
+-- Version: 1.37
+-- Description: Add the result sinks of the jobs
+
+ALTER TABLE jobs ADD COLUMN result_sink JSONB;
+
+-- Version: 1.38
+-- Description: Add the external IDs of the jobs
+
+ALTER TABLE jobs ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX jobs_namespace_external_id_unique_index ON jobs (namespace, external_id) WHERE external_id IS NOT NULL AND status <> 'ARCHIVED';
+
+-- Version: 1.39
+-- Description: Add the teams of the webhooks and notification rules
+
+ALTER TABLE webhooks ADD COLUMN team TEXT;
+
+ALTER TABLE notification_rules ADD COLUMN team TEXT;
+
+-- Version: 1.40
+-- Description: Record the oldest namespace key version encrypting the credentials, and the pending key rotations
+
+-- the oldest version of the namespace keys of the values encrypted in the texts, see security.NamespaceKeyReference,
+-- or NULL if none is encrypted with a namespace key. The encrypted values are whole texts or strings of JSON documents.
+CREATE FUNCTION namespace_key_version(VARIADIC texts TEXT[]) RETURNS INT
+    LANGUAGE sql IMMUTABLE AS
+$$
+SELECT min(m.match[2]::int)
+FROM unnest(texts) AS t(value), regexp_matches(t.value, '(^|")ns:v1:([0-9]+):', 'g') AS m(match)
+$$;
+
+ALTER TABLE jobs ADD key_version INT GENERATED ALWAYS AS (namespace_key_version(http_job::text, amqp_job::text, result_sink::text)) STORED;
+
+ALTER TABLE job_executions ADD key_version INT GENERATED ALWAYS AS (namespace_key_version(http_job::text, amqp_job::text)) STORED;
+
+ALTER TABLE webhooks ADD key_version INT GENERATED ALWAYS AS (namespace_key_version(secret)) STORED;
+
+ALTER TABLE job_templates ADD key_version INT GENERATED ALWAYS AS (namespace_key_version(definition)) STORED;
+
+CREATE INDEX jobs_namespace_key_version_index ON jobs (namespace, key_version);
+
+CREATE INDEX job_executions_namespace_key_version_index ON job_executions (namespace, key_version);
+
+CREATE INDEX webhooks_namespace_key_version_index ON webhooks (namespace, key_version);
+
+CREATE INDEX job_templates_namespace_key_version_index ON job_templates (namespace, key_version);
+
+-- the rotations whose credentials are encrypted again with the new key in the background, the executions from the one
+-- after the last execution encrypted again
+CREATE TABLE namespace_key_rotations (
+    namespace TEXT PRIMARY KEY,
+    version INT NOT NULL,
+    last_execution_id INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,139 @@
+-- Rollback scripts of the migrations in migrate.sql, with the same versions. Migrations without a rollback script,
+-- e.g. the ones adding enum values which Postgres can't remove, are not reversible.
+
+-- Version: 1.16
+-- Description: Add the registry of runner instances
+
+DROP TABLE instances;
+
+-- Version: 1.17
+-- Description: Add tag selectors to webhooks
+
+ALTER TABLE webhooks DROP COLUMN selector;
+
+-- Version: 1.18
+-- Description: Add job templates
+
+DROP TABLE job_templates;
+
+-- Version: 1.19
+-- Description: Add job priorities
+
+ALTER TABLE jobs DROP COLUMN priority;
+
+-- Version: 1.20
+-- Description: Add the capabilities required by jobs
+
+ALTER TABLE jobs DROP COLUMN required_capabilities;
+
+-- Version: 1.22
+-- Description: Add misfire policies to jobs and record the instance running an execution
+
+ALTER TABLE jobs DROP COLUMN misfire_policy;
+
+ALTER TABLE job_executions DROP COLUMN instance_id;
+
+-- Version: 1.24
+-- Description: Add the progress reported by running executions
+
+ALTER TABLE job_executions DROP COLUMN progress_percent;
+
+ALTER TABLE job_executions DROP COLUMN progress_message;
+
+ALTER TABLE job_executions DROP COLUMN progress_updated_at;
+
+-- Version: 1.26
+-- Description: Add the outbox of the job lifecycle events
+
+DROP TABLE event_outbox;
+
+-- Version: 1.27
+-- Description: Add the outputs of the executions, compressed apart from the executions
+
+DROP TABLE job_execution_outputs;
+
+-- Version: 1.28
+-- Description: Add the timelines of the executions
+
+DROP TABLE job_execution_events;
+
+-- Version: 1.29
+-- Description: Add the notification rules and their notifications
+
+DROP TABLE notifications;
+DROP TABLE notification_states;
+DROP TABLE notification_rules;
+
+-- Version: 1.30
+-- Description: Add the SLAs of the jobs
+
+ALTER TABLE jobs DROP COLUMN sla;
+
+-- Version: 1.31
+-- Description: Add the labels the jobs opt into adding to their execution metrics
+
+ALTER TABLE jobs DROP COLUMN metric_labels;
+
+-- Version: 1.32
+-- Description: Add the payload format of the webhooks
+
+ALTER TABLE webhooks DROP COLUMN format;
+
+-- Version: 1.33
+-- Description: Add the data keys of the namespaces
+
+DROP TABLE namespace_keys;
+
+-- Version: 1.34
+-- Description: Add the owner and team of the jobs, and the team of the API keys
+
+DROP INDEX jobs_namespace_team_index;
+ALTER TABLE api_keys DROP COLUMN team;
+ALTER TABLE jobs DROP COLUMN team;
+ALTER TABLE jobs DROP COLUMN owner;
+
+-- Version: 1.35
+-- Description: Record the executions of manual runs whose definition was overridden
+
+ALTER TABLE job_executions DROP COLUMN overridden;
+
+-- Version: 1.36
+-- Description: Add the metadata of the jobs
+
+DROP INDEX jobs_metadata_index;
+ALTER TABLE jobs DROP COLUMN metadata;
+
+-- Version: 1.37
+-- Description: Add the result sinks of the jobs
+
+ALTER TABLE jobs DROP COLUMN result_sink;
+
+-- Version: 1.38
+-- Description: Add the external IDs of the jobs
+
+DROP INDEX jobs_namespace_external_id_unique_index;
+ALTER TABLE jobs DROP COLUMN external_id;
+
+-- Version: 1.39
+-- Description: Add the teams of the webhooks and notification rules
+
+ALTER TABLE webhooks DROP COLUMN team;
+
+ALTER TABLE notification_rules DROP COLUMN team;
+
+-- Version: 1.40
+-- Description: Record the oldest namespace key version encrypting the credentials, and the pending key rotations
+
+DROP TABLE namespace_key_rotations;
+
+DROP INDEX job_templates_namespace_key_version_index;
+DROP INDEX webhooks_namespace_key_version_index;
+DROP INDEX job_executions_namespace_key_version_index;
+DROP INDEX jobs_namespace_key_version_index;
+
+ALTER TABLE job_templates DROP COLUMN key_version;
+ALTER TABLE webhooks DROP COLUMN key_version;
+ALTER TABLE job_executions DROP COLUMN key_version;
+ALTER TABLE jobs DROP COLUMN key_version;
+
+DROP FUNCTION namespace_key_version;
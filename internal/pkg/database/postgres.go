@@ -2,51 +2,223 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"maps"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 )
 
+// TLS modes of the connections, from the least to the most secure.
+const (
+	SSLModeDisable    = "disable"
+	SSLModeAllow      = "allow"
+	SSLModePrefer     = "prefer"
+	SSLModeRequire    = "require"
+	SSLModeVerifyCA   = "verify-ca"
+	SSLModeVerifyFull = "verify-full"
+)
+
 // Config is the required properties to use the database.
 type Config struct {
-	User         string `mapstructure:"user" yaml:"user" json:"user,omitempty"`
-	Password     string `mapstructure:"password" yaml:"password" json:"password,omitempty"`
-	Host         string `mapstructure:"host" yaml:"host" json:"host,omitempty"`
-	Name         string `mapstructure:"name" yaml:"name" json:"name,omitempty"`
-	MaxIdleConns int    `mapstructure:"maxIdleConns" yaml:"maxIdleConns" json:"maxIdleConns,omitempty"`
-	MaxOpenConns int    `mapstructure:"maxOpenConns" yaml:"maxOpenConns" json:"maxOpenConns,omitempty"`
-	DisableTLS   bool   `mapstructure:"disableTls" yaml:"disableTLS" json:"disableTLS,omitempty"`
+	// DSN is a full connection string, as a URL or keyword/value pairs, replacing the user, password, host and name.
+	// The TLS settings below override the ones of the DSN when set.
+	DSN      string `mapstructure:"dsn" yaml:"dsn" json:"-" redact:"true"`
+	User     string `mapstructure:"user" yaml:"user" json:"user,omitempty"`
+	Password string `mapstructure:"password" yaml:"password" json:"password,omitempty" redact:"true"`
+	Host     string `mapstructure:"host" yaml:"host" json:"host,omitempty"`
+	Name     string `mapstructure:"name" yaml:"name" json:"name,omitempty"`
+	// MaxIdleConns is ignored, idle connections are kept by the pgx pool until they are closed by its health checks
+	MaxIdleConns int `mapstructure:"maxIdleConns" yaml:"maxIdleConns" json:"maxIdleConns,omitempty"`
+	MaxOpenConns int `mapstructure:"maxOpenConns" yaml:"maxOpenConns" json:"maxOpenConns,omitempty"`
+	// ConnMaxLifetime and ConnMaxIdleTime close the connections once they are that old or idle for that long, the pgx
+	// defaults (1h and 30m) if zero.
+	ConnMaxLifetime time.Duration `mapstructure:"connMaxLifetime" yaml:"connMaxLifetime" json:"connMaxLifetime,omitempty"`
+	ConnMaxIdleTime time.Duration `mapstructure:"connMaxIdleTime" yaml:"connMaxIdleTime" json:"connMaxIdleTime,omitempty"`
+	// DisableTLS selects the disable mode over require, if no SSL mode is set.
+	DisableTLS bool `mapstructure:"disableTls" yaml:"disableTLS" json:"disableTLS,omitempty"`
+	// SSLMode is the TLS mode of the connections, see the SSLMode constants.
+	SSLMode string `mapstructure:"sslMode" yaml:"sslMode" json:"sslMode,omitempty"`
+	// SSLRootCert is the path of the CA certificates verifying the server, for the verify-ca and verify-full modes.
+	SSLRootCert string `mapstructure:"sslRootCert" yaml:"sslRootCert" json:"sslRootCert,omitempty"`
+	// SSLCert and SSLKey are the paths of the client certificate and its key, for certificate authentication.
+	SSLCert string `mapstructure:"sslCert" yaml:"sslCert" json:"sslCert,omitempty"`
+	SSLKey  string `mapstructure:"sslKey" yaml:"sslKey" json:"sslKey,omitempty"`
+	// StatementTimeout aborts the statements running longer, on the server, none if zero. It applies to all the
+	// statements, including the migrations applied by the manager.
+	StatementTimeout time.Duration `mapstructure:"statementTimeout" yaml:"statementTimeout" json:"statementTimeout,omitempty"`
+	// SlowQueryThreshold is the duration above which the queries are logged, none are if zero.
+	SlowQueryThreshold time.Duration `mapstructure:"slowQueryThreshold" yaml:"slowQueryThreshold" json:"slowQueryThreshold,omitempty"`
+	// QueryTimeouts bound the duration of the operations of the store.
+	QueryTimeouts QueryTimeouts `mapstructure:"queryTimeouts" yaml:"queryTimeouts" json:"queryTimeouts"`
+}
+
+// QueryTimeouts bound the duration of the operations of the store, such as GetJobsToRun. The listeners and the exports
+// streaming the executions are not bounded.
+type QueryTimeouts struct {
+	// Default is the timeout of the operations without one of their own, none if zero.
+	Default time.Duration `mapstructure:"default" yaml:"default" json:"default,omitempty"`
+	// Operations are the timeouts of the operations, by the name of the store method.
+	Operations map[string]time.Duration `mapstructure:"operations" yaml:"operations" json:"operations,omitempty"`
+}
+
+// Timeout returns the timeout of the operation, zero if it has none. The operations are matched case-insensitively, as
+// the keys of the configuration are lowercased.
+func (t QueryTimeouts) Timeout(operation string) time.Duration {
+	for name, timeout := range t.Operations {
+		if strings.EqualFold(name, operation) {
+			return timeout
+		}
+	}
+
+	return t.Default
+}
+
+// ConnString returns the connection string of the configuration.
+func (cfg Config) ConnString() (string, error) {
+	params := map[string]string{}
+	switch {
+	case cfg.SSLMode != "":
+		switch cfg.SSLMode {
+		case SSLModeDisable, SSLModeAllow, SSLModePrefer, SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull:
+		default:
+			return "", fmt.Errorf("invalid ssl mode: %q", cfg.SSLMode)
+		}
+		params["sslmode"] = cfg.SSLMode
+	case cfg.DSN == "" && cfg.DisableTLS:
+		params["sslmode"] = SSLModeDisable
+	case cfg.DSN == "":
+		params["sslmode"] = SSLModeRequire
+	}
+
+	for name, value := range map[string]string{"sslrootcert": cfg.SSLRootCert, "sslcert": cfg.SSLCert, "sslkey": cfg.SSLKey} {
+		if value != "" {
+			params[name] = value
+		}
+	}
+
+	if cfg.DSN == "" {
+		q := make(url.Values)
+		q.Set("timezone", "utc")
+		for name, value := range params {
+			q.Set(name, value)
+		}
+
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.User, cfg.Password),
+			Host:     cfg.Host,
+			Path:     cfg.Name,
+			RawQuery: q.Encode(),
+		}
+		return u.String(), nil
+	}
+
+	if strings.HasPrefix(cfg.DSN, "postgres://") || strings.HasPrefix(cfg.DSN, "postgresql://") {
+		u, err := url.Parse(cfg.DSN)
+		if err != nil {
+			// the error would include the password
+			return "", errors.New("invalid database DSN")
+		}
+
+		q := u.Query()
+		if !q.Has("timezone") {
+			q.Set("timezone", "utc")
+		}
+		for name, value := range params {
+			q.Set(name, value)
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+
+	// the last occurrence of a keyword wins
+	dsn := cfg.DSN
+	if !strings.Contains(dsn, "timezone=") {
+		dsn += " timezone=utc"
+	}
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		dsn += " " + name + "=" + quoteConnValue(params[name])
+	}
+	return strings.TrimSpace(dsn), nil
+}
+
+// quoteConnValue quotes a value of a keyword/value connection string.
+func quoteConnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
 
 // Open knows how to open a database connection based on the configuration.
-func Open(cfg Config) (*sqlx.DB, error) {
-	sslMode := "require"
-	if cfg.DisableTLS {
-		sslMode = "disable"
+// The connections are pooled by a pgx pool, which is closed along with the returned database.
+func Open(cfg Config, options ...TracerOption) (*sqlx.DB, error) {
+	pool, err := OpenPool(cfg, options...)
+	if err != nil {
+		return nil, err
 	}
 
-	q := make(url.Values)
-	q.Set("sslmode", sslMode)
-	q.Set("timezone", "utc")
+	return NewDB(pool), nil
+}
 
-	u := url.URL{
-		Scheme:   "postgres",
-		User:     url.UserPassword(cfg.User, cfg.Password),
-		Host:     cfg.Host,
-		Path:     cfg.Name,
-		RawQuery: q.Encode(),
+// OpenPool opens a native pgx connection pool based on the configuration, tracing the queries with OpenTelemetry.
+// The options configure the logging of the slow queries and the query metrics.
+func OpenPool(cfg Config, options ...TracerOption) (*pgxpool.Pool, error) {
+	connString, err := cfg.ConnString()
+	if err != nil {
+		return nil, err
 	}
 
-	db, err := sqlx.Open("pgx", u.String())
+	poolConfig, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, err
 	}
-	db.SetMaxIdleConns(cfg.MaxIdleConns)
-	db.SetMaxOpenConns(cfg.MaxOpenConns)
 
-	return db, nil
+	if cfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	}
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	options = append([]TracerOption{WithSlowQueryThreshold(cfg.SlowQueryThreshold)}, options...)
+	poolConfig.ConnConfig.Tracer = NewTracer(options...)
+
+	// the pool connects lazily, like database/sql
+	return pgxpool.NewWithConfig(context.Background(), poolConfig)
+}
+
+// NewDB wraps the pool in a database/sql database for sqlx. The connections are only pooled by the pgx pool,
+// and closing the database closes the pool.
+func NewDB(pool *pgxpool.Pool) *sqlx.DB {
+	db := sql.OpenDB(&poolConnector{Connector: stdlib.GetPoolConnector(pool), pool: pool})
+	// idle connections are returned to the pgx pool, rather than kept by database/sql
+	db.SetMaxIdleConns(0)
+
+	return sqlx.NewDb(db, "pgx")
+}
+
+// poolConnector closes the pgx pool when the database/sql database is closed.
+type poolConnector struct {
+	driver.Connector
+	pool *pgxpool.Pool
+}
+
+func (c *poolConnector) Close() error {
+	c.pool.Close()
+	return nil
 }
 
 // StatusCheck returns nil if it can successfully talk to the database. It
@@ -93,6 +265,11 @@ func (h *HealthcheckAdapter) Pass() bool {
 	return StatusCheck(context.Background(), h.DB) == nil
 }
 
+// Name of the checked database, "sqlite" for the SQLite database of the manager and "postgres" otherwise.
 func (h *HealthcheckAdapter) Name() string {
+	if h.DB.DriverName() == "sqlite3" {
+		return "sqlite"
+	}
+
 	return "postgres"
 }
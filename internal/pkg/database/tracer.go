@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// QueryMetrics records the duration and the outcome of the queries.
+type QueryMetrics interface {
+	RecordQuery(ctx context.Context, operation string, duration time.Duration, err error)
+}
+
+// Tracer records a span for every query, batch and copy of the connections, with the statement and the number of
+// affected rows. Queries are only traced within an existing trace, e.g. of an API request or a job execution, so the
+// polling of the runners and the maintenance tasks doesn't flood the traces. All the queries are measured by the
+// query metrics, and the ones slower than the threshold are logged.
+type Tracer struct {
+	tracer             trace.Tracer
+	log                *otelzap.Logger
+	slowQueryThreshold time.Duration
+	metrics            QueryMetrics
+	now                func() time.Time
+}
+
+// TracerOption configures the Tracer.
+type TracerOption func(*Tracer)
+
+// WithLogger logs the slow queries with the logger.
+func WithLogger(log *otelzap.Logger) TracerOption {
+	return func(t *Tracer) {
+		t.log = log
+	}
+}
+
+// WithSlowQueryThreshold logs the queries taking longer than the threshold as warnings, none if it is zero.
+func WithSlowQueryThreshold(threshold time.Duration) TracerOption {
+	return func(t *Tracer) {
+		t.slowQueryThreshold = threshold
+	}
+}
+
+// WithQueryMetrics records the queries in the metrics.
+func WithQueryMetrics(metrics QueryMetrics) TracerOption {
+	return func(t *Tracer) {
+		t.metrics = metrics
+	}
+}
+
+var (
+	_ pgx.QueryTracer    = (*Tracer)(nil)
+	_ pgx.BatchTracer    = (*Tracer)(nil)
+	_ pgx.CopyFromTracer = (*Tracer)(nil)
+)
+
+// queryStartKey is the context key of the start of the query.
+type queryStartKey struct{}
+
+type queryStart struct {
+	operation string
+	statement string
+	at        time.Time
+}
+
+func NewTracer(options ...TracerOption) *Tracer {
+	t := &Tracer{tracer: otel.Tracer("database"), now: time.Now}
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+	return t.start(ctx, operation, data.SQL,
+		attribute.String("db.operation", operation),
+		attribute.String("db.statement", data.SQL),
+	)
+}
+
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.end(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+func (t *Tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+
+	return t.start(ctx, "BATCH", "",
+		attribute.String("db.operation", "BATCH"),
+		attribute.Int("db.batch.size", size),
+	)
+}
+
+func (t *Tracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.AddEvent("query", trace.WithAttributes(
+		attribute.String("db.statement", data.SQL),
+		attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()),
+	))
+	if data.Err != nil {
+		span.RecordError(data.Err)
+	}
+}
+
+func (t *Tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, -1, data.Err)
+}
+
+func (t *Tracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return t.start(ctx, "COPY", "COPY "+data.TableName.Sanitize(),
+		attribute.String("db.operation", "COPY"),
+		attribute.String("db.sql.table", data.TableName.Sanitize()),
+	)
+}
+
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.end(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+// start records the start of the query, and starts a client span if the context is traced.
+func (t *Tracer) start(ctx context.Context, operation, statement string, attrs ...attribute.KeyValue) context.Context {
+	ctx = context.WithValue(ctx, queryStartKey{}, queryStart{operation: operation, statement: statement, at: t.now()})
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	ctx, _ = t.tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, attribute.String("db.system", "postgresql"))...))
+	return ctx
+}
+
+// end ends the span started by start, recording the affected rows unless negative, and the error. The query is
+// measured, and logged if it was slow.
+func (t *Tracer) end(ctx context.Context, rowsAffected int64, err error) {
+	if start, ok := ctx.Value(queryStartKey{}).(queryStart); ok {
+		t.observe(ctx, start, rowsAffected, err)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	if rowsAffected >= 0 {
+		span.SetAttributes(attribute.Int64("db.rows_affected", rowsAffected))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *Tracer) observe(ctx context.Context, start queryStart, rowsAffected int64, err error) {
+	duration := t.now().Sub(start.at)
+	if t.metrics != nil {
+		t.metrics.RecordQuery(ctx, start.operation, duration, err)
+	}
+
+	if t.log == nil || t.slowQueryThreshold <= 0 || duration < t.slowQueryThreshold {
+		return
+	}
+
+	// the arguments are left out, as they may hold credentials
+	fields := []zap.Field{
+		zap.String("operation", start.operation),
+		zap.String("statement", compactStatement(start.statement)),
+		zap.Duration("duration", duration),
+	}
+	if rowsAffected >= 0 {
+		fields = append(fields, zap.Int64("rowsAffected", rowsAffected))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	t.log.Ctx(ctx).Warn("Slow database query", fields...)
+}
+
+// maxLoggedStatementSize is the size above which the logged statements are truncated.
+const maxLoggedStatementSize = 2048
+
+// compactStatement collapses the whitespace of the statement and truncates it for logging.
+func compactStatement(statement string) string {
+	statement = strings.Join(strings.Fields(statement), " ")
+	if len(statement) > maxLoggedStatementSize {
+		statement = statement[:maxLoggedStatementSize] + "..."
+	}
+
+	return statement
+}
+
+// sqlOperation returns the first keyword of the statement, e.g. SELECT, as the operation.
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestTracer() (*Tracer, *sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer()
+	tracer.tracer = provider.Tracer("database")
+	return tracer, provider, recorder
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("Traces the queries of a trace", func(t *testing.T) {
+		tracer, provider, recorder := newTestTracer()
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+		defer parent.End()
+
+		ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "\n\tupdate jobs SET status = $1"})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "UPDATE", spans[0].Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.system", "postgresql"))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.statement", "\n\tupdate jobs SET status = $1"))
+		assert.Contains(t, spans[0].Attributes(), attribute.Int64("db.rows_affected", 3))
+	})
+
+	t.Run("Records the errors", func(t *testing.T) {
+		tracer, provider, recorder := newTestTracer()
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+		defer parent.End()
+
+		ctx = tracer.TraceCopyFromStart(ctx, nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"jobs"}})
+		tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{Err: errors.New("unique violation")})
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "COPY", spans[0].Name())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("db.sql.table", `"jobs"`))
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+	})
+
+	t.Run("Doesn't trace queries outside of a trace", func(t *testing.T) {
+		tracer, _, recorder := newTestTracer()
+		ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+		assert.Empty(t, recorder.Ended())
+	})
+}
+
+type recordedQuery struct {
+	operation string
+	duration  time.Duration
+	err       error
+}
+
+type mockQueryMetrics struct {
+	queries []recordedQuery
+}
+
+func (m *mockQueryMetrics) RecordQuery(_ context.Context, operation string, duration time.Duration, err error) {
+	m.queries = append(m.queries, recordedQuery{operation: operation, duration: duration, err: err})
+}
+
+func TestTracer_SlowQueries(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	queryMetrics := &mockQueryMetrics{}
+	tracer := NewTracer(WithLogger(otelzap.New(zap.New(core))), WithSlowQueryThreshold(time.Second), WithQueryMetrics(queryMetrics))
+
+	now := time.Now()
+	tracer.now = func() time.Time { return now }
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	now = now.Add(10 * time.Millisecond)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "UPDATE jobs\n\tSET status = $1"})
+	now = now.Add(2 * time.Second)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("canceling statement due to statement timeout")})
+
+	require.Len(t, queryMetrics.queries, 2)
+	assert.Equal(t, recordedQuery{operation: "SELECT", duration: 10 * time.Millisecond}, queryMetrics.queries[0])
+	assert.Equal(t, "UPDATE", queryMetrics.queries[1].operation)
+	assert.Equal(t, 2*time.Second, queryMetrics.queries[1].duration)
+	assert.Error(t, queryMetrics.queries[1].err)
+
+	// only the slow query is logged
+	entries := logs.All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Slow database query", entries[0].Message)
+	assert.Equal(t, "UPDATE jobs SET status = $1", entries[0].ContextMap()["statement"])
+	assert.Equal(t, 2*time.Second, entries[0].ContextMap()["duration"])
+}
@@ -0,0 +1,124 @@
+// Package debug serves pprof, expvar and the internal state of the services on a separate listener, kept off the API
+// so it can be bound to localhost or a private network.
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Settings configure the debug listener.
+type Settings struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Address the debug listener binds to, e.g. localhost:6060. Anyone reaching it can profile the process and read
+	// its internal state, keep it private.
+	Address string `mapstructure:"address" yaml:"address" json:"address,omitempty"`
+	// MutexProfileFraction samples 1/n of the mutex contention events in the mutex profile, disabled if zero
+	MutexProfileFraction int `mapstructure:"mutexProfileFraction" yaml:"mutexProfileFraction" json:"mutexProfileFraction,omitempty"`
+	// BlockProfileRate samples the goroutines blocked for longer than the rate in nanoseconds in the block profile,
+	// disabled if zero
+	BlockProfileRate int `mapstructure:"blockProfileRate" yaml:"blockProfileRate" json:"blockProfileRate,omitempty"`
+}
+
+// StateFunc returns a snapshot of the internal state of a component, encoded as JSON on /debug/state.
+type StateFunc func() any
+
+// Server is the debug listener.
+type Server struct {
+	server *http.Server
+	log    *otelzap.Logger
+
+	mu     sync.RWMutex
+	states map[string]StateFunc
+}
+
+// New creates the debug listener. It only listens once started.
+func New(settings Settings, log *otelzap.Logger) *Server {
+	s := &Server{
+		log:    log,
+		states: map[string]StateFunc{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/state", s.handleState)
+
+	s.server = &http.Server{
+		Addr:              settings.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	runtime.SetMutexProfileFraction(settings.MutexProfileFraction)
+	runtime.SetBlockProfileRate(settings.BlockProfileRate)
+
+	return s
+}
+
+// Register adds the state of a component to /debug/state under the name.
+func (s *Server) Register(name string, state StateFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[name] = state
+}
+
+// Start listens in a separate goroutine.
+func (s *Server) Start() {
+	go func() {
+		s.log.Info("Starting the debug listener", zap.String("address", s.server.Addr))
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("Debug listener failed", zap.Error(err))
+		}
+	}()
+}
+
+// Stop closes the listener, waiting for the requests in progress to finish or the context to expire. Running
+// profiles are cut short.
+func (s *Server) Stop(ctx context.Context) {
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.log.Warn("Timeout while stopping the debug listener", zap.Error(err))
+		_ = s.server.Close()
+		return
+	}
+
+	s.log.Info("Debug listener stopped")
+}
+
+// handleState writes the number of goroutines and the state of the registered components, by name.
+func (s *Server) handleState(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.states))
+	for name := range s.states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	state := make(map[string]any, len(names)+1)
+	state["goroutines"] = runtime.NumGoroutine()
+	for _, name := range names {
+		state[name] = s.states[name]()
+	}
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(state)
+}
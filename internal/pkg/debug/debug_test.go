@@ -0,0 +1,48 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+func TestServer(t *testing.T) {
+	s := New(Settings{Enabled: true, Address: "localhost:0"}, otelzap.New(zap.NewNop()))
+	s.Register("runner", func() any {
+		return map[string]int{"running": 2}
+	})
+
+	t.Run("State", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/state", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var state struct {
+			Goroutines int            `json:"goroutines"`
+			Runner     map[string]int `json:"runner"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+		assert.Positive(t, state.Goroutines)
+		assert.Equal(t, map[string]int{"running": 2}, state.Runner)
+	})
+
+	t.Run("Goroutine dump", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=2", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "goroutine ")
+	})
+
+	t.Run("Expvar", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		s.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "memstats")
+	})
+}
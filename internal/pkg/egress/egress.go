@@ -0,0 +1,254 @@
+// Package egress restricts the destinations the HTTP jobs can reach, so the URLs supplied by the tenants can't probe
+// the internal networks of the runners, e.g. the cloud metadata endpoints.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// ErrDenied is returned when the destination of a request is denied by the egress policy.
+var ErrDenied = errors.New("destination denied by the egress policy")
+
+// deniedPrefixes are the addresses denied unless allowed by the settings: the unspecified, loopback, private (RFC 1918,
+// unique local), shared (CGNAT), link-local (including the cloud metadata endpoints, e.g. 169.254.169.254),
+// benchmarking, reserved and multicast addresses.
+var deniedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// Settings configure the egress policy of the HTTP jobs.
+type Settings struct {
+	// Enabled denies the private, loopback, link-local and metadata addresses, and applies the lists below.
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// AllowHosts restricts the jobs to the hosts, e.g. api.example.com or *.example.com, any host if empty.
+	AllowHosts []string `mapstructure:"allowHosts" yaml:"allowHosts" json:"allowHosts,omitempty"`
+	// DenyHosts are hosts the jobs can't reach, e.g. *.internal.
+	DenyHosts []string `mapstructure:"denyHosts" yaml:"denyHosts" json:"denyHosts,omitempty"`
+	// AllowCIDRs are address ranges reachable despite being denied by default, e.g. a partner VPN subnet.
+	AllowCIDRs []string `mapstructure:"allowCidrs" yaml:"allowCidrs" json:"allowCidrs,omitempty"`
+	// DenyCIDRs are address ranges denied in addition to the default ones, e.g. the public ranges of the company.
+	DenyCIDRs []string `mapstructure:"denyCidrs" yaml:"denyCidrs" json:"denyCidrs,omitempty"`
+}
+
+// Resolver resolves the hosts to their addresses.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Policy checks the destinations of the requests against the settings.
+type Policy struct {
+	allowHosts []string
+	denyHosts  []string
+	allowCIDRs []netip.Prefix
+	denyCIDRs  []netip.Prefix
+
+	resolver Resolver
+	dialer   *net.Dialer
+}
+
+// NewPolicy returns the policy of the settings, or an error if a CIDR is invalid.
+func NewPolicy(settings Settings) (*Policy, error) {
+	allowCIDRs, err := parsePrefixes(settings.AllowCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	denyCIDRs, err := parsePrefixes(settings.DenyCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Policy{
+		allowHosts: normalizeHosts(settings.AllowHosts),
+		denyHosts:  normalizeHosts(settings.DenyHosts),
+		allowCIDRs: allowCIDRs,
+		denyCIDRs:  denyCIDRs,
+		resolver:   net.DefaultResolver,
+		dialer:     &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}, nil
+}
+
+// CheckHost reports whether the host, a name or an address, may be reached according to the host lists.
+func (p *Policy) CheckHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if matchHost(p.denyHosts, host) {
+		return fmt.Errorf("%w: host %s is denied", ErrDenied, host)
+	}
+
+	if len(p.allowHosts) > 0 && !matchHost(p.allowHosts, host) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrDenied, host)
+	}
+
+	return nil
+}
+
+// CheckAddr reports whether the address may be reached. The denied ranges take precedence over the allowed ones.
+func (p *Policy) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+
+	if containsAddr(p.denyCIDRs, addr) {
+		return fmt.Errorf("%w: address %s is denied", ErrDenied, addr)
+	}
+
+	if containsAddr(p.allowCIDRs, addr) {
+		return nil
+	}
+
+	if containsAddr(deniedPrefixes, addr) {
+		return fmt.Errorf("%w: address %s is private, loopback or link-local", ErrDenied, addr)
+	}
+
+	return nil
+}
+
+// DialContext resolves the host of the address, checks the host and all of its addresses, and dials the checked
+// addresses rather than resolving the host again, so the host can't be rebound to a denied address in between.
+func (p *Policy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckHost(host); err != nil {
+		return nil, err
+	}
+
+	addrs, err := p.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	// a host resolving to any denied address is denied, rather than dialing its allowed addresses only
+	for _, addr := range addrs {
+		if err := p.CheckAddr(addr); err != nil {
+			return nil, fmt.Errorf("%w (host %s)", err, host)
+		}
+	}
+
+	var errs []error
+	for _, addr := range addrs {
+		conn, err := p.dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// Client returns an HTTP client enforcing the policy on every connection, including the redirects. The proxies of
+// the environment are ignored, as the policy couldn't be enforced on the connections made by the proxies.
+func (p *Policy) Client(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = p.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func (p *Policy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for host %s", host)
+	}
+
+	return addrs, nil
+}
+
+// matchHost reports whether the host is one of the patterns, which are either exact hosts or wildcards matching
+// the subdomains of a domain, e.g. *.example.com.
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+			continue
+		}
+
+		if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeHosts(hosts []string) []string {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		normalized = append(normalized, strings.ToLower(strings.TrimSuffix(host, ".")))
+	}
+
+	return normalized
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid egress CIDR %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// NewClient returns an HTTP client enforcing the egress policy of the settings, or a plain client if it is disabled.
+func NewClient(settings Settings, timeout time.Duration) (*http.Client, error) {
+	if !settings.Enabled {
+		return &http.Client{Timeout: timeout}, nil
+	}
+
+	policy, err := NewPolicy(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return policy.Client(timeout), nil
+}
@@ -0,0 +1,118 @@
+package egress
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockResolver map[string][]netip.Addr
+
+func (m mockResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	return m[host], nil
+}
+
+func TestPolicyCheckAddr(t *testing.T) {
+	policy, err := NewPolicy(Settings{
+		Enabled:    true,
+		AllowCIDRs: []string{"10.20.0.0/16"},
+		DenyCIDRs:  []string{"203.0.113.0/24", "10.20.30.0/24"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{addr: "93.184.216.34", allowed: true},
+		{addr: "2606:2800:220:1::248", allowed: true},
+		{addr: "127.0.0.1", allowed: false},
+		{addr: "10.0.0.1", allowed: false},
+		{addr: "172.16.5.4", allowed: false},
+		{addr: "192.168.1.1", allowed: false},
+		{addr: "169.254.169.254", allowed: false},
+		{addr: "100.64.0.1", allowed: false},
+		{addr: "0.0.0.0", allowed: false},
+		{addr: "::1", allowed: false},
+		{addr: "::ffff:127.0.0.1", allowed: false},
+		{addr: "fd00:ec2::254", allowed: false},
+		{addr: "fe80::1%eth0", allowed: false},
+		{addr: "10.20.1.1", allowed: true},
+		{addr: "10.20.30.1", allowed: false},
+		{addr: "203.0.113.7", allowed: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.addr, func(t *testing.T) {
+			err := policy.CheckAddr(netip.MustParseAddr(tc.addr))
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckHost(t *testing.T) {
+	policy, err := NewPolicy(Settings{
+		Enabled:    true,
+		AllowHosts: []string{"api.example.com", "*.partner.io"},
+		DenyHosts:  []string{"admin.partner.io"},
+	})
+	require.NoError(t, err)
+
+	assert.NoError(t, policy.CheckHost("api.example.com"))
+	assert.NoError(t, policy.CheckHost("API.example.com."))
+	assert.NoError(t, policy.CheckHost("eu.partner.io"))
+	assert.ErrorIs(t, policy.CheckHost("partner.io"), ErrDenied)
+	assert.ErrorIs(t, policy.CheckHost("admin.partner.io"), ErrDenied)
+	assert.ErrorIs(t, policy.CheckHost("example.com"), ErrDenied)
+}
+
+func TestNewPolicyInvalidCIDR(t *testing.T) {
+	_, err := NewPolicy(Settings{Enabled: true, DenyCIDRs: []string{"10.0.0.0/33"}})
+	assert.Error(t, err)
+}
+
+func TestPolicyClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	port := netip.MustParseAddrPort(server.Listener.Addr().String()).Port()
+	loopback := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+
+	t.Run("Denies the loopback address", func(t *testing.T) {
+		policy, err := NewPolicy(Settings{Enabled: true})
+		require.NoError(t, err)
+
+		_, err = policy.Client(time.Second).Get(server.URL)
+		assert.ErrorIs(t, err, ErrDenied)
+	})
+
+	t.Run("Denies hosts resolving to a denied address", func(t *testing.T) {
+		policy, err := NewPolicy(Settings{Enabled: true})
+		require.NoError(t, err)
+		policy.resolver = mockResolver{"metadata.example.com": {netip.MustParseAddr("93.184.216.34"), loopback[0]}}
+
+		_, err = policy.Client(time.Second).Get("http://metadata.example.com/")
+		assert.ErrorIs(t, err, ErrDenied)
+	})
+
+	t.Run("Dials the resolved address", func(t *testing.T) {
+		policy, err := NewPolicy(Settings{Enabled: true, AllowCIDRs: []string{"127.0.0.0/8"}})
+		require.NoError(t, err)
+		policy.resolver = mockResolver{"service.example.com": loopback}
+
+		resp, err := policy.Client(time.Second).Get(fmt.Sprintf("http://service.example.com:%d/", port))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
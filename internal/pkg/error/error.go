@@ -5,28 +5,99 @@ import (
 )
 
 var (
-	ErrInvalidJobType        = errors.New("job type must be either HTTP or AMQP")
-	ErrInvalidJobID          = errors.New("job ID must be a valid UUID")
-	ErrInvalidJobStatus      = errors.New("job status must be either PENDING, SCHEDULED, SUCCESSFUL, or FAILED")
-	ErrInvalidJobFields      = errors.New("job cannot have both HTTP and AMQP fields defined")
-	ErrInvalidJobSchedule    = errors.New("job must have only one of execute_at and cron_schedule defined")
-	ErrInvalidCronSchedule   = errors.New("invalid cron schedule")
-	ErrInvalidExecuteAt      = errors.New("execute_at must be in the future")
-	ErrEmptyHTTPJobURL       = errors.New("HTTP job URL cannot be empty")
-	ErrHTTPJobNotDefined     = errors.New("HTTP job must be defined")
-	ErrEmptyHTTPJobMethod    = errors.New("HTTP job method cannot be empty")
-	ErrAMQPJobNotDefined     = errors.New("AMQP job must be defined")
-	ErrAMQPConnectionInvalid = errors.New("AMQP connection string is invalid")
-	ErrEmptyExchange         = errors.New("exchange must be defined for AMQP jobs")
-	ErrEmptyRoutingKey       = errors.New("routing key must be defined for AMQP jobs")
-	ErrInvalidAuthType       = errors.New("auth type must be either none, basic, or bearer")
-	ErrEmptyUsername         = errors.New("username must be defined for basic auth")
-	ErrEmptyPassword         = errors.New("password must be defined for basic auth")
-	ErrEmptyBearerToken      = errors.New("bearer token must be defined for bearer auth")
-	ErrAuthMethodNotDefined  = errors.New("auth method must be defined")
-	ErrJobNotFound           = errors.New("job not found")
-	ErrInvalidResponseCode   = errors.New("invalid response code")
-	ErrInvalidBodyEncoding   = errors.New("invalid body encoding")
+	ErrInvalidJobType            = errors.New("job type must be either HTTP, AMQP or a registered custom job type")
+	ErrInvalidJobID              = errors.New("job ID must be a valid UUID")
+	ErrInvalidJobStatus          = errors.New("job status must be either PENDING, SCHEDULED, SUCCESSFUL, or FAILED")
+	ErrInvalidJobFields          = errors.New("job can only define one of http_job, amqp_job or custom_job, matching its type")
+	ErrInvalidJobSchedule        = errors.New("job must have only one of execute_at and cron_schedule defined")
+	ErrInvalidCronSchedule       = errors.New("invalid cron schedule")
+	ErrInvalidExecuteAt          = errors.New("execute_at must be in the future")
+	ErrEmptyHTTPJobURL           = errors.New("HTTP job URL cannot be empty")
+	ErrHTTPJobNotDefined         = errors.New("HTTP job must be defined")
+	ErrEmptyHTTPJobMethod        = errors.New("HTTP job method cannot be empty")
+	ErrAMQPJobNotDefined         = errors.New("AMQP job must be defined")
+	ErrAMQPConnectionInvalid     = errors.New("AMQP connection string is invalid")
+	ErrEmptyExchange             = errors.New("exchange must be defined for AMQP jobs")
+	ErrEmptyRoutingKey           = errors.New("routing key must be defined for AMQP jobs")
+	ErrInvalidAuthType           = errors.New("auth type must be either none, basic, or bearer")
+	ErrEmptyUsername             = errors.New("username must be defined for basic auth")
+	ErrEmptyPassword             = errors.New("password must be defined for basic auth")
+	ErrEmptyBearerToken          = errors.New("bearer token must be defined for bearer auth")
+	ErrAuthMethodNotDefined      = errors.New("auth method must be defined")
+	ErrJobNotFound               = errors.New("job not found")
+	ErrInvalidResponseCode       = errors.New("invalid response code")
+	ErrInvalidBodyEncoding       = errors.New("invalid body encoding")
+	ErrHTTPJobBodyTooLarge       = errors.New("HTTP job body exceeds the maximum body size")
+	ErrHTTPJobHeadersTooLarge    = errors.New("HTTP job headers exceed the maximum headers size")
+	ErrAMQPJobBodyTooLarge       = errors.New("AMQP job body exceeds the maximum body size")
+	ErrAMQPJobHeadersTooLarge    = errors.New("AMQP job headers exceed the maximum headers size")
+	ErrCustomJobNotDefined       = errors.New("custom job must be defined as a JSON object")
+	ErrCustomJobTooLarge         = errors.New("custom job exceeds the maximum body size")
+	ErrRequestBodyTooLarge       = errors.New("request body exceeds the maximum request size")
+	ErrInvalidJobTTL             = errors.New("ttl must be positive and can only be set on one-off jobs")
+	ErrInvalidCapabilities       = errors.New("capabilities must be at most 32 labels of alphanumeric characters, '.', '_', '/' or '-', e.g. region=eu")
+	ErrInvalidExecutionTimeout   = errors.New("execution timeout must be between 1 second and 24 hours")
+	ErrInvalidMisfirePolicy      = errors.New("misfire policy must be either RUN_ONCE or SKIP")
+	ErrInvalidMetricLabels       = errors.New("metric labels must be at most 8 distinct labels among job_id, job_name and tag:<key>")
+	ErrInvalidJobMetadata        = errors.New("metadata must have at most 64 entries, with keys of alphanumeric characters, '.', '_', '/' or '-' and values of at most 1024 bytes")
+	ErrInvalidJobSLA             = errors.New("SLA must set a max_delay of at least 1 second and/or a min_success_rate between 0 and 100, over a window between 1 hour and 90 days")
+	ErrJobSLANotFound            = errors.New("job has no SLA")
+	ErrInvalidResultSink         = errors.New("result sink must be either AMQP, with the url of the broker and an exchange, or KAFKA, with the url of the REST proxy and a topic")
+	ErrInvalidJobPriority        = errors.New("priority must be one of LOW, NORMAL or HIGH")
+	ErrStaleExecution            = errors.New("execution skipped: scheduling drift exceeded the allowed threshold")
+	ErrUnsupportedRRule          = errors.New("RRULE schedules are not supported, use a cron expression, optionally prefixed with CRON_TZ=<time zone>, or an anchored interval, e.g. @every 24h starting 2024-01-01T03:00:00Z")
+	ErrInvalidCursor             = errors.New("invalid pagination cursor")
+	ErrInvalidExportRange        = errors.New("export range must end after it starts")
+	ErrInvalidBackfill           = errors.New("backfill must cover a past range of at most 1000 runs of a recurring job")
+	ErrInvalidJobFilter          = errors.New("invalid job filter")
+	ErrInvalidJobFieldSelection  = errors.New("fields must be a comma-separated list of job fields, e.g. id,status,next_run")
+	ErrExecutionNotFound         = errors.New("job execution not found")
+	ErrExecutionNotRunning       = errors.New("job execution is not running")
+	ErrExecutionOutputNotFound   = errors.New("job execution has no output")
+	ErrInvalidExecutionProgress  = errors.New("progress must be between 0 and 100 percent, with a message of at most 1024 characters")
+	ErrExecutionCancelled        = errors.New("job execution was cancelled")
+	ErrExecutionInterrupted      = errors.New("job execution was interrupted by the runner stopping")
+	ErrExecutionTimedOut         = errors.New("job execution exceeded its execution timeout")
+	ErrExecutorPanicked          = errors.New("job executor panicked")
+	ErrCircuitOpen               = errors.New("job target is failing repeatedly, its circuit breaker is open")
+	ErrExecutionAbandoned        = errors.New("job execution was abandoned: its runner stopped renewing the job lock, e.g. because it crashed")
+	ErrJobLockLost               = errors.New("job is no longer locked by the instance")
+	ErrInvalidEventFilter        = errors.New("invalid event filter")
+	ErrInvalidWebhookURL         = errors.New("webhook URL must be an absolute HTTP or HTTPS URL")
+	ErrInvalidWebhookSecret      = errors.New("webhook secret must be at least 16 characters long")
+	ErrInvalidWebhookFormat      = errors.New("webhook format must be json or cloudevents")
+	ErrWebhookNotFound           = errors.New("webhook not found")
+	ErrInvalidNotificationRule   = errors.New("notification rule must have a valid channel and target, and triggers among FAILURE, RECOVERY and DISABLED, RECOVERY requiring FAILURE")
+	ErrNotificationRuleNotFound  = errors.New("notification rule not found")
+	ErrInvalidAPIKeyName         = errors.New("API key name cannot be empty")
+	ErrAPIKeyNotFound            = errors.New("API key not found")
+	ErrUnauthorized              = errors.New("missing or invalid API key")
+	ErrForbidden                 = errors.New("API key is not allowed to perform this operation")
+	ErrInvalidNamespace          = errors.New("namespace must consist of at most 63 lowercase alphanumeric characters or '-'")
+	ErrInvalidNamespaceQuotas    = errors.New("namespace quotas cannot be negative")
+	ErrNamespaceQuotaExceeded    = errors.New("namespace job quota exceeded")
+	ErrNamespaceKeysDisabled     = errors.New("namespace encryption keys are disabled")
+	ErrInvalidTeam               = errors.New("team must consist of at most 63 lowercase alphanumeric characters or '-'")
+	ErrInvalidJobOwner           = errors.New("job owner cannot be blank or longer than 128 characters")
+	ErrInvalidJobName            = errors.New("job name must consist of at most 128 alphanumeric characters, '.', '_' or '-'")
+	ErrJobNameTaken              = errors.New("a job with the same name already exists in the namespace")
+	ErrInvalidExternalID         = errors.New("external ID must consist of at most 255 alphanumeric characters, '.', '_', '-', ':' or '@'")
+	ErrJobExternalIDTaken        = errors.New("a job with the same external ID already exists in the namespace")
+	ErrInvalidManifest           = errors.New("manifest jobs must be named, with unique names and external IDs, and there can be at most 1000 jobs")
+	ErrJobVersionMismatch        = errors.New("job was modified since it was read, get it again and retry")
+	ErrInvalidMergePatch         = errors.New("merge patch must be a JSON object with the fields of a job")
+	ErrInvalidRetryDefinition    = errors.New("retry definition must be either 'original' or 'current'")
+	ErrExecutionNotRetryable     = errors.New("job definition of the execution was not recorded, retry it with the current definition")
+	ErrInvalidBulkRequest        = errors.New("bulk requests must contain between 1 and 500 items and select jobs either by IDs, by tags or by a tag selector")
+	ErrInvalidTagSelector        = errors.New("tag selector must be requirements (key=value, key!=value, key or !key) joined by AND")
+	ErrInvalidTagRename          = errors.New("tags can only be renamed to a different tag of alphanumeric characters, '.', '_', '/' or '-', optionally as key=value")
+	ErrInvalidTemplate           = errors.New("template must have a valid name, at most 64 uniquely named parameters and a JSON object definition only using declared parameters")
+	ErrInvalidTemplateParameters = errors.New("template parameters must be declared by the template, and parameters without a default value are required")
+	ErrTemplateNotFound          = errors.New("job template not found")
+	ErrTemplateNameTaken         = errors.New("a job template with the same name already exists in the namespace")
+	ErrInvalidCronJobImport      = errors.New("CronJob imports must have between 1 and 500 CronJobs, and an HTTP or KUBERNETES mapping whose definition is a JSON object only using the name and namespace placeholders")
+	ErrInvalidCrontabImport      = errors.New("crontab imports must have between 1 and 500 commands, and an HTTP or SHELL mapping whose definition is a JSON object only using the command, input, user and line placeholders")
+	ErrInvalidRunOverrides       = errors.New("run overrides must match the type of the job, and template parameters can only override jobs instantiated from a template")
 )
 
 type CustomError struct {
@@ -57,10 +128,79 @@ func ToCustomJobError(err error) *CustomError {
 		errors.Is(err, ErrEmptyUsername),
 		errors.Is(err, ErrEmptyPassword),
 		errors.Is(err, ErrEmptyBearerToken),
-		errors.Is(err, ErrAuthMethodNotDefined):
+		errors.Is(err, ErrAuthMethodNotDefined),
+		errors.Is(err, ErrCustomJobNotDefined),
+		errors.Is(err, ErrInvalidJobTTL),
+		errors.Is(err, ErrInvalidJobPriority),
+		errors.Is(err, ErrInvalidCapabilities),
+		errors.Is(err, ErrInvalidExecutionTimeout),
+		errors.Is(err, ErrInvalidMisfirePolicy),
+		errors.Is(err, ErrInvalidJobSLA),
+		errors.Is(err, ErrInvalidMetricLabels),
+		errors.Is(err, ErrInvalidJobMetadata),
+		errors.Is(err, ErrInvalidResultSink),
+		errors.Is(err, ErrUnsupportedRRule),
+		errors.Is(err, ErrInvalidCursor),
+		errors.Is(err, ErrInvalidExportRange),
+		errors.Is(err, ErrInvalidBackfill),
+		errors.Is(err, ErrInvalidJobFilter),
+		errors.Is(err, ErrInvalidJobFieldSelection),
+		errors.Is(err, ErrInvalidBulkRequest),
+		errors.Is(err, ErrInvalidTagSelector),
+		errors.Is(err, ErrInvalidTagRename),
+		errors.Is(err, ErrInvalidTemplate),
+		errors.Is(err, ErrInvalidTemplateParameters),
+		errors.Is(err, ErrInvalidCrontabImport),
+		errors.Is(err, ErrInvalidCronJobImport),
+		errors.Is(err, ErrInvalidRunOverrides),
+		errors.Is(err, ErrInvalidEventFilter),
+		errors.Is(err, ErrInvalidWebhookURL),
+		errors.Is(err, ErrInvalidWebhookSecret),
+		errors.Is(err, ErrInvalidWebhookFormat),
+		errors.Is(err, ErrInvalidNotificationRule),
+		errors.Is(err, ErrInvalidAPIKeyName),
+		errors.Is(err, ErrInvalidNamespace),
+		errors.Is(err, ErrInvalidNamespaceQuotas),
+		errors.Is(err, ErrInvalidTeam),
+		errors.Is(err, ErrInvalidJobOwner),
+		errors.Is(err, ErrInvalidJobName),
+		errors.Is(err, ErrInvalidExternalID),
+		errors.Is(err, ErrInvalidManifest),
+		errors.Is(err, ErrInvalidMergePatch),
+		errors.Is(err, ErrInvalidRetryDefinition),
+		errors.Is(err, ErrInvalidExecutionProgress):
 		return &CustomError{err, 400}
-	case errors.Is(err, ErrJobNotFound):
+	case errors.Is(err, ErrJobNotFound),
+		errors.Is(err, ErrExecutionNotFound),
+		errors.Is(err, ErrExecutionOutputNotFound),
+		errors.Is(err, ErrWebhookNotFound),
+		errors.Is(err, ErrNotificationRuleNotFound),
+		errors.Is(err, ErrJobSLANotFound),
+		errors.Is(err, ErrTemplateNotFound),
+		errors.Is(err, ErrAPIKeyNotFound):
 		return &CustomError{err, 404}
+	case errors.Is(err, ErrUnauthorized):
+		return &CustomError{err, 401}
+	case errors.Is(err, ErrForbidden):
+		return &CustomError{err, 403}
+	case errors.Is(err, ErrHTTPJobBodyTooLarge),
+		errors.Is(err, ErrHTTPJobHeadersTooLarge),
+		errors.Is(err, ErrAMQPJobBodyTooLarge),
+		errors.Is(err, ErrAMQPJobHeadersTooLarge),
+		errors.Is(err, ErrCustomJobTooLarge),
+		errors.Is(err, ErrRequestBodyTooLarge):
+		return &CustomError{err, 413}
+	case errors.Is(err, ErrNamespaceQuotaExceeded):
+		return &CustomError{err, 429}
+	case errors.Is(err, ErrJobVersionMismatch):
+		return &CustomError{err, 412}
+	case errors.Is(err, ErrExecutionNotRunning),
+		errors.Is(err, ErrExecutionNotRetryable),
+		errors.Is(err, ErrJobNameTaken),
+		errors.Is(err, ErrJobExternalIDTaken),
+		errors.Is(err, ErrTemplateNameTaken),
+		errors.Is(err, ErrNamespaceKeysDisabled):
+		return &CustomError{err, 409}
 	default:
 		return &CustomError{err, 500}
 	}
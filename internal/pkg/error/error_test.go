@@ -16,6 +16,46 @@ func TestToCustomJobError(t *testing.T) {
 		{"ErrEmptyPassword", ErrEmptyPassword, 400},
 		{"ErrEmptyBearerToken", ErrEmptyBearerToken, 400},
 		{"ErrAuthMethodNotDefined", ErrAuthMethodNotDefined, 400},
+		{"ErrExecutionNotFound", ErrExecutionNotFound, 404},
+		{"ErrExecutionNotRunning", ErrExecutionNotRunning, 409},
+		{"ErrExecutionOutputNotFound", ErrExecutionOutputNotFound, 404},
+		{"ErrInvalidManifest", ErrInvalidManifest, 400},
+		{"ErrInvalidJobPriority", ErrInvalidJobPriority, 400},
+		{"ErrInvalidCapabilities", ErrInvalidCapabilities, 400},
+		{"ErrInvalidExecutionTimeout", ErrInvalidExecutionTimeout, 400},
+		{"ErrInvalidMisfirePolicy", ErrInvalidMisfirePolicy, 400},
+		{"ErrInvalidJobSLA", ErrInvalidJobSLA, 400},
+		{"ErrInvalidMetricLabels", ErrInvalidMetricLabels, 400},
+		{"ErrInvalidJobMetadata", ErrInvalidJobMetadata, 400},
+		{"ErrInvalidResultSink", ErrInvalidResultSink, 400},
+		{"ErrJobNameTaken", ErrJobNameTaken, 409},
+		{"ErrInvalidExternalID", ErrInvalidExternalID, 400},
+		{"ErrJobExternalIDTaken", ErrJobExternalIDTaken, 409},
+		{"ErrJobVersionMismatch", ErrJobVersionMismatch, 412},
+		{"ErrInvalidMergePatch", ErrInvalidMergePatch, 400},
+		{"ErrInvalidRetryDefinition", ErrInvalidRetryDefinition, 400},
+		{"ErrExecutionNotRetryable", ErrExecutionNotRetryable, 409},
+		{"ErrInvalidExecutionProgress", ErrInvalidExecutionProgress, 400},
+		{"ErrInvalidJobFieldSelection", ErrInvalidJobFieldSelection, 400},
+		{"ErrInvalidExportRange", ErrInvalidExportRange, 400},
+		{"ErrInvalidTagSelector", ErrInvalidTagSelector, 400},
+		{"ErrInvalidTagRename", ErrInvalidTagRename, 400},
+		{"ErrInvalidTemplate", ErrInvalidTemplate, 400},
+		{"ErrInvalidTemplateParameters", ErrInvalidTemplateParameters, 400},
+		{"ErrInvalidCrontabImport", ErrInvalidCrontabImport, 400},
+		{"ErrInvalidCronJobImport", ErrInvalidCronJobImport, 400},
+		{"ErrInvalidRunOverrides", ErrInvalidRunOverrides, 400},
+		{"ErrTemplateNotFound", ErrTemplateNotFound, 404},
+		{"ErrJobSLANotFound", ErrJobSLANotFound, 404},
+		{"ErrTemplateNameTaken", ErrTemplateNameTaken, 409},
+		{"ErrHTTPJobBodyTooLarge", ErrHTTPJobBodyTooLarge, 413},
+		{"ErrAMQPJobHeadersTooLarge", ErrAMQPJobHeadersTooLarge, 413},
+		{"ErrCustomJobNotDefined", ErrCustomJobNotDefined, 400},
+		{"ErrCustomJobTooLarge", ErrCustomJobTooLarge, 413},
+		{"ErrRequestBodyTooLarge", ErrRequestBodyTooLarge, 413},
+		{"ErrUnauthorized", ErrUnauthorized, 401},
+		{"ErrForbidden", ErrForbidden, 403},
+		{"ErrNamespaceQuotaExceeded", ErrNamespaceQuotaExceeded, 429},
 		{"Other error", errors.New("other error"), 500},
 	}
 
@@ -0,0 +1,197 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Elector elects a single leader among the instances competing for the same lock, so periodic maintenance tasks
+// are performed by exactly one of them. The leader holds a session-level Postgres advisory lock on a dedicated
+// connection. The lock is released when the elector stops, or by the database when the connection drops, e.g.
+// because the instance crashed, and another instance takes over on its next attempt.
+//
+// The leader checks it still holds the connection every interval, so two instances can briefly both consider
+// themselves the leader after a connection loss. Tasks run by the leader must be safe to run concurrently.
+type Elector struct {
+	db       *sqlx.DB
+	log      *otelzap.Logger
+	name     string
+	key      int64
+	interval time.Duration
+	leader   atomic.Bool
+
+	// Add a context and cancel function to stop the elector
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Add a wait group to wait for the elector to stop
+	stopWg sync.WaitGroup
+
+	// Add a sync.Once to ensure the elector only starts once
+	startOnce sync.Once
+}
+
+type Config struct {
+	DB  *sqlx.DB
+	Log *otelzap.Logger
+	// Name of the election, instances using the same name elect a single leader
+	Name     string
+	Settings Settings
+}
+
+type Settings struct {
+	// Interval is how often followers try to become the leader, and the leader checks it still holds the lock
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+}
+
+func New(cfg Config) *Elector {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	e := &Elector{
+		db:       cfg.DB,
+		log:      cfg.Log,
+		name:     cfg.Name,
+		key:      lockKey(cfg.Name),
+		interval: cfg.Settings.Interval,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	e.stopWg.Add(1)
+
+	return e
+}
+
+// lockKey returns the advisory lock key of the election.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("leader:" + name))
+
+	return int64(h.Sum64())
+}
+
+// IsLeader reports whether the instance is currently the leader.
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Start starts the election in a separate goroutine.
+// Only the first call will start the elector, subsequent calls are ignored.
+func (e *Elector) Start() {
+	e.startOnce.Do(func() {
+		go e.run()
+	})
+}
+
+// Stop stops the elector, giving up the leadership if the instance is the leader.
+func (e *Elector) Stop(ctx context.Context) {
+	e.cancel()
+
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		e.stopWg.Wait()
+	}()
+
+	select {
+	case <-c:
+		e.log.Info("Leader elector stopped", zap.String("name", e.name))
+	case <-ctx.Done():
+		e.log.Warn("Timeout while stopping the leader elector", zap.String("name", e.name))
+	}
+}
+
+func (e *Elector) run() {
+	defer e.stopWg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	var conn *sql.Conn
+	for {
+		conn = e.elect(conn)
+
+		select {
+		case <-ticker.C:
+		case <-e.ctx.Done():
+			if conn != nil {
+				e.resign(conn)
+			}
+			return
+		}
+	}
+}
+
+// elect checks the leader still holds the lock, or tries to acquire it. It returns the connection holding
+// the lock, or nil if the instance is not the leader.
+func (e *Elector) elect(conn *sql.Conn) *sql.Conn {
+	ctx, cancel := context.WithTimeout(e.ctx, e.interval)
+	defer cancel()
+
+	if conn != nil {
+		err := conn.PingContext(ctx)
+		if err == nil {
+			return conn
+		}
+
+		e.log.Warn("Lost the leadership", zap.String("name", e.name), zap.Error(err))
+		e.leader.Store(false)
+		_ = conn.Close()
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		e.log.Warn("Failed to acquire a connection for the leader election", zap.String("name", e.name), zap.Error(err))
+		return nil
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, e.key).Scan(&acquired); err != nil || !acquired {
+		if err != nil {
+			e.log.Warn("Failed to run for leader", zap.String("name", e.name), zap.Error(err))
+		}
+
+		_ = conn.Close()
+		return nil
+	}
+
+	e.log.Info("Elected leader", zap.String("name", e.name))
+	e.leader.Store(true)
+
+	return conn
+}
+
+// resign releases the lock, so another instance becomes the leader right away.
+func (e *Elector) resign(conn *sql.Conn) {
+	e.leader.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, e.key); err != nil {
+		e.log.Warn("Failed to release the leadership", zap.String("name", e.name), zap.Error(err))
+	}
+
+	_ = conn.Close()
+}
+
+// Leader tells whether the instance is the leader of the maintenance tasks. Elector and Static are leaders.
+type Leader interface {
+	IsLeader() bool
+}
+
+// Static is a leadership which never changes, e.g. of a single instance which is always the leader.
+type Static bool
+
+// IsLeader reports whether the instance is the leader.
+func (s Static) IsLeader() bool {
+	return bool(s)
+}
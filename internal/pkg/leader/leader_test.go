@@ -0,0 +1,60 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database/dbtest"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/tests/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dbtest.StopDB(c)
+
+	m.Run()
+}
+
+func TestElector(t *testing.T) {
+	test := dbtest.NewTest(t, c)
+	defer test.Teardown()
+
+	newElector := func(name string) *Elector {
+		return New(Config{DB: test.DB, Log: test.Log, Name: name, Settings: Settings{Interval: time.Millisecond * 50}})
+	}
+
+	first, second, other := newElector("maintenance"), newElector("maintenance"), newElector("other")
+
+	first.Start()
+	assert.Eventually(t, first.IsLeader, time.Second, time.Millisecond*10)
+
+	// a single leader is elected per name
+	second.Start()
+	other.Start()
+	assert.Eventually(t, other.IsLeader, time.Second, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 200)
+	assert.False(t, second.IsLeader())
+
+	// another instance takes over once the leader stops
+	first.Stop(context.Background())
+	assert.False(t, first.IsLeader())
+	assert.Eventually(t, second.IsLeader, time.Second, time.Millisecond*10)
+
+	second.Stop(context.Background())
+	other.Stop(context.Background())
+}
+
+func TestLockKey(t *testing.T) {
+	assert.Equal(t, lockKey("maintenance"), lockKey("maintenance"))
+	assert.NotEqual(t, lockKey("maintenance"), lockKey("other"))
+}
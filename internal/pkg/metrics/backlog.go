@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	backlogDueJobs           = "scheduler_backlog_due_jobs"
+	backlogLockWait          = "scheduler_backlog_lock_wait"
+	backlogPendingRetries    = "scheduler_backlog_pending_retries"
+	backlogRunningExecutions = "scheduler_backlog_running_executions"
+	backlogDemand            = "scheduler_backlog_demand"
+	backlogCapacity          = "scheduler_backlog_runner_capacity"
+	backlogUtilization       = "scheduler_backlog_utilization"
+)
+
+// backlogTimeout bounds the time spent observing the backlog on each collection.
+const backlogTimeout = 5 * time.Second
+
+// BacklogObserver returns the current backlog.
+type BacklogObserver func(ctx context.Context) (*model.Backlog, error)
+
+// RegisterBacklogMetrics registers gauges observing the backlog on each collection, to autoscale the runners on.
+func RegisterBacklogMetrics(config observability.MetricsConfig, observe BacklogObserver) {
+	if !config.Enabled {
+		return
+	}
+
+	meter := otel.GetMeterProvider().Meter("backlog")
+
+	dueJobs, err := meter.Int64ObservableGauge(backlogDueJobs)
+	must(err)
+
+	lockWait, err := meter.Float64ObservableGauge(backlogLockWait, metric.WithUnit("s"))
+	must(err)
+
+	pendingRetries, err := meter.Int64ObservableGauge(backlogPendingRetries)
+	must(err)
+
+	runningExecutions, err := meter.Int64ObservableGauge(backlogRunningExecutions)
+	must(err)
+
+	demand, err := meter.Int64ObservableGauge(backlogDemand)
+	must(err)
+
+	capacity, err := meter.Int64ObservableGauge(backlogCapacity)
+	must(err)
+
+	utilization, err := meter.Float64ObservableGauge(backlogUtilization)
+	must(err)
+
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		ctx, cancel := context.WithTimeout(ctx, backlogTimeout)
+		defer cancel()
+
+		backlog, err := observe(ctx)
+		if err != nil {
+			return err
+		}
+
+		o.ObserveInt64(dueJobs, int64(backlog.DueJobs))
+		o.ObserveFloat64(lockWait, backlog.LockWaitSeconds)
+		o.ObserveInt64(pendingRetries, int64(backlog.PendingRetries))
+		o.ObserveInt64(runningExecutions, int64(backlog.RunningExecutions))
+		o.ObserveInt64(demand, int64(backlog.Demand))
+		o.ObserveInt64(capacity, int64(backlog.Capacity))
+		o.ObserveFloat64(utilization, backlog.Utilization)
+		return nil
+	}, dueJobs, lockWait, pendingRetries, runningExecutions, demand, capacity, utilization)
+	must(err)
+}
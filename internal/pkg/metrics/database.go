@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	dbPoolAcquiredConns        = "scheduler_db_pool_acquired_connections"
+	dbPoolIdleConns            = "scheduler_db_pool_idle_connections"
+	dbPoolTotalConns           = "scheduler_db_pool_total_connections"
+	dbPoolMaxConns             = "scheduler_db_pool_max_connections"
+	dbPoolAcquires             = "scheduler_db_pool_acquires"
+	dbPoolEmptyAcquires        = "scheduler_db_pool_empty_acquires"
+	dbPoolCanceledAcquires     = "scheduler_db_pool_canceled_acquires"
+	dbPoolAcquireDuration      = "scheduler_db_pool_acquire_duration"
+	dbPoolNewConns             = "scheduler_db_pool_new_connections"
+	dbPoolMaxLifetimeDestroyed = "scheduler_db_pool_max_lifetime_destroyed"
+	dbPoolMaxIdleDestroyed     = "scheduler_db_pool_max_idle_destroyed"
+	dbQueryDuration            = "scheduler_db_query_duration"
+	dbQueryErrors              = "scheduler_db_query_errors"
+)
+
+// RegisterDBPoolMetrics registers instruments observing the statistics of the database connection pool on each
+// collection.
+func RegisterDBPoolMetrics(config observability.MetricsConfig, pool *pgxpool.Pool) {
+	if !config.Enabled {
+		return
+	}
+
+	meter := otel.GetMeterProvider().Meter("database")
+
+	acquiredConns, err := meter.Int64ObservableGauge(dbPoolAcquiredConns)
+	must(err)
+
+	idleConns, err := meter.Int64ObservableGauge(dbPoolIdleConns)
+	must(err)
+
+	totalConns, err := meter.Int64ObservableGauge(dbPoolTotalConns)
+	must(err)
+
+	maxConns, err := meter.Int64ObservableGauge(dbPoolMaxConns)
+	must(err)
+
+	acquires, err := meter.Int64ObservableCounter(dbPoolAcquires)
+	must(err)
+
+	emptyAcquires, err := meter.Int64ObservableCounter(dbPoolEmptyAcquires)
+	must(err)
+
+	canceledAcquires, err := meter.Int64ObservableCounter(dbPoolCanceledAcquires)
+	must(err)
+
+	acquireDuration, err := meter.Float64ObservableCounter(dbPoolAcquireDuration, metric.WithUnit("s"))
+	must(err)
+
+	newConns, err := meter.Int64ObservableCounter(dbPoolNewConns)
+	must(err)
+
+	maxLifetimeDestroyed, err := meter.Int64ObservableCounter(dbPoolMaxLifetimeDestroyed)
+	must(err)
+
+	maxIdleDestroyed, err := meter.Int64ObservableCounter(dbPoolMaxIdleDestroyed)
+	must(err)
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stat := pool.Stat()
+
+		o.ObserveInt64(acquiredConns, int64(stat.AcquiredConns()))
+		o.ObserveInt64(idleConns, int64(stat.IdleConns()))
+		o.ObserveInt64(totalConns, int64(stat.TotalConns()))
+		o.ObserveInt64(maxConns, int64(stat.MaxConns()))
+		o.ObserveInt64(acquires, stat.AcquireCount())
+		o.ObserveInt64(emptyAcquires, stat.EmptyAcquireCount())
+		o.ObserveInt64(canceledAcquires, stat.CanceledAcquireCount())
+		o.ObserveFloat64(acquireDuration, stat.AcquireDuration().Seconds())
+		o.ObserveInt64(newConns, stat.NewConnsCount())
+		o.ObserveInt64(maxLifetimeDestroyed, stat.MaxLifetimeDestroyCount())
+		o.ObserveInt64(maxIdleDestroyed, stat.MaxIdleDestroyCount())
+		return nil
+	}, acquiredConns, idleConns, totalConns, maxConns, acquires, emptyAcquires, canceledAcquires, acquireDuration,
+		newConns, maxLifetimeDestroyed, maxIdleDestroyed)
+	must(err)
+}
+
+// DBQueryMetrics records the duration and the errors of the database queries, by SQL operation.
+type DBQueryMetrics struct {
+	enabled bool
+
+	queryDuration metric.Float64Histogram
+
+	queryErrors metric.Int64Counter
+}
+
+func NewDBQueryMetrics(config observability.MetricsConfig) *DBQueryMetrics {
+	if !config.Enabled {
+		return &DBQueryMetrics{enabled: false}
+	}
+
+	meter := otel.GetMeterProvider().Meter("database")
+
+	queryDuration, err := meter.Float64Histogram(dbQueryDuration, metric.WithUnit("s"))
+	must(err)
+
+	queryErrors, err := meter.Int64Counter(dbQueryErrors)
+	must(err)
+
+	return &DBQueryMetrics{
+		enabled:       true,
+		queryDuration: queryDuration,
+		queryErrors:   queryErrors,
+	}
+}
+
+// RecordQuery records the duration of the query, and counts it if it failed.
+func (m *DBQueryMetrics) RecordQuery(ctx context.Context, operation string, duration time.Duration, err error) {
+	if !m.enabled {
+		return
+	}
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+		m.queryErrors.Add(ctx, 1, metric.WithAttributes(attribute.String("operation", operation)))
+	}
+
+	m.queryDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("status", status),
+	))
+}
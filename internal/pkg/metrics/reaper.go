@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	executionsReaped = "scheduler_reaper_executions_reaped"
+)
+
+type ReaperMetrics struct {
+	enabled bool
+
+	executionsReaped metric.Int64Counter
+}
+
+func NewReaperMetrics(config observability.MetricsConfig) *ReaperMetrics {
+	if !config.Enabled {
+		return &ReaperMetrics{enabled: false}
+	}
+
+	meter := otel.GetMeterProvider().Meter("reaper")
+
+	executionsReaped, err := meter.Int64Counter(executionsReaped)
+	must(err)
+
+	return &ReaperMetrics{
+		enabled:          true,
+		executionsReaped: executionsReaped,
+	}
+}
+
+// IncreaseReapedExecutionCount counts the executions abandoned by their runner and failed by the reaper.
+func (r *ReaperMetrics) IncreaseReapedExecutionCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.executionsReaped.Add(ctx, 1, attrs)
+	}
+}
@@ -10,12 +10,24 @@ import (
 )
 
 const (
-	jobsTotal       = "scheduler_runner_jobs_total"
-	jobsExecuted    = "scheduler_runner_jobs_executed"
-	jobsFailed      = "scheduler_runner_jobs_failed"
-	jobRetries      = "scheduler_runner_job_retries"
-	jobDuration     = "scheduler_runner_job_duration"
-	jobsInExecution = "scheduler_runner_jobs_in_execution"
+	jobsTotal        = "scheduler_runner_jobs_total"
+	jobsExecuted     = "scheduler_runner_jobs_executed"
+	jobsFailed       = "scheduler_runner_jobs_failed"
+	jobRetries       = "scheduler_runner_job_retries"
+	jobDuration      = "scheduler_runner_job_duration"
+	jobsInExecution  = "scheduler_runner_jobs_in_execution"
+	jobDrift         = "scheduler_runner_job_drift"
+	jobsStale        = "scheduler_runner_jobs_stale"
+	poolJobs         = "scheduler_runner_pool_jobs_in_execution"
+	poolRejected     = "scheduler_runner_pool_jobs_rejected"
+	saturation       = "scheduler_runner_saturation"
+	fetchesSkipped   = "scheduler_runner_fetches_skipped"
+	executorAttempts = "scheduler_runner_executor_attempts"
+	attemptDuration  = "scheduler_runner_executor_attempt_duration"
+	fetchDuration    = "scheduler_runner_fetch_duration"
+	jobsClaimed      = "scheduler_runner_jobs_claimed"
+	lockReclaims     = "scheduler_runner_lock_reclaims"
+	finishDuration   = "scheduler_runner_finish_duration"
 )
 
 // Add attributes: Job Type/Executor, Instance ID, status, numberOfTries
@@ -34,6 +46,30 @@ type RunnerMetrics struct {
 	jobDuration metric.Float64Histogram
 
 	jobsInExecution metric.Int64Gauge
+
+	jobDrift metric.Float64Histogram
+
+	jobsStale metric.Int64Counter
+
+	poolJobs metric.Int64UpDownCounter
+
+	poolRejected metric.Int64Counter
+
+	saturation metric.Float64Gauge
+
+	fetchesSkipped metric.Int64Counter
+
+	executorAttempts metric.Int64Counter
+
+	attemptDuration metric.Float64Histogram
+
+	fetchDuration metric.Float64Histogram
+
+	jobsClaimed metric.Int64Counter
+
+	lockReclaims metric.Int64Counter
+
+	finishDuration metric.Float64Histogram
 }
 
 func NewRunnerMetrics(config observability.MetricsConfig) *RunnerMetrics {
@@ -61,6 +97,42 @@ func NewRunnerMetrics(config observability.MetricsConfig) *RunnerMetrics {
 	jobsInExecution, err := meter.Int64Gauge(jobsInExecution)
 	must(err)
 
+	jobDrift, err := meter.Float64Histogram(jobDrift, metric.WithUnit("s"))
+	must(err)
+
+	jobsStale, err := meter.Int64Counter(jobsStale)
+	must(err)
+
+	poolJobs, err := meter.Int64UpDownCounter(poolJobs)
+	must(err)
+
+	poolRejected, err := meter.Int64Counter(poolRejected)
+	must(err)
+
+	saturation, err := meter.Float64Gauge(saturation)
+	must(err)
+
+	fetchesSkipped, err := meter.Int64Counter(fetchesSkipped)
+	must(err)
+
+	executorAttempts, err := meter.Int64Counter(executorAttempts)
+	must(err)
+
+	attemptDuration, err := meter.Float64Histogram(attemptDuration, metric.WithUnit("s"))
+	must(err)
+
+	fetchDuration, err := meter.Float64Histogram(fetchDuration, metric.WithUnit("s"))
+	must(err)
+
+	jobsClaimed, err := meter.Int64Counter(jobsClaimed)
+	must(err)
+
+	lockReclaims, err := meter.Int64Counter(lockReclaims)
+	must(err)
+
+	finishDuration, err := meter.Float64Histogram(finishDuration, metric.WithUnit("s"))
+	must(err)
+
 	return &RunnerMetrics{
 		enabled:         true,
 		jobsTotal:       jobsTotal,
@@ -69,6 +141,20 @@ func NewRunnerMetrics(config observability.MetricsConfig) *RunnerMetrics {
 		jobRetries:      jobRetries,
 		jobDuration:     jobDuration,
 		jobsInExecution: jobsInExecution,
+		jobDrift:        jobDrift,
+		jobsStale:       jobsStale,
+		poolJobs:        poolJobs,
+		poolRejected:    poolRejected,
+		saturation:      saturation,
+		fetchesSkipped:  fetchesSkipped,
+
+		executorAttempts: executorAttempts,
+		attemptDuration:  attemptDuration,
+
+		fetchDuration:  fetchDuration,
+		jobsClaimed:    jobsClaimed,
+		lockReclaims:   lockReclaims,
+		finishDuration: finishDuration,
 	}
 }
 
@@ -112,6 +198,90 @@ func (r *RunnerMetrics) IncreaseFailedJobCount(ctx context.Context, attributes .
 	}
 }
 
+// RecordJobDrift records the delay between the scheduled and actual start time of a job in seconds.
+func (r *RunnerMetrics) RecordJobDrift(ctx context.Context, drift float64, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.jobDrift.Record(ctx, drift, attrs)
+	}
+}
+
+func (r *RunnerMetrics) IncreaseStaleJobCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.jobsStale.Add(ctx, 1, attrs)
+	}
+}
+
+// AddPoolJobsInExecution adds delta to the number of jobs executed in a worker pool.
+func (r *RunnerMetrics) AddPoolJobsInExecution(ctx context.Context, delta int, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.poolJobs.Add(ctx, int64(delta), attrs)
+	}
+}
+
+// IncreasePoolRejectedJobCount counts the jobs handed over to other runners because their worker pool was full.
+func (r *RunnerMetrics) IncreasePoolRejectedJobCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.poolRejected.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordSaturation records the share of the slots of the worker pools in use or held for jobs fetched ahead.
+func (r *RunnerMetrics) RecordSaturation(ctx context.Context, saturation float64, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.saturation.Record(ctx, saturation, attrs)
+	}
+}
+
+// IncreaseSkippedFetchCount counts the polls that didn't fetch any job because the runner was saturated.
+func (r *RunnerMetrics) IncreaseSkippedFetchCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.fetchesSkipped.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordExecutorAttempt counts an attempt of an executor wrapped in the metrics middleware and records its duration
+// in seconds.
+func (r *RunnerMetrics) RecordExecutorAttempt(ctx context.Context, duration float64, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.executorAttempts.Add(ctx, 1, attrs)
+		r.attemptDuration.Record(ctx, duration, attrs)
+	}
+}
+
+// RecordFetch records the duration in seconds of a fetch of the due jobs, and counts the jobs it claimed. The
+// attributes carry the status of the fetch.
+func (r *RunnerMetrics) RecordFetch(ctx context.Context, duration float64, claimed int, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.fetchDuration.Record(ctx, duration, attrs)
+		r.jobsClaimed.Add(ctx, int64(claimed), attrs)
+	}
+}
+
+// IncreaseLockReclaimCount counts the jobs claimed while still locked by an instance whose lock expired.
+func (r *RunnerMetrics) IncreaseLockReclaimCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.lockReclaims.Add(ctx, 1, attrs)
+	}
+}
+
+// RecordFinish records the duration in seconds of the report of a finished execution. The attributes carry the
+// status of the report.
+func (r *RunnerMetrics) RecordFinish(ctx context.Context, duration float64, attributes ...attribute.KeyValue) {
+	if r.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		r.finishDuration.Record(ctx, duration, attrs)
+	}
+}
+
 func must(err error) {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	slaBreachedJobs = "scheduler_sla_breached_jobs"
+	slaBreaches     = "scheduler_sla_breaches"
+)
+
+type SLAMetrics struct {
+	enabled bool
+
+	breachedJobs metric.Int64Gauge
+	breaches     metric.Int64Counter
+}
+
+func NewSLAMetrics(config observability.MetricsConfig) *SLAMetrics {
+	if !config.Enabled {
+		return &SLAMetrics{enabled: false}
+	}
+
+	meter := otel.GetMeterProvider().Meter("sla")
+
+	breachedJobs, err := meter.Int64Gauge(slaBreachedJobs)
+	must(err)
+
+	breaches, err := meter.Int64Counter(slaBreaches)
+	must(err)
+
+	return &SLAMetrics{
+		enabled:      true,
+		breachedJobs: breachedJobs,
+		breaches:     breaches,
+	}
+}
+
+// RecordBreachedJobs records the number of jobs currently not meeting their SLA.
+func (s *SLAMetrics) RecordBreachedJobs(ctx context.Context, count int) {
+	if s.enabled {
+		s.breachedJobs.Record(ctx, int64(count))
+	}
+}
+
+// IncreaseBreachCount counts the breaches of the SLA of a job, once per breach.
+func (s *SLAMetrics) IncreaseBreachCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if s.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		s.breaches.Add(ctx, 1, attrs)
+	}
+}
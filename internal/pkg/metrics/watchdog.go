@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	watchdogMissedJobs = "scheduler_watchdog_missed_jobs"
+	watchdogMissedRuns = "scheduler_watchdog_missed_runs"
+)
+
+type WatchdogMetrics struct {
+	enabled bool
+
+	missedJobs metric.Int64Gauge
+	missedRuns metric.Int64Counter
+}
+
+func NewWatchdogMetrics(config observability.MetricsConfig) *WatchdogMetrics {
+	if !config.Enabled {
+		return &WatchdogMetrics{enabled: false}
+	}
+
+	meter := otel.GetMeterProvider().Meter("watchdog")
+
+	missedJobs, err := meter.Int64Gauge(watchdogMissedJobs)
+	must(err)
+
+	missedRuns, err := meter.Int64Counter(watchdogMissedRuns)
+	must(err)
+
+	return &WatchdogMetrics{
+		enabled:    true,
+		missedJobs: missedJobs,
+		missedRuns: missedRuns,
+	}
+}
+
+// RecordMissedJobs records the number of jobs currently overdue by more than the missed run threshold.
+func (w *WatchdogMetrics) RecordMissedJobs(ctx context.Context, count int) {
+	if w.enabled {
+		w.missedJobs.Record(ctx, int64(count))
+	}
+}
+
+// IncreaseMissedRunCount counts the runs missed by a job, once per run.
+func (w *WatchdogMetrics) IncreaseMissedRunCount(ctx context.Context, attributes ...attribute.KeyValue) {
+	if w.enabled {
+		attrs := metric.WithAttributes(attributes...)
+		w.missedRuns.Add(ctx, 1, attrs)
+	}
+}
@@ -0,0 +1,388 @@
+// Package mtls builds the TLS configurations of the management API and its clients, verifying the certificates of the
+// clients against a CA bundle and their revocation against CRLs and OCSP responders, for the deployments without a
+// service mesh terminating TLS.
+package mtls
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// ClientAuthMode is whether the clients must present a certificate.
+type ClientAuthMode string
+
+const (
+	// ClientAuthNone doesn't request the certificates of the clients.
+	ClientAuthNone ClientAuthMode = "none"
+	// ClientAuthOptional verifies the certificates of the clients presenting one.
+	ClientAuthOptional ClientAuthMode = "optional"
+	// ClientAuthRequired rejects the clients without a valid certificate.
+	ClientAuthRequired ClientAuthMode = "required"
+)
+
+const (
+	// defaultOCSPTimeout bounds the requests to the OCSP responders, if the timeout is unset.
+	defaultOCSPTimeout = 5 * time.Second
+	// maxOCSPCacheTTL bounds how long the OCSP responses are cached, whatever their next update.
+	maxOCSPCacheTTL = time.Hour
+	// maxOCSPResponseSize bounds the size of the OCSP responses read.
+	maxOCSPResponseSize = 1 << 20
+)
+
+// ErrRevoked is returned when the certificate of a client is revoked.
+var ErrRevoked = errors.New("client certificate is revoked")
+
+// Settings configure the verification of the client certificates.
+type Settings struct {
+	// Mode is one of none, optional or required.
+	Mode ClientAuthMode `mapstructure:"mode" yaml:"mode" json:"mode"`
+	// CAPath is the path of the PEM bundle of the CAs issuing the client certificates.
+	CAPath string `mapstructure:"caPath" yaml:"caPath" json:"caPath,omitempty"`
+	// CRLPath is the path of the PEM or DER revocation lists of the CAs, read again when the file changes.
+	CRLPath string `mapstructure:"crlPath" yaml:"crlPath" json:"crlPath,omitempty"`
+	// OCSP checks the revocation of the client certificates with the responders of their CA.
+	OCSP OCSPSettings `mapstructure:"ocsp" yaml:"ocsp" json:"ocsp"`
+}
+
+// OCSPSettings configure the OCSP checks of the client certificates.
+type OCSPSettings struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// FailClosed rejects the certificates whose status is unknown, e.g. when the responder is unreachable. Otherwise,
+	// only the certificates reported as revoked are rejected.
+	FailClosed bool `mapstructure:"failClosed" yaml:"failClosed" json:"failClosed"`
+	// Timeout bounds the requests to the responders.
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout" json:"timeout"`
+}
+
+// Validate validates the settings.
+func (s Settings) Validate() error {
+	switch s.Mode {
+	case "", ClientAuthNone:
+		return nil
+	case ClientAuthOptional, ClientAuthRequired:
+		if s.CAPath == "" {
+			return fmt.Errorf("a CA bundle is required to verify the client certificates")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid client auth mode %q, must be one of none, optional or required", s.Mode)
+	}
+}
+
+// ServerConfig returns the TLS configuration of a server with the certificate and key, verifying the certificates of
+// the clients according to the settings.
+func ServerConfig(certPath, keyPath string, settings Settings) (*tls.Config, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if settings.Mode == "" || settings.Mode == ClientAuthNone {
+		return config, nil
+	}
+
+	config.ClientCAs, err = loadCertPool(settings.CAPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	if settings.Mode == ClientAuthRequired {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	timeout := settings.OCSP.Timeout
+	if timeout <= 0 {
+		timeout = defaultOCSPTimeout
+	}
+
+	v := &verifier{
+		crlPath:   settings.CRLPath,
+		ocsp:      settings.OCSP,
+		client:    &http.Client{Timeout: timeout},
+		now:       time.Now,
+		responses: map[string]*ocsp.Response{},
+	}
+
+	// the revocation lists are loaded upfront, so a missing or invalid file is reported on startup
+	if _, err := v.revocationLists(); err != nil {
+		return nil, err
+	}
+
+	config.VerifyPeerCertificate = v.verify
+
+	return config, nil
+}
+
+// ClientConfig returns the TLS configuration of a client presenting the certificate and key if set, and verifying
+// the server against the CA bundle if set, or the system CAs otherwise.
+func ClientConfig(certPath, keyPath, caPath string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certPath != "" || keyPath != "" {
+		certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if caPath != "" {
+		pool, err := loadCertPool(caPath)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// verifier checks the revocation of the verified client certificates.
+type verifier struct {
+	crlPath string
+	ocsp    OCSPSettings
+	client  *http.Client
+	now     func() time.Time
+
+	mu         sync.Mutex
+	crls       []*x509.RevocationList
+	crlModTime time.Time
+	responses  map[string]*ocsp.Response
+}
+
+func (v *verifier) verify(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	// without a certificate, the optional mode accepts the client
+	if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+		return nil
+	}
+
+	chain := verifiedChains[0]
+	leaf, issuer := chain[0], chain[0]
+	if len(chain) > 1 {
+		issuer = chain[1]
+	}
+
+	if err := v.checkCRL(leaf, issuer); err != nil {
+		return err
+	}
+
+	if v.ocsp.Enabled {
+		return v.checkOCSP(leaf, issuer)
+	}
+
+	return nil
+}
+
+func (v *verifier) checkCRL(leaf, issuer *x509.Certificate) error {
+	crls, err := v.revocationLists()
+	if err != nil {
+		return err
+	}
+
+	for _, crl := range crls {
+		// only the lists signed by the issuer of the certificate apply to it
+		if !bytes.Equal(crl.RawIssuer, leaf.RawIssuer) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+
+		for _, revoked := range crl.RevokedCertificateEntries {
+			if revoked.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+				return fmt.Errorf("%w: serial %s listed in the CRL", ErrRevoked, leaf.SerialNumber)
+			}
+		}
+	}
+
+	return nil
+}
+
+// revocationLists returns the revocation lists, reading the file again if it changed since it was last read.
+func (v *verifier) revocationLists() ([]*x509.RevocationList, error) {
+	if v.crlPath == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(v.crlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CRL: %w", err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.crls != nil && info.ModTime().Equal(v.crlModTime) {
+		return v.crls, nil
+	}
+
+	data, err := os.ReadFile(v.crlPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CRL: %w", err)
+	}
+
+	crls, err := parseRevocationLists(data)
+	if err != nil {
+		return nil, err
+	}
+
+	v.crls, v.crlModTime = crls, info.ModTime()
+	return crls, nil
+}
+
+func (v *verifier) checkOCSP(leaf, issuer *x509.Certificate) error {
+	if len(leaf.OCSPServer) == 0 {
+		return v.unknown(fmt.Errorf("certificate %s has no OCSP responder", leaf.SerialNumber))
+	}
+
+	response, err := v.ocspResponse(leaf, issuer)
+	if err != nil {
+		return v.unknown(err)
+	}
+
+	switch response.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("%w: serial %s revoked according to the OCSP responder", ErrRevoked, leaf.SerialNumber)
+	default:
+		return v.unknown(fmt.Errorf("status of certificate %s is unknown to the OCSP responder", leaf.SerialNumber))
+	}
+}
+
+// unknown rejects the certificate whose status couldn't be checked if the OCSP checks fail closed.
+func (v *verifier) unknown(err error) error {
+	if v.ocsp.FailClosed {
+		return err
+	}
+
+	return nil
+}
+
+// ocspResponse returns the response of the responder for the certificate, cached until its next update.
+func (v *verifier) ocspResponse(leaf, issuer *x509.Certificate) (*ocsp.Response, error) {
+	key := string(leaf.RawIssuer) + leaf.SerialNumber.String()
+	now := v.now()
+
+	v.mu.Lock()
+	cached, ok := v.responses[key]
+	v.mu.Unlock()
+
+	if ok && now.Before(cacheExpiry(cached)) {
+		return cached, nil
+	}
+
+	request, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OCSP request: %w", err)
+	}
+
+	httpResponse, err := v.client.Post(leaf.OCSPServer[0], "application/ocsp-request", bytes.NewReader(request))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the OCSP responder: %w", err)
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder returned status %d", httpResponse.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxOCSPResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OCSP response: %w", err)
+	}
+
+	response, err := ocsp.ParseResponseForCert(body, leaf, issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OCSP response: %w", err)
+	}
+
+	v.mu.Lock()
+	for cachedKey, cachedResponse := range v.responses {
+		if !now.Before(cacheExpiry(cachedResponse)) {
+			delete(v.responses, cachedKey)
+		}
+	}
+	v.responses[key] = response
+	v.mu.Unlock()
+
+	return response, nil
+}
+
+// cacheExpiry returns when the response must be fetched again: at its next update, or after the maximum TTL.
+func cacheExpiry(response *ocsp.Response) time.Time {
+	expiry := response.ThisUpdate.Add(maxOCSPCacheTTL)
+	if !response.NextUpdate.IsZero() && response.NextUpdate.Before(expiry) {
+		return response.NextUpdate
+	}
+
+	return expiry
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in the CA bundle %s", path)
+	}
+
+	return pool, nil
+}
+
+// parseRevocationLists parses the PEM encoded revocation lists of the data, or a single DER encoded one.
+func parseRevocationLists(data []byte) ([]*x509.RevocationList, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		crl, err := x509.ParseRevocationList(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRL: %w", err)
+		}
+		return []*x509.RevocationList{crl}, nil
+	}
+
+	var crls []*x509.RevocationList
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "X509 CRL" {
+			continue
+		}
+
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRL: %w", err)
+		}
+		crls = append(crls, crl)
+	}
+
+	if len(crls) == 0 {
+		return nil, fmt.Errorf("no revocation lists found in the CRL file")
+	}
+
+	return crls, nil
+}
@@ -0,0 +1,260 @@
+package mtls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+// issue writes a certificate signed by the CA and its key to the directory, returning their paths.
+func (ca *testCA) issue(t *testing.T, dir string, serial int64, usage x509.ExtKeyUsage, ocspServer string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if ocspServer != "" {
+		template.OCSPServer = []string{ocspServer}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, big.NewInt(serial).String()+".crt")
+	keyPath := filepath.Join(dir, big.NewInt(serial).String()+".key")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+
+	return certPath, keyPath
+}
+
+func (ca *testCA) revocationList(t *testing.T, path string, serials ...int64) {
+	var entries []x509.RevocationListEntry
+	for _, serial := range serials {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(time.Now().UnixNano()),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}, ca.cert, ca.key)
+	require.NoError(t, err)
+
+	writePEM(t, path, "X509 CRL", der)
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// serve starts a TLS server with the settings, returning its URL.
+func serve(t *testing.T, certPath, keyPath string, settings Settings) string {
+	config, err := ServerConfig(certPath, keyPath, settings)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = config
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func get(t *testing.T, url, certPath, keyPath, caPath string) error {
+	config, err := ClientConfig(certPath, keyPath, caPath)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}, Timeout: 5 * time.Second}
+	response, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+
+	return nil
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caPath := filepath.Join(dir, "ca.crt")
+	writePEM(t, caPath, "CERTIFICATE", ca.cert.Raw)
+
+	serverCert, serverKey := ca.issue(t, dir, 10, x509.ExtKeyUsageServerAuth, "")
+	clientCert, clientKey := ca.issue(t, dir, 20, x509.ExtKeyUsageClientAuth, "")
+	revokedCert, revokedKey := ca.issue(t, dir, 21, x509.ExtKeyUsageClientAuth, "")
+
+	t.Run("Requires a client certificate", func(t *testing.T) {
+		url := serve(t, serverCert, serverKey, Settings{Mode: ClientAuthRequired, CAPath: caPath})
+
+		assert.NoError(t, get(t, url, clientCert, clientKey, caPath))
+		assert.Error(t, get(t, url, "", "", caPath))
+	})
+
+	t.Run("Accepts clients without a certificate if optional", func(t *testing.T) {
+		url := serve(t, serverCert, serverKey, Settings{Mode: ClientAuthOptional, CAPath: caPath})
+
+		assert.NoError(t, get(t, url, "", "", caPath))
+		assert.NoError(t, get(t, url, clientCert, clientKey, caPath))
+	})
+
+	t.Run("Rejects the certificates of another CA", func(t *testing.T) {
+		otherDir := t.TempDir()
+		otherCert, otherKey := newTestCA(t).issue(t, otherDir, 30, x509.ExtKeyUsageClientAuth, "")
+		url := serve(t, serverCert, serverKey, Settings{Mode: ClientAuthOptional, CAPath: caPath})
+
+		assert.Error(t, get(t, url, otherCert, otherKey, caPath))
+	})
+
+	t.Run("Rejects the certificates listed in the CRL", func(t *testing.T) {
+		crlPath := filepath.Join(dir, "ca.crl")
+		ca.revocationList(t, crlPath, 21)
+		url := serve(t, serverCert, serverKey, Settings{Mode: ClientAuthRequired, CAPath: caPath, CRLPath: crlPath})
+
+		assert.NoError(t, get(t, url, clientCert, clientKey, caPath))
+		assert.Error(t, get(t, url, revokedCert, revokedKey, caPath))
+
+		// the CRL is read again once updated
+		ca.revocationList(t, crlPath, 20, 21)
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(crlPath, future, future))
+
+		assert.Error(t, get(t, url, clientCert, clientKey, caPath))
+	})
+
+	t.Run("Rejects an invalid configuration", func(t *testing.T) {
+		_, err := ServerConfig(serverCert, serverKey, Settings{Mode: ClientAuthRequired})
+		assert.Error(t, err)
+
+		_, err = ServerConfig(serverCert, serverKey, Settings{Mode: "always", CAPath: caPath})
+		assert.Error(t, err)
+
+		_, err = ServerConfig(serverCert, serverKey, Settings{Mode: ClientAuthRequired, CAPath: caPath, CRLPath: filepath.Join(dir, "missing.crl")})
+		assert.Error(t, err)
+	})
+}
+
+func TestServerConfigOCSP(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caPath := filepath.Join(dir, "ca.crt")
+	writePEM(t, caPath, "CERTIFICATE", ca.cert.Raw)
+
+	var requests atomic.Int32
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		request, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		status := ocsp.Good
+		if request.SerialNumber.Int64() == 21 {
+			status = ocsp.Revoked
+		}
+
+		response, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       status,
+			SerialNumber: request.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, ca.key)
+		require.NoError(t, err)
+
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(responder.Close)
+
+	serverCert, serverKey := ca.issue(t, dir, 10, x509.ExtKeyUsageServerAuth, "")
+	clientCert, clientKey := ca.issue(t, dir, 20, x509.ExtKeyUsageClientAuth, responder.URL)
+	revokedCert, revokedKey := ca.issue(t, dir, 21, x509.ExtKeyUsageClientAuth, responder.URL)
+	unreachableCert, unreachableKey := ca.issue(t, dir, 22, x509.ExtKeyUsageClientAuth, "http://127.0.0.1:1")
+
+	t.Run("Rejects the certificates revoked according to the responder", func(t *testing.T) {
+		url := serve(t, serverCert, serverKey, Settings{Mode: ClientAuthRequired, CAPath: caPath, OCSP: OCSPSettings{Enabled: true}})
+
+		assert.NoError(t, get(t, url, clientCert, clientKey, caPath))
+		assert.Error(t, get(t, url, revokedCert, revokedKey, caPath))
+
+		// the responses are cached until their next update
+		before := requests.Load()
+		assert.NoError(t, get(t, url, clientCert, clientKey, caPath))
+		assert.Equal(t, before, requests.Load())
+	})
+
+	t.Run("Accepts the certificates of unreachable responders unless failing closed", func(t *testing.T) {
+		url := serve(t, serverCert, serverKey, Settings{Mode: ClientAuthRequired, CAPath: caPath, OCSP: OCSPSettings{Enabled: true}})
+		assert.NoError(t, get(t, url, unreachableCert, unreachableKey, caPath))
+
+		url = serve(t, serverCert, serverKey, Settings{Mode: ClientAuthRequired, CAPath: caPath, OCSP: OCSPSettings{Enabled: true, FailClosed: true}})
+		assert.Error(t, get(t, url, unreachableCert, unreachableKey, caPath))
+	})
+}
+
+func TestParseRevocationLists(t *testing.T) {
+	_, err := parseRevocationLists([]byte("-----BEGIN CERTIFICATE-----\n-----END CERTIFICATE-----\n"))
+	assert.Error(t, err)
+
+	_, err = parseRevocationLists([]byte{0x01, 0x02})
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+// Package objectstore writes objects to S3, or to S3-compatible object storage such as GCS and MinIO.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/sigv4"
+)
+
+// S3Settings configure a bucket on S3, or on S3-compatible object storage such as GCS (with HMAC keys) or MinIO.
+type S3Settings struct {
+	// Endpoint of the object storage, e.g. https://storage.googleapis.com for GCS or http://minio:9000, the AWS
+	// endpoint of the region if empty.
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint" json:"endpoint,omitempty"`
+	// Region of the bucket, us-east-1 if empty. GCS accepts any region, e.g. auto.
+	Region string `mapstructure:"region" yaml:"region" json:"region,omitempty"`
+	Bucket string `mapstructure:"bucket" yaml:"bucket" json:"bucket,omitempty"`
+	// PathStyle addresses the bucket in the path of the URLs instead of in the host name, e.g. for MinIO.
+	PathStyle bool `mapstructure:"pathStyle" yaml:"pathStyle" json:"pathStyle,omitempty"`
+	// The credentials default to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment
+	// variables.
+	AccessKeyID     string `mapstructure:"accessKeyId" yaml:"accessKeyId" json:"-" redact:"true"`
+	SecretAccessKey string `mapstructure:"secretAccessKey" yaml:"secretAccessKey" json:"-" redact:"true"`
+	SessionToken    string `mapstructure:"sessionToken" yaml:"sessionToken" json:"-" redact:"true"`
+}
+
+// S3Bucket uploads objects to a bucket with PutObject requests signed with AWS Signature Version 4.
+type S3Bucket struct {
+	client      *http.Client
+	endpoint    *url.URL
+	region      string
+	bucket      string
+	pathStyle   bool
+	credentials sigv4.Credentials
+	now         func() time.Time
+}
+
+// NewS3Bucket creates the bucket configured by the settings, sending the requests with the client, or with the
+// default client if nil.
+func NewS3Bucket(settings S3Settings, client *http.Client) (*S3Bucket, error) {
+	if settings.Bucket == "" {
+		return nil, fmt.Errorf("s3 requires a bucket")
+	}
+
+	region := settings.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := settings.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", endpoint)
+	}
+
+	credentials := sigv4.CredentialsFromEnv(sigv4.Credentials{
+		AccessKeyID:     settings.AccessKeyID,
+		SecretAccessKey: settings.SecretAccessKey,
+		SessionToken:    settings.SessionToken,
+	})
+	if !credentials.Valid() {
+		return nil, fmt.Errorf("s3 requires an access key")
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &S3Bucket{
+		client:      client,
+		endpoint:    parsed,
+		region:      region,
+		bucket:      settings.Bucket,
+		pathStyle:   settings.PathStyle,
+		credentials: credentials,
+		now:         time.Now,
+	}, nil
+}
+
+// Put uploads the data as the object of the key, replacing it if it exists.
+func (b *S3Bucket) Put(ctx context.Context, key, contentType string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, b.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create s3 request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	sigv4.Sign(req, sigv4.SHA256Hex(data), b.credentials, b.region, "s3", b.now())
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload object to s3: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("upload object to s3: %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func (b *S3Bucket) objectURL(key string) string {
+	u := *b.endpoint
+	path := "/" + sigv4.URIEncode(key, false)
+
+	if b.pathStyle {
+		path = "/" + sigv4.URIEncode(b.bucket, true) + path
+	} else {
+		u.Host = b.bucket + "." + u.Host
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.Scheme + "://" + u.Host + u.Path + path
+}
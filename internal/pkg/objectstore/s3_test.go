@@ -0,0 +1,81 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/sigv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3BucketPut(t *testing.T) {
+	var (
+		path          string
+		body          string
+		contentType   string
+		authorization string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		authorization = r.Header.Get("Authorization")
+
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		assert.Equal(t, sigv4.SHA256Hex(data), r.Header.Get("X-Amz-Content-Sha256"))
+	}))
+	defer server.Close()
+
+	bucket, err := NewS3Bucket(S3Settings{
+		Endpoint:        server.URL,
+		Bucket:          "archive",
+		PathStyle:       true,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	}, server.Client())
+	require.NoError(t, err)
+
+	require.NoError(t, bucket.Put(context.Background(), "logs/1.ndjson", "application/x-ndjson", []byte("data")))
+	assert.Equal(t, "/archive/logs/1.ndjson", path)
+	assert.Equal(t, "data", body)
+	assert.Equal(t, "application/x-ndjson", contentType)
+	assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=key/"))
+}
+
+func TestS3BucketPutError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	bucket, err := NewS3Bucket(S3Settings{Endpoint: server.URL, Bucket: "archive", PathStyle: true, AccessKeyID: "key", SecretAccessKey: "secret"}, server.Client())
+	require.NoError(t, err)
+
+	err = bucket.Put(context.Background(), "executions.ndjson.gz", "application/gzip", []byte("data"))
+	assert.ErrorContains(t, err, "AccessDenied")
+}
+
+func TestNewS3Bucket(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	_, err := NewS3Bucket(S3Settings{AccessKeyID: "key", SecretAccessKey: "secret"}, nil)
+	assert.ErrorContains(t, err, "bucket")
+
+	_, err = NewS3Bucket(S3Settings{Bucket: "archive"}, nil)
+	assert.ErrorContains(t, err, "access key")
+}
+
+func TestS3ObjectURL(t *testing.T) {
+	bucket, err := NewS3Bucket(S3Settings{Bucket: "archive", Region: "eu-west-1", AccessKeyID: "key", SecretAccessKey: "secret"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://archive.s3.eu-west-1.amazonaws.com/2026/10/15/executions%20a.ndjson.gz",
+		bucket.objectURL("2026/10/15/executions a.ndjson.gz"))
+}
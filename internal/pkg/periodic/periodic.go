@@ -0,0 +1,123 @@
+// Package periodic runs the tasks performed at a regular interval, e.g. the maintenance tasks of the leader.
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Task runs a function every interval in a goroutine of its own, until it is stopped.
+type Task struct {
+	name     string
+	interval time.Duration
+	leader   leader.Leader
+	log      *otelzap.Logger
+	run      func(ctx context.Context)
+
+	// Add a context and cancel function to stop the task
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Add a wait group to wait for the task to stop
+	stopWg sync.WaitGroup
+
+	// Add a sync.Once to ensure the task only starts once
+	startOnce sync.Once
+}
+
+type Config struct {
+	// Name of the task in the logs, e.g. "zombie reaper"
+	Name     string
+	Interval time.Duration
+	// Leader restricts the task to the leader instance, every instance runs it if nil
+	Leader leader.Leader
+	Log    *otelzap.Logger
+	// Run performs the task, its context is cancelled when the task stops
+	Run func(ctx context.Context)
+}
+
+// ValidateInterval validates the interval of a task, which must be positive.
+func ValidateInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", interval)
+	}
+
+	return nil
+}
+
+// New creates a task, or returns an error if its interval isn't positive.
+func New(cfg Config) (*Task, error) {
+	if err := ValidateInterval(cfg.Interval); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Task{
+		name:     cfg.Name,
+		interval: cfg.Interval,
+		leader:   cfg.Leader,
+		log:      cfg.Log,
+		run:      cfg.Run,
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+// Start starts the task in a separate goroutine, which runs it on every tick of the interval.
+// Only the first call will start the task, subsequent calls are ignored.
+func (t *Task) Start() {
+	t.startOnce.Do(func() {
+		t.stopWg.Add(1)
+
+		go func() {
+			defer t.stopWg.Done()
+
+			ticker := time.NewTicker(t.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					t.tick()
+				case <-t.ctx.Done():
+					return
+				}
+			}
+		}()
+	})
+}
+
+// Stop stops the task and waits for the current run to finish or the context to expire.
+func (t *Task) Stop(ctx context.Context) {
+	t.cancel()
+
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		t.stopWg.Wait()
+	}()
+
+	select {
+	case <-c:
+		t.log.Info("Periodic task stopped", zap.String("task", t.name))
+	case <-ctx.Done():
+		t.log.Warn("Timeout while stopping the periodic task", zap.String("task", t.name))
+	}
+}
+
+// tick runs the task, unless the instance is not the leader.
+func (t *Task) tick() {
+	if t.leader != nil && !t.leader.IsLeader() {
+		t.log.Debug("Skipping the periodic task, the instance is not the leader", zap.String("task", t.name))
+		return
+	}
+
+	t.run(t.ctx)
+}
@@ -0,0 +1,67 @@
+package periodic
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+func TestTask(t *testing.T) {
+	log := otelzap.New(zap.NewNop())
+
+	newTask := func(l leader.Leader, runs *atomic.Int32) *Task {
+		task, err := New(Config{
+			Name:     "test",
+			Interval: time.Millisecond * 10,
+			Leader:   l,
+			Log:      log,
+			Run:      func(ctx context.Context) { runs.Add(1) },
+		})
+		require.NoError(t, err)
+
+		return task
+	}
+
+	t.Run("Runs on every tick until stopped", func(t *testing.T) {
+		var runs atomic.Int32
+		task := newTask(nil, &runs)
+		task.Start()
+		task.Start()
+
+		assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond*5)
+		task.Stop(context.Background())
+
+		stopped := runs.Load()
+		time.Sleep(time.Millisecond * 50)
+		assert.Equal(t, stopped, runs.Load())
+	})
+
+	t.Run("Only the leader runs the task", func(t *testing.T) {
+		var runs atomic.Int32
+		task := newTask(leader.Static(false), &runs)
+		task.Start()
+
+		time.Sleep(time.Millisecond * 50)
+		task.Stop(context.Background())
+		assert.Zero(t, runs.Load())
+	})
+
+	t.Run("Stopping a task which didn't start", func(t *testing.T) {
+		var runs atomic.Int32
+		newTask(nil, &runs).Stop(context.Background())
+	})
+
+	t.Run("Non-positive intervals are rejected", func(t *testing.T) {
+		for _, interval := range []time.Duration{0, -time.Second} {
+			_, err := New(Config{Name: "test", Interval: interval, Log: log, Run: func(ctx context.Context) {}})
+			assert.Error(t, err)
+		}
+	})
+}
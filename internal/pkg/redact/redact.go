@@ -0,0 +1,334 @@
+// Package redact masks the credentials of the job payloads in the API responses, the logs and the error messages.
+//
+// The credentials are marked with the redact struct tag, so new fields are masked everywhere by tagging them:
+//
+//	Password null.String `json:"password" redact:"true"` // removed
+//	Connection string    `json:"connection" redact:"url"` // password of the URL masked
+//
+// Types with credentials that can't be tagged, e.g. in a JSON document, implement Redactor.
+package redact
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Tag is the struct tag marking the credentials.
+const Tag = "redact"
+
+// Modes of the tag.
+const (
+	// ModeRemove removes the value of the field, resetting it to its zero value.
+	ModeRemove = "true"
+	// ModeURL masks the password of the URL in the field, keeping the rest of the URL.
+	ModeURL = "url"
+)
+
+// Mask replaces the credentials found in the messages.
+const Mask = "[REDACTED]"
+
+// minSecretLength is the length under which the credentials aren't masked in the messages, as short values would
+// mask unrelated parts of the messages.
+const minSecretLength = 6
+
+// Redactor is implemented by the types redacting credentials the tags can't mark. Redact is called after the tagged
+// fields of the value were redacted.
+type Redactor interface {
+	Redact()
+}
+
+// Apply redacts the tagged fields of the value in place, following the pointers, structs and slices. The value must
+// be a pointer. Maps and interfaces are not followed.
+func Apply(v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return
+	}
+
+	apply(rv.Elem(), false)
+}
+
+// Copy returns a redacted deep copy of the value, leaving the value untouched.
+func Copy[T any](v T) T {
+	c := deepCopy(reflect.ValueOf(&v).Elem()).Interface().(T)
+	Apply(&c)
+	return c
+}
+
+// Masked returns a deep copy of the value with the credentials which are set masked rather than removed, e.g. to show
+// which credentials a configuration sets.
+func Masked[T any](v T) T {
+	c := deepCopy(reflect.ValueOf(&v).Elem()).Interface().(T)
+	apply(reflect.ValueOf(&c).Elem(), true)
+	return c
+}
+
+// Field returns a log field with a redacted copy of the value, e.g. a job.
+func Field(key string, v any) zap.Field {
+	return zap.Any(key, Copy(v))
+}
+
+// Secrets returns the values of the tagged fields of the value, e.g. to mask them in the error messages.
+func Secrets(v any) []string {
+	var secrets []string
+	collect(reflect.ValueOf(v), &secrets)
+	return secrets
+}
+
+// Message masks the secrets found in the message, e.g. credentials echoed in the error of an execution.
+func Message(message string, secrets []string) string {
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			message = strings.ReplaceAll(message, secret, Mask)
+		}
+	}
+
+	return message
+}
+
+// Document redacts the fields of the JSON document tagged in the type the document encodes, e.g. a job definition,
+// matching the fields by their JSON names. The values for which keep returns true are left untouched.
+func Document(document map[string]any, t reflect.Type, keep func(value any) bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		value, ok := document[name]
+		if !ok || keep(value) {
+			continue
+		}
+
+		switch field.Tag.Get(Tag) {
+		case ModeRemove:
+			delete(document, name)
+		case ModeURL:
+			if s, ok := value.(string); ok {
+				document[name] = redactURL(s)
+			}
+		default:
+			if nested, ok := value.(map[string]any); ok {
+				Document(nested, field.Type, keep)
+			}
+		}
+	}
+}
+
+// Restore copies back into dst the credentials of src which were redacted in dst, e.g. to update a job with a
+// definition exported without its credentials. Credentials set in dst are kept. Slices are matched by index, and the
+// credentials redacted by a Redactor are not restored.
+func Restore[T any](dst, src *T) {
+	if dst == nil || src == nil {
+		return
+	}
+
+	restore(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem())
+}
+
+func apply(v reflect.Value, mask bool) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			apply(v.Elem(), mask)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			apply(v.Index(i), mask)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			value := v.Field(i)
+			switch field.Tag.Get(Tag) {
+			case ModeRemove:
+				if mask && value.Kind() == reflect.String && value.String() != "" {
+					value.SetString(Mask)
+				} else {
+					value.Set(reflect.Zero(value.Type()))
+				}
+			case ModeURL:
+				if value.Kind() == reflect.String {
+					value.SetString(redactURL(value.String()))
+				}
+			default:
+				apply(value, mask)
+			}
+		}
+
+		if v.CanAddr() {
+			if redactor, ok := v.Addr().Interface().(Redactor); ok {
+				redactor.Redact()
+			}
+		}
+	}
+}
+
+func restore(dst, src reflect.Value) {
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if !dst.IsNil() && !src.IsNil() {
+			restore(dst.Elem(), src.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range min(dst.Len(), src.Len()) {
+			restore(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		t := dst.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			d, s := dst.Field(i), src.Field(i)
+			switch field.Tag.Get(Tag) {
+			case ModeRemove:
+				if d.IsZero() {
+					d.Set(deepCopy(s))
+				}
+			case ModeURL:
+				// only the masked URLs are restored, a URL pointing elsewhere replaces the credentials
+				if d.Kind() == reflect.String && d.String() != s.String() && d.String() == redactURL(s.String()) {
+					d.SetString(s.String())
+				}
+			default:
+				restore(d, s)
+			}
+		}
+	}
+}
+
+// stringer matches the nullable strings, e.g. null.String.
+type stringer interface {
+	ValueOrZero() string
+}
+
+func collect(v reflect.Value, secrets *[]string) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			collect(v.Elem(), secrets)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			collect(v.Index(i), secrets)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			value := v.Field(i)
+			switch field.Tag.Get(Tag) {
+			case ModeRemove:
+				if secret := stringValue(value); secret != "" {
+					*secrets = append(*secrets, secret)
+				}
+			case ModeURL:
+				if u, err := url.Parse(stringValue(value)); err == nil && u.User != nil {
+					if password, ok := u.User.Password(); ok && password != "" {
+						*secrets = append(*secrets, password)
+					}
+				}
+			default:
+				collect(value, secrets)
+			}
+		}
+	}
+}
+
+func stringValue(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+
+	if s, ok := v.Interface().(stringer); ok {
+		return s.ValueOrZero()
+	}
+
+	return ""
+}
+
+// redactURL masks the password of the URL, leaving the values which aren't URLs untouched.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	return u.Redacted()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		// the unexported fields are copied as they are
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
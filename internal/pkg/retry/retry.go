@@ -0,0 +1,91 @@
+// Package retry attempts the queued deliveries of the events, e.g. the webhook deliveries and the notifications,
+// retrying the failed ones with an exponential backoff.
+package retry
+
+import (
+	"context"
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	// BatchSize is the maximum number of deliveries attempted per tick.
+	BatchSize = 50
+
+	MinDelay = time.Second * 10
+	MaxDelay = time.Hour
+)
+
+// ClaimFunc claims up to limit deliveries due at the given time, leasing them until leaseUntil so other instances
+// skip them.
+type ClaimFunc[T any] func(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]T, error)
+
+// AttemptDue claims a batch of due deliveries and attempts them one by one, until the context is done. The deliveries
+// are leased for longer than it takes to attempt all of them, each attempt taking up to timeout, and the interval
+// between ticks.
+func AttemptDue[T any](ctx context.Context, timeout, interval time.Duration, claim ClaimFunc[T], attempt func(T)) error {
+	now := time.Now()
+	leaseUntil := now.Add(timeout*BatchSize + interval)
+
+	deliveries, err := claim(ctx, now, leaseUntil, BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		attempt(delivery)
+	}
+
+	return nil
+}
+
+// Outcome is the outcome of an attempt of a delivery.
+type Outcome int
+
+const (
+	// Succeeded deliveries are done.
+	Succeeded Outcome = iota
+	// Retried deliveries are attempted again after a delay.
+	Retried
+	// Failed deliveries failed for good, after the maximum number of attempts.
+	Failed
+)
+
+// Result is the outcome of an attempt of a delivery, along with the error and next attempt to record.
+type Result struct {
+	Outcome       Outcome
+	Error         null.String
+	NextAttemptAt null.Time
+}
+
+// NewResult returns the result of the given attempt of a delivery, which failed if err isn't nil. Failed deliveries
+// are retried until maxAttempts attempts were made.
+func NewResult(attempt, maxAttempts int, err error) Result {
+	switch {
+	case err == nil:
+		return Result{Outcome: Succeeded}
+	case attempt >= maxAttempts:
+		return Result{Outcome: Failed, Error: null.StringFrom(err.Error())}
+	default:
+		return Result{
+			Outcome:       Retried,
+			Error:         null.StringFrom(err.Error()),
+			NextAttemptAt: null.TimeFrom(time.Now().Add(Delay(attempt))),
+		}
+	}
+}
+
+// Delay returns the delay before the next attempt, doubling with every failed attempt.
+func Delay(attempts int) time.Duration {
+	delay := MinDelay
+	for i := 1; i < attempts && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, MaxDelay)
+}
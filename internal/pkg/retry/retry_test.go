@@ -0,0 +1,58 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelay(t *testing.T) {
+	assert.Equal(t, MinDelay, Delay(1))
+	assert.Equal(t, MinDelay*4, Delay(3))
+	assert.Equal(t, MaxDelay, Delay(20))
+}
+
+func TestNewResult(t *testing.T) {
+	assert.Equal(t, Result{Outcome: Succeeded}, NewResult(1, 3, nil))
+
+	result := NewResult(2, 3, errors.New("unexpected status code 503"))
+	assert.Equal(t, Retried, result.Outcome)
+	assert.Equal(t, "unexpected status code 503", result.Error.String)
+	assert.WithinDuration(t, time.Now().Add(MinDelay*2), result.NextAttemptAt.Time, time.Second)
+
+	result = NewResult(3, 3, errors.New("unexpected status code 503"))
+	assert.Equal(t, Failed, result.Outcome)
+	assert.True(t, result.Error.Valid)
+	assert.False(t, result.NextAttemptAt.Valid)
+}
+
+func TestAttemptDue(t *testing.T) {
+	var leaseUntil time.Time
+	claim := func(_ context.Context, at time.Time, until time.Time, limit uint) ([]int, error) {
+		leaseUntil = until
+		assert.Equal(t, uint(BatchSize), limit)
+		return []int{1, 2, 3}, nil
+	}
+
+	var attempted []int
+	require.NoError(t, AttemptDue(context.Background(), time.Second, time.Minute, claim, func(i int) { attempted = append(attempted, i) }))
+	assert.Equal(t, []int{1, 2, 3}, attempted)
+	assert.WithinDuration(t, time.Now().Add(BatchSize*time.Second+time.Minute), leaseUntil, time.Second)
+
+	// the deliveries aren't attempted once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempted = nil
+	require.NoError(t, AttemptDue(ctx, time.Second, time.Minute, claim, func(i int) { attempted = append(attempted, i) }))
+	assert.Empty(t, attempted)
+
+	claimErr := errors.New("database is down")
+	err := AttemptDue(context.Background(), time.Second, time.Minute, func(context.Context, time.Time, time.Time, uint) ([]int, error) {
+		return nil, claimErr
+	}, func(int) {})
+	assert.ErrorIs(t, err, claimErr)
+}
@@ -0,0 +1,109 @@
+// Package secrets resolves the references to secrets held by a secret manager, e.g. HashiCorp Vault, which the job
+// credentials can be set to instead of the secrets themselves.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// VaultScheme prefixes the references to Vault secrets, e.g. vault:secret/data/payments#token for the token field of
+// the secret at secret/data/payments.
+const VaultScheme = "vault"
+
+// Reference identifies a field of a secret of a secret manager.
+type Reference struct {
+	// Scheme identifies the secret manager, e.g. vault
+	Scheme string
+	// Path of the secret, e.g. secret/data/payments
+	Path string
+	// Field of the secret, e.g. token
+	Field string
+}
+
+func (r Reference) String() string {
+	return r.Scheme + ":" + r.Path + "#" + r.Field
+}
+
+// IsReference tells whether the value is a reference to a secret, rather than a secret itself.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, VaultScheme+":")
+}
+
+// ParseReference parses a reference to a secret, formatted as <scheme>:<path>#<field>.
+func ParseReference(value string) (Reference, error) {
+	scheme, rest, found := strings.Cut(value, ":")
+	if !found || scheme != VaultScheme {
+		return Reference{}, fmt.Errorf("not a secret reference: unknown scheme %q", scheme)
+	}
+
+	path, field, found := strings.Cut(rest, "#")
+	path = strings.Trim(path, "/")
+	if !found || path == "" || field == "" {
+		return Reference{}, fmt.Errorf("invalid secret reference %q: expected %s:<path>#<field>", value, scheme)
+	}
+
+	return Reference{Scheme: scheme, Path: path, Field: field}, nil
+}
+
+// Provider reads the secrets of a secret manager.
+type Provider interface {
+	// Secret returns the value of the referenced field of the secret.
+	Secret(ctx context.Context, ref Reference) (string, error)
+}
+
+// Settings configure the secret managers the references are resolved with.
+type Settings struct {
+	Vault VaultSettings `mapstructure:"vault" yaml:"vault" json:"vault"`
+}
+
+// Resolver resolves the references to secrets with the provider of their scheme.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver creates a resolver with the providers of the schemes, e.g. VaultScheme.
+func NewResolver(providers map[string]Provider) *Resolver {
+	return &Resolver{providers: providers}
+}
+
+// NewResolverFromSettings creates a resolver with the secret managers configured by the settings.
+func NewResolverFromSettings(settings Settings, client *http.Client) (*Resolver, error) {
+	providers := map[string]Provider{}
+
+	vault, err := NewVaultProvider(settings.Vault, client)
+	if err != nil {
+		return nil, err
+	}
+	if vault != nil {
+		providers[VaultScheme] = vault
+	}
+
+	return NewResolver(providers), nil
+}
+
+// Resolve returns the secret the value refers to, or the value itself if it isn't a reference.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	provider, ok := r.providers[ref.Scheme]
+	if !ok {
+		return "", fmt.Errorf("no %s secret provider is configured to resolve %s", ref.Scheme, ref)
+	}
+
+	secret, err := provider.Secret(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,321 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authentication methods of Vault.
+const (
+	VaultAuthToken      = "token"
+	VaultAuthKubernetes = "kubernetes"
+	VaultAuthAppRole    = "approle"
+)
+
+const (
+	// defaultVaultCacheTTL is how long the secrets are cached, if the cache TTL is unset.
+	defaultVaultCacheTTL = 5 * time.Minute
+	// defaultKubernetesTokenPath is the service account token mounted in the pods.
+	defaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// errVaultForbidden is returned when Vault rejects the token, e.g. because it expired.
+var errVaultForbidden = errors.New("permission denied")
+
+// VaultSettings configure the access to HashiCorp Vault.
+type VaultSettings struct {
+	// Address of Vault, e.g. https://vault:8200, defaults to the VAULT_ADDR environment variable. The references to
+	// Vault secrets can't be resolved if both are empty.
+	Address string `mapstructure:"address" yaml:"address" json:"address,omitempty"`
+	// Namespace of Vault Enterprise, defaults to the VAULT_NAMESPACE environment variable.
+	Namespace string `mapstructure:"namespace" yaml:"namespace" json:"namespace,omitempty"`
+	// CacheTTL is how long the secrets are cached, at most their lease duration.
+	CacheTTL time.Duration     `mapstructure:"cacheTtl" yaml:"cacheTtl" json:"cacheTtl,omitempty"`
+	Auth     VaultAuthSettings `mapstructure:"auth" yaml:"auth" json:"auth"`
+}
+
+// VaultAuthSettings configure the authentication to Vault.
+type VaultAuthSettings struct {
+	// Method of authentication: token, kubernetes or approle, token if empty.
+	Method string `mapstructure:"method" yaml:"method" json:"method,omitempty"`
+	// Mount path of the auth method, the name of the method if empty.
+	Mount string `mapstructure:"mount" yaml:"mount" json:"mount,omitempty"`
+	// Token of the token method, defaults to the VAULT_TOKEN environment variable.
+	Token string `mapstructure:"token" yaml:"token" json:"-" redact:"true"`
+	// Role of the kubernetes method.
+	Role string `mapstructure:"role" yaml:"role" json:"role,omitempty"`
+	// JWTPath is the service account token of the kubernetes method, the token mounted in the pod if empty.
+	JWTPath string `mapstructure:"jwtPath" yaml:"jwtPath" json:"jwtPath,omitempty"`
+	// RoleID and SecretID of the approle method.
+	RoleID   string `mapstructure:"roleId" yaml:"roleId" json:"roleId,omitempty"`
+	SecretID string `mapstructure:"secretId" yaml:"secretId" json:"-" redact:"true"`
+}
+
+// vaultProvider reads the secrets of the KV secrets engine, version 1 or 2, and caches them.
+type vaultProvider struct {
+	client    *http.Client
+	address   string
+	namespace string
+	cacheTTL  time.Duration
+	auth      VaultAuthSettings
+	now       func() time.Time
+
+	tokenMu        sync.Mutex
+	token          string
+	tokenExpiresAt time.Time
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	fields    map[string]any
+	expiresAt time.Time
+}
+
+type vaultResponse struct {
+	Data          map[string]any `json:"data"`
+	LeaseDuration int64          `json:"lease_duration"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// NewVaultProvider creates a provider of the Vault secrets, nil if Vault isn't configured.
+func NewVaultProvider(settings VaultSettings, client *http.Client) (Provider, error) {
+	address := settings.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return nil, nil
+	}
+
+	namespace := settings.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+
+	cacheTTL := settings.CacheTTL
+	if cacheTTL <= 0 {
+		cacheTTL = defaultVaultCacheTTL
+	}
+
+	auth := settings.Auth
+	if auth.Method == "" {
+		auth.Method = VaultAuthToken
+	}
+	if auth.Mount == "" {
+		auth.Mount = auth.Method
+	}
+
+	switch auth.Method {
+	case VaultAuthToken:
+		if auth.Token == "" {
+			auth.Token = os.Getenv("VAULT_TOKEN")
+		}
+		if auth.Token == "" {
+			return nil, fmt.Errorf("the vault token auth method requires a token")
+		}
+	case VaultAuthKubernetes:
+		if auth.Role == "" {
+			return nil, fmt.Errorf("the vault kubernetes auth method requires a role")
+		}
+		if auth.JWTPath == "" {
+			auth.JWTPath = defaultKubernetesTokenPath
+		}
+	case VaultAuthAppRole:
+		if auth.RoleID == "" {
+			return nil, fmt.Errorf("the vault approle auth method requires a role ID")
+		}
+	default:
+		return nil, fmt.Errorf("unknown vault auth method: %q", auth.Method)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &vaultProvider{
+		client:    client,
+		address:   strings.TrimSuffix(address, "/"),
+		namespace: namespace,
+		cacheTTL:  cacheTTL,
+		auth:      auth,
+		now:       time.Now,
+		cache:     map[string]cachedSecret{},
+	}, nil
+}
+
+func (p *vaultProvider) Secret(ctx context.Context, ref Reference) (string, error) {
+	fields, err := p.read(ctx, ref.Path)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := fields[ref.Field]
+	if !ok {
+		return "", fmt.Errorf("the secret has no %q field", ref.Field)
+	}
+
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("the %q field of the secret is not a string", ref.Field)
+	}
+
+	return secret, nil
+}
+
+// read returns the fields of the secret at the path, from the cache if they haven't expired.
+func (p *vaultProvider) read(ctx context.Context, path string) (map[string]any, error) {
+	p.cacheMu.Lock()
+	cached, ok := p.cache[path]
+	p.cacheMu.Unlock()
+	if ok && p.now().Before(cached.expiresAt) {
+		return cached.fields, nil
+	}
+
+	res, err := p.get(ctx, path)
+	if errors.Is(err, errVaultForbidden) && p.auth.Method != VaultAuthToken {
+		// the token obtained by logging in may have been revoked, log in again once
+		p.resetToken()
+		res, err = p.get(ctx, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := res.Data
+	// the secrets of the KV version 2 engine are nested, along with their metadata
+	if data, ok := fields["data"].(map[string]any); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = data
+		}
+	}
+
+	ttl := p.cacheTTL
+	if lease := time.Duration(res.LeaseDuration) * time.Second; lease > 0 && lease < ttl {
+		ttl = lease
+	}
+
+	p.cacheMu.Lock()
+	p.cache[path] = cachedSecret{fields: fields, expiresAt: p.now().Add(ttl)}
+	p.cacheMu.Unlock()
+
+	return fields, nil
+}
+
+func (p *vaultProvider) get(ctx context.Context, path string) (*vaultResponse, error) {
+	token, err := p.clientToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.do(ctx, http.MethodGet, path, token, nil)
+}
+
+// clientToken returns the token authenticating the requests, logging in if it expired.
+func (p *vaultProvider) clientToken(ctx context.Context) (string, error) {
+	if p.auth.Method == VaultAuthToken {
+		return p.auth.Token, nil
+	}
+
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+
+	if p.token != "" && p.now().Before(p.tokenExpiresAt) {
+		return p.token, nil
+	}
+
+	body := map[string]string{}
+	switch p.auth.Method {
+	case VaultAuthKubernetes:
+		jwt, err := os.ReadFile(p.auth.JWTPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the service account token: %w", err)
+		}
+		body["role"] = p.auth.Role
+		body["jwt"] = strings.TrimSpace(string(jwt))
+	case VaultAuthAppRole:
+		body["role_id"] = p.auth.RoleID
+		body["secret_id"] = p.auth.SecretID
+	}
+
+	res, err := p.do(ctx, http.MethodPost, "auth/"+strings.Trim(p.auth.Mount, "/")+"/login", "", body)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to vault: %w", err)
+	}
+	if res.Auth == nil || res.Auth.ClientToken == "" {
+		return "", fmt.Errorf("failed to log in to vault: no client token")
+	}
+
+	// the token is renewed by logging in again shortly before it expires
+	p.token = res.Auth.ClientToken
+	p.tokenExpiresAt = p.now().Add(time.Duration(res.Auth.LeaseDuration) * time.Second * 9 / 10)
+	return p.token, nil
+}
+
+func (p *vaultProvider) resetToken() {
+	p.tokenMu.Lock()
+	defer p.tokenMu.Unlock()
+
+	p.token = ""
+}
+
+func (p *vaultProvider) do(ctx context.Context, method, path, token string, body any) (*vaultResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.address+"/v1/"+strings.TrimPrefix(path, "/"), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res vaultResponse
+	// the errors are reported in the body of the responses too
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&res)
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, errVaultForbidden
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("secret not found: %s", path)
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("vault: %s: %s", resp.Status, strings.Join(res.Errors, ", "))
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,137 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVaultProvider(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+
+	provider, err := NewVaultProvider(VaultSettings{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+
+	_, err = NewVaultProvider(VaultSettings{Address: "http://vault:8200"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewVaultProvider(VaultSettings{Address: "http://vault:8200", Auth: VaultAuthSettings{Method: VaultAuthKubernetes}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewVaultProvider(VaultSettings{Address: "http://vault:8200", Auth: VaultAuthSettings{Method: "ldap"}}, nil)
+	assert.Error(t, err)
+
+	t.Setenv("VAULT_TOKEN", "token")
+	provider, err = NewVaultProvider(VaultSettings{Address: "http://vault:8200"}, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, provider)
+}
+
+func TestVaultProvider(t *testing.T) {
+	var reads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "team", r.Header.Get("X-Vault-Namespace"))
+		reads.Add(1)
+
+		switch r.URL.Path {
+		case "/v1/secret/data/payments":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"data":     map[string]any{"token": "s3cr3t", "port": 5672},
+				"metadata": map[string]any{"version": 3},
+			}})
+		case "/v1/kv/payments":
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"token": "v1"}, "lease_duration": 60})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewVaultProvider(VaultSettings{
+		Address:   server.URL,
+		Namespace: "team",
+		Auth:      VaultAuthSettings{Token: "token"},
+	}, server.Client())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	t.Run("Reads the secrets of the KV version 2 engine", func(t *testing.T) {
+		secret, err := provider.Secret(ctx, Reference{Scheme: VaultScheme, Path: "secret/data/payments", Field: "token"})
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", secret)
+
+		// the secret is cached
+		_, err = provider.Secret(ctx, Reference{Scheme: VaultScheme, Path: "secret/data/payments", Field: "token"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), reads.Load())
+
+		_, err = provider.Secret(ctx, Reference{Scheme: VaultScheme, Path: "secret/data/payments", Field: "port"})
+		assert.ErrorContains(t, err, "not a string")
+
+		_, err = provider.Secret(ctx, Reference{Scheme: VaultScheme, Path: "secret/data/payments", Field: "password"})
+		assert.ErrorContains(t, err, "no \"password\" field")
+	})
+
+	t.Run("Reads the secrets of the KV version 1 engine", func(t *testing.T) {
+		secret, err := provider.Secret(ctx, Reference{Scheme: VaultScheme, Path: "kv/payments", Field: "token"})
+		require.NoError(t, err)
+		assert.Equal(t, "v1", secret)
+	})
+
+	t.Run("Reports missing secrets", func(t *testing.T) {
+		_, err := provider.Secret(ctx, Reference{Scheme: VaultScheme, Path: "secret/data/missing", Field: "token"})
+		assert.ErrorContains(t, err, "secret not found")
+	})
+}
+
+func TestVaultProviderAppRole(t *testing.T) {
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "role", body["role_id"])
+			assert.Equal(t, "secret", body["secret_id"])
+
+			// the first token is revoked right away
+			token := "revoked"
+			if logins.Add(1) > 1 {
+				token = "token"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{"client_token": token, "lease_duration": 3600}})
+		case "/v1/secret/data/payments":
+			if r.Header.Get("X-Vault-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors": ["permission denied"]}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"data":     map[string]any{"token": "s3cr3t"},
+				"metadata": map[string]any{},
+			}})
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewVaultProvider(VaultSettings{
+		Address: server.URL,
+		Auth:    VaultAuthSettings{Method: VaultAuthAppRole, RoleID: "role", SecretID: "secret"},
+	}, server.Client())
+	require.NoError(t, err)
+
+	secret, err := provider.Secret(context.Background(), Reference{Scheme: VaultScheme, Path: "secret/data/payments", Field: "token"})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", secret)
+	assert.Equal(t, int32(2), logins.Load())
+}
@@ -0,0 +1,34 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const (
+	// apiKeyPrefix makes API keys recognizable, e.g. by secret scanners.
+	apiKeyPrefix = "dsk_"
+	apiKeyBytes  = 32
+	// displayedPrefixLength is the number of characters of the key that are stored in plain text.
+	displayedPrefixLength = len(apiKeyPrefix) + 8
+)
+
+// GenerateAPIKey generates a new random API key and returns it along with its displayable prefix.
+func GenerateAPIKey() (string, string, error) {
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:displayedPrefixLength], nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of the API key. As the keys are random,
+// a fast hash is sufficient and allows looking the keys up by their hash.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,23 @@
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	key, prefix, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+
+	other, _, err := GenerateAPIKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+
+	assert.Equal(t, HashAPIKey(key), HashAPIKey(key))
+	assert.NotEqual(t, HashAPIKey(key), HashAPIKey(other))
+	assert.Len(t, HashAPIKey(key), 64)
+}
@@ -39,6 +39,8 @@ func NewEncryptor(secretKey string) Encryptor {
 	}
 }
 
+// NewEncryptorFromEnv creates the encryptor configured under storage.encryption. With a key provider, the values are
+// encrypted with data keys of the key management service, and the raw key only decrypts the values encrypted before.
 func NewEncryptorFromEnv() Encryptor {
 	// Load the secret key from a secure location.
 	secretKey := viper.GetString("storage.encryption.key")
@@ -53,10 +55,26 @@ func NewEncryptorFromEnv() Encryptor {
 		panic(err)
 	}
 
-	return &encryptor{
+	rawKey := &encryptor{
 		cipherBlock: aes,
 		aead:        gcm,
 	}
+
+	var kms KMSSettings
+	if err := viper.UnmarshalKey("storage.encryption.kms", &kms); err != nil {
+		panic(err)
+	}
+
+	provider, err := NewKeyProvider(kms, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if provider == nil {
+		return rawKey
+	}
+
+	return NewEnvelopeEncryptor(provider, kms.DataKeyTTL, rawKey)
 }
 
 func (e *encryptor) Encrypt(plaintext string) (*string, error) {
@@ -0,0 +1,204 @@
+package security
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// envelopePrefix marks the values encrypted with a data key, and the version of their format:
+// envelopePrefix + base64(encrypted data key) + ":" + base64(nonce + ciphertext)
+const envelopePrefix = "kms:v1:"
+
+const (
+	// defaultDataKeyTTL is how long a data key encrypts new values, if the TTL is unset.
+	defaultDataKeyTTL = time.Hour
+	// maxCachedDataKeys bounds the number of decrypted data keys kept in memory.
+	maxCachedDataKeys = 1024
+	// keyProviderTimeout bounds the requests to the key provider.
+	keyProviderTimeout = 10 * time.Second
+)
+
+// KeyProvider generates and decrypts the data keys of the envelope encryption, with a key that never leaves the
+// key management service.
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit data key, in plain text and encrypted with the key of the provider.
+	GenerateDataKey(ctx context.Context) (plaintext []byte, ciphertext []byte, err error)
+	// DecryptDataKey decrypts a data key encrypted by GenerateDataKey.
+	DecryptDataKey(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// envelopeEncryptor encrypts the values with data keys generated by a key provider, and stores each value along with
+// its encrypted data key. A data key encrypts new values for its TTL, and the decrypted data keys are cached, so the
+// key provider is only called when the data key is rotated or a value of an unknown data key is decrypted.
+type envelopeEncryptor struct {
+	provider KeyProvider
+	ttl      time.Duration
+	// legacy decrypts the values encrypted before the key provider was configured, none if nil
+	legacy Encryptor
+	now    func() time.Time
+
+	mu        sync.Mutex
+	current   *dataKey
+	decrypted map[string]cipher.AEAD
+}
+
+type dataKey struct {
+	aead       cipher.AEAD
+	ciphertext string
+	expiresAt  time.Time
+}
+
+// NewEnvelopeEncryptor creates an encryptor using the data keys of the key provider, rotated after the TTL. The
+// legacy encryptor decrypts the values that were encrypted with a raw key, if not nil.
+func NewEnvelopeEncryptor(provider KeyProvider, ttl time.Duration, legacy Encryptor) Encryptor {
+	if ttl <= 0 {
+		ttl = defaultDataKeyTTL
+	}
+
+	return &envelopeEncryptor{
+		provider:  provider,
+		ttl:       ttl,
+		legacy:    legacy,
+		now:       time.Now,
+		decrypted: map[string]cipher.AEAD{},
+	}
+}
+
+func (e *envelopeEncryptor) Encrypt(plaintext string) (*string, error) {
+	key, err := e.dataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, key.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	ciphertext := key.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return lo.ToPtr(envelopePrefix + key.ciphertext + ":" + base64.StdEncoding.EncodeToString(ciphertext)), nil
+}
+
+func (e *envelopeEncryptor) Decrypt(ciphertext string) (*string, error) {
+	if !strings.HasPrefix(ciphertext, envelopePrefix) {
+		if e.legacy == nil {
+			return nil, errors.New("value is not encrypted with a data key")
+		}
+
+		return e.legacy.Decrypt(ciphertext)
+	}
+
+	encryptedKey, encoded, found := strings.Cut(strings.TrimPrefix(ciphertext, envelopePrefix), ":")
+	if !found {
+		return nil, errors.New("malformed envelope ciphertext")
+	}
+
+	aead, err := e.decryptDataKey(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode ciphertext")
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(decoded) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	plaintext, err := aead.Open(nil, decoded[:nonceSize], decoded[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt ciphertext")
+	}
+
+	return lo.ToPtr(string(plaintext)), nil
+}
+
+// dataKey returns the data key encrypting new values, generating a new one if it expired.
+func (e *envelopeEncryptor) dataKey() (*dataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && e.now().Before(e.current.expiresAt) {
+		return e.current, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyProviderTimeout)
+	defer cancel()
+
+	plaintext, ciphertext, err := e.provider.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate data key")
+	}
+
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(ciphertext)
+	e.current = &dataKey{aead: aead, ciphertext: encoded, expiresAt: e.now().Add(e.ttl)}
+	e.cacheDataKey(encoded, aead)
+
+	return e.current, nil
+}
+
+// decryptDataKey returns the cipher of the encrypted data key, decrypting it with the key provider if it isn't cached.
+func (e *envelopeEncryptor) decryptDataKey(encoded string) (cipher.AEAD, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if aead, ok := e.decrypted[encoded]; ok {
+		return aead, nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode data key")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyProviderTimeout)
+	defer cancel()
+
+	plaintext, err := e.provider.DecryptDataKey(ctx, ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data key")
+	}
+
+	aead, err := newAEAD(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	e.cacheDataKey(encoded, aead)
+	return aead, nil
+}
+
+func (e *envelopeEncryptor) cacheDataKey(encoded string, aead cipher.AEAD) {
+	// the cache is only reset once full, data keys are rotated rarely enough for it to hold all the recent ones
+	if len(e.decrypted) >= maxCachedDataKeys {
+		e.decrypted = map[string]cipher.AEAD{}
+	}
+
+	e.decrypted[encoded] = aead
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid data key")
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,106 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKeyProvider "encrypts" the data keys by keeping them in memory.
+type fakeKeyProvider struct {
+	keys      map[string][]byte
+	generated int
+	decrypted int
+}
+
+func (p *fakeKeyProvider) GenerateDataKey(context.Context) ([]byte, []byte, error) {
+	key := make([]byte, dataKeySize)
+	_, _ = rand.Read(key)
+
+	p.generated++
+	id := []byte{byte(p.generated)}
+	p.keys[string(id)] = key
+	return key, id, nil
+}
+
+func (p *fakeKeyProvider) DecryptDataKey(_ context.Context, ciphertext []byte) ([]byte, error) {
+	p.decrypted++
+	return p.keys[string(ciphertext)], nil
+}
+
+func TestEnvelopeEncryptor(t *testing.T) {
+	legacy := NewEncryptor("N1PCdw3M2B1TfJhoaY2mL736p2vCUc47")
+
+	t.Run("Encrypts with a cached data key", func(t *testing.T) {
+		provider := &fakeKeyProvider{keys: map[string][]byte{}}
+		enc := NewEnvelopeEncryptor(provider, time.Hour, legacy)
+
+		first, err := enc.Encrypt("secret")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(*first, envelopePrefix))
+
+		second, err := enc.Encrypt("other")
+		require.NoError(t, err)
+		assert.Equal(t, 1, provider.generated)
+
+		decrypted, err := enc.Decrypt(*first)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+
+		decrypted, err = enc.Decrypt(*second)
+		require.NoError(t, err)
+		assert.Equal(t, "other", *decrypted)
+		assert.Equal(t, 0, provider.decrypted)
+	})
+
+	t.Run("Rotates the data key after its TTL", func(t *testing.T) {
+		provider := &fakeKeyProvider{keys: map[string][]byte{}}
+		enc := NewEnvelopeEncryptor(provider, time.Minute, nil).(*envelopeEncryptor)
+
+		now := time.Now()
+		enc.now = func() time.Time { return now }
+
+		first, err := enc.Encrypt("secret")
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute * 2)
+		_, err = enc.Encrypt("secret")
+		require.NoError(t, err)
+		assert.Equal(t, 2, provider.generated)
+
+		// a new instance decrypts the values of both data keys through the provider once
+		restarted := NewEnvelopeEncryptor(provider, time.Minute, nil)
+		for range 2 {
+			decrypted, err := restarted.Decrypt(*first)
+			require.NoError(t, err)
+			assert.Equal(t, "secret", *decrypted)
+		}
+		assert.Equal(t, 1, provider.decrypted)
+	})
+
+	t.Run("Decrypts the values of the raw key", func(t *testing.T) {
+		enc := NewEnvelopeEncryptor(&fakeKeyProvider{keys: map[string][]byte{}}, 0, legacy)
+
+		encrypted, err := legacy.Encrypt("secret")
+		require.NoError(t, err)
+
+		decrypted, err := enc.Decrypt(*encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+
+		_, err = NewEnvelopeEncryptor(&fakeKeyProvider{}, 0, nil).Decrypt(*encrypted)
+		assert.Error(t, err)
+	})
+
+	t.Run("Rejects malformed values", func(t *testing.T) {
+		enc := NewEnvelopeEncryptor(&fakeKeyProvider{keys: map[string][]byte{}}, 0, nil)
+
+		_, err := enc.Decrypt(envelopePrefix + "AQ==")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,305 @@
+package security
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// namespaceKeyPrefix marks the values encrypted with the data key of their namespace, and the version of their format:
+// namespaceKeyPrefix + key version + ":" + base64(nonce + ciphertext)
+const namespaceKeyPrefix = "ns:v1:"
+
+const (
+	// namespaceKeysTTL is how long the keys of a namespace are cached, bounding the time the other instances keep
+	// encrypting new values with the previous key after a rotation.
+	namespaceKeysTTL = time.Minute
+	// namespaceKeyStoreTimeout bounds the requests to the key store.
+	namespaceKeyStoreTimeout = 10 * time.Second
+)
+
+// ErrNamespaceKeyConflict is returned when a key of the same version was created concurrently for the namespace.
+var ErrNamespaceKeyConflict = errors.New("namespace key created concurrently")
+
+// NamespaceKey is a data key of a namespace, encrypted with the master encryptor.
+type NamespaceKey struct {
+	Namespace    string
+	Version      int
+	EncryptedKey string
+	CreatedAt    time.Time
+}
+
+// NamespaceKeyStore stores the data keys of the namespaces. A key is only deleted once no value is encrypted with it,
+// so the values encrypted with a previous key stay readable.
+type NamespaceKeyStore interface {
+	// GetNamespaceKeys returns the keys of the namespace, in any order.
+	GetNamespaceKeys(ctx context.Context, namespace string) ([]NamespaceKey, error)
+	// CreateNamespaceKey stores a new key, or returns ErrNamespaceKeyConflict if the namespace has a key of the same
+	// version.
+	CreateNamespaceKey(ctx context.Context, key NamespaceKey) error
+}
+
+// Keyring encrypts the values of each namespace with a data key of its own, so the key of a namespace can't decrypt
+// the values of another one. The data keys are generated on the first value encrypted in a namespace, and stored
+// encrypted with the master encryptor. The latest version of the keys encrypts the new values, the previous versions
+// only decrypt the values encrypted before a rotation.
+type Keyring struct {
+	master Encryptor
+	store  NamespaceKeyStore
+	// enabled encrypts the new values with the namespace keys, otherwise they are encrypted with the master encryptor
+	// and the namespace keys only decrypt the values encrypted while the keyring was enabled
+	enabled bool
+	now     func() time.Time
+
+	mu         sync.Mutex
+	namespaces map[string]*namespaceKeys
+}
+
+type namespaceKeys struct {
+	active   int
+	aeads    map[int]cipher.AEAD
+	loadedAt time.Time
+}
+
+// NewKeyring creates a keyring storing its keys in the store, encrypted with the master encryptor. Values without the
+// prefix of the namespace keys are decrypted with the master encryptor, e.g. the ones encrypted before the keyring was
+// enabled.
+func NewKeyring(master Encryptor, store NamespaceKeyStore, enabled bool) *Keyring {
+	return &Keyring{
+		master:     master,
+		store:      store,
+		enabled:    enabled,
+		now:        time.Now,
+		namespaces: map[string]*namespaceKeys{},
+	}
+}
+
+// Enabled reports whether the new values are encrypted with the namespace keys.
+func (k *Keyring) Enabled() bool {
+	return k.enabled
+}
+
+// Encrypt encrypts the value with the active key of the namespace, generating the first key of the namespace if
+// needed. The namespace is authenticated along with the value, so it can't be decrypted in another namespace.
+func (k *Keyring) Encrypt(namespace, plaintext string) (*string, error) {
+	if !k.enabled {
+		return k.master.Encrypt(plaintext)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), namespaceKeyStoreTimeout)
+	defer cancel()
+
+	keys, err := k.keys(ctx, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if keys.active == 0 {
+		if err := k.createKey(ctx, namespace, 1); err != nil && !errors.Is(err, ErrNamespaceKeyConflict) {
+			return nil, err
+		}
+
+		// another instance may have created the key concurrently, so the stored keys are used
+		if keys, err = k.keys(ctx, namespace, true); err != nil {
+			return nil, err
+		}
+
+		if keys.active == 0 {
+			return nil, errors.Errorf("no key found for namespace %s", namespace)
+		}
+	}
+
+	aead := keys.aeads[keys.active]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	ciphertext := aead.Seal(nonce, nonce, []byte(plaintext), []byte(namespace))
+
+	return lo.ToPtr(NamespaceKeyReference(keys.active) + base64.StdEncoding.EncodeToString(ciphertext)), nil
+}
+
+// Decrypt decrypts a value of the namespace, encrypted with any version of its keys or with the master encryptor.
+func (k *Keyring) Decrypt(namespace, ciphertext string) (*string, error) {
+	rest, ok := strings.CutPrefix(ciphertext, namespaceKeyPrefix)
+	if !ok {
+		return k.master.Decrypt(ciphertext)
+	}
+
+	encodedVersion, encodedValue, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, errors.New("malformed namespace encrypted value")
+	}
+
+	version, err := strconv.Atoi(encodedVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed namespace key version")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encodedValue)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode ciphertext")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), namespaceKeyStoreTimeout)
+	defer cancel()
+
+	keys, err := k.keys(ctx, namespace, false)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := keys.aeads[version]
+	if !ok {
+		// the key may have been created by another instance since the keys were loaded
+		if keys, err = k.keys(ctx, namespace, true); err != nil {
+			return nil, err
+		}
+
+		if aead, ok = keys.aeads[version]; !ok {
+			return nil, errors.Errorf("key version %d not found for namespace %s", version, namespace)
+		}
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(decoded) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	plaintext, err := aead.Open(nil, decoded[:nonceSize], decoded[nonceSize:], []byte(namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt ciphertext")
+	}
+
+	return lo.ToPtr(string(plaintext)), nil
+}
+
+// Rotate creates a new version of the key of the namespace, which encrypts the new values from now on. The values
+// encrypted with the previous versions stay readable until they are encrypted again.
+func (k *Keyring) Rotate(ctx context.Context, namespace string) (*NamespaceKey, error) {
+	keys, err := k.keys(ctx, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+
+	version := keys.active + 1
+	if err := k.createKey(ctx, namespace, version); err != nil {
+		return nil, err
+	}
+
+	if _, err := k.keys(ctx, namespace, true); err != nil {
+		return nil, err
+	}
+
+	return &NamespaceKey{Namespace: namespace, Version: version, CreatedAt: k.now()}, nil
+}
+
+// Reload loads the keys of the namespace from the store again, so the values are encrypted with the latest version
+// even if it was created by another instance since the keys were loaded.
+func (k *Keyring) Reload(ctx context.Context, namespace string) error {
+	_, err := k.keys(ctx, namespace, true)
+	return err
+}
+
+// RetiredNamespaceKeys returns the versions of the keys which no instance encrypts new values with anymore, as a later
+// version was created before the instances reloaded the keys of the namespace. They can be deleted once no value is
+// encrypted with them.
+func RetiredNamespaceKeys(keys []NamespaceKey, now time.Time) []int {
+	created := make(map[int]time.Time, len(keys))
+	for _, key := range keys {
+		created[key.Version] = key.CreatedAt
+	}
+
+	var retired []int
+	for _, key := range keys {
+		next, ok := created[key.Version+1]
+		if ok && now.Sub(next) >= namespaceKeysTTL {
+			retired = append(retired, key.Version)
+		}
+	}
+
+	sort.Ints(retired)
+	return retired
+}
+
+// NamespaceKeyReference returns the prefix of the values encrypted with the version of the key of their namespace.
+func NamespaceKeyReference(version int) string {
+	return namespaceKeyPrefix + strconv.Itoa(version) + ":"
+}
+
+// keys returns the keys of the namespace, from the cache unless they expired or reload is set.
+func (k *Keyring) keys(ctx context.Context, namespace string, reload bool) (*namespaceKeys, error) {
+	k.mu.Lock()
+	cached, ok := k.namespaces[namespace]
+	k.mu.Unlock()
+
+	if ok && !reload && k.now().Sub(cached.loadedAt) < namespaceKeysTTL {
+		return cached, nil
+	}
+
+	stored, err := k.store.GetNamespaceKeys(ctx, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get namespace keys")
+	}
+
+	keys := &namespaceKeys{aeads: map[int]cipher.AEAD{}, loadedAt: k.now()}
+	for _, key := range stored {
+		aead, err := k.decryptKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		keys.aeads[key.Version] = aead
+		keys.active = max(keys.active, key.Version)
+	}
+
+	k.mu.Lock()
+	k.namespaces[namespace] = keys
+	k.mu.Unlock()
+
+	return keys, nil
+}
+
+func (k *Keyring) createKey(ctx context.Context, namespace string, version int) error {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return errors.Wrap(err, "failed to generate namespace key")
+	}
+
+	encryptedKey, err := k.master.Encrypt(base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt namespace key")
+	}
+
+	return k.store.CreateNamespaceKey(ctx, NamespaceKey{
+		Namespace:    namespace,
+		Version:      version,
+		EncryptedKey: *encryptedKey,
+		CreatedAt:    k.now(),
+	})
+}
+
+func (k *Keyring) decryptKey(key NamespaceKey) (cipher.AEAD, error) {
+	encoded, err := k.master.Decrypt(key.EncryptedKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt key version %d of namespace %s", key.Version, key.Namespace)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode namespace key")
+	}
+
+	return newAEAD(decoded)
+}
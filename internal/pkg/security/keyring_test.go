@@ -0,0 +1,174 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNamespaceKeyStore keeps the namespace keys in memory.
+type fakeNamespaceKeyStore struct {
+	mu   sync.Mutex
+	keys []NamespaceKey
+}
+
+func (s *fakeNamespaceKeyStore) GetNamespaceKeys(_ context.Context, namespace string) ([]NamespaceKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var keys []NamespaceKey
+	for _, key := range s.keys {
+		if key.Namespace == namespace {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s *fakeNamespaceKeyStore) CreateNamespaceKey(_ context.Context, key NamespaceKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.keys {
+		if existing.Namespace == key.Namespace && existing.Version == key.Version {
+			return ErrNamespaceKeyConflict
+		}
+	}
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func TestKeyring(t *testing.T) {
+	master := NewEncryptor("N1PCdw3M2B1TfJhoaY2mL736p2vCUc47")
+
+	t.Run("Encrypts with a key per namespace", func(t *testing.T) {
+		store := &fakeNamespaceKeyStore{}
+		keyring := NewKeyring(master, store, true)
+
+		encrypted, err := keyring.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(*encrypted, namespaceKeyPrefix+"1:"))
+
+		_, err = keyring.Encrypt("team-b", "secret")
+		require.NoError(t, err)
+		assert.Len(t, store.keys, 2)
+
+		decrypted, err := keyring.Decrypt("team-a", *encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+
+		// the key of the namespace is not readable without the master key
+		assert.NotContains(t, store.keys[0].EncryptedKey, "secret")
+	})
+
+	t.Run("Can't decrypt the values of another namespace", func(t *testing.T) {
+		keyring := NewKeyring(master, &fakeNamespaceKeyStore{}, true)
+
+		encrypted, err := keyring.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+		_, err = keyring.Encrypt("team-b", "other")
+		require.NoError(t, err)
+
+		_, err = keyring.Decrypt("team-b", *encrypted)
+		assert.Error(t, err)
+	})
+
+	t.Run("Rotates the key of a namespace", func(t *testing.T) {
+		store := &fakeNamespaceKeyStore{}
+		keyring := NewKeyring(master, store, true)
+
+		before, err := keyring.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+
+		key, err := keyring.Rotate(context.Background(), "team-a")
+		require.NoError(t, err)
+		assert.Equal(t, 2, key.Version)
+
+		after, err := keyring.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(*after, namespaceKeyPrefix+"2:"))
+
+		decrypted, err := keyring.Decrypt("team-a", *before)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+	})
+
+	t.Run("Loads the keys created by another instance", func(t *testing.T) {
+		store := &fakeNamespaceKeyStore{}
+		first := NewKeyring(master, store, true)
+		second := NewKeyring(master, store, true)
+
+		_, err := second.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+
+		_, err = first.Rotate(context.Background(), "team-a")
+		require.NoError(t, err)
+		encrypted, err := first.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+
+		decrypted, err := second.Decrypt("team-a", *encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+	})
+
+	t.Run("Encrypts with the key rotated by another instance once reloaded", func(t *testing.T) {
+		store := &fakeNamespaceKeyStore{}
+		first := NewKeyring(master, store, true)
+		second := NewKeyring(master, store, true)
+
+		_, err := second.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+
+		_, err = first.Rotate(context.Background(), "team-a")
+		require.NoError(t, err)
+		require.NoError(t, second.Reload(context.Background(), "team-a"))
+
+		encrypted, err := second.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(*encrypted, NamespaceKeyReference(2)))
+	})
+
+	t.Run("Decrypts the values of the master encryptor", func(t *testing.T) {
+		keyring := NewKeyring(master, &fakeNamespaceKeyStore{}, true)
+
+		encrypted, err := master.Encrypt("secret")
+		require.NoError(t, err)
+
+		decrypted, err := keyring.Decrypt("team-a", *encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+	})
+
+	t.Run("Encrypts with the master encryptor if disabled", func(t *testing.T) {
+		store := &fakeNamespaceKeyStore{}
+		keyring := NewKeyring(master, store, false)
+
+		encrypted, err := keyring.Encrypt("team-a", "secret")
+		require.NoError(t, err)
+		assert.False(t, strings.HasPrefix(*encrypted, namespaceKeyPrefix))
+		assert.Empty(t, store.keys)
+
+		decrypted, err := master.Decrypt(*encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", *decrypted)
+	})
+}
+
+func TestRetiredNamespaceKeys(t *testing.T) {
+	now := time.Now()
+	keys := []NamespaceKey{
+		{Namespace: "team-a", Version: 3, CreatedAt: now.Add(-time.Second)},
+		{Namespace: "team-a", Version: 1, CreatedAt: now.Add(-time.Hour)},
+		{Namespace: "team-a", Version: 2, CreatedAt: now.Add(-time.Minute * 2)},
+	}
+
+	// the second version may still encrypt values on the instances which didn't reload the keys since the rotation
+	assert.Equal(t, []int{1}, RetiredNamespaceKeys(keys, now))
+	assert.Equal(t, []int{1, 2}, RetiredNamespaceKeys(keys, now.Add(namespaceKeysTTL)))
+	assert.Empty(t, RetiredNamespaceKeys(keys[:1], now.Add(time.Hour)))
+}
@@ -0,0 +1,169 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Providers of the keys of the envelope encryption.
+const (
+	KMSProviderAWS   = "aws"
+	KMSProviderGCP   = "gcp"
+	KMSProviderAzure = "azure"
+)
+
+// dataKeySize is the size of the data keys, for AES-256.
+const dataKeySize = 32
+
+// KMSSettings configure the key management service providing the data keys of the envelope encryption.
+type KMSSettings struct {
+	// Provider of the keys: aws, gcp or azure, values are encrypted with the raw key if empty.
+	Provider string `mapstructure:"provider" yaml:"provider" json:"provider,omitempty"`
+	// KeyID identifies the key encrypting the data keys: the ID, ARN or alias of an AWS KMS key, the resource name of a
+	// GCP KMS key, or the key identifier URL of an Azure Key Vault key.
+	KeyID string `mapstructure:"keyId" yaml:"keyId" json:"keyId,omitempty"`
+	// DataKeyTTL is how long a data key encrypts new values before a new one is generated.
+	DataKeyTTL time.Duration         `mapstructure:"dataKeyTtl" yaml:"dataKeyTtl" json:"dataKeyTtl,omitempty"`
+	AWS        AWSKMSSettings        `mapstructure:"aws" yaml:"aws" json:"aws"`
+	GCP        GCPKMSSettings        `mapstructure:"gcp" yaml:"gcp" json:"gcp"`
+	Azure      AzureKeyVaultSettings `mapstructure:"azure" yaml:"azure" json:"azure"`
+}
+
+// NewKeyProvider creates the key provider configured by the settings, nil if none is configured.
+func NewKeyProvider(settings KMSSettings, client *http.Client) (KeyProvider, error) {
+	if settings.Provider == "" {
+		return nil, nil
+	}
+
+	if settings.KeyID == "" {
+		return nil, fmt.Errorf("the %s key provider requires a key ID", settings.Provider)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: keyProviderTimeout}
+	}
+
+	switch settings.Provider {
+	case KMSProviderAWS:
+		return newAWSKeyProvider(settings.KeyID, settings.AWS, client)
+	case KMSProviderGCP:
+		return newGCPKeyProvider(settings.KeyID, settings.GCP, client), nil
+	case KMSProviderAzure:
+		return newAzureKeyProvider(settings.KeyID, settings.Azure, client)
+	default:
+		return nil, fmt.Errorf("unknown key provider: %q", settings.Provider)
+	}
+}
+
+// generateDataKey generates a random data key locally and encrypts it with the key provider, for the key management
+// services that only encrypt data.
+func generateDataKey(ctx context.Context, encrypt func(ctx context.Context, plaintext []byte) ([]byte, error)) ([]byte, []byte, error) {
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate data key")
+	}
+
+	ciphertext, err := encrypt(ctx, plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return plaintext, ciphertext, nil
+}
+
+// doJSON sends the request with the JSON encoded body and decodes the JSON response into res.
+func doJSON(ctx context.Context, client *http.Client, method, url string, headers http.Header, body any, res any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return sendJSON(client, req, res)
+}
+
+// sendJSON sends the request and decodes the JSON response into res.
+func sendJSON(client *http.Client, req *http.Request, res any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+// tokenSource returns the OAuth access tokens authenticating the requests to the key management service.
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// staticToken is a token configured explicitly, which the operator renews.
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// cachedTokenSource caches the access token returned by fetch until shortly before it expires.
+type cachedTokenSource struct {
+	fetch func(ctx context.Context) (token string, expiresIn time.Duration, err error)
+	now   func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newCachedTokenSource(fetch func(ctx context.Context) (string, time.Duration, error)) *cachedTokenSource {
+	return &cachedTokenSource{fetch: fetch, now: time.Now}
+}
+
+func (s *cachedTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	token, expiresIn, err := s.fetch(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get access token")
+	}
+
+	// the token is refreshed a minute before it expires, so it doesn't expire in flight
+	s.token = token
+	s.expiresAt = s.now().Add(expiresIn - time.Minute)
+	return token, nil
+}
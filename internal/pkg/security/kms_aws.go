@@ -0,0 +1,123 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/sigv4"
+)
+
+// AWSKMSSettings configure the access to AWS KMS.
+type AWSKMSSettings struct {
+	// Region of the key, us-east-1 if empty.
+	Region string `mapstructure:"region" yaml:"region" json:"region,omitempty"`
+	// Endpoint of KMS, e.g. of a VPC endpoint or LocalStack, the endpoint of the region if empty.
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint" json:"endpoint,omitempty"`
+	// The credentials default to the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment
+	// variables.
+	AccessKeyID     string `mapstructure:"accessKeyId" yaml:"accessKeyId" json:"-" redact:"true"`
+	SecretAccessKey string `mapstructure:"secretAccessKey" yaml:"secretAccessKey" json:"-" redact:"true"`
+	SessionToken    string `mapstructure:"sessionToken" yaml:"sessionToken" json:"-" redact:"true"`
+}
+
+// awsKeyProvider generates the data keys with the GenerateDataKey action of AWS KMS, which returns them both in plain
+// text and encrypted.
+type awsKeyProvider struct {
+	client      *http.Client
+	endpoint    string
+	region      string
+	keyID       string
+	credentials sigv4.Credentials
+	now         func() time.Time
+}
+
+func newAWSKeyProvider(keyID string, settings AWSKMSSettings, client *http.Client) (*awsKeyProvider, error) {
+	region := settings.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint := settings.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", region)
+	}
+
+	if parsed, err := url.Parse(endpoint); err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid kms endpoint: %q", endpoint)
+	}
+
+	credentials := sigv4.CredentialsFromEnv(sigv4.Credentials{
+		AccessKeyID:     settings.AccessKeyID,
+		SecretAccessKey: settings.SecretAccessKey,
+		SessionToken:    settings.SessionToken,
+	})
+	if !credentials.Valid() {
+		return nil, fmt.Errorf("the aws key provider requires an access key")
+	}
+
+	return &awsKeyProvider{
+		client:      client,
+		endpoint:    endpoint,
+		region:      region,
+		keyID:       keyID,
+		credentials: credentials,
+		now:         time.Now,
+	}, nil
+}
+
+func (p *awsKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	var res struct {
+		CiphertextBlob []byte
+		Plaintext      []byte
+	}
+
+	err := p.call(ctx, "GenerateDataKey", map[string]any{"KeyId": p.keyID, "KeySpec": "AES_256"}, &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res.Plaintext, res.CiphertextBlob, nil
+}
+
+func (p *awsKeyProvider) DecryptDataKey(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var res struct {
+		Plaintext []byte
+	}
+
+	// the key ID is checked by KMS, so a data key of another key can't be decrypted
+	err := p.call(ctx, "Decrypt", map[string]any{"KeyId": p.keyID, "CiphertextBlob": ciphertext}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Plaintext, nil
+}
+
+// call calls the action of the KMS JSON API. The blobs of the requests and responses are base64 encoded, as they are
+// by encoding/json.
+func (p *awsKeyProvider) call(ctx context.Context, action string, body any, res any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	sigv4.Sign(req, sigv4.SHA256Hex(encoded), p.credentials, p.region, "kms", p.now())
+
+	if err := sendJSON(p.client, req, res); err != nil {
+		return fmt.Errorf("kms %s: %w", action, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	azureKeyVaultAPIVersion = "7.4"
+	azureKeyVaultScope      = "https://vault.azure.net/.default"
+	azureAuthority          = "https://login.microsoftonline.com"
+	// azureIMDSTokenURL returns the access tokens of the managed identity of the VM or AKS workload.
+	azureIMDSTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=" +
+		"https%3A%2F%2Fvault.azure.net"
+)
+
+// AzureKeyVaultSettings configure the access to Azure Key Vault.
+type AzureKeyVaultSettings struct {
+	// Algorithm wrapping the data keys, RSA-OAEP-256 if empty.
+	Algorithm string `mapstructure:"algorithm" yaml:"algorithm" json:"algorithm,omitempty"`
+	// The service principal credentials default to the AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET
+	// environment variables, and to the managed identity of the instance if no client secret is set.
+	TenantID     string `mapstructure:"tenantId" yaml:"tenantId" json:"tenantId,omitempty"`
+	ClientID     string `mapstructure:"clientId" yaml:"clientId" json:"clientId,omitempty"`
+	ClientSecret string `mapstructure:"clientSecret" yaml:"clientSecret" json:"-" redact:"true"`
+}
+
+// azureKeyProvider wraps locally generated data keys with a Key Vault key. The wrapped data keys record the version of
+// the key that wrapped them, so they remain decryptable after the key is rotated.
+type azureKeyProvider struct {
+	client    *http.Client
+	keyURL    string
+	algorithm string
+	tokens    tokenSource
+}
+
+// azureWrappedKey is a data key wrapped by a version of a Key Vault key.
+type azureWrappedKey struct {
+	KeyID string `json:"kid"`
+	Value string `json:"value"`
+}
+
+func newAzureKeyProvider(keyURL string, settings AzureKeyVaultSettings, client *http.Client) (*azureKeyProvider, error) {
+	if parsed, err := url.Parse(keyURL); err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("the azure key ID must be the URL of a key vault key: %q", keyURL)
+	}
+
+	algorithm := settings.Algorithm
+	if algorithm == "" {
+		algorithm = "RSA-OAEP-256"
+	}
+
+	tenantID, clientID, clientSecret := settings.TenantID, settings.ClientID, settings.ClientSecret
+	if clientSecret == "" {
+		tenantID, clientID, clientSecret = os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	}
+
+	fetch := func(ctx context.Context) (string, time.Duration, error) {
+		return azureManagedIdentityToken(ctx, client, azureIMDSTokenURL)
+	}
+	if clientSecret != "" {
+		if tenantID == "" || clientID == "" {
+			return nil, fmt.Errorf("the azure key provider requires the tenant and client IDs of the client secret")
+		}
+
+		tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", azureAuthority, url.PathEscape(tenantID))
+		fetch = func(ctx context.Context) (string, time.Duration, error) {
+			return azureClientCredentialsToken(ctx, client, tokenURL, clientID, clientSecret)
+		}
+	}
+
+	return &azureKeyProvider{
+		client:    client,
+		keyURL:    strings.TrimSuffix(keyURL, "/"),
+		algorithm: algorithm,
+		tokens:    newCachedTokenSource(fetch),
+	}, nil
+}
+
+func (p *azureKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	return generateDataKey(ctx, func(ctx context.Context, plaintext []byte) ([]byte, error) {
+		var res azureWrappedKey
+		if err := p.call(ctx, p.keyURL, "wrapkey", base64.RawURLEncoding.EncodeToString(plaintext), &res); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(res)
+	})
+}
+
+func (p *azureKeyProvider) DecryptDataKey(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var wrapped azureWrappedKey
+	if err := json.Unmarshal(ciphertext, &wrapped); err != nil {
+		return nil, fmt.Errorf("malformed wrapped key: %w", err)
+	}
+
+	// only the versions of the configured key unwrap the data keys
+	if wrapped.KeyID != p.keyURL && !strings.HasPrefix(wrapped.KeyID, p.keyURL+"/") {
+		return nil, fmt.Errorf("data key wrapped by another key: %s", wrapped.KeyID)
+	}
+
+	var res azureWrappedKey
+	if err := p.call(ctx, wrapped.KeyID, "unwrapkey", wrapped.Value, &res); err != nil {
+		return nil, err
+	}
+
+	return base64.RawURLEncoding.DecodeString(res.Value)
+}
+
+func (p *azureKeyProvider) call(ctx context.Context, keyURL, operation, value string, res any) error {
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/%s?api-version=%s", keyURL, operation, azureKeyVaultAPIVersion)
+	headers := http.Header{"Authorization": {"Bearer " + token}}
+	body := map[string]string{"alg": p.algorithm, "value": value}
+	if err := doJSON(ctx, p.client, http.MethodPost, u, headers, body, res); err != nil {
+		return fmt.Errorf("key vault %s: %w", operation, err)
+	}
+
+	return nil
+}
+
+type azureToken struct {
+	AccessToken string `json:"access_token"`
+	// ExpiresIn is a number of seconds, encoded as a string by the instance metadata service
+	ExpiresIn json.Number `json:"expires_in"`
+}
+
+func (t azureToken) result() (string, time.Duration, error) {
+	expiresIn, err := t.ExpiresIn.Int64()
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid token expiration: %w", err)
+	}
+
+	return t.AccessToken, time.Duration(expiresIn) * time.Second, nil
+}
+
+// azureManagedIdentityToken gets an access token of the managed identity of the instance from the instance metadata
+// service.
+func azureManagedIdentityToken(ctx context.Context, client *http.Client, tokenURL string) (string, time.Duration, error) {
+	var res azureToken
+	if err := doJSON(ctx, client, http.MethodGet, tokenURL, http.Header{"Metadata": {"true"}}, nil, &res); err != nil {
+		return "", 0, err
+	}
+
+	return res.result()
+}
+
+// azureClientCredentialsToken gets an access token of the service principal with the client credentials flow.
+func azureClientCredentialsToken(ctx context.Context, client *http.Client, tokenURL, clientID, clientSecret string) (string, time.Duration, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"scope":         {azureKeyVaultScope},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var res azureToken
+	if err := sendJSON(client, req, &res); err != nil {
+		return "", 0, err
+	}
+
+	return res.result()
+}
@@ -0,0 +1,116 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	gcpKMSEndpoint = "https://cloudkms.googleapis.com"
+	// gcpMetadataTokenURL returns the access tokens of the service account of the GCE instance or GKE workload.
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// GCPKMSSettings configure the access to GCP Cloud KMS.
+type GCPKMSSettings struct {
+	// Endpoint of Cloud KMS, https://cloudkms.googleapis.com if empty.
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint" json:"endpoint,omitempty"`
+	// AccessToken authenticates the requests, defaults to the GOOGLE_OAUTH_ACCESS_TOKEN environment variable, or to the
+	// tokens of the service account of the instance from the metadata server if both are empty.
+	AccessToken string `mapstructure:"accessToken" yaml:"accessToken" json:"-" redact:"true"`
+}
+
+// gcpKeyProvider encrypts locally generated data keys with the encrypt method of a Cloud KMS key. The key version is
+// recorded in the ciphertext by Cloud KMS, so the data keys remain decryptable after the key is rotated.
+type gcpKeyProvider struct {
+	client   *http.Client
+	endpoint string
+	keyName  string
+	tokens   tokenSource
+}
+
+func newGCPKeyProvider(keyName string, settings GCPKMSSettings, client *http.Client) *gcpKeyProvider {
+	endpoint := settings.Endpoint
+	if endpoint == "" {
+		endpoint = gcpKMSEndpoint
+	}
+
+	token := settings.AccessToken
+	if token == "" {
+		token = os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	}
+
+	var tokens tokenSource = staticToken(token)
+	if token == "" {
+		tokens = newCachedTokenSource(func(ctx context.Context) (string, time.Duration, error) {
+			return gcpMetadataToken(ctx, client, gcpMetadataTokenURL)
+		})
+	}
+
+	return &gcpKeyProvider{
+		client:   client,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		keyName:  strings.TrimPrefix(keyName, "/"),
+		tokens:   tokens,
+	}
+}
+
+func (p *gcpKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	return generateDataKey(ctx, func(ctx context.Context, plaintext []byte) ([]byte, error) {
+		var res struct {
+			Ciphertext []byte `json:"ciphertext"`
+		}
+
+		if err := p.call(ctx, "encrypt", map[string]any{"plaintext": plaintext}, &res); err != nil {
+			return nil, err
+		}
+
+		return res.Ciphertext, nil
+	})
+}
+
+func (p *gcpKeyProvider) DecryptDataKey(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	var res struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+
+	if err := p.call(ctx, "decrypt", map[string]any{"ciphertext": ciphertext}, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Plaintext, nil
+}
+
+func (p *gcpKeyProvider) call(ctx context.Context, method string, body any, res any) error {
+	token, err := p.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s:%s", p.endpoint, p.keyName, method)
+	headers := http.Header{"Authorization": {"Bearer " + token}}
+	if err := doJSON(ctx, p.client, http.MethodPost, url, headers, body, res); err != nil {
+		return fmt.Errorf("cloud kms %s: %w", method, err)
+	}
+
+	return nil
+}
+
+// gcpMetadataToken gets an access token of the service account of the instance from the metadata server.
+func gcpMetadataToken(ctx context.Context, client *http.Client, url string) (string, time.Duration, error) {
+	var res struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	headers := http.Header{"Metadata-Flavor": {"Google"}}
+	if err := doJSON(ctx, client, http.MethodGet, url, headers, nil, &res); err != nil {
+		return "", 0, err
+	}
+
+	return res.AccessToken, time.Duration(res.ExpiresIn) * time.Second, nil
+}
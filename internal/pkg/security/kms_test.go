@@ -0,0 +1,179 @@
+package security
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewKeyProvider(t *testing.T) {
+	provider, err := NewKeyProvider(KMSSettings{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, provider)
+
+	_, err = NewKeyProvider(KMSSettings{Provider: KMSProviderGCP}, nil)
+	assert.Error(t, err)
+
+	_, err = NewKeyProvider(KMSSettings{Provider: "vault", KeyID: "key"}, nil)
+	assert.Error(t, err)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	_, err = NewKeyProvider(KMSSettings{Provider: KMSProviderAWS, KeyID: "alias/scheduler"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewKeyProvider(KMSSettings{Provider: KMSProviderAzure, KeyID: "scheduler"}, nil)
+	assert.Error(t, err)
+}
+
+func TestAWSKeyProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request")
+
+		var body struct {
+			KeyId          string
+			KeySpec        string
+			CiphertextBlob []byte
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "alias/scheduler", body.KeyId)
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GenerateDataKey":
+			assert.Equal(t, "AES_256", body.KeySpec)
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": []byte("plaintext"), "CiphertextBlob": []byte("ciphertext")})
+		case "TrentService.Decrypt":
+			assert.Equal(t, []byte("ciphertext"), body.CiphertextBlob)
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": []byte("plaintext")})
+		default:
+			http.Error(w, "unknown target", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewKeyProvider(KMSSettings{
+		Provider: KMSProviderAWS,
+		KeyID:    "alias/scheduler",
+		AWS:      AWSKMSSettings{Region: "eu-west-1", Endpoint: server.URL, AccessKeyID: "key", SecretAccessKey: "secret"},
+	}, server.Client())
+	require.NoError(t, err)
+
+	plaintext, ciphertext, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plaintext"), plaintext)
+	assert.Equal(t, []byte("ciphertext"), ciphertext)
+
+	decrypted, err := provider.DecryptDataKey(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plaintext"), decrypted)
+}
+
+func TestGCPKeyProvider(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var body map[string][]byte
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/" + keyName + ":encrypt":
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"ciphertext": append([]byte("wrapped:"), body["plaintext"]...)})
+		case "/v1/" + keyName + ":decrypt":
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"plaintext": []byte(strings.TrimPrefix(string(body["ciphertext"]), "wrapped:"))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewKeyProvider(KMSSettings{
+		Provider: KMSProviderGCP,
+		KeyID:    keyName,
+		GCP:      GCPKMSSettings{Endpoint: server.URL, AccessToken: "token"},
+	}, server.Client())
+	require.NoError(t, err)
+
+	plaintext, ciphertext, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, plaintext, dataKeySize)
+
+	decrypted, err := provider.DecryptDataKey(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestAzureKeyProvider(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, azureKeyVaultScope, r.PostForm.Get("scope"))
+			_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 3599}`))
+			return
+		}
+
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, azureKeyVaultAPIVersion, r.URL.Query().Get("api-version"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "RSA-OAEP-256", body["alg"])
+
+		switch r.URL.Path {
+		case "/keys/scheduler/wrapkey":
+			_ = json.NewEncoder(w).Encode(azureWrappedKey{KeyID: server.URL + "/keys/scheduler/v1", Value: "w" + body["value"]})
+		case "/keys/scheduler/v1/unwrapkey":
+			_ = json.NewEncoder(w).Encode(azureWrappedKey{KeyID: server.URL + "/keys/scheduler/v1", Value: strings.TrimPrefix(body["value"], "w")})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	token, _, err := azureClientCredentialsToken(context.Background(), server.Client(), server.URL+"/token", "client", "secret")
+	require.NoError(t, err)
+
+	provider, err := newAzureKeyProvider(server.URL+"/keys/scheduler", AzureKeyVaultSettings{}, server.Client())
+	require.NoError(t, err)
+	provider.tokens = staticToken(token)
+
+	plaintext, ciphertext, err := provider.GenerateDataKey(context.Background())
+	require.NoError(t, err)
+
+	var wrapped azureWrappedKey
+	require.NoError(t, json.Unmarshal(ciphertext, &wrapped))
+	assert.Equal(t, "w"+base64.RawURLEncoding.EncodeToString(plaintext), wrapped.Value)
+
+	decrypted, err := provider.DecryptDataKey(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	other, _ := json.Marshal(azureWrappedKey{KeyID: "https://other.vault.azure.net/keys/scheduler/v1", Value: "w"})
+	_, err = provider.DecryptDataKey(context.Background(), other)
+	assert.Error(t, err)
+}
+
+func TestAzureManagedIdentityToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		// the instance metadata service encodes the expiration as a string
+		_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": "86399"}`))
+	}))
+	defer server.Close()
+
+	token, expiresIn, err := azureManagedIdentityToken(context.Background(), server.Client(), server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+	assert.Equal(t, 86399.0, expiresIn.Seconds())
+}
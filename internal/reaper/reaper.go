@@ -0,0 +1,106 @@
+package reaper
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// reapBatchSize limits the number of executions reaped at once.
+const reapBatchSize = 100
+
+// Reaper periodically fails the executions left running by runners that stopped renewing the lock of their job,
+// e.g. because they crashed, and reschedules the jobs according to their misfire policy.
+type Reaper struct {
+	jobService  JobService
+	metrics     *metrics.ReaperMetrics
+	log         *otelzap.Logger
+	gracePeriod time.Duration
+	task        *periodic.Task
+}
+
+type JobService interface {
+	ReapZombieJobExecutions(ctx context.Context, before time.Time, limit uint) ([]model.ZombieExecution, error)
+}
+
+type Config struct {
+	JobService JobService
+	// Leader restricts the reaping to the leader instance, every instance reaps if nil
+	Leader   leader.Leader
+	Metrics  *metrics.ReaperMetrics
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// GracePeriod is how long after its lock expired an execution is considered abandoned
+	GracePeriod time.Duration `mapstructure:"gracePeriod" yaml:"gracePeriod" json:"gracePeriod,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Reaper, error) {
+	r := &Reaper{
+		jobService:  cfg.JobService,
+		metrics:     cfg.Metrics,
+		log:         cfg.Log,
+		gracePeriod: cfg.Settings.GracePeriod,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "zombie reaper",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      r.reap,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.task = task
+
+	return r, nil
+}
+
+// Start starts the reaper in a separate goroutine.
+// Only the first call will start the reaper, subsequent calls are ignored.
+func (r *Reaper) Start() {
+	r.task.Start()
+}
+
+// Stop stops the reaper and waits for the current reaping to finish or the context to expire.
+func (r *Reaper) Stop(ctx context.Context) {
+	r.task.Stop(ctx)
+}
+
+func (r *Reaper) reap(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	zombies, err := r.jobService.ReapZombieJobExecutions(ctx, time.Now().Add(-r.gracePeriod), reapBatchSize)
+	if err != nil {
+		r.log.Error("Failed to reap zombie job executions", zap.Error(err))
+		return
+	}
+
+	for _, zombie := range zombies {
+		r.metrics.IncreaseReapedExecutionCount(ctx,
+			attribute.String("job_type", string(zombie.Job.Type)),
+			attribute.String("namespace", zombie.Job.Namespace),
+		)
+	}
+
+	r.log.Debug("Reaped zombie job executions", zap.Int("count", len(zombies)))
+}
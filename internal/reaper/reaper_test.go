@@ -0,0 +1,85 @@
+package reaper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.uber.org/zap"
+)
+
+type mockJobService struct {
+	sync.Mutex
+	reaps  int
+	before []time.Time
+}
+
+func (m *mockJobService) ReapZombieJobExecutions(_ context.Context, before time.Time, _ uint) ([]model.ZombieExecution, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.reaps++
+	m.before = append(m.before, before)
+
+	return []model.ZombieExecution{{ExecutionID: m.reaps, Job: &model.Job{ID: uuid.New(), Type: model.JobTypeHTTP}}}, nil
+}
+
+func createReaper(t *testing.T, gracePeriod time.Duration) (*Reaper, *mockJobService) {
+	jobService := &mockJobService{}
+	zapL, _ := zap.NewDevelopment()
+
+	r, err := New(Config{
+		JobService: jobService,
+		Metrics:    metrics.NewReaperMetrics(observability.MetricsConfig{}),
+		Log:        otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:     true,
+			Interval:    time.Millisecond * 20,
+			GracePeriod: gracePeriod,
+		},
+	})
+	assert.NoError(t, err)
+
+	return r, jobService
+}
+
+func TestReaper(t *testing.T) {
+	t.Run("Reaps the executions abandoned before the grace period", func(t *testing.T) {
+		r, jobService := createReaper(t, time.Minute)
+		r.reap(context.Background())
+
+		assert.Equal(t, 1, jobService.reaps)
+		assert.WithinDuration(t, time.Now().Add(-time.Minute), jobService.before[0], time.Second)
+	})
+
+	t.Run("Only the leader reaps", func(t *testing.T) {
+		jobService := &mockJobService{}
+		r, err := New(Config{
+			JobService: jobService,
+			Leader:     leader.Static(false),
+			Metrics:    metrics.NewReaperMetrics(observability.MetricsConfig{}),
+			Log:        otelzap.New(zap.NewNop()),
+			Settings:   Settings{Enabled: true, Interval: time.Millisecond * 20, GracePeriod: time.Minute},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		r.Start()
+		time.Sleep(time.Millisecond * 100)
+		r.Stop(context.Background())
+
+		assert.Equal(t, 0, jobService.reaps)
+	})
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{Enabled: true, GracePeriod: time.Minute}})
+	assert.Error(t, err)
+}
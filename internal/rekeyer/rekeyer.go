@@ -0,0 +1,89 @@
+package rekeyer
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Rekeyer periodically encrypts the credentials of the namespaces whose key was rotated again with the new key, and
+// deletes the previous versions of their keys once no credential is encrypted with them anymore. The store records
+// the progress of the rotations, so they resume where they stopped, e.g. after a restart or a change of leader.
+type Rekeyer struct {
+	store Store
+	log   *otelzap.Logger
+	task  *periodic.Task
+}
+
+type Store interface {
+	ReencryptRotatedNamespaces(ctx context.Context) ([]string, error)
+}
+
+type Config struct {
+	Store Store
+	// Leader restricts the encryption to the leader instance, every instance encrypts if nil
+	Leader   leader.Leader
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Rekeyer, error) {
+	r := &Rekeyer{
+		store: cfg.Store,
+		log:   cfg.Log,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "namespace key rotation",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      r.reencrypt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.task = task
+
+	return r, nil
+}
+
+// Start starts the rekeyer in a separate goroutine.
+// Only the first call will start the rekeyer, subsequent calls are ignored.
+func (r *Rekeyer) Start() {
+	r.task.Start()
+}
+
+// Stop stops the rekeyer and waits for the current run to finish or the context to expire.
+func (r *Rekeyer) Stop(ctx context.Context) {
+	r.task.Stop(ctx)
+}
+
+func (r *Rekeyer) reencrypt(ctx context.Context) {
+	// a rotation cut short by the timeout resumes on the next run
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+
+	completed, err := r.store.ReencryptRotatedNamespaces(ctx)
+	if err != nil {
+		r.log.Error("Failed to encrypt the credentials again with the rotated namespace keys", zap.Error(err))
+	}
+
+	if len(completed) > 0 {
+		r.log.Info("Completed namespace key rotations", zap.Strings("namespaces", completed))
+	}
+}
@@ -0,0 +1,72 @@
+package rekeyer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockStore struct {
+	sync.Mutex
+	runs int
+}
+
+func (m *mockStore) ReencryptRotatedNamespaces(_ context.Context) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.runs++
+
+	return []string{"team-a"}, nil
+}
+
+func (m *mockStore) Runs() int {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.runs
+}
+
+func TestRekeyer(t *testing.T) {
+	t.Run("Encrypts the rotated namespaces periodically", func(t *testing.T) {
+		store := &mockStore{}
+		r, err := New(Config{
+			Store:    store,
+			Log:      otelzap.New(zap.NewNop()),
+			Settings: Settings{Enabled: true, Interval: time.Millisecond * 20},
+		})
+		assert.NoError(t, err)
+
+		r.Start()
+		assert.Eventually(t, func() bool { return store.Runs() >= 2 }, time.Second, time.Millisecond*10)
+		r.Stop(context.Background())
+	})
+
+	t.Run("Only the leader encrypts the rotated namespaces", func(t *testing.T) {
+		store := &mockStore{}
+		r, err := New(Config{
+			Store:    store,
+			Leader:   leader.Static(false),
+			Log:      otelzap.New(zap.NewNop()),
+			Settings: Settings{Enabled: true, Interval: time.Millisecond * 20},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		r.Start()
+		time.Sleep(time.Millisecond * 100)
+		r.Stop(context.Background())
+
+		assert.Zero(t, store.Runs())
+	})
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{Enabled: true}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package resultsink
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// publishAMQP publishes the result as a persistent message on a channel in confirm mode, waiting for the broker to
+// confirm it. Like AMQP jobs, each publication opens its own connection, as the sinks of the jobs differ.
+func publishAMQP(ctx context.Context, sink model.JobResultSink, result model.ExecutionResult, data []byte) error {
+	conn, err := amqp.Dial(sink.URL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to AMQP: %w", err)
+	}
+	defer conn.Close()
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open a channel: %w", err)
+	}
+	defer channel.Close()
+
+	if err := channel.Confirm(false); err != nil {
+		return fmt.Errorf("failed to put the channel in confirm mode: %w", err)
+	}
+
+	routingKey := sink.RoutingKey
+	if routingKey == "" {
+		routingKey = string(result.Status)
+	}
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx, sink.Exchange, routingKey, false, false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    strconv.Itoa(result.ExecutionID),
+			Timestamp:    result.EndTime,
+			Type:         string(result.Status),
+			Body:         data,
+		})
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to confirm message: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("message rejected by the broker")
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package resultsink
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/kafka"
+)
+
+// publishKafka produces the result to the topic with the v2 API of the REST proxy, e.g. a Confluent REST Proxy or
+// Redpanda's HTTP Proxy. The records are keyed by the ID of the job, so the results of a job land on the same
+// partition, in order. The credentials of the URL authenticate to the proxy with basic auth.
+func publishKafka(ctx context.Context, client *http.Client, sink model.JobResultSink, result model.ExecutionResult, data []byte) error {
+	proxy, err := kafka.ParseProxy(sink.URL)
+	if err != nil {
+		return err
+	}
+
+	return kafka.Produce(ctx, client, proxy, sink.Topic, result.JobID.String(), data)
+}
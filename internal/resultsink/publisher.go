@@ -0,0 +1,109 @@
+// Package resultsink publishes the results of the executions to the result sinks of their jobs, e.g. an AMQP exchange
+// or a Kafka topic, so event-driven pipelines can react to scheduled work finishing.
+package resultsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/klauspost/compress/zstd"
+)
+
+// defaultTimeout is the time allowed to publish a result.
+const defaultTimeout = 10 * time.Second
+
+// Publisher publishes the results to the sinks of the jobs.
+type Publisher struct {
+	client  *http.Client
+	secrets executor.SecretResolver
+	timeout time.Duration
+}
+
+// Option configures the publisher.
+type Option func(p *Publisher)
+
+// WithSecretResolver resolves the URLs of the sinks referring to secrets before each publication, like the
+// connections of AMQP jobs.
+func WithSecretResolver(resolver executor.SecretResolver) Option {
+	return func(p *Publisher) {
+		p.secrets = resolver
+	}
+}
+
+// New creates a publisher. The HTTP client is used by the Kafka REST proxies, e.g. the egress client of the jobs.
+func New(client *http.Client, options ...Option) *Publisher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	p := &Publisher{client: client, timeout: defaultTimeout}
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// Publish publishes the result to the sink, returning once the broker acknowledged it. Results are published at
+// most once: a result which failed to be published is not published again.
+func (p *Publisher) Publish(ctx context.Context, sink model.JobResultSink, result model.ExecutionResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode the result of execution %d: %w", result.ExecutionID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	if p.secrets != nil {
+		sink.URL, err = p.secrets.Resolve(ctx, sink.URL)
+		if err != nil {
+			return fmt.Errorf("failed to resolve the url of the result sink: %w", err)
+		}
+	}
+
+	switch sink.Type {
+	case model.ResultSinkTypeAMQP:
+		err = publishAMQP(ctx, sink, result, data)
+	case model.ResultSinkTypeKafka:
+		err = publishKafka(ctx, p.client, sink, result, data)
+	default:
+		return fmt.Errorf("unknown result sink type: %q", sink.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to publish the result of execution %d: %w", result.ExecutionID, err)
+	}
+
+	return nil
+}
+
+// NewResult creates the result of an execution of the job which finished with the error, with the beginning of its
+// output if it has any. Only the excerpt is decompressed.
+func NewResult(job *model.Job, executionID int, startTime, endTime time.Time, err error, output *model.ExecutionOutput) model.ExecutionResult {
+	result := model.NewExecutionResult(job, executionID, startTime, endTime, err)
+	if output == nil {
+		return result
+	}
+
+	reader, decodeErr := zstd.NewReader(bytes.NewReader(output.Data), zstd.WithDecoderConcurrency(1))
+	if decodeErr != nil {
+		return result
+	}
+	defer reader.Close()
+
+	// one more byte than the excerpt tells whether the output is longer
+	excerpt, decodeErr := io.ReadAll(io.LimitReader(reader, model.MaxResultOutputLength+1))
+	if decodeErr != nil {
+		return result
+	}
+
+	result.SetOutput(excerpt, output.Truncated)
+	return result
+}
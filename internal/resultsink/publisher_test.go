@@ -0,0 +1,152 @@
+package resultsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	error2 "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/kafka"
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func testJob() *model.Job {
+	return &model.Job{
+		ID:        uuid.MustParse("6a8e1f0c-1d2b-4c3d-8e9f-0a1b2c3d4e5f"),
+		Namespace: "billing",
+		Name:      null.StringFrom("invoices"),
+		Type:      model.JobTypeHTTP,
+		HTTPJob: &model.HTTPJob{
+			URL:  "https://billing.example.com/invoices",
+			Auth: model.Auth{Type: model.AuthTypeBearer, BearerToken: null.StringFrom("s3cr3t-token")},
+		},
+		Metadata: map[string]string{"ticket": "OPS-1234"},
+	}
+}
+
+func compressed(t *testing.T, data []byte, truncated bool) *model.ExecutionOutput {
+	var buf bytes.Buffer
+	encoder, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = encoder.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Close())
+
+	return &model.ExecutionOutput{Size: int64(len(data)), Truncated: truncated, Encoding: model.ExecutionOutputEncodingZstd, Data: buf.Bytes()}
+}
+
+func TestNewResult(t *testing.T) {
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	end := start.Add(1500 * time.Millisecond)
+
+	result := NewResult(testJob(), 7, start, end, nil, compressed(t, []byte(`{"invoices": 12}`), false))
+	assert.Equal(t, model.JobExecutionStatusSuccessful, result.Status)
+	assert.Equal(t, "invoices", result.JobName)
+	assert.Equal(t, int64(1500), result.Duration)
+	assert.Equal(t, `{"invoices": 12}`, result.Output)
+	assert.False(t, result.OutputTruncated)
+	assert.Equal(t, map[string]string{"ticket": "OPS-1234"}, result.Metadata)
+
+	// the credentials of the job are masked in the error
+	result = NewResult(testJob(), 7, start, end, errors.New("401: invalid token s3cr3t-token"), nil)
+	assert.Equal(t, model.JobExecutionStatusFailed, result.Status)
+	assert.NotContains(t, result.Error, "s3cr3t-token")
+	assert.Empty(t, result.Output)
+
+	result = NewResult(testJob(), 7, start, end, error2.ErrExecutionTimedOut, nil)
+	assert.Equal(t, model.JobExecutionStatusTimedOut, result.Status)
+
+	// only the beginning of long outputs is kept
+	long := strings.Repeat("a", model.MaxResultOutputLength*2)
+	result = NewResult(testJob(), 7, start, end, nil, compressed(t, []byte(long), false))
+	assert.Len(t, result.Output, model.MaxResultOutputLength)
+	assert.True(t, result.OutputTruncated)
+}
+
+func TestPublishKafka(t *testing.T) {
+	var (
+		path     string
+		username string
+		password string
+		records  kafka.Records
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		username, password, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &records))
+
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":12}]}`))
+	}))
+	defer server.Close()
+
+	sink := model.JobResultSink{
+		Type:  model.ResultSinkTypeKafka,
+		URL:   strings.Replace(server.URL, "http://", "http://scheduler:secret@", 1),
+		Topic: "job-results",
+	}
+	result := model.ExecutionResult{JobID: testJob().ID, ExecutionID: 7, Status: model.JobExecutionStatusSuccessful}
+
+	require.NoError(t, New(server.Client()).Publish(context.Background(), sink, result))
+	assert.Equal(t, "/topics/job-results", path)
+	assert.Equal(t, "scheduler", username)
+	assert.Equal(t, "secret", password)
+	require.Len(t, records.Records, 1)
+	assert.Equal(t, "6a8e1f0c-1d2b-4c3d-8e9f-0a1b2c3d4e5f", records.Records[0].Key)
+
+	var published model.ExecutionResult
+	require.NoError(t, json.Unmarshal(records.Records[0].Value, &published))
+	assert.Equal(t, 7, published.ExecutionID)
+	assert.Equal(t, model.JobExecutionStatusSuccessful, published.Status)
+}
+
+func TestPublishKafkaError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"offsets":[{"error_code":50003,"error":"Kafka error"}]}`))
+	}))
+	defer server.Close()
+
+	sink := model.JobResultSink{Type: model.ResultSinkTypeKafka, URL: server.URL, Topic: "job-results"}
+	err := New(server.Client()).Publish(context.Background(), sink, model.ExecutionResult{ExecutionID: 7})
+	assert.ErrorContains(t, err, "50003")
+}
+
+// mockSecretResolver resolves the secrets it knows.
+type mockSecretResolver map[string]string
+
+func (m mockSecretResolver) Resolve(_ context.Context, value string) (string, error) {
+	if secret, ok := m[value]; ok {
+		return secret, nil
+	}
+	return value, nil
+}
+
+func TestPublishResolvesSecrets(t *testing.T) {
+	published := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		published = true
+		_, _ = w.Write([]byte(`{"offsets":[]}`))
+	}))
+	defer server.Close()
+
+	publisher := New(server.Client(), WithSecretResolver(mockSecretResolver{"vault:secret/data/kafka#url": server.URL}))
+	sink := model.JobResultSink{Type: model.ResultSinkTypeKafka, URL: "vault:secret/data/kafka#url", Topic: "job-results"}
+
+	require.NoError(t, publisher.Publish(context.Background(), sink, model.ExecutionResult{ExecutionID: 7}))
+	assert.True(t, published)
+
+	sink.Type = "SQS"
+	assert.ErrorContains(t, publisher.Publish(context.Background(), sink, model.ExecutionResult{ExecutionID: 7}), "SQS")
+}
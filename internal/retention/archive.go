@@ -0,0 +1,102 @@
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// Types of the archives of the expired executions.
+const (
+	ArchiveTypeFile = "file"
+	ArchiveTypeS3   = "s3"
+)
+
+// Archiver writes the expired executions somewhere before they are deleted, e.g. to object storage.
+type Archiver interface {
+	// Archive writes a batch of executions, encoded as gzip-compressed NDJSON, under the key.
+	Archive(ctx context.Context, key string, data []byte) error
+}
+
+// ArchiveSettings configure the archive of the expired executions.
+type ArchiveSettings struct {
+	// Type of the archive: s3 for S3 or S3-compatible object storage such as GCS and MinIO, file for a local
+	// directory, or none if empty, in which case the expired executions are deleted right away.
+	Type string `mapstructure:"type" yaml:"type" json:"type,omitempty"`
+	// Prefix of the keys of the archived batches, e.g. scheduler/executions/
+	Prefix string `mapstructure:"prefix" yaml:"prefix" json:"prefix,omitempty"`
+	// Dir is the directory of the file archive
+	Dir string     `mapstructure:"dir" yaml:"dir" json:"dir,omitempty"`
+	S3  S3Settings `mapstructure:"s3" yaml:"s3" json:"s3"`
+}
+
+// NewArchiver creates the archiver configured by the settings, nil if the archive is disabled.
+func NewArchiver(settings ArchiveSettings, client *http.Client) (Archiver, error) {
+	switch settings.Type {
+	case "":
+		return nil, nil
+	case ArchiveTypeFile:
+		if settings.Dir == "" {
+			return nil, fmt.Errorf("the file archive requires a directory")
+		}
+
+		return &fileArchiver{dir: filepath.Join(settings.Dir, filepath.FromSlash(settings.Prefix))}, nil
+	case ArchiveTypeS3:
+		return newS3Archiver(settings.S3, settings.Prefix, client)
+	default:
+		return nil, fmt.Errorf("unknown archive type: %q", settings.Type)
+	}
+}
+
+// fileArchiver writes the batches to files in a directory, with the key as their relative path.
+type fileArchiver struct {
+	dir string
+}
+
+func (a *fileArchiver) Archive(_ context.Context, key string, data []byte) error {
+	path := filepath.Join(a.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create archive directory: %w", err)
+	}
+
+	// the batch is written under a temporary name first, so a partially written file is never mistaken for a batch
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// archiveKey identifies a batch by the day it was archived and the IDs of its first and last executions.
+func archiveKey(at time.Time, executions []*model.JobExecution) string {
+	return fmt.Sprintf("%s/executions-%d-%d.ndjson.gz",
+		at.UTC().Format("2006/01/02"), executions[0].ID, executions[len(executions)-1].ID)
+}
+
+// encodeExecutions encodes the executions as gzip-compressed NDJSON, one execution per line.
+func encodeExecutions(executions []*model.JobExecution) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+
+	encoder := json.NewEncoder(zw)
+	for _, execution := range executions {
+		if err := encoder.Encode(execution); err != nil {
+			return nil, fmt.Errorf("encode execution %d: %w", execution.ID, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress executions: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// PruneOptions select the executions deleted by Prune, and the pace of the deletion.
+type PruneOptions struct {
+	Expiry model.ExecutionExpiry
+	// BatchSize is the number of executions archived and deleted at once
+	BatchSize uint
+	// Rate limits the number of executions deleted per second, unlimited if zero
+	Rate uint
+	// Progress is called after each batch with the number of executions deleted so far, if set
+	Progress func(deleted int64)
+}
+
+// Prune archives and deletes the expired executions batch by batch until none are left, pausing between the batches
+// to keep within the rate, e.g. from a cron job. It returns the number of deleted executions.
+func Prune(ctx context.Context, jobService JobService, archiver Archiver, options PruneOptions) (int64, error) {
+	batchSize := options.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var pause time.Duration
+	if options.Rate > 0 {
+		pause = time.Duration(batchSize) * time.Second / time.Duration(options.Rate)
+	}
+
+	var deleted int64
+	for {
+		expired, swept, err := deleteBatch(ctx, jobService, archiver, options.Expiry, batchSize)
+		deleted += swept
+		if err != nil {
+			return deleted, err
+		}
+
+		if options.Progress != nil {
+			options.Progress(deleted)
+		}
+
+		// a partial batch means no expired executions are left, and a batch none of which could be deleted would be
+		// expired again forever
+		if expired < int(batchSize) || swept == 0 {
+			return deleted, nil
+		}
+
+		select {
+		case <-time.After(pause):
+		case <-ctx.Done():
+			return deleted, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// defaultBatchSize limits the number of executions archived and deleted at once, if the batch size is unset.
+const defaultBatchSize = 1000
+
+// Sweeper periodically deletes the finished executions expired by the retention policy, after writing them to the
+// archive if one is configured.
+type Sweeper struct {
+	jobService JobService
+	archiver   Archiver
+	log        *otelzap.Logger
+	maxAge     time.Duration
+	keepLast   uint
+	batchSize  uint
+	task       *periodic.Task
+}
+
+type JobService interface {
+	GetExpiredJobExecutions(ctx context.Context, expiry model.ExecutionExpiry, limit uint) ([]*model.JobExecution, error)
+	DeleteJobExecutions(ctx context.Context, executionIDs []int) (int64, error)
+}
+
+type Config struct {
+	JobService JobService
+	// Leader restricts the sweeps to the leader instance, every instance sweeps if nil
+	Leader leader.Leader
+	// Archiver writes the expired executions before they are deleted, they are deleted right away if nil
+	Archiver Archiver
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// MaxAge expires the executions created longer ago, executions don't expire by age if zero
+	MaxAge time.Duration `mapstructure:"maxAge" yaml:"maxAge" json:"maxAge,omitempty"`
+	// KeepLast expires the executions of a job beyond its latest ones, executions don't expire by count if zero
+	KeepLast uint `mapstructure:"keepLast" yaml:"keepLast" json:"keepLast,omitempty"`
+	// BatchSize is the number of executions archived and deleted at once
+	BatchSize uint            `mapstructure:"batchSize" yaml:"batchSize" json:"batchSize,omitempty"`
+	Archive   ArchiveSettings `mapstructure:"archive" yaml:"archive" json:"archive"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Sweeper, error) {
+	batchSize := cfg.Settings.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+
+	s := &Sweeper{
+		jobService: cfg.JobService,
+		archiver:   cfg.Archiver,
+		log:        cfg.Log,
+		maxAge:     cfg.Settings.MaxAge,
+		keepLast:   cfg.Settings.KeepLast,
+		batchSize:  batchSize,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "execution retention sweeper",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      s.sweep,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.task = task
+
+	return s, nil
+}
+
+// Start starts the sweeper in a separate goroutine.
+// Only the first call will start the sweeper, subsequent calls are ignored.
+func (s *Sweeper) Start() {
+	s.task.Start()
+}
+
+// Stop stops the sweeper and waits for the current sweep to finish or the context to expire.
+func (s *Sweeper) Stop(ctx context.Context) {
+	s.task.Stop(ctx)
+}
+
+// sweep archives and deletes the expired executions batch by batch, until none are left or the sweep timed out.
+func (s *Sweeper) sweep(ctx context.Context) {
+	if s.maxAge <= 0 && s.keepLast == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
+	defer cancel()
+
+	var deleted int64
+	for ctx.Err() == nil {
+		expired, swept, err := s.sweepBatch(ctx)
+		deleted += swept
+		if err != nil {
+			s.log.Error("Failed to sweep expired job executions", zap.Error(err))
+			break
+		}
+
+		// a partial batch means no expired executions are left
+		if expired < int(s.batchSize) {
+			break
+		}
+	}
+
+	s.log.Debug("Swept expired job executions", zap.Int64("count", deleted))
+}
+
+// sweepBatch archives and deletes a batch of expired executions, and returns the number of expired and deleted ones.
+func (s *Sweeper) sweepBatch(ctx context.Context) (int, int64, error) {
+	var before null.Time
+	if s.maxAge > 0 {
+		before = null.TimeFrom(time.Now().Add(-s.maxAge))
+	}
+
+	return deleteBatch(ctx, s.jobService, s.archiver, model.ExecutionExpiry{Before: before, KeepLast: s.keepLast}, s.batchSize)
+}
+
+// deleteBatch archives and deletes a batch of expired executions, and returns the number of expired and deleted ones.
+func deleteBatch(ctx context.Context, jobService JobService, archiver Archiver, expiry model.ExecutionExpiry, batchSize uint) (int, int64, error) {
+	executions, err := jobService.GetExpiredJobExecutions(ctx, expiry, batchSize)
+	if err != nil || len(executions) == 0 {
+		return 0, 0, err
+	}
+
+	// executions are only deleted once they are archived, a failed deletion archives them again in the next sweep
+	if archiver != nil {
+		data, err := encodeExecutions(executions)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if err := archiver.Archive(ctx, archiveKey(time.Now(), executions), data); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	ids := make([]int, 0, len(executions))
+	for _, execution := range executions {
+		ids = append(ids, execution.ID)
+	}
+
+	deleted, err := jobService.DeleteJobExecutions(ctx, ids)
+	return len(executions), deleted, err
+}
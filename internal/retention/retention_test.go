@@ -0,0 +1,249 @@
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+type mockJobService struct {
+	sync.Mutex
+	expired  []*model.JobExecution
+	before   []null.Time
+	keepLast []uint
+	expiries []model.ExecutionExpiry
+	deleted  []int
+}
+
+func (m *mockJobService) GetExpiredJobExecutions(_ context.Context, expiry model.ExecutionExpiry, limit uint) ([]*model.JobExecution, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.before = append(m.before, expiry.Before)
+	m.keepLast = append(m.keepLast, expiry.KeepLast)
+	m.expiries = append(m.expiries, expiry)
+
+	n := min(int(limit), len(m.expired))
+	batch := m.expired[:n]
+	m.expired = m.expired[n:]
+	return batch, nil
+}
+
+func (m *mockJobService) DeleteJobExecutions(_ context.Context, executionIDs []int) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.deleted = append(m.deleted, executionIDs...)
+	return int64(len(executionIDs)), nil
+}
+
+type mockArchiver struct {
+	sync.Mutex
+	keys []string
+	err  error
+}
+
+func (m *mockArchiver) Archive(_ context.Context, key string, _ []byte) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func executions(ids ...int) []*model.JobExecution {
+	executions := make([]*model.JobExecution, 0, len(ids))
+	for _, id := range ids {
+		executions = append(executions, &model.JobExecution{ID: id, Status: model.JobExecutionStatusSuccessful})
+	}
+
+	return executions
+}
+
+func createSweeper(t *testing.T, archiver Archiver, settings Settings) (*Sweeper, *mockJobService) {
+	jobService := &mockJobService{}
+	zapL, _ := zap.NewDevelopment()
+
+	settings.Enabled = true
+	settings.Interval = time.Millisecond * 20
+
+	s, err := New(Config{
+		JobService: jobService,
+		Archiver:   archiver,
+		Log:        otelzap.New(zapL),
+		Settings:   settings,
+	})
+	assert.NoError(t, err)
+
+	return s, jobService
+}
+
+func TestSweeper(t *testing.T) {
+	t.Run("Archives and deletes the expired executions in batches", func(t *testing.T) {
+		archiver := &mockArchiver{}
+		s, jobService := createSweeper(t, archiver, Settings{MaxAge: time.Hour, KeepLast: 10, BatchSize: 2})
+		jobService.expired = executions(1, 2, 3)
+
+		s.sweep(context.Background())
+
+		assert.Equal(t, []int{1, 2, 3}, jobService.deleted)
+		assert.Len(t, archiver.keys, 2)
+		assert.Contains(t, archiver.keys[0], "executions-1-2.ndjson.gz")
+		assert.Contains(t, archiver.keys[1], "executions-3-3.ndjson.gz")
+
+		require.NotEmpty(t, jobService.before)
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), jobService.before[0].Time, time.Second)
+		assert.Equal(t, uint(10), jobService.keepLast[0])
+	})
+
+	t.Run("Keeps the executions that couldn't be archived", func(t *testing.T) {
+		archiver := &mockArchiver{err: errors.New("unavailable")}
+		s, jobService := createSweeper(t, archiver, Settings{KeepLast: 10})
+		jobService.expired = executions(1, 2)
+
+		s.sweep(context.Background())
+
+		assert.Empty(t, jobService.deleted)
+	})
+
+	t.Run("Deletes right away without an archive", func(t *testing.T) {
+		s, jobService := createSweeper(t, nil, Settings{MaxAge: time.Hour})
+		jobService.expired = executions(1, 2)
+
+		s.sweep(context.Background())
+
+		assert.Equal(t, []int{1, 2}, jobService.deleted)
+		assert.False(t, jobService.before[0].Time.IsZero())
+		assert.Equal(t, uint(0), jobService.keepLast[0])
+	})
+
+	t.Run("Doesn't sweep without a retention policy", func(t *testing.T) {
+		s, jobService := createSweeper(t, nil, Settings{})
+		jobService.expired = executions(1)
+
+		s.sweep(context.Background())
+
+		assert.Empty(t, jobService.before)
+		assert.Empty(t, jobService.deleted)
+	})
+
+	t.Run("Only the leader sweeps", func(t *testing.T) {
+		jobService := &mockJobService{expired: executions(1)}
+		s, err := New(Config{
+			JobService: jobService,
+			Leader:     leader.Static(false),
+			Log:        otelzap.New(zap.NewNop()),
+			Settings:   Settings{Enabled: true, Interval: time.Millisecond * 20, MaxAge: time.Hour},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		s.Start()
+		time.Sleep(time.Millisecond * 100)
+		s.Stop(context.Background())
+
+		assert.Empty(t, jobService.deleted)
+	})
+}
+
+func TestPrune(t *testing.T) {
+	t.Run("Archives and deletes the executions of the job in batches", func(t *testing.T) {
+		archiver := &mockArchiver{}
+		jobService := &mockJobService{expired: executions(1, 2, 3, 4)}
+		jobID := uuid.New()
+		expiry := model.ExecutionExpiry{JobID: &jobID, Before: null.TimeFrom(time.Now()), KeepLast: 5, MatchAll: true}
+
+		var progress []int64
+		deleted, err := Prune(context.Background(), jobService, archiver, PruneOptions{
+			Expiry:    expiry,
+			BatchSize: 2,
+			Rate:      1000,
+			Progress:  func(deleted int64) { progress = append(progress, deleted) },
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), deleted)
+		assert.Equal(t, []int{1, 2, 3, 4}, jobService.deleted)
+		assert.Equal(t, []int64{2, 4, 4}, progress)
+		assert.Len(t, archiver.keys, 2)
+		assert.Equal(t, expiry, jobService.expiries[0])
+	})
+
+	t.Run("Stops when the context is done", func(t *testing.T) {
+		jobService := &mockJobService{expired: executions(1, 2, 3)}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		deleted, err := Prune(ctx, jobService, nil, PruneOptions{BatchSize: 1, Rate: 1})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
+
+func TestFileArchiver(t *testing.T) {
+	dir := t.TempDir()
+
+	archiver, err := NewArchiver(ArchiveSettings{Type: ArchiveTypeFile, Dir: dir, Prefix: "scheduler/"}, nil)
+	require.NoError(t, err)
+
+	data, err := encodeExecutions(executions(1, 2))
+	require.NoError(t, err)
+
+	key := archiveKey(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC), executions(1, 2))
+	assert.Equal(t, "2026/10/15/executions-1-2.ndjson.gz", key)
+	require.NoError(t, archiver.Archive(context.Background(), key, data))
+
+	archived, err := os.ReadFile(filepath.Join(dir, "scheduler", "2026", "10", "15", "executions-1-2.ndjson.gz"))
+	require.NoError(t, err)
+
+	zr, err := gzip.NewReader(bytes.NewReader(archived))
+	require.NoError(t, err)
+
+	var ids []int
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var execution model.JobExecution
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &execution))
+		ids = append(ids, execution.ID)
+	}
+	assert.Equal(t, []int{1, 2}, ids)
+}
+
+func TestNewArchiver(t *testing.T) {
+	archiver, err := NewArchiver(ArchiveSettings{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, archiver)
+
+	_, err = NewArchiver(ArchiveSettings{Type: ArchiveTypeFile}, nil)
+	assert.Error(t, err)
+
+	_, err = NewArchiver(ArchiveSettings{Type: "ftp"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewArchiver(ArchiveSettings{Type: ArchiveTypeS3, S3: S3Settings{AccessKeyID: "key", SecretAccessKey: "secret"}}, nil)
+	assert.Error(t, err)
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{Enabled: true, MaxAge: time.Hour}})
+	assert.Error(t, err)
+}
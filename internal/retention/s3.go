@@ -0,0 +1,31 @@
+package retention
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/objectstore"
+)
+
+// S3Settings configure the archive on S3, or on S3-compatible object storage such as GCS (with HMAC keys) or MinIO.
+type S3Settings = objectstore.S3Settings
+
+// s3Archiver uploads the batches as objects of a bucket.
+type s3Archiver struct {
+	bucket *objectstore.S3Bucket
+	prefix string
+}
+
+func newS3Archiver(settings S3Settings, prefix string, client *http.Client) (*s3Archiver, error) {
+	bucket, err := objectstore.NewS3Bucket(settings, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Archiver{bucket: bucket, prefix: strings.TrimPrefix(prefix, "/")}, nil
+}
+
+func (a *s3Archiver) Archive(ctx context.Context, key string, data []byte) error {
+	return a.bucket.Put(ctx, a.prefix+key, "application/gzip", data)
+}
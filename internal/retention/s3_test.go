@@ -0,0 +1,66 @@
+package retention
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/sigv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3Archiver(t *testing.T) {
+	var (
+		path          string
+		body          string
+		authorization string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		assert.Equal(t, sigv4.SHA256Hex(data), r.Header.Get("X-Amz-Content-Sha256"))
+	}))
+	defer server.Close()
+
+	archiver, err := NewArchiver(ArchiveSettings{
+		Type:   ArchiveTypeS3,
+		Prefix: "scheduler/",
+		S3: S3Settings{
+			Endpoint:        server.URL,
+			Bucket:          "archive",
+			PathStyle:       true,
+			AccessKeyID:     "key",
+			SecretAccessKey: "secret",
+		},
+	}, server.Client())
+	require.NoError(t, err)
+
+	require.NoError(t, archiver.Archive(context.Background(), "2026/10/15/executions-1-2.ndjson.gz", []byte("data")))
+	assert.Equal(t, "/archive/scheduler/2026/10/15/executions-1-2.ndjson.gz", path)
+	assert.Equal(t, "data", body)
+	assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=key/"))
+}
+
+func TestS3ArchiverError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	archiver, err := NewArchiver(ArchiveSettings{
+		Type: ArchiveTypeS3,
+		S3:   S3Settings{Endpoint: server.URL, Bucket: "archive", PathStyle: true, AccessKeyID: "key", SecretAccessKey: "secret"},
+	}, server.Client())
+	require.NoError(t, err)
+
+	err = archiver.Archive(context.Background(), "executions.ndjson.gz", []byte("data"))
+	assert.ErrorContains(t, err, "AccessDenied")
+}
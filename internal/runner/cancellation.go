@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"go.uber.org/zap"
+)
+
+// runningExecution is an execution of the runner that can be cancelled.
+type runningExecution struct {
+	job       *model.Job
+	startedAt time.Time
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+}
+
+// watchCancellation returns a context for the execution, which is cancelled once the cancellation
+// of the execution is requested. The returned function stops watching for cancellation requests
+// and reports whether the execution was cancelled.
+func (s *Runner) watchCancellation(job *model.Job, executionID int) (context.Context, func() bool) {
+	ctx, cancel := context.WithCancel(s.execCtx)
+	if executionID == 0 {
+		return ctx, func() bool {
+			cancel()
+			return false
+		}
+	}
+
+	execution := &runningExecution{job: job, startedAt: time.Now(), cancel: cancel}
+
+	s.executionsMu.Lock()
+	s.executions[executionID] = execution
+	s.executionsMu.Unlock()
+
+	return ctx, func() bool {
+		s.executionsMu.Lock()
+		delete(s.executions, executionID)
+		s.executionsMu.Unlock()
+
+		cancel()
+		return execution.cancelled.Load()
+	}
+}
+
+// cancelExecution cancels the execution if the runner is executing it.
+func (s *Runner) cancelExecution(executionID int) {
+	s.executionsMu.Lock()
+	execution, ok := s.executions[executionID]
+	s.executionsMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	execution.cancelled.Store(true)
+	execution.cancel()
+}
+
+// runningExecutionIDs returns the IDs of the executions the runner is executing.
+func (s *Runner) runningExecutionIDs() []int {
+	s.executionsMu.Lock()
+	defer s.executionsMu.Unlock()
+
+	ids := make([]int, 0, len(s.executions))
+	for id := range s.executions {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// pollCancellations periodically checks all the running executions for cancellation requests in a single query,
+// and cancels the cancelled ones, until the running executions are interrupted.
+func (s *Runner) pollCancellations() {
+	defer s.stopWg.Done()
+
+	ticker := time.NewTicker(s.cancellationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ids := s.runningExecutionIDs()
+			if len(ids) == 0 {
+				continue
+			}
+
+			cancelled, err := s.jobService.GetCancelledJobExecutions(s.execCtx, ids)
+			if err != nil {
+				s.log.Warn("Failed to check for job execution cancellations", zap.Int("executions", len(ids)), zap.Error(err))
+				continue
+			}
+
+			for _, id := range cancelled {
+				s.cancelExecution(id)
+			}
+		case <-s.execCtx.Done():
+			return
+		}
+	}
+}
+
+// listenCancellations listens for cancellation requests until the running executions are interrupted, and cancels the requested
+// executions if the runner is executing them. Listening is retried after the poll interval if it fails.
+func (s *Runner) listenCancellations() {
+	defer s.stopWg.Done()
+
+	for {
+		err := s.jobService.ListenJobCancellations(s.execCtx, func(cancellation model.JobCancellation) {
+			s.cancelExecution(cancellation.ExecutionID)
+		})
+
+		if s.execCtx.Err() != nil {
+			return
+		}
+		s.log.Warn("Stopped listening for job cancellations, retrying", zap.Error(err))
+
+		select {
+		case <-s.execCtx.Done():
+			return
+		case <-time.After(s.pollInterval()):
+		}
+	}
+}
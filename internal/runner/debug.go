@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DebugState is a snapshot of the internal state of the runner, served on the debug listener.
+type DebugState struct {
+	InstanceID string `json:"instance_id"`
+	Stopping   bool   `json:"stopping"`
+	Interval   string `json:"interval"`
+	// Pools are the worker pools and their occupancy
+	Pools []PoolState `json:"pools"`
+	// Executions are the executions in flight, oldest first
+	Executions []ExecutionState `json:"executions"`
+	// Prefetched is the number of jobs fetched ahead, waiting to be due
+	Prefetched int `json:"prefetched"`
+	// Shard of the due jobs taken by the runner, with fair distribution
+	Shard *ShardState `json:"shard,omitempty"`
+}
+
+// PoolState is the occupancy of a worker pool.
+type PoolState struct {
+	Name  string `json:"name"`
+	InUse int    `json:"in_use"`
+	Size  int    `json:"size"`
+}
+
+// ExecutionState is an execution in flight.
+type ExecutionState struct {
+	ExecutionID int       `json:"execution_id"`
+	JobID       uuid.UUID `json:"job_id"`
+	JobType     string    `json:"job_type"`
+	StartedAt   time.Time `json:"started_at"`
+	Cancelled   bool      `json:"cancelled"`
+}
+
+// ShardState is the shard of the runner among the live runners.
+type ShardState struct {
+	Index int `json:"index"`
+	Count int `json:"count"`
+}
+
+// DebugState returns a snapshot of the internal state of the runner. The executions whose start couldn't be recorded
+// aren't tracked, so they aren't listed, but they occupy their pool.
+func (s *Runner) DebugState() DebugState {
+	state := DebugState{
+		InstanceID: s.instanceId,
+		Stopping:   s.ctx.Err() != nil,
+		Interval:   s.pollInterval().String(),
+		Pools:      []PoolState{},
+		Executions: []ExecutionState{},
+		Prefetched: s.prefetched.len(),
+	}
+
+	for _, pool := range s.pools.all() {
+		inUse, size := pool.usage()
+		state.Pools = append(state.Pools, PoolState{Name: pool.name, InUse: inUse, Size: size})
+	}
+
+	s.executionsMu.Lock()
+	for id, execution := range s.executions {
+		state.Executions = append(state.Executions, ExecutionState{
+			ExecutionID: id,
+			JobID:       execution.job.ID,
+			JobType:     string(execution.job.Type),
+			StartedAt:   execution.startedAt,
+			Cancelled:   execution.cancelled.Load(),
+		})
+	}
+	s.executionsMu.Unlock()
+
+	sort.Slice(state.Executions, func(i, j int) bool {
+		return state.Executions[i].StartedAt.Before(state.Executions[j].StartedAt)
+	})
+
+	if shard := s.shard.Load(); shard != nil {
+		state.Shard = &ShardState{Index: shard.Index, Count: shard.Count}
+	}
+
+	return state
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugState(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Second, 2, nil, nil, nil, nil)
+
+	job := &model.Job{ID: uuid.New(), Type: model.JobTypeHTTP}
+	require.True(t, s.acquireSlot(s.pools.get(job.Type)))
+	_, stopWatching := s.watchCancellation(job, 42)
+	s.cancelExecution(42)
+
+	state := s.DebugState()
+	assert.Equal(t, s.instanceId, state.InstanceID)
+	assert.False(t, state.Stopping)
+	assert.Equal(t, []PoolState{{Name: defaultPoolName, InUse: 1, Size: 2}}, state.Pools)
+	require.Len(t, state.Executions, 1)
+	assert.Equal(t, 42, state.Executions[0].ExecutionID)
+	assert.Equal(t, job.ID, state.Executions[0].JobID)
+	assert.True(t, state.Executions[0].Cancelled)
+
+	stopWatching()
+	s.releaseSlot(s.pools.get(job.Type))
+
+	state = s.DebugState()
+	assert.Empty(t, state.Executions)
+	assert.Equal(t, 0, state.Pools[0].InUse)
+}
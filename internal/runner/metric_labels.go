@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// metricLabels adds the labels the jobs opted into to their execution metrics, up to a maximum number of distinct
+// label sets, bounding the cardinality of the metrics. The executions of the jobs labelled with further label sets are
+// recorded without their labels.
+type metricLabels struct {
+	max int
+
+	mu   sync.Mutex
+	sets map[attribute.Distinct]struct{}
+	// full is set once a label set was dropped
+	full bool
+}
+
+func newMetricLabels(max int) *metricLabels {
+	return &metricLabels{
+		max:  max,
+		sets: map[attribute.Distinct]struct{}{},
+	}
+}
+
+// attributes returns the metric labels of the job as attributes, none if the job didn't opt into any label or if the
+// cap was reached. capReached is only set the first time labels are dropped, so the cap is reported once.
+func (l *metricLabels) attributes(job *model.Job) (attrs []attribute.KeyValue, capReached bool) {
+	if len(job.MetricLabels) == 0 {
+		return nil, false
+	}
+
+	for _, label := range job.MetricLabelValues() {
+		attrs = append(attrs, attribute.String(label.Name, label.Value))
+	}
+	labelSet := attribute.NewSet(attrs...)
+	set := labelSet.Equivalent()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.sets[set]; ok {
+		return attrs, false
+	}
+
+	if len(l.sets) >= l.max {
+		capReached = !l.full
+		l.full = true
+		return nil, capReached
+	}
+
+	l.sets[set] = struct{}{}
+	return attrs, false
+}
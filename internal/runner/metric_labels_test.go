@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestMetricLabels(t *testing.T) {
+	labels := newMetricLabels(2)
+	labelledJob := func() *model.Job {
+		return &model.Job{ID: uuid.New(), MetricLabels: []string{model.MetricLabelJobID}}
+	}
+
+	// jobs that didn't opt in are not labelled
+	attrs, capReached := labels.attributes(&model.Job{ID: uuid.New()})
+	assert.Empty(t, attrs)
+	assert.False(t, capReached)
+
+	first, second, third := labelledJob(), labelledJob(), labelledJob()
+	attrs, _ = labels.attributes(first)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("job_id", first.ID.String())}, attrs)
+	attrs, _ = labels.attributes(second)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("job_id", second.ID.String())}, attrs)
+
+	// the third job is over the cap, which is only reported once
+	attrs, capReached = labels.attributes(third)
+	assert.Empty(t, attrs)
+	assert.True(t, capReached)
+	_, capReached = labels.attributes(third)
+	assert.False(t, capReached)
+
+	// the jobs labelled before the cap was reached keep their labels
+	attrs, _ = labels.attributes(first)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("job_id", first.ID.String())}, attrs)
+}
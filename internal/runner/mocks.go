@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -20,27 +21,146 @@ type mockJobService struct {
 	Jobs   []*model.Job
 	GetErr error
 	FinErr error
+	// Errors the jobs were finished with
+	ExecErrs []error
+	// Executions for which cancellation was requested
+	Cancelled map[int]bool
+	lastID    int
+	// Logs saved for the executions
+	Logs    []model.ExecutionLogs
+	Outputs []model.ExecutionOutput
+	Events  []model.ExecutionEvent
+	// Progress reported by the executions
+	Progress map[int][]model.ExecutionProgress
+	// Pending retries, and the errors the finished retries were finished with
+	Retries   []model.ExecutionRetry
+	RetryErrs map[int]error
+	// Wakeups and cancellations sent to the listening runner
+	Wakeups       chan model.JobWakeup
+	Cancellations chan model.JobCancellation
+	// Number of times the job locks were extended
+	LockExtensions int
+	// Jobs whose locks were released, and jobs interrupted while running
+	Released    []uuid.UUID
+	Interrupted []uuid.UUID
+	// Retries released without being executed
+	ReleasedRetries []int
+	// Time, due time, shard and job types the jobs were last fetched for
+	At       time.Time
+	DueBy    time.Time
+	Shard    model.JobShard
+	JobTypes []string
+	// Number of times jobs were fetched
+	Fetches int
 }
 
-func (m *mockJobService) GetJobsToRun(_ context.Context, _ time.Time, _ time.Time, _ string, _ uint) ([]*model.Job, error) {
+func (m *mockJobService) GetJobsToRun(_ context.Context, at time.Time, dueBy time.Time, _ time.Time, _ string, _ []string, jobTypes []string, _ []string, shard model.JobShard, _ uint) ([]*model.Job, error) {
 	m.Lock()
 	defer m.Unlock()
+	m.At = at
+	m.DueBy = dueBy
+	m.Shard = shard
+	m.JobTypes = jobTypes
+	m.Fetches++
 	if m.GetErr != nil {
 		return nil, m.GetErr
 	}
-	// return a copy of the jobs (so we can modify the slice)
-	jobs := make([]*model.Job, len(m.Jobs))
-	copy(jobs, m.Jobs)
+	// return a copy of the jobs of the requested types (so we can modify the slice)
+	jobs := make([]*model.Job, 0, len(m.Jobs))
+	for _, job := range m.Jobs {
+		if len(jobTypes) == 0 || slices.Contains(jobTypes, string(job.Type)) {
+			jobs = append(jobs, job)
+		}
+	}
 
 	return jobs, nil
 }
 
-func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job, _, _ time.Time, _ error) error {
+func (m *mockJobService) StartJobExecution(_ context.Context, _ *model.Job, _ time.Time) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.lastID++
+	return m.lastID, nil
+}
+
+func (m *mockJobService) ExtendJobLock(_ context.Context, _ uuid.UUID, _ string, _ time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+	m.LockExtensions++
+	return nil
+}
+
+func (m *mockJobService) ReleaseJobLock(_ context.Context, jobID uuid.UUID, _ string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Released = append(m.Released, jobID)
+	return nil
+}
+
+func (m *mockJobService) InterruptJobExecution(_ context.Context, job *model.Job, _ int, _ string, _, _ time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Interrupted = append(m.Interrupted, job.ID)
+	return nil
+}
+
+func (m *mockJobService) ReleaseJobExecutionRetry(_ context.Context, retry model.ExecutionRetry) error {
+	m.Lock()
+	defer m.Unlock()
+	m.ReleasedRetries = append(m.ReleasedRetries, retry.ExecutionID)
+	return nil
+}
+
+func (m *mockJobService) GetCancelledJobExecutions(_ context.Context, executionIDs []int) ([]int, error) {
+	m.Lock()
+	defer m.Unlock()
+	var cancelled []int
+	for _, id := range executionIDs {
+		if m.Cancelled[id] {
+			cancelled = append(cancelled, id)
+		}
+	}
+	return cancelled, nil
+}
+
+func (m *mockJobService) SaveJobExecutionLogs(_ context.Context, logs model.ExecutionLogs) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Logs = append(m.Logs, logs)
+	return nil
+}
+
+func (m *mockJobService) SaveJobExecutionOutput(_ context.Context, output model.ExecutionOutput) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Outputs = append(m.Outputs, output)
+	return nil
+}
+
+func (m *mockJobService) AddJobExecutionEvents(_ context.Context, events ...model.ExecutionEvent) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Events = append(m.Events, events...)
+	return nil
+}
+
+func (m *mockJobService) UpdateJobExecutionProgress(_ context.Context, executionID int, progress model.ExecutionProgress) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.Progress == nil {
+		m.Progress = map[int][]model.ExecutionProgress{}
+	}
+	m.Progress[executionID] = append(m.Progress[executionID], progress)
+	return nil
+}
+
+func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job, _ int, _, _ time.Time, execErr error) error {
 	m.Lock()
 	defer m.Unlock()
 	if m.FinErr != nil {
 		return m.FinErr
 	}
+	m.ExecErrs = append(m.ExecErrs, execErr)
 	for i, j := range m.Jobs {
 		if j.ID == job.ID {
 			m.Jobs = append(m.Jobs[:i], m.Jobs[i+1:]...)
@@ -50,6 +170,46 @@ func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job,
 	return nil
 }
 
+func (m *mockJobService) ClaimJobExecutionRetries(_ context.Context, _ time.Time, _ []string, limit uint) ([]model.ExecutionRetry, error) {
+	m.Lock()
+	defer m.Unlock()
+	claimed := m.Retries[:min(int(limit), len(m.Retries))]
+	m.Retries = m.Retries[len(claimed):]
+	return claimed, nil
+}
+
+func (m *mockJobService) FinishJobExecutionRetry(_ context.Context, retry model.ExecutionRetry, _ time.Time, execErr error) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.RetryErrs == nil {
+		m.RetryErrs = map[int]error{}
+	}
+	m.RetryErrs[retry.ExecutionID] = execErr
+	return nil
+}
+
+func (m *mockJobService) ListenJobWakeups(ctx context.Context, handler func(model.JobWakeup)) error {
+	for {
+		select {
+		case wakeup := <-m.Wakeups:
+			handler(wakeup)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *mockJobService) ListenJobCancellations(ctx context.Context, handler func(model.JobCancellation)) error {
+	for {
+		select {
+		case cancellation := <-m.Cancellations:
+			handler(cancellation)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 func createMockJobService(getErr, finErr error) *mockJobService {
 	return &mockJobService{
 		Jobs:   []*model.Job{{ID: uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3875800ed40")}, {ID: uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3275800ed40")}, {ID: uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3875800ed40")}},
@@ -58,24 +218,83 @@ func createMockJobService(getErr, finErr error) *mockJobService {
 	}
 }
 
+type mockInstanceService struct {
+	sync.Mutex
+	Heartbeats   []model.Instance
+	TTL          time.Duration
+	Deregistered []string
+	// Other instances in the registry
+	Instances []model.Instance
+}
+
+func (m *mockInstanceService) Heartbeat(_ context.Context, instance model.Instance, ttl time.Duration) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Heartbeats = append(m.Heartbeats, instance)
+	m.TTL = ttl
+	return nil
+}
+
+func (m *mockInstanceService) ListInstances(_ context.Context) ([]model.Instance, error) {
+	m.Lock()
+	defer m.Unlock()
+	instances := append([]model.Instance{}, m.Instances...)
+	if len(m.Heartbeats) > 0 {
+		instance := m.Heartbeats[len(m.Heartbeats)-1]
+		instance.Alive = true
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (m *mockInstanceService) Deregister(_ context.Context, id string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.Deregistered = append(m.Deregistered, id)
+	return nil
+}
+
 type mockJobExecutor struct {
 	err error
+	// block until the context is cancelled
+	block bool
+	// panic instead of returning
+	panic bool
+	// take this long to execute, unless the context is cancelled
+	duration time.Duration
 }
 
-func (m *mockJobExecutor) Execute(_ context.Context, _ *model.Job) error {
+func (m *mockJobExecutor) Execute(ctx context.Context, _ *model.Job) error {
+	if m.panic {
+		panic("executor bug")
+	}
+	if m.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if m.duration > 0 {
+		select {
+		case <-time.After(m.duration):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 	return m.err
 }
 
 type mockExecutorFactory struct {
 	executeErr error
 	factoryErr error
+	block      bool
+	panic      bool
+	duration   time.Duration
 }
 
 func (m *mockExecutorFactory) NewExecutor(_ *model.Job, _ ...executor.Option) (executor.Executor, error) {
 	if m.factoryErr != nil {
 		return nil, m.factoryErr
 	}
-	return &mockJobExecutor{err: m.executeErr}, nil
+	return &mockJobExecutor{err: m.executeErr, block: m.block, panic: m.panic, duration: m.duration}, nil
 }
 
 func createRunnerWithMockExecutor(interval time.Duration, maxConcurrentJobs int, getErr, finErr, factoryErr, execErr error) *Runner {
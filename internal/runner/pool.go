@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// defaultPoolName is the name of the pool shared by the job types without a dedicated pool.
+const defaultPoolName = "default"
+
+// workerPool limits the number of concurrent executions of the job types using it. The limit can be changed while
+// jobs are running: a lower limit only applies to the jobs started afterwards.
+type workerPool struct {
+	name string
+
+	mu    sync.Mutex
+	limit int
+	inUse int
+}
+
+func newWorkerPool(name string, size int) *workerPool {
+	return &workerPool{name: name, limit: size}
+}
+
+// tryAcquire takes a free slot of the pool without waiting, and returns false if the pool is full.
+func (p *workerPool) tryAcquire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.inUse >= p.limit {
+		return false
+	}
+
+	p.inUse++
+	return true
+}
+
+func (p *workerPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inUse--
+}
+
+// usage returns the number of slots in use and the number of slots of the pool.
+func (p *workerPool) usage() (inUse int, size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.inUse, p.limit
+}
+
+func (p *workerPool) resize(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.limit = size
+}
+
+// workerPools are the worker pools of the runner: a dedicated pool per job type with its own concurrency limit,
+// so slow job types can't starve the others, and a default pool shared by the other job types.
+type workerPools struct {
+	mu          sync.RWMutex
+	defaultPool *workerPool
+	pools       map[model.JobType]*workerPool
+}
+
+// newWorkerPools creates the pools of the runner. The limits are keyed by job type, case-insensitively since
+// configuration keys are lowercased.
+func newWorkerPools(maxConcurrentJobs int, maxConcurrentJobsPerType map[string]int) *workerPools {
+	pools := &workerPools{
+		defaultPool: newWorkerPool(defaultPoolName, maxConcurrentJobs),
+		pools:       make(map[model.JobType]*workerPool, len(maxConcurrentJobsPerType)),
+	}
+
+	for jobType, limit := range maxConcurrentJobsPerType {
+		jobType := model.JobType(strings.ToUpper(jobType))
+		pools.pools[jobType] = newWorkerPool(strings.ToLower(string(jobType)), limit)
+	}
+
+	return pools
+}
+
+// resize changes the limits of the pools without interrupting the running jobs. Job types losing their dedicated
+// pool move to the default pool, the jobs running in the dedicated pool release their slots there.
+func (p *workerPools) resize(maxConcurrentJobs int, maxConcurrentJobsPerType map[string]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.defaultPool.resize(maxConcurrentJobs)
+
+	pools := make(map[model.JobType]*workerPool, len(maxConcurrentJobsPerType))
+	for jobType, limit := range maxConcurrentJobsPerType {
+		jobType := model.JobType(strings.ToUpper(jobType))
+		if pool, ok := p.pools[jobType]; ok {
+			pool.resize(limit)
+			pools[jobType] = pool
+			continue
+		}
+		pools[jobType] = newWorkerPool(strings.ToLower(string(jobType)), limit)
+	}
+
+	p.pools = pools
+}
+
+// get returns the pool of the job type.
+func (p *workerPools) get(jobType model.JobType) *workerPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if pool, ok := p.pools[jobType]; ok {
+		return pool
+	}
+
+	return p.defaultPool
+}
+
+func (p *workerPools) all() []*workerPool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	all := []*workerPool{p.defaultPool}
+	for _, pool := range p.pools {
+		all = append(all, pool)
+	}
+
+	return all
+}
+
+// capacity returns the total number of slots of the pools.
+func (p *workerPools) capacity() int {
+	capacity := 0
+	for _, pool := range p.all() {
+		_, size := pool.usage()
+		capacity += size
+	}
+
+	return capacity
+}
+
+// load returns the number of slots in use in the pools.
+func (p *workerPools) load() int {
+	load := 0
+	for _, pool := range p.all() {
+		inUse, _ := pool.usage()
+		load += inUse
+	}
+
+	return load
+}
+
+// free returns the number of free slots in the pools.
+func (p *workerPools) free() int {
+	free := 0
+	for _, pool := range p.all() {
+		inUse, size := pool.usage()
+		free += max(size-inUse, 0)
+	}
+
+	return free
+}
+
+// runnableTypes returns the job types whose pool has free slots, or nil if all of them have,
+// so the jobs of saturated pools aren't fetched only to be handed over to other runners.
+func (p *workerPools) runnableTypes() []string {
+	runnable := []string{}
+	for _, jobType := range model.JobTypes {
+		if inUse, size := p.get(jobType).usage(); inUse < size {
+			runnable = append(runnable, string(jobType))
+		}
+	}
+
+	if len(runnable) == len(model.JobTypes) {
+		return nil
+	}
+
+	return runnable
+}
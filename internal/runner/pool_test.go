@@ -0,0 +1,62 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPools(t *testing.T) {
+	pools := newWorkerPools(2, map[string]int{"amqp": 1})
+
+	// Job types without a dedicated pool share the default pool
+	assert.Equal(t, "amqp", pools.get(model.JobTypeAMQP).name)
+	assert.Equal(t, defaultPoolName, pools.get(model.JobTypeHTTP).name)
+	assert.Equal(t, 3, pools.capacity())
+
+	// A full pool doesn't prevent the other pools from being used
+	assert.True(t, pools.get(model.JobTypeAMQP).tryAcquire())
+	assert.False(t, pools.get(model.JobTypeAMQP).tryAcquire())
+	assert.True(t, pools.get(model.JobTypeHTTP).tryAcquire())
+	assert.Equal(t, 2, pools.load())
+	assert.Equal(t, 1, pools.free())
+
+	pools.get(model.JobTypeAMQP).release()
+	assert.Equal(t, 2, pools.free())
+}
+
+func TestRunnableTypes(t *testing.T) {
+	pools := newWorkerPools(1, map[string]int{"amqp": 1})
+	assert.Nil(t, pools.runnableTypes())
+
+	// The jobs of a full pool aren't fetched
+	assert.True(t, pools.get(model.JobTypeHTTP).tryAcquire())
+	assert.Equal(t, []string{string(model.JobTypeAMQP)}, pools.runnableTypes())
+
+	assert.True(t, pools.get(model.JobTypeAMQP).tryAcquire())
+	assert.Empty(t, pools.runnableTypes())
+	assert.NotNil(t, pools.runnableTypes())
+}
+
+func TestResizeWorkerPools(t *testing.T) {
+	pools := newWorkerPools(2, map[string]int{"amqp": 1})
+	amqp := pools.get(model.JobTypeAMQP)
+	assert.True(t, amqp.tryAcquire())
+	assert.True(t, pools.get(model.JobTypeHTTP).tryAcquire())
+
+	// Running jobs keep their slots when the limits shrink
+	pools.resize(1, map[string]int{"amqp": 2})
+	assert.Equal(t, 3, pools.capacity())
+	assert.Equal(t, 2, pools.load())
+	assert.Equal(t, 1, pools.free())
+	assert.False(t, pools.get(model.JobTypeHTTP).tryAcquire())
+	assert.True(t, pools.get(model.JobTypeAMQP).tryAcquire())
+
+	// Job types losing their dedicated pool move to the default pool
+	pools.resize(3, nil)
+	assert.Equal(t, defaultPoolName, pools.get(model.JobTypeAMQP).name)
+	amqp.release()
+	assert.Equal(t, 3, pools.capacity())
+	assert.Equal(t, 2, pools.free())
+}
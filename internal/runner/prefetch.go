@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+)
+
+// prefetchQueue holds the jobs fetched ahead of their next run, ordered by next run, until they are due.
+// It is only used by the loop of the runner, so it isn't safe for concurrent use.
+type prefetchQueue struct {
+	jobs   prefetchHeap
+	queued map[uuid.UUID]bool
+}
+
+func newPrefetchQueue() *prefetchQueue {
+	return &prefetchQueue{queued: map[uuid.UUID]bool{}}
+}
+
+// push queues the job, unless it is already queued.
+func (q *prefetchQueue) push(job *model.Job) {
+	if q.queued[job.ID] {
+		return
+	}
+
+	q.queued[job.ID] = true
+	heap.Push(&q.jobs, job)
+}
+
+// popDue removes and returns the jobs due at the given time.
+func (q *prefetchQueue) popDue(at time.Time) []*model.Job {
+	var due []*model.Job
+	for q.jobs.Len() > 0 && !q.jobs[0].NextRun.Time.After(at) {
+		job := heap.Pop(&q.jobs).(*model.Job)
+		delete(q.queued, job.ID)
+		due = append(due, job)
+	}
+
+	return due
+}
+
+// popAll removes and returns all the queued jobs.
+func (q *prefetchQueue) popAll() []*model.Job {
+	all := []*model.Job(q.jobs)
+	q.jobs = nil
+	clear(q.queued)
+
+	return all
+}
+
+// next returns the next run of the earliest queued job, or false if the queue is empty.
+func (q *prefetchQueue) next() (time.Time, bool) {
+	if q.jobs.Len() == 0 {
+		return time.Time{}, false
+	}
+
+	return q.jobs[0].NextRun.Time, true
+}
+
+func (q *prefetchQueue) len() int {
+	return q.jobs.Len()
+}
+
+// prefetchHeap implements heap.Interface over the next run of the jobs.
+type prefetchHeap []*model.Job
+
+func (h prefetchHeap) Len() int { return len(h) }
+
+func (h prefetchHeap) Less(i, j int) bool { return h[i].NextRun.Time.Before(h[j].NextRun.Time) }
+
+func (h prefetchHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *prefetchHeap) Push(x any) { *h = append(*h, x.(*model.Job)) }
+
+func (h *prefetchHeap) Pop() any {
+	old := *h
+	job := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+
+	return job
+}
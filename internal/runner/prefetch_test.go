@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestPrefetchQueue(t *testing.T) {
+	now := time.Now()
+	later := &model.Job{ID: uuid.New(), NextRun: null.TimeFrom(now.Add(time.Minute))}
+	sooner := &model.Job{ID: uuid.New(), NextRun: null.TimeFrom(now.Add(time.Second))}
+
+	queue := newPrefetchQueue()
+	_, ok := queue.next()
+	assert.False(t, ok)
+
+	// Jobs are ordered by next run, and queued once
+	queue.push(later)
+	queue.push(sooner)
+	queue.push(later)
+	assert.Equal(t, 2, queue.len())
+
+	next, ok := queue.next()
+	assert.True(t, ok)
+	assert.Equal(t, sooner.NextRun.Time, next)
+
+	// Only the due jobs are popped
+	assert.Empty(t, queue.popDue(now))
+	assert.Equal(t, []*model.Job{sooner}, queue.popDue(now.Add(time.Second)))
+	assert.Equal(t, []*model.Job{later}, queue.popAll())
+	assert.Equal(t, 0, queue.len())
+
+	// A popped job can be queued again
+	queue.push(sooner)
+	assert.Equal(t, 1, queue.len())
+}
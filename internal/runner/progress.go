@@ -0,0 +1,93 @@
+package runner
+
+import (
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// progressReportInterval is the minimum time between two progress updates of an execution, so executors reporting
+// their progress in a tight loop don't flood the database.
+const progressReportInterval = time.Second
+
+// progressReporter records the progress reported by the executor of an execution. Reports within the report interval
+// of the previous update are coalesced, and the latest one is recorded when the execution finishes.
+type progressReporter struct {
+	runner      *Runner
+	executionID int
+	interval    time.Duration
+
+	mu         sync.Mutex
+	lastUpdate time.Time
+	pending    *model.ExecutionProgress
+	notRunning bool
+}
+
+func (s *Runner) newProgressReporter(executionID int) *progressReporter {
+	return &progressReporter{runner: s, executionID: executionID, interval: progressReportInterval}
+}
+
+// report is the executor.ProgressReporter of the execution. Only invalid progress is returned to the executor,
+// failing to record the progress doesn't affect the execution.
+func (p *progressReporter) report(percent int, message string) error {
+	progress := model.ExecutionProgress{
+		Percent:   null.IntFrom(int64(percent)),
+		Message:   null.NewString(message, message != ""),
+		UpdatedAt: time.Now(),
+	}
+	if err := progress.Validate(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	if p.notRunning {
+		p.mu.Unlock()
+		return nil
+	}
+
+	if progress.UpdatedAt.Sub(p.lastUpdate) < p.interval {
+		p.pending = &progress
+		p.mu.Unlock()
+		return nil
+	}
+
+	p.lastUpdate = progress.UpdatedAt
+	p.pending = nil
+	p.mu.Unlock()
+
+	p.update(progress)
+	return nil
+}
+
+// flush records the latest coalesced report, if any.
+func (p *progressReporter) flush() {
+	p.mu.Lock()
+	pending := p.pending
+	p.pending = nil
+	p.mu.Unlock()
+
+	if pending != nil {
+		p.update(*pending)
+	}
+}
+
+func (p *progressReporter) update(progress model.ExecutionProgress) {
+	ctx, cancel := p.runner.reportContext()
+	defer cancel()
+
+	err := p.runner.jobService.UpdateJobExecutionProgress(ctx, p.executionID, progress)
+	switch {
+	case stderrors.Is(err, errors.ErrExecutionNotRunning):
+		// the execution was cancelled or reaped, further reports are dropped
+		p.mu.Lock()
+		p.notRunning = true
+		p.mu.Unlock()
+	case err != nil:
+		p.runner.log.Warn("Failed to update job execution progress", zap.Int("executionID", p.executionID), zap.Error(err))
+	}
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressReporter(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Second, 1, nil, nil, nil, nil)
+	jobService := s.jobService.(*mockJobService)
+
+	progress := s.newProgressReporter(1)
+
+	// The first report is recorded right away, the following ones are coalesced
+	assert.NoError(t, progress.report(10, "Processing batch 1"))
+	assert.NoError(t, progress.report(20, "Processing batch 2"))
+	assert.NoError(t, progress.report(30, ""))
+	assert.ErrorIs(t, progress.report(101, ""), errs.ErrInvalidExecutionProgress)
+
+	jobService.Lock()
+	assert.Len(t, jobService.Progress[1], 1)
+	assert.EqualValues(t, 10, jobService.Progress[1][0].Percent.Int64)
+	assert.Equal(t, "Processing batch 1", jobService.Progress[1][0].Message.String)
+	jobService.Unlock()
+
+	// The latest coalesced report is recorded when the execution finishes
+	progress.flush()
+	progress.flush()
+
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Len(t, jobService.Progress[1], 2)
+	assert.EqualValues(t, 30, jobService.Progress[1][1].Percent.Int64)
+	assert.False(t, jobService.Progress[1][1].Message.Valid)
+}
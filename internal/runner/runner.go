@@ -2,14 +2,29 @@ package runner
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/logsink"
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	errors "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/resultsink"
+	"github.com/google/uuid"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -21,7 +36,9 @@ type Runner struct {
 
 	executorFactory executor.Factory
 	ticker          *time.Ticker
-	log             *otelzap.Logger
+	// poll interval in nanoseconds, which can be changed while the runner is running
+	interval atomic.Int64
+	log      *otelzap.Logger
 
 	// Add an instance ID to identify the runner
 	instanceId string
@@ -30,36 +47,127 @@ type Runner struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	// context of the executions, cancelled to interrupt them once the shutdown grace period elapsed
+	execCtx          context.Context
+	cancelExecutions context.CancelFunc
+	// how long the running executions are left to finish when the runner stops
+	shutdownGracePeriod time.Duration
+
 	// add a wait group to wait for all jobs to finish
 	wg sync.WaitGroup
 
 	// Add a wait group to wait for the runner to stop
 	stopWg sync.WaitGroup
 
-	// Add a semaphore to limit the number of concurrent jobs
-	jobSemaphore chan struct{}
+	// worker pools limiting the number of concurrent jobs per job type
+	pools *workerPools
 
 	// Add a sync.Once to ensure the runner only starts once
 	startOnce sync.Once
 
-	// limit the number of concurrent jobs
-	maxConcurrentJobs int
-
 	// job lock duration
 	jobLockDuration time.Duration
+
+	// maximum allowed delay between the scheduled and actual start time (0 disables the check)
+	maxDrift time.Duration
+
+	// how often running executions are checked for cancellation requests (0 disables the check), and whether
+	// cancellation requests are also listened for
+	cancellationPollInterval time.Duration
+	listenForCancellations   bool
+
+	// running executions, which are cancelled once their cancellation is requested
+	executionsMu sync.Mutex
+	executions   map[int]*runningExecution
+
+	// maximum size of the logs captured per execution in bytes (0 disables log capture)
+	maxExecutionLogSize int
+
+	// maximum size of the output captured per execution in bytes, before compression (0 disables output capture)
+	maxExecutionOutputSize int64
+
+	// sink the logs and outputs are shipped to (disabled if nil), and whether they are no longer stored in the database
+	logSink      logsink.Sink
+	skipLogStore bool
+
+	// publisher of the results of the executions of the jobs with a result sink (disabled if nil)
+	resultPublisher ResultPublisher
+
+	// namespaces whose jobs are executed (all namespaces if empty)
+	namespaces []string
+
+	// capabilities of the runner, it only executes the jobs whose required capabilities are among them
+	capabilities []string
+
+	// registration of the runner in the instance registry (disabled if the service is nil)
+	instanceService   InstanceService
+	instance          model.Instance
+	heartbeatInterval time.Duration
+
+	// share of the due jobs of the runner among the live runners, updated on each heartbeat
+	fairDistribution bool
+	shard            atomic.Pointer[model.JobShard]
+
+	// listen for wakeups of jobs due soon, in addition to polling
+	listenForWakeups bool
+	wakeups          chan time.Time
+
+	// how far ahead jobs are fetched, and the jobs fetched ahead waiting to be due
+	lookahead  time.Duration
+	prefetched *prefetchQueue
+
+	// middleware chains wrapping the executors, per job type and for the other job types
+	middleware        executor.Middleware
+	middlewarePerType map[model.JobType]executor.Middleware
+
+	// labels the jobs opted into adding to their execution metrics
+	metricLabels *metricLabels
 }
 
 type JobService interface {
-	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error)
-	FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error
+	GetJobsToRun(ctx context.Context, at time.Time, dueBy time.Time, lockedUntil time.Time, instanceID string, namespaces []string, jobTypes []string, capabilities []string, shard model.JobShard, limit uint) ([]*model.Job, error)
+	StartJobExecution(ctx context.Context, job *model.Job, startTime time.Time) (int, error)
+	ExtendJobLock(ctx context.Context, jobID uuid.UUID, instanceID string, lockedUntil time.Time) error
+	FinishJobExecution(ctx context.Context, job *model.Job, executionID int, startTime, stopTime time.Time, err error) error
+	InterruptJobExecution(ctx context.Context, job *model.Job, executionID int, instanceID string, startTime, stopTime time.Time) error
+	ReleaseJobLock(ctx context.Context, jobID uuid.UUID, instanceID string) error
+	GetCancelledJobExecutions(ctx context.Context, executionIDs []int) ([]int, error)
+	ListenJobCancellations(ctx context.Context, handler func(model.JobCancellation)) error
+	SaveJobExecutionLogs(ctx context.Context, logs model.ExecutionLogs) error
+	SaveJobExecutionOutput(ctx context.Context, output model.ExecutionOutput) error
+	AddJobExecutionEvents(ctx context.Context, events ...model.ExecutionEvent) error
+	UpdateJobExecutionProgress(ctx context.Context, executionID int, progress model.ExecutionProgress) error
+	ClaimJobExecutionRetries(ctx context.Context, at time.Time, namespaces []string, limit uint) ([]model.ExecutionRetry, error)
+	FinishJobExecutionRetry(ctx context.Context, retry model.ExecutionRetry, stopTime time.Time, err error) error
+	ReleaseJobExecutionRetry(ctx context.Context, retry model.ExecutionRetry) error
+	ListenJobWakeups(ctx context.Context, handler func(model.JobWakeup)) error
+}
+
+// InstanceService registers the runner in the instance registry.
+type InstanceService interface {
+	Heartbeat(ctx context.Context, instance model.Instance, ttl time.Duration) error
+	Deregister(ctx context.Context, id string) error
+	ListInstances(ctx context.Context) ([]model.Instance, error)
+}
+
+// ResultPublisher publishes the results of the executions to the result sinks of their jobs.
+type ResultPublisher interface {
+	Publish(ctx context.Context, sink model.JobResultSink, result model.ExecutionResult) error
 }
 
 type Config struct {
 	JobService      JobService
+	InstanceService InstanceService
 	Metrics         *metrics.RunnerMetrics
 	ExecutorFactory executor.Factory
+	// LogSink ships the logs and outputs of the executions, in addition to the database, disabled if nil
+	LogSink logsink.Sink
+	// ResultPublisher publishes the results of the executions of the jobs with a result sink, disabled if nil
+	ResultPublisher ResultPublisher
 	Log             *otelzap.Logger
 	InstanceId      string
+	// Version of the runner, reported in the instance registry
+	Version string
 
 	JobExecution JobExecutionSettings
 }
@@ -68,23 +176,144 @@ type JobExecutionSettings struct {
 	Interval          time.Duration `conf:"default:10s" mapstructure:"interval" json:"interval,omitempty"`
 	MaxConcurrentJobs int           `conf:"default:100" mapstructure:"maxConcurrentJobs" json:"maxConcurrentJobs,omitempty"`
 	MaxJobLockTime    time.Duration `conf:"default:1m" mapstructure:"maxJobLockTime" json:"maxJobLockTime,omitempty"`
+	// MaxConcurrentJobsPerType gives job types, e.g. AMQP, a dedicated worker pool with its own concurrency limit.
+	// Job types without a dedicated pool share a pool of MaxConcurrentJobs.
+	MaxConcurrentJobsPerType map[string]int `mapstructure:"maxConcurrentJobsPerType" json:"maxConcurrentJobsPerType,omitempty"`
+	// MaxDrift rejects executions that start later than the threshold after their scheduled time.
+	MaxDrift time.Duration `conf:"default:0s" mapstructure:"maxDrift" json:"maxDrift,omitempty"`
+	// CancellationPollInterval is how often running executions are checked for cancellation requests.
+	CancellationPollInterval time.Duration `conf:"default:5s" mapstructure:"cancellationPollInterval" json:"cancellationPollInterval,omitempty"`
+	// MaxExecutionLogSize is the maximum size of the logs captured per execution, in bytes.
+	MaxExecutionLogSize int `conf:"default:65536" mapstructure:"maxExecutionLogSize" json:"maxExecutionLogSize,omitempty"`
+	// MaxExecutionOutputSize is the maximum size of the output captured per execution, e.g. the HTTP response body,
+	// in bytes before compression.
+	MaxExecutionOutputSize int64 `conf:"default:1048576" mapstructure:"maxExecutionOutputSize" json:"maxExecutionOutputSize,omitempty"`
+	// Namespaces restricts the runner to the jobs of the namespaces. All namespaces are executed if empty.
+	Namespaces []string `mapstructure:"namespaces" json:"namespaces,omitempty"`
+	// Capabilities are labels describing the runner, e.g. region=eu or network=dmz. Jobs requiring capabilities
+	// are only executed by the runners having all of them.
+	Capabilities []string `mapstructure:"capabilities" json:"capabilities,omitempty"`
+	// HeartbeatInterval is how often the runner reports itself in the instance registry. The runner is
+	// considered dead after missing three heartbeats.
+	HeartbeatInterval time.Duration `conf:"default:10s" mapstructure:"heartbeatInterval" json:"heartbeatInterval,omitempty"`
+	// ShutdownGracePeriod is how long the running executions are left to finish when the runner stops, before
+	// they are interrupted and handed over to other runners. The runner stops fetching jobs right away.
+	ShutdownGracePeriod time.Duration `conf:"default:20s" mapstructure:"shutdownGracePeriod" json:"shutdownGracePeriod,omitempty"`
+	// ListenForCancellations makes the runner cancel executions as soon as their cancellation is requested,
+	// instead of on its next cancellation poll. Polling still cancels the executions if a notification is missed.
+	ListenForCancellations bool `conf:"default:true" mapstructure:"listenForCancellations" json:"listenForCancellations,omitempty"`
+	// ListenForWakeups makes the runner fetch jobs created or updated with a near-term next run as soon as
+	// they are due, instead of on its next poll. Polling still picks up the jobs if a wakeup is missed.
+	ListenForWakeups bool `conf:"default:true" mapstructure:"listenForWakeups" json:"listenForWakeups,omitempty"`
+	// FairDistribution shards the due jobs across the live runners registered in the instance registry, so
+	// each runner takes its share of the jobs. Jobs of other shards are taken once overdue by two intervals.
+	FairDistribution bool `conf:"default:true" mapstructure:"fairDistribution" json:"fairDistribution,omitempty"`
+	// Lookahead makes the runner also fetch the jobs due within the duration, and hold them until they are due
+	// so they start at their scheduled time instead of up to an interval late. Disabled if zero.
+	Lookahead time.Duration `conf:"default:0s" mapstructure:"lookahead" json:"lookahead,omitempty"`
+	// MaxLabelledJobs is the maximum number of distinct sets of metric labels the jobs opted into, e.g. job IDs,
+	// recorded by the runner. The executions of further jobs are recorded without their labels.
+	MaxLabelledJobs int `conf:"default:1000" mapstructure:"maxLabelledJobs" json:"maxLabelledJobs,omitempty"`
+	// Egress restricts the destinations of the HTTP jobs, e.g. denying the private and cloud metadata addresses.
+	Egress egress.Settings `mapstructure:"egress" json:"egress"`
+	// LogShipping configures the sinks the logs and outputs of the executions are shipped to, e.g. Loki.
+	LogShipping logsink.Settings `mapstructure:"logShipping" json:"logShipping"`
+	// Middleware configures the middleware chain wrapping the executors, e.g. retries and circuit breakers.
+	Middleware executor.MiddlewareSettings `mapstructure:"middleware" json:"middleware"`
+	// MiddlewarePerType replaces the middleware chain of job types, e.g. to give AMQP jobs a shorter timeout.
+	MiddlewarePerType map[string]executor.MiddlewareSettings `mapstructure:"middlewarePerType" json:"middlewarePerType,omitempty"`
+}
+
+// ValidateMiddleware reports unknown middlewares and invalid middleware parameters in the settings.
+func (s JobExecutionSettings) ValidateMiddleware() error {
+	if err := s.Middleware.Validate(); err != nil {
+		return err
+	}
+
+	for jobType, settings := range s.MiddlewarePerType {
+		if err := settings.Validate(); err != nil {
+			return fmt.Errorf("middleware of job type %s: %w", jobType, err)
+		}
+	}
+
+	return nil
 }
 
+// tracer traces the executions of the runner.
+var tracer = otel.Tracer("runner")
+
+// heartbeatsToLive is the number of heartbeats a runner can miss before it is considered dead.
+const heartbeatsToLive = 3
+
+// shardGraceIntervals is the number of poll intervals after which the overdue jobs of other shards can be taken.
+const shardGraceIntervals = 2
+
+// lockRenewalsPerLockTime is the number of times the lock of a running job is renewed per lock duration,
+// so a renewal can fail without the lock lapsing.
+const lockRenewalsPerLockTime = 3
+
+// reportTimeout is the time allowed to report the outcome of an execution, including while the runner stops.
+const reportTimeout = time.Second * 10
+
+// wakeupBufferSize is the number of wakeups waiting to be handled. Wakeups are dropped once the buffer
+// is full, leaving the jobs to the polling.
+const wakeupBufferSize = 64
+
 func New(cfg Config) *Runner {
 	ctx, cancel := context.WithCancel(context.Background())
+	execCtx, cancelExecutions := context.WithCancel(context.Background())
 
 	s := &Runner{
-		jobService:        cfg.JobService,
-		metrics:           cfg.Metrics,
-		instanceId:        cfg.InstanceId,
-		log:               cfg.Log,
-		ticker:            time.NewTicker(cfg.JobExecution.Interval),
-		ctx:               ctx,
-		executorFactory:   cfg.ExecutorFactory,
-		cancel:            cancel,
-		jobSemaphore:      make(chan struct{}, cfg.JobExecution.MaxConcurrentJobs),
-		maxConcurrentJobs: cfg.JobExecution.MaxConcurrentJobs,
-		jobLockDuration:   cfg.JobExecution.MaxJobLockTime,
+		jobService:      cfg.JobService,
+		metrics:         cfg.Metrics,
+		instanceId:      cfg.InstanceId,
+		log:             cfg.Log,
+		ticker:          time.NewTicker(cfg.JobExecution.Interval),
+		ctx:             ctx,
+		executorFactory: cfg.ExecutorFactory,
+		cancel:          cancel,
+
+		execCtx:             execCtx,
+		cancelExecutions:    cancelExecutions,
+		shutdownGracePeriod: cfg.JobExecution.ShutdownGracePeriod,
+
+		pools:           newWorkerPools(cfg.JobExecution.MaxConcurrentJobs, cfg.JobExecution.MaxConcurrentJobsPerType),
+		jobLockDuration: cfg.JobExecution.MaxJobLockTime,
+		maxDrift:        cfg.JobExecution.MaxDrift,
+
+		cancellationPollInterval: cfg.JobExecution.CancellationPollInterval,
+		listenForCancellations:   cfg.JobExecution.ListenForCancellations,
+		executions:               map[int]*runningExecution{},
+		maxExecutionLogSize:      cfg.JobExecution.MaxExecutionLogSize,
+		maxExecutionOutputSize:   cfg.JobExecution.MaxExecutionOutputSize,
+		logSink:                  cfg.LogSink,
+		skipLogStore:             cfg.LogSink != nil && cfg.JobExecution.LogShipping.SkipDatabase,
+		resultPublisher:          cfg.ResultPublisher,
+		namespaces:               cfg.JobExecution.Namespaces,
+		capabilities:             cfg.JobExecution.Capabilities,
+
+		instanceService:   cfg.InstanceService,
+		heartbeatInterval: cfg.JobExecution.HeartbeatInterval,
+		fairDistribution:  cfg.JobExecution.FairDistribution,
+
+		listenForWakeups: cfg.JobExecution.ListenForWakeups,
+		wakeups:          make(chan time.Time, wakeupBufferSize),
+
+		lookahead:  cfg.JobExecution.Lookahead,
+		prefetched: newPrefetchQueue(),
+
+		metricLabels: newMetricLabels(cfg.JobExecution.MaxLabelledJobs),
+	}
+
+	s.interval.Store(int64(cfg.JobExecution.Interval))
+	s.buildMiddleware(cfg.JobExecution)
+
+	hostname, _ := os.Hostname()
+	s.instance = model.Instance{
+		ID:         cfg.InstanceId,
+		Hostname:   hostname,
+		Version:    cfg.Version,
+		Namespaces: cfg.JobExecution.Namespaces,
 	}
 
 	s.stopWg.Add(1)
@@ -106,16 +335,69 @@ func (s *Runner) Start() {
 // in a separate goroutine.
 // It will run until the runner is stopped.
 func (s *Runner) start() {
+	s.instance.StartedAt = time.Now()
+	if s.instanceService != nil && s.heartbeatInterval > 0 {
+		s.stopWg.Add(1)
+		go s.heartbeat()
+	}
+
+	if s.listenForWakeups {
+		s.stopWg.Add(1)
+		go s.listenWakeups()
+	}
+
+	if s.cancellationPollInterval > 0 {
+		s.stopWg.Add(1)
+		go s.pollCancellations()
+	}
+
+	if s.listenForCancellations {
+		s.stopWg.Add(1)
+		go s.listenCancellations()
+	}
+
 	// Run the runner in a separate goroutine
 	go func() {
 		defer s.stopWg.Done() // Signal that the runner has stopped
 		defer s.ticker.Stop() // Stop the ticker
 
+		// Fires when the earliest job the runner was woken up for is due
+		wakeupTimer := time.NewTimer(0)
+		<-wakeupTimer.C
+		defer wakeupTimer.Stop()
+		var wakeAt time.Time
+
+		// Fires when the earliest job fetched ahead is due
+		prefetchTimer := time.NewTimer(0)
+		<-prefetchTimer.C
+		defer prefetchTimer.Stop()
+		resetPrefetchTimer := func() {
+			if next, ok := s.prefetched.next(); ok {
+				prefetchTimer.Reset(time.Until(next))
+			}
+		}
+
 		for {
 			select {
 			case <-s.ticker.C:
 				s.runJobs()
+				resetPrefetchTimer()
+			case at := <-s.wakeups:
+				// A run is already planned by then
+				if !wakeAt.IsZero() && (!at.Before(wakeAt) || !wakeAt.After(time.Now())) {
+					continue
+				}
+				wakeAt = at
+				wakeupTimer.Reset(time.Until(at))
+			case <-wakeupTimer.C:
+				wakeAt = time.Time{}
+				s.runJobs()
+				resetPrefetchTimer()
+			case <-prefetchTimer.C:
+				s.runPrefetchedJobs()
+				resetPrefetchTimer()
 			case <-s.ctx.Done():
+				s.releasePrefetchedJobs()
 				s.wg.Wait() // Wait for all jobs to finish
 				return
 			}
@@ -123,20 +405,47 @@ func (s *Runner) start() {
 	}()
 }
 
-// Stop is a method to stop the runner, with a context
-// to allow for a timeout. if the context has no deadline,
-// default to a 10-second timeout.
+// InterruptTimeout is how long the runner waits for the interrupted executions to be reported when it stops, after
+// the shutdown grace period. The deadline of a stopping runner is the sum of both.
+const InterruptTimeout = 10 * time.Second
+
+// Stop stops the runner, with a context to allow for a timeout. If the context has no deadline, it defaults to the
+// shutdown grace period and the interrupt timeout.
+// The runner stops fetching jobs and releases the jobs fetched but not started yet, so other runners pick them up
+// right away. Running executions are left to finish until the shutdown grace period elapsed or the context is done,
+// after which they are interrupted and recorded as cancelled.
 func (s *Runner) Stop(ctx context.Context) {
 	// check if context has a deadline, and if not, create one
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, time.Second*10)
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownGracePeriod+InterruptTimeout)
 		defer cancel()
 	}
 
-	// Cancel the runner context to stop the runner
+	// Cancel the runner context to stop fetching jobs
 	s.cancel()
 
+	// Let the running executions finish for the grace period
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		s.wg.Wait()
+	}()
+
+	grace := time.NewTimer(s.shutdownGracePeriod)
+	defer grace.Stop()
+
+	select {
+	case <-drained:
+	case <-grace.C:
+		s.log.Warn("Shutdown grace period elapsed, interrupting the running executions", zap.Int("executions", len(s.runningExecutionIDs())))
+	case <-ctx.Done():
+		s.log.Warn("Stop deadline reached, interrupting the running executions", zap.Int("executions", len(s.runningExecutionIDs())))
+	}
+
+	// Interrupt the remaining executions
+	s.cancelExecutions()
+
 	// Wait for the runner to stop, with a timeout
 	c := make(chan struct{})
 	go func() {
@@ -155,21 +464,222 @@ func (s *Runner) Stop(ctx context.Context) {
 	}
 }
 
+// Reconfigure applies the poll interval and the concurrency limits of the settings while the runner is running,
+// without interrupting the running jobs. The other settings only apply on restart.
+func (s *Runner) Reconfigure(settings JobExecutionSettings) {
+	if settings.Interval > 0 && settings.Interval != s.pollInterval() {
+		s.interval.Store(int64(settings.Interval))
+		s.ticker.Reset(settings.Interval)
+	}
+
+	s.pools.resize(settings.MaxConcurrentJobs, settings.MaxConcurrentJobsPerType)
+
+	s.log.Info("Reconfigured the runner",
+		zap.Duration("interval", s.pollInterval()),
+		zap.Int("maxConcurrentJobs", settings.MaxConcurrentJobs),
+		zap.Any("maxConcurrentJobsPerType", settings.MaxConcurrentJobsPerType),
+	)
+}
+
+func (s *Runner) pollInterval() time.Duration {
+	return time.Duration(s.interval.Load())
+}
+
+// heartbeat registers the runner in the instance registry and reports its load until the runner
+// is stopped. The runner is deregistered once its running jobs are finished.
+func (s *Runner) heartbeat() {
+	defer s.stopWg.Done()
+
+	ticker := time.NewTicker(s.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		instance := s.instance
+		instance.Capacity = s.pools.capacity()
+		instance.Load = s.pools.load()
+
+		ctx, cancel := context.WithTimeout(s.ctx, s.heartbeatInterval)
+		if err := s.instanceService.Heartbeat(ctx, instance, s.heartbeatInterval*heartbeatsToLive); err != nil {
+			s.log.Warn("Failed to send instance heartbeat", zap.Error(err))
+		} else if s.fairDistribution {
+			s.updateShard(ctx)
+		}
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			s.wg.Wait() // Wait for all jobs to finish
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			if err := s.instanceService.Deregister(ctx, s.instance.ID); err != nil {
+				s.log.Warn("Failed to deregister instance", zap.Error(err))
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// updateShard updates the share of the due jobs of the runner from the live runners in the instance registry.
+// The previous shard is kept if the registry can't be read.
+func (s *Runner) updateShard(ctx context.Context) {
+	instances, err := s.instanceService.ListInstances(ctx)
+	if err != nil {
+		s.log.Warn("Failed to list instances", zap.Error(err))
+		return
+	}
+
+	shard := model.NewJobShard(s.instanceId, instances, s.pollInterval()*shardGraceIntervals)
+	if previous := s.shard.Swap(&shard); previous == nil || previous.Index != shard.Index || previous.Count != shard.Count {
+		s.log.Info("Updated the shard of the runner", zap.Int("index", shard.Index), zap.Int("count", shard.Count))
+	}
+}
+
+// listenWakeups listens for wakeups of the jobs the runner can execute until the runner is stopped,
+// and plans a run for when the jobs are due. Listening is retried after the poll interval if it fails.
+func (s *Runner) listenWakeups() {
+	defer s.stopWg.Done()
+
+	for {
+		err := s.jobService.ListenJobWakeups(s.ctx, func(wakeup model.JobWakeup) {
+			if len(s.namespaces) > 0 && !slices.Contains(s.namespaces, wakeup.Namespace) {
+				return
+			}
+
+			if !model.HasCapabilities(s.capabilities, wakeup.RequiredCapabilities) {
+				return
+			}
+
+			select {
+			case s.wakeups <- wakeup.NextRun:
+			default:
+				s.log.Debug("Dropping job wakeup", zap.Any("jobID", wakeup.JobID))
+			}
+		})
+
+		if s.ctx.Err() != nil {
+			return
+		}
+		s.log.Warn("Stopped listening for job wakeups, retrying", zap.Error(err))
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.pollInterval()):
+		}
+	}
+}
+
 func (s *Runner) runJobs() {
+	// The runner may be stopped while a tick or wakeup is pending, don't fetch jobs to release them right away
+	if s.ctx.Err() != nil {
+		return
+	}
+
 	// Get the current time
 	now := time.Now()
 
 	ctx, cancel := context.WithTimeout(s.ctx, time.Second*10)
 	defer cancel()
 
+	// Only fetch as many jobs as there are free slots, minus the ones taken by the jobs fetched ahead, and only
+	// the jobs of the types whose pool has free slots. A saturated runner doesn't lock jobs it can't run, and
+	// leaves them to the other runners.
+	free := s.pools.free() - s.prefetched.len()
+	jobTypes := s.pools.runnableTypes()
+
+	attr := attribute.String("instance", s.instanceId)
+	if capacity := s.pools.capacity(); capacity > 0 {
+		s.metrics.RecordSaturation(ctx, float64(capacity-free)/float64(capacity), attr)
+	}
+
+	if free <= 0 || (jobTypes != nil && len(jobTypes) == 0) {
+		s.log.Debug("Runner is saturated, skipping the fetch", zap.Int("free", free))
+		s.metrics.IncreaseSkippedFetchCount(ctx, attr)
+		return
+	}
+
 	// Get the jobs that should be run
-	jobs, err := s.jobService.GetJobsToRun(ctx, now, now.Add(s.jobLockDuration), s.instanceId, uint(s.maxConcurrentJobs))
+	shard := model.JobShard{}
+	if current := s.shard.Load(); current != nil {
+		shard = *current
+	}
+
+	// Jobs fetched ahead stay locked until they are due, and for the lock duration once they are
+	fetchStart := time.Now()
+	jobs, err := s.jobService.GetJobsToRun(ctx, now, now.Add(s.lookahead), now.Add(s.lookahead+s.jobLockDuration), s.instanceId, s.namespaces, jobTypes, s.capabilities, shard, uint(free))
+	s.metrics.RecordFetch(ctx, time.Since(fetchStart).Seconds(), len(jobs), attr, statusAttribute(err))
 	if err != nil {
 		// Log the error and return
 		s.log.Error("Failed to get jobs to run", zap.Error(err))
 		return
 	}
 
+	// Jobs still locked by another instance were left behind by a runner that died or lost its lock
+	for _, j := range jobs {
+		if j.ReclaimedFrom != "" {
+			s.log.Debug("Reclaimed job with an expired lock", zap.Any("jobID", j.ID), zap.String("lockedBy", j.ReclaimedFrom))
+			s.metrics.IncreaseLockReclaimCount(ctx, attr, attribute.Bool("same_instance", j.ReclaimedFrom == s.instanceId))
+		}
+	}
+
+	// Hold the jobs that aren't due yet until their next run
+	due := make([]*model.Job, 0, len(jobs))
+	for _, j := range jobs {
+		if j.NextRun.Valid && j.NextRun.Time.After(now) {
+			s.prefetched.push(j)
+			continue
+		}
+		due = append(due, j)
+	}
+
+	s.dispatchJobs(ctx, due)
+
+	// Run the retries of past executions with the remaining capacity
+	free = s.pools.free() - s.prefetched.len()
+	if free <= 0 {
+		return
+	}
+
+	retries, err := s.jobService.ClaimJobExecutionRetries(ctx, now, s.namespaces, uint(free))
+	if err != nil {
+		s.log.Error("Failed to get job execution retries to run", zap.Error(err))
+		return
+	}
+
+	for _, retry := range retries {
+		s.executeRetry(retry)
+	}
+}
+
+// runPrefetchedJobs runs the jobs fetched ahead that are now due.
+func (s *Runner) runPrefetchedJobs() {
+	jobs := s.prefetched.popDue(time.Now())
+	if len(jobs) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, time.Second*10)
+	defer cancel()
+
+	s.dispatchJobs(ctx, jobs)
+}
+
+// releasePrefetchedJobs releases the jobs fetched ahead when the runner stops, so other runners pick them up.
+func (s *Runner) releasePrefetchedJobs() {
+	ctx, cancel := s.reportContext()
+	defer cancel()
+
+	for _, job := range s.prefetched.popAll() {
+		if err := s.jobService.ReleaseJobLock(ctx, job.ID, s.instanceId); err != nil {
+			s.log.Error("Failed to release the job lock", zap.Any("jobID", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// dispatchJobs starts the execution of the due jobs.
+func (s *Runner) dispatchJobs(ctx context.Context, jobs []*model.Job) {
 	numJobs := len(jobs)
 	attr := attribute.String("instance", s.instanceId)
 
@@ -178,6 +688,12 @@ func (s *Runner) runJobs() {
 
 	s.log.Debug("Running jobs", zap.Int("count", len(jobs)))
 
+	// The jobs are fetched fairly across priorities, start the highest priorities first so they get the
+	// slots of the pools that are full
+	slices.SortStableFunc(jobs, func(a, b *model.Job) int {
+		return b.Priority.Weight() - a.Priority.Weight()
+	})
+
 	// Run each job
 	for _, j := range jobs {
 		s.executeJob(j)
@@ -188,18 +704,27 @@ func (s *Runner) runJobs() {
 }
 
 func (s *Runner) executeJob(job *model.Job) {
+	// Acquire a slot in the pool of the job, or hand the job over to other runners
+	pool := s.pools.get(job.Type)
+	if !s.acquireSlot(pool) {
+		ctx, cancel := s.reportContext()
+		defer cancel()
 
-	s.jobSemaphore <- struct{}{} // Acquire a slot in the semaphore
-	s.wg.Add(1)                  // Increment the wait group counter
+		if err := s.jobService.ReleaseJobLock(ctx, job.ID, s.instanceId); err != nil {
+			s.log.Error("Failed to release the job lock", zap.Any("jobID", job.ID), zap.Error(err))
+		}
+		return
+	}
+	s.wg.Add(1) // Increment the wait group counter
 
 	go func() {
-		defer s.wg.Done()                   // Decrement the wait group counter
-		defer func() { <-s.jobSemaphore }() // Release the semaphore slot
+		defer s.wg.Done()         // Decrement the wait group counter
+		defer s.releaseSlot(pool) // Release the pool slot
 
 		s.log.Debug("Executing job", zap.Any("jobID", job.ID))
 
-		// Create a new executor for the job with retry enabled
-		jobExecutor, err := s.executorFactory.NewExecutor(job, executor.WithRetry)
+		// Create a new executor for the job wrapped in the middleware chain of its type
+		jobExecutor, err := s.executorFactory.NewExecutor(job, s.middlewareOf(job.Type))
 		if err != nil {
 			s.log.Error("Failed to create job executor", zap.Any("jobID", job.ID), zap.Error(err))
 			return
@@ -207,33 +732,419 @@ func (s *Runner) executeJob(job *model.Job) {
 
 		startTime := time.Now()
 
-		// Execute the job
-		err = jobExecutor.Execute(s.ctx, job)
+		attrs := []attribute.KeyValue{
+			attribute.String("job_type", string(job.Type)),
+			attribute.String("namespace", job.Namespace),
+			attribute.String("instance", s.instanceId),
+		}
+
+		// Record the delay between the scheduled and the actual start time
+		if job.NextRun.Valid {
+			drift := startTime.Sub(job.NextRun.Time)
+			s.metrics.RecordJobDrift(s.execCtx, drift.Seconds(), attrs...)
 
+			// Reject stale executions, but still report them so the job gets rescheduled
+			if s.maxDrift > 0 && drift > s.maxDrift {
+				s.log.Warn("Skipping stale job execution", zap.Any("jobID", job.ID), zap.Duration("drift", drift))
+				s.metrics.IncreaseStaleJobCount(s.execCtx, attrs...)
+
+				err = s.finishJobExecution(s.execCtx, job, 0, startTime, startTime, errors.ErrStaleExecution)
+				if err != nil {
+					s.log.Error("Failed to report job as finished", zap.Any("jobID", job.ID), zap.Error(err))
+				}
+				return
+			}
+		}
+
+		// Record the start of the execution, so it can be cancelled while running
+		executionID, err := s.jobService.StartJobExecution(s.execCtx, job, startTime)
+		if err != nil {
+			s.log.Error("Failed to record the start of the job execution", zap.Any("jobID", job.ID), zap.Error(err))
+		}
+
+		// Keep the job locked while it is running, so it isn't executed twice if it outlasts the lock
+		stopRenewing := s.renewLock(job)
+		capture, execErr := s.execute(jobExecutor, job, executionID, startTime, attrs)
+		stopRenewing()
 		stopTime := time.Now()
 
+		reportCtx, cancel := s.reportContext()
+		defer cancel()
+
+		interrupted := stderrors.Is(execErr, errors.ErrExecutionInterrupted)
+		if interrupted {
+			// Record the partial execution and hand the job over to other runners
+			err = s.jobService.InterruptJobExecution(reportCtx, job, executionID, s.instanceId, startTime, stopTime)
+			if err != nil {
+				s.log.Error("Failed to report job as interrupted", zap.Any("jobID", job.ID), zap.Error(err))
+			}
+		} else {
+			// Report the job as finished
+			err = s.finishJobExecution(reportCtx, job, executionID, startTime, stopTime, execErr)
+			if err != nil {
+				s.log.Error("Failed to report job as finished", zap.Any("jobID", job.ID), zap.Error(err))
+			}
+		}
+
+		output := s.saveExecutionCapture(reportCtx, job, executionID, capture)
+
+		// Interrupted executions didn't finish, the job runs again on another runner
+		if !interrupted {
+			s.publishResult(reportCtx, job, executionID, startTime, stopTime, execErr, output)
+		}
+
+		s.log.Debug("Job finished", zap.Any("jobID", job.ID))
+	}()
+}
+
+// executeRetry runs a retry of a past execution. Unlike scheduled executions, retries are already
+// recorded as started and don't reschedule the job once finished.
+func (s *Runner) executeRetry(retry model.ExecutionRetry) {
+	// Acquire a slot in the pool of the job, or hand the retry over to other runners
+	pool := s.pools.get(retry.Job.Type)
+	if !s.acquireSlot(pool) {
+		ctx, cancel := s.reportContext()
+		defer cancel()
+
+		if err := s.jobService.ReleaseJobExecutionRetry(ctx, retry); err != nil {
+			s.log.Error("Failed to release the job execution retry", zap.Any("jobID", retry.Job.ID), zap.Error(err))
+		}
+		return
+	}
+	s.wg.Add(1) // Increment the wait group counter
+
+	go func() {
+		defer s.wg.Done()         // Decrement the wait group counter
+		defer s.releaseSlot(pool) // Release the pool slot
+
+		job := retry.Job
+		s.log.Debug("Executing job execution retry", zap.Any("jobID", job.ID), zap.Int("executionID", retry.ExecutionID))
+
+		startTime := time.Now()
+
+		jobExecutor, err := s.executorFactory.NewExecutor(job, s.middlewareOf(job.Type))
+		if err != nil {
+			s.log.Error("Failed to create job executor", zap.Any("jobID", job.ID), zap.Error(err))
+
+			// Fail the retry rather than leaving it running
+			if err := s.jobService.FinishJobExecutionRetry(s.execCtx, retry, startTime, err); err != nil {
+				s.log.Error("Failed to report job execution retry as finished", zap.Any("jobID", job.ID), zap.Error(err))
+			}
+			return
+		}
+
 		attrs := []attribute.KeyValue{
 			attribute.String("job_type", string(job.Type)),
+			attribute.String("namespace", job.Namespace),
 			attribute.String("instance", s.instanceId),
 		}
-		// Record the job duration
-		s.metrics.RecordJobDuration(
-			s.ctx,
-			time.Since(startTime).Seconds(),
-			attrs...,
-		)
 
-		// Increment the job retries metric if the job failed
-		if err != nil {
-			s.metrics.IncreaseFailedJobCount(s.ctx, attrs...)
+		capture, err := s.execute(jobExecutor, job, retry.ExecutionID, startTime, attrs)
+		stopTime := time.Now()
+
+		reportCtx, cancel := s.reportContext()
+		defer cancel()
+
+		// Interrupted retries are recorded as cancelled
+		if err := s.jobService.FinishJobExecutionRetry(reportCtx, retry, stopTime, err); err != nil {
+			s.log.Error("Failed to report job execution retry as finished", zap.Any("jobID", job.ID), zap.Error(err))
 		}
 
-		// Report the job as finished
-		err = s.jobService.FinishJobExecution(s.ctx, job, startTime, stopTime, err)
+		output := s.saveExecutionCapture(reportCtx, job, retry.ExecutionID, capture)
+		s.publishResult(reportCtx, job, retry.ExecutionID, startTime, stopTime, err, output)
+
+		s.log.Debug("Job execution retry finished", zap.Any("jobID", job.ID), zap.Int("executionID", retry.ExecutionID))
+	}()
+}
+
+// buildMiddleware builds the middleware chains of the runner. Invalid chains fall back to retrying the executions,
+// as the settings are validated on startup.
+func (s *Runner) buildMiddleware(settings JobExecutionSettings) {
+	build := func(middlewareSettings executor.MiddlewareSettings) executor.Middleware {
+		middleware, err := middlewareSettings.Build(s.metrics)
 		if err != nil {
-			s.log.Error("Failed to report job as finished", zap.Any("jobID", job.ID), zap.Error(err))
+			s.log.Error("Invalid executor middleware, falling back to retries", zap.Error(err))
+			return executor.WithRetry
 		}
+		return middleware
+	}
 
-		s.log.Debug("Job finished", zap.Any("jobID", job.ID))
+	s.middleware = build(settings.Middleware)
+	s.middlewarePerType = make(map[model.JobType]executor.Middleware, len(settings.MiddlewarePerType))
+	for jobType, middlewareSettings := range settings.MiddlewarePerType {
+		s.middlewarePerType[model.JobType(strings.ToUpper(jobType))] = build(middlewareSettings)
+	}
+}
+
+// middlewareOf returns the middleware chain wrapping the executors of the job type.
+func (s *Runner) middlewareOf(jobType model.JobType) executor.Middleware {
+	if middleware, ok := s.middlewarePerType[jobType]; ok {
+		return middleware
+	}
+
+	return s.middleware
+}
+
+// finishJobExecution reports the execution as finished, recording how long the report took, as it contends with the
+// fetches of the runners for the lock of the job.
+func (s *Runner) finishJobExecution(ctx context.Context, job *model.Job, executionID int, startTime, stopTime time.Time, execErr error) error {
+	reportStart := time.Now()
+	err := s.jobService.FinishJobExecution(ctx, job, executionID, startTime, stopTime, execErr)
+	s.metrics.RecordFinish(ctx, time.Since(reportStart).Seconds(),
+		attribute.String("instance", s.instanceId),
+		statusAttribute(err),
+	)
+
+	return err
+}
+
+// statusAttribute is the status attribute of the metrics of a call to the job service.
+func statusAttribute(err error) attribute.KeyValue {
+	if err != nil {
+		return attribute.String("status", "error")
+	}
+
+	return attribute.String("status", "ok")
+}
+
+// executionCapture is what was captured during an execution, saved once the execution finished.
+type executionCapture struct {
+	log      *executor.ExecutionLog
+	output   *executor.ExecutionOutput
+	timeline *executor.ExecutionTimeline
+}
+
+// execute runs the job while watching for cancellation requests and capturing its logs, output and timeline, and
+// records its metrics.
+func (s *Runner) execute(jobExecutor executor.Executor, job *model.Job, executionID int, startTime time.Time, attrs []attribute.KeyValue) (*executionCapture, error) {
+	execCtx, stopWatching := s.watchCancellation(job, executionID)
+	capture := &executionCapture{}
+
+	// Capture the logs written by the executor
+	if executionID != 0 && s.maxExecutionLogSize > 0 {
+		var logger *zap.Logger
+		capture.log, logger = executor.NewExecutionLog(s.maxExecutionLogSize)
+		execCtx = executor.WithLogger(execCtx, logger)
+	}
+
+	// Capture the output written by the executor
+	if executionID != 0 && s.maxExecutionOutputSize > 0 {
+		capture.output = executor.NewExecutionOutput(s.maxExecutionOutputSize)
+		execCtx = executor.WithOutput(execCtx, capture.output)
+	}
+
+	// Record the start of the executor and its attempts
+	if executionID != 0 {
+		capture.timeline = executor.NewExecutionTimeline(s.instanceId)
+		execCtx = executor.WithTimeline(execCtx, capture.timeline)
+	}
+
+	// Record the progress reported by the executor
+	var progress *progressReporter
+	if executionID != 0 {
+		progress = s.newProgressReporter(executionID)
+		execCtx = executor.WithProgressReporter(execCtx, progress.report)
+	}
+
+	// Abort the execution once the execution timeout of the job elapsed
+	if job.ExecutionTimeout.Valid {
+		var cancelTimeout context.CancelFunc
+		execCtx, cancelTimeout = context.WithTimeout(execCtx, time.Duration(job.ExecutionTimeout.Int64)*time.Second)
+		defer cancelTimeout()
+	}
+
+	// Trace the execution, the executors propagate the span to the job targets
+	execCtx, span := tracer.Start(execCtx, "execute job", trace.WithAttributes(append(attrs,
+		attribute.String("job_id", job.ID.String()),
+		attribute.Int("execution_id", executionID),
+	)...))
+	defer span.End()
+
+	executor.RecordEvent(execCtx, model.ExecutionEventStarted, 0, nil)
+	err := s.safeExecute(execCtx, jobExecutor, job, executionID)
+	if progress != nil {
+		progress.flush()
+	}
+
+	if stopWatching() {
+		s.log.Info("Job execution cancelled", zap.Any("jobID", job.ID), zap.Int("executionID", executionID))
+		err = errors.ErrExecutionCancelled
+	} else if err != nil && s.execCtx.Err() != nil {
+		s.log.Info("Job execution interrupted", zap.Any("jobID", job.ID), zap.Int("executionID", executionID))
+		err = errors.ErrExecutionInterrupted
+	} else if err != nil && stderrors.Is(execCtx.Err(), context.DeadlineExceeded) {
+		s.log.Info("Job execution timed out", zap.Any("jobID", job.ID), zap.Int("executionID", executionID))
+		err = errors.ErrExecutionTimedOut
+	}
+
+	// Label the duration and failures with the labels the job opted into
+	labels, capReached := s.metricLabels.attributes(job)
+	if capReached {
+		s.log.Warn("Maximum number of labelled jobs reached, further jobs are recorded without their metric labels",
+			zap.Any("jobID", job.ID))
+	}
+	jobAttrs := append(slices.Clip(attrs), labels...)
+
+	// Record the job duration
+	s.metrics.RecordJobDuration(
+		s.execCtx,
+		time.Since(startTime).Seconds(),
+		jobAttrs...,
+	)
+
+	// Increment the job retries metric if the job failed
+	if err != nil {
+		s.metrics.IncreaseFailedJobCount(s.execCtx, jobAttrs...)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return capture, err
+}
+
+// safeExecute runs the executor, turning a panic into an execution error holding the stack trace, so a single
+// misbehaving executor fails its execution instead of crashing the runner.
+func (s *Runner) safeExecute(ctx context.Context, jobExecutor executor.Executor, job *model.Job, executionID int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			s.log.Error("Job executor panicked", zap.Any("jobID", job.ID), zap.Int("executionID", executionID), zap.Any("panic", r), zap.ByteString("stack", stack))
+			err = fmt.Errorf("%w: %v\n%s", errors.ErrExecutorPanicked, r, stack)
+		}
+	}()
+
+	return jobExecutor.Execute(ctx, job)
+}
+
+// saveExecutionCapture saves what was captured during the execution, and ships the logs and output to the log sink.
+// Failing to save it doesn't affect the outcome of the execution, which is already recorded. It returns the output
+// of the execution, nil if it has none.
+func (s *Runner) saveExecutionCapture(ctx context.Context, job *model.Job, executionID int, capture *executionCapture) *model.ExecutionOutput {
+	shipped := logsink.Execution{
+		JobID:       job.ID,
+		JobType:     job.Type,
+		Namespace:   job.Namespace,
+		ExecutionID: executionID,
+		Instance:    s.instanceId,
+	}
+
+	if capture.log != nil {
+		logs := capture.log.Logs(executionID)
+		shipped.Logs = &logs
+
+		if !s.skipLogStore {
+			if err := s.jobService.SaveJobExecutionLogs(ctx, logs); err != nil {
+				s.log.Error("Failed to save job execution logs", zap.Any("jobID", job.ID), zap.Error(err))
+			}
+		}
+	}
+
+	if capture.output != nil {
+		if output, ok := capture.output.Output(executionID); ok {
+			shipped.Output = &output
+
+			if !s.skipLogStore {
+				if err := s.jobService.SaveJobExecutionOutput(ctx, output); err != nil {
+					s.log.Error("Failed to save job execution output", zap.Any("jobID", job.ID), zap.Error(err))
+				}
+			}
+		}
+	}
+
+	if s.logSink != nil && (shipped.Logs != nil || shipped.Output != nil) {
+		if err := s.logSink.Ship(ctx, shipped); err != nil {
+			s.log.Error("Failed to ship job execution logs", zap.Any("jobID", job.ID), zap.Error(err))
+		}
+	}
+
+	if capture.timeline != nil {
+		if err := s.jobService.AddJobExecutionEvents(ctx, capture.timeline.Events(executionID)...); err != nil {
+			s.log.Error("Failed to save job execution timeline", zap.Any("jobID", job.ID), zap.Error(err))
+		}
+	}
+
+	return shipped.Output
+}
+
+// publishResult publishes the result of the execution to the result sink of the job, if it has one. Like the
+// capture, failing to publish it doesn't affect the outcome of the execution.
+func (s *Runner) publishResult(ctx context.Context, job *model.Job, executionID int, startTime, stopTime time.Time, execErr error, output *model.ExecutionOutput) {
+	if s.resultPublisher == nil || job.ResultSink == nil || executionID == 0 {
+		return
+	}
+
+	result := resultsink.NewResult(job, executionID, startTime, stopTime, execErr, output)
+	if err := s.resultPublisher.Publish(ctx, *job.ResultSink, result); err != nil {
+		s.log.Error("Failed to publish job execution result", zap.Any("jobID", job.ID), zap.Int("executionID", executionID), zap.Error(err))
+	}
+}
+
+// acquireSlot takes a free slot of the pool without waiting, so jobs of a full pool don't hold up the jobs of the
+// other pools. It returns false if the pool is full or the runner is stopping.
+func (s *Runner) acquireSlot(pool *workerPool) bool {
+	if s.ctx.Err() != nil {
+		return false
+	}
+
+	attr := attribute.String("pool", pool.name)
+	if !pool.tryAcquire() {
+		s.log.Debug("Worker pool is full", zap.String("pool", pool.name))
+		s.metrics.IncreasePoolRejectedJobCount(s.ctx, attr)
+		return false
+	}
+
+	s.metrics.AddPoolJobsInExecution(s.ctx, 1, attr)
+	return true
+}
+
+func (s *Runner) releaseSlot(pool *workerPool) {
+	pool.release()
+	s.metrics.AddPoolJobsInExecution(context.WithoutCancel(s.ctx), -1, attribute.String("pool", pool.name))
+}
+
+// reportContext returns the context the outcome of executions is reported with. Unlike the runner context,
+// it isn't cancelled when the runner stops, so executions interrupted by the runner stopping are still reported.
+func (s *Runner) reportContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(s.ctx), reportTimeout)
+}
+
+// renewLock periodically extends the lock of the job while it is executed, until the returned function is called.
+// If the runner dies, the renewals stop and the lock lapses after the lock duration, as if it wasn't renewed.
+func (s *Runner) renewLock(job *model.Job) func() {
+	if s.jobLockDuration <= 0 {
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(s.jobLockDuration / lockRenewalsPerLockTime)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				err := s.jobService.ExtendJobLock(s.execCtx, job.ID, s.instanceId, time.Now().Add(s.jobLockDuration))
+				if stderrors.Is(err, errors.ErrJobLockLost) {
+					s.log.Warn("Lost the lock of the running job", zap.Any("jobID", job.ID))
+					return
+				}
+				if err != nil {
+					s.log.Warn("Failed to extend the lock of the running job", zap.Any("jobID", job.ID), zap.Error(err))
+				}
+			case <-stop:
+				return
+			case <-s.execCtx.Done():
+				return
+			}
+		}
 	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
 }
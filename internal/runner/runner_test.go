@@ -7,7 +7,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/logsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
 )
 
 func TestNew(t *testing.T) {
@@ -33,8 +39,8 @@ func TestNew(t *testing.T) {
 	if s.instanceId == "" {
 		t.Error("Expected instanceId to be initialized, but got empty")
 	}
-	if s.jobSemaphore == nil {
-		t.Error("Expected jobSemaphore to be initialized, but got nil")
+	if s.pools == nil {
+		t.Error("Expected pools to be initialized, but got nil")
 	}
 }
 
@@ -77,6 +83,183 @@ func TestStop(t *testing.T) {
 	}
 }
 
+func TestStopReleasesJobs(t *testing.T) {
+	// A single slot, so only the first job is started and the others wait for it
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 1, nil, nil, nil, nil)
+	s.executorFactory.(*mockExecutorFactory).block = true
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to start the first job
+	time.Sleep(time.Millisecond * 100)
+
+	s.Stop(context.Background())
+
+	jobService.Lock()
+	defer jobService.Unlock()
+
+	// The running job is interrupted and the waiting ones are released, none of them is finished
+	assert.Equal(t, []uuid.UUID{jobService.Jobs[0].ID}, jobService.Interrupted)
+	assert.Equal(t, []uuid.UUID{jobService.Jobs[1].ID, jobService.Jobs[2].ID}, jobService.Released)
+	assert.Empty(t, jobService.ExecErrs)
+}
+
+func TestStopDrainsExecutions(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.shutdownGracePeriod = time.Second
+	s.executorFactory.(*mockExecutorFactory).duration = time.Millisecond * 200
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to start the jobs
+	time.Sleep(time.Millisecond * 100)
+
+	s.Stop(context.Background())
+
+	// The running jobs are left to finish within the grace period
+	assertJobsProcessed(t, jobService)
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Empty(t, jobService.Interrupted)
+	for _, err := range jobService.ExecErrs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestStopInterruptsAfterGracePeriod(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 1, nil, nil, nil, nil)
+	s.shutdownGracePeriod = time.Millisecond * 100
+	s.executorFactory.(*mockExecutorFactory).block = true
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to start the first job
+	time.Sleep(time.Millisecond * 100)
+
+	start := time.Now()
+	s.Stop(context.Background())
+
+	// The running job is interrupted once the grace period elapsed
+	assert.GreaterOrEqual(t, time.Since(start), s.shutdownGracePeriod)
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Equal(t, []uuid.UUID{jobService.Jobs[0].ID}, jobService.Interrupted)
+}
+
+func TestWorkerPoolsIsolation(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*100, 2, nil, nil, nil, nil)
+	s.pools = newWorkerPools(2, map[string]int{"AMQP": 1})
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	jobService.Jobs = []*model.Job{
+		{ID: uuid.New(), Type: model.JobTypeAMQP},
+		{ID: uuid.New(), Type: model.JobTypeAMQP},
+		{ID: uuid.New(), Type: model.JobTypeHTTP},
+	}
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to dispatch the jobs once
+	time.Sleep(time.Millisecond * 150)
+
+	// The second AMQP job is handed over to other runners, without holding up the HTTP job
+	amqpInUse, _ := s.pools.get(model.JobTypeAMQP).usage()
+	httpInUse, _ := s.pools.get(model.JobTypeHTTP).usage()
+	assert.Equal(t, 1, amqpInUse)
+	assert.Equal(t, 1, httpInUse)
+
+	s.Stop(context.Background())
+
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Equal(t, jobService.Jobs[1].ID, jobService.Released[0])
+}
+
+func TestBackpressure(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 1, nil, nil, nil, nil)
+	s.pools = newWorkerPools(1, map[string]int{"AMQP": 1})
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	jobService.Jobs = []*model.Job{{ID: uuid.New(), Type: model.JobTypeHTTP}}
+
+	s.Start()
+
+	// Once the HTTP pool is full, only AMQP jobs are fetched
+	time.Sleep(time.Millisecond * 120)
+	jobService.Lock()
+	assert.Equal(t, []string{string(model.JobTypeAMQP)}, jobService.JobTypes)
+	jobService.Jobs = []*model.Job{{ID: uuid.New(), Type: model.JobTypeAMQP}}
+	jobService.Unlock()
+
+	// Once every pool is full, the runner stops fetching jobs
+	time.Sleep(time.Millisecond * 120)
+	jobService.Lock()
+	fetches := jobService.Fetches
+	jobService.Unlock()
+
+	time.Sleep(time.Millisecond * 120)
+	s.Stop(context.Background())
+
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Equal(t, fetches, jobService.Fetches)
+	assert.Empty(t, jobService.Released)
+}
+
+func TestReconfigure(t *testing.T) {
+	// Poll rarely and without any slot, so no job is run until the runner is reconfigured
+	s := createRunnerWithMockExecutor(time.Hour, 0, nil, nil, nil, nil)
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	time.Sleep(time.Millisecond * 100)
+	jobService.Lock()
+	assert.Len(t, jobService.Jobs, 3)
+	jobService.Unlock()
+
+	s.Reconfigure(JobExecutionSettings{Interval: time.Millisecond * 50, MaxConcurrentJobs: 3})
+	assert.Equal(t, time.Millisecond*50, s.pollInterval())
+	assert.Equal(t, 3, s.pools.capacity())
+
+	time.Sleep(time.Millisecond * 200)
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+}
+
+func TestPriorityDispatch(t *testing.T) {
+	// A single slot, so only the first job dispatched is started
+	s := createRunnerWithMockExecutor(time.Millisecond*100, 1, nil, nil, nil, nil)
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	jobService.Jobs = []*model.Job{
+		{ID: uuid.New(), Priority: model.JobPriorityLow},
+		{ID: uuid.New()},
+		{ID: uuid.New(), Priority: model.JobPriorityHigh},
+	}
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to dispatch the jobs once
+	time.Sleep(time.Millisecond * 150)
+
+	s.Stop(context.Background())
+
+	jobService.Lock()
+	defer jobService.Unlock()
+
+	// The high-priority job is started, the other ones are released by order of priority
+	assert.Equal(t, []uuid.UUID{jobService.Jobs[2].ID}, jobService.Interrupted)
+	assert.Equal(t, []uuid.UUID{jobService.Jobs[1].ID, jobService.Jobs[0].ID}, jobService.Released)
+}
+
 func TestRunJobs(t *testing.T) {
 	t.Run("Happy path", func(t *testing.T) {
 		// Test the happy path where GetJobsToRun and FinishJobExecution succeed
@@ -172,3 +355,500 @@ func TestExecuteJob(t *testing.T) {
 		assertJobsProcessed(t, s.jobService.(*mockJobService))
 	})
 }
+
+func TestStaleExecution(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 1, nil, nil, nil, errors.New("execute error"))
+	s.maxDrift = time.Second
+
+	jobService := s.jobService.(*mockJobService)
+	for _, job := range jobService.Jobs {
+		job.NextRun = null.TimeFrom(time.Now().Add(-time.Hour))
+	}
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run jobs
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	// Stale jobs are reported as finished without being executed
+	assertJobsProcessed(t, jobService)
+	for _, err := range jobService.ExecErrs {
+		assert.ErrorIs(t, err, errs.ErrStaleExecution)
+	}
+}
+
+func TestLookahead(t *testing.T) {
+	t.Run("Jobs fetched ahead run when due", func(t *testing.T) {
+		s := createRunnerWithMockExecutor(time.Millisecond*100, 3, nil, nil, nil, nil)
+		s.lookahead = time.Second
+
+		jobService := s.jobService.(*mockJobService)
+		dueAt := time.Now().Add(time.Millisecond * 250)
+		jobService.Jobs = []*model.Job{{ID: uuid.New(), NextRun: null.TimeFrom(dueAt)}}
+
+		s.Start()
+
+		// The job is fetched on the first tick, but not run before it is due
+		time.Sleep(time.Millisecond * 200)
+		jobService.Lock()
+		assert.Len(t, jobService.Jobs, 1)
+		jobService.Unlock()
+
+		time.Sleep(time.Millisecond * 150)
+		s.Stop(context.Background())
+
+		assertJobsProcessed(t, jobService)
+		assert.Len(t, jobService.ExecErrs, 1)
+	})
+
+	t.Run("Jobs fetched ahead are released when stopping", func(t *testing.T) {
+		s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+		s.lookahead = time.Hour
+
+		jobService := s.jobService.(*mockJobService)
+		jobService.Jobs = []*model.Job{{ID: uuid.New(), NextRun: null.TimeFrom(time.Now().Add(time.Minute))}}
+
+		s.Start()
+
+		time.Sleep(time.Millisecond * 100)
+		s.Stop(context.Background())
+
+		jobService.Lock()
+		defer jobService.Unlock()
+		assert.Equal(t, []uuid.UUID{jobService.Jobs[0].ID}, jobService.Released)
+		assert.Empty(t, jobService.ExecErrs)
+
+		// only the due time is ahead, the locks of the other runners are checked at the current time
+		assert.WithinDuration(t, time.Now(), jobService.At, time.Second)
+		assert.Equal(t, time.Hour, jobService.DueBy.Sub(jobService.At))
+	})
+}
+
+func TestCancelExecution(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.cancellationPollInterval = time.Millisecond * 10
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	jobService.Cancelled = map[int]bool{1: true, 2: true, 3: true}
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run and cancel the jobs
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	// Cancelled jobs are reported as finished with the cancellation error
+	assertJobsProcessed(t, jobService)
+	for _, err := range jobService.ExecErrs {
+		assert.ErrorIs(t, err, errs.ErrExecutionCancelled)
+	}
+}
+
+func TestCancelExecutionNotification(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.listenForCancellations = true
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	jobService.Cancellations = make(chan model.JobCancellation)
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run the jobs
+	time.Sleep(time.Millisecond * 100)
+
+	// Executions are cancelled as soon as the runner is notified, without polling
+	for id := 1; id <= 3; id++ {
+		jobService.Cancellations <- model.JobCancellation{ExecutionID: id, Namespace: model.DefaultNamespace}
+	}
+	time.Sleep(time.Millisecond * 50)
+
+	jobService.Lock()
+	assert.Len(t, jobService.ExecErrs, 3)
+	for _, err := range jobService.ExecErrs {
+		assert.ErrorIs(t, err, errs.ErrExecutionCancelled)
+	}
+	jobService.Unlock()
+
+	s.Stop(context.Background())
+	assertJobsProcessed(t, jobService)
+}
+
+func TestExecutionTimeout(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	for _, job := range jobService.Jobs {
+		job.ExecutionTimeout = null.IntFrom(1)
+	}
+
+	s.Start()
+
+	// Sleep for a moment to allow the executions to time out
+	time.Sleep(time.Millisecond * 1200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	// Timed out jobs are reported as finished with the timeout error
+	assertJobsProcessed(t, jobService)
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Len(t, jobService.ExecErrs, 3)
+	for _, err := range jobService.ExecErrs {
+		assert.ErrorIs(t, err, errs.ErrExecutionTimedOut)
+	}
+}
+
+func TestExecutorPanic(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.executorFactory.(*mockExecutorFactory).panic = true
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run the jobs
+	time.Sleep(time.Millisecond * 100)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	// Panics are reported as failed executions with the stack trace, without crashing the runner
+	assertJobsProcessed(t, jobService)
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Len(t, jobService.ExecErrs, 3)
+	for _, err := range jobService.ExecErrs {
+		assert.ErrorIs(t, err, errs.ErrExecutorPanicked)
+		assert.Contains(t, err.Error(), "executor bug")
+		assert.Contains(t, err.Error(), "safeExecute")
+	}
+}
+
+func TestLockRenewal(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.jobLockDuration = time.Millisecond * 30
+	s.cancellationPollInterval = time.Millisecond * 150
+	s.executorFactory.(*mockExecutorFactory).block = true
+
+	jobService := s.jobService.(*mockJobService)
+	jobService.Cancelled = map[int]bool{1: true, 2: true, 3: true}
+
+	s.Start()
+
+	// Sleep for a moment to allow the jobs to outlast their lock before being cancelled
+	time.Sleep(time.Millisecond * 300)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+	assertJobsProcessed(t, jobService)
+
+	jobService.Lock()
+	defer jobService.Unlock()
+	// The locks of the running jobs are renewed every 10ms
+	assert.GreaterOrEqual(t, jobService.LockExtensions, 3*5)
+	extensions := jobService.LockExtensions
+
+	// Renewals stop once the jobs are finished
+	jobService.Unlock()
+	time.Sleep(time.Millisecond * 50)
+	jobService.Lock()
+	assert.Equal(t, extensions, jobService.LockExtensions)
+}
+
+func TestExecutionLogs(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.maxExecutionLogSize = 1024
+
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run jobs
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	// Logs are saved for every started execution
+	assertJobsProcessed(t, jobService)
+
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Len(t, jobService.Logs, len(jobService.ExecErrs))
+	for _, logs := range jobService.Logs {
+		assert.NotZero(t, logs.ExecutionID)
+	}
+}
+
+// mockLogSink records the executions shipped to it.
+type mockLogSink struct {
+	sync.Mutex
+	executions []logsink.Execution
+}
+
+func (m *mockLogSink) Ship(_ context.Context, execution logsink.Execution) error {
+	m.Lock()
+	defer m.Unlock()
+	m.executions = append(m.executions, execution)
+	return nil
+}
+
+func TestExecutionLogShipping(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	s.maxExecutionLogSize = 1024
+	sink := &mockLogSink{}
+	s.logSink = sink
+	s.skipLogStore = true
+
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run jobs
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+
+	// The logs are shipped to the sink instead of being saved
+	jobService.Lock()
+	defer jobService.Unlock()
+	sink.Lock()
+	defer sink.Unlock()
+	assert.Empty(t, jobService.Logs)
+	assert.Len(t, sink.executions, len(jobService.ExecErrs))
+	for _, execution := range sink.executions {
+		assert.NotZero(t, execution.ExecutionID)
+		assert.NotNil(t, execution.Logs)
+		assert.Equal(t, s.instanceId, execution.Instance)
+	}
+}
+
+// mockResultPublisher records the results published to it.
+type mockResultPublisher struct {
+	sync.Mutex
+	sinks   []model.JobResultSink
+	results []model.ExecutionResult
+}
+
+func (m *mockResultPublisher) Publish(_ context.Context, sink model.JobResultSink, result model.ExecutionResult) error {
+	m.Lock()
+	defer m.Unlock()
+	m.sinks = append(m.sinks, sink)
+	m.results = append(m.results, result)
+	return nil
+}
+
+func TestExecutionResultPublishing(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, errors.New("connection refused"))
+	publisher := &mockResultPublisher{}
+	s.resultPublisher = publisher
+
+	jobService := s.jobService.(*mockJobService)
+	sink := &model.JobResultSink{Type: model.ResultSinkTypeKafka, URL: "http://kafka-rest:8082", Topic: "job-results"}
+	jobService.Jobs[0] = &model.Job{ID: jobService.Jobs[0].ID, ResultSink: sink}
+	jobService.Retries = []model.ExecutionRetry{{ExecutionID: 101, Job: &model.Job{ID: uuid.New(), ResultSink: sink}}}
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run the jobs and the retry
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+
+	// Only the results of the executions of the jobs with a result sink are published
+	publisher.Lock()
+	defer publisher.Unlock()
+	assert.Len(t, publisher.results, 2)
+	for i, result := range publisher.results {
+		assert.Equal(t, *sink, publisher.sinks[i])
+		assert.NotZero(t, result.ExecutionID)
+		assert.Equal(t, model.JobExecutionStatusFailed, result.Status)
+		assert.Equal(t, "connection refused", result.Error)
+	}
+}
+
+func TestExecutionTimeline(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+
+	jobService := s.jobService.(*mockJobService)
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run jobs
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+
+	// The start of the executor is recorded for every started execution
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Len(t, jobService.Events, len(jobService.ExecErrs))
+	for _, event := range jobService.Events {
+		assert.NotZero(t, event.ExecutionID)
+		assert.Equal(t, model.ExecutionEventStarted, event.Type)
+		assert.Equal(t, s.instanceId, event.InstanceID.String)
+	}
+}
+
+func TestExecutionRetries(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 5, nil, nil, nil, nil)
+
+	jobService := s.jobService.(*mockJobService)
+	for i := 1; i <= 3; i++ {
+		jobService.Retries = append(jobService.Retries, model.ExecutionRetry{ExecutionID: 100 + i, Job: &model.Job{ID: uuid.New()}})
+	}
+
+	s.Start()
+
+	// Sleep for a moment to allow the scheduler to run the jobs and the retries
+	time.Sleep(time.Millisecond * 200)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+
+	jobService.Lock()
+	defer jobService.Unlock()
+	// Retries are run with the remaining capacity, and finished without rescheduling the job
+	assert.Empty(t, jobService.Retries)
+	assert.Len(t, jobService.ExecErrs, 3)
+	assert.Len(t, jobService.RetryErrs, 3)
+	for executionID, err := range jobService.RetryErrs {
+		assert.NoError(t, err, executionID)
+	}
+}
+
+func TestInstanceHeartbeat(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	instanceService := &mockInstanceService{}
+	s.instanceService = instanceService
+	s.heartbeatInterval = time.Millisecond * 20
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to send heartbeats
+	time.Sleep(time.Millisecond * 100)
+
+	// Stop the scheduler
+	s.Stop(context.Background())
+
+	instanceService.Lock()
+	defer instanceService.Unlock()
+
+	// The runner registers right away, and deregisters once stopped
+	assert.GreaterOrEqual(t, len(instanceService.Heartbeats), 2)
+	assert.Equal(t, time.Millisecond*60, instanceService.TTL)
+	assert.Equal(t, []string{"test"}, instanceService.Deregistered)
+
+	instance := instanceService.Heartbeats[0]
+	assert.Equal(t, "test", instance.ID)
+	assert.Equal(t, 3, instance.Capacity)
+	assert.False(t, instance.StartedAt.IsZero())
+}
+
+func TestFairDistribution(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Millisecond*50, 3, nil, nil, nil, nil)
+	jobService := s.jobService.(*mockJobService)
+	instanceService := &mockInstanceService{Instances: []model.Instance{
+		{ID: "another", Alive: true},
+		{ID: "dead", Alive: false},
+		{ID: "zzz", Alive: true},
+	}}
+	s.instanceService = instanceService
+	s.heartbeatInterval = time.Millisecond * 20
+	s.fairDistribution = true
+
+	s.Start()
+
+	// Sleep for a moment to allow the runner to update its shard and fetch jobs
+	time.Sleep(time.Millisecond * 120)
+
+	s.Stop(context.Background())
+
+	// The jobs are sharded over the live runners
+	jobService.Lock()
+	defer jobService.Unlock()
+	assert.Equal(t, model.JobShard{Index: 1, Count: 3, Grace: time.Millisecond * 100}, jobService.Shard)
+}
+
+func TestJobWakeups(t *testing.T) {
+	// Poll rarely, so jobs are only run when the runner is woken up
+	s := createRunnerWithMockExecutor(time.Hour, 3, nil, nil, nil, nil)
+	jobService := s.jobService.(*mockJobService)
+	jobService.Wakeups = make(chan model.JobWakeup)
+	s.listenForWakeups = true
+	s.namespaces = []string{model.DefaultNamespace}
+	s.capabilities = []string{"region=eu"}
+
+	s.Start()
+
+	// Wakeups for the jobs of other namespaces are ignored
+	jobService.Wakeups <- model.JobWakeup{JobID: uuid.New(), Namespace: "other", NextRun: time.Now()}
+	time.Sleep(time.Millisecond * 100)
+	jobService.Lock()
+	assert.Len(t, jobService.Jobs, 3)
+	jobService.Unlock()
+
+	// Wakeups for the jobs requiring capabilities the runner doesn't have are ignored
+	jobService.Wakeups <- model.JobWakeup{JobID: uuid.New(), Namespace: model.DefaultNamespace, NextRun: time.Now(), RequiredCapabilities: []string{"gpu"}}
+	time.Sleep(time.Millisecond * 100)
+	jobService.Lock()
+	assert.Len(t, jobService.Jobs, 3)
+	jobService.Unlock()
+
+	// The jobs are run once due
+	jobService.Wakeups <- model.JobWakeup{JobID: uuid.New(), Namespace: model.DefaultNamespace, NextRun: time.Now().Add(time.Millisecond * 100)}
+	time.Sleep(time.Millisecond * 50)
+	jobService.Lock()
+	assert.Len(t, jobService.Jobs, 3)
+	jobService.Unlock()
+
+	time.Sleep(time.Millisecond * 200)
+
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+}
+
+func TestMiddlewarePerType(t *testing.T) {
+	s := createRunnerWithMockExecutor(time.Second, 1, nil, nil, nil, nil)
+	s.buildMiddleware(JobExecutionSettings{
+		Middleware: executor.MiddlewareSettings{Chain: []string{executor.MiddlewareRetry}, MaxRetries: 1},
+		MiddlewarePerType: map[string]executor.MiddlewareSettings{
+			"amqp": {Chain: []string{executor.MiddlewareTimeout}, Timeout: time.Millisecond * 10},
+		},
+	})
+
+	blocking := &mockJobExecutor{block: true}
+
+	// the AMQP jobs get their own chain, the configuration keys being lowercased
+	err := s.middlewareOf(model.JobTypeAMQP)(blocking).Execute(context.Background(), &model.Job{Type: model.JobTypeAMQP})
+	assert.ErrorIs(t, err, errs.ErrExecutionTimedOut)
+
+	failing := &mockJobExecutor{err: errors.New("failed")}
+	err = s.middlewareOf(model.JobTypeHTTP)(failing).Execute(context.Background(), &model.Job{Type: model.JobTypeHTTP})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, errs.ErrExecutionTimedOut)
+
+	// invalid chains are rejected
+	settings := JobExecutionSettings{MiddlewarePerType: map[string]executor.MiddlewareSettings{"http": {Chain: []string{"unknown"}}}}
+	assert.Error(t, settings.ValidateMiddleware())
+}
@@ -0,0 +1,46 @@
+package seed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator(t *testing.T) {
+	now := time.Now()
+	g := NewGenerator(42, now, Options{Namespace: "demo", Types: []model.JobType{model.JobTypeAMQP}, History: 7 * 24 * time.Hour, MaxExecutions: 50})
+
+	names := map[string]bool{}
+	executions := 0
+	for i := 0; i < 200; i++ {
+		job := g.Job(i)
+		require.NoError(t, job.Validate(), "job %d", i)
+		assert.Equal(t, "demo", job.Namespace)
+		assert.Equal(t, model.JobTypeAMQP, job.Type)
+		assert.False(t, names[job.Name.String], "duplicate name %s", job.Name.String)
+		names[job.Name.String] = true
+
+		history := g.Executions(job)
+		assert.LessOrEqual(t, len(history), 50)
+		if job.ExecuteAt.Valid {
+			assert.Empty(t, history)
+		}
+
+		for j, execution := range history {
+			assert.Equal(t, job.ID, execution.JobID)
+			assert.True(t, execution.ScheduledTime.Time.After(job.CreatedAt))
+			assert.False(t, execution.StartTime.Before(execution.ScheduledTime.Time))
+			assert.True(t, execution.EndTime.Time.After(execution.StartTime))
+			assert.Equal(t, execution.Status == model.JobExecutionStatusSuccessful, execution.Success)
+			if j > 0 {
+				assert.True(t, execution.ScheduledTime.Time.After(history[j-1].ScheduledTime.Time))
+			}
+		}
+		executions += len(history)
+	}
+
+	assert.Positive(t, executions)
+}
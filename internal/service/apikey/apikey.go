@@ -0,0 +1,78 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Service manages API keys and authenticates requests with them.
+type Service struct {
+	store store.APIKeyStorer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new API key service with the given store and logger.
+func NewService(store store.APIKeyStorer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// CreateAPIKey generates a new API key. The returned key is not stored and cannot be retrieved again.
+func (s *Service) CreateAPIKey(ctx context.Context, create *model.APIKeyCreate) (*model.CreatedAPIKey, error) {
+	s.log.Info("Creating API key", zap.String("name", create.Name), zap.Bool("admin", create.Admin))
+
+	if err := create.Validate(); err != nil {
+		return nil, err
+	}
+
+	key, prefix, err := security.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := create.ToAPIKey(prefix)
+	if err := s.store.CreateAPIKey(ctx, apiKey, security.HashAPIKey(key)); err != nil {
+		return nil, err
+	}
+
+	return &model.CreatedAPIKey{APIKey: *apiKey, Key: key}, nil
+}
+
+// ListAPIKeys returns all API keys, including the revoked ones.
+func (s *Service) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	s.log.Info("Getting API keys")
+	return s.store.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey revokes the API key with the given ID. Revoked keys can no longer be used.
+func (s *Service) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	s.log.Info("Revoking API key", zap.Any("id", id))
+	return s.store.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate returns the active API key matching the given key.
+func (s *Service) Authenticate(ctx context.Context, key string) (*model.APIKey, error) {
+	if key == "" {
+		return nil, errs.ErrUnauthorized
+	}
+
+	apiKey, err := s.store.GetAPIKeyByHash(ctx, security.HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, errs.ErrAPIKeyNotFound) {
+			return nil, errs.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
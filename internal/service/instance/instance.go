@@ -0,0 +1,59 @@
+package instance
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// expiredRetention is how long instances that stopped sending heartbeats stay listed as dead.
+const expiredRetention = 24 * time.Hour
+
+// Service manages the registry of runner instances.
+type Service struct {
+	store store.InstanceStorer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new instance service with the given store and logger.
+func NewService(store store.InstanceStorer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// Heartbeat registers the instance, or renews its registration, for the TTL. Instances whose registration
+// expired a long time ago are removed from the registry.
+func (s *Service) Heartbeat(ctx context.Context, instance model.Instance, ttl time.Duration) error {
+	s.log.Debug("Sending instance heartbeat", zap.String("id", instance.ID), zap.Int("load", instance.Load))
+
+	if err := s.store.SaveInstance(ctx, instance, ttl); err != nil {
+		return err
+	}
+
+	deleted, err := s.store.DeleteExpiredInstances(ctx, time.Now().Add(-expiredRetention))
+	if err != nil {
+		s.log.Warn("Failed to delete expired instances", zap.Error(err))
+	} else if deleted > 0 {
+		s.log.Info("Deleted expired instances", zap.Int64("count", deleted))
+	}
+
+	return nil
+}
+
+// Deregister removes the instance from the registry.
+func (s *Service) Deregister(ctx context.Context, id string) error {
+	s.log.Info("Deregistering instance", zap.String("id", id))
+	return s.store.DeleteInstance(ctx, id)
+}
+
+// ListInstances returns the registered instances, including the ones that stopped sending heartbeats.
+func (s *Service) ListInstances(ctx context.Context) ([]model.Instance, error) {
+	s.log.Info("Getting instances")
+	return s.store.ListInstances(ctx)
+}
@@ -1,12 +1,22 @@
 package job
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
 	"github.com/TimeSnap/distributed-scheduler/internal/store"
 	"github.com/google/uuid"
+	"github.com/samber/lo"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.uber.org/zap"
 	"gopkg.in/guregu/null.v4"
@@ -26,32 +36,165 @@ func NewService(store store.Storer, log *otelzap.Logger) *Service {
 	}
 }
 
-// CreateJob creates a new job using the given job create request and returns the created job.
+// CreateJob creates a new job in the namespace of the context using the given job create request and returns the created job.
+// The job is owned by the principal of the context and its team unless the request sets them.
 // If the job create request is invalid, an error is returned.
 func (s *Service) CreateJob(ctx context.Context, jobCreate *model.JobCreate) (*model.Job, error) {
-	s.log.Info("Creating job", zap.Any("job", jobCreate))
+	s.log.Info("Creating job", redact.Field("job", jobCreate))
 
 	// Convert the job create request to a job
 	job := jobCreate.ToJob()
+	job.Namespace = model.NamespaceFromContext(ctx)
+	if err := model.PrincipalFromContext(ctx).AssignJob(job); err != nil {
+		return nil, err
+	}
+
+	return s.insertJob(ctx, job)
+}
+
+// CloneJob creates a copy of the job with the given ID, with a new ID and no execution history. The definition
+// of the copy can be overridden with a JSON merge patch, in which case an empty patch keeps it unchanged.
+// The name and external ID of the job are not copied, since they are unique within a namespace, unless the patch sets
+// new ones. Neither is its owner, the copy being owned by the principal of the context. Copies of paused jobs are
+// paused as well.
+func (s *Service) CloneJob(ctx context.Context, jobID uuid.UUID, overrides []byte) (*model.Job, error) {
+	s.log.Info("Cloning a job", zap.Any("id", jobID))
+
+	source, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	definition := source.ToManifest()
+	definition.Name = null.String{}
+	definition.ExternalID = null.String{}
+	definition.Owner = null.String{}
+
+	if len(bytes.TrimSpace(overrides)) > 0 {
+		definition, err = model.ApplyMergePatch(definition, overrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	job := definition.ToJob()
+	job.Namespace = source.Namespace
+	if source.Status == model.JobStatusStopped {
+		job.Status = model.JobStatusStopped
+	}
+
+	if err := model.PrincipalFromContext(ctx).AssignJob(job); err != nil {
+		return nil, err
+	}
+
+	return s.insertJob(ctx, job)
+}
 
+// insertJob validates and stores the new job, within the jobs quota of its namespace.
+func (s *Service) insertJob(ctx context.Context, job *model.Job) (*model.Job, error) {
 	// Validate the job
 	if err := job.Validate(); err != nil {
 		return nil, err
 	}
 
+	if err := s.checkJobsQuota(ctx, job.Namespace, 1); err != nil {
+		return nil, err
+	}
+
 	// Create the job using the store
-	err := s.store.CreateJob(ctx, job)
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := s.store.CreateJob(ctx, job); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, model.NewJobEvent(model.EventJobCreated, job))
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.wakeUp(ctx, job)
+
 	return job, nil
 }
 
-// GetJob returns the job with the given ID.
+// GetJob returns the job with the given ID. Jobs of other namespaces than the one of the context are not found,
+// nor are the jobs of other teams if the principal of the context is restricted to its team.
 func (s *Service) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
 	s.log.Info("Getting a job", zap.Any("id", id))
-	return s.store.GetJob(ctx, id)
+
+	job, err := s.store.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Namespace != model.NamespaceFromContext(ctx) || !model.PrincipalFromContext(ctx).CanAccess(job) {
+		return nil, errs.ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// GetJobByExternalID returns the job of the namespace of the context with the given external ID. Jobs of other teams
+// are reported as not found.
+func (s *Service) GetJobByExternalID(ctx context.Context, externalID string) (*model.Job, error) {
+	s.log.Info("Getting a job by external ID", zap.String("externalID", externalID))
+
+	if err := model.ValidateExternalID(externalID); err != nil {
+		return nil, err
+	}
+
+	job, err := s.store.GetJobByExternalID(ctx, model.NamespaceFromContext(ctx), externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.PrincipalFromContext(ctx).CanAccess(job) {
+		return nil, errs.ErrJobNotFound
+	}
+
+	return job, nil
+}
+
+// BatchGetJobs returns the jobs of the namespace of the context with the given IDs, in the requested order.
+// Missing jobs, and jobs of other namespaces or teams, are reported as not found.
+func (s *Service) BatchGetJobs(ctx context.Context, batch model.JobBatchGet) (*model.JobBatch, error) {
+	s.log.Info("Getting jobs by IDs", zap.Int("count", len(batch.IDs)))
+
+	if err := batch.Validate(); err != nil {
+		return nil, err
+	}
+
+	jobs, err := s.store.GetJobs(ctx, model.NamespaceFromContext(ctx), batch.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := model.PrincipalFromContext(ctx)
+	jobsByID := make(map[uuid.UUID]model.Job, len(jobs))
+	for _, job := range jobs {
+		if principal.CanAccess(&job) {
+			jobsByID[job.ID] = job
+		}
+	}
+
+	result := &model.JobBatch{Jobs: make([]model.Job, 0, len(jobs)), NotFound: []uuid.UUID{}}
+	seen := make(map[uuid.UUID]bool, len(batch.IDs))
+	for _, id := range batch.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		job, ok := jobsByID[id]
+		if !ok {
+			result.NotFound = append(result.NotFound, id)
+			continue
+		}
+		result.Jobs = append(result.Jobs, job)
+	}
+
+	return result, nil
 }
 
 // UpdateJob updates the given job.
@@ -59,77 +202,1694 @@ func (s *Service) UpdateJob(ctx context.Context, jobID uuid.UUID, jobUpdate mode
 	s.log.Info("Updating a job", zap.Any("id", jobID))
 
 	// get the job from the store
-	job, err := s.store.GetJob(ctx, jobID)
+	job, err := s.GetJob(ctx, jobID)
 	if err != nil {
 		return nil, err
 	}
 
+	// reject updates based on a stale version of the job
+	if jobUpdate.Version != nil && *jobUpdate.Version != job.Version {
+		return nil, errs.ErrJobVersionMismatch
+	}
+
 	// update the job
 	job.ApplyUpdate(jobUpdate)
+	if err := model.PrincipalFromContext(ctx).AssignJob(job); err != nil {
+		return nil, err
+	}
 
+	return s.saveJob(ctx, job)
+}
+
+// PatchJob updates the job with the given JSON merge patch (RFC 7386). Unlike UpdateJob, fields can be
+// cleared by setting them to null, e.g. to turn a recurring job into a one-off job.
+// If version is set, the patch is rejected if the job was modified since that version.
+func (s *Service) PatchJob(ctx context.Context, jobID uuid.UUID, patch []byte, version *int64) (*model.Job, error) {
+	s.log.Info("Patching a job", zap.Any("id", jobID))
+
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != nil && *version != job.Version {
+		return nil, errs.ErrJobVersionMismatch
+	}
+
+	definition, err := model.ApplyMergePatch(job.ToManifest(), patch)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Name = definition.Name
+	job.ExternalID = definition.ExternalID
+	job.Owner = definition.Owner
+	job.Team = definition.Team
+	job.ApplyManifest(definition)
+	if err := model.PrincipalFromContext(ctx).AssignJob(job); err != nil {
+		return nil, err
+	}
+
+	return s.saveJob(ctx, job)
+}
+
+// saveJob validates and stores the updated job.
+func (s *Service) saveJob(ctx context.Context, job *model.Job) (*model.Job, error) {
 	// validate the job
 	if err := job.Validate(); err != nil {
 		return nil, err
 	}
 
 	// update the job in the store
-	err = s.store.UpdateJob(ctx, job)
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := s.store.UpdateJob(ctx, job); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, model.NewJobEvent(model.EventJobUpdated, job))
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	s.wakeUp(ctx, job)
+
 	return job, nil
 }
 
 // DeleteJob deletes the job with the given ID.
 func (s *Service) DeleteJob(ctx context.Context, id uuid.UUID) error {
 	s.log.Info("Deleting a job", zap.Any("id", id))
-	return s.store.DeleteJob(ctx, id)
+
+	// get the job first, so the event carries its tags and jobs of other namespaces are left alone
+	job, err := s.GetJob(ctx, id)
+	if errors.Is(err, errs.ErrJobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := s.store.DeleteJob(ctx, id); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, model.NewJobEvent(model.EventJobDeleted, job))
+	})
+}
+
+// DeleteJobByExternalID deletes the job of the namespace of the context with the given external ID. Like deleting a
+// job by ID, deleting a missing job succeeds, so deletions can be retried.
+func (s *Service) DeleteJobByExternalID(ctx context.Context, externalID string) error {
+	job, err := s.GetJobByExternalID(ctx, externalID)
+	if errors.Is(err, errs.ErrJobNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.DeleteJob(ctx, job.ID)
+}
+
+// ListJobs returns a page of jobs matching the filter after the given cursor, along with the total number of matching jobs,
+// estimated if there are many of them unless the filter requests an exact total.
+func (s *Service) ListJobs(ctx context.Context, limit uint64, cursor *model.Cursor, filter model.JobFilter) (*model.JobPage, error) {
+	s.log.Info("Getting jobs", zap.Any("filter", filter))
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	filter.Namespace = model.NamespaceFromContext(ctx)
+	if principal := model.PrincipalFromContext(ctx); principal.Restricted {
+		if filter.Team != "" && filter.Team != principal.Team {
+			return nil, errs.ErrForbidden
+		}
+		filter.Team = principal.Team
+	}
+
+	// fetch one extra job to determine whether there is a next page
+	jobs, err := s.store.ListJobs(ctx, limit+1, cursor, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination, err := countTotal(ctx, filter.ExactTotal,
+		func(ctx context.Context) (uint64, error) { return s.store.EstimateJobs(ctx, filter) },
+		func(ctx context.Context) (uint64, error) { return s.store.CountJobs(ctx, filter) })
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.JobPage{Jobs: jobs, Pagination: pagination}
+	if uint64(len(jobs)) > limit {
+		page.Jobs = jobs[:limit]
+		last := page.Jobs[limit-1]
+		page.NextCursor = null.StringFrom(model.Cursor{Time: last.CreatedAt, ID: last.ID.String()}.Encode())
+	}
+
+	return page, nil
+}
+
+// exactCountThreshold is the estimated total below which listings are counted exactly anyway, as counting them is
+// cheap.
+const exactCountThreshold = 10000
+
+// countTotal returns the total of a listing. Unless exact is set, the total is estimated first, and only counted
+// exactly if the estimate is small, since counting a large table takes seconds.
+func countTotal(ctx context.Context, exact bool, estimate, count func(ctx context.Context) (uint64, error)) (model.Pagination, error) {
+	if !exact {
+		total, err := estimate(ctx)
+		if err != nil {
+			return model.Pagination{}, err
+		}
+
+		if total >= exactCountThreshold {
+			return model.Pagination{Total: total, TotalEstimated: true}, nil
+		}
+	}
+
+	total, err := count(ctx)
+	if err != nil {
+		return model.Pagination{}, err
+	}
+
+	return model.Pagination{Total: total}, nil
+}
+
+// GetJobsToRun returns a list of jobs of the given namespaces (all of them if empty) that should be run by dueBy, and
+// are not locked at the given time, e.g. now while dueBy is ahead of it to prefetch the jobs.
+// Jobs exceeding the concurrent executions quota of their namespace are left for later, and jobs requiring
+// capabilities that are not among the given ones are left to other runners. Jobs of other shards are only
+// returned once they are overdue by the grace period of the shard.
+func (s *Service) GetJobsToRun(ctx context.Context, at time.Time, dueBy time.Time, lockedUntil time.Time, instanceID string, namespaces []string, jobTypes []string, capabilities []string, shard model.JobShard, limit uint) ([]*model.Job, error) {
+	s.log.Info("Getting jobs to run", zap.Any("at", at), zap.Any("dueBy", dueBy), zap.String("lockedUntil", lockedUntil.Format(time.RFC3339)), zap.Any("instanceID", instanceID), zap.Strings("namespaces", namespaces), zap.Strings("jobTypes", jobTypes), zap.Strings("capabilities", capabilities), zap.Any("shard", shard), zap.Any("limit", limit))
+
+	return s.store.GetJobsToRun(ctx, at, dueBy, lockedUntil, instanceID, namespaces, jobTypes, capabilities, shard, limit)
 }
 
-// ListJobs returns a list of jobs with the given limit and offset.
-func (s *Service) ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error) {
-	s.log.Info("Getting jobs")
-	return s.store.ListJobs(ctx, limit, offset, tags)
+// GetSchedulerStatus reports the jobs of all namespaces that are due at the given time but not picked up by any
+// runner yet. The scheduler isn't keeping up if the oldest of them is older than the maximum lag.
+func (s *Service) GetSchedulerStatus(ctx context.Context, at time.Time, maxLag time.Duration) (*model.SchedulerStatus, error) {
+	dueJobs, oldestDueAt, err := s.store.GetDueJobs(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	status := model.NewSchedulerStatus(at, dueJobs, oldestDueAt, maxLag)
+	return &status, nil
+}
+
+// GetBacklog counts the due jobs no runner picked up yet, the pending retries and the running executions of all
+// namespaces at the given time.
+func (s *Service) GetBacklog(ctx context.Context, at time.Time) (*model.Backlog, error) {
+	return s.store.GetBacklog(ctx, at)
+}
+
+// GetMissedJobs returns up to limit jobs of all namespaces that are overdue by more than the threshold at the given
+// time without being picked up by any runner, most overdue first.
+func (s *Service) GetMissedJobs(ctx context.Context, at time.Time, threshold time.Duration, limit uint) ([]*model.Job, error) {
+	return s.store.GetMissedJobs(ctx, at, at.Add(-threshold), limit)
+}
+
+// ReportMissedJobs publishes a missed run event for each of the jobs, for their current next run.
+func (s *Service) ReportMissedJobs(ctx context.Context, jobs ...*model.Job) error {
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		for _, job := range jobs {
+			s.log.Warn("Job missed its run", zap.Any("job", job.ID), zap.String("namespace", job.Namespace), zap.Any("nextRun", job.NextRun))
+
+			event := model.NewJobEvent(model.EventJobMissed, job)
+			event.ScheduledTime = job.NextRun.Ptr()
+			if err := s.publish(ctx, event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DisableFailingJob stops the job of a failed execution event if its last executions failed the given number of times
+// in a row, and publishes a job disabled event. Called by the outbox relay, in the transaction relaying the event.
+func (s *Service) DisableFailingJob(ctx context.Context, event model.Event, failures int) error {
+	if event.Type != model.EventExecutionFailed || failures <= 0 {
+		return nil
+	}
+
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		job, err := s.store.DisableFailingJob(ctx, event.JobID, failures)
+		if err != nil || job == nil {
+			return err
+		}
+
+		s.log.Warn("Disabled failing job", zap.Any("job", job.ID), zap.String("namespace", job.Namespace), zap.Int("failures", failures))
+
+		disabled := model.NewJobEvent(model.EventJobDisabled, job)
+		disabled.Error = fmt.Sprintf("the job failed %d times in a row, last with: %s", failures, event.Error)
+		return s.publish(ctx, disabled)
+	})
+}
+
+// StartJobExecution records the start of a job execution and returns the ID of the execution.
+func (s *Service) StartJobExecution(ctx context.Context, job *model.Job, startTime time.Time) (int, error) {
+	s.log.Info("Starting job execution", zap.Any("job", job.ID), zap.Any("startTime", startTime))
+
+	var executionID int
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		executionID, err = s.store.StartJobExecution(ctx, job.ID, job.NextRun, startTime)
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.AddJobExecutionEvents(ctx, model.NewExecutionEvent(executionID, model.ExecutionEventClaimed, startTime)); err != nil {
+			return err
+		}
+
+		event := model.NewJobEvent(model.EventExecutionStarted, job)
+		event.ExecutionID = &executionID
+		return s.publish(ctx, event)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return executionID, nil
 }
 
-// GetJobsToRun returns a list of jobs that should be run at the given time.
-func (s *Service) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error) {
-	s.log.Info("Getting jobs to run", zap.Any("at", at), zap.String("lockedUntil", lockedUntil.Format(time.RFC3339)), zap.Any("instanceID", instanceID), zap.Any("limit", limit))
+// ExtendJobLock extends the lock of a job being executed by the instance, so it isn't picked up by
+// another instance while the execution outlasts the lock.
+func (s *Service) ExtendJobLock(ctx context.Context, jobID uuid.UUID, instanceID string, lockedUntil time.Time) error {
+	s.log.Debug("Extending job lock", zap.Any("job", jobID), zap.String("instanceID", instanceID), zap.Time("lockedUntil", lockedUntil))
 
-	return s.store.GetJobsToRun(ctx, at, lockedUntil, instanceID, limit)
+	return s.store.ExtendJobLock(ctx, jobID, instanceID, lockedUntil)
 }
 
-func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error {
-	s.log.Info("Finishing job execution", zap.Any("job", job.ID), zap.Any("startTime", startTime), zap.Any("stopTime", stopTime), zap.Any("err", err))
+// FinishJobExecution reschedules the job and records the outcome of the execution. If the execution
+// was not started with StartJobExecution (executionID is 0), the execution is created instead.
+func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, executionID int, startTime, stopTime time.Time, err error) error {
+	// the credentials of the job echoed in the error are masked before the error is logged or stored
+	jobExecutionStatus, errorMessage := model.ExecutionOutcome(err, redact.Secrets(job))
+	s.log.Info("Finishing job execution", zap.Any("job", job.ID), zap.Int("executionID", executionID), zap.Any("startTime", startTime), zap.Any("stopTime", stopTime), zap.String("err", errorMessage.String))
+
+	// Remember when the job was supposed to run, before computing the next run
+	scheduledTime := job.NextRun
 
 	// Update the job execution
 	job.SetNextRunTime()
 
-	// finish the job in the store (update the next run time and clear lock)
-	err2 := s.store.FinishJob(ctx, job.ID, job.NextRun)
-	if err2 != nil {
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		// finish the job in the store (update the next run time and clear lock)
+		err2 := s.store.FinishJob(ctx, job.ID, job.NextRun)
+		if err2 != nil {
+			return err2
+		}
+
+		if executionID != 0 {
+			err2 = s.finishJobExecution(ctx, executionID, stopTime, jobExecutionStatus, errorMessage)
+		} else {
+			// Create the job execution
+			err2 = s.store.CreateJobExecution(ctx, job.ID, scheduledTime, startTime, stopTime, jobExecutionStatus, errorMessage)
+		}
+		if err2 != nil {
+			return err2
+		}
+
+		return s.publish(ctx, executionEvent(job, executionID, jobExecutionStatus, errorMessage))
+	})
+}
+
+// RetryJobExecution schedules a new execution of the job of an execution of the namespace of the context,
+// with either the job definition the execution ran with or the current one. The new execution links to
+// the retried one, and stays pending until a runner picks it up.
+func (s *Service) RetryJobExecution(ctx context.Context, executionID int, definition model.RetryDefinition) (*model.JobExecution, error) {
+	s.log.Info("Retrying job execution", zap.Int("executionID", executionID), zap.String("definition", string(definition)))
+
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkExecutionAccess(ctx, executionID); err != nil {
+		return nil, err
+	}
+
+	var execution *model.JobExecution
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		execution, err = s.store.RetryJobExecution(ctx, model.NamespaceFromContext(ctx), executionID, definition == model.RetryDefinitionCurrent)
+		if err != nil {
+			return err
+		}
+
+		event := model.NewExecutionEvent(execution.ID, model.ExecutionEventQueued, time.Now())
+		event.Message = null.StringFrom(fmt.Sprintf("retry of execution %d with the %s definition", executionID, definition))
+		return s.store.AddJobExecutionEvents(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// RunJob schedules an execution of the job of the namespace of the context with its current definition, on top
+// of its schedule. The execution is pending until a runner picks it up, like retries. The overrides, if any, apply
+// to the definition recorded by this execution only, the job is left unchanged.
+func (s *Service) RunJob(ctx context.Context, jobID uuid.UUID, overrides *model.JobRunOverrides) (*model.JobExecution, error) {
+	s.log.Info("Running job", zap.Any("id", jobID), zap.Bool("overridden", !overrides.IsEmpty()))
+
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition *model.Job
+	if !overrides.IsEmpty() {
+		definition, err = s.overrideJob(ctx, job, overrides)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var execution *model.JobExecution
+	err = s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		message := "manual run"
+		if definition != nil {
+			execution, err = s.store.CreateOverriddenJobExecution(ctx, definition)
+			message = "manual run with overrides"
+		} else {
+			execution, err = s.store.CreatePendingJobExecution(ctx, jobID)
+		}
+		if err != nil {
+			return err
+		}
+
+		event := model.NewExecutionEvent(execution.ID, model.ExecutionEventQueued, time.Now())
+		event.Message = null.StringFrom(message)
+		return s.store.AddJobExecutionEvents(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// overrideJob returns the definition of the job with the overrides of a manual run, rendering the template the job
+// was instantiated from again if the overrides have template parameters.
+func (s *Service) overrideJob(ctx context.Context, job *model.Job, overrides *model.JobRunOverrides) (*model.Job, error) {
+	name, err := overrides.Template(job)
+	if err != nil {
+		return nil, err
+	}
+
+	var template *model.JobTemplate
+	if name != "" {
+		template, err = s.GetTemplate(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return overrides.Apply(job, template)
+}
+
+// BackfillJob schedules an execution of each run of the recurring job of the namespace of the context scheduled in
+// the range, except the runs which were already executed. Like manual runs, the executions are pending until a runner
+// picks them up, and run the current definition of the job. With a dry run, the runs are only listed.
+func (s *Service) BackfillJob(ctx context.Context, jobID uuid.UUID, backfill model.JobBackfill, dryRun bool) (*model.JobBackfillResult, error) {
+	s.log.Info("Backfilling job", zap.Any("id", jobID), zap.Time("from", backfill.From), zap.Time("to", backfill.To), zap.Bool("dryRun", dryRun))
+
+	if err := backfill.Validate(); err != nil {
+		return nil, err
+	}
+
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := job.RunsBetween(backfill.From, backfill.To, model.MaxBackfillRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	executed, err := s.store.GetJobExecutionScheduledTimes(ctx, jobID, backfill.From, backfill.To)
+	if err != nil {
+		return nil, err
+	}
+
+	result := model.NewJobBackfillResult(runs, executed, dryRun)
+	if dryRun || len(result.Runs) == 0 {
+		return result, nil
+	}
+
+	err = s.store.InTx(ctx, func(ctx context.Context) error {
+		executions, err := s.store.CreateBackfillJobExecutions(ctx, jobID, result.Runs)
+		if err != nil {
+			return err
+		}
+
+		events := make([]model.ExecutionEvent, 0, len(executions))
+		for _, execution := range executions {
+			event := model.NewExecutionEvent(execution.ID, model.ExecutionEventQueued, time.Now())
+			event.Message = null.StringFrom("backfill of the run scheduled at " + execution.ScheduledTime.Time.UTC().Format(time.RFC3339))
+			events = append(events, event)
+		}
+
+		result.Executions = executions
+		return s.store.AddJobExecutionEvents(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ClaimJobExecutionRetries starts up to limit pending retries of the given namespaces (all of them if empty).
+func (s *Service) ClaimJobExecutionRetries(ctx context.Context, at time.Time, namespaces []string, limit uint) ([]model.ExecutionRetry, error) {
+	var retries []model.ExecutionRetry
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		retries, err = s.store.ClaimJobExecutionRetries(ctx, at, namespaces, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, retry := range retries {
+			if err := s.store.AddJobExecutionEvents(ctx, model.NewExecutionEvent(retry.ExecutionID, model.ExecutionEventClaimed, at)); err != nil {
+				return err
+			}
+
+			event := model.NewJobEvent(model.EventExecutionStarted, retry.Job)
+			event.ExecutionID = &retry.ExecutionID
+			if err := s.publish(ctx, event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return retries, nil
+}
+
+// InterruptJobExecution records the execution of a job interrupted by the runner stopping as cancelled, and
+// releases the lock of the job without rescheduling it, so another runner executes it again right away.
+func (s *Service) InterruptJobExecution(ctx context.Context, job *model.Job, executionID int, instanceID string, startTime, stopTime time.Time) error {
+	s.log.Info("Interrupting job execution", zap.Any("job", job.ID), zap.Int("executionID", executionID), zap.String("instanceID", instanceID))
+
+	jobExecutionStatus, errorMessage := model.ExecutionOutcome(errs.ErrExecutionInterrupted, nil)
+
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		if executionID != 0 {
+			err = s.finishJobExecution(ctx, executionID, stopTime, jobExecutionStatus, errorMessage)
+		} else {
+			err = s.store.CreateJobExecution(ctx, job.ID, job.NextRun, startTime, stopTime, jobExecutionStatus, errorMessage)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.ReleaseJobLock(ctx, job.ID, instanceID); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, executionEvent(job, executionID, jobExecutionStatus, errorMessage))
+	})
+}
+
+// ReleaseJobLock unlocks a job fetched by the instance but not executed, so another runner executes it right away.
+func (s *Service) ReleaseJobLock(ctx context.Context, jobID uuid.UUID, instanceID string) error {
+	s.log.Info("Releasing job lock", zap.Any("job", jobID), zap.String("instanceID", instanceID))
+	return s.store.ReleaseJobLock(ctx, jobID, instanceID)
+}
+
+// ReleaseJobExecutionRetry returns a retry claimed but not executed to the pending retries.
+func (s *Service) ReleaseJobExecutionRetry(ctx context.Context, retry model.ExecutionRetry) error {
+	s.log.Info("Releasing job execution retry", zap.Any("job", retry.Job.ID), zap.Int("executionID", retry.ExecutionID))
+	return s.store.ReleaseJobExecutionRetry(ctx, retry.ExecutionID)
+}
+
+// FinishJobExecutionRetry records the outcome of a retry. Unlike FinishJobExecution, the job is not rescheduled.
+func (s *Service) FinishJobExecutionRetry(ctx context.Context, retry model.ExecutionRetry, stopTime time.Time, err error) error {
+	jobExecutionStatus, errorMessage := model.ExecutionOutcome(err, redact.Secrets(retry.Job))
+	s.log.Info("Finishing job execution retry", zap.Any("job", retry.Job.ID), zap.Int("executionID", retry.ExecutionID), zap.Any("stopTime", stopTime), zap.String("err", errorMessage.String))
+
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := s.finishJobExecution(ctx, retry.ExecutionID, stopTime, jobExecutionStatus, errorMessage); err != nil {
+			return err
+		}
+
+		return s.publish(ctx, executionEvent(retry.Job, retry.ExecutionID, jobExecutionStatus, errorMessage))
+	})
+}
+
+// finishJobExecution records the outcome of the execution, and adds it to the timeline of the execution.
+func (s *Service) finishJobExecution(ctx context.Context, executionID int, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
+	if err := s.store.FinishJobExecution(ctx, executionID, stopTime, status, errorMessage); err != nil {
 		return err
 	}
 
-	jobExecutionStatus := model.JobExecutionStatusSuccessful
-	errorMessage := null.String{}
+	event := model.NewExecutionEvent(executionID, model.ExecutionEventFinished, stopTime)
+	event.Status = status
+	event.Message = errorMessage
+	return s.store.AddJobExecutionEvents(ctx, event)
+}
+
+// ReapZombieJobExecutions fails up to limit executions whose runner stopped renewing the lock of their job before
+// the given time, e.g. because it crashed. Jobs skipping misfires are rescheduled to their next occurrence, the
+// others are left due, so a runner executes the missed occurrence once.
+func (s *Service) ReapZombieJobExecutions(ctx context.Context, before time.Time, limit uint) ([]model.ZombieExecution, error) {
+	var zombies []model.ZombieExecution
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		zombies, err = s.store.ReapZombieJobExecutions(ctx, before, errs.ErrExecutionAbandoned.Error(), limit)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, zombie := range zombies {
+			abandoned := model.NewExecutionEvent(zombie.ExecutionID, model.ExecutionEventAbandoned, now)
+			abandoned.Status = model.JobExecutionStatusFailed
+			abandoned.Message = null.StringFrom(errs.ErrExecutionAbandoned.Error())
+			if err := s.store.AddJobExecutionEvents(ctx, abandoned); err != nil {
+				return err
+			}
+
+			if err := s.publish(ctx, executionEvent(zombie.Job, zombie.ExecutionID, model.JobExecutionStatusFailed, null.StringFrom(errs.ErrExecutionAbandoned.Error()))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		jobExecutionStatus = model.JobExecutionStatusFailed
-		errorMessage = null.StringFrom(err.Error())
+		return nil, err
 	}
 
-	// Create the job execution
-	err2 = s.store.CreateJobExecution(ctx, job.ID, startTime, stopTime, jobExecutionStatus, errorMessage)
-	if err2 != nil {
-		return err2
+	for _, zombie := range zombies {
+		s.log.Warn("Reaped zombie job execution", zap.Any("job", zombie.Job.ID), zap.Int("executionID", zombie.ExecutionID))
+
+		if zombie.Job.MisfirePolicy.OrDefault() != model.MisfirePolicySkip || !zombie.ScheduledTime.Valid {
+			continue
+		}
+
+		job := *zombie.Job
+		job.SkipRun(zombie.ScheduledTime.Time)
+
+		if err := s.store.RescheduleMisfiredJob(ctx, job.ID, zombie.ScheduledTime.Time, job.NextRun); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	return zombies, nil
 }
 
-func (s *Service) GetJobExecutions(ctx context.Context, id uuid.UUID, failedOnly bool, limit uint64, offset uint64) ([]*model.JobExecution, error) {
-	s.log.Info("Getting job executions", zap.Any("id", id), zap.Any("failedOnly", failedOnly), zap.Any("limit", limit), zap.Any("offset", offset))
+// CancelJobExecution requests the cancellation of a running execution of the namespace of the context.
+// The runner executing it cancels the execution once it notices the request, pending retries are cancelled right away.
+func (s *Service) CancelJobExecution(ctx context.Context, executionID int) error {
+	s.log.Info("Cancelling job execution", zap.Int("executionID", executionID))
+
+	if err := s.checkExecutionAccess(ctx, executionID); err != nil {
+		return err
+	}
+
+	namespace := model.NamespaceFromContext(ctx)
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := s.store.CancelJobExecution(ctx, namespace, executionID); err != nil {
+			return err
+		}
+
+		return s.store.AddJobExecutionEvents(ctx, model.NewExecutionEvent(executionID, model.ExecutionEventCancelRequested, time.Now()))
+	})
+	if err != nil {
+		return err
+	}
+
+	// Notify the runner executing it, the runners still poll for cancellations if the notification is lost
+	cancellation := model.JobCancellation{ExecutionID: executionID, Namespace: namespace}
+	if err := s.store.NotifyJobCancellation(ctx, cancellation); err != nil {
+		s.log.Warn("Failed to notify job cancellation", zap.Int("executionID", executionID), zap.Error(err))
+	}
+
+	return nil
+}
+
+// IsJobExecutionCancelled returns whether the cancellation of the execution was requested.
+func (s *Service) IsJobExecutionCancelled(ctx context.Context, executionID int) (bool, error) {
+	return s.store.IsJobExecutionCancelled(ctx, executionID)
+}
+
+// GetCancelledJobExecutions returns the executions among the given ones whose cancellation was requested.
+func (s *Service) GetCancelledJobExecutions(ctx context.Context, executionIDs []int) ([]int, error) {
+	return s.store.GetCancelledJobExecutions(ctx, executionIDs)
+}
+
+// ListenJobCancellations calls the handler for every cancelled execution, until the context is cancelled or
+// listening fails.
+func (s *Service) ListenJobCancellations(ctx context.Context, handler func(model.JobCancellation)) error {
+	return s.store.ListenJobCancellations(ctx, handler)
+}
+
+// UpdateJobExecutionProgress records the latest progress reported by the executor of a running execution.
+func (s *Service) UpdateJobExecutionProgress(ctx context.Context, executionID int, progress model.ExecutionProgress) error {
+	if err := progress.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.UpdateJobExecutionProgress(ctx, executionID, progress)
+}
+
+// SaveJobExecutionLogs persists the logs captured during an execution.
+func (s *Service) SaveJobExecutionLogs(ctx context.Context, logs model.ExecutionLogs) error {
+	s.log.Info("Saving job execution logs", zap.Int("executionID", logs.ExecutionID), zap.Int("entries", len(logs.Entries)), zap.Bool("truncated", logs.Truncated))
+	return s.store.SaveJobExecutionLogs(ctx, logs)
+}
+
+// GetJobExecutionLogs returns the logs captured during the execution of the job.
+func (s *Service) GetJobExecutionLogs(ctx context.Context, jobID uuid.UUID, executionID int) (*model.ExecutionLogs, error) {
+	s.log.Info("Getting job execution logs", zap.Any("id", jobID), zap.Int("executionID", executionID))
+
+	if _, err := s.GetJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetJobExecutionLogs(ctx, jobID, executionID)
+}
+
+func (s *Service) SaveJobExecutionOutput(ctx context.Context, output model.ExecutionOutput) error {
+	s.log.Info("Saving job execution output", zap.Int("executionID", output.ExecutionID), zap.Int64("size", output.Size), zap.Bool("truncated", output.Truncated))
+	return s.store.SaveJobExecutionOutput(ctx, output)
+}
+
+// GetJobExecutionOutput returns the output captured during the execution of the job, compressed.
+func (s *Service) GetJobExecutionOutput(ctx context.Context, jobID uuid.UUID, executionID int) (*model.ExecutionOutput, error) {
+	s.log.Info("Getting job execution output", zap.Any("id", jobID), zap.Int("executionID", executionID))
+
+	if _, err := s.GetJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetJobExecutionOutput(ctx, jobID, executionID)
+}
+
+// AddJobExecutionEvents adds the events recorded by the runner to the timelines of the executions.
+func (s *Service) AddJobExecutionEvents(ctx context.Context, events ...model.ExecutionEvent) error {
+	s.log.Debug("Adding job execution events", zap.Int("events", len(events)))
+	return s.store.AddJobExecutionEvents(ctx, events...)
+}
+
+// GetJobExecutionEvents returns the timeline of an execution of the namespace of the context, oldest event first.
+func (s *Service) GetJobExecutionEvents(ctx context.Context, executionID int) ([]model.ExecutionEvent, error) {
+	s.log.Info("Getting job execution events", zap.Int("executionID", executionID))
+
+	if err := s.checkExecutionAccess(ctx, executionID); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetJobExecutionEvents(ctx, model.NamespaceFromContext(ctx), executionID)
+}
+
+// checkExecutionAccess returns ErrExecutionNotFound if the principal of the context is restricted to its team, and
+// the execution is not one of a job of its team.
+func (s *Service) checkExecutionAccess(ctx context.Context, executionID int) error {
+	if !model.PrincipalFromContext(ctx).Restricted {
+		return nil
+	}
+
+	jobID, err := s.store.GetJobExecutionJobID(ctx, model.NamespaceFromContext(ctx), executionID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.GetJob(ctx, jobID); err != nil {
+		if errors.Is(err, errs.ErrJobNotFound) {
+			return errs.ErrExecutionNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// GetJobExecutions returns a page of executions of the job after the given cursor, along with their total number,
+// estimated if there are many of them unless exact is set.
+func (s *Service) GetJobExecutions(ctx context.Context, id uuid.UUID, failedOnly bool, limit uint64, cursor *model.Cursor, exact bool) (*model.JobExecutionPage, error) {
+	s.log.Info("Getting job executions", zap.Any("id", id), zap.Any("failedOnly", failedOnly), zap.Any("limit", limit), zap.Any("cursor", cursor), zap.Bool("exact", exact))
+
+	if _, err := s.GetJob(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// fetch one extra execution to determine whether there is a next page
+	executions, err := s.store.GetJobExecutions(ctx, id, failedOnly, limit+1, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	pagination, err := countTotal(ctx, exact,
+		func(ctx context.Context) (uint64, error) { return s.store.EstimateJobExecutions(ctx, id, failedOnly) },
+		func(ctx context.Context) (uint64, error) { return s.store.CountJobExecutions(ctx, id, failedOnly) })
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.JobExecutionPage{Executions: executions, Pagination: pagination}
+	if uint64(len(executions)) > limit {
+		page.Executions = executions[:limit]
+		last := page.Executions[limit-1]
+		page.NextCursor = null.StringFrom(model.Cursor{Time: last.StartTime, ID: strconv.Itoa(last.ID)}.Encode())
+	}
+
+	return page, nil
+}
+
+// ExportJobExecutions calls fn with every execution of the job started within the range, oldest first.
+func (s *Service) ExportJobExecutions(ctx context.Context, id uuid.UUID, r model.ExecutionExportRange, fn func(*model.JobExecution) error) error {
+	s.log.Info("Exporting job executions", zap.Any("id", id), zap.Time("from", r.From), zap.Time("to", r.To))
+
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	// make sure the job exists in the namespace of the context
+	if _, err := s.GetJob(ctx, id); err != nil {
+		return err
+	}
+
+	return s.store.ExportJobExecutions(ctx, id, r, fn)
+}
+
+// GetLatestJobExecutions returns up to limit most recent executions of each of the jobs, newest first, grouped by job ID.
+func (s *Service) GetLatestJobExecutions(ctx context.Context, jobIDs []uuid.UUID, limit uint64) (map[uuid.UUID][]*model.JobExecution, error) {
+	s.log.Info("Getting latest job executions", zap.Int("jobs", len(jobIDs)), zap.Uint64("limit", limit))
+
+	executions, err := s.store.GetLatestJobExecutions(ctx, jobIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.GroupBy(executions, func(execution *model.JobExecution) uuid.UUID {
+		return execution.JobID
+	}), nil
+}
+
+// GetJobExecutionStats returns the execution stats of each of the jobs, including the jobs that were never executed.
+func (s *Service) GetJobExecutionStats(ctx context.Context, jobIDs []uuid.UUID) (map[uuid.UUID]model.JobExecutionStats, error) {
+	s.log.Info("Getting job execution stats", zap.Int("jobs", len(jobIDs)))
+
+	stats, err := s.store.GetJobExecutionStats(ctx, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	statsByJob := make(map[uuid.UUID]model.JobExecutionStats, len(jobIDs))
+	for _, id := range jobIDs {
+		statsByJob[id] = model.JobExecutionStats{JobID: id}
+	}
+
+	for _, stat := range stats {
+		statsByJob[stat.JobID] = stat
+	}
+
+	statuses, err := s.store.GetJobSLAStatuses(ctx, time.Now(), jobIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range statuses {
+		stat := statsByJob[status.JobID]
+		stat.SLA = &status
+		statsByJob[status.JobID] = stat
+	}
+
+	return statsByJob, nil
+}
+
+// GetJobSLAStatus returns the compliance of the job with its SLA at the given time.
+func (s *Service) GetJobSLAStatus(ctx context.Context, id uuid.UUID, at time.Time) (*model.JobSLAStatus, error) {
+	s.log.Info("Getting job SLA status", zap.Any("id", id))
+
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.SLA == nil {
+		return nil, errs.ErrJobSLANotFound
+	}
+
+	statuses, err := s.store.GetJobSLAStatuses(ctx, at, []uuid.UUID{job.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	// the SLA was removed since the job was read
+	if len(statuses) == 0 {
+		return nil, errs.ErrJobSLANotFound
+	}
+
+	return &statuses[0], nil
+}
+
+// GetSLAJobs returns up to limit jobs of all namespaces with an SLA, with an ID after the given one, by ID.
+func (s *Service) GetSLAJobs(ctx context.Context, after uuid.UUID, limit uint) ([]*model.Job, error) {
+	return s.store.GetSLAJobs(ctx, after, limit)
+}
+
+// GetJobSLAStatuses returns the compliance with their SLA of the jobs of all namespaces at the given time. Jobs
+// without an SLA are omitted.
+func (s *Service) GetJobSLAStatuses(ctx context.Context, at time.Time, jobIDs []uuid.UUID) ([]model.JobSLAStatus, error) {
+	return s.store.GetJobSLAStatuses(ctx, at, jobIDs)
+}
+
+// ReportSLABreaches publishes an SLA breach event for each of the jobs, with the status breaching its SLA.
+func (s *Service) ReportSLABreaches(ctx context.Context, breaches ...model.SLABreach) error {
+	return s.store.InTx(ctx, func(ctx context.Context) error {
+		for _, breach := range breaches {
+			s.log.Warn("Job breached its SLA", zap.Any("job", breach.Job.ID), zap.String("namespace", breach.Job.Namespace),
+				zap.Any("breaches", breach.Status.Breaches))
+
+			event := model.NewJobEvent(model.EventJobSLABreached, breach.Job)
+			event.SLA = &breach.Status
+			if err := s.publish(ctx, event); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ArchiveExpiredJobs archives all completed one-off jobs whose TTL has elapsed at the given time.
+func (s *Service) ArchiveExpiredJobs(ctx context.Context, at time.Time) (int64, error) {
+	s.log.Debug("Archiving expired jobs", zap.Time("at", at))
+
+	archived, err := s.store.ArchiveExpiredJobs(ctx, at)
+	if err == nil && archived > 0 {
+		s.log.Info("Archived expired jobs", zap.Int64("count", archived))
+	}
+
+	return archived, err
+}
+
+// DeleteExpiredJobs deletes all completed one-off jobs whose TTL has elapsed at the given time.
+func (s *Service) DeleteExpiredJobs(ctx context.Context, at time.Time) (int64, error) {
+	s.log.Debug("Deleting expired jobs", zap.Time("at", at))
+
+	deleted, err := s.store.DeleteExpiredJobs(ctx, at)
+	if err == nil && deleted > 0 {
+		s.log.Info("Deleted expired jobs", zap.Int64("count", deleted))
+	}
+
+	return deleted, err
+}
+
+// GetExpiredJobExecutions returns up to limit finished executions, oldest first, expired by age or by count. Unset
+// limits don't expire executions.
+func (s *Service) GetExpiredJobExecutions(ctx context.Context, expiry model.ExecutionExpiry, limit uint) ([]*model.JobExecution, error) {
+	return s.store.GetExpiredJobExecutions(ctx, expiry, limit)
+}
+
+// DeleteJobExecutions deletes the finished executions among the given ones along with their logs.
+func (s *Service) DeleteJobExecutions(ctx context.Context, executionIDs []int) (int64, error) {
+	s.log.Info("Deleting expired job executions", zap.Int("count", len(executionIDs)))
+	return s.store.DeleteJobExecutions(ctx, executionIDs)
+}
+
+// CreateJobs creates all the given jobs in a single transaction. If any of the jobs is invalid, none are created.
+func (s *Service) CreateJobs(ctx context.Context, bulk model.BulkJobCreate) (*model.BulkResult, error) {
+	s.log.Info("Creating jobs in bulk", zap.Int("count", len(bulk.Jobs)))
+
+	if err := bulk.Validate(); err != nil {
+		return nil, err
+	}
+
+	namespace := model.NamespaceFromContext(ctx)
+	if err := s.checkJobsQuota(ctx, namespace, len(bulk.Jobs)); err != nil {
+		return nil, err
+	}
+
+	principal := model.PrincipalFromContext(ctx)
+	jobs := make([]*model.Job, 0, len(bulk.Jobs))
+	results := make([]model.BulkItemResult, 0, len(bulk.Jobs))
+	for i := range bulk.Jobs {
+		job := bulk.Jobs[i].ToJob()
+		job.Namespace = namespace
+		result := model.BulkItemResult{Index: lo.ToPtr(i), ID: lo.ToPtr(job.ID)}
+		if err := principal.AssignJob(job); err != nil {
+			result.Error = err.Error()
+		} else if err := job.Validate(); err != nil {
+			result.Error = err.Error()
+		}
+
+		jobs = append(jobs, job)
+		results = append(results, result)
+	}
+
+	return s.writeJobs(ctx, jobs, results, s.store.CreateJobs, model.EventJobCreated)
+}
+
+// ImportCrontab converts the commands of a crontab into jobs with the mapping of the import, and creates them in a
+// single transaction unless it is a dry run. If any of the lines is invalid, none are created.
+func (s *Service) ImportCrontab(ctx context.Context, request model.CrontabImport, dryRun bool) (*model.CrontabImportResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	entries, lineErrors := model.ParseCrontab(request.Crontab, request.Mapping.System)
+	if count := len(entries) + len(lineErrors); count == 0 || count > model.MaxBulkItems {
+		return nil, errs.ErrInvalidCrontabImport
+	}
+
+	s.log.Info("Importing crontab", zap.Int("commands", len(entries)), zap.Bool("dryRun", dryRun))
+
+	namespace := model.NamespaceFromContext(ctx)
+	principal := model.PrincipalFromContext(ctx)
+	result := &model.CrontabImportResult{Jobs: make([]model.CrontabImportItem, 0, len(entries)+len(lineErrors))}
+	jobs := make([]*model.Job, 0, len(entries))
+	for _, lineError := range lineErrors {
+		result.Jobs = append(result.Jobs, model.CrontabImportItem{Line: lineError.Line, Entry: lineError.Text, Error: lineError.Error})
+	}
+
+	for _, entry := range entries {
+		item := model.CrontabImportItem{Line: entry.Line, Entry: entry.Text}
+
+		definition, err := request.Mapping.ToJob(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		job := definition.ToJob()
+		job.Namespace = namespace
+		if err := principal.AssignJob(job); err != nil {
+			item.Error = err.Error()
+		} else if err := job.Validate(); err != nil {
+			item.Error = err.Error()
+		}
+
+		item.Job = job
+		jobs = append(jobs, job)
+		result.Jobs = append(result.Jobs, item)
+	}
+
+	slices.SortFunc(result.Jobs, func(a, b model.CrontabImportItem) int { return a.Line - b.Line })
+
+	if dryRun || !result.Succeeded() {
+		return result, nil
+	}
+
+	if err := s.checkJobsQuota(ctx, namespace, len(jobs)); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.writeJobs(ctx, jobs, nil, s.store.CreateJobs, model.EventJobCreated); err != nil {
+		return nil, err
+	}
+	result.Created = true
+
+	return result, nil
+}
+
+// ImportCronJobs converts the Kubernetes CronJobs of the manifests into jobs with the mapping of the import, and
+// creates them in a single transaction unless it is a dry run. If any of the CronJobs is invalid, none are created.
+// The jobs of suspended CronJobs are created stopped.
+func (s *Service) ImportCronJobs(ctx context.Context, request model.CronJobImport, dryRun bool) (*model.CronJobImportResult, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	cronJobs, err := model.ParseCronJobs(request.Manifests)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrInvalidCronJobImport, err)
+	}
+	if len(cronJobs) == 0 || len(cronJobs) > model.MaxBulkItems {
+		return nil, errs.ErrInvalidCronJobImport
+	}
+
+	s.log.Info("Importing CronJobs", zap.Int("cronJobs", len(cronJobs)), zap.Bool("dryRun", dryRun))
+
+	namespace := model.NamespaceFromContext(ctx)
+	principal := model.PrincipalFromContext(ctx)
+	result := &model.CronJobImportResult{Jobs: make([]model.CronJobImportItem, 0, len(cronJobs))}
+	jobs := make([]*model.Job, 0, len(cronJobs))
+	for _, cronJob := range cronJobs {
+		item := model.CronJobImportItem{Namespace: cronJob.Namespace(), Name: cronJob.Metadata.Name}
+
+		definition, warnings, err := request.Mapping.ToJob(cronJob)
+		item.Warnings = warnings
+		if err != nil {
+			item.Error = err.Error()
+			result.Jobs = append(result.Jobs, item)
+			continue
+		}
+
+		job := definition.ToJob()
+		job.Namespace = namespace
+		if cronJob.Spec.Suspend {
+			job.Status = model.JobStatusStopped
+		}
+
+		if err := principal.AssignJob(job); err != nil {
+			item.Error = err.Error()
+		} else if err := job.Validate(); err != nil {
+			item.Error = err.Error()
+		}
+
+		item.Job = job
+		jobs = append(jobs, job)
+		result.Jobs = append(result.Jobs, item)
+	}
+
+	if dryRun || !result.Succeeded() {
+		return result, nil
+	}
+
+	if err := s.checkJobsQuota(ctx, namespace, len(jobs)); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.writeJobs(ctx, jobs, nil, s.store.CreateJobs, model.EventJobCreated); err != nil {
+		return nil, err
+	}
+	result.Created = true
+
+	return result, nil
+}
+
+// UpdateJobs updates the listed jobs, or all jobs matching the selector, in a single transaction.
+// If any of the updated jobs is invalid or does not exist, none are updated.
+func (s *Service) UpdateJobs(ctx context.Context, bulk model.BulkJobUpdate) (*model.BulkResult, error) {
+	s.log.Info("Updating jobs in bulk", zap.Int("count", len(bulk.Jobs)), zap.Any("selector", bulk.Selector))
+
+	if err := bulk.Validate(); err != nil {
+		return nil, err
+	}
+
+	jobs := []*model.Job{}
+	results := []model.BulkItemResult{}
+	principal := model.PrincipalFromContext(ctx)
+
+	if len(bulk.Jobs) > 0 {
+		ids := lo.Map(bulk.Jobs, func(item model.BulkJobUpdateItem, _ int) uuid.UUID { return item.ID })
+		selector := model.JobSelector{IDs: ids, Namespace: model.NamespaceFromContext(ctx), Team: principal.TeamScope()}
+		if err := selector.Validate(); err != nil {
+			return nil, err
+		}
+
+		existing, err := s.store.ListJobs(ctx, model.MaxBulkItems, nil, selector.Filter())
+		if err != nil {
+			return nil, err
+		}
+		byID := lo.KeyBy(existing, func(job model.Job) uuid.UUID { return job.ID })
+
+		for i, item := range bulk.Jobs {
+			result := model.BulkItemResult{Index: lo.ToPtr(i), ID: lo.ToPtr(item.ID)}
+
+			job, ok := byID[item.ID]
+			if !ok {
+				result.Error = errs.ErrJobNotFound.Error()
+				results = append(results, result)
+				continue
+			}
+
+			result.Error = applyUpdate(&job, item.JobUpdate, principal)
+			jobs = append(jobs, &job)
+			results = append(results, result)
+		}
+	} else {
+		bulk.Selector.Namespace = model.NamespaceFromContext(ctx)
+		bulk.Selector.Team = principal.TeamScope()
+
+		// fetch one extra job to detect selectors matching too many jobs
+		existing, err := s.store.ListJobs(ctx, model.MaxBulkItems+1, nil, bulk.Selector.Filter())
+		if err != nil {
+			return nil, err
+		}
+
+		if len(existing) > model.MaxBulkItems {
+			return nil, errs.ErrInvalidBulkRequest
+		}
+
+		for i := range existing {
+			job := &existing[i]
+			result := model.BulkItemResult{ID: lo.ToPtr(job.ID)}
+			result.Error = applyUpdate(job, *bulk.Update, principal)
+
+			jobs = append(jobs, job)
+			results = append(results, result)
+		}
+	}
+
+	return s.writeJobs(ctx, jobs, results, s.store.UpdateJobs, model.EventJobUpdated)
+}
+
+// PauseJobs stops all selected jobs in a single transaction.
+func (s *Service) PauseJobs(ctx context.Context, selector model.JobSelector) (*model.BulkResult, error) {
+	s.log.Info("Pausing jobs in bulk", zap.Any("selector", selector))
+	return s.selectJobs(ctx, selector, model.EventJobUpdated, func(ctx context.Context, selector model.JobSelector) ([]uuid.UUID, error) {
+		return s.store.SetJobsStatus(ctx, selector, model.JobStatusStopped)
+	})
+}
+
+// ResumeJobs resumes all selected jobs in a single transaction.
+func (s *Service) ResumeJobs(ctx context.Context, selector model.JobSelector) (*model.BulkResult, error) {
+	s.log.Info("Resuming jobs in bulk", zap.Any("selector", selector))
+	return s.selectJobs(ctx, selector, model.EventJobUpdated, func(ctx context.Context, selector model.JobSelector) ([]uuid.UUID, error) {
+		return s.store.SetJobsStatus(ctx, selector, model.JobStatusRunning)
+	})
+}
+
+// DeleteJobs deletes all selected jobs in a single transaction.
+func (s *Service) DeleteJobs(ctx context.Context, selector model.JobSelector) (*model.BulkResult, error) {
+	s.log.Info("Deleting jobs in bulk", zap.Any("selector", selector))
+	return s.selectJobs(ctx, selector, model.EventJobDeleted, func(ctx context.Context, selector model.JobSelector) ([]uuid.UUID, error) {
+		return s.store.DeleteJobs(ctx, selector)
+	})
+}
+
+// ListTags returns the tags of the jobs of the namespace of the context, with the number of jobs having each of them.
+// Principals restricted to their team only see the tags of the jobs of their team.
+func (s *Service) ListTags(ctx context.Context) ([]model.TagCount, error) {
+	s.log.Info("Listing tags")
+	return s.store.ListTags(ctx, model.NamespaceFromContext(ctx), model.PrincipalFromContext(ctx).TeamScope())
+}
+
+// RenameTag renames the tag on all jobs of the namespace of the context. Since the jobs of all teams are renamed,
+// principals restricted to their team can't rename tags.
+func (s *Service) RenameTag(ctx context.Context, rename model.TagRename) (*model.TagRenameResult, error) {
+	s.log.Info("Renaming tag", zap.String("from", rename.From), zap.String("to", rename.To))
+
+	if model.PrincipalFromContext(ctx).Restricted {
+		return nil, errs.ErrForbidden
+	}
+
+	if err := rename.Validate(); err != nil {
+		return nil, err
+	}
+
+	namespace := model.NamespaceFromContext(ctx)
+	var ids []uuid.UUID
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		ids, err = s.store.RenameTag(ctx, namespace, rename.From, rename.To)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := s.publish(ctx, model.Event{ID: uuid.New(), Type: model.EventJobUpdated, Time: time.Now(), JobID: id, Namespace: namespace, Tags: []string{rename.To}}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TagRenameResult{Renamed: int64(len(ids))}, nil
+}
+
+// CreateTemplate creates a new job template in the namespace of the context.
+func (s *Service) CreateTemplate(ctx context.Context, templateCreate *model.JobTemplateCreate) (*model.JobTemplate, error) {
+	s.log.Info("Creating job template", zap.String("name", templateCreate.Name))
+
+	template := templateCreate.ToTemplate()
+	template.Namespace = model.NamespaceFromContext(ctx)
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// GetTemplate returns the job template of the namespace of the context with the given name.
+func (s *Service) GetTemplate(ctx context.Context, name string) (*model.JobTemplate, error) {
+	s.log.Info("Getting a job template", zap.String("name", name))
+	return s.store.GetTemplate(ctx, model.NamespaceFromContext(ctx), name)
+}
+
+// ListTemplates returns all job templates of the namespace of the context, ordered by name.
+func (s *Service) ListTemplates(ctx context.Context) ([]model.JobTemplate, error) {
+	s.log.Info("Getting job templates")
+	return s.store.ListTemplates(ctx, model.NamespaceFromContext(ctx))
+}
+
+// UpdateTemplate replaces the job template with the given name. Jobs already instantiated from it are not changed.
+func (s *Service) UpdateTemplate(ctx context.Context, name string, templateUpdate *model.JobTemplateCreate) (*model.JobTemplate, error) {
+	s.log.Info("Updating job template", zap.String("name", name))
+
+	existing, err := s.GetTemplate(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	template := templateUpdate.ToTemplate()
+	template.Name = existing.Name
+	template.Namespace = existing.Namespace
+	template.CreatedAt = existing.CreatedAt
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.UpdateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// DeleteTemplate deletes the job template with the given name. Jobs instantiated from it are kept.
+func (s *Service) DeleteTemplate(ctx context.Context, name string) error {
+	s.log.Info("Deleting job template", zap.String("name", name))
+	return s.store.DeleteTemplate(ctx, model.NamespaceFromContext(ctx), name)
+}
+
+// CreateJobFromTemplate creates a new job in the namespace of the context from the definition of a template,
+// with its placeholders replaced by the given parameters. The job is labeled template=<name>.
+func (s *Service) CreateJobFromTemplate(ctx context.Context, fromTemplate model.JobFromTemplate) (*model.Job, error) {
+	s.log.Info("Creating job from template", zap.String("template", fromTemplate.Template))
+
+	template, err := s.GetTemplate(ctx, fromTemplate.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := template.Render(fromTemplate.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fromTemplate.Metadata) > 0 {
+		if definition.Metadata == nil {
+			definition.Metadata = map[string]string{}
+		}
+		maps.Copy(definition.Metadata, fromTemplate.Metadata)
+	}
+
+	job := definition.ToJob()
+	job.Namespace = template.Namespace
+	if err := model.PrincipalFromContext(ctx).AssignJob(job); err != nil {
+		return nil, err
+	}
+
+	return s.insertJob(ctx, job)
+}
+
+// ExportJobs returns the manifest of the named jobs of the namespace of the context, accessible by its principal.
+// Credentials are not exported, they must be added back to the manifest before applying it.
+func (s *Service) ExportJobs(ctx context.Context) (*model.JobManifest, error) {
+	s.log.Info("Exporting jobs")
+
+	jobs, err := s.namedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &model.JobManifest{Jobs: make([]model.JobCreate, 0, len(jobs))}
+	for i := range jobs {
+		jobs[i].RemoveCredentials()
+		manifest.Jobs = append(manifest.Jobs, jobs[i].ToManifest())
+	}
+
+	return manifest, nil
+}
+
+// ApplyManifest makes the named jobs of the namespace of the context match the manifest: jobs missing from
+// the namespace are created, jobs whose definition differs are updated, and named jobs missing from the
+// manifest are deleted, unless the options keep them. Unnamed jobs are left alone, as are the jobs of other teams if
+// the principal of the context is restricted to its team. All changes are applied in a single transaction.
+func (s *Service) ApplyManifest(ctx context.Context, manifest model.JobManifest, options model.ManifestApplyOptions) (*model.ManifestApplyResult, error) {
+	s.log.Info("Applying job manifest", zap.Int("jobs", len(manifest.Jobs)), zap.Bool("keepMissing", options.KeepMissing))
+
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	namespace := model.NamespaceFromContext(ctx)
+	principal := model.PrincipalFromContext(ctx)
+	existing, err := s.namedJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := lo.KeyBy(existing, func(job model.Job) string { return job.Name.String })
+	byExternalID := lo.KeyBy(lo.Filter(existing, func(job model.Job, _ int) bool { return job.ExternalID.Valid }),
+		func(job model.Job) string { return job.ExternalID.String })
+
+	result := &model.ManifestApplyResult{Created: []string{}, Updated: []string{}, Deleted: []string{}, Unchanged: []string{}}
+	var created, updated []*model.Job
+	matched := make(map[uuid.UUID]bool, len(manifest.Jobs))
+	for i := range manifest.Jobs {
+		definition := manifest.Jobs[i]
+		name := definition.Name.String
+
+		// jobs are matched by external ID first, so renaming a job with an external ID updates it
+		var current model.Job
+		ok := false
+		if definition.ExternalID.Valid {
+			current, ok = byExternalID[definition.ExternalID.String]
+		}
+		if !ok {
+			current, ok = byName[name]
+		}
+		if ok {
+			// a job matched by the name of a job and the external ID of another one
+			if matched[current.ID] {
+				return nil, fmt.Errorf("job %s: %w", name, errs.ErrInvalidManifest)
+			}
+			matched[current.ID] = true
+			delete(byName, current.Name.String)
+		}
+
+		// the external ID, owner and team of the jobs are kept unless the manifest sets them
+		if ok && !definition.ExternalID.Valid {
+			definition.ExternalID = current.ExternalID
+		}
+		if ok && !definition.Owner.Valid {
+			definition.Owner = current.Owner
+		}
+		if ok && !definition.Team.Valid {
+			definition.Team = current.Team
+		}
+
+		if ok && options.KeepCredentials {
+			currentDefinition := current.ToManifest()
+			redact.Restore(&definition, &currentDefinition)
+		}
+
+		if ok && model.SameDefinition(current.ToManifest(), definition) {
+			result.Unchanged = append(result.Unchanged, name)
+			continue
+		}
+
+		job := definition.ToJob()
+		if ok {
+			job = &current
+			job.Name = definition.Name
+			job.ExternalID = definition.ExternalID
+			job.Owner = definition.Owner
+			job.Team = definition.Team
+			job.ApplyManifest(definition)
+		}
+		job.Namespace = namespace
+
+		if err := principal.AssignJob(job); err != nil {
+			return nil, fmt.Errorf("job %s: %w", name, err)
+		}
+
+		if err := job.Validate(); err != nil {
+			return nil, fmt.Errorf("job %s: %w", name, err)
+		}
+
+		if ok {
+			updated = append(updated, job)
+			result.Updated = append(result.Updated, name)
+		} else {
+			created = append(created, job)
+			result.Created = append(result.Created, name)
+		}
+	}
+
+	// the remaining named jobs are not in the manifest anymore
+	var deleted []model.Job
+	if !options.KeepMissing {
+		deleted = lo.Values(byName)
+	}
+	slices.SortFunc(deleted, func(a, b model.Job) int { return strings.Compare(a.Name.String, b.Name.String) })
+	for _, job := range deleted {
+		result.Deleted = append(result.Deleted, job.Name.String)
+	}
+
+	if added := len(created) - len(deleted); added > 0 {
+		if err := s.checkJobsQuota(ctx, namespace, added); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := lo.Map(deleted, func(job model.Job, _ int) uuid.UUID { return job.ID })
+	err = s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := s.store.ApplyJobs(ctx, created, updated, ids); err != nil {
+			return err
+		}
+
+		events := make([]model.Event, 0, len(created)+len(updated)+len(deleted))
+		for _, job := range created {
+			events = append(events, model.NewJobEvent(model.EventJobCreated, job))
+		}
+		for _, job := range updated {
+			events = append(events, model.NewJobEvent(model.EventJobUpdated, job))
+		}
+		for i := range deleted {
+			events = append(events, model.NewJobEvent(model.EventJobDeleted, &deleted[i]))
+		}
+
+		return s.publish(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.wakeUp(ctx, created...)
+	s.wakeUp(ctx, updated...)
+
+	return result, nil
+}
+
+// namedJobs returns the named jobs of the namespace of the context, accessible by its principal.
+func (s *Service) namedJobs(ctx context.Context) ([]model.Job, error) {
+	jobs, err := s.store.GetNamedJobs(ctx, model.NamespaceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	principal := model.PrincipalFromContext(ctx)
+	return lo.Filter(jobs, func(job model.Job, _ int) bool { return principal.CanAccess(&job) }), nil
+}
+
+// GetNamespace returns the namespace with the given name, along with its quotas and number of jobs.
+func (s *Service) GetNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	s.log.Info("Getting a namespace", zap.String("name", name))
+
+	if err := model.ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetNamespace(ctx, name)
+}
+
+// ListNamespaces returns all namespaces having jobs or quotas.
+func (s *Service) ListNamespaces(ctx context.Context) ([]model.Namespace, error) {
+	s.log.Info("Getting namespaces")
+	return s.store.ListNamespaces(ctx)
+}
+
+// SetNamespaceQuotas sets the quotas of the namespace. Quotas are not applied retroactively:
+// a namespace already exceeding its new max jobs keeps its jobs, but no more can be created.
+func (s *Service) SetNamespaceQuotas(ctx context.Context, name string, quotas model.NamespaceQuotas) (*model.Namespace, error) {
+	s.log.Info("Setting namespace quotas", zap.String("name", name), zap.Any("quotas", quotas))
+
+	if err := model.ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	if err := quotas.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.store.SetNamespaceQuotas(ctx, name, quotas)
+}
+
+// ListNamespaceKeys returns the versions of the data key encrypting the credentials of the namespace.
+func (s *Service) ListNamespaceKeys(ctx context.Context, name string) ([]model.NamespaceKey, error) {
+	s.log.Info("Getting namespace keys", zap.String("name", name))
+
+	if err := model.ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListNamespaceKeys(ctx, name)
+}
+
+// RotateNamespaceKey creates a new version of the data key of the namespace, encrypting its new credentials from now
+// on, and its existing credentials in the background.
+func (s *Service) RotateNamespaceKey(ctx context.Context, name string) (*model.NamespaceKey, error) {
+	s.log.Info("Rotating namespace key", zap.String("name", name))
+
+	if err := model.ValidateNamespace(name); err != nil {
+		return nil, err
+	}
+
+	return s.store.RotateNamespaceKey(ctx, name)
+}
+
+// applyUpdate applies the update of the principal to the job and returns the validation error message, if any.
+func applyUpdate(job *model.Job, update model.JobUpdate, principal model.Principal) string {
+	job.ApplyUpdate(update)
+	if err := principal.AssignJob(job); err != nil {
+		return err.Error()
+	}
+
+	if err := job.Validate(); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+// writeJobs writes the jobs using the given store function, unless any of the items failed.
+func (s *Service) writeJobs(ctx context.Context, jobs []*model.Job, results []model.BulkItemResult, write func(context.Context, []*model.Job) error, eventType model.EventType) (*model.BulkResult, error) {
+	result := model.NewBulkResult(results)
+	if !result.Succeeded {
+		return result, nil
+	}
+
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		if err := write(ctx, jobs); err != nil {
+			return err
+		}
+
+		events := lo.Map(jobs, func(job *model.Job, _ int) model.Event { return model.NewJobEvent(eventType, job) })
+		return s.publish(ctx, events...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.wakeUp(ctx, jobs...)
+
+	return result, nil
+}
+
+// selectJobs executes a bulk operation on the selected jobs and builds the per-item results.
+// The selector is restricted to the namespace of the context, and to the team of its principal if restricted.
+func (s *Service) selectJobs(ctx context.Context, selector model.JobSelector, eventType model.EventType, exec func(context.Context, model.JobSelector) ([]uuid.UUID, error)) (*model.BulkResult, error) {
+	selector.Namespace = model.NamespaceFromContext(ctx)
+	selector.Team = model.PrincipalFromContext(ctx).TeamScope()
+
+	if err := selector.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the changes are rolled back if any of the selected jobs is missing, along with their events
+	var ids []uuid.UUID
+	err := s.store.InTx(ctx, func(ctx context.Context) error {
+		var err error
+		ids, err = exec(ctx, selector)
+		if err != nil {
+			return err
+		}
+
+		// jobs selected by tags are known to have the selector tags
+		events := lo.Map(ids, func(id uuid.UUID, _ int) model.Event {
+			return model.Event{ID: uuid.New(), Type: eventType, Time: time.Now(), JobID: id, Namespace: selector.Namespace, Team: selector.Team, Tags: selector.KnownTags()}
+		})
+		return s.publish(ctx, events...)
+	})
+	if err != nil && !errors.Is(err, errs.ErrJobNotFound) {
+		return nil, err
+	}
+
+	// jobs selected by tags or a tag selector are reported in the order they were affected
+	if len(selector.IDs) == 0 {
+		results := lo.Map(ids, func(id uuid.UUID, _ int) model.BulkItemResult {
+			return model.BulkItemResult{ID: lo.ToPtr(id)}
+		})
+		return model.NewBulkResult(results), nil
+	}
+
+	affected := lo.SliceToMap(ids, func(id uuid.UUID) (uuid.UUID, bool) { return id, true })
+	results := make([]model.BulkItemResult, 0, len(selector.IDs))
+	for i, id := range selector.IDs {
+		result := model.BulkItemResult{Index: lo.ToPtr(i), ID: lo.ToPtr(id)}
+		if !affected[id] {
+			result.Error = errs.ErrJobNotFound.Error()
+		}
+		results = append(results, result)
+	}
+
+	return model.NewBulkResult(results), nil
+}
+
+// checkJobsQuota returns ErrNamespaceQuotaExceeded if adding count jobs to the namespace would exceed its quota.
+// The quota is checked before writing the jobs, so concurrent requests may slightly exceed it.
+func (s *Service) checkJobsQuota(ctx context.Context, name string, count int) error {
+	namespace, err := s.store.GetNamespace(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !namespace.AllowsJobs(count) {
+		return errs.ErrNamespaceQuotaExceeded
+	}
+
+	return nil
+}
+
+// publish publishes job lifecycle events. Called in the transaction of the state change, the events are only
+// delivered if the change is committed, so failing to publish them fails the change.
+func (s *Service) publish(ctx context.Context, events ...model.Event) error {
+	for _, event := range events {
+		if err := s.store.PublishEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to publish %s event of job %s: %w", event.Type, event.JobID, err)
+		}
+	}
+
+	return nil
+}
+
+// wakeUp notifies the runners of the jobs due soon, so they are run without waiting for the next poll
+// of the runners. Wakeups are best-effort, so failures are only logged.
+func (s *Service) wakeUp(ctx context.Context, jobs ...*model.Job) {
+	now := time.Now()
+	for _, job := range jobs {
+		wakeup, ok := model.NewJobWakeup(job, now)
+		if !ok {
+			continue
+		}
+
+		if err := s.store.NotifyJobWakeup(ctx, wakeup); err != nil {
+			s.log.Warn("Failed to notify job wakeup", zap.Any("jobID", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// ListenJobWakeups calls the handler for every job due soon, until the context is cancelled or listening fails.
+func (s *Service) ListenJobWakeups(ctx context.Context, handler func(model.JobWakeup)) error {
+	return s.store.ListenJobWakeups(ctx, handler)
+}
+
+// executionEvent creates the event for a finished execution.
+func executionEvent(job *model.Job, executionID int, status model.JobExecutionStatus, errorMessage null.String) model.Event {
+	eventType := model.EventExecutionFinished
+	switch status {
+	case model.JobExecutionStatusFailed, model.JobExecutionStatusTimedOut:
+		eventType = model.EventExecutionFailed
+	case model.JobExecutionStatusCancelled:
+		eventType = model.EventExecutionCancelled
+	}
+
+	event := model.NewJobEvent(eventType, job)
+	event.Error = errorMessage.String
+	if executionID != 0 {
+		event.ExecutionID = &executionID
+	}
 
-	return s.store.GetJobExecutions(ctx, id, failedOnly, limit, offset)
+	return event
 }
@@ -2,45 +2,139 @@ package job
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database/dbtest"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
 	"github.com/TimeSnap/distributed-scheduler/internal/pkg/tests/docker"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/notification"
+	webhookService "github.com/TimeSnap/distributed-scheduler/internal/service/webhook"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
 	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/sqlite"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
 	"gopkg.in/guregu/null.v4"
 )
 
 var c *docker.Container
 
 func TestMain(m *testing.M) {
+	// the SQLite tests run without the database container
 	var err error
 	c, err = dbtest.StartDB()
 	if err != nil {
 		fmt.Println(err)
-		return
+	} else {
+		defer dbtest.StopDB(c)
 	}
-	defer dbtest.StopDB(c)
 
 	m.Run()
 }
 
+// storeTest owns the stores of a storage backend on an empty database.
+type storeTest struct {
+	DB            *sqlx.DB
+	Store         store.Storer
+	Notifications store.NotificationStorer
+	Webhooks      store.WebhookStorer
+	Log           *otelzap.Logger
+	Teardown      func()
+}
+
+// openStore opens the stores of the storage backend under test on an empty database.
+type openStore func(t *testing.T) *storeTest
+
+// openPostgres opens the Postgres stores on a new database of the container.
+func openPostgres(t *testing.T) *storeTest {
+	test := dbtest.NewTest(t, c)
+
+	return &storeTest{
+		DB:            test.DB,
+		Store:         postgres.New(test.DB, test.Log),
+		Notifications: postgres.NewNotificationStore(test.DB, test.Log),
+		Webhooks:      postgres.NewWebhookStore(test.DB, test.Log),
+		Log:           test.Log,
+		Teardown:      test.Teardown,
+	}
+}
+
+// openSQLite opens the SQLite stores on a new database file.
+func openSQLite(t *testing.T) *storeTest {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatalf("Opening database: %v", err)
+	}
+
+	log := otelzap.New(zap.NewNop())
+
+	return &storeTest{
+		DB:            db,
+		Store:         sqlite.New(db, log),
+		Notifications: sqlite.NewNotificationStore(db, log),
+		Webhooks:      sqlite.NewWebhookStore(db, log),
+		Log:           log,
+		Teardown:      func() { _ = db.Close() },
+	}
+}
+
 func TestIntegration_Job(t *testing.T) {
-	t.Run("crud", crud)
-	t.Run("job_execution", jobExecution)
+	if c == nil {
+		t.Skip("database container not started")
+	}
+
+	testJobs(t, openPostgres)
+}
+
+func TestIntegration_JobSQLite(t *testing.T) {
+	testJobs(t, openSQLite)
+}
+
+func testJobs(t *testing.T, open openStore) {
+	for _, test := range []struct {
+		name string
+		run  func(t *testing.T, open openStore)
+	}{
+		{"crud", crud},
+		{"job_execution", jobExecution},
+		{"priorities", priorities},
+		{"capabilities", capabilities},
+		{"sharding", sharding},
+		{"lookahead", lookahead},
+		{"zombies", zombies},
+		{"bulk", bulk},
+		{"templates", templates},
+		{"events", events},
+		{"notifications", notifications},
+		{"teams", teams},
+		{"sla", slas},
+		{"namespace_keys", namespaceKeys},
+	} {
+		t.Run(test.name, func(t *testing.T) { test.run(t, open) })
+	}
 }
 
-func crud(t *testing.T) {
+func crud(t *testing.T, open openStore) {
 	// Init
 	// -------------------------------------------------------------------------
 
-	test := dbtest.NewTest(t, c)
+	test := open(t)
 	defer func() {
 		if r := recover(); r != nil {
 			t.Log(r)
@@ -49,7 +143,7 @@ func crud(t *testing.T) {
 		test.Teardown()
 	}()
 
-	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+	jobService := NewService(test.Store, test.Log)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -103,14 +197,25 @@ func crud(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	job2, err := jobService.CreateJob(ctx, &model.JobCreate{
-		Type:         model.JobTypeHTTP,
-		CronSchedule: null.StringFrom("@every 1m"),
-		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		Type:             model.JobTypeHTTP,
+		CronSchedule:     null.StringFrom("@every 1m"),
+		HTTPJob:          &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		ExecutionTimeout: null.IntFrom(30),
 	})
 	if err != nil {
 		t.Fatalf("Should be able to create a job: %s", err)
 	}
 
+	job2, err = jobService.GetJob(ctx, job2.ID)
+	if err != nil || job2.ExecutionTimeout.Int64 != 30 {
+		t.Fatalf("Should get back the execution timeout of the job: %v", err)
+	}
+
+	_, err = jobService.UpdateJob(ctx, job2.ID, model.JobUpdate{ExecutionTimeout: lo.ToPtr(int64(0))})
+	if !errors.Is(err, errs.ErrInvalidExecutionTimeout) {
+		t.Fatalf("Should not be able to update a job with an invalid execution timeout: %v", err)
+	}
+
 	if job2.ID == uuid.Nil {
 		t.Fatalf("Should get back an ID: %s", job2.ID)
 	}
@@ -128,28 +233,102 @@ func crud(t *testing.T) {
 		t.Fatalf("Should get back an updated cron schedule: %s", job.CronSchedule.String)
 	}
 
+	if job.Version != 2 {
+		t.Fatalf("Should get back an incremented version: %d", job.Version)
+	}
+
+	// Updates based on a stale version are rejected
+	_, err = jobService.UpdateJob(ctx, job.ID, model.JobUpdate{
+		CronSchedule: lo.ToPtr("@every 3m"),
+		Version:      lo.ToPtr(int64(1)),
+	})
+	if !errors.Is(err, errs.ErrJobVersionMismatch) {
+		t.Fatalf("Should not be able to update a stale job: %v", err)
+	}
+
 	// Get jobs
 	// -------------------------------------------------------------------------
 
-	jobs, err := jobService.ListJobs(ctx, 10, 0, []string{})
+	page, err := jobService.ListJobs(ctx, 10, nil, model.JobFilter{})
 	if err != nil {
 		t.Fatalf("Should be able to list jobs: %s", err)
 	}
 
-	if len(jobs) != 2 {
-		t.Fatalf("Should get back 2 jobs: %d", len(jobs))
+	if len(page.Jobs) != 2 || page.Total != 2 || page.TotalEstimated {
+		t.Fatalf("Should get back 2 jobs, counted exactly: %d", len(page.Jobs))
+	}
+
+	if page.NextCursor.Valid {
+		t.Fatalf("Should not get back a next cursor: %s", page.NextCursor.String)
 	}
 
 	// Get jobs with limit
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.ListJobs(ctx, 1, 0, []string{})
+	page, err = jobService.ListJobs(ctx, 1, nil, model.JobFilter{})
 	if err != nil {
 		t.Fatalf("Should be able to list jobs: %s", err)
 	}
 
-	if len(jobs) != 1 {
-		t.Fatalf("Should get back 1 job: %d", len(jobs))
+	if len(page.Jobs) != 1 {
+		t.Fatalf("Should get back 1 job: %d", len(page.Jobs))
+	}
+
+	// Get the next page
+	// -------------------------------------------------------------------------
+
+	cursor, err := model.DecodeCursor(page.NextCursor.String)
+	if err != nil {
+		t.Fatalf("Should get back a valid cursor: %s", err)
+	}
+
+	nextPage, err := jobService.ListJobs(ctx, 1, cursor, model.JobFilter{})
+	if err != nil {
+		t.Fatalf("Should be able to list jobs: %s", err)
+	}
+
+	if len(nextPage.Jobs) != 1 || nextPage.Jobs[0].ID == page.Jobs[0].ID {
+		t.Fatalf("Should get back the other job: %v", nextPage.Jobs)
+	}
+
+	// List jobs with only some of their fields
+	// -------------------------------------------------------------------------
+
+	sparsePage, err := jobService.ListJobs(ctx, 10, nil, model.JobFilter{Fields: model.JobFields{"id", "status"}})
+	if err != nil {
+		t.Fatalf("Should be able to list jobs with only some of their fields: %s", err)
+	}
+
+	for _, sparseJob := range sparsePage.Jobs {
+		if sparseJob.HTTPJob != nil || sparseJob.AMQPJob != nil {
+			t.Fatalf("Should not get back the job definitions: %+v", sparseJob)
+		}
+	}
+
+	// Clone job
+	// -------------------------------------------------------------------------
+
+	clone, err := jobService.CloneJob(ctx, job.ID, []byte(`{"name": "clone", "tags": ["cloned"]}`))
+	if err != nil {
+		t.Fatalf("Should be able to clone a job: %s", err)
+	}
+
+	if clone.ID == job.ID || clone.Version != 1 || clone.Name.String != "clone" {
+		t.Fatalf("Should get back a new job: %+v", clone)
+	}
+
+	assert.Equal(t, job.CronSchedule, clone.CronSchedule)
+	assert.Equal(t, job.HTTPJob, clone.HTTPJob)
+	assert.Equal(t, []string{"cloned"}, clone.Tags)
+
+	_, err = jobService.CloneJob(ctx, job.ID, []byte(`{"cron_schedule": "invalid"}`))
+	if err == nil {
+		t.Fatalf("Should not be able to clone a job with an invalid override")
+	}
+
+	_, err = jobService.CloneJob(ctx, uuid.New(), nil)
+	if !errors.Is(err, errs.ErrJobNotFound) {
+		t.Fatalf("Should not be able to clone a missing job: %v", err)
 	}
 
 	// Delete job
@@ -167,11 +346,11 @@ func crud(t *testing.T) {
 	}
 }
 
-func jobExecution(t *testing.T) {
+func jobExecution(t *testing.T, open openStore) {
 	// Init
 	// -------------------------------------------------------------------------
 
-	test := dbtest.NewTest(t, c)
+	test := open(t)
 	defer func() {
 		if r := recover(); r != nil {
 			t.Log(r)
@@ -180,7 +359,7 @@ func jobExecution(t *testing.T) {
 		test.Teardown()
 	}()
 
-	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+	jobService := NewService(test.Store, test.Log)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -207,7 +386,7 @@ func jobExecution(t *testing.T) {
 	// Get jobs to run
 	// -------------------------------------------------------------------------
 
-	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", 10)
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{}, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -220,10 +399,14 @@ func jobExecution(t *testing.T) {
 		t.Fatalf("Should get back the correct job: %s", jobs[0].ID)
 	}
 
+	if jobs[0].ReclaimedFrom != "" {
+		t.Fatalf("Should not reclaim an unlocked job: %s", jobs[0].ReclaimedFrom)
+	}
+
 	// Get jobs to run
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.GetJobsToRun(ctx, now.Add(4*time.Second), now.Add(6*time.Second), "instance1", 10)
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(4*time.Second), now.Add(4*time.Second), now.Add(6*time.Second), "instance1", nil, nil, nil, model.JobShard{}, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -236,7 +419,7 @@ func jobExecution(t *testing.T) {
 	// Get jobs to run
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.GetJobsToRun(ctx, now.Add(6*time.Second), now.Add(8*time.Second), "instance2", 10)
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(6*time.Second), now.Add(6*time.Second), now.Add(8*time.Second), "instance2", nil, nil, nil, model.JobShard{}, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -246,19 +429,156 @@ func jobExecution(t *testing.T) {
 		t.Fatalf("Should get back 1 job: %d", len(jobs))
 	}
 
+	if jobs[0].ReclaimedFrom != "instance1" {
+		t.Fatalf("Should reclaim the job from the instance whose lock expired: %q", jobs[0].ReclaimedFrom)
+	}
+
 	if jobs[0].ID != job.ID {
 		t.Fatalf("Should get back the correct job: %s", jobs[0].ID)
 	}
 
+	// Extend the job lock
+	// -------------------------------------------------------------------------
+
+	err = jobService.ExtendJobLock(ctx, job.ID, "instance1", now.Add(20*time.Second))
+	if !errors.Is(err, errs.ErrJobLockLost) {
+		t.Fatalf("Should not be able to extend the lock of another instance: %v", err)
+	}
+
+	err = jobService.ExtendJobLock(ctx, job.ID, "instance2", now.Add(20*time.Second))
+	if err != nil {
+		t.Fatalf("Should be able to extend the job lock: %s", err)
+	}
+
+	lockedJobs, err := jobService.GetJobsToRun(ctx, now.Add(10*time.Second), now.Add(10*time.Second), now.Add(12*time.Second), "instance1", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	// the lock was extended, so the job is still locked
+	if len(lockedJobs) != 0 {
+		t.Fatalf("Should get back 0 jobs: %d", len(lockedJobs))
+	}
+
+	// Interrupt the job execution
+	// -------------------------------------------------------------------------
+
+	err = jobService.InterruptJobExecution(ctx, job, 0, "instance2", now.Add(6*time.Second), now.Add(7*time.Second))
+	if err != nil {
+		t.Fatalf("Should be able to interrupt the job execution: %s", err)
+	}
+
+	// the lock was released without rescheduling the job, so it is picked up again right away
+	lockedJobs, err = jobService.GetJobsToRun(ctx, now.Add(10*time.Second), now.Add(10*time.Second), now.Add(12*time.Second), "instance2", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(lockedJobs) != 1 || !lockedJobs[0].NextRun.Equal(jobs[0].NextRun) {
+		t.Fatalf("Should get back the interrupted job: %d", len(lockedJobs))
+	}
+
 	// complete job
 	// -------------------------------------------------------------------------
 
-	err = jobService.FinishJobExecution(ctx, jobs[0], now.Add(6*time.Second), now.Add(7*time.Second), nil)
+	executionID, err := jobService.StartJobExecution(ctx, jobs[0], now.Add(6*time.Second))
+	if err != nil {
+		t.Fatalf("Should be able to start job execution: %s", err)
+	}
+
+	err = jobService.CancelJobExecution(ctx, executionID)
+	if err != nil {
+		t.Fatalf("Should be able to cancel a running job execution: %s", err)
+	}
+
+	cancelled, err := jobService.IsJobExecutionCancelled(ctx, executionID)
+	if err != nil || !cancelled {
+		t.Fatalf("Job execution should be cancelled: %v", err)
+	}
+
+	cancelledIDs, err := jobService.GetCancelledJobExecutions(ctx, []int{executionID, executionID + 1000})
+	if err != nil || len(cancelledIDs) != 1 || cancelledIDs[0] != executionID {
+		t.Fatalf("Only the cancelled job execution should be returned: %v %v", cancelledIDs, err)
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], executionID, now.Add(6*time.Second), now.Add(7*time.Second), errs.ErrExecutionCancelled)
 	if err != nil {
 		t.Fatalf("Should be able to finish job execution: %s", err)
 	}
 
-	jobs, err = jobService.GetJobsToRun(ctx, now.Add(10*time.Second), now.Add(12*time.Second), "instance2", 10)
+	err = jobService.AddJobExecutionEvents(ctx, model.NewExecutionEvent(executionID, model.ExecutionEventStarted, now.Add(6*time.Second)))
+	if err != nil {
+		t.Fatalf("Should be able to add job execution events: %s", err)
+	}
+
+	timeline, err := jobService.GetJobExecutionEvents(ctx, executionID)
+	if err != nil {
+		t.Fatalf("Should be able to get job execution events: %s", err)
+	}
+
+	// the events recorded by the runner are ordered by time among the others
+	eventTypes := lo.Map(timeline, func(event model.ExecutionEvent, _ int) model.ExecutionEventType { return event.Type })
+	if !assert.ElementsMatch(t, []model.ExecutionEventType{model.ExecutionEventClaimed, model.ExecutionEventStarted, model.ExecutionEventCancelRequested, model.ExecutionEventFinished}, eventTypes) {
+		t.FailNow()
+	}
+	assert.Equal(t, model.ExecutionEventFinished, timeline[len(timeline)-1].Type)
+	assert.Equal(t, model.JobExecutionStatusCancelled, timeline[len(timeline)-1].Status)
+
+	_, err = jobService.GetJobExecutionEvents(ctx, executionID+1000)
+	if !errors.Is(err, errs.ErrExecutionNotFound) {
+		t.Fatalf("Should not get the timeline of a missing job execution: %v", err)
+	}
+
+	err = jobService.SaveJobExecutionLogs(ctx, model.ExecutionLogs{
+		ExecutionID: executionID,
+		Entries:     []model.ExecutionLogEntry{{Time: now, Level: "info", Message: "Sending HTTP request"}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to save job execution logs: %s", err)
+	}
+
+	logs, err := jobService.GetJobExecutionLogs(ctx, job.ID, executionID)
+	if err != nil || len(logs.Entries) != 1 {
+		t.Fatalf("Should get back the job execution logs: %v", err)
+	}
+
+	_, err = jobService.GetJobExecutionOutput(ctx, job.ID, executionID)
+	if !errors.Is(err, errs.ErrExecutionOutputNotFound) {
+		t.Fatalf("Should not get an output of an execution without output: %v", err)
+	}
+
+	err = jobService.SaveJobExecutionOutput(ctx, model.ExecutionOutput{
+		ExecutionID: executionID,
+		ContentType: "application/json",
+		Size:        15,
+		Encoding:    model.ExecutionOutputEncodingZstd,
+		Data:        []byte("compressed"),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to save job execution output: %s", err)
+	}
+
+	output, err := jobService.GetJobExecutionOutput(ctx, job.ID, executionID)
+	if err != nil || output.Size != 15 || string(output.Data) != "compressed" {
+		t.Fatalf("Should get back the job execution output: %v", err)
+	}
+
+	_, err = jobService.GetJobExecutionOutput(ctx, job.ID, executionID+1000)
+	if !errors.Is(err, errs.ErrExecutionNotFound) {
+		t.Fatalf("Should not get the output of a missing job execution: %v", err)
+	}
+
+	err = jobService.CancelJobExecution(ctx, executionID)
+	if !errors.Is(err, errs.ErrExecutionNotRunning) {
+		t.Fatalf("Should not be able to cancel a finished job execution: %v", err)
+	}
+
+	err = jobService.CancelJobExecution(ctx, executionID+1)
+	if !errors.Is(err, errs.ErrExecutionNotFound) {
+		t.Fatalf("Should not be able to cancel a missing job execution: %v", err)
+	}
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(10*time.Second), now.Add(10*time.Second), now.Add(12*time.Second), "instance2", nil, nil, nil, model.JobShard{}, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -271,25 +591,1358 @@ func jobExecution(t *testing.T) {
 	// get job execution
 	// -------------------------------------------------------------------------
 
-	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, nil, false)
 	if err != nil {
 		t.Fatalf("Should be able to get job executions: %s", err)
 	}
 
-	if len(jobExecutions) != 1 {
-		t.Fatalf("Should get back 1 job execution: %d", len(jobExecutions))
+	// the interrupted execution and the cancelled one
+	if len(jobExecutions.Executions) != 2 || jobExecutions.Total != 2 {
+		t.Fatalf("Should get back 2 job executions: %d", len(jobExecutions.Executions))
 	}
 
-	if jobExecutions[0].JobID != job.ID {
-		t.Fatalf("Should get back the correct job execution: %s", jobExecutions[0].JobID)
+	if jobExecutions.Executions[0].JobID != job.ID {
+		t.Fatalf("Should get back the correct job execution: %s", jobExecutions.Executions[0].JobID)
 	}
 
-	jobExecutions, err = jobService.GetJobExecutions(ctx, job.ID, true, 10, 0)
+	if jobExecutions.Executions[0].Status != model.JobExecutionStatusCancelled {
+		t.Fatalf("Job execution should be cancelled: %s", jobExecutions.Executions[0].Status)
+	}
+
+	jobExecutions, err = jobService.GetJobExecutions(ctx, job.ID, true, 10, nil, false)
 	if err != nil {
 		t.Fatalf("Should be able to get job executions: %s", err)
 	}
 
-	if len(jobExecutions) != 0 {
-		t.Fatalf("Should get back 0 failed job executions: %d", len(jobExecutions))
+	if len(jobExecutions.Executions) != 0 {
+		t.Fatalf("Should get back 0 failed job executions: %d", len(jobExecutions.Executions))
+	}
+
+	jobExecutions, err = jobService.GetJobExecutions(ctx, job.ID, false, 10, nil, true)
+	if err != nil || jobExecutions.Total != 2 || jobExecutions.TotalEstimated {
+		t.Fatalf("Should count the job executions exactly: %v", err)
+	}
+
+	latest, err := jobService.GetLatestJobExecutions(ctx, []uuid.UUID{job.ID}, 5)
+	if err != nil || len(latest[job.ID]) != 2 {
+		t.Fatalf("Should get back the latest job executions: %v", err)
+	}
+
+	stats, err := jobService.GetJobExecutionStats(ctx, []uuid.UUID{job.ID, uuid.New()})
+	if err != nil {
+		t.Fatalf("Should be able to get job execution stats: %s", err)
+	}
+
+	if stats[job.ID].Total != 2 || stats[job.ID].Cancelled != 2 || len(stats) != 2 {
+		t.Fatalf("Should get back the job execution stats: %+v", stats)
+	}
+
+	// retry job execution
+	// -------------------------------------------------------------------------
+
+	_, err = jobService.RetryJobExecution(ctx, executionID, "latest")
+	if !errors.Is(err, errs.ErrInvalidRetryDefinition) {
+		t.Fatalf("Should not be able to retry with an unknown definition: %v", err)
+	}
+
+	retry, err := jobService.RetryJobExecution(ctx, executionID, model.RetryDefinitionOriginal)
+	if err != nil {
+		t.Fatalf("Should be able to retry a job execution: %s", err)
+	}
+
+	if retry.Status != model.JobExecutionStatusPending || retry.RetryOf.Int64 != int64(executionID) || retry.JobVersion.Int64 != job.Version {
+		t.Fatalf("Retry should be pending and link to the retried execution: %+v", retry)
+	}
+
+	retries, err := jobService.ClaimJobExecutionRetries(ctx, now.Add(10*time.Second), nil, 10)
+	if err != nil || len(retries) != 1 || retries[0].ExecutionID != retry.ID {
+		t.Fatalf("Should claim the retry: %v", err)
+	}
+
+	if retries[0].Job.HTTPJob == nil || retries[0].Job.HTTPJob.URL != job.HTTPJob.URL {
+		t.Fatalf("Retry should run with the job definition of the execution: %+v", retries[0].Job)
+	}
+
+	retries, err = jobService.ClaimJobExecutionRetries(ctx, now.Add(10*time.Second), nil, 10)
+	if err != nil || len(retries) != 0 {
+		t.Fatalf("Retry should only be claimed once: %v", err)
+	}
+
+	err = jobService.ReleaseJobExecutionRetry(ctx, model.ExecutionRetry{ExecutionID: retry.ID, Job: job})
+	if err != nil {
+		t.Fatalf("Should be able to release the retry: %s", err)
+	}
+
+	retries, err = jobService.ClaimJobExecutionRetries(ctx, now.Add(10*time.Second), nil, 10)
+	if err != nil || len(retries) != 1 || retries[0].ExecutionID != retry.ID {
+		t.Fatalf("Should claim the released retry again: %v", err)
+	}
+
+	err = jobService.FinishJobExecutionRetry(ctx, model.ExecutionRetry{ExecutionID: retry.ID, Job: job}, now.Add(11*time.Second), nil)
+	if err != nil {
+		t.Fatalf("Should be able to finish the retry: %s", err)
+	}
+
+	_, err = jobService.RetryJobExecution(ctx, executionID+10, model.RetryDefinitionCurrent)
+	if !errors.Is(err, errs.ErrExecutionNotFound) {
+		t.Fatalf("Should not be able to retry a missing job execution: %v", err)
+	}
+
+	// run job now
+	// -------------------------------------------------------------------------
+
+	run, err := jobService.RunJob(ctx, job.ID, nil)
+	if err != nil {
+		t.Fatalf("Should be able to run a job: %s", err)
+	}
+
+	if run.Status != model.JobExecutionStatusPending || run.RetryOf.Valid || run.JobVersion.Int64 != job.Version {
+		t.Fatalf("Run should be pending with the current job definition: %+v", run)
+	}
+
+	_, err = jobService.RunJob(ctx, uuid.New(), nil)
+	if !errors.Is(err, errs.ErrJobNotFound) {
+		t.Fatalf("Should not be able to run a missing job: %v", err)
+	}
+
+	overrides := &model.JobRunOverrides{HTTP: &model.HTTPRunOverrides{Body: null.StringFrom(`{"replay": true}`)}}
+	overriddenRun, err := jobService.RunJob(ctx, job.ID, overrides)
+	if err != nil || !overriddenRun.Overridden {
+		t.Fatalf("Should be able to run a job with overrides: %v", err)
+	}
+
+	retries, err = jobService.ClaimJobExecutionRetries(ctx, time.Now().Add(10*time.Second), nil, 10)
+	if err != nil || len(retries) != 2 || retries[1].ExecutionID != overriddenRun.ID {
+		t.Fatalf("Should claim the manual runs: %v", err)
+	}
+
+	if retries[1].Job.HTTPJob.Body.String != `{"replay": true}` || retries[0].Job.HTTPJob.Body.Valid {
+		t.Fatalf("Overrides should only apply to their run: %+v", retries[1].Job.HTTPJob)
+	}
+
+	_, err = jobService.RunJob(ctx, job.ID, &model.JobRunOverrides{Parameters: map[string]string{"customer": "acme"}})
+	if !errors.Is(err, errs.ErrInvalidRunOverrides) {
+		t.Fatalf("Should not be able to render a job without a template: %v", err)
+	}
+
+	// export executions
+	// -------------------------------------------------------------------------
+
+	var exported []*model.JobExecution
+	exportRange := model.ExecutionExportRange{From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour)}
+	err = jobService.ExportJobExecutions(ctx, job.ID, exportRange, func(execution *model.JobExecution) error {
+		exported = append(exported, execution)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Should be able to export the executions: %s", err)
+	}
+
+	// the executions of the job started ahead of the clock, so the manual run isn't the last one
+	startTimes := lo.Map(exported, func(execution *model.JobExecution, _ int) time.Time { return execution.StartTime })
+	if !slices.IsSortedFunc(startTimes, time.Time.Compare) || !lo.ContainsBy(exported, func(execution *model.JobExecution) bool { return execution.ID == overriddenRun.ID }) {
+		t.Fatalf("Should export the executions oldest first: %+v", exported)
+	}
+
+	err = jobService.ExportJobExecutions(ctx, job.ID, model.ExecutionExportRange{From: exportRange.To, To: exportRange.From}, nil)
+	if !errors.Is(err, errs.ErrInvalidExportRange) {
+		t.Fatalf("Should not be able to export an empty range: %v", err)
+	}
+}
+
+func priorities(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	_, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(now.Add(time.Second)),
+		HTTPJob:   &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		Priority:  "URGENT",
+	})
+	if !errors.Is(err, errs.ErrInvalidJobPriority) {
+		t.Fatalf("Should not be able to create a job with an invalid priority: %v", err)
+	}
+
+	// the low-priority jobs are due first
+	var created []*model.Job
+	for i, priority := range []model.JobPriority{model.JobPriorityLow, model.JobPriorityLow, model.JobPriorityHigh, model.JobPriorityHigh, model.JobPriorityHigh, model.JobPriorityHigh} {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:      model.JobTypeHTTP,
+			ExecuteAt: null.TimeFrom(now.Add(time.Second + time.Duration(i)*time.Millisecond)),
+			HTTPJob:   &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Priority:  priority,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+		created = append(created, job)
+	}
+
+	// Get jobs to run
+	// -------------------------------------------------------------------------
+
+	ids := func(jobs []*model.Job) []uuid.UUID {
+		return lo.Map(jobs, func(job *model.Job, _ int) uuid.UUID { return job.ID })
+	}
+
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{}, 3)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	// high-priority jobs are started first
+	assert.Equal(t, ids(created[2:5]), ids(jobs))
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	// low-priority jobs still get their share
+	assert.Equal(t, ids([]*model.Job{created[5], created[0], created[1]}), ids(jobs))
+	assert.Equal(t, model.JobPriorityLow, jobs[1].Priority)
+}
+
+func capabilities(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	_, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:                 model.JobTypeHTTP,
+		ExecuteAt:            null.TimeFrom(now.Add(time.Second)),
+		HTTPJob:              &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		RequiredCapabilities: []string{"region=eu west"},
+	})
+	if !errors.Is(err, errs.ErrInvalidCapabilities) {
+		t.Fatalf("Should not be able to create a job with invalid capabilities: %v", err)
+	}
+
+	var created []*model.Job
+	for _, required := range [][]string{nil, {"region=eu"}, {"region=eu", "network=dmz"}} {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:                 model.JobTypeHTTP,
+			ExecuteAt:            null.TimeFrom(now.Add(time.Second)),
+			HTTPJob:              &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			RequiredCapabilities: required,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+		created = append(created, job)
+	}
+
+	// Get jobs to run
+	// -------------------------------------------------------------------------
+
+	ids := func(jobs []*model.Job) []uuid.UUID {
+		return lo.Map(jobs, func(job *model.Job, _ int) uuid.UUID { return job.ID })
+	}
+
+	// a runner without capabilities only gets the jobs without requirements
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Equal(t, ids(created[:1]), ids(jobs))
+
+	// a runner must have all the required capabilities
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance2", nil, nil, []string{"region=eu", "gpu"}, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Equal(t, ids(created[1:2]), ids(jobs))
+	assert.Equal(t, []string{"region=eu"}, jobs[0].RequiredCapabilities)
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance3", nil, nil, []string{"network=dmz", "region=eu"}, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Equal(t, ids(created[2:]), ids(jobs))
+}
+
+func sharding(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	var created []uuid.UUID
+	for i := 0; i < 20; i++ {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:      model.JobTypeHTTP,
+			ExecuteAt: null.TimeFrom(now.Add(time.Second)),
+			HTTPJob:   &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+		created = append(created, job.ID)
+	}
+
+	// Get jobs to run
+	// -------------------------------------------------------------------------
+
+	ids := func(jobs []*model.Job) []uuid.UUID {
+		return lo.Map(jobs, func(job *model.Job, _ int) uuid.UUID { return job.ID })
+	}
+
+	// each runner only gets the jobs of its shard
+	first, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{Index: 0, Count: 2, Grace: time.Hour}, 20)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Less(t, len(first), len(created))
+
+	second, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance2", nil, nil, nil, model.JobShard{Index: 1, Count: 2, Grace: time.Hour}, 20)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.ElementsMatch(t, created, append(ids(first), ids(second)...))
+
+	// the overdue jobs of other shards are taken once the grace period elapsed
+	err = jobService.ReleaseJobLock(ctx, second[0].ID, "instance2")
+	if err != nil {
+		t.Fatalf("Should be able to release the job lock: %s", err)
+	}
+
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{Index: 0, Count: 2, Grace: time.Hour}, 20)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Empty(t, jobs)
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, nil, nil, model.JobShard{Index: 0, Count: 2, Grace: time.Millisecond * 500}, 20)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Equal(t, ids(second[:1]), ids(jobs))
+}
+
+func lookahead(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	_, err := jobService.SetNamespaceQuotas(ctx, model.DefaultNamespace, model.NamespaceQuotas{MaxConcurrentExecutions: null.IntFrom(1)})
+	if err != nil {
+		t.Fatalf("Should be able to set the namespace quotas: %s", err)
+	}
+
+	running, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(now.Add(time.Second)),
+		HTTPJob:   &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// Get jobs to run
+	// -------------------------------------------------------------------------
+
+	// the first runner locks the job for a minute
+	jobs, err := jobService.GetJobsToRun(ctx, now, now.Add(2*time.Second), now.Add(time.Minute), "instance1", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Len(t, jobs, 1)
+
+	_, err = jobService.CreateJob(ctx, &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(now.Add(30 * time.Second)),
+		HTTPJob:   &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// a runner fetching the jobs due within its lookahead neither takes the job locked by the first runner, nor
+	// exceeds the quota of the namespace that job takes
+	jobs, err = jobService.GetJobsToRun(ctx, now, now.Add(90*time.Second), now.Add(2*time.Minute), "instance2", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Empty(t, jobs)
+
+	// once the lock lapsed, the locked job is reclaimed
+	later := now.Add(time.Minute)
+	jobs, err = jobService.GetJobsToRun(ctx, later, later.Add(90*time.Second), later.Add(2*time.Minute), "instance2", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, running.ID, jobs[0].ID)
+		assert.Equal(t, "instance1", jobs[0].ReclaimedFrom)
+	}
+}
+
+func zombies(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	jobs := map[model.MisfirePolicy]*model.Job{}
+	for _, policy := range []model.MisfirePolicy{model.MisfirePolicyRunOnce, model.MisfirePolicySkip} {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:          model.JobTypeHTTP,
+			CronSchedule:  null.StringFrom("@every 1m"),
+			MisfirePolicy: policy,
+			HTTPJob:       &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+		jobs[policy] = job
+	}
+
+	// Start executions with a lock that lapses right away, as if the runner crashed
+	// -------------------------------------------------------------------------
+
+	at := now.Add(2 * time.Minute)
+	fetched, err := jobService.GetJobsToRun(ctx, at, at, now, "instance1", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Len(t, fetched, 2)
+
+	for _, job := range fetched {
+		if _, err := jobService.StartJobExecution(ctx, job, now); err != nil {
+			t.Fatalf("Should be able to start a job execution: %s", err)
+		}
+	}
+
+	// the job skipping misfires is left to the reaper
+	fetched, err = jobService.GetJobsToRun(ctx, at, at, at, "instance2", nil, nil, nil, model.JobShard{}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+	assert.Equal(t, []uuid.UUID{jobs[model.MisfirePolicyRunOnce].ID}, lo.Map(fetched, func(job *model.Job, _ int) uuid.UUID { return job.ID }))
+
+	// Reap the executions
+	// -------------------------------------------------------------------------
+
+	// executions whose lock expired within the grace period are left alone
+	reaped, err := jobService.ReapZombieJobExecutions(ctx, now.Add(-time.Minute), 10)
+	if err != nil {
+		t.Fatalf("Should be able to reap zombie executions: %s", err)
+	}
+	assert.Empty(t, reaped)
+
+	reaped, err = jobService.ReapZombieJobExecutions(ctx, now.Add(time.Second), 10)
+	if err != nil {
+		t.Fatalf("Should be able to reap zombie executions: %s", err)
+	}
+	assert.Len(t, reaped, 2)
+
+	for _, job := range jobs {
+		page, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, nil, false)
+		if err != nil {
+			t.Fatalf("Should be able to get the job executions: %s", err)
+		}
+		assert.Len(t, page.Executions, 1)
+		assert.Equal(t, model.JobExecutionStatusFailed, page.Executions[0].Status)
+		assert.Equal(t, errs.ErrExecutionAbandoned.Error(), page.Executions[0].ErrorMessage.String)
+	}
+
+	// the job skipping misfires moved to its next occurrence, the other one is still due
+	skipped, err := jobService.GetJob(ctx, jobs[model.MisfirePolicySkip].ID)
+	if err != nil {
+		t.Fatalf("Should be able to get a job: %s", err)
+	}
+	assert.True(t, skipped.NextRun.Time.After(jobs[model.MisfirePolicySkip].NextRun.Time))
+
+	runOnce, err := jobService.GetJob(ctx, jobs[model.MisfirePolicyRunOnce].ID)
+	if err != nil {
+		t.Fatalf("Should be able to get a job: %s", err)
+	}
+	assert.WithinDuration(t, jobs[model.MisfirePolicyRunOnce].NextRun.Time, runOnce.NextRun.Time, time.Millisecond)
+}
+
+func bulk(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	newJob := func(tags ...string) model.JobCreate {
+		return model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("@every 1m"),
+			HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Tags:         tags,
+		}
+	}
+
+	// Create jobs, an invalid job rolls back the whole batch
+	// -------------------------------------------------------------------------
+
+	invalid := newJob("bulk")
+	invalid.CronSchedule = null.StringFrom("invalid")
+
+	result, err := jobService.CreateJobs(ctx, model.BulkJobCreate{Jobs: []model.JobCreate{newJob("bulk"), invalid}})
+	assert.NoError(t, err)
+	assert.False(t, result.Succeeded)
+	assert.Empty(t, result.Results[0].Error)
+	assert.NotEmpty(t, result.Results[1].Error)
+
+	page, err := jobService.ListJobs(ctx, 10, nil, model.JobFilter{Tags: []string{"bulk"}})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Jobs)
+
+	result, err = jobService.CreateJobs(ctx, model.BulkJobCreate{Jobs: []model.JobCreate{newJob("bulk"), newJob("bulk", "other")}})
+	assert.NoError(t, err)
+	assert.True(t, result.Succeeded)
+	assert.Len(t, result.Results, 2)
+
+	// Pause jobs by tag
+	// -------------------------------------------------------------------------
+
+	result, err = jobService.PauseJobs(ctx, model.JobSelector{Tags: []string{"bulk"}})
+	assert.NoError(t, err)
+	assert.True(t, result.Succeeded)
+	assert.Len(t, result.Results, 2)
+
+	page, err = jobService.ListJobs(ctx, 10, nil, model.JobFilter{Statuses: []model.JobStatus{model.JobStatusStopped}})
+	assert.NoError(t, err)
+	assert.Len(t, page.Jobs, 2)
+
+	// Update jobs by ID
+	// -------------------------------------------------------------------------
+
+	id, other := *result.Results[0].ID, *result.Results[1].ID
+	result, err = jobService.UpdateJobs(ctx, model.BulkJobUpdate{Jobs: []model.BulkJobUpdateItem{
+		{ID: id, JobUpdate: model.JobUpdate{CronSchedule: lo.ToPtr("@every 5m")}},
+	}})
+	assert.NoError(t, err)
+	assert.True(t, result.Succeeded)
+
+	job, err := jobService.GetJob(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "@every 5m", job.CronSchedule.String)
+
+	// Get jobs by ID, in the requested order
+	// -------------------------------------------------------------------------
+
+	missing := uuid.New()
+	batch, err := jobService.BatchGetJobs(ctx, model.JobBatchGet{IDs: []uuid.UUID{other, missing, id}})
+	assert.NoError(t, err)
+	assert.Len(t, batch.Jobs, 2)
+	assert.Equal(t, other, batch.Jobs[0].ID)
+	assert.Equal(t, id, batch.Jobs[1].ID)
+	assert.Equal(t, []uuid.UUID{missing}, batch.NotFound)
+
+	_, err = jobService.BatchGetJobs(ctx, model.JobBatchGet{})
+	assert.ErrorIs(t, err, errs.ErrInvalidBulkRequest)
+
+	// Rename a tag into a label, and select jobs by labels
+	// -------------------------------------------------------------------------
+
+	rename, err := jobService.RenameTag(ctx, model.TagRename{From: "other", To: "team=payments"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rename.Renamed)
+
+	tags, err := jobService.ListTags(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TagCount{
+		{Tag: "bulk", Label: model.Label{Key: "bulk"}, Count: 2},
+		{Tag: "team=payments", Label: model.Label{Key: "team", Value: "payments"}, Count: 1},
+	}, tags)
+
+	selector, err := model.ParseTagSelector("bulk AND team=payments")
+	assert.NoError(t, err)
+
+	page, err = jobService.ListJobs(ctx, 10, nil, model.JobFilter{Selector: selector})
+	assert.NoError(t, err)
+	assert.Len(t, page.Jobs, 1)
+
+	result, err = jobService.ResumeJobs(ctx, model.JobSelector{Selector: "bulk AND !team"})
+	assert.NoError(t, err)
+	assert.True(t, result.Succeeded)
+	assert.Len(t, result.Results, 1)
+
+	// Select jobs by metadata
+	// -------------------------------------------------------------------------
+
+	metadata := map[string]string{"cost-center": "research", "runbook": "https://wiki.example.com/bulk"}
+	job, err = jobService.UpdateJob(ctx, id, model.JobUpdate{Metadata: &metadata})
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, job.Metadata)
+
+	selector, err = model.ParseTagSelector("bulk AND metadata.cost-center=research AND metadata.runbook")
+	assert.NoError(t, err)
+
+	page, err = jobService.ListJobs(ctx, 10, nil, model.JobFilter{Selector: selector})
+	assert.NoError(t, err)
+	assert.Len(t, page.Jobs, 1)
+	assert.Equal(t, id, page.Jobs[0].ID)
+
+	_, err = jobService.UpdateJob(ctx, id, model.JobUpdate{Metadata: &map[string]string{"cost center": "research"}})
+	assert.ErrorIs(t, err, errs.ErrInvalidJobMetadata)
+
+	// Delete jobs, a missing job rolls back the whole batch
+	// -------------------------------------------------------------------------
+
+	result, err = jobService.DeleteJobs(ctx, model.JobSelector{IDs: []uuid.UUID{id, uuid.New()}})
+	assert.NoError(t, err)
+	assert.False(t, result.Succeeded)
+	assert.NotEmpty(t, result.Results[1].Error)
+
+	_, err = jobService.GetJob(ctx, id)
+	assert.NoError(t, err)
+
+	result, err = jobService.DeleteJobs(ctx, model.JobSelector{Tags: []string{"bulk"}})
+	assert.NoError(t, err)
+	assert.True(t, result.Succeeded)
+	assert.Len(t, result.Results, 2)
+}
+
+func templates(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	// template definitions are encrypted at rest
+	codec.SetEncryptor(security.NewEncryptor("testkey123456789"))
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create template
+	// -------------------------------------------------------------------------
+
+	template, err := jobService.CreateTemplate(ctx, &model.JobTemplateCreate{
+		Name:       "customer-sync",
+		Parameters: []model.TemplateParameter{{Name: "customer"}, {Name: "schedule", Default: null.StringFrom("@every 1h")}},
+		Definition: []byte(`{
+			"name": "sync-{{customer}}",
+			"type": "HTTP",
+			"cron_schedule": "{{schedule}}",
+			"http_job": {"url": "https://example.com/{{customer}}", "method": "POST", "auth": {"type": "bearer", "bearer_token": "secret"}}
+		}`),
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a template: %s", err)
+	}
+
+	_, err = jobService.CreateTemplate(ctx, &model.JobTemplateCreate{Name: template.Name, Definition: []byte(`{}`)})
+	if !errors.Is(err, errs.ErrTemplateNameTaken) {
+		t.Fatalf("Should not be able to create a template with the same name: %v", err)
+	}
+
+	templates, err := jobService.ListTemplates(ctx)
+	if err != nil || len(templates) != 1 {
+		t.Fatalf("Should be able to list templates: %v %v", templates, err)
+	}
+
+	// Create jobs from the template
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJobFromTemplate(ctx, model.JobFromTemplate{Template: "customer-sync", Parameters: map[string]string{"customer": "acme"}})
+	if err != nil {
+		t.Fatalf("Should be able to create a job from a template: %s", err)
+	}
+
+	assert.Equal(t, "sync-acme", job.Name.String)
+	assert.Equal(t, "@every 1h", job.CronSchedule.String)
+	assert.Equal(t, "https://example.com/acme", job.HTTPJob.URL)
+	assert.Equal(t, "secret", job.HTTPJob.Auth.BearerToken.String)
+	assert.Equal(t, []string{"template=customer-sync"}, job.Tags)
+
+	_, err = jobService.CreateJobFromTemplate(ctx, model.JobFromTemplate{Template: "customer-sync"})
+	if !errors.Is(err, errs.ErrInvalidTemplateParameters) {
+		t.Fatalf("Should not be able to create a job without the required parameters: %v", err)
+	}
+
+	_, err = jobService.CreateJobFromTemplate(ctx, model.JobFromTemplate{Template: "missing"})
+	if !errors.Is(err, errs.ErrTemplateNotFound) {
+		t.Fatalf("Should not be able to create a job from a missing template: %v", err)
+	}
+
+	// Update and delete the template
+	// -------------------------------------------------------------------------
+
+	_, err = jobService.UpdateTemplate(ctx, "customer-sync", &model.JobTemplateCreate{Definition: []byte(`{"type": "HTTP"}`)})
+	if err != nil {
+		t.Fatalf("Should be able to update a template: %s", err)
+	}
+
+	err = jobService.DeleteTemplate(ctx, "customer-sync")
+	if err != nil {
+		t.Fatalf("Should be able to delete a template: %s", err)
+	}
+
+	_, err = jobService.GetTemplate(ctx, "customer-sync")
+	if !errors.Is(err, errs.ErrTemplateNotFound) {
+		t.Fatalf("Should not be able to get a deleted template: %v", err)
+	}
+
+	// jobs instantiated from the template are kept
+	if _, err := jobService.GetJob(ctx, job.ID); err != nil {
+		t.Fatalf("Should still be able to get the job: %s", err)
+	}
+}
+
+func events(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := test.Store
+	jobService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Listen for events
+	// -------------------------------------------------------------------------
+
+	received := make(chan model.Event, 10)
+	go func() {
+		_ = store.ListenEvents(ctx, func(event model.Event) {
+			received <- event
+		})
+	}()
+
+	wakeups := make(chan model.JobWakeup, 10)
+	go func() {
+		_ = jobService.ListenJobWakeups(ctx, func(wakeup model.JobWakeup) {
+			wakeups <- wakeup
+		})
+	}()
+
+	// wait for the listener to start listening
+	time.Sleep(time.Second)
+
+	// Create a job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1m"),
+		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		Tags:         []string{"events"},
+	})
+	assert.NoError(t, err)
+
+	// The event is broadcast once relayed from the outbox
+	var relayed []model.Event
+	count, err := store.RelayEvents(ctx, 10, func(_ context.Context, events []model.Event) error {
+		relayed = append(relayed, events...)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Len(t, relayed, 1)
+
+	select {
+	case event := <-received:
+		assert.Equal(t, model.EventJobCreated, event.Type)
+		assert.Equal(t, job.ID, event.JobID)
+		assert.Equal(t, []string{"events"}, event.Tags)
+	case <-ctx.Done():
+		t.Fatal("Should receive the job created event")
+	}
+
+	// The job is due within a minute, so the runners are woken up
+	select {
+	case wakeup := <-wakeups:
+		assert.Equal(t, job.ID, wakeup.JobID)
+		assert.Equal(t, model.DefaultNamespace, wakeup.Namespace)
+		assert.WithinDuration(t, job.NextRun.Time, wakeup.NextRun, time.Millisecond)
+	case <-ctx.Done():
+		t.Fatal("Should receive the job wakeup")
+	}
+
+	// The events of rolled back changes are never relayed
+	// -------------------------------------------------------------------------
+
+	err = store.InTx(ctx, func(ctx context.Context) error {
+		if err := jobService.DeleteJob(ctx, job.ID); err != nil {
+			return err
+		}
+
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+
+	_, err = jobService.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+
+	count, err = store.RelayEvents(ctx, 10, func(context.Context, []model.Event) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// The events are left in the outbox if they fail to be relayed
+	// -------------------------------------------------------------------------
+
+	assert.NoError(t, jobService.DeleteJob(ctx, job.ID))
+
+	_, err = store.RelayEvents(ctx, 10, func(context.Context, []model.Event) error { return errors.New("unavailable") })
+	assert.Error(t, err)
+
+	count, err = store.RelayEvents(ctx, 10, func(context.Context, []model.Event) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func notifications(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := test.Store
+	jobService := NewService(store, test.Log)
+	notificationService := notification.NewService(test.Notifications, store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1m"),
+		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		Tags:         []string{"team=payments"},
+	})
+	assert.NoError(t, err)
+
+	rule, err := notificationService.CreateNotificationRule(ctx, &model.NotificationRuleCreate{
+		Channel: model.NotificationChannelSlack,
+		URL:     "https://hooks.slack.com/services/T0/B0/X",
+		Tags:    []string{"team=payments"},
+	})
+	assert.NoError(t, err)
+
+	// relay relays the outbox through the auto-disabling and the notifications, like the manager
+	relay := func() {
+		for {
+			count, err := store.RelayEvents(ctx, 10, func(ctx context.Context, events []model.Event) error {
+				for _, event := range events {
+					if err := jobService.DisableFailingJob(ctx, event, 3); err != nil {
+						return err
+					}
+					if _, err := notificationService.EnqueueNotifications(ctx, event); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+			if count == 0 {
+				return
+			}
+		}
+	}
+
+	triggers := func() []model.NotificationTrigger {
+		page, err := notificationService.GetNotifications(ctx, rule.ID, 100, nil)
+		assert.NoError(t, err)
+
+		var triggers []model.NotificationTrigger
+		for _, notification := range page.Notifications {
+			triggers = append(triggers, notification.Trigger)
+		}
+		return triggers
+	}
+
+	// The first failure is notified, the following ones are throttled
+	// -------------------------------------------------------------------------
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		err = jobService.FinishJobExecution(ctx, job, 0, now, now.Add(time.Second), errors.New("unexpected status code 503"))
+		assert.NoError(t, err)
+	}
+	relay()
+
+	assert.Equal(t, []model.NotificationTrigger{model.NotificationTriggerFailure}, triggers())
+
+	// A success recovers the job
+	// -------------------------------------------------------------------------
+
+	assert.NoError(t, jobService.FinishJobExecution(ctx, job, 0, now, now.Add(time.Second), nil))
+	relay()
+
+	assert.Equal(t, []model.NotificationTrigger{model.NotificationTriggerRecovery, model.NotificationTriggerFailure}, triggers())
+
+	// The job is disabled after failing 3 times in a row
+	// -------------------------------------------------------------------------
+
+	for i := 0; i < 3; i++ {
+		err = jobService.FinishJobExecution(ctx, job, 0, now, now.Add(time.Second), errors.New("unexpected status code 503"))
+		assert.NoError(t, err)
+	}
+	relay()
+
+	disabled, err := jobService.GetJob(ctx, job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.JobStatusStopped, disabled.Status)
+
+	assert.Equal(t, []model.NotificationTrigger{
+		model.NotificationTriggerDisabled,
+		model.NotificationTriggerRecovery,
+		model.NotificationTriggerFailure,
+	}, triggers())
+
+	// The notifications are claimed once
+	// -------------------------------------------------------------------------
+
+	claimed, err := notificationService.ClaimNotifications(ctx, time.Now(), time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 3)
+
+	claimed, err = notificationService.ClaimNotifications(ctx, time.Now(), time.Now().Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func teams(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	// webhook secrets are encrypted at rest
+	codec.SetEncryptor(security.NewEncryptor("testkey123456789"))
+
+	store := test.Store
+	jobService := NewService(store, test.Log)
+	notificationService := notification.NewService(test.Notifications, store, test.Log)
+	webhooks := webhookService.NewService(test.Webhooks, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payments := model.WithPrincipal(ctx, model.Principal{Name: "payments-ci", Team: "payments", Restricted: true})
+
+	createJob := func(team string) *model.Job {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("@every 1m"),
+			HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Team:         null.StringFrom(team),
+		})
+		assert.NoError(t, err)
+		return job
+	}
+	paymentsJob := createJob("payments")
+	searchJob := createJob("search")
+
+	// Notification rules of restricted principals are bound to their team
+	// -------------------------------------------------------------------------
+
+	ruleCreate := func(jobID *uuid.UUID) *model.NotificationRuleCreate {
+		return &model.NotificationRuleCreate{
+			Channel: model.NotificationChannelSlack,
+			URL:     "https://hooks.slack.com/services/T0/B0/X",
+			JobID:   jobID,
+		}
+	}
+
+	_, err := notificationService.CreateNotificationRule(payments, ruleCreate(&searchJob.ID))
+	assert.ErrorIs(t, err, errs.ErrJobNotFound)
+
+	jobRule, err := notificationService.CreateNotificationRule(payments, ruleCreate(&paymentsJob.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, null.StringFrom("payments"), jobRule.Team)
+
+	teamRule, err := notificationService.CreateNotificationRule(payments, ruleCreate(nil))
+	assert.NoError(t, err)
+
+	adminRule, err := notificationService.CreateNotificationRule(ctx, ruleCreate(nil))
+	assert.NoError(t, err)
+	assert.False(t, adminRule.Team.Valid)
+
+	rules, err := notificationService.ListNotificationRules(payments)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{jobRule.ID, teamRule.ID}, lo.Map(rules, func(rule model.NotificationRule, _ int) uuid.UUID { return rule.ID }))
+
+	rules, err = notificationService.ListNotificationRules(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 3)
+
+	_, err = notificationService.GetNotificationRule(payments, adminRule.ID)
+	assert.ErrorIs(t, err, errs.ErrNotificationRuleNotFound)
+
+	// only the rules without a team notify of the jobs of other teams
+	rules, err = test.Notifications.GetEventNotificationRules(ctx, model.NewJobEvent(model.EventExecutionFailed, searchJob))
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{adminRule.ID}, lo.Map(rules, func(rule model.NotificationRule, _ int) uuid.UUID { return rule.ID }))
+
+	rules, err = test.Notifications.GetEventNotificationRules(ctx, model.NewJobEvent(model.EventExecutionFailed, paymentsJob))
+	assert.NoError(t, err)
+	assert.Len(t, rules, 3)
+
+	// Webhooks of restricted principals are bound to their team
+	// -------------------------------------------------------------------------
+
+	webhookCreate := &model.WebhookCreate{URL: "https://example.com/hooks", Secret: "0123456789abcdef"}
+
+	teamWebhook, err := webhooks.CreateWebhook(payments, webhookCreate)
+	assert.NoError(t, err)
+	assert.Equal(t, null.StringFrom("payments"), teamWebhook.Team)
+
+	adminWebhook, err := webhooks.CreateWebhook(ctx, webhookCreate)
+	assert.NoError(t, err)
+
+	list, err := webhooks.ListWebhooks(payments)
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{teamWebhook.ID}, lo.Map(list, func(webhook model.Webhook, _ int) uuid.UUID { return webhook.ID }))
+
+	list, err = webhooks.ListWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	_, err = webhooks.GetWebhook(payments, adminWebhook.ID)
+	assert.ErrorIs(t, err, errs.ErrWebhookNotFound)
+
+	// only the webhooks without a team receive the events of the jobs of other teams
+	count, err := webhooks.EnqueueDeliveries(ctx, model.NewJobEvent(model.EventJobUpdated, searchJob))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = webhooks.EnqueueDeliveries(ctx, model.NewJobEvent(model.EventJobUpdated, paymentsJob))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func slas(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := test.Store
+	jobService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1m"),
+		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		SLA:          &model.JobSLA{MaxDelay: null.IntFrom(60), MinSuccessRate: null.FloatFrom(75)},
+	})
+	assert.NoError(t, err)
+
+	// A job without executions meets its SLA
+	// -------------------------------------------------------------------------
+
+	at := job.NextRun.Time.Add(time.Hour)
+	status, err := jobService.GetJobSLAStatus(ctx, job.ID, at)
+	assert.NoError(t, err)
+	assert.True(t, status.Compliant)
+	assert.False(t, status.SuccessRate.Valid)
+
+	// A failure breaches the success rate, a late execution the max delay
+	// -------------------------------------------------------------------------
+
+	scheduled := job.NextRun.Time
+	assert.NoError(t, jobService.FinishJobExecution(ctx, job, 0, scheduled, scheduled.Add(time.Second*10), nil))
+
+	scheduled = job.NextRun.Time
+	assert.NoError(t, jobService.FinishJobExecution(ctx, job, 0, scheduled, scheduled.Add(time.Second*10), errors.New("unexpected status code 503")))
+
+	scheduled = job.NextRun.Time
+	assert.NoError(t, jobService.FinishJobExecution(ctx, job, 0, scheduled, scheduled.Add(time.Minute*2), nil))
+
+	status, err = jobService.GetJobSLAStatus(ctx, job.ID, at)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), status.Executions)
+	assert.Equal(t, uint64(2), status.Successful)
+	assert.Equal(t, uint64(1), status.Late)
+	assert.False(t, status.Compliant)
+	assert.Equal(t, []model.SLAObjective{model.SLAObjectiveMaxDelay, model.SLAObjectiveMinSuccessRate}, status.Breaches)
+
+	// The executions out of the window are ignored
+	status, err = jobService.GetJobSLAStatus(ctx, job.ID, at.Add(model.DefaultSLAWindow))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), status.Executions)
+	assert.True(t, status.Compliant)
+
+	// The SLA is part of the stats of the job
+	// -------------------------------------------------------------------------
+
+	stats, err := jobService.GetJobExecutionStats(ctx, []uuid.UUID{job.ID})
+	assert.NoError(t, err)
+	assert.NotNil(t, stats[job.ID].SLA)
+
+	// Breaches are published as events
+	// -------------------------------------------------------------------------
+
+	jobs, err := jobService.GetSLAJobs(ctx, uuid.Nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	statuses, err := jobService.GetJobSLAStatuses(ctx, at, []uuid.UUID{job.ID})
+	assert.NoError(t, err)
+	assert.NoError(t, jobService.ReportSLABreaches(ctx, model.SLABreach{Job: jobs[0], Status: statuses[0]}))
+
+	var relayed []model.Event
+	_, err = store.RelayEvents(ctx, 100, func(ctx context.Context, events []model.Event) error {
+		relayed = append(relayed, events...)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	breached, ok := lo.Find(relayed, func(event model.Event) bool {
+		return event.Type == model.EventJobSLABreached
+	})
+	assert.True(t, ok)
+	assert.Equal(t, []model.SLAObjective{model.SLAObjectiveMaxDelay, model.SLAObjectiveMinSuccessRate}, breached.SLA.Breaches)
+
+	// An empty SLA removes the SLA of the job
+	// -------------------------------------------------------------------------
+
+	_, err = jobService.UpdateJob(ctx, job.ID, model.JobUpdate{SLA: &model.JobSLA{}})
+	assert.NoError(t, err)
+
+	_, err = jobService.GetJobSLAStatus(ctx, job.ID, at)
+	assert.ErrorIs(t, err, errs.ErrJobSLANotFound)
+}
+
+func namespaceKeys(t *testing.T, open openStore) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := open(t)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(test.Store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, stored := test.Store.(security.NamespaceKeyStore)
+	rotator, rotates := test.Store.(store.NamespaceKeyRotator)
+	if !stored || !rotates {
+		_, err := jobService.RotateNamespaceKey(ctx, model.DefaultNamespace)
+		if !errors.Is(err, errs.ErrNamespaceKeysDisabled) {
+			t.Fatalf("Should not be able to rotate the key of a namespace: %v", err)
+		}
+		return
+	}
+
+	codec.SetEncryptor(security.NewEncryptor("testkey123456789"))
+	codec.SetNamespaceKeys(keys, true)
+	defer codec.SetNamespaceKeys(keys, false)
+
+	// Encrypt the credentials with the first key
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1h"),
+		HTTPJob: &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{
+			Type:        model.AuthTypeBearer,
+			BearerToken: null.StringFrom("secret"),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	if _, err := jobService.StartJobExecution(ctx, job, time.Now()); err != nil {
+		t.Fatalf("Should be able to start job execution: %s", err)
+	}
+
+	// Rotate the key
+	// -------------------------------------------------------------------------
+
+	key, err := jobService.RotateNamespaceKey(ctx, model.DefaultNamespace)
+	if err != nil {
+		t.Fatalf("Should be able to rotate the key of the namespace: %s", err)
+	}
+	assert.Equal(t, 2, key.Version)
+
+	// the previous key is kept while the other instances may still encrypt with it
+	completed, err := rotator.ReencryptRotatedNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to encrypt the credentials again: %s", err)
+	}
+	assert.Empty(t, completed)
+
+	versions, err := jobService.ListNamespaceKeys(ctx, model.DefaultNamespace)
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("Should keep the previous key: %v %v", versions, err)
+	}
+
+	// once the other instances reloaded the keys, the previous key no credential is encrypted with is deleted
+	if _, err := test.DB.ExecContext(ctx, `UPDATE namespace_keys SET created_at = created_at - interval '1 minute'`); err != nil {
+		t.Fatalf("Should be able to age the keys: %s", err)
+	}
+
+	completed, err = rotator.ReencryptRotatedNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to encrypt the credentials again: %s", err)
+	}
+	assert.Equal(t, []string{model.DefaultNamespace}, completed)
+
+	versions, err = jobService.ListNamespaceKeys(ctx, model.DefaultNamespace)
+	if err != nil || len(versions) != 1 || versions[0].Version != 2 {
+		t.Fatalf("Should delete the previous key: %v %v", versions, err)
+	}
+
+	job, err = jobService.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+	assert.Equal(t, "secret", job.HTTPJob.Auth.BearerToken.String)
+
+	// the rotation completed, so there is nothing left to encrypt
+	completed, err = rotator.ReencryptRotatedNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("Should be able to encrypt the credentials again: %s", err)
 	}
+	assert.Empty(t, completed)
 }
@@ -0,0 +1,85 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/redact"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// resolveTimeout bounds the DNS lookups of the reachability hints.
+const resolveTimeout = time.Second * 2
+
+// ValidateJob validates the job create request and computes the upcoming runs of the job, without creating it.
+func (s *Service) ValidateJob(ctx context.Context, jobCreate *model.JobCreate) *model.JobValidation {
+	s.log.Info("Validating job", redact.Field("job", jobCreate))
+
+	job := jobCreate.ToJob()
+	job.Namespace = model.NamespaceFromContext(ctx)
+
+	return s.validate(ctx, job)
+}
+
+// ValidateJobUpdate validates the job resulting from the update, without updating it.
+func (s *Service) ValidateJobUpdate(ctx context.Context, jobID uuid.UUID, jobUpdate model.JobUpdate) (*model.JobValidation, error) {
+	s.log.Info("Validating job update", zap.Any("id", jobID))
+
+	job, err := s.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	job.ApplyUpdate(jobUpdate)
+
+	return s.validate(ctx, job), nil
+}
+
+func (s *Service) validate(ctx context.Context, job *model.Job) *model.JobValidation {
+	validation := model.NewJobValidation(job)
+	if validation.Valid {
+		validation.Hints = reachabilityHints(ctx, job)
+	}
+
+	return validation
+}
+
+// reachabilityHints checks that the host targeted by the job can be resolved.
+// The target is not contacted, as that could trigger side effects.
+func reachabilityHints(ctx context.Context, job *model.Job) []string {
+	target := ""
+	switch {
+	case job.HTTPJob != nil:
+		target = job.HTTPJob.URL
+	case job.AMQPJob != nil:
+		target = job.AMQPJob.Connection
+	}
+
+	hints := []string{}
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Hostname() == "" {
+		return append(hints, fmt.Sprintf("%q is not an absolute URL", target))
+	}
+
+	if job.HTTPJob != nil && parsed.Scheme != "http" && parsed.Scheme != "https" {
+		hints = append(hints, fmt.Sprintf("URL scheme %q is not supported, use http or https", parsed.Scheme))
+	}
+
+	if job.AMQPJob != nil && parsed.Scheme != "amqp" && parsed.Scheme != "amqps" {
+		hints = append(hints, fmt.Sprintf("connection scheme %q is not supported, use amqp or amqps", parsed.Scheme))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	if _, err := net.DefaultResolver.LookupHost(ctx, parsed.Hostname()); err != nil {
+		hints = append(hints, fmt.Sprintf("host %q could not be resolved from the API: %s", parsed.Hostname(), err))
+	}
+
+	return hints
+}
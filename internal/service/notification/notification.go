@@ -0,0 +1,210 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Service manages notification rules and their notifications.
+type Service struct {
+	store store.NotificationStorer
+	jobs  store.Storer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new notification service with the given stores and logger. The jobs store is used to check
+// the access to the jobs the rules are created for.
+func NewService(store store.NotificationStorer, jobs store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		jobs:  jobs,
+		log:   log,
+	}
+}
+
+// CreateNotificationRule creates a new notification rule in the namespace of the context using the given rule create
+// request and returns the created rule. The rules of principals restricted to their team are restricted to the jobs
+// of the team, and can only be created for a job of the team.
+func (s *Service) CreateNotificationRule(ctx context.Context, ruleCreate *model.NotificationRuleCreate) (*model.NotificationRule, error) {
+	s.log.Info("Creating notification rule", zap.String("name", ruleCreate.Name), zap.String("channel", string(ruleCreate.Channel)))
+
+	principal := model.PrincipalFromContext(ctx)
+	rule := ruleCreate.ToNotificationRule()
+	rule.Namespace = model.NamespaceFromContext(ctx)
+	if team := principal.TeamScope(); team != "" {
+		rule.Team = null.StringFrom(team)
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if rule.JobID != nil {
+		job, err := s.jobs.GetJob(ctx, *rule.JobID)
+		if err != nil {
+			return nil, err
+		}
+
+		if job.Namespace != rule.Namespace || !principal.CanAccess(job) {
+			return nil, errs.ErrJobNotFound
+		}
+	}
+
+	if err := s.store.CreateNotificationRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// GetNotificationRule returns the notification rule with the given ID. Rules of other namespaces than the one of the
+// context, and of other teams than the one of a principal restricted to its team, are not found.
+func (s *Service) GetNotificationRule(ctx context.Context, id uuid.UUID) (*model.NotificationRule, error) {
+	s.log.Info("Getting a notification rule", zap.Any("id", id))
+
+	rule, err := s.store.GetNotificationRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	team := model.PrincipalFromContext(ctx).TeamScope()
+	if rule.Namespace != model.NamespaceFromContext(ctx) || (team != "" && rule.Team.String != team) {
+		return nil, errs.ErrNotificationRuleNotFound
+	}
+
+	return rule, nil
+}
+
+// ListNotificationRules returns all notification rules of the namespace of the context, only the ones of its team for
+// a principal restricted to its team.
+func (s *Service) ListNotificationRules(ctx context.Context) ([]model.NotificationRule, error) {
+	s.log.Info("Getting notification rules")
+	return s.store.ListNotificationRules(ctx, model.NamespaceFromContext(ctx), model.PrincipalFromContext(ctx).TeamScope())
+}
+
+// DeleteNotificationRule deletes the notification rule with the given ID, along with its notifications.
+func (s *Service) DeleteNotificationRule(ctx context.Context, id uuid.UUID) error {
+	s.log.Info("Deleting a notification rule", zap.Any("id", id))
+
+	if _, err := s.GetNotificationRule(ctx, id); err != nil {
+		return err
+	}
+
+	return s.store.DeleteNotificationRule(ctx, id)
+}
+
+// GetNotifications returns a page of notifications of the rule after the given cursor, newest first.
+func (s *Service) GetNotifications(ctx context.Context, ruleID uuid.UUID, limit uint64, cursor *model.Cursor) (*model.NotificationPage, error) {
+	s.log.Info("Getting notifications", zap.Any("rule_id", ruleID))
+
+	// make sure the rule exists, so a missing rule is not reported as an empty page
+	if _, err := s.GetNotificationRule(ctx, ruleID); err != nil {
+		return nil, err
+	}
+
+	// fetch one extra notification to determine whether there is a next page
+	notifications, err := s.store.GetNotifications(ctx, ruleID, limit+1, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.store.CountNotifications(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.NotificationPage{Notifications: notifications, Pagination: model.Pagination{Total: total}}
+	if uint64(len(notifications)) > limit {
+		page.Notifications = notifications[:limit]
+		last := page.Notifications[limit-1]
+		page.NextCursor = null.StringFrom(model.Cursor{Time: last.CreatedAt, ID: strconv.Itoa(last.ID)}.Encode())
+	}
+
+	return page, nil
+}
+
+// EnqueueNotifications creates a pending notification of the event for every rule firing on it, unless the
+// notifications of the job are throttled, and returns the number of notifications created. The throttling state of
+// the job is locked until the end of the transaction of the context.
+func (s *Service) EnqueueNotifications(ctx context.Context, event model.Event) (int, error) {
+	trigger, ok := model.NotificationTriggerOf(event)
+	if !ok {
+		return 0, nil
+	}
+
+	rules, err := s.store.GetEventNotificationRules(ctx, event)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, rule := range rules {
+		if !rule.HasTrigger(trigger) {
+			continue
+		}
+
+		state, err := s.store.GetNotificationState(ctx, rule.ID, event.JobID)
+		if err != nil {
+			return count, err
+		}
+
+		notify, suppressed := state.Record(trigger, event.Time, time.Duration(rule.Throttle)*time.Second)
+		if !notify && trigger == model.NotificationTriggerRecovery {
+			// successful executions of healthy jobs leave the state untouched
+			continue
+		}
+
+		if err := s.store.SaveNotificationState(ctx, state); err != nil {
+			return count, err
+		}
+
+		if !notify {
+			s.log.Debug("Throttled notification", zap.Any("rule_id", rule.ID), zap.Any("job_id", event.JobID), zap.Int("suppressed", state.Suppressed))
+			continue
+		}
+
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return count, fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		notification := &model.Notification{
+			RuleID:     rule.ID,
+			JobID:      event.JobID,
+			Trigger:    trigger,
+			Event:      payload,
+			Suppressed: suppressed,
+		}
+		if err := s.store.EnqueueNotification(ctx, notification); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// GetRule returns the rule of a notification, whatever its namespace.
+func (s *Service) GetRule(ctx context.Context, id uuid.UUID) (*model.NotificationRule, error) {
+	return s.store.GetNotificationRule(ctx, id)
+}
+
+// ClaimNotifications returns the notifications due at the given time, leased until leaseUntil.
+func (s *Service) ClaimNotifications(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.Notification, error) {
+	return s.store.ClaimNotifications(ctx, at, leaseUntil, limit)
+}
+
+// FinishNotification records the outcome of a notification attempt.
+func (s *Service) FinishNotification(ctx context.Context, notification model.Notification) error {
+	return s.store.FinishNotification(ctx, notification)
+}
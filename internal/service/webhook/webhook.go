@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Service manages webhooks and their deliveries.
+type Service struct {
+	store store.WebhookStorer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new webhook service with the given store and logger.
+func NewService(store store.WebhookStorer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// CreateWebhook creates a new webhook in the namespace of the context using the given webhook create request
+// and returns the created webhook.
+func (s *Service) CreateWebhook(ctx context.Context, webhookCreate *model.WebhookCreate) (*model.Webhook, error) {
+	s.log.Info("Creating webhook", zap.String("url", webhookCreate.URL))
+
+	webhook := webhookCreate.ToWebhook()
+	webhook.Namespace = model.NamespaceFromContext(ctx)
+	// webhooks of API keys bound to a team only receive the events of the jobs of the team
+	if team := model.PrincipalFromContext(ctx).TeamScope(); team != "" {
+		webhook.Team = null.StringFrom(team)
+	}
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// GetWebhook returns the webhook with the given ID. Webhooks of other namespaces than the one of the context, and of
+// other teams than the one of a principal restricted to its team, are not found.
+func (s *Service) GetWebhook(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	s.log.Info("Getting a webhook", zap.Any("id", id))
+
+	webhook, err := s.store.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	team := model.PrincipalFromContext(ctx).TeamScope()
+	if webhook.Namespace != model.NamespaceFromContext(ctx) || (team != "" && webhook.Team.String != team) {
+		return nil, errs.ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+// ListWebhooks returns all webhooks of the namespace of the context, only the ones of its team for a principal
+// restricted to its team.
+func (s *Service) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	s.log.Info("Getting webhooks")
+	return s.store.ListWebhooks(ctx, model.NamespaceFromContext(ctx), model.PrincipalFromContext(ctx).TeamScope())
+}
+
+// DeleteWebhook deletes the webhook with the given ID, along with its deliveries.
+func (s *Service) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	s.log.Info("Deleting a webhook", zap.Any("id", id))
+
+	if _, err := s.GetWebhook(ctx, id); err != nil {
+		return err
+	}
+
+	return s.store.DeleteWebhook(ctx, id)
+}
+
+// GetWebhookDeliveries returns a page of deliveries of the webhook after the given cursor, newest first.
+func (s *Service) GetWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, limit uint64, cursor *model.Cursor) (*model.WebhookDeliveryPage, error) {
+	s.log.Info("Getting webhook deliveries", zap.Any("webhook_id", webhookID))
+
+	// make sure the webhook exists, so a missing webhook is not reported as an empty page
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	// fetch one extra delivery to determine whether there is a next page
+	deliveries, err := s.store.GetWebhookDeliveries(ctx, webhookID, limit+1, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.store.CountWebhookDeliveries(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.WebhookDeliveryPage{Deliveries: deliveries, Pagination: model.Pagination{Total: total}}
+	if uint64(len(deliveries)) > limit {
+		page.Deliveries = deliveries[:limit]
+		last := page.Deliveries[limit-1]
+		page.NextCursor = null.StringFrom(model.Cursor{Time: last.CreatedAt, ID: strconv.Itoa(last.ID)}.Encode())
+	}
+
+	return page, nil
+}
+
+// EnqueueDeliveries creates pending deliveries of the event for all webhooks subscribed to it.
+func (s *Service) EnqueueDeliveries(ctx context.Context, event model.Event) (int64, error) {
+	return s.store.EnqueueWebhookDeliveries(ctx, event)
+}
+
+// ClaimDeliveries returns the deliveries due at the given time, leased until leaseUntil.
+func (s *Service) ClaimDeliveries(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.WebhookDelivery, error) {
+	return s.store.ClaimWebhookDeliveries(ctx, at, leaseUntil, limit)
+}
+
+// FinishDelivery records the outcome of a delivery attempt.
+func (s *Service) FinishDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	return s.store.FinishWebhookDelivery(ctx, delivery)
+}
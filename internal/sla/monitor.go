@@ -0,0 +1,163 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// jobsBatchSize is the number of jobs evaluated at once.
+const jobsBatchSize = 500
+
+// Monitor periodically evaluates the compliance of the jobs with their SLA, and reports each job that stops meeting
+// its SLA once, as a job.sla_breached event and a metric, until it meets its SLA again.
+type Monitor struct {
+	jobService JobService
+	metrics    *metrics.SLAMetrics
+	log        *otelzap.Logger
+	task       *periodic.Task
+
+	// breached holds the jobs already reported as breaching their SLA
+	breached map[uuid.UUID]struct{}
+}
+
+type JobService interface {
+	GetSLAJobs(ctx context.Context, after uuid.UUID, limit uint) ([]*model.Job, error)
+	GetJobSLAStatuses(ctx context.Context, at time.Time, jobIDs []uuid.UUID) ([]model.JobSLAStatus, error)
+	ReportSLABreaches(ctx context.Context, breaches ...model.SLABreach) error
+}
+
+type Config struct {
+	JobService JobService
+	// Leader restricts the evaluations to the leader instance, every instance evaluates if nil
+	Leader   leader.Leader
+	Metrics  *metrics.SLAMetrics
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Interval between evaluations of the SLAs
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Monitor, error) {
+	m := &Monitor{
+		jobService: cfg.JobService,
+		metrics:    cfg.Metrics,
+		log:        cfg.Log,
+		breached:   map[uuid.UUID]struct{}{},
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "SLA monitor",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      m.evaluate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.task = task
+
+	return m, nil
+}
+
+// Start starts the monitor in a separate goroutine.
+// Only the first call will start the monitor, subsequent calls are ignored.
+func (m *Monitor) Start() {
+	m.task.Start()
+}
+
+// Stop stops the monitor and waits for the current evaluation to finish or the context to expire.
+func (m *Monitor) Stop(ctx context.Context) {
+	m.task.Stop(ctx)
+}
+
+func (m *Monitor) evaluate(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	now := time.Now()
+	breached := map[uuid.UUID]struct{}{}
+	var breaches []model.SLABreach
+
+	for after := uuid.Nil; ; {
+		jobs, err := m.jobService.GetSLAJobs(ctx, after, jobsBatchSize)
+		if err != nil {
+			m.log.Error("Failed to get jobs with an SLA", zap.Error(err))
+			return
+		}
+
+		if len(jobs) == 0 {
+			break
+		}
+		after = jobs[len(jobs)-1].ID
+
+		jobsByID := make(map[uuid.UUID]*model.Job, len(jobs))
+		ids := make([]uuid.UUID, 0, len(jobs))
+		for _, job := range jobs {
+			jobsByID[job.ID] = job
+			ids = append(ids, job.ID)
+		}
+
+		statuses, err := m.jobService.GetJobSLAStatuses(ctx, now, ids)
+		if err != nil {
+			m.log.Error("Failed to evaluate the SLAs", zap.Error(err))
+			return
+		}
+
+		for _, status := range statuses {
+			if status.Compliant {
+				continue
+			}
+
+			breached[status.JobID] = struct{}{}
+			if _, ok := m.breached[status.JobID]; !ok {
+				breaches = append(breaches, model.SLABreach{Job: jobsByID[status.JobID], Status: status})
+			}
+		}
+
+		if len(jobs) < jobsBatchSize {
+			break
+		}
+	}
+
+	m.metrics.RecordBreachedJobs(ctx, len(breached))
+
+	if len(breaches) == 0 {
+		m.breached = breached
+		return
+	}
+
+	if err := m.jobService.ReportSLABreaches(ctx, breaches...); err != nil {
+		// the breaches are reported again on the next evaluation
+		m.log.Error("Failed to report SLA breaches", zap.Error(err))
+		return
+	}
+	m.breached = breached
+
+	for _, breach := range breaches {
+		m.metrics.IncreaseBreachCount(ctx,
+			attribute.String("job_type", string(breach.Job.Type)),
+			attribute.String("namespace", breach.Job.Namespace),
+		)
+	}
+
+	m.log.Warn("Reported SLA breaches", zap.Int("count", len(breaches)))
+}
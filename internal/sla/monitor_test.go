@@ -0,0 +1,170 @@
+package sla
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+type mockJobService struct {
+	sync.Mutex
+	jobs        []*model.Job
+	compliant   map[uuid.UUID]bool
+	reportErr   error
+	evaluations int
+	reported    []uuid.UUID
+}
+
+func (m *mockJobService) GetSLAJobs(_ context.Context, after uuid.UUID, limit uint) ([]*model.Job, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	var jobs []*model.Job
+	for _, job := range m.jobs {
+		if job.ID.String() > after.String() && uint(len(jobs)) < limit {
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, nil
+}
+
+func (m *mockJobService) GetJobSLAStatuses(_ context.Context, at time.Time, jobIDs []uuid.UUID) ([]model.JobSLAStatus, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.evaluations++
+
+	var statuses []model.JobSLAStatus
+	for _, id := range jobIDs {
+		statuses = append(statuses, model.JobSLAStatus{JobID: id, Compliant: m.compliant[id], EvaluatedAt: at})
+	}
+
+	return statuses, nil
+}
+
+func (m *mockJobService) ReportSLABreaches(_ context.Context, breaches ...model.SLABreach) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.reportErr != nil {
+		return m.reportErr
+	}
+
+	for _, breach := range breaches {
+		m.reported = append(m.reported, breach.Job.ID)
+	}
+	return nil
+}
+
+func createMonitor(t *testing.T, jobService *mockJobService) *Monitor {
+	zapL, _ := zap.NewDevelopment()
+
+	m, err := New(Config{
+		JobService: jobService,
+		Metrics:    metrics.NewSLAMetrics(observability.MetricsConfig{}),
+		Log:        otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:  true,
+			Interval: time.Millisecond * 20,
+		},
+	})
+	assert.NoError(t, err)
+
+	return m
+}
+
+// slaJobs returns count jobs with an SLA, by ID.
+func slaJobs(count int) []*model.Job {
+	var jobs []*model.Job
+	for range count {
+		jobs = append(jobs, &model.Job{
+			ID:   uuid.New(),
+			Type: model.JobTypeHTTP,
+			SLA:  &model.JobSLA{MinSuccessRate: null.FloatFrom(99)},
+		})
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID.String() < jobs[j].ID.String()
+	})
+
+	return jobs
+}
+
+func TestMonitor(t *testing.T) {
+	t.Run("Reports each breach once", func(t *testing.T) {
+		jobs := slaJobs(2)
+		jobService := &mockJobService{jobs: jobs, compliant: map[uuid.UUID]bool{jobs[0].ID: true}}
+
+		m := createMonitor(t, jobService)
+		m.evaluate(context.Background())
+		m.evaluate(context.Background())
+		assert.Equal(t, []uuid.UUID{jobs[1].ID}, jobService.reported)
+
+		// the job meets its SLA again, then breaches it once more
+		jobService.compliant[jobs[1].ID] = true
+		m.evaluate(context.Background())
+		jobService.compliant[jobs[1].ID] = false
+		m.evaluate(context.Background())
+		assert.Equal(t, []uuid.UUID{jobs[1].ID, jobs[1].ID}, jobService.reported)
+	})
+
+	t.Run("Evaluates all jobs in batches", func(t *testing.T) {
+		jobs := slaJobs(jobsBatchSize + 1)
+		jobService := &mockJobService{jobs: jobs, compliant: map[uuid.UUID]bool{}}
+
+		m := createMonitor(t, jobService)
+		m.evaluate(context.Background())
+		assert.Equal(t, 2, jobService.evaluations)
+		assert.Len(t, jobService.reported, jobsBatchSize+1)
+	})
+
+	t.Run("Reports the breaches again after a failed report", func(t *testing.T) {
+		jobs := slaJobs(1)
+		jobService := &mockJobService{jobs: jobs, compliant: map[uuid.UUID]bool{}, reportErr: errors.New("database down")}
+
+		m := createMonitor(t, jobService)
+		m.evaluate(context.Background())
+		assert.Empty(t, jobService.reported)
+
+		jobService.reportErr = nil
+		m.evaluate(context.Background())
+		assert.Equal(t, []uuid.UUID{jobs[0].ID}, jobService.reported)
+	})
+
+	t.Run("Only the leader evaluates", func(t *testing.T) {
+		jobService := &mockJobService{jobs: slaJobs(1)}
+		m, err := New(Config{
+			JobService: jobService,
+			Leader:     leader.Static(false),
+			Metrics:    metrics.NewSLAMetrics(observability.MetricsConfig{}),
+			Log:        otelzap.New(zap.NewNop()),
+			Settings:   Settings{Enabled: true, Interval: time.Millisecond * 20},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		m.Start()
+		time.Sleep(time.Millisecond * 100)
+		m.Stop(context.Background())
+
+		assert.Equal(t, 0, jobService.evaluations)
+	})
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{Enabled: true}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+// Package codec encodes the definitions of the jobs and the other documents stored by the SQL backends, encrypting
+// the credentials they contain. The encryption is set up once for the process and shared by the backends, so the
+// credentials are encrypted the same way whatever the database.
+package codec
+
+import (
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+)
+
+var encryptor security.Encryptor
+
+// SetEncryptor sets the encryptor of the credentials.
+func SetEncryptor(e security.Encryptor) {
+	encryptor = e
+}
+
+// keyring encrypts the credentials with the data key of their namespace, if set.
+var keyring *security.Keyring
+
+// SetNamespaceKeys sets up the keyring storing the data keys of the namespaces in the store, encrypted with the
+// encryptor. If enabled, the credentials are encrypted with the key of their namespace, otherwise the keys only
+// decrypt the credentials encrypted while they were enabled. The encryptor must be set first.
+func SetNamespaceKeys(keys security.NamespaceKeyStore, enabled bool) {
+	keyring = security.NewKeyring(encryptor, keys, enabled)
+}
+
+// Keyring returns the keyring of the namespace keys, nil unless set up.
+func Keyring() *security.Keyring {
+	return keyring
+}
+
+// Encrypt encrypts a value of the namespace with the keyring if set, or with the encryptor.
+func Encrypt(namespace, plaintext string) (*string, error) {
+	if keyring != nil {
+		return keyring.Encrypt(namespace, plaintext)
+	}
+
+	return encryptor.Encrypt(plaintext)
+}
+
+// Decrypt decrypts a value of the namespace with the keyring if set, or with the encryptor.
+func Decrypt(namespace, ciphertext string) (*string, error) {
+	if keyring != nil {
+		return keyring.Decrypt(namespace, ciphertext)
+	}
+
+	return encryptor.Decrypt(ciphertext)
+}
+
+// Reencrypt decrypts a value of the namespace and encrypts it again with the active key.
+func Reencrypt(namespace, ciphertext string) (string, error) {
+	plaintext, err := Decrypt(namespace, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := Encrypt(namespace, *plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	return *encrypted, nil
+}
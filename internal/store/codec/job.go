@@ -0,0 +1,271 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+)
+
+// JobDocuments are the JSON documents of a job as stored, with its credentials encrypted.
+type JobDocuments struct {
+	HTTPJob    []byte
+	AMQPJob    []byte
+	Metadata   []byte
+	SLA        []byte
+	ResultSink []byte
+}
+
+// EncodeJob encodes the documents of the job, encrypting the credentials of its definition and result sink. The job
+// keeps its credentials in plain text.
+func EncodeJob(j *model.Job) (*JobDocuments, error) {
+	docs := &JobDocuments{}
+
+	// jobs without metadata have an empty object, like the default of the column
+	metadata := j.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	encodedMetadata, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal metadata")
+	}
+	docs.Metadata = encodedMetadata
+
+	if j.SLA != nil {
+		sla, err := json.Marshal(j.SLA)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal sla")
+		}
+
+		docs.SLA = sla
+	}
+
+	if j.ResultSink != nil {
+		// Encrypt the url of the sink before storing it as it may contain credentials
+		sink := *j.ResultSink
+		encryptedURL, err := Encrypt(j.Namespace, sink.URL)
+		if err != nil {
+			return nil, err
+		}
+		sink.URL = *encryptedURL
+
+		resultSink, err := json.Marshal(sink)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal result sink")
+		}
+
+		docs.ResultSink = resultSink
+	}
+
+	if j.HTTPJob != nil {
+		stored := httpJobDB{HTTPJob: *j.HTTPJob}
+		switch stored.Auth.Type {
+		case model.AuthTypeBasic:
+			// Encrypt both the username and password before storing them
+			encryptedUsername, err := Encrypt(j.Namespace, stored.Auth.Username.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+			stored.Auth.Username = null.StringFrom(*encryptedUsername)
+
+			encryptedPassword, err := Encrypt(j.Namespace, stored.Auth.Password.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+
+			stored.Auth.Password = null.StringFrom(*encryptedPassword)
+		case model.AuthTypeBearer:
+			encryptedToken, err := Encrypt(j.Namespace, stored.Auth.BearerToken.ValueOrZero())
+			if err != nil {
+				return nil, err
+			}
+
+			stored.Auth.BearerToken = null.StringFrom(*encryptedToken)
+		}
+
+		if encryptPayloads {
+			if err := stored.encryptPayload(j.Namespace); err != nil {
+				return nil, err
+			}
+		}
+
+		httpJob, err := json.Marshal(stored)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal http job")
+		}
+
+		docs.HTTPJob = httpJob
+	}
+
+	if j.AMQPJob != nil {
+
+		// Encrypt the connection url before storing it as it contains login credentials
+		encryptedConnectionUrl, err := Encrypt(j.Namespace, j.AMQPJob.Connection)
+		if err != nil {
+			return nil, err
+		}
+
+		stored := *j.AMQPJob
+		stored.Connection = *encryptedConnectionUrl
+
+		amqpJob, err := json.Marshal(stored)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal amqp job")
+		}
+
+		docs.AMQPJob = amqpJob
+	}
+
+	return docs, nil
+}
+
+// DecodeJob decodes the documents of the job into it, decrypting their credentials with the keys of the namespace
+// of the job.
+func DecodeJob(job *model.Job, docs JobDocuments) error {
+	if err := unmarshalNullableJSON(docs.SLA, &job.SLA); err != nil {
+		return errors.Wrap(err, "failed to unmarshal sla")
+	}
+
+	if err := unmarshalNullableJSON(docs.Metadata, &job.Metadata); err != nil {
+		return errors.Wrap(err, "failed to unmarshal metadata")
+	}
+
+	if err := unmarshalNullableJSON(docs.ResultSink, &job.ResultSink); err != nil {
+		return errors.Wrap(err, "failed to unmarshal result sink")
+	}
+
+	if job.ResultSink != nil {
+		decryptedURL, err := Decrypt(job.Namespace, job.ResultSink.URL)
+		if err != nil {
+			return err
+		}
+
+		job.ResultSink.URL = *decryptedURL
+	}
+
+	if docs.HTTPJob != nil {
+		var stored httpJobDB
+		if err := json.Unmarshal(docs.HTTPJob, &stored); err != nil {
+			return errors.Wrap(err, "failed to unmarshal http job")
+		}
+
+		if err := stored.decryptPayload(job.Namespace); err != nil {
+			return err
+		}
+
+		job.HTTPJob = &stored.HTTPJob
+	}
+
+	if job.HTTPJob != nil {
+		switch job.HTTPJob.Auth.Type {
+		case model.AuthTypeBasic:
+			// Encrypt both the username and password before storing them
+			decryptedUsername, err := Decrypt(job.Namespace, job.HTTPJob.Auth.Username.ValueOrZero())
+			if err != nil {
+				return err
+			}
+			job.HTTPJob.Auth.Username = null.StringFrom(*decryptedUsername)
+
+			decryptedPassword, err := Decrypt(job.Namespace, job.HTTPJob.Auth.Password.ValueOrZero())
+			if err != nil {
+				return err
+			}
+			job.HTTPJob.Auth.Password = null.StringFrom(*decryptedPassword)
+		case model.AuthTypeBearer:
+			decryptedToken, err := Decrypt(job.Namespace, job.HTTPJob.Auth.BearerToken.ValueOrZero())
+			if err != nil {
+				return err
+			}
+
+			job.HTTPJob.Auth.BearerToken = null.StringFrom(*decryptedToken)
+		}
+	}
+
+	if err := unmarshalNullableJSON(docs.AMQPJob, &job.AMQPJob); err != nil {
+		return errors.Wrap(err, "failed to unmarshal amqp job")
+	}
+
+	if job.AMQPJob != nil {
+		decryptedConnectionUrl, err := Decrypt(job.Namespace, job.AMQPJob.Connection)
+		if err != nil {
+			return errors.Wrap(err, "failed to decrypt amqp connection url")
+		}
+
+		job.AMQPJob.Connection = *decryptedConnectionUrl
+	}
+
+	return nil
+}
+
+// ReencryptHTTPJob encrypts the credentials of a stored HTTP job of the namespace again with the active key, e.g. the
+// definition recorded by an execution. Its payload is encrypted again if it was encrypted.
+func ReencryptHTTPJob(namespace string, httpJob []byte) ([]byte, error) {
+	var stored httpJobDB
+	if err := json.Unmarshal(httpJob, &stored); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal http job")
+	}
+
+	var credentials []*null.String
+	switch stored.Auth.Type {
+	case model.AuthTypeBasic:
+		credentials = []*null.String{&stored.Auth.Username, &stored.Auth.Password}
+	case model.AuthTypeBearer:
+		credentials = []*null.String{&stored.Auth.BearerToken}
+	}
+
+	for _, credential := range credentials {
+		encrypted, err := Reencrypt(namespace, credential.ValueOrZero())
+		if err != nil {
+			return nil, err
+		}
+
+		*credential = null.StringFrom(encrypted)
+	}
+
+	if stored.EncryptedPayload != "" {
+		payload, err := Reencrypt(namespace, stored.EncryptedPayload)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encrypt http job payload")
+		}
+
+		stored.EncryptedPayload = payload
+	}
+
+	encrypted, err := json.Marshal(stored)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal http job")
+	}
+
+	return encrypted, nil
+}
+
+// ReencryptAMQPJob encrypts the connection url of a stored AMQP job of the namespace again with the active key.
+func ReencryptAMQPJob(namespace string, amqpJob []byte) ([]byte, error) {
+	var stored model.AMQPJob
+	if err := json.Unmarshal(amqpJob, &stored); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal amqp job")
+	}
+
+	connection, err := Reencrypt(namespace, stored.Connection)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt amqp connection url")
+	}
+	stored.Connection = connection
+
+	encrypted, err := json.Marshal(stored)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal amqp job")
+	}
+
+	return encrypted, nil
+}
+
+func unmarshalNullableJSON(data []byte, v interface{}) error {
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,88 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+)
+
+// encryptPayloads encrypts the headers and bodies of the HTTP jobs, in addition to their credentials.
+var encryptPayloads bool
+
+// SetPayloadEncryption sets whether the headers and bodies of the HTTP jobs are encrypted when stored. The payloads
+// encrypted before are decrypted whatever the setting, and the ones stored in plain text are read as they are.
+func SetPayloadEncryption(enabled bool) {
+	encryptPayloads = enabled
+}
+
+// EncryptHTTPPayload encrypts the headers and body of a stored HTTP job of the namespace, e.g. one stored in plain
+// text before payload encryption was enabled.
+func EncryptHTTPPayload(namespace string, httpJob []byte) ([]byte, error) {
+	var stored httpJobDB
+	if err := json.Unmarshal(httpJob, &stored); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal http job")
+	}
+
+	if err := stored.encryptPayload(namespace); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := json.Marshal(stored)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal http job")
+	}
+
+	return encrypted, nil
+}
+
+// httpJobDB is the stored HTTP job. With payload encryption, its headers and body are removed and stored together,
+// encrypted, in EncryptedPayload.
+type httpJobDB struct {
+	model.HTTPJob
+	EncryptedPayload string `json:"encrypted_payload,omitempty"`
+}
+
+type httpPayloadDB struct {
+	Headers map[string]string `json:"headers"`
+	Body    null.String       `json:"body"`
+}
+
+func (h *httpJobDB) encryptPayload(namespace string) error {
+	payload, err := json.Marshal(httpPayloadDB{Headers: h.Headers, Body: h.Body})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal http job payload")
+	}
+
+	encrypted, err := Encrypt(namespace, string(payload))
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt http job payload")
+	}
+
+	h.EncryptedPayload = *encrypted
+	h.Headers = nil
+	h.Body = null.String{}
+	return nil
+}
+
+func (h *httpJobDB) decryptPayload(namespace string) error {
+	if h.EncryptedPayload == "" {
+		return nil
+	}
+
+	decrypted, err := Decrypt(namespace, h.EncryptedPayload)
+	if err != nil {
+		return errors.Wrap(err, "failed to decrypt http job payload")
+	}
+
+	var payload httpPayloadDB
+	if err := json.Unmarshal([]byte(*decrypted), &payload); err != nil {
+		return errors.Wrap(err, "failed to unmarshal http job payload")
+	}
+
+	h.Headers = payload.Headers
+	h.Body = payload.Body
+	h.EncryptedPayload = ""
+	return nil
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"gopkg.in/guregu/null.v4"
+)
+
+// NewDiagnosticsStore creates a new PostgresSQL store for the diagnostics of the scheduler.
+func NewDiagnosticsStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.DiagnosticsStorer {
+	return newStore(db, log, options...)
+}
+
+type jobLockDB struct {
+	JobID       uuid.UUID   `db:"id"`
+	Namespace   string      `db:"namespace"`
+	Name        null.String `db:"name"`
+	LockedBy    string      `db:"locked_by"`
+	LockedUntil time.Time   `db:"locked_until"`
+	RunnerAlive bool        `db:"runner_alive"`
+}
+
+func (s *pgStore) GetStaleJobLocks(ctx context.Context, at time.Time, limit uint) ([]model.JobLock, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetStaleJobLocks")
+	defer cancel()
+
+	query := `
+		SELECT id, namespace, name, locked_by, locked_until, runner_alive
+		FROM (
+			SELECT j.id, j.namespace, j.name, j.locked_by, COALESCE(j.locked_until, j.updated_at) AS locked_until,
+			       EXISTS (SELECT 1 FROM instances i WHERE i.id = j.locked_by AND i.expires_at > $1) AS runner_alive
+			FROM jobs j
+			WHERE j.locked_by IS NOT NULL
+		) locks
+		WHERE locked_until < $1 OR NOT runner_alive
+		ORDER BY locked_until
+		LIMIT $2
+	`
+
+	var dbLocks []jobLockDB
+	if err := s.q(ctx).SelectContext(ctx, &dbLocks, query, at, limit); err != nil {
+		return nil, fmt.Errorf("failed to get stale job locks from database: %w", err)
+	}
+
+	locks := make([]model.JobLock, 0, len(dbLocks))
+	for _, dbLock := range dbLocks {
+		locks = append(locks, model.JobLock(dbLock))
+	}
+
+	return locks, nil
+}
+
+type jobScheduleDB struct {
+	ID           uuid.UUID   `db:"id"`
+	Namespace    string      `db:"namespace"`
+	Name         null.String `db:"name"`
+	Status       string      `db:"status"`
+	ExecuteAt    null.Time   `db:"execute_at"`
+	CronSchedule null.String `db:"cron_schedule"`
+	NextRun      null.Time   `db:"next_run"`
+	CompletedAt  null.Time   `db:"completed_at"`
+}
+
+func (s *pgStore) ListJobSchedules(ctx context.Context, after uuid.UUID, limit uint) ([]model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListJobSchedules")
+	defer cancel()
+
+	// the credentials aren't read, so the schedules are listed whatever the encryption key
+	query := `
+		SELECT id, namespace, name, status, execute_at, cron_schedule, next_run, completed_at
+		FROM jobs
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	var dbJobs []jobScheduleDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, after, limit); err != nil {
+		return nil, fmt.Errorf("failed to list job schedules from database: %w", err)
+	}
+
+	jobs := make([]model.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		jobs = append(jobs, model.Job{
+			ID:           dbJob.ID,
+			Namespace:    dbJob.Namespace,
+			Name:         dbJob.Name,
+			Status:       model.JobStatus(dbJob.Status),
+			ExecuteAt:    dbJob.ExecuteAt,
+			CronSchedule: dbJob.CronSchedule,
+			NextRun:      dbJob.NextRun,
+			CompletedAt:  dbJob.CompletedAt,
+		})
+	}
+
+	return jobs, nil
+}
+
+func (s *pgStore) CheckJobCredentials(ctx context.Context, limit uint) (int, []model.JobCredentialsError, error) {
+	ctx, cancel := s.withTimeout(ctx, "CheckJobCredentials")
+	defer cancel()
+
+	query := `
+		SELECT * FROM jobs
+		WHERE http_job IS NOT NULL OR amqp_job IS NOT NULL OR result_sink IS NOT NULL
+		ORDER BY updated_at DESC
+		LIMIT $1
+	`
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, limit); err != nil {
+		return 0, nil, fmt.Errorf("failed to get jobs from database: %w", err)
+	}
+
+	// converting the jobs decrypts their credentials and payloads
+	var failures []model.JobCredentialsError
+	for _, dbJob := range dbJobs {
+		if _, err := dbJob.ToJob(); err != nil {
+			failures = append(failures, model.JobCredentialsError{JobID: dbJob.ID, Namespace: dbJob.Namespace, Err: err})
+		}
+	}
+
+	return len(dbJobs), failures, nil
+}
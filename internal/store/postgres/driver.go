@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// DriverName is the name the Postgres backend is registered with.
+const DriverName = "postgres"
+
+func init() {
+	store.Register(DriverName, store.DriverFunc(open))
+}
+
+// NewBackend returns the Postgres stores on the database handle.
+func NewBackend(db *sqlx.DB, log *otelzap.Logger, options ...Option) *store.Backend {
+	s := newStore(db, log, options...)
+
+	return &store.Backend{
+		Jobs:          s,
+		Instances:     s,
+		Webhooks:      s,
+		Notifications: s,
+		APIKeys:       s,
+	}
+}
+
+// open opens the backend on the database handle of the config, or connects to the DSN.
+func open(ctx context.Context, cfg store.Config) (*store.Backend, error) {
+	log := otelzap.New(cfg.Logger)
+
+	if cfg.DB != nil {
+		return NewBackend(sqlx.NewDb(cfg.DB, "pgx"), log), nil
+	}
+
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("a database handle or DSN is required")
+	}
+
+	db, err := sqlx.Open("pgx", cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	backend := NewBackend(db, log)
+	backend.Closer = db.Close
+	return backend, nil
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventsChannel is the notification channel the events relayed from the outbox are broadcast on.
+	eventsChannel = "job_events"
+	// wakeupsChannel is the notification channel runners are woken up on when a job is due soon.
+	wakeupsChannel = "job_wakeups"
+	// cancellationsChannel is the notification channel runners are notified on when an execution is cancelled.
+	cancellationsChannel = "job_cancellations"
+)
+
+// PublishEvent writes the event to the outbox. Within a transaction, the event is only relayed if the transaction is
+// committed.
+func (s *pgStore) PublishEvent(ctx context.Context, event model.Event) error {
+	ctx, cancel := s.withTimeout(ctx, "PublishEvent")
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	query := `INSERT INTO event_outbox (event_id, payload) VALUES ($1, $2)`
+	if _, err := s.q(ctx).ExecContext(ctx, query, event.ID, payload); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// RelayEvents locks up to limit events of the outbox, skipping the ones locked by other instances, and passes them
+// to fn. The events are deleted from the outbox and broadcast to the listeners in the transaction fn runs in, so
+// they are relayed again if fn or the commit fails.
+func (s *pgStore) RelayEvents(ctx context.Context, limit uint, fn func(ctx context.Context, events []model.Event) error) (int, error) {
+	ctx, cancel := s.withTimeout(ctx, "RelayEvents")
+	defer cancel()
+
+	var count int
+	err := s.InTx(ctx, func(ctx context.Context) error {
+		query := `
+			SELECT id, payload FROM event_outbox
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		`
+		var rows []struct {
+			ID      int64  `db:"id"`
+			Payload []byte `db:"payload"`
+		}
+		if err := s.q(ctx).SelectContext(ctx, &rows, query, limit); err != nil {
+			return fmt.Errorf("failed to get outbox events from database: %w", err)
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make(pq.Int64Array, 0, len(rows))
+		events := make([]model.Event, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+
+			var event model.Event
+			if err := json.Unmarshal(row.Payload, &event); err != nil {
+				// a malformed event would block the outbox, it is dropped instead
+				s.log.Error("Failed to unmarshal outbox event", zap.Int64("id", row.ID), zap.Error(err))
+				continue
+			}
+			events = append(events, event)
+		}
+
+		if err := fn(ctx, events); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := s.notify(ctx, eventsChannel, event); err != nil {
+				return fmt.Errorf("failed to broadcast event: %w", err)
+			}
+		}
+
+		if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM event_outbox WHERE id = ANY($1)`, ids); err != nil {
+			return fmt.Errorf("failed to delete outbox events from database: %w", err)
+		}
+
+		count = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListenEvents listens for published events on a dedicated connection and calls the handler for each of them.
+// It blocks until the context is cancelled or the connection fails.
+func (s *pgStore) ListenEvents(ctx context.Context, handler func(model.Event)) error {
+	return s.listen(ctx, eventsChannel, func(payload string) {
+		var event model.Event
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			s.log.Warn("Failed to unmarshal event", zap.String("payload", payload), zap.Error(err))
+			return
+		}
+
+		handler(event)
+	})
+}
+
+// NotifyJobWakeup notifies the listening runners that the job is due soon.
+func (s *pgStore) NotifyJobWakeup(ctx context.Context, wakeup model.JobWakeup) error {
+	ctx, cancel := s.withTimeout(ctx, "NotifyJobWakeup")
+	defer cancel()
+
+	if err := s.notify(ctx, wakeupsChannel, wakeup); err != nil {
+		return fmt.Errorf("failed to notify job wakeup: %w", err)
+	}
+
+	return nil
+}
+
+// ListenJobWakeups listens for job wakeups on a dedicated connection and calls the handler for each of them.
+// It blocks until the context is cancelled or the connection fails.
+func (s *pgStore) ListenJobWakeups(ctx context.Context, handler func(model.JobWakeup)) error {
+	return s.listen(ctx, wakeupsChannel, func(payload string) {
+		var wakeup model.JobWakeup
+		if err := json.Unmarshal([]byte(payload), &wakeup); err != nil {
+			s.log.Warn("Failed to unmarshal job wakeup", zap.String("payload", payload), zap.Error(err))
+			return
+		}
+
+		handler(wakeup)
+	})
+}
+
+// NotifyJobCancellation notifies the listening runners that the cancellation of the execution was requested.
+func (s *pgStore) NotifyJobCancellation(ctx context.Context, cancellation model.JobCancellation) error {
+	ctx, cancel := s.withTimeout(ctx, "NotifyJobCancellation")
+	defer cancel()
+
+	if err := s.notify(ctx, cancellationsChannel, cancellation); err != nil {
+		return fmt.Errorf("failed to notify job cancellation: %w", err)
+	}
+
+	return nil
+}
+
+// ListenJobCancellations listens for job cancellations on a dedicated connection and calls the handler for each
+// of them. It blocks until the context is cancelled or the connection fails.
+func (s *pgStore) ListenJobCancellations(ctx context.Context, handler func(model.JobCancellation)) error {
+	return s.listen(ctx, cancellationsChannel, func(payload string) {
+		var cancellation model.JobCancellation
+		if err := json.Unmarshal([]byte(payload), &cancellation); err != nil {
+			s.log.Warn("Failed to unmarshal job cancellation", zap.String("payload", payload), zap.Error(err))
+			return
+		}
+
+		handler(cancellation)
+	})
+}
+
+// notify sends the JSON encoded payload on the notification channel.
+func (s *pgStore) notify(ctx context.Context, channel string, payload any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	_, err = s.q(ctx).ExecContext(ctx, `SELECT pg_notify($1, $2)`, channel, string(encoded))
+	return err
+}
+
+// listen listens on the notification channel using a dedicated connection and calls the handler with the
+// payload of every notification, until the context is cancelled or the connection fails.
+func (s *pgStore) listen(ctx context.Context, channel string, handler func(payload string)) error {
+	return s.withConn(ctx, func(pgxConn *pgx.Conn) error {
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+channel); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", channel, err)
+		}
+
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to wait for notifications on %s: %w", channel, err)
+			}
+
+			handler(notification.Payload)
+		}
+	})
+}
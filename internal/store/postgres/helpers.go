@@ -1,17 +1,256 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.uber.org/zap"
 )
 
+// checkJobUpdated checks that the update of the job matched its version, and increments it.
+// Updates only match the version the job was read at, so concurrent updates can't overwrite each other.
+func checkJobUpdated(res sql.Result, job *model.Job) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update job in database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrJobVersionMismatch
+	}
+
+	job.Version++
+	return nil
+}
+
+// uniqueViolationCode is the Postgres error code of unique constraint violations.
+const uniqueViolationCode = "23505"
+
+// isUniqueViolation reports whether the error is caused by a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// jobExternalIDIndex is the unique index of the external IDs of the jobs of a namespace.
+const jobExternalIDIndex = "jobs_namespace_external_id_unique_index"
+
+// jobConflict returns the error of a unique constraint violation caused by writing a job, telling whether its external
+// ID or its name is taken.
+func jobConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == jobExternalIDIndex {
+		return errs.ErrJobExternalIDTaken
+	}
+
+	return errs.ErrJobNameTaken
+}
+
 func rollback(tx *sqlx.Tx, log *otelzap.Logger) {
 	err := tx.Rollback()
 	if err != nil && !errors.Is(err, sql.ErrTxDone) {
 		log.Error("Failed to rollback transaction", zap.Error(err))
 	}
 }
+
+// jobFilterQuery builds the WHERE clause for the given job filter, appending the values to args.
+func jobFilterQuery(filter model.JobFilter, args []interface{}) (string, []interface{}) {
+	where := "TRUE"
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+condition, len(args))
+	}
+
+	if filter.Namespace != "" {
+		add("namespace = $%d", filter.Namespace)
+	}
+
+	if filter.Team != "" {
+		add("team = $%d", filter.Team)
+	}
+
+	if len(filter.IDs) > 0 {
+		add("id = ANY($%d::uuid[])", uuidArray(filter.IDs))
+	}
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		// substring matches are served by the trigram index, word matches by the full-text index
+		args = append(args, "%"+escapeLike(query)+"%", query)
+		where += fmt.Sprintf(
+			" AND (search_text ILIKE $%d OR to_tsvector('simple', search_text) @@ plainto_tsquery('simple', $%d))",
+			len(args)-1, len(args),
+		)
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make(pq.StringArray, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		add("status::text = ANY($%d)", statuses)
+	}
+
+	if len(filter.Types) > 0 {
+		types := make(pq.StringArray, 0, len(filter.Types))
+		for _, jobType := range filter.Types {
+			types = append(types, string(jobType))
+		}
+		add("type::text = ANY($%d)", types)
+	}
+
+	if len(filter.Tags) > 0 {
+		add("tags @> $%d", pq.StringArray(filter.Tags))
+	}
+
+	if len(filter.AnyTags) > 0 {
+		add("tags && $%d", pq.StringArray(filter.AnyTags))
+	}
+
+	for _, requirement := range filter.Selector {
+		if key, ok := requirement.MetadataKey(); ok {
+			// values are matched by containment, which is served by the GIN index of the metadata
+			contained, _ := json.Marshal(map[string]string{key: requirement.Value})
+			switch requirement.Operator {
+			case model.SelectorOperatorEquals:
+				add("metadata @> $%d::jsonb", string(contained))
+			case model.SelectorOperatorNotEquals:
+				add("NOT metadata @> $%d::jsonb", string(contained))
+			case model.SelectorOperatorExists:
+				add("metadata ? $%d", key)
+			case model.SelectorOperatorNotExists:
+				add("NOT metadata ? $%d", key)
+			}
+			continue
+		}
+
+		switch requirement.Operator {
+		case model.SelectorOperatorEquals:
+			add("tags @> $%d", pq.StringArray{model.Label{Key: requirement.Key, Value: requirement.Value}.String()})
+		case model.SelectorOperatorNotEquals:
+			add("NOT coalesce(tags, '{}') @> $%d", pq.StringArray{model.Label{Key: requirement.Key, Value: requirement.Value}.String()})
+		case model.SelectorOperatorExists, model.SelectorOperatorNotExists:
+			// a key is set either as a plain tag or as a key=value tag
+			args = append(args, requirement.Key, escapeLike(requirement.Key)+"=%")
+			condition := fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(tags) tag WHERE tag = $%d OR tag LIKE $%d)", len(args)-1, len(args))
+			if requirement.Operator == model.SelectorOperatorNotExists {
+				condition = "NOT " + condition
+			}
+			where += " AND " + condition
+		}
+	}
+
+	if filter.NextRunFrom != nil {
+		add("next_run >= $%d", *filter.NextRunFrom)
+	}
+
+	if filter.NextRunTo != nil {
+		add("next_run <= $%d", *filter.NextRunTo)
+	}
+
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		add("created_at <= $%d", *filter.CreatedTo)
+	}
+
+	if filter.Failed != nil {
+		if *filter.Failed {
+			where += " AND last_execution_status IN ('FAILED', 'TIMED_OUT')"
+		} else {
+			where += " AND last_execution_status IS DISTINCT FROM 'FAILED' AND last_execution_status IS DISTINCT FROM 'TIMED_OUT'"
+		}
+	}
+
+	return where, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE pattern wildcards in s.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// uuidArray converts the IDs to an array parameter, to be cast to uuid[] in the query.
+func uuidArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, 0, len(ids))
+	for _, id := range ids {
+		array = append(array, id.String())
+	}
+
+	return array
+}
+
+// withConn calls fn with a dedicated native pgx connection, for the features database/sql doesn't support,
+// such as notifications and COPY. The connection is returned to the pool once fn returns. Within a transaction, fn
+// is called with the connection of the transaction.
+func (s *pgStore) withConn(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	var conn *sql.Conn
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		conn = state.conn.Conn
+	} else {
+		var err error
+		conn, err = s.db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire connection: %w", err)
+		}
+		defer conn.Close()
+	}
+
+	return conn.Raw(func(driverConn any) error {
+		stdlibConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unsupported driver connection %T", driverConn)
+		}
+
+		return fn(stdlibConn.Conn())
+	})
+}
+
+// withTimeout bounds the context by the timeout of the operation, if it has one.
+func (s *pgStore) withTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	if timeout := s.timeouts.Timeout(operation); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// estimateCount returns the number of rows the planner expects the query to return, without running it. The estimate
+// comes from the statistics of the tables (pg_class and pg_statistic), kept up to date by autovacuum, so it is
+// immediate whatever the number of rows, but can be off by a few percent or more on skewed data.
+func (s *pgStore) estimateCount(ctx context.Context, query string, args ...interface{}) (uint64, error) {
+	var explain []byte
+	if err := s.q(ctx).GetContext(ctx, &explain, `EXPLAIN (FORMAT JSON) `+query, args...); err != nil {
+		return 0, err
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(explain, &plans); err != nil {
+		return 0, fmt.Errorf("malformed query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("empty query plan")
+	}
+
+	return uint64(plans[0].Plan.Rows), nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobFilterQuery(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		filter        model.JobFilter
+		args          []interface{}
+		expectedWhere string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "Empty filter",
+			filter:        model.JobFilter{},
+			args:          []interface{}{},
+			expectedWhere: "TRUE",
+			expectedArgs:  []interface{}{},
+		},
+		{
+			name: "Statuses and tags",
+			filter: model.JobFilter{
+				Statuses: []model.JobStatus{model.JobStatusRunning},
+				Tags:     []string{"a", "b"},
+			},
+			args:          []interface{}{uint64(10)},
+			expectedWhere: "TRUE AND status::text = ANY($2) AND tags @> $3",
+			expectedArgs:  []interface{}{uint64(10), pq.StringArray{"RUNNING"}, pq.StringArray{"a", "b"}},
+		},
+		{
+			name: "Namespace and types",
+			filter: model.JobFilter{
+				Namespace: "billing",
+				Types:     []model.JobType{model.JobTypeAMQP},
+			},
+			args:          []interface{}{},
+			expectedWhere: "TRUE AND namespace = $1 AND type::text = ANY($2)",
+			expectedArgs:  []interface{}{"billing", pq.StringArray{"AMQP"}},
+		},
+		{
+			name: "Namespace and team",
+			filter: model.JobFilter{
+				Namespace: "billing",
+				Team:      "payments",
+			},
+			args:          []interface{}{},
+			expectedWhere: "TRUE AND namespace = $1 AND team = $2",
+			expectedArgs:  []interface{}{"billing", "payments"},
+		},
+		{
+			name: "Search query",
+			filter: model.JobFilter{
+				Query: " billing_api ",
+			},
+			args: []interface{}{},
+			expectedWhere: "TRUE AND (search_text ILIKE $1 OR " +
+				"to_tsvector('simple', search_text) @@ plainto_tsquery('simple', $2))",
+			expectedArgs: []interface{}{`%billing\_api%`, "billing_api"},
+		},
+		{
+			name: "Tag selector",
+			filter: model.JobFilter{
+				Selector: model.TagSelector{
+					{Key: "env", Operator: model.SelectorOperatorEquals, Value: "prod"},
+					{Key: "team", Operator: model.SelectorOperatorNotEquals, Value: "payments"},
+					{Key: "canary_v1", Operator: model.SelectorOperatorNotExists},
+				},
+			},
+			args: []interface{}{},
+			expectedWhere: "TRUE AND tags @> $1 AND NOT coalesce(tags, '{}') @> $2" +
+				" AND NOT EXISTS (SELECT 1 FROM unnest(tags) tag WHERE tag = $3 OR tag LIKE $4)",
+			expectedArgs: []interface{}{pq.StringArray{"env=prod"}, pq.StringArray{"team=payments"}, "canary_v1", `canary\_v1=%`},
+		},
+		{
+			name: "Metadata selector",
+			filter: model.JobFilter{
+				Selector: model.TagSelector{
+					{Key: "metadata.cost-center", Operator: model.SelectorOperatorEquals, Value: "research"},
+					{Key: "metadata.ticket", Operator: model.SelectorOperatorNotExists},
+				},
+			},
+			args:          []interface{}{},
+			expectedWhere: "TRUE AND metadata @> $1::jsonb AND NOT metadata ? $2",
+			expectedArgs:  []interface{}{`{"cost-center":"research"}`, "ticket"},
+		},
+		{
+			name: "Ranges and failure state",
+			filter: model.JobFilter{
+				NextRunFrom: &now,
+				CreatedTo:   &now,
+				Failed:      lo.ToPtr(true),
+			},
+			args:          []interface{}{},
+			expectedWhere: "TRUE AND next_run >= $1 AND created_at <= $2 AND last_execution_status IN ('FAILED', 'TIMED_OUT')",
+			expectedArgs:  []interface{}{now, now},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := jobFilterQuery(tt.filter, tt.args)
+			assert.Equal(t, tt.expectedWhere, where)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	s := newStore(nil, nil, WithQueryTimeouts(database.QueryTimeouts{
+		Operations: map[string]time.Duration{"getjobstorun": time.Second},
+	}))
+
+	ctx, cancel := s.withTimeout(context.Background(), "GetJobsToRun")
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	ctx, cancel = s.withTimeout(context.Background(), "ListJobs")
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestJobConflict(t *testing.T) {
+	err := fmt.Errorf("insert: %w", &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: jobExternalIDIndex})
+	assert.ErrorIs(t, jobConflict(err), errs.ErrJobExternalIDTaken)
+
+	err = &pgconn.PgError{Code: uniqueViolationCode, ConstraintName: "jobs_namespace_name_unique_index"}
+	assert.ErrorIs(t, jobConflict(err), errs.ErrJobNameTaken)
+}
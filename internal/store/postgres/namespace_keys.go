@@ -0,0 +1,436 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// reencryptionBatchSize is the number of rows encrypted again at once by ReencryptRotatedNamespaces.
+const reencryptionBatchSize = 500
+
+// SetNamespaceKeys sets up the keyring storing the data keys of the namespaces in the database, see
+// codec.SetNamespaceKeys. The encryptor must be set first.
+func SetNamespaceKeys(db *sqlx.DB, log *otelzap.Logger, enabled bool) {
+	codec.SetNamespaceKeys(newStore(db, log), enabled)
+}
+
+type namespaceKeyDB struct {
+	Namespace    string    `db:"namespace"`
+	Version      int       `db:"version"`
+	EncryptedKey string    `db:"encrypted_key"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+func (s *pgStore) GetNamespaceKeys(ctx context.Context, namespace string) ([]security.NamespaceKey, error) {
+	var dbKeys []namespaceKeyDB
+	query := `SELECT * FROM namespace_keys WHERE namespace = $1`
+	if err := s.db.SelectContext(ctx, &dbKeys, query, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace keys from database: %w", err)
+	}
+
+	keys := make([]security.NamespaceKey, 0, len(dbKeys))
+	for _, dbKey := range dbKeys {
+		keys = append(keys, security.NamespaceKey(dbKey))
+	}
+
+	return keys, nil
+}
+
+func (s *pgStore) CreateNamespaceKey(ctx context.Context, key security.NamespaceKey) error {
+	query := `
+		INSERT INTO namespace_keys (namespace, version, encrypted_key, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := s.db.ExecContext(ctx, query, key.Namespace, key.Version, key.EncryptedKey, key.CreatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return security.ErrNamespaceKeyConflict
+		}
+
+		return fmt.Errorf("failed to insert namespace key into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) ListNamespaceKeys(ctx context.Context, namespace string) ([]model.NamespaceKey, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListNamespaceKeys")
+	defer cancel()
+
+	var dbKeys []namespaceKeyDB
+	query := `SELECT * FROM namespace_keys WHERE namespace = $1 ORDER BY version DESC`
+	if err := s.q(ctx).SelectContext(ctx, &dbKeys, query, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace keys from database: %w", err)
+	}
+
+	keys := []model.NamespaceKey{}
+	for i, dbKey := range dbKeys {
+		keys = append(keys, model.NamespaceKey{
+			Namespace: dbKey.Namespace,
+			Version:   dbKey.Version,
+			Active:    i == 0,
+			CreatedAt: dbKey.CreatedAt,
+		})
+	}
+
+	return keys, nil
+}
+
+type namespaceKeyRotationDB struct {
+	Namespace       string    `db:"namespace"`
+	Version         int       `db:"version"`
+	LastExecutionID int       `db:"last_execution_id"`
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+// RotateNamespaceKey creates a new version of the key of the namespace, and records the rotation so the credentials of
+// the jobs, executions, webhooks and templates of the namespace are encrypted again with it in the background, see
+// ReencryptRotatedNamespaces. A rotation replaces the one of the namespace still in progress.
+func (s *pgStore) RotateNamespaceKey(ctx context.Context, namespace string) (*model.NamespaceKey, error) {
+	keyring := codec.Keyring()
+	if keyring == nil || !keyring.Enabled() {
+		return nil, errs.ErrNamespaceKeysDisabled
+	}
+
+	ctx, cancel := s.withTimeout(ctx, "RotateNamespaceKey")
+	defer cancel()
+
+	key, err := keyring.Rotate(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate namespace key: %w", err)
+	}
+
+	query := `
+		INSERT INTO namespace_key_rotations (namespace, version, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (namespace) DO UPDATE SET version = EXCLUDED.version, last_execution_id = 0, created_at = EXCLUDED.created_at
+	`
+	if _, err := s.q(ctx).ExecContext(ctx, query, namespace, key.Version, key.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to insert namespace key rotation into database: %w", err)
+	}
+
+	return &model.NamespaceKey{
+		Namespace: key.Namespace,
+		Version:   key.Version,
+		Active:    true,
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
+
+// ReencryptRotatedNamespaces encrypts the credentials of the namespaces whose key was rotated again with the new key,
+// and deletes the previous versions of their keys which no instance encrypts new values with anymore and no value is
+// encrypted with. A rotation completes once all the previous versions are deleted, until then the credentials
+// encrypted with them are looked for again, e.g. the ones updated by another instance before it reloaded the keys. The
+// executions are encrypted again by batches, from the one after the last execution encrypted again, so a rotation
+// resumes where it stopped, e.g. on a timeout or a restart.
+func (s *pgStore) ReencryptRotatedNamespaces(ctx context.Context) ([]string, error) {
+	keyring := codec.Keyring()
+	if keyring == nil || !keyring.Enabled() {
+		return nil, nil
+	}
+
+	var rotations []namespaceKeyRotationDB
+	if err := s.q(ctx).SelectContext(ctx, &rotations, `SELECT * FROM namespace_key_rotations ORDER BY created_at`); err != nil {
+		return nil, fmt.Errorf("failed to get namespace key rotations from database: %w", err)
+	}
+
+	var completed []string
+	for _, rotation := range rotations {
+		// the rotation may have been requested from another instance, whose new key this one doesn't use yet
+		if err := keyring.Reload(ctx, rotation.Namespace); err != nil {
+			return completed, err
+		}
+
+		done, err := s.reencryptNamespace(ctx, rotation)
+		if err != nil {
+			return completed, fmt.Errorf("failed to rotate the key of namespace %s: %w", rotation.Namespace, err)
+		}
+
+		if done {
+			completed = append(completed, rotation.Namespace)
+		}
+	}
+
+	return completed, nil
+}
+
+// reencryptNamespace encrypts the credentials of the namespace encrypted with the versions of its key older than the
+// one of the rotation again, deletes the previous versions no value is encrypted with anymore, and reports whether the
+// rotation completed.
+func (s *pgStore) reencryptNamespace(ctx context.Context, rotation namespaceKeyRotationDB) (bool, error) {
+	if err := s.reencryptJobs(ctx, rotation.Namespace, rotation.Version); err != nil {
+		return false, err
+	}
+
+	if err := s.reencryptWebhooks(ctx, rotation.Namespace, rotation.Version); err != nil {
+		return false, err
+	}
+
+	if err := s.reencryptTemplates(ctx, rotation.Namespace, rotation.Version); err != nil {
+		return false, err
+	}
+
+	if err := s.reencryptExecutions(ctx, rotation); err != nil {
+		return false, err
+	}
+
+	remaining, err := s.deleteRetiredNamespaceKeys(ctx, rotation.Namespace, rotation.Version)
+	if err != nil || remaining {
+		return false, err
+	}
+
+	// a later rotation of the namespace replaced the rotation if its version changed
+	query := `DELETE FROM namespace_key_rotations WHERE namespace = $1 AND version = $2`
+	if _, err := s.q(ctx).ExecContext(ctx, query, rotation.Namespace, rotation.Version); err != nil {
+		return false, fmt.Errorf("failed to delete namespace key rotation: %w", err)
+	}
+
+	return true, nil
+}
+
+// reencryptJobs encrypts the credentials of the jobs of the namespace encrypted with a version of its key older than
+// the given one with the active key, by batches. The jobs updated in the meantime are skipped, as they were encrypted
+// with the active key by their update.
+func (s *pgStore) reencryptJobs(ctx context.Context, namespace string, version int) error {
+	query := `
+		SELECT id, namespace, type, version, http_job, amqp_job, result_sink
+		FROM jobs
+		WHERE namespace = $1 AND key_version < $2 AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+
+	var lastID uuid.UUID
+	for {
+		var dbJobs []jobDB
+		if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, namespace, version, lastID, reencryptionBatchSize); err != nil {
+			return fmt.Errorf("failed to get jobs from database: %w", err)
+		}
+
+		for _, dbJob := range dbJobs {
+			job, err := dbJob.ToJob()
+			if err != nil {
+				return fmt.Errorf("failed to decrypt job %s: %w", dbJob.ID, err)
+			}
+
+			encrypted, err := toJobDB(job)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt job %s: %w", dbJob.ID, err)
+			}
+
+			query := `UPDATE jobs SET http_job = $3, amqp_job = $4, result_sink = $5 WHERE id = $1 AND version = $2`
+			if _, err := s.q(ctx).ExecContext(ctx, query, dbJob.ID, dbJob.Version, encrypted.HTTPJob, encrypted.AMQPJob, encrypted.ResultSink); err != nil {
+				return fmt.Errorf("failed to update job %s: %w", dbJob.ID, err)
+			}
+
+			lastID = dbJob.ID
+		}
+
+		if len(dbJobs) < reencryptionBatchSize {
+			return nil
+		}
+	}
+}
+
+// reencryptWebhooks encrypts the secrets of the webhooks of the namespace encrypted with a version of its key older
+// than the given one with the active key, by batches.
+func (s *pgStore) reencryptWebhooks(ctx context.Context, namespace string, version int) error {
+	query := `
+		SELECT id, namespace, secret
+		FROM webhooks
+		WHERE namespace = $1 AND key_version < $2 AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+
+	var lastID uuid.UUID
+	for {
+		var dbWebhooks []webhookDB
+		if err := s.q(ctx).SelectContext(ctx, &dbWebhooks, query, namespace, version, lastID, reencryptionBatchSize); err != nil {
+			return fmt.Errorf("failed to get webhooks from database: %w", err)
+		}
+
+		for _, dbWebhook := range dbWebhooks {
+			secret, err := codec.Reencrypt(namespace, dbWebhook.Secret)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt webhook %s: %w", dbWebhook.ID, err)
+			}
+
+			query := `UPDATE webhooks SET secret = $3 WHERE id = $1 AND secret = $2`
+			if _, err := s.q(ctx).ExecContext(ctx, query, dbWebhook.ID, dbWebhook.Secret, secret); err != nil {
+				return fmt.Errorf("failed to update webhook %s: %w", dbWebhook.ID, err)
+			}
+
+			lastID = dbWebhook.ID
+		}
+
+		if len(dbWebhooks) < reencryptionBatchSize {
+			return nil
+		}
+	}
+}
+
+// reencryptTemplates encrypts the definitions of the templates of the namespace encrypted with a version of its key
+// older than the given one with the active key, by batches.
+func (s *pgStore) reencryptTemplates(ctx context.Context, namespace string, version int) error {
+	query := `
+		SELECT namespace, name, definition
+		FROM job_templates
+		WHERE namespace = $1 AND key_version < $2 AND name > $3
+		ORDER BY name
+		LIMIT $4
+	`
+
+	var lastName string
+	for {
+		var dbTemplates []templateDB
+		if err := s.q(ctx).SelectContext(ctx, &dbTemplates, query, namespace, version, lastName, reencryptionBatchSize); err != nil {
+			return fmt.Errorf("failed to get templates from database: %w", err)
+		}
+
+		for _, dbTemplate := range dbTemplates {
+			definition, err := codec.Reencrypt(namespace, dbTemplate.Definition)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt template %s: %w", dbTemplate.Name, err)
+			}
+
+			query := `UPDATE job_templates SET definition = $3 WHERE namespace = $1 AND name = $2 AND definition = $4`
+			if _, err := s.q(ctx).ExecContext(ctx, query, namespace, dbTemplate.Name, definition, dbTemplate.Definition); err != nil {
+				return fmt.Errorf("failed to update template %s: %w", dbTemplate.Name, err)
+			}
+
+			lastName = dbTemplate.Name
+		}
+
+		if len(dbTemplates) < reencryptionBatchSize {
+			return nil
+		}
+	}
+}
+
+// reencryptExecutions encrypts the credentials of the job definitions recorded by the executions of the namespace
+// encrypted with a version of its key older than the one of the rotation with the active key, by batches, from the
+// one after the last execution of the rotation. The last execution is recorded after each batch, and reset once all
+// the executions are done, so the ones created in the meantime by an instance still encrypting with a previous version
+// are encrypted again on the next run.
+func (s *pgStore) reencryptExecutions(ctx context.Context, rotation namespaceKeyRotationDB) error {
+	selectQuery := `
+		SELECT id, http_job, amqp_job
+		FROM job_executions
+		WHERE namespace = $1 AND key_version < $2 AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+	updateQuery := `
+		UPDATE job_executions SET http_job = $4, amqp_job = $5
+		WHERE id = $1 AND http_job IS NOT DISTINCT FROM $2::jsonb AND amqp_job IS NOT DISTINCT FROM $3::jsonb
+	`
+	progressQuery := `UPDATE namespace_key_rotations SET last_execution_id = $3 WHERE namespace = $1 AND version = $2`
+
+	lastID := rotation.LastExecutionID
+	for {
+		var rows []struct {
+			ID      int    `db:"id"`
+			HTTPJob []byte `db:"http_job"`
+			AMQPJob []byte `db:"amqp_job"`
+		}
+		if err := s.q(ctx).SelectContext(ctx, &rows, selectQuery, rotation.Namespace, rotation.Version, lastID, reencryptionBatchSize); err != nil {
+			return fmt.Errorf("failed to get executions from database: %w", err)
+		}
+
+		for _, row := range rows {
+			var httpJob, amqpJob []byte
+			var err error
+			if row.HTTPJob != nil {
+				if httpJob, err = codec.ReencryptHTTPJob(rotation.Namespace, row.HTTPJob); err != nil {
+					return fmt.Errorf("failed to encrypt execution %d: %w", row.ID, err)
+				}
+			}
+
+			if row.AMQPJob != nil {
+				if amqpJob, err = codec.ReencryptAMQPJob(rotation.Namespace, row.AMQPJob); err != nil {
+					return fmt.Errorf("failed to encrypt execution %d: %w", row.ID, err)
+				}
+			}
+
+			if _, err := s.q(ctx).ExecContext(ctx, updateQuery, row.ID, row.HTTPJob, row.AMQPJob, httpJob, amqpJob); err != nil {
+				return fmt.Errorf("failed to update execution %d: %w", row.ID, err)
+			}
+
+			lastID = row.ID
+		}
+
+		done := len(rows) < reencryptionBatchSize
+		if done {
+			lastID = 0
+		}
+
+		if _, err := s.q(ctx).ExecContext(ctx, progressQuery, rotation.Namespace, rotation.Version, lastID); err != nil {
+			return fmt.Errorf("failed to update namespace key rotation: %w", err)
+		}
+
+		if done {
+			return nil
+		}
+	}
+}
+
+// deleteRetiredNamespaceKeys deletes the versions of the key of the namespace older than the given one which no
+// instance encrypts new values with anymore, unless a value is still encrypted with them, e.g. one updated while it
+// was encrypted again, and reports whether such versions remain. The key versions of the rows are the oldest ones of
+// their values, so a version is kept while a row has a version up to it.
+func (s *pgStore) deleteRetiredNamespaceKeys(ctx context.Context, namespace string, version int) (bool, error) {
+	keys, err := s.GetNamespaceKeys(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+
+	referencedQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM jobs WHERE namespace = $1 AND key_version <= $2
+		) OR EXISTS (
+			SELECT 1 FROM job_executions WHERE namespace = $1 AND key_version <= $2
+		) OR EXISTS (
+			SELECT 1 FROM webhooks WHERE namespace = $1 AND key_version <= $2
+		) OR EXISTS (
+			SELECT 1 FROM job_templates WHERE namespace = $1 AND key_version <= $2
+		)
+	`
+	deleted := map[int]bool{}
+	for _, retired := range security.RetiredNamespaceKeys(keys, time.Now()) {
+		if retired >= version {
+			continue
+		}
+
+		var referenced bool
+		if err := s.q(ctx).GetContext(ctx, &referenced, referencedQuery, namespace, retired); err != nil {
+			return false, fmt.Errorf("failed to check the values encrypted with namespace key %d: %w", retired, err)
+		}
+
+		if referenced {
+			continue
+		}
+
+		query := `DELETE FROM namespace_keys WHERE namespace = $1 AND version = $2`
+		if _, err := s.q(ctx).ExecContext(ctx, query, namespace, retired); err != nil {
+			return false, fmt.Errorf("failed to delete namespace key %d: %w", retired, err)
+		}
+		deleted[retired] = true
+	}
+
+	for _, key := range keys {
+		if key.Version < version && !deleted[key.Version] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
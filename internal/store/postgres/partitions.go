@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// executionPartitionPrefix prefixes the names of the monthly partitions of the job executions, followed by the month
+// as YYYYMM. The months are in UTC.
+const executionPartitionPrefix = "job_executions_p"
+
+// CreateExecutionPartitions creates the missing partitions of the months from the one of from to the one of until.
+// The executions created in a month without a partition are stored in the default partition, which must be empty
+// for the range of a created partition.
+func (s *pgStore) CreateExecutionPartitions(ctx context.Context, from, until time.Time) ([]string, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateExecutionPartitions")
+	defer cancel()
+
+	existing, err := s.executionPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	for month := startOfMonth(from); !month.After(until); month = month.AddDate(0, 1, 0) {
+		name := executionPartitionName(month)
+		if _, ok := existing[name]; ok {
+			continue
+		}
+
+		query := fmt.Sprintf(`CREATE TABLE %s PARTITION OF job_executions FOR VALUES FROM ('%s') TO ('%s')`,
+			name, month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339))
+		if _, err := s.q(ctx).ExecContext(ctx, query); err != nil {
+			return created, fmt.Errorf("failed to create job execution partition %s: %w", name, err)
+		}
+
+		created = append(created, name)
+	}
+
+	return created, nil
+}
+
+// DropExecutionPartitions drops the partitions of the months ending before the given time, oldest first. The logs,
+// outputs and timelines of the executions are deleted in the same transaction, as they can't reference the partitioned
+// executions.
+func (s *pgStore) DropExecutionPartitions(ctx context.Context, before time.Time) ([]string, error) {
+	ctx, cancel := s.withTimeout(ctx, "DropExecutionPartitions")
+	defer cancel()
+
+	existing, err := s.executionPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired []string
+	for name, month := range existing {
+		if !month.AddDate(0, 1, 0).After(before) {
+			expired = append(expired, name)
+		}
+	}
+	sort.Strings(expired)
+
+	var dropped []string
+	for _, name := range expired {
+		if err := s.dropExecutionPartition(ctx, name); err != nil {
+			return dropped, fmt.Errorf("failed to drop job execution partition %s: %w", name, err)
+		}
+
+		dropped = append(dropped, name)
+	}
+
+	return dropped, nil
+}
+
+func (s *pgStore) dropExecutionPartition(ctx context.Context, name string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, table := range []string{"job_execution_logs", "job_execution_outputs", "job_execution_events"} {
+		query := fmt.Sprintf(`DELETE FROM %s l USING %s e WHERE l.execution_id = e.id`, table, name)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE %s`, name)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// executionPartitions returns the months of the monthly partitions of the job executions, by partition name.
+func (s *pgStore) executionPartitions(ctx context.Context) (map[string]time.Time, error) {
+	query := `
+		SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'job_executions'::regclass
+	`
+
+	var names []string
+	if err := s.q(ctx).SelectContext(ctx, &names, query); err != nil {
+		return nil, fmt.Errorf("failed to list job execution partitions: %w", err)
+	}
+
+	partitions := make(map[string]time.Time, len(names))
+	for _, name := range names {
+		if month, ok := executionPartitionMonth(name); ok {
+			partitions[name] = month
+		}
+	}
+
+	return partitions, nil
+}
+
+func executionPartitionName(month time.Time) string {
+	return executionPartitionPrefix + month.Format("200601")
+}
+
+// executionPartitionMonth parses the month of a monthly partition from its name, the default partition has none.
+func executionPartitionMonth(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, executionPartitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	month, err := time.ParseInLocation("200601", suffix, time.UTC)
+	return month, err == nil
+}
+
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
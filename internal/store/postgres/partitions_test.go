@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutionPartitionNames(t *testing.T) {
+	month := startOfMonth(time.Date(2026, 10, 15, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60)))
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), month)
+
+	name := executionPartitionName(month)
+	assert.Equal(t, "job_executions_p202610", name)
+
+	parsed, ok := executionPartitionMonth(name)
+	assert.True(t, ok)
+	assert.Equal(t, month, parsed)
+
+	_, ok = executionPartitionMonth("job_executions_default")
+	assert.False(t, ok)
+
+	_, ok = executionPartitionMonth("job_executions_p2026")
+	assert.False(t, ok)
+}
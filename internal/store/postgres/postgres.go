@@ -3,64 +3,233 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
 	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
 	"github.com/TimeSnap/distributed-scheduler/internal/store"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"gopkg.in/guregu/null.v4"
 )
 
+const (
+	insertJobQuery = `
+	INSERT INTO jobs (
+		id,
+		namespace,
+		name,
+	 	type,
+	 	status,
+	 	version,
+	 	execute_at,
+	 	cron_schedule,
+	 	http_job,
+	 	amqp_job,
+	 	custom_job,
+	 	created_at,
+	 	updated_at,
+	 	next_run,
+	    tags,
+	    metadata,
+	    priority,
+	    required_capabilities,
+	    execution_timeout,
+	    misfire_policy,
+	    sla,
+	    result_sink,
+	    metric_labels,
+	    ttl,
+	    owner,
+	    team,
+	    external_id
+	) VALUES (
+	 	:id,
+	 	:namespace,
+	 	:name,
+	 	:type,
+	 	:status,
+	 	:version,
+	 	:execute_at,
+	 	:cron_schedule,
+	 	:http_job,
+	 	:amqp_job,
+	 	:custom_job,
+	 	:created_at,
+	 	:updated_at,
+	 	:next_run,
+    	:tags,
+    	:metadata,
+    	:priority,
+    	:required_capabilities,
+    	:execution_timeout,
+    	:misfire_policy,
+    	:sla,
+    	:result_sink,
+    	:metric_labels,
+    	:ttl,
+    	:owner,
+    	:team,
+    	:external_id
+	)
+ `
+
+	updateJobQuery = `
+		UPDATE
+			jobs
+		SET
+			 name = :name,
+			 type = :type,
+			 execute_at = :execute_at,
+			 cron_schedule = :cron_schedule,
+			 http_job = :http_job,
+			 amqp_job = :amqp_job,
+			 custom_job = :custom_job,
+			 updated_at = :updated_at,
+			 next_run = :next_run,
+			 tags = :tags,
+			 metadata = :metadata,
+			 priority = :priority,
+			 required_capabilities = :required_capabilities,
+			 execution_timeout = :execution_timeout,
+			 misfire_policy = :misfire_policy,
+			 sla = :sla,
+			 result_sink = :result_sink,
+			 metric_labels = :metric_labels,
+			 ttl = :ttl,
+			 owner = :owner,
+			 team = :team,
+			 external_id = :external_id,
+			 version = version + 1
+		WHERE id = :id AND version = :version
+		`
+)
+
+// jobCopyColumns are the columns written when copying new jobs, in the order of the values of jobDB.copyValues.
+var jobCopyColumns = []string{
+	"id",
+	"namespace",
+	"name",
+	"type",
+	"status",
+	"version",
+	"execute_at",
+	"cron_schedule",
+	"http_job",
+	"amqp_job",
+	"custom_job",
+	"created_at",
+	"updated_at",
+	"next_run",
+	"tags",
+	"metadata",
+	"priority",
+	"required_capabilities",
+	"execution_timeout",
+	"misfire_policy",
+	"ttl",
+	"sla",
+	"result_sink",
+	"metric_labels",
+	"owner",
+	"team",
+	"external_id",
+}
+
+// priorityWeightSQL evaluates to the weight of the priority of a job.
+var priorityWeightSQL = func() string {
+	weight := "CASE priority"
+	for _, priority := range model.JobPriorities {
+		weight += fmt.Sprintf(" WHEN '%s' THEN %d", priority, priority.Weight())
+	}
+
+	return weight + fmt.Sprintf(" ELSE %d END", model.JobPriorityNormal.Weight())
+}()
+
 type pgStore struct {
-	db  *sqlx.DB
-	log *otelzap.Logger
+	db       *sqlx.DB
+	log      *otelzap.Logger
+	timeouts database.QueryTimeouts
 }
 
-// New creates a new PostgresSQL store.
-func New(db *sqlx.DB, log *otelzap.Logger) store.Storer {
-	return &pgStore{
+// Option configures the Postgres stores.
+type Option func(*pgStore)
+
+// WithQueryTimeouts bounds the duration of the operations of the stores.
+func WithQueryTimeouts(timeouts database.QueryTimeouts) Option {
+	return func(s *pgStore) {
+		s.timeouts = timeouts
+	}
+}
+
+func newStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) *pgStore {
+	s := &pgStore{
 		db:  db,
 		log: log,
 	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// New creates a new PostgresSQL store.
+func New(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.Storer {
+	return newStore(db, log, options...)
 }
 
 func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job) error {
+	ctx, cancel := s.withTimeout(ctx, "UpdateJob")
+	defer cancel()
+
 	dbJob, err := toJobDB(job)
 	if err != nil {
 		return fmt.Errorf("failed to convert job to database job: %w", err)
 	}
 
-	query := `
-		UPDATE
-			jobs
-		SET
-			 type = :type,
-			 execute_at = :execute_at,
-			 cron_schedule = :cron_schedule,
-			 http_job = :http_job,
-			 amqp_job = :amqp_job,
-			 updated_at = :updated_at,
-			 next_run = :next_run
-		WHERE id = :id
-		`
-
-	_, err = s.db.NamedExecContext(ctx, query, dbJob)
+	res, err := s.q(ctx).NamedExecContext(ctx, updateJobQuery, dbJob)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return jobConflict(err)
+		}
 		return fmt.Errorf("failed to update job in database: %w", err)
 	}
 
+	if err := checkJobUpdated(res, job); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error) {
+func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit uint64, cursor *model.Cursor) ([]*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutions")
+	defer cancel()
+
+	args := []interface{}{jobID, limit}
 	extraFilter := ""
 	if failedOnly {
-		extraFilter = " AND status = 'FAILED'"
+		extraFilter = " AND status IN ('FAILED', 'TIMED_OUT')"
+	}
+
+	// keyset pagination on (start_time, id)
+	if cursor != nil {
+		id, err := strconv.Atoi(cursor.ID)
+		if err != nil {
+			return nil, errs.ErrInvalidCursor
+		}
+
+		args = append(args, cursor.Time, id)
+		extraFilter += fmt.Sprintf(" AND (start_time, id) < ($%d, $%d)", len(args)-1, len(args))
 	}
 
 	query := `
@@ -70,11 +239,11 @@ func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedO
 			job_executions
 		WHERE
 			job_id = $1` + extraFilter +
-		` ORDER BY start_time DESC
-		LIMIT $2 OFFSET $3`
+		` ORDER BY start_time DESC, id DESC
+		LIMIT $2`
 
 	var dbExecutions []*executionDB
-	err := s.db.SelectContext(ctx, &dbExecutions, query, jobID, limit, offset)
+	err := s.q(ctx).SelectContext(ctx, &dbExecutions, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get job executions from database: %w", err)
 	}
@@ -89,7 +258,201 @@ func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedO
 
 }
 
+// ExportJobExecutions calls fn with every execution of the job started within the range, oldest first.
+// Executions are read from the database as they are exported, so the whole range is never held in memory.
+func (s *pgStore) ExportJobExecutions(ctx context.Context, jobID uuid.UUID, r model.ExecutionExportRange, fn func(*model.JobExecution) error) error {
+	query := `
+		SELECT * FROM job_executions
+		WHERE job_id = $1 AND start_time >= $2 AND start_time < $3
+		ORDER BY start_time, id
+	`
+	rows, err := s.q(ctx).QueryxContext(ctx, query, jobID, r.From, r.To)
+	if err != nil {
+		return fmt.Errorf("failed to export job executions from database: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dbExecution executionDB
+		if err := rows.StructScan(&dbExecution); err != nil {
+			return fmt.Errorf("failed to scan job execution: %w", err)
+		}
+
+		if err := fn(dbExecution.ToModel()); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to export job executions from database: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestJobExecutions returns up to limit most recent executions of each of the jobs.
+func (s *pgStore) GetLatestJobExecutions(ctx context.Context, jobIDs []uuid.UUID, limit uint64) ([]*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetLatestJobExecutions")
+	defer cancel()
+
+	query := `
+		SELECT e.*
+		FROM unnest($1::uuid[]) AS j(id)
+		CROSS JOIN LATERAL (
+			SELECT * FROM job_executions
+			WHERE job_id = j.id
+			ORDER BY start_time DESC, id DESC
+			LIMIT $2
+		) e
+	`
+
+	var dbExecutions []*executionDB
+	if err := s.q(ctx).SelectContext(ctx, &dbExecutions, query, uuidArray(jobIDs), limit); err != nil {
+		return nil, fmt.Errorf("failed to get job executions from database: %w", err)
+	}
+
+	executions := []*model.JobExecution{}
+	for _, dbExecution := range dbExecutions {
+		executions = append(executions, dbExecution.ToModel())
+	}
+
+	return executions, nil
+}
+
+// GetJobExecutionStats aggregates the executions of each of the jobs. Jobs without executions are omitted.
+func (s *pgStore) GetJobExecutionStats(ctx context.Context, jobIDs []uuid.UUID) ([]model.JobExecutionStats, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutionStats")
+	defer cancel()
+
+	query := `
+		SELECT
+			job_id,
+			count(*) AS total,
+			count(*) FILTER (WHERE status = 'SUCCESSFUL') AS successful,
+			count(*) FILTER (WHERE status = 'FAILED') AS failed,
+			count(*) FILTER (WHERE status = 'CANCELLED') AS cancelled,
+			count(*) FILTER (WHERE status = 'TIMED_OUT') AS timed_out,
+			avg(extract(EPOCH FROM end_time - start_time) * 1000) AS average_duration_ms,
+			max(start_time) AS last_execution_at
+		FROM job_executions
+		WHERE job_id = ANY($1::uuid[])
+		GROUP BY job_id
+	`
+
+	var dbStats []executionStatsDB
+	if err := s.q(ctx).SelectContext(ctx, &dbStats, query, uuidArray(jobIDs)); err != nil {
+		return nil, fmt.Errorf("failed to get job execution stats from database: %w", err)
+	}
+
+	stats := []model.JobExecutionStats{}
+	for _, dbStat := range dbStats {
+		stats = append(stats, dbStat.ToModel())
+	}
+
+	return stats, nil
+}
+
+func (s *pgStore) GetSLAJobs(ctx context.Context, after uuid.UUID, limit uint) ([]*model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetSLAJobs")
+	defer cancel()
+
+	query := `SELECT * FROM jobs WHERE sla IS NOT NULL AND id > $1 ORDER BY id LIMIT $2`
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, after, limit); err != nil {
+		return nil, fmt.Errorf("failed to get jobs with an SLA from database: %w", err)
+	}
+
+	jobs := make([]*model.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		job, err := dbJob.ToJob()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// GetJobSLAStatuses aggregates the executions of each of the jobs started within the window of its SLA. Cancelled
+// executions and pending retries are ignored, and running executions are late once they are past their deadline.
+func (s *pgStore) GetJobSLAStatuses(ctx context.Context, at time.Time, jobIDs []uuid.UUID) ([]model.JobSLAStatus, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobSLAStatuses")
+	defer cancel()
+
+	query := `
+		SELECT
+			j.id AS job_id,
+			j.sla,
+			count(e.id) FILTER (WHERE e.status IN ('SUCCESSFUL', 'FAILED', 'TIMED_OUT')) AS executions,
+			count(e.id) FILTER (WHERE e.status = 'SUCCESSFUL') AS successful,
+			count(e.id) FILTER (
+				WHERE e.status IN ('SUCCESSFUL', 'FAILED', 'TIMED_OUT', 'RUNNING')
+				AND coalesce(e.end_time, $1) > e.scheduled_time + make_interval(secs => (j.sla->>'max_delay')::float8)
+			) AS late
+		FROM jobs j
+		LEFT JOIN job_executions e ON e.job_id = j.id
+			AND e.start_time > $1 - make_interval(secs => coalesce((j.sla->>'window')::float8, $3))
+			AND e.start_time <= $1
+		WHERE j.id = ANY($2::uuid[]) AND j.sla IS NOT NULL
+		GROUP BY j.id
+	`
+
+	var dbStatuses []slaStatusDB
+	if err := s.q(ctx).SelectContext(ctx, &dbStatuses, query, at, uuidArray(jobIDs), model.DefaultSLAWindow.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to get job SLA statuses from database: %w", err)
+	}
+
+	statuses := make([]model.JobSLAStatus, 0, len(dbStatuses))
+	for _, dbStatus := range dbStatuses {
+		status, err := dbStatus.ToModel(at)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db SLA status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (s *pgStore) CountJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool) (uint64, error) {
+	ctx, cancel := s.withTimeout(ctx, "CountJobExecutions")
+	defer cancel()
+
+	var count uint64
+	if err := s.q(ctx).GetContext(ctx, &count, `SELECT count(*) FROM job_executions WHERE `+jobExecutionsWhere(failedOnly), jobID); err != nil {
+		return 0, fmt.Errorf("failed to count job executions in database: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *pgStore) EstimateJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool) (uint64, error) {
+	ctx, cancel := s.withTimeout(ctx, "EstimateJobExecutions")
+	defer cancel()
+
+	count, err := s.estimateCount(ctx, `SELECT 1 FROM job_executions WHERE `+jobExecutionsWhere(failedOnly), jobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate job executions in database: %w", err)
+	}
+
+	return count, nil
+}
+
+// jobExecutionsWhere returns the condition selecting the executions of the job given as the first argument.
+func jobExecutionsWhere(failedOnly bool) string {
+	where := `job_id = $1`
+	if failedOnly {
+		where += " AND status IN ('FAILED', 'TIMED_OUT')"
+	}
+
+	return where
+}
+
 func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateJob")
+	defer cancel()
 
 	dbJob, err := toJobDB(job)
 	if err != nil {
@@ -97,36 +460,11 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 	}
 
 	// insert job struct into database
-	query := `
-	INSERT INTO jobs (
-		id,
-	 	type,
-	 	status,
-	 	execute_at,
-	 	cron_schedule,
-	 	http_job,
-	 	amqp_job,
-	 	created_at,
-	 	updated_at,
-	 	next_run,
-	    tags
-	) VALUES (
-	 	:id,
-	 	:type,
-	 	:status,
-	 	:execute_at,
-	 	:cron_schedule,
-	 	:http_job,
-	 	:amqp_job,
-	 	:created_at,
-	 	:updated_at,
-	 	:next_run,
-    	:tags
-	)
- `
-
-	_, err = s.db.NamedExecContext(ctx, query, dbJob)
+	_, err = s.q(ctx).NamedExecContext(ctx, insertJobQuery, dbJob)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return jobConflict(err)
+		}
 		return fmt.Errorf("failed to insert job into database: %w", err)
 	}
 
@@ -134,6 +472,9 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 }
 
 func (s *pgStore) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJob")
+	defer cancel()
+
 	// create a JobDB struct to hold the result of the query
 	var dbJob jobDB
 
@@ -141,7 +482,7 @@ func (s *pgStore) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
 	query := `
         SELECT * FROM jobs WHERE id = $1
     `
-	err := s.db.GetContext(ctx, &dbJob, query, id)
+	err := s.q(ctx).GetContext(ctx, &dbJob, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.ErrJobNotFound
@@ -158,12 +499,68 @@ func (s *pgStore) GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
 	return job, nil
 }
 
+// GetJobByExternalID returns the job of the namespace with the given external ID. Archived jobs are left out, since
+// their external IDs can be reused.
+func (s *pgStore) GetJobByExternalID(ctx context.Context, namespace string, externalID string) (*model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobByExternalID")
+	defer cancel()
+
+	var dbJob jobDB
+	query := `
+		SELECT * FROM jobs
+		WHERE namespace = $1 AND external_id = $2 AND status <> 'ARCHIVED'
+	`
+	err := s.q(ctx).GetContext(ctx, &dbJob, query, namespace, externalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job from database: %w", err)
+	}
+
+	job, err := dbJob.ToJob()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetJobs returns the jobs of the namespace with the given IDs in a single query.
+func (s *pgStore) GetJobs(ctx context.Context, namespace string, ids []uuid.UUID) ([]model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobs")
+	defer cancel()
+
+	query := `
+		SELECT * FROM jobs WHERE namespace = $1 AND id = ANY($2::uuid[])
+	`
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, namespace, uuidArray(ids)); err != nil {
+		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
+	}
+
+	jobs := make([]model.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		job, err := dbJob.ToJob()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, nil
+}
+
 func (s *pgStore) DeleteJob(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := s.withTimeout(ctx, "DeleteJob")
+	defer cancel()
+
 	// delete job from database
 	query := `
         DELETE FROM jobs WHERE id = $1
     `
-	_, err := s.db.ExecContext(ctx, query, id)
+	_, err := s.q(ctx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete job from database: %w", err)
 	}
@@ -171,21 +568,31 @@ func (s *pgStore) DeleteJob(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (s *pgStore) ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error) {
+func (s *pgStore) ListJobs(ctx context.Context, limit uint64, cursor *model.Cursor, filter model.JobFilter) ([]model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListJobs")
+	defer cancel()
+
 	// get all jobs from database
-	args := []interface{}{limit, offset}
+	args := []interface{}{limit}
+	where, args := jobFilterQuery(filter, args)
+
+	// keyset pagination on (created_at, id)
+	if cursor != nil {
+		id, err := uuid.Parse(cursor.ID)
+		if err != nil {
+			return nil, errs.ErrInvalidCursor
+		}
+
+		args = append(args, cursor.Time, id)
+		where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
 	query := `
-        SELECT * FROM jobs ORDER BY id DESC LIMIT $1 OFFSET $2 
+        SELECT * FROM jobs WHERE ` + where + ` ORDER BY created_at DESC, id DESC LIMIT $1
     `
-	if len(tags) > 0 {
-		args = append(args, tags)
-		query = `
-			SELECT * FROM jobs WHERE tags @> $3 ORDER BY id DESC LIMIT $1 OFFSET $2 
-		`
-	}
 
 	var dbJobs []jobDB
-	err := s.db.SelectContext(ctx, &dbJobs, query, args...)
+	err := s.q(ctx).SelectContext(ctx, &dbJobs, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
 	}
@@ -193,6 +600,14 @@ func (s *pgStore) ListJobs(ctx context.Context, limit, offset uint64, tags []str
 	// convert JobDB structs to Job structs
 	jobs := []model.Job{}
 	for _, dbJob := range dbJobs {
+		// skip decrypting the HTTP and AMQP definitions when they are not returned
+		if !filter.Fields.IncludesPayloads() {
+			dbJob.HTTPJob, dbJob.AMQPJob = nil, nil
+		}
+		if !filter.Fields.Includes("result_sink") {
+			dbJob.ResultSink = nil
+		}
+
 		job, err := dbJob.ToJob()
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
@@ -203,7 +618,38 @@ func (s *pgStore) ListJobs(ctx context.Context, limit, offset uint64, tags []str
 	return jobs, nil
 }
 
-func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error) {
+func (s *pgStore) CountJobs(ctx context.Context, filter model.JobFilter) (uint64, error) {
+	ctx, cancel := s.withTimeout(ctx, "CountJobs")
+	defer cancel()
+
+	where, args := jobFilterQuery(filter, []interface{}{})
+	query := `SELECT count(*) FROM jobs WHERE ` + where
+
+	var count uint64
+	if err := s.q(ctx).GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to count jobs in database: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *pgStore) EstimateJobs(ctx context.Context, filter model.JobFilter) (uint64, error) {
+	ctx, cancel := s.withTimeout(ctx, "EstimateJobs")
+	defer cancel()
+
+	where, args := jobFilterQuery(filter, []interface{}{})
+	count, err := s.estimateCount(ctx, `SELECT 1 FROM jobs WHERE `+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate jobs in database: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, dueBy time.Time, lockedUntil time.Time, instanceID string, namespaces []string, jobTypes []string, capabilities []string, shard model.JobShard, limit uint) ([]*model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobsToRun")
+	defer cancel()
+
 	tx, err := s.db.Beginx()
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -211,14 +657,51 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 
 	defer rollback(tx, s.log)
 
-	// Get jobs that should be run at time at and are not currently locked
+	// Get jobs that should be run by time dueBy and are not locked at time at, leaving out the jobs
+	// that would exceed the concurrent executions quota of their namespace. Locked jobs are the
+	// ones currently being executed. Jobs of types the runner has no room for and jobs requiring
+	// capabilities the runner doesn't have are left
+	// to other runners, and so are the jobs of other shards until they are overdue by the grace period.
+	// Jobs skipping misfires whose lock lapsed with an execution still running are left to the zombie reaper.
+	// Jobs are ordered by weighted round-robin over their priorities: the n-th due job of a priority
+	// comes at position n / weight, so higher priorities come first while every priority gets its share.
 	rows, err := tx.QueryContext(ctx, `
-	   SELECT *
+	   WITH locked AS (
+	       SELECT namespace, count(*) AS count
+	       FROM jobs
+	       WHERE locked_until > $2
+	       GROUP BY namespace
+	   ), candidates AS (
+	       SELECT id, namespace,
+	              row_number() OVER (PARTITION BY namespace ORDER BY `+priorityWeightSQL+` DESC, next_run) AS rank,
+	              row_number() OVER (PARTITION BY priority ORDER BY next_run)::float / `+priorityWeightSQL+` AS fair_rank
+	       FROM jobs
+	       WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $2) AND status = 'RUNNING'
+	         AND (cardinality($4::text[]) = 0 OR namespace = ANY($4))
+	         AND required_capabilities <@ $5::text[]
+	         AND (cardinality($9::text[]) = 0 OR type = ANY($9))
+	         AND ($6::int <= 1 OR mod(abs(hashtext(id::text)::bigint), $6) = $7 OR next_run <= $8)
+	         AND (misfire_policy <> 'SKIP' OR locked_by IS NULL OR NOT EXISTS (
+	             SELECT 1 FROM job_executions e
+	             WHERE e.job_id = jobs.id AND e.status = 'RUNNING' AND e.instance_id = jobs.locked_by
+	         ))
+	   ), eligible AS (
+	       SELECT candidates.id, candidates.fair_rank
+	       FROM candidates
+	       LEFT JOIN namespaces ON namespaces.name = candidates.namespace
+	       LEFT JOIN locked ON locked.namespace = candidates.namespace
+	       WHERE namespaces.max_concurrent_executions IS NULL
+	          OR candidates.rank <= namespaces.max_concurrent_executions - coalesce(locked.count, 0)
+	   )
+	   SELECT jobs.*
 	   FROM jobs
+	   JOIN eligible ON eligible.id = jobs.id
 	   WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $2) AND status = 'RUNNING'
+	   ORDER BY eligible.fair_rank, `+priorityWeightSQL+` DESC, next_run
 	   LIMIT $3
-	   FOR UPDATE SKIP LOCKED
-	`, at, at, limit)
+	   FOR UPDATE OF jobs SKIP LOCKED
+	`, dueBy, at, limit, pq.StringArray(namespaces), append(pq.StringArray{}, capabilities...),
+		shard.Count, shard.Index, at.Add(-shard.Grace), pq.StringArray(jobTypes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query jobs: %w", err)
 	}
@@ -237,6 +720,8 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
 		}
+		// Locks are cleared when released, the lock of a job still locked by an instance expired
+		job.ReclaimedFrom = dbJob.LockedBy.String
 		jobs = append(jobs, job)
 
 		// Mark the job as locked by this instance
@@ -256,33 +741,776 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 	return jobs, nil
 }
 
-func (s *pgStore) FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error {
+func (s *pgStore) GetDueJobs(ctx context.Context, at time.Time) (uint64, null.Time, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetDueJobs")
+	defer cancel()
 
-	// finish job in database
 	query := `
-		UPDATE jobs SET 
-		        next_run = $1, 
+		SELECT count(*) AS count, min(next_run) AS oldest
+		FROM jobs
+		WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $1) AND status = 'RUNNING'
+	`
+
+	var due struct {
+		Count  uint64    `db:"count"`
+		Oldest null.Time `db:"oldest"`
+	}
+	if err := s.q(ctx).GetContext(ctx, &due, query, at); err != nil {
+		return 0, null.Time{}, fmt.Errorf("failed to get due jobs from database: %w", err)
+	}
+
+	return due.Count, due.Oldest, nil
+}
+
+func (s *pgStore) GetMissedJobs(ctx context.Context, at, before time.Time, limit uint) ([]*model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetMissedJobs")
+	defer cancel()
+
+	// jobs locked by a runner are being executed, however late
+	query := `
+		SELECT * FROM jobs
+		WHERE next_run <= $2 AND (locked_until IS NULL OR locked_until <= $1) AND status = 'RUNNING'
+		ORDER BY next_run
+		LIMIT $3
+	`
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, at, before, limit); err != nil {
+		return nil, fmt.Errorf("failed to get missed jobs from database: %w", err)
+	}
+
+	jobs := make([]*model.Job, 0, len(dbJobs))
+	for _, dbJob := range dbJobs {
+		job, err := dbJob.ToJob()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// DisableFailingJob stops the running job if its last finished executions failed or timed out failures times in a
+// row. Cancelled executions break the streak.
+func (s *pgStore) DisableFailingJob(ctx context.Context, jobID uuid.UUID, failures int) (*model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "DisableFailingJob")
+	defer cancel()
+
+	query := `
+		UPDATE jobs SET status = 'STOPPED', updated_at = now(), version = version + 1
+		WHERE id = $1 AND status = 'RUNNING' AND (
+			SELECT count(*) FILTER (WHERE status IN ('FAILED', 'TIMED_OUT'))
+			FROM (
+				SELECT status FROM job_executions
+				WHERE job_id = $1 AND status NOT IN ('RUNNING', 'PENDING')
+				ORDER BY start_time DESC, id DESC
+				LIMIT $2
+			) latest
+		) = $2
+		RETURNING *
+	`
+
+	var dbJob jobDB
+	if err := s.q(ctx).GetContext(ctx, &dbJob, query, jobID, failures); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to disable job in database: %w", err)
+	}
+
+	job, err := dbJob.ToJob()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *pgStore) GetBacklog(ctx context.Context, at time.Time) (*model.Backlog, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetBacklog")
+	defer cancel()
+
+	query := `
+		SELECT due.count AS due_jobs, due.oldest AS oldest_due_at, executions.pending AS pending_retries, executions.running AS running_executions
+		FROM (
+			SELECT count(*) AS count, min(next_run) AS oldest
+			FROM jobs
+			WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $1) AND status = 'RUNNING'
+		) due, (
+			SELECT count(*) FILTER (WHERE status = 'PENDING') AS pending, count(*) FILTER (WHERE status = 'RUNNING') AS running
+			FROM job_executions
+			WHERE end_time IS NULL
+		) executions
+	`
+
+	var backlog struct {
+		DueJobs           uint64    `db:"due_jobs"`
+		OldestDueAt       null.Time `db:"oldest_due_at"`
+		PendingRetries    uint64    `db:"pending_retries"`
+		RunningExecutions uint64    `db:"running_executions"`
+	}
+	if err := s.q(ctx).GetContext(ctx, &backlog, query, at); err != nil {
+		return nil, fmt.Errorf("failed to get backlog from database: %w", err)
+	}
+
+	return &model.Backlog{
+		DueJobs:           backlog.DueJobs,
+		OldestDueAt:       backlog.OldestDueAt,
+		PendingRetries:    backlog.PendingRetries,
+		RunningExecutions: backlog.RunningExecutions,
+	}, nil
+}
+
+// ExtendJobLock extends the lock of the job until lockedUntil. It returns ErrJobLockLost if the job is not
+// locked by the instance anymore, e.g. because it was finished or locked by another instance.
+func (s *pgStore) ExtendJobLock(ctx context.Context, jobID uuid.UUID, instanceID string, lockedUntil time.Time) error {
+	ctx, cancel := s.withTimeout(ctx, "ExtendJobLock")
+	defer cancel()
+
+	result, err := s.q(ctx).ExecContext(ctx, `
+		UPDATE jobs SET locked_until = $3
+		WHERE id = $1 AND locked_by = $2 AND locked_until IS NOT NULL
+	`, jobID, instanceID, lockedUntil)
+	if err != nil {
+		return fmt.Errorf("failed to extend job lock in database: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to extend job lock in database: %w", err)
+	}
+
+	if rows == 0 {
+		return errs.ErrJobLockLost
+	}
+
+	return nil
+}
+
+// ReleaseJobLock unlocks the job if it is locked by the instance, leaving its next run unchanged so it is
+// picked up again right away.
+func (s *pgStore) ReleaseJobLock(ctx context.Context, jobID uuid.UUID, instanceID string) error {
+	ctx, cancel := s.withTimeout(ctx, "ReleaseJobLock")
+	defer cancel()
+
+	_, err := s.q(ctx).ExecContext(ctx, `
+		UPDATE jobs SET locked_until = null, locked_by = null
+		WHERE id = $1 AND locked_by = $2
+	`, jobID, instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to release job lock in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error {
+	ctx, cancel := s.withTimeout(ctx, "FinishJob")
+	defer cancel()
+
+	// finish job in database, marking it as completed if it will not run again
+	query := `
+		UPDATE jobs SET 
+		        next_run = $1, 
+		        completed_at = CASE WHEN $1::timestamptz IS NULL THEN now() ELSE NULL END,
 		        locked_until = null, locked_by = null, updated_at = now() 
 		WHERE id = $2
 	`
-	_, err := s.db.ExecContext(ctx, query, nextRun, jobID)
+	_, err := s.q(ctx).ExecContext(ctx, query, nextRun, jobID)
 	if err != nil {
 		return fmt.Errorf("failed to finish job in database: %w", err)
 	}
 
 	return nil
 }
-func (s *pgStore) CreateJobExecution(ctx context.Context, jobID uuid.UUID, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
+func (s *pgStore) CreateJobExecution(ctx context.Context, jobID uuid.UUID, scheduledTime null.Time, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateJobExecution")
+	defer cancel()
 
 	// create job execution in database
 	query := `
-		INSERT INTO job_executions (job_id, start_time, end_time, status, error_message, created_at) 
-		VALUES ($1, $2, $3, $4, $5, now())
+		WITH execution AS (
+			INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, scheduled_time, start_time, end_time, status, error_message, created_at)
+			SELECT id, namespace, version, type, http_job, amqp_job, custom_job, $2, $3, $4, $5, $6, now()
+			FROM jobs WHERE id = $1
+			RETURNING job_id, status
+		)
+		UPDATE jobs SET last_execution_status = execution.status
+		FROM execution
+		WHERE jobs.id = execution.job_id
 	`
-	_, err := s.db.ExecContext(ctx, query, jobID, startTime, stopTime, status, errorMessage)
+	_, err := s.q(ctx).ExecContext(ctx, query, jobID, scheduledTime, startTime, stopTime, status, errorMessage)
 	if err != nil {
 		return fmt.Errorf("failed to create job execution in database: %w", err)
 	}
 
 	return nil
 }
+
+// CreateJobExecutions records the finished executions in a single statement, each created at its start time so it
+// is stored in the partition of its month.
+func (s *pgStore) CreateJobExecutions(ctx context.Context, executions []*model.JobExecution) error {
+	if len(executions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := s.withTimeout(ctx, "CreateJobExecutions")
+	defer cancel()
+
+	records, err := json.Marshal(executions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job executions: %w", err)
+	}
+
+	// the last execution status of each job is the one of its latest execution
+	query := `
+		WITH execution AS (
+			INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, scheduled_time, start_time, end_time, status, error_message, created_at)
+			SELECT j.id, j.namespace, j.version, j.type, j.http_job, j.amqp_job, j.custom_job, v.scheduled_time, v.start_time, v.end_time, v.status, v.error_message, v.start_time
+			FROM jsonb_to_recordset($1::jsonb) AS v(job_id UUID, scheduled_time TIMESTAMPTZ, start_time TIMESTAMPTZ,
+				end_time TIMESTAMPTZ, status job_execution_status_enum, error_message TEXT)
+			JOIN jobs j ON j.id = v.job_id
+			RETURNING job_id, status, start_time
+		)
+		UPDATE jobs SET last_execution_status = latest.status
+		FROM (SELECT DISTINCT ON (job_id) job_id, status FROM execution ORDER BY job_id, start_time DESC) latest
+		WHERE jobs.id = latest.job_id
+	`
+	if _, err := s.q(ctx).ExecContext(ctx, query, records); err != nil {
+		return fmt.Errorf("failed to create job executions in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) StartJobExecution(ctx context.Context, jobID uuid.UUID, scheduledTime null.Time, startTime time.Time) (int, error) {
+	ctx, cancel := s.withTimeout(ctx, "StartJobExecution")
+	defer cancel()
+
+	query := `
+		INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, scheduled_time, start_time, status, instance_id, created_at)
+		SELECT id, namespace, version, type, http_job, amqp_job, custom_job, $2, $3, 'RUNNING', locked_by, now()
+		FROM jobs WHERE id = $1
+		RETURNING id
+	`
+
+	var id int
+	if err := s.q(ctx).GetContext(ctx, &id, query, jobID, scheduledTime, startTime); err != nil {
+		return 0, fmt.Errorf("failed to create job execution in database: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *pgStore) FinishJobExecution(ctx context.Context, executionID int, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
+	ctx, cancel := s.withTimeout(ctx, "FinishJobExecution")
+	defer cancel()
+
+	// finish the execution and update the last execution status of the job
+	query := `
+		WITH execution AS (
+			UPDATE job_executions SET end_time = $2, status = $3, error_message = $4
+			WHERE id = $1
+			RETURNING job_id, status
+		)
+		UPDATE jobs SET last_execution_status = execution.status
+		FROM execution
+		WHERE jobs.id = execution.job_id
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query, executionID, stopTime, status, errorMessage)
+	if err != nil {
+		return fmt.Errorf("failed to finish job execution in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) CancelJobExecution(ctx context.Context, namespace string, executionID int) error {
+	ctx, cancel := s.withTimeout(ctx, "CancelJobExecution")
+	defer cancel()
+
+	query := `
+		UPDATE job_executions SET
+			cancel_requested_at = coalesce(cancel_requested_at, now()),
+			-- retries that haven't started yet are cancelled right away
+			status = CASE WHEN status = 'PENDING' THEN 'CANCELLED' ELSE status END,
+			end_time = CASE WHEN status = 'PENDING' THEN now() ELSE end_time END
+		WHERE id = $1 AND namespace = $2 AND status IN ('RUNNING', 'PENDING')
+	`
+	res, err := s.q(ctx).ExecContext(ctx, query, executionID, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to cancel job execution in database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to cancel job execution in database: %w", err)
+	}
+
+	if affected > 0 {
+		return nil
+	}
+
+	// distinguish between a missing and an already finished execution
+	var exists bool
+	if err := s.q(ctx).GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM job_executions WHERE id = $1 AND namespace = $2)`, executionID, namespace); err != nil {
+		return fmt.Errorf("failed to get job execution from database: %w", err)
+	}
+
+	if !exists {
+		return errs.ErrExecutionNotFound
+	}
+
+	return errs.ErrExecutionNotRunning
+}
+
+func (s *pgStore) IsJobExecutionCancelled(ctx context.Context, executionID int) (bool, error) {
+	ctx, cancel := s.withTimeout(ctx, "IsJobExecutionCancelled")
+	defer cancel()
+
+	query := `SELECT cancel_requested_at IS NOT NULL FROM job_executions WHERE id = $1`
+
+	var cancelled bool
+	if err := s.q(ctx).GetContext(ctx, &cancelled, query, executionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errs.ErrExecutionNotFound
+		}
+		return false, fmt.Errorf("failed to get job execution from database: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+func (s *pgStore) GetCancelledJobExecutions(ctx context.Context, executionIDs []int) ([]int, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetCancelledJobExecutions")
+	defer cancel()
+
+	query := `SELECT id FROM job_executions WHERE id = ANY($1::int[]) AND cancel_requested_at IS NOT NULL`
+
+	cancelled := []int{}
+	if err := s.q(ctx).SelectContext(ctx, &cancelled, query, executionIDs); err != nil {
+		return nil, fmt.Errorf("failed to get cancelled job executions from database: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+func (s *pgStore) UpdateJobExecutionProgress(ctx context.Context, executionID int, progress model.ExecutionProgress) error {
+	ctx, cancel := s.withTimeout(ctx, "UpdateJobExecutionProgress")
+	defer cancel()
+
+	query := `
+		UPDATE job_executions SET progress_percent = $2, progress_message = $3, progress_updated_at = $4
+		WHERE id = $1 AND status = 'RUNNING'
+	`
+	res, err := s.q(ctx).ExecContext(ctx, query, executionID, progress.Percent, progress.Message, progress.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update job execution progress in database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update job execution progress in database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrExecutionNotRunning
+	}
+
+	return nil
+}
+
+func (s *pgStore) SaveJobExecutionLogs(ctx context.Context, logs model.ExecutionLogs) error {
+	ctx, cancel := s.withTimeout(ctx, "SaveJobExecutionLogs")
+	defer cancel()
+
+	entries, err := json.Marshal(logs.Entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job execution logs: %w", err)
+	}
+
+	query := `
+		INSERT INTO job_execution_logs (execution_id, job_id, entries, truncated, created_at)
+		SELECT id, job_id, $2, $3, now() FROM job_executions WHERE id = $1
+		ON CONFLICT (execution_id) DO UPDATE SET entries = excluded.entries, truncated = excluded.truncated
+	`
+	_, err = s.q(ctx).ExecContext(ctx, query, logs.ExecutionID, entries, logs.Truncated)
+	if err != nil {
+		return fmt.Errorf("failed to save job execution logs in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetJobExecutionLogs(ctx context.Context, jobID uuid.UUID, executionID int) (*model.ExecutionLogs, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutionLogs")
+	defer cancel()
+
+	// executions without captured logs have no entries
+	query := `
+		SELECT e.id, l.entries, coalesce(l.truncated, false) AS truncated
+		FROM job_executions e
+		LEFT JOIN job_execution_logs l ON l.execution_id = e.id
+		WHERE e.id = $1 AND e.job_id = $2
+	`
+
+	var dbLogs executionLogsDB
+	if err := s.q(ctx).GetContext(ctx, &dbLogs, query, executionID, jobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrExecutionNotFound
+		}
+		return nil, fmt.Errorf("failed to get job execution logs from database: %w", err)
+	}
+
+	return dbLogs.ToModel()
+}
+
+func (s *pgStore) SaveJobExecutionOutput(ctx context.Context, output model.ExecutionOutput) error {
+	ctx, cancel := s.withTimeout(ctx, "SaveJobExecutionOutput")
+	defer cancel()
+
+	query := `
+		INSERT INTO job_execution_outputs (execution_id, job_id, content_type, size, truncated, encoding, data, created_at)
+		SELECT id, job_id, $2, $3, $4, $5, $6, now() FROM job_executions WHERE id = $1
+		ON CONFLICT (execution_id) DO UPDATE SET
+			content_type = excluded.content_type,
+			size = excluded.size,
+			truncated = excluded.truncated,
+			encoding = excluded.encoding,
+			data = excluded.data
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query, output.ExecutionID, output.ContentType, output.Size, output.Truncated, output.Encoding, output.Data)
+	if err != nil {
+		return fmt.Errorf("failed to save job execution output in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetJobExecutionOutput(ctx context.Context, jobID uuid.UUID, executionID int) (*model.ExecutionOutput, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutionOutput")
+	defer cancel()
+
+	// executions without captured output have no output row
+	query := `
+		SELECT e.id, o.content_type, o.size, o.truncated, o.encoding, o.data
+		FROM job_executions e
+		LEFT JOIN job_execution_outputs o ON o.execution_id = e.id
+		WHERE e.id = $1 AND e.job_id = $2
+	`
+
+	var dbOutput executionOutputDB
+	if err := s.q(ctx).GetContext(ctx, &dbOutput, query, executionID, jobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrExecutionNotFound
+		}
+		return nil, fmt.Errorf("failed to get job execution output from database: %w", err)
+	}
+
+	if !dbOutput.Encoding.Valid {
+		return nil, errs.ErrExecutionOutputNotFound
+	}
+
+	return dbOutput.ToModel(), nil
+}
+
+func (s *pgStore) AddJobExecutionEvents(ctx context.Context, events ...model.ExecutionEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ctx, cancel := s.withTimeout(ctx, "AddJobExecutionEvents")
+	defer cancel()
+
+	records, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job execution events: %w", err)
+	}
+
+	// the events of executions that no longer exist are dropped
+	query := `
+		INSERT INTO job_execution_events (execution_id, job_id, type, time, attempt, status, message, instance_id)
+		SELECT e.id, e.job_id, v.type, v.time, v.attempt, v.status, v.message, v.instance_id
+		FROM jsonb_to_recordset($1::jsonb) AS v(execution_id INTEGER, type TEXT, time TIMESTAMPTZ, attempt INTEGER,
+			status TEXT, message TEXT, instance_id TEXT)
+		JOIN job_executions e ON e.id = v.execution_id
+	`
+	if _, err := s.q(ctx).ExecContext(ctx, query, records); err != nil {
+		return fmt.Errorf("failed to save job execution events in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetJobExecutionJobID(ctx context.Context, namespace string, executionID int) (uuid.UUID, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutionJobID")
+	defer cancel()
+
+	var jobID uuid.UUID
+	query := `SELECT job_id FROM job_executions WHERE id = $1 AND namespace = $2`
+	if err := s.q(ctx).GetContext(ctx, &jobID, query, executionID, namespace); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, errs.ErrExecutionNotFound
+		}
+
+		return uuid.Nil, fmt.Errorf("failed to get job execution from database: %w", err)
+	}
+
+	return jobID, nil
+}
+
+func (s *pgStore) GetJobExecutionEvents(ctx context.Context, namespace string, executionID int) ([]model.ExecutionEvent, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutionEvents")
+	defer cancel()
+
+	var exists bool
+	if err := s.q(ctx).GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM job_executions WHERE id = $1 AND namespace = $2)`, executionID, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get job execution from database: %w", err)
+	}
+
+	if !exists {
+		return nil, errs.ErrExecutionNotFound
+	}
+
+	var dbEvents []executionEventDB
+	query := `
+		SELECT execution_id, type, time, attempt, status, message, instance_id
+		FROM job_execution_events
+		WHERE execution_id = $1
+		ORDER BY time, id
+	`
+	if err := s.q(ctx).SelectContext(ctx, &dbEvents, query, executionID); err != nil {
+		return nil, fmt.Errorf("failed to get job execution events from database: %w", err)
+	}
+
+	events := make([]model.ExecutionEvent, 0, len(dbEvents))
+	for _, dbEvent := range dbEvents {
+		events = append(events, dbEvent.ToModel())
+	}
+
+	return events, nil
+}
+
+func (s *pgStore) ArchiveExpiredJobs(ctx context.Context, at time.Time) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx, "ArchiveExpiredJobs")
+	defer cancel()
+
+	query := `
+		UPDATE jobs SET
+		        status = 'ARCHIVED', updated_at = now()
+		WHERE status <> 'ARCHIVED'
+		  AND ttl IS NOT NULL
+		  AND completed_at IS NOT NULL
+		  AND completed_at + make_interval(secs => ttl) <= $1
+	`
+	res, err := s.q(ctx).ExecContext(ctx, query, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive expired jobs in database: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (s *pgStore) DeleteExpiredJobs(ctx context.Context, at time.Time) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx, "DeleteExpiredJobs")
+	defer cancel()
+
+	query := `
+		DELETE FROM jobs
+		WHERE ttl IS NOT NULL
+		  AND completed_at IS NOT NULL
+		  AND completed_at + make_interval(secs => ttl) <= $1
+	`
+	res, err := s.q(ctx).ExecContext(ctx, query, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired jobs from database: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// CreateJobs copies the jobs to the database with a single COPY, so either all or none of them are created.
+func (s *pgStore) CreateJobs(ctx context.Context, jobs []*model.Job) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateJobs")
+	defer cancel()
+
+	rows := make([][]any, 0, len(jobs))
+	for _, job := range jobs {
+		dbJob, err := toJobDB(job)
+		if err != nil {
+			return fmt.Errorf("failed to convert job to db job: %w", err)
+		}
+
+		rows = append(rows, dbJob.copyValues())
+	}
+
+	err := s.withConn(ctx, func(conn *pgx.Conn) error {
+		_, err := conn.CopyFrom(ctx, pgx.Identifier{"jobs"}, jobCopyColumns, pgx.CopyFromRows(rows))
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("jobs: %w", jobConflict(err))
+		}
+		return fmt.Errorf("failed to copy jobs to database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) UpdateJobs(ctx context.Context, jobs []*model.Job) error {
+	ctx, cancel := s.withTimeout(ctx, "UpdateJobs")
+	defer cancel()
+
+	return s.execJobs(ctx, jobs, updateJobQuery)
+}
+
+// execJobs executes the named query for each of the jobs in a single transaction.
+func (s *pgStore) execJobs(ctx context.Context, jobs []*model.Job, query string) error {
+	return s.InTx(ctx, func(ctx context.Context) error {
+		for _, job := range jobs {
+			dbJob, err := toJobDB(job)
+			if err != nil {
+				return fmt.Errorf("failed to convert job to db job: %w", err)
+			}
+
+			res, err := s.q(ctx).NamedExecContext(ctx, query, dbJob)
+			if err != nil {
+				if isUniqueViolation(err) {
+					return fmt.Errorf("job %s: %w", job.ID, jobConflict(err))
+				}
+				return fmt.Errorf("failed to write job %s to database: %w", job.ID, err)
+			}
+
+			if query == updateJobQuery {
+				if err := checkJobUpdated(res, job); err != nil {
+					return fmt.Errorf("job %s: %w", job.ID, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetNamedJobs returns all named jobs of the namespace, except the archived ones, ordered by name.
+func (s *pgStore) GetNamedJobs(ctx context.Context, namespace string) ([]model.Job, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetNamedJobs")
+	defer cancel()
+
+	query := `
+		SELECT * FROM jobs
+		WHERE namespace = $1 AND name IS NOT NULL AND status <> 'ARCHIVED'
+		ORDER BY name
+	`
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, query, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get named jobs from database: %w", err)
+	}
+
+	jobs := []model.Job{}
+	for _, dbJob := range dbJobs {
+		job, err := dbJob.ToJob()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	return jobs, nil
+}
+
+// ApplyJobs creates, updates and deletes the jobs in a single transaction.
+func (s *pgStore) ApplyJobs(ctx context.Context, created []*model.Job, updated []*model.Job, deleted []uuid.UUID) error {
+	ctx, cancel := s.withTimeout(ctx, "ApplyJobs")
+	defer cancel()
+
+	return s.InTx(ctx, func(ctx context.Context) error {
+		// delete first, so the names of the deleted jobs can be reused
+		if len(deleted) > 0 {
+			if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM jobs WHERE id = ANY($1::uuid[])`, uuidArray(deleted)); err != nil {
+				return fmt.Errorf("failed to delete jobs from database: %w", err)
+			}
+		}
+
+		for _, write := range []struct {
+			jobs  []*model.Job
+			query string
+		}{{created, insertJobQuery}, {updated, updateJobQuery}} {
+			for _, job := range write.jobs {
+				dbJob, err := toJobDB(job)
+				if err != nil {
+					return fmt.Errorf("failed to convert job to db job: %w", err)
+				}
+
+				res, err := s.q(ctx).NamedExecContext(ctx, write.query, dbJob)
+				if err != nil {
+					if isUniqueViolation(err) {
+						return fmt.Errorf("job %s: %w", job.Name.String, jobConflict(err))
+					}
+					return fmt.Errorf("failed to write job %s to database: %w", job.ID, err)
+				}
+
+				if write.query == updateJobQuery {
+					if err := checkJobUpdated(res, job); err != nil {
+						return fmt.Errorf("job %s: %w", job.Name.String, err)
+					}
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *pgStore) SetJobsStatus(ctx context.Context, selector model.JobSelector, status model.JobStatus) ([]uuid.UUID, error) {
+	ctx, cancel := s.withTimeout(ctx, "SetJobsStatus")
+	defer cancel()
+
+	where, args := jobFilterQuery(selector.Filter(), []interface{}{status})
+	query := `UPDATE jobs SET status = $1, updated_at = now(), version = version + 1 WHERE ` + where + ` RETURNING id`
+
+	return s.execSelector(ctx, selector, query, args)
+}
+
+func (s *pgStore) DeleteJobs(ctx context.Context, selector model.JobSelector) ([]uuid.UUID, error) {
+	ctx, cancel := s.withTimeout(ctx, "DeleteJobs")
+	defer cancel()
+
+	where, args := jobFilterQuery(selector.Filter(), []interface{}{})
+	query := `DELETE FROM jobs WHERE ` + where + ` RETURNING id`
+
+	return s.execSelector(ctx, selector, query, args)
+}
+
+// execSelector executes a query affecting the selected jobs and returns the IDs of the affected jobs.
+// If the jobs are selected by IDs and any of them does not exist, the transaction is rolled back
+// and ErrJobNotFound is returned along with the IDs of the existing jobs.
+func (s *pgStore) execSelector(ctx context.Context, selector model.JobSelector, query string, args []interface{}) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := s.InTx(ctx, func(ctx context.Context) error {
+		if err := s.q(ctx).SelectContext(ctx, &ids, query, args...); err != nil {
+			return fmt.Errorf("failed to update jobs in database: %w", err)
+		}
+
+		if len(selector.IDs) > 0 && len(ids) != len(selector.IDs) {
+			return errs.ErrJobNotFound
+		}
+
+		if len(ids) > model.MaxBulkItems {
+			return errs.ErrInvalidBulkRequest
+		}
+
+		return nil
+	})
+	if errors.Is(err, errs.ErrJobNotFound) {
+		return ids, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// GetExpiredJobExecutions returns up to limit finished executions, oldest first, which were either created before the
+// given time or are not among the keepLast latest executions of their job, or both if the expiry matches all the
+// limits. Unset limits don't expire executions.
+func (s *pgStore) GetExpiredJobExecutions(ctx context.Context, expiry model.ExecutionExpiry, limit uint) ([]*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetExpiredJobExecutions")
+	defer cancel()
+
+	// with MatchAll, an unset limit doesn't exclude executions instead of not expiring them
+	query := `
+		SELECT e.* FROM job_executions e
+		WHERE e.end_time IS NOT NULL AND ($4::uuid IS NULL OR e.job_id = $4) AND
+		CASE WHEN $5::bool THEN
+			($1::timestamptz IS NULL OR e.created_at < $1) AND
+			($2::int = 0 OR (e.start_time, e.id) < (
+				SELECT k.start_time, k.id FROM job_executions k
+				WHERE k.job_id = e.job_id
+				ORDER BY k.start_time DESC, k.id DESC
+				OFFSET GREATEST($2::int - 1, 0) LIMIT 1
+			))
+		ELSE
+			($1::timestamptz IS NOT NULL AND e.created_at < $1) OR
+			($2::int > 0 AND (e.start_time, e.id) < (
+				SELECT k.start_time, k.id FROM job_executions k
+				WHERE k.job_id = e.job_id
+				ORDER BY k.start_time DESC, k.id DESC
+				OFFSET GREATEST($2::int - 1, 0) LIMIT 1
+			))
+		END
+		ORDER BY e.id
+		LIMIT $3
+	`
+
+	var dbExecutions []executionDB
+	if err := s.q(ctx).SelectContext(ctx, &dbExecutions, query, expiry.Before, expiry.KeepLast, limit, expiry.JobID, expiry.MatchAll); err != nil {
+		return nil, fmt.Errorf("failed to get expired job executions from database: %w", err)
+	}
+
+	executions := make([]*model.JobExecution, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		executions = append(executions, dbExecution.ToModel())
+	}
+
+	return executions, nil
+}
+
+// DeleteJobExecutions deletes the finished executions among the given ones along with their logs, outputs and timelines.
+func (s *pgStore) DeleteJobExecutions(ctx context.Context, executionIDs []int) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx, "DeleteJobExecutions")
+	defer cancel()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM job_executions WHERE id = ANY($1::int[]) AND end_time IS NOT NULL`, executionIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete job executions from database: %w", err)
+	}
+
+	// the logs can't reference the partitioned executions
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_execution_logs WHERE execution_id = ANY($1::int[])`, executionIDs); err != nil {
+		return 0, fmt.Errorf("failed to delete job execution logs from database: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_execution_outputs WHERE execution_id = ANY($1::int[])`, executionIDs); err != nil {
+		return 0, fmt.Errorf("failed to delete job execution outputs from database: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_execution_events WHERE execution_id = ANY($1::int[])`, executionIDs); err != nil {
+		return 0, fmt.Errorf("failed to delete job execution events from database: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+)
+
+// ListTags returns every tag of the jobs of the namespace, along with the number of jobs having it, ordered by tag.
+// If the team isn't empty, only the jobs of the team are counted.
+func (s *pgStore) ListTags(ctx context.Context, namespace string, team string) ([]model.TagCount, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListTags")
+	defer cancel()
+
+	query := `
+		SELECT tag, count(*) AS count
+		FROM jobs, unnest(tags) AS tag
+		WHERE namespace = $1 AND ($2 = '' OR team = $2)
+		GROUP BY tag
+		ORDER BY tag
+	`
+
+	var dbTags []struct {
+		Tag   string `db:"tag"`
+		Count uint64 `db:"count"`
+	}
+	if err := s.q(ctx).SelectContext(ctx, &dbTags, query, namespace, team); err != nil {
+		return nil, fmt.Errorf("failed to list tags from database: %w", err)
+	}
+
+	tags := make([]model.TagCount, 0, len(dbTags))
+	for _, dbTag := range dbTags {
+		tags = append(tags, model.TagCount{Tag: dbTag.Tag, Label: model.ParseLabel(dbTag.Tag), Count: dbTag.Count})
+	}
+
+	return tags, nil
+}
+
+// RenameTag replaces the tag on every job of the namespace having it, and returns the IDs of the updated jobs.
+// Jobs already having the new tag just lose the old one, so tags stay unique.
+func (s *pgStore) RenameTag(ctx context.Context, namespace string, from, to string) ([]uuid.UUID, error) {
+	ctx, cancel := s.withTimeout(ctx, "RenameTag")
+	defer cancel()
+
+	query := `
+		UPDATE jobs
+		SET tags = CASE WHEN $3 = ANY(tags) THEN array_remove(tags, $2) ELSE array_replace(tags, $2, $3) END,
+		    updated_at = now(),
+		    version = version + 1
+		WHERE namespace = $1 AND $2 = ANY(tags)
+		RETURNING id
+	`
+
+	var ids []uuid.UUID
+	if err := s.q(ctx).SelectContext(ctx, &ids, query, namespace, from, to); err != nil {
+		return nil, fmt.Errorf("failed to rename tag in database: %w", err)
+	}
+
+	return ids, nil
+}
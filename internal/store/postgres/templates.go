@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+func (s *pgStore) CreateTemplate(ctx context.Context, template *model.JobTemplate) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateTemplate")
+	defer cancel()
+
+	dbTemplate, err := toTemplateDB(template)
+	if err != nil {
+		return fmt.Errorf("failed to convert template to db template: %w", err)
+	}
+
+	query := `
+		INSERT INTO job_templates (namespace, name, description, parameters, definition, created_at, updated_at)
+		VALUES (:namespace, :name, :description, :parameters, :definition, :created_at, :updated_at)
+	`
+	if _, err := s.q(ctx).NamedExecContext(ctx, query, dbTemplate); err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrTemplateNameTaken
+		}
+		return fmt.Errorf("failed to insert template into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetTemplate(ctx context.Context, namespace, name string) (*model.JobTemplate, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetTemplate")
+	defer cancel()
+
+	var dbTemplate templateDB
+	query := `SELECT * FROM job_templates WHERE namespace = $1 AND name = $2`
+	if err := s.q(ctx).GetContext(ctx, &dbTemplate, query, namespace, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to get template from database: %w", err)
+	}
+
+	template, err := dbTemplate.ToModel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db template to template: %w", err)
+	}
+
+	return template, nil
+}
+
+func (s *pgStore) ListTemplates(ctx context.Context, namespace string) ([]model.JobTemplate, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListTemplates")
+	defer cancel()
+
+	var dbTemplates []templateDB
+	query := `SELECT * FROM job_templates WHERE namespace = $1 ORDER BY name`
+	if err := s.q(ctx).SelectContext(ctx, &dbTemplates, query, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get templates from database: %w", err)
+	}
+
+	templates := []model.JobTemplate{}
+	for _, dbTemplate := range dbTemplates {
+		template, err := dbTemplate.ToModel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db template to template: %w", err)
+		}
+		templates = append(templates, *template)
+	}
+
+	return templates, nil
+}
+
+// UpdateTemplate replaces the description, parameters and definition of the template. Jobs already instantiated
+// from it are left unchanged.
+func (s *pgStore) UpdateTemplate(ctx context.Context, template *model.JobTemplate) error {
+	ctx, cancel := s.withTimeout(ctx, "UpdateTemplate")
+	defer cancel()
+
+	dbTemplate, err := toTemplateDB(template)
+	if err != nil {
+		return fmt.Errorf("failed to convert template to db template: %w", err)
+	}
+
+	query := `
+		UPDATE job_templates
+		SET description = :description, parameters = :parameters, definition = :definition, updated_at = :updated_at
+		WHERE namespace = :namespace AND name = :name
+	`
+	res, err := s.q(ctx).NamedExecContext(ctx, query, dbTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to update template in database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update template in database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrTemplateNotFound
+	}
+
+	return nil
+}
+
+func (s *pgStore) DeleteTemplate(ctx context.Context, namespace, name string) error {
+	ctx, cancel := s.withTimeout(ctx, "DeleteTemplate")
+	defer cancel()
+
+	res, err := s.q(ctx).ExecContext(ctx, `DELETE FROM job_templates WHERE namespace = $1 AND name = $2`, namespace, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete template from database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete template from database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrTemplateNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// txKey is the context key of the transaction the operations of the store run in.
+type txKey struct{}
+
+// txState is a transaction in progress, on a connection dedicated to it.
+type txState struct {
+	conn *sqlx.Conn
+	tx   *sqlx.Tx
+}
+
+// querier runs the queries of the store, either on the pool or in a transaction.
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// q returns the transaction of the context, or the pool if there is none.
+func (s *pgStore) q(ctx context.Context) querier {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+
+	return s.db
+}
+
+// InTx runs fn in a transaction, which is committed if fn returns nil and rolled back otherwise. The operations of
+// the store called with the context passed to fn run in the transaction, and so do the events they publish. Nested
+// calls join the transaction in progress.
+func (s *pgStore) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	// the connection is kept, so the native pgx operations such as COPY run in the transaction too
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{conn: conn, tx: tx})); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
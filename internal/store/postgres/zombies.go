@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ReapZombieJobExecutions fails up to limit executions started before the given time whose runner stopped renewing
+// the lock of the job before that time, either because the lock lapsed or because another instance locked the job
+// since. The jobs must not have been updated since either, so executions being finished right now aren't reaped.
+func (s *pgStore) ReapZombieJobExecutions(ctx context.Context, before time.Time, errorMessage string, limit uint) ([]model.ZombieExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "ReapZombieJobExecutions")
+	defer cancel()
+
+	query := `
+		WITH reaped AS (
+			UPDATE job_executions SET status = 'FAILED', end_time = now(), error_message = $2
+			WHERE id IN (
+				SELECT e.id FROM job_executions e
+				JOIN jobs j ON j.id = e.job_id
+				WHERE e.status = 'RUNNING' AND e.end_time IS NULL AND e.instance_id IS NOT NULL
+				  AND e.start_time < $1 AND j.updated_at < $1
+				  AND (j.locked_by IS DISTINCT FROM e.instance_id OR j.locked_until IS NULL OR j.locked_until < $1)
+				ORDER BY e.id
+				LIMIT $3
+				FOR UPDATE OF e SKIP LOCKED
+			)
+			RETURNING *
+		), jobs_status AS (
+			UPDATE jobs SET last_execution_status = 'FAILED'
+			WHERE id IN (SELECT job_id FROM reaped)
+		)
+		SELECT * FROM reaped
+	`
+
+	var dbExecutions []executionDB
+	if err := s.q(ctx).SelectContext(ctx, &dbExecutions, query, before, errorMessage, limit); err != nil {
+		return nil, fmt.Errorf("failed to reap zombie job executions: %w", err)
+	}
+
+	if len(dbExecutions) == 0 {
+		return nil, nil
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		jobIDs = append(jobIDs, dbExecution.JobID)
+	}
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, `SELECT * FROM jobs WHERE id = ANY($1::uuid[])`, uuidArray(jobIDs)); err != nil {
+		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
+	}
+
+	jobsByID := map[uuid.UUID]*jobDB{}
+	for i := range dbJobs {
+		jobsByID[dbJobs[i].ID] = &dbJobs[i]
+	}
+
+	zombies := []model.ZombieExecution{}
+	for _, dbExecution := range dbExecutions {
+		dbJob, ok := jobsByID[dbExecution.JobID]
+		if !ok {
+			continue
+		}
+
+		job, err := dbJob.ToJob()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+
+		zombies = append(zombies, model.ZombieExecution{
+			ExecutionID:   dbExecution.ID,
+			ScheduledTime: dbExecution.ScheduledTime,
+			Job:           job,
+		})
+	}
+
+	return zombies, nil
+}
+
+// RescheduleMisfiredJob moves the next run of a job from the missed occurrence to the given one, marking the job as
+// completed if it will not run again. Nothing changes if a runner already locked the job or it was rescheduled since.
+func (s *pgStore) RescheduleMisfiredJob(ctx context.Context, jobID uuid.UUID, missedRun time.Time, nextRun null.Time) error {
+	ctx, cancel := s.withTimeout(ctx, "RescheduleMisfiredJob")
+	defer cancel()
+
+	query := `
+		UPDATE jobs SET
+			next_run = $3,
+			completed_at = CASE WHEN $3::timestamptz IS NULL THEN now() ELSE NULL END,
+			locked_until = null, locked_by = null, updated_at = now()
+		WHERE id = $1 AND next_run = $2 AND (locked_until IS NULL OR locked_until < now())
+	`
+	if _, err := s.q(ctx).ExecContext(ctx, query, jobID, missedRun, nextRun); err != nil {
+		return fmt.Errorf("failed to reschedule misfired job in database: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// NewAPIKeyStore creates a new SQLite API key store.
+func NewAPIKeyStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.APIKeyStorer {
+	return newStore(db, log, options...)
+}
+
+func (s *sqliteStore) CreateAPIKey(ctx context.Context, key *model.APIKey, keyHash string) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateAPIKey")
+	defer cancel()
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, admin, namespace, team, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query, key.ID, key.Name, key.Prefix, keyHash, key.Admin, key.Namespace, key.Team, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert API key into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListAPIKeys")
+	defer cancel()
+
+	var dbKeys []apiKeyDB
+	if err := s.q(ctx).SelectContext(ctx, &dbKeys, `SELECT * FROM api_keys ORDER BY created_at DESC, id DESC`); err != nil {
+		return nil, fmt.Errorf("failed to get API keys from database: %w", err)
+	}
+
+	keys := []model.APIKey{}
+	for _, dbKey := range dbKeys {
+		keys = append(keys, *dbKey.ToModel())
+	}
+
+	return keys, nil
+}
+
+func (s *sqliteStore) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := s.withTimeout(ctx, "RevokeAPIKey")
+	defer cancel()
+
+	query := `UPDATE api_keys SET revoked_at = coalesce(revoked_at, now()) WHERE id = $1`
+	res, err := s.q(ctx).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key in database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key in database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetAPIKeyByHash")
+	defer cancel()
+
+	query := `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING *
+	`
+
+	var dbKey apiKeyDB
+	if err := s.q(ctx).GetContext(ctx, &dbKey, query, keyHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key from database: %w", err)
+	}
+
+	return dbKey.ToModel(), nil
+}
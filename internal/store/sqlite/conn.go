@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+)
+
+// timeLayout is the layout of the times stored in the database. Times are stored in UTC with a fixed number of
+// fractional digits, so that they compare in order as text.
+const timeLayout = "2006-01-02 15:04:05.000000000"
+
+// busyTimeout is how long a connection waits for the write lock of the database before failing.
+const busyTimeout = 10 * time.Second
+
+// Open opens the SQLite database at the path, creating it if needed, and migrates its schema. The database is in WAL
+// mode, so reads don't wait for the writer, and transactions take the write lock when they begin, so they never fail
+// to upgrade a read lock: writers are serialized instead of skipping each other's locked rows.
+func Open(ctx context.Context, path string) (*sqlx.DB, error) {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	dsn := path + separator + fmt.Sprintf(
+		"_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)&_txlock=immediate",
+		busyTimeout.Milliseconds(),
+	)
+
+	db := sqlx.NewDb(sql.OpenDB(connector{dsn: dsn}), "sqlite3")
+	if err := Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// connector opens the connections to the database with the timeDriver.
+type connector struct {
+	dsn string
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return timeDriver{}.Open(c.dsn)
+}
+
+func (c connector) Driver() driver.Driver {
+	return timeDriver{}
+}
+
+// sqliteDriver is the driver registered by the SQLite package, which the functions of the store are registered with.
+var sqliteDriver = func() driver.Driver {
+	db, _ := sql.Open("sqlite", "")
+	defer db.Close()
+
+	return db.Driver()
+}()
+
+// timeDriver is the SQLite driver, storing the times in timeLayout. The driver stores them in their own time zone with
+// their trailing zeros trimmed otherwise, which doesn't compare in order.
+type timeDriver struct{}
+
+func (timeDriver) Open(name string) (driver.Conn, error) {
+	c, err := sqliteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return timeConn{c.(conn)}, nil
+}
+
+// conn is a connection of the SQLite driver.
+type conn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+type timeConn struct {
+	conn
+}
+
+// CheckNamedValue formats the times in timeLayout, and leaves the other values to the default conversion.
+func (timeConn) CheckNamedValue(value *driver.NamedValue) error {
+	switch v := value.Value.(type) {
+	case time.Time:
+		value.Value = formatTime(v)
+		return nil
+	case *time.Time:
+		if v != nil {
+			value.Value = formatTime(*v)
+			return nil
+		}
+	case null.Time:
+		if v.Valid {
+			value.Value = formatTime(v.Time)
+			return nil
+		}
+	}
+
+	return driver.ErrSkip
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// isOpenedWithOpen reports whether the database handle was opened by Open.
+func isOpenedWithOpen(db *sql.DB) bool {
+	_, ok := db.Driver().(timeDriver)
+	return ok
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// DriverName is the name the SQLite backend is registered with.
+const DriverName = "sqlite"
+
+func init() {
+	store.Register(DriverName, store.DriverFunc(open))
+}
+
+// NewBackend returns the SQLite stores on the database handle, which must be opened with Open.
+func NewBackend(db *sqlx.DB, log *otelzap.Logger, options ...Option) *store.Backend {
+	s := newStore(db, log, options...)
+
+	return &store.Backend{
+		Jobs:          s,
+		Instances:     s,
+		Webhooks:      s,
+		Notifications: s,
+		APIKeys:       s,
+	}
+}
+
+// open opens the backend on the database handle of the config, or opens the database file the DSN is the path of.
+// The "poll_interval" option sets how often the listeners poll for notifications.
+func open(ctx context.Context, cfg store.Config) (*store.Backend, error) {
+	log := otelzap.New(cfg.Logger)
+
+	var options []Option
+	if interval, ok := cfg.Options["poll_interval"]; ok {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid poll_interval %s: %w", strconv.Quote(interval), err)
+		}
+		options = append(options, WithPollInterval(d))
+	}
+
+	if cfg.DB != nil {
+		if !isOpenedWithOpen(cfg.DB) {
+			return nil, fmt.Errorf("the database handle must be opened with sqlite.Open")
+		}
+
+		return NewBackend(sqlx.NewDb(cfg.DB, "sqlite3"), log, options...), nil
+	}
+
+	if cfg.DSN == "" {
+		return nil, fmt.Errorf("a database handle or DSN is required")
+	}
+
+	db, err := Open(ctx, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := NewBackend(db, log, options...)
+	backend.Closer = db.Close
+	return backend, nil
+}
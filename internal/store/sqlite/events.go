@@ -0,0 +1,220 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventsChannel is the notification channel the events relayed from the outbox are broadcast on.
+	eventsChannel = "job_events"
+	// wakeupsChannel is the notification channel runners are woken up on when a job is due soon.
+	wakeupsChannel = "job_wakeups"
+	// cancellationsChannel is the notification channel runners are notified on when an execution is cancelled.
+	cancellationsChannel = "job_cancellations"
+	// signalsRetention is how long the notifications are kept for the listeners to poll them.
+	signalsRetention = time.Minute
+)
+
+// PublishEvent writes the event to the outbox. Within a transaction, the event is only relayed if the transaction is
+// committed.
+func (s *sqliteStore) PublishEvent(ctx context.Context, event model.Event) error {
+	ctx, cancel := s.withTimeout(ctx, "PublishEvent")
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	query := `INSERT INTO event_outbox (event_id, payload) VALUES ($1, $2)`
+	if _, err := s.q(ctx).ExecContext(ctx, query, event.ID, string(payload)); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+// RelayEvents passes up to limit events of the outbox to fn, holding the write lock of the database so no other
+// instance relays them. The events are deleted from the outbox and broadcast to the listeners in the transaction fn
+// runs in, so they are relayed again if fn or the commit fails.
+func (s *sqliteStore) RelayEvents(ctx context.Context, limit uint, fn func(ctx context.Context, events []model.Event) error) (int, error) {
+	ctx, cancel := s.withTimeout(ctx, "RelayEvents")
+	defer cancel()
+
+	var count int
+	err := s.InTx(ctx, func(ctx context.Context) error {
+		query := `
+			SELECT id, payload FROM event_outbox
+			ORDER BY id
+			LIMIT $1
+		`
+		var rows []struct {
+			ID      int64  `db:"id"`
+			Payload string `db:"payload"`
+		}
+		if err := s.q(ctx).SelectContext(ctx, &rows, query, limit); err != nil {
+			return fmt.Errorf("failed to get outbox events from database: %w", err)
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		ids := make([]int, 0, len(rows))
+		events := make([]model.Event, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, int(row.ID))
+
+			var event model.Event
+			if err := json.Unmarshal([]byte(row.Payload), &event); err != nil {
+				// a malformed event would block the outbox, it is dropped instead
+				s.log.Error("Failed to unmarshal outbox event", zap.Int64("id", row.ID), zap.Error(err))
+				continue
+			}
+			events = append(events, event)
+		}
+
+		if err := fn(ctx, events); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := s.notify(ctx, eventsChannel, event); err != nil {
+				return fmt.Errorf("failed to broadcast event: %w", err)
+			}
+		}
+
+		if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM event_outbox WHERE id IN (SELECT value FROM json_each($1))`, intArray(ids)); err != nil {
+			return fmt.Errorf("failed to delete outbox events from database: %w", err)
+		}
+
+		count = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListenEvents polls for published events and calls the handler for each of them.
+// It blocks until the context is cancelled or the database fails.
+func (s *sqliteStore) ListenEvents(ctx context.Context, handler func(model.Event)) error {
+	return s.listen(ctx, eventsChannel, func(payload string) {
+		var event model.Event
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			s.log.Warn("Failed to unmarshal event", zap.String("payload", payload), zap.Error(err))
+			return
+		}
+
+		handler(event)
+	})
+}
+
+// NotifyJobWakeup notifies the listening runners that the job is due soon.
+func (s *sqliteStore) NotifyJobWakeup(ctx context.Context, wakeup model.JobWakeup) error {
+	ctx, cancel := s.withTimeout(ctx, "NotifyJobWakeup")
+	defer cancel()
+
+	if err := s.notify(ctx, wakeupsChannel, wakeup); err != nil {
+		return fmt.Errorf("failed to notify job wakeup: %w", err)
+	}
+
+	return nil
+}
+
+// ListenJobWakeups polls for job wakeups and calls the handler for each of them.
+// It blocks until the context is cancelled or the database fails.
+func (s *sqliteStore) ListenJobWakeups(ctx context.Context, handler func(model.JobWakeup)) error {
+	return s.listen(ctx, wakeupsChannel, func(payload string) {
+		var wakeup model.JobWakeup
+		if err := json.Unmarshal([]byte(payload), &wakeup); err != nil {
+			s.log.Warn("Failed to unmarshal job wakeup", zap.String("payload", payload), zap.Error(err))
+			return
+		}
+
+		handler(wakeup)
+	})
+}
+
+// NotifyJobCancellation notifies the listening runners that the cancellation of the execution was requested.
+func (s *sqliteStore) NotifyJobCancellation(ctx context.Context, cancellation model.JobCancellation) error {
+	ctx, cancel := s.withTimeout(ctx, "NotifyJobCancellation")
+	defer cancel()
+
+	if err := s.notify(ctx, cancellationsChannel, cancellation); err != nil {
+		return fmt.Errorf("failed to notify job cancellation: %w", err)
+	}
+
+	return nil
+}
+
+// ListenJobCancellations polls for job cancellations and calls the handler for each of them. It blocks until the context is cancelled or the database fails.
+func (s *sqliteStore) ListenJobCancellations(ctx context.Context, handler func(model.JobCancellation)) error {
+	return s.listen(ctx, cancellationsChannel, func(payload string) {
+		var cancellation model.JobCancellation
+		if err := json.Unmarshal([]byte(payload), &cancellation); err != nil {
+			s.log.Warn("Failed to unmarshal job cancellation", zap.String("payload", payload), zap.Error(err))
+			return
+		}
+
+		handler(cancellation)
+	})
+}
+
+// notify writes the JSON encoded payload to the notification channel. Within a transaction, the listeners only see
+// it once the transaction is committed. The notifications older than signalsRetention are pruned on the way.
+func (s *sqliteStore) notify(ctx context.Context, channel string, payload any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM signals WHERE created_at < $1`, time.Now().Add(-signalsRetention)); err != nil {
+		return err
+	}
+
+	_, err = s.q(ctx).ExecContext(ctx, `INSERT INTO signals (channel, payload) VALUES ($1, $2)`, channel, string(encoded))
+	return err
+}
+
+// listen polls the notification channel every pollInterval and calls the handler with the payload of every
+// notification written since it started listening, until the context is cancelled or the database fails.
+func (s *sqliteStore) listen(ctx context.Context, channel string, handler func(payload string)) error {
+	var lastID int64
+	if err := s.db.GetContext(ctx, &lastID, `SELECT COALESCE(max(id), 0) FROM signals`); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for notifications on %s: %w", channel, ctx.Err())
+		case <-ticker.C:
+		}
+
+		var signals []struct {
+			ID      int64  `db:"id"`
+			Payload string `db:"payload"`
+		}
+		query := `SELECT id, payload FROM signals WHERE channel = $1 AND id > $2 ORDER BY id`
+		if err := s.db.SelectContext(ctx, &signals, query, channel, lastID); err != nil {
+			return fmt.Errorf("failed to wait for notifications on %s: %w", channel, err)
+		}
+
+		for _, signal := range signals {
+			lastID = signal.ID
+			handler(signal.Payload)
+		}
+	}
+}
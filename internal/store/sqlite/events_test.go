@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+func TestListenJobWakeups(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	db, err := Open(ctx, filepath.Join(t.TempDir(), "scheduler.db"))
+	if err != nil {
+		t.Fatalf("Should be able to open the database: %s", err)
+	}
+	defer db.Close()
+
+	s := newStore(db, otelzap.New(zap.NewNop()), WithPollInterval(10*time.Millisecond))
+
+	wakeups := make(chan model.JobWakeup, 2)
+	listenCtx, stopListening := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- s.ListenJobWakeups(listenCtx, func(wakeup model.JobWakeup) { wakeups <- wakeup })
+	}()
+
+	// the listener starts from the notifications written once it listens
+	time.Sleep(50 * time.Millisecond)
+
+	// notifications are only delivered once their transaction is committed
+	rolledBack := model.JobWakeup{JobID: uuid.New(), Namespace: "default"}
+	err = s.InTx(ctx, func(ctx context.Context) error {
+		if err := s.NotifyJobWakeup(ctx, rolledBack); err != nil {
+			return err
+		}
+
+		return errors.New("rollback")
+	})
+	assert.Error(t, err)
+
+	committed := model.JobWakeup{JobID: uuid.New(), Namespace: "default"}
+	if err := s.NotifyJobWakeup(ctx, committed); err != nil {
+		t.Fatalf("Should be able to notify a job wakeup: %s", err)
+	}
+
+	select {
+	case wakeup := <-wakeups:
+		assert.Equal(t, committed.JobID, wakeup.JobID)
+	case <-ctx.Done():
+		t.Fatal("Should receive the job wakeup")
+	}
+
+	stopListening()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, wakeups)
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// The functions of Postgres used by the queries, or replacing the ones they use, registered on the connections of
+// the SQLite driver.
+func init() {
+	// now returns the current time, like the now of Postgres, in timeLayout.
+	sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+		return formatTime(time.Now()), nil
+	})
+
+	// add_seconds adds a number of seconds to a time, in place of the intervals of Postgres.
+	sqlite.MustRegisterDeterministicScalarFunction("add_seconds", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if args[0] == nil || args[1] == nil {
+			return nil, nil
+		}
+
+		t, err := parseTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		var seconds float64
+		switch v := args[1].(type) {
+		case int64:
+			seconds = float64(v)
+		case float64:
+			seconds = v
+		default:
+			return nil, fmt.Errorf("add_seconds: invalid number of seconds %v", v)
+		}
+
+		return formatTime(t.Add(time.Duration(seconds * float64(time.Second)))), nil
+	})
+
+	// shard_hash hashes a job ID to a shard, in place of the hashtext of Postgres.
+	sqlite.MustRegisterDeterministicScalarFunction("shard_hash", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		id, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("shard_hash: invalid id %v", args[0])
+		}
+
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(id))
+		return int64(h.Sum32()), nil
+	})
+}
+
+// parseTime parses a time stored in the database, or passed to a function.
+func parseTime(value driver.Value) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse("2006-01-02 15:04:05.999999999", v)
+	case []byte:
+		return time.Parse("2006-01-02 15:04:05.999999999", string(v))
+	default:
+		return time.Time{}, fmt.Errorf("invalid time %v", v)
+	}
+}
@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// checkJobUpdated checks that the update of the job matched its version, and increments it.
+// Updates only match the version the job was read at, so concurrent updates can't overwrite each other.
+func checkJobUpdated(res sql.Result, job *model.Job) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update job in database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrJobVersionMismatch
+	}
+
+	job.Version++
+	return nil
+}
+
+// isUniqueViolation reports whether the error is caused by a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+
+	return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// jobExternalIDColumn is the column of the external IDs in the messages of the unique constraint violations, which
+// name the columns of the violated index rather than the index.
+const jobExternalIDColumn = "jobs.external_id"
+
+// jobConflict returns the error of a unique constraint violation caused by writing a job, telling whether its external
+// ID or its name is taken.
+func jobConflict(err error) error {
+	if strings.Contains(err.Error(), jobExternalIDColumn) {
+		return errs.ErrJobExternalIDTaken
+	}
+
+	return errs.ErrJobNameTaken
+}
+
+func rollback(tx *sqlx.Tx, log *otelzap.Logger) {
+	err := tx.Rollback()
+	if err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Error("Failed to rollback transaction", zap.Error(err))
+	}
+}
+
+// searchTextSQL evaluates to the text the jobs are searched in, like the search_text column of Postgres.
+const searchTextSQL = `concat_ws(' ', json_extract(http_job, '$.url'), json_extract(amqp_job, '$.exchange'), ` +
+	`json_extract(amqp_job, '$.routing_key'), (SELECT group_concat(value, ' ') FROM json_each(tags)))`
+
+// jobFilterQuery builds the WHERE clause for the given job filter, appending the values to args.
+func jobFilterQuery(filter model.JobFilter, args []interface{}) (string, []interface{}) {
+	where := "TRUE"
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		where += fmt.Sprintf(" AND "+condition, len(args))
+	}
+
+	if filter.Namespace != "" {
+		add("namespace = $%d", filter.Namespace)
+	}
+
+	if filter.Team != "" {
+		add("team = $%d", filter.Team)
+	}
+
+	if len(filter.IDs) > 0 {
+		add("id IN (SELECT value FROM json_each($%d))", uuidArray(filter.IDs))
+	}
+
+	// without full-text search, each word of the query must be found in the text of the job, which matches the
+	// substring and word matches of Postgres
+	for _, word := range strings.Fields(filter.Query) {
+		add(searchTextSQL+` LIKE $%d ESCAPE '\'`, "%"+escapeLike(word)+"%")
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make(stringArray, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		add("status IN (SELECT value FROM json_each($%d))", statuses)
+	}
+
+	if len(filter.Types) > 0 {
+		types := make(stringArray, 0, len(filter.Types))
+		for _, jobType := range filter.Types {
+			types = append(types, string(jobType))
+		}
+		add("type IN (SELECT value FROM json_each($%d))", types)
+	}
+
+	if len(filter.Tags) > 0 {
+		add("NOT EXISTS (SELECT 1 FROM json_each($%d) t WHERE t.value NOT IN (SELECT value FROM json_each(tags)))", stringArray(filter.Tags))
+	}
+
+	if len(filter.AnyTags) > 0 {
+		add("EXISTS (SELECT 1 FROM json_each(tags) t WHERE t.value IN (SELECT value FROM json_each($%d)))", stringArray(filter.AnyTags))
+	}
+
+	for _, requirement := range filter.Selector {
+		if key, ok := requirement.MetadataKey(); ok {
+			switch requirement.Operator {
+			case model.SelectorOperatorEquals, model.SelectorOperatorNotEquals:
+				args = append(args, key, requirement.Value)
+				condition := fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(metadata) m WHERE m.key = $%d AND m.value = $%d)", len(args)-1, len(args))
+				if requirement.Operator == model.SelectorOperatorNotEquals {
+					condition = "NOT " + condition
+				}
+				where += " AND " + condition
+			case model.SelectorOperatorExists:
+				add("EXISTS (SELECT 1 FROM json_each(metadata) m WHERE m.key = $%d)", key)
+			case model.SelectorOperatorNotExists:
+				add("NOT EXISTS (SELECT 1 FROM json_each(metadata) m WHERE m.key = $%d)", key)
+			}
+			continue
+		}
+
+		switch requirement.Operator {
+		case model.SelectorOperatorEquals:
+			add("EXISTS (SELECT 1 FROM json_each(tags) t WHERE t.value = $%d)", model.Label{Key: requirement.Key, Value: requirement.Value}.String())
+		case model.SelectorOperatorNotEquals:
+			add("NOT EXISTS (SELECT 1 FROM json_each(tags) t WHERE t.value = $%d)", model.Label{Key: requirement.Key, Value: requirement.Value}.String())
+		case model.SelectorOperatorExists, model.SelectorOperatorNotExists:
+			// a key is set either as a plain tag or as a key=value tag, compared case-sensitively unlike LIKE
+			args = append(args, requirement.Key, requirement.Key+"=")
+			condition := fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM json_each(tags) t WHERE t.value = $%d OR substr(t.value, 1, length($%d)) = $%d)",
+				len(args)-1, len(args), len(args),
+			)
+			if requirement.Operator == model.SelectorOperatorNotExists {
+				condition = "NOT " + condition
+			}
+			where += " AND " + condition
+		}
+	}
+
+	if filter.NextRunFrom != nil {
+		add("next_run >= $%d", *filter.NextRunFrom)
+	}
+
+	if filter.NextRunTo != nil {
+		add("next_run <= $%d", *filter.NextRunTo)
+	}
+
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		add("created_at <= $%d", *filter.CreatedTo)
+	}
+
+	if filter.Failed != nil {
+		if *filter.Failed {
+			where += " AND last_execution_status IN ('FAILED', 'TIMED_OUT')"
+		} else {
+			where += " AND last_execution_status IS NOT 'FAILED' AND last_execution_status IS NOT 'TIMED_OUT'"
+		}
+	}
+
+	return where, args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes the LIKE pattern wildcards in s.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// withTimeout bounds the context by the timeout of the operation, if it has one.
+func (s *sqliteStore) withTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	if timeout := s.timeouts.Timeout(operation); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return ctx, func() {}
+}
+
+// estimateCount returns the number of rows the query returns. SQLite keeps no statistics to estimate it from, so the
+// rows are counted, which is cheap at the sizes a single file database is meant for.
+func (s *sqliteStore) estimateCount(ctx context.Context, query string, args ...interface{}) (uint64, error) {
+	var count uint64
+	if err := s.q(ctx).GetContext(ctx, &count, `SELECT count(*) FROM (`+query+`)`, args...); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
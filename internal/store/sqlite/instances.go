@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// NewInstanceStore creates a new SQLite runner instance store.
+func NewInstanceStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.InstanceStorer {
+	return newStore(db, log, options...)
+}
+
+func (s *sqliteStore) SaveInstance(ctx context.Context, instance model.Instance, ttl time.Duration) error {
+	ctx, cancel := s.withTimeout(ctx, "SaveInstance")
+	defer cancel()
+
+	// the database clock is used for the heartbeats, so clock skew between the runners doesn't matter
+	query := `
+		INSERT INTO instances (id, hostname, version, namespaces, capacity, load, started_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, now(), add_seconds(now(), $8 / 1000.0))
+		ON CONFLICT (id) DO UPDATE SET
+			hostname = excluded.hostname,
+			version = excluded.version,
+			namespaces = excluded.namespaces,
+			capacity = excluded.capacity,
+			load = excluded.load,
+			started_at = excluded.started_at,
+			last_seen_at = excluded.last_seen_at,
+			expires_at = excluded.expires_at
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query,
+		instance.ID, instance.Hostname, instance.Version, append(stringArray{}, instance.Namespaces...),
+		instance.Capacity, instance.Load, instance.StartedAt, ttl.Milliseconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save instance in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) DeleteInstance(ctx context.Context, id string) error {
+	ctx, cancel := s.withTimeout(ctx, "DeleteInstance")
+	defer cancel()
+
+	if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM instances WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete instance from database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) ListInstances(ctx context.Context) ([]model.Instance, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListInstances")
+	defer cancel()
+
+	query := `SELECT *, expires_at > now() AS alive FROM instances ORDER BY id`
+
+	var dbInstances []instanceDB
+	if err := s.q(ctx).SelectContext(ctx, &dbInstances, query); err != nil {
+		return nil, fmt.Errorf("failed to get instances from database: %w", err)
+	}
+
+	instances := []model.Instance{}
+	for _, dbInstance := range dbInstances {
+		instances = append(instances, dbInstance.ToModel())
+	}
+
+	return instances, nil
+}
+
+func (s *sqliteStore) DeleteExpiredInstances(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx, "DeleteExpiredInstances")
+	defer cancel()
+
+	res, err := s.q(ctx).ExecContext(ctx, `DELETE FROM instances WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired instances from database: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/ardanlabs/darwin/v3"
+	"github.com/ardanlabs/darwin/v3/dialects/sqlite"
+	"github.com/ardanlabs/darwin/v3/drivers/generic"
+	"github.com/jmoiron/sqlx"
+)
+
+// migrateDoc holds the migrations of the SQLite schema. The schema starts from the final state of the Postgres
+// schema, and follows its changes with migrations of its own.
+//
+//go:embed sql/migrate.sql
+var migrateDoc string
+
+// Migrate brings the database up to date with the migrations of the SQLite schema.
+func Migrate(ctx context.Context, db *sqlx.DB) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+
+	driver, err := generic.New(db.DB, sqlite.Dialect{})
+	if err != nil {
+		return fmt.Errorf("construct darwin driver: %w", err)
+	}
+
+	if err := driver.Create(); err != nil {
+		return fmt.Errorf("create migrations table: %w", err)
+	}
+
+	d := darwin.New(driver, darwin.ParseMigrations(migrateDoc))
+	return d.Migrate()
+}
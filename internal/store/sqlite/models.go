@@ -0,0 +1,693 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gopkg.in/guregu/null.v4"
+)
+
+type jobDB struct {
+	ID           uuid.UUID   `db:"id"`
+	Namespace    string      `db:"namespace"`
+	Name         null.String `db:"name"`
+	Owner        null.String `db:"owner"`
+	Team         null.String `db:"team"`
+	ExternalID   null.String `db:"external_id"`
+	Type         string      `db:"type"`
+	Status       string      `db:"status"`
+	Version      int64       `db:"version"`
+	ExecuteAt    null.Time   `db:"execute_at"`
+	CronSchedule null.String `db:"cron_schedule"`
+	HTTPJob      jsonDoc     `db:"http_job"`
+	AMQPJob      jsonDoc     `db:"amqp_job"`
+	CreatedAt    time.Time   `db:"created_at"`
+	UpdatedAt    time.Time   `db:"updated_at"`
+	NextRun      null.Time   `db:"next_run"`
+	LockedUntil  null.Time   `db:"locked_until"`
+	LockedBy     null.String `db:"locked_by"`
+	Tags         stringArray `db:"tags"`
+	Metadata     jsonDoc     `db:"metadata"`
+	Priority     string      `db:"priority"`
+	TTL          null.Int    `db:"ttl"`
+	CompletedAt  null.Time   `db:"completed_at"`
+
+	RequiredCapabilities stringArray `db:"required_capabilities"`
+	ExecutionTimeout     null.Int    `db:"execution_timeout"`
+	MisfirePolicy        string      `db:"misfire_policy"`
+	SLA                  jsonDoc     `db:"sla"`
+	ResultSink           jsonDoc     `db:"result_sink"`
+	MetricLabels         stringArray `db:"metric_labels"`
+
+	// Definition of a job of a custom type
+	CustomJob jsonDoc `db:"custom_job"`
+
+	LastExecutionStatus null.String `db:"last_execution_status"`
+}
+
+func toJobDB(j *model.Job) (*jobDB, error) {
+	dbJ := &jobDB{
+		ID:           j.ID,
+		Namespace:    j.Namespace,
+		Name:         j.Name,
+		Owner:        j.Owner,
+		Team:         j.Team,
+		ExternalID:   j.ExternalID,
+		Type:         string(j.Type),
+		Status:       string(j.Status),
+		Version:      j.Version,
+		ExecuteAt:    j.ExecuteAt,
+		CronSchedule: j.CronSchedule,
+		CreatedAt:    j.CreatedAt,
+		UpdatedAt:    j.UpdatedAt,
+		NextRun:      j.NextRun,
+		Tags:         j.Tags,
+		Priority:     string(j.Priority.OrDefault()),
+		TTL:          j.TTL,
+		CompletedAt:  j.CompletedAt,
+
+		RequiredCapabilities: append(stringArray{}, j.RequiredCapabilities...),
+		ExecutionTimeout:     j.ExecutionTimeout,
+		MisfirePolicy:        string(j.MisfirePolicy.OrDefault()),
+		MetricLabels:         append(stringArray{}, j.MetricLabels...),
+
+		CustomJob: jsonDoc(j.CustomJob),
+	}
+
+	docs, err := codec.EncodeJob(j)
+	if err != nil {
+		return nil, err
+	}
+
+	dbJ.HTTPJob = docs.HTTPJob
+	dbJ.AMQPJob = docs.AMQPJob
+	dbJ.Metadata = docs.Metadata
+	dbJ.SLA = docs.SLA
+	dbJ.ResultSink = docs.ResultSink
+
+	return dbJ, nil
+}
+
+func (j *jobDB) ToJob() (*model.Job, error) {
+	job := &model.Job{
+		ID:           j.ID,
+		Namespace:    j.Namespace,
+		Name:         j.Name,
+		Owner:        j.Owner,
+		Team:         j.Team,
+		ExternalID:   j.ExternalID,
+		Type:         model.JobType(j.Type),
+		Status:       model.JobStatus(j.Status),
+		Version:      j.Version,
+		ExecuteAt:    j.ExecuteAt,
+		CronSchedule: j.CronSchedule,
+		CreatedAt:    j.CreatedAt,
+		UpdatedAt:    j.UpdatedAt,
+		NextRun:      j.NextRun,
+		Tags:         j.Tags,
+		Priority:     model.JobPriority(j.Priority),
+		TTL:          j.TTL,
+		CompletedAt:  j.CompletedAt,
+
+		RequiredCapabilities: append([]string{}, j.RequiredCapabilities...),
+		ExecutionTimeout:     j.ExecutionTimeout,
+		MisfirePolicy:        model.MisfirePolicy(j.MisfirePolicy),
+		MetricLabels:         append([]string{}, j.MetricLabels...),
+
+		CustomJob: json.RawMessage(j.CustomJob),
+	}
+
+	if j.LastExecutionStatus.Valid {
+		job.LastExecutionStatus = lo.ToPtr(model.JobExecutionStatus(j.LastExecutionStatus.String))
+	}
+
+	docs := codec.JobDocuments{
+		HTTPJob:    j.HTTPJob,
+		AMQPJob:    j.AMQPJob,
+		Metadata:   j.Metadata,
+		SLA:        j.SLA,
+		ResultSink: j.ResultSink,
+	}
+	if err := codec.DecodeJob(job, docs); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func unmarshalNullableJSON(data []byte, v interface{}) error {
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+type executionDB struct {
+	ID            int         `db:"id"`
+	JobID         uuid.UUID   `db:"job_id"`
+	Namespace     string      `db:"namespace"`
+	Status        string      `db:"status"`
+	StartTime     time.Time   `db:"start_time"`
+	EndTime       null.Time   `db:"end_time"`
+	ErrorMessage  null.String `db:"error_message"`
+	CreatedAt     time.Time   `db:"created_at"`
+	ScheduledTime null.Time   `db:"scheduled_time"`
+
+	CancelRequestedAt null.Time `db:"cancel_requested_at"`
+	// Instance that started the execution, while holding the lock of the job
+	InstanceID null.String `db:"instance_id"`
+
+	// Job definition the execution ran with
+	JobVersion null.Int    `db:"job_version"`
+	JobType    null.String `db:"job_type"`
+	HTTPJob    jsonDoc     `db:"http_job"`
+	AMQPJob    jsonDoc     `db:"amqp_job"`
+	CustomJob  jsonDoc     `db:"custom_job"`
+
+	RetryOf    null.Int `db:"retry_of"`
+	Overridden bool     `db:"overridden"`
+
+	// Latest progress reported by the executor
+	ProgressPercent   null.Int    `db:"progress_percent"`
+	ProgressMessage   null.String `db:"progress_message"`
+	ProgressUpdatedAt null.Time   `db:"progress_updated_at"`
+}
+
+func (e *executionDB) ToModel() *model.JobExecution {
+	execution := &model.JobExecution{
+		ID:            e.ID,
+		JobID:         e.JobID,
+		Namespace:     e.Namespace,
+		Status:        model.JobExecutionStatus(e.Status),
+		Success:       e.Status == string(model.JobExecutionStatusSuccessful),
+		StartTime:     e.StartTime,
+		EndTime:       e.EndTime,
+		ErrorMessage:  e.ErrorMessage,
+		ScheduledTime: e.ScheduledTime,
+
+		CancelRequestedAt: e.CancelRequestedAt,
+		JobVersion:        e.JobVersion,
+		RetryOf:           e.RetryOf,
+		Overridden:        e.Overridden,
+	}
+
+	if e.ScheduledTime.Valid {
+		execution.Drift = null.IntFrom(e.StartTime.Sub(e.ScheduledTime.Time).Milliseconds())
+	}
+
+	if e.ProgressUpdatedAt.Valid {
+		execution.Progress = &model.ExecutionProgress{
+			Percent:   e.ProgressPercent,
+			Message:   e.ProgressMessage,
+			UpdatedAt: e.ProgressUpdatedAt.Time,
+		}
+	}
+
+	return execution
+}
+
+// ToJob returns the job with the definition the execution ran with.
+func (e *executionDB) ToJob(job *jobDB) (*model.Job, error) {
+	definition := *job
+	definition.Version = e.JobVersion.Int64
+	definition.Type = e.JobType.String
+	definition.HTTPJob = e.HTTPJob
+	definition.AMQPJob = e.AMQPJob
+	definition.CustomJob = e.CustomJob
+
+	return definition.ToJob()
+}
+
+type executionStatsDB struct {
+	JobID           uuid.UUID  `db:"job_id"`
+	Total           uint64     `db:"total"`
+	Successful      uint64     `db:"successful"`
+	Failed          uint64     `db:"failed"`
+	Cancelled       uint64     `db:"cancelled"`
+	TimedOut        uint64     `db:"timed_out"`
+	AverageDuration null.Float `db:"average_duration_ms"`
+	LastExecutionAt nullTime   `db:"last_execution_at"`
+}
+
+func (s *executionStatsDB) ToModel() model.JobExecutionStats {
+	return model.JobExecutionStats{
+		JobID:           s.JobID,
+		Total:           s.Total,
+		Successful:      s.Successful,
+		Failed:          s.Failed,
+		Cancelled:       s.Cancelled,
+		TimedOut:        s.TimedOut,
+		AverageDuration: s.AverageDuration,
+		LastExecutionAt: s.LastExecutionAt.Time,
+	}
+}
+
+type slaStatusDB struct {
+	JobID      uuid.UUID `db:"job_id"`
+	SLA        jsonDoc   `db:"sla"`
+	Executions uint64    `db:"executions"`
+	Successful uint64    `db:"successful"`
+	Late       uint64    `db:"late"`
+}
+
+func (s *slaStatusDB) ToModel(at time.Time) (model.JobSLAStatus, error) {
+	status := model.JobSLAStatus{
+		JobID:       s.JobID,
+		Executions:  s.Executions,
+		Successful:  s.Successful,
+		Late:        s.Late,
+		EvaluatedAt: at,
+	}
+
+	if err := json.Unmarshal(s.SLA, &status.SLA); err != nil {
+		return status, err
+	}
+
+	status.Evaluate()
+	return status, nil
+}
+
+type executionLogsDB struct {
+	ExecutionID int     `db:"id"`
+	Entries     jsonDoc `db:"entries"`
+	Truncated   bool    `db:"truncated"`
+}
+
+func (l *executionLogsDB) ToModel() (*model.ExecutionLogs, error) {
+	logs := &model.ExecutionLogs{
+		ExecutionID: l.ExecutionID,
+		Entries:     []model.ExecutionLogEntry{},
+		Truncated:   l.Truncated,
+	}
+
+	if err := unmarshalNullableJSON(l.Entries, &logs.Entries); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal execution logs")
+	}
+
+	return logs, nil
+}
+
+type executionOutputDB struct {
+	ExecutionID int         `db:"id"`
+	ContentType null.String `db:"content_type"`
+	Size        null.Int    `db:"size"`
+	Truncated   null.Bool   `db:"truncated"`
+	Encoding    null.String `db:"encoding"`
+	Data        []byte      `db:"data"`
+}
+
+func (o *executionOutputDB) ToModel() *model.ExecutionOutput {
+	return &model.ExecutionOutput{
+		ExecutionID: o.ExecutionID,
+		ContentType: o.ContentType.String,
+		Size:        o.Size.Int64,
+		Truncated:   o.Truncated.Bool,
+		Encoding:    o.Encoding.String,
+		Data:        o.Data,
+	}
+}
+
+type executionEventDB struct {
+	ExecutionID int         `db:"execution_id"`
+	Type        string      `db:"type"`
+	Time        time.Time   `db:"time"`
+	Attempt     null.Int    `db:"attempt"`
+	Status      null.String `db:"status"`
+	Message     null.String `db:"message"`
+	InstanceID  null.String `db:"instance_id"`
+}
+
+func (e *executionEventDB) ToModel() model.ExecutionEvent {
+	return model.ExecutionEvent{
+		ExecutionID: e.ExecutionID,
+		Type:        model.ExecutionEventType(e.Type),
+		Time:        e.Time,
+		Attempt:     e.Attempt,
+		Status:      model.JobExecutionStatus(e.Status.String),
+		Message:     e.Message,
+		InstanceID:  e.InstanceID,
+	}
+}
+
+type webhookDB struct {
+	ID         uuid.UUID   `db:"id"`
+	Namespace  string      `db:"namespace"`
+	Team       null.String `db:"team"`
+	URL        string      `db:"url"`
+	Secret     string      `db:"secret"`
+	EventTypes stringArray `db:"event_types"`
+	Tags       stringArray `db:"tags"`
+	Selector   string      `db:"selector"`
+	Format     string      `db:"format"`
+	Enabled    bool        `db:"enabled"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at"`
+}
+
+func toWebhookDB(w *model.Webhook) (*webhookDB, error) {
+	// Encrypt the secret before storing it
+	encryptedSecret, err := codec.Encrypt(w.Namespace, w.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt webhook secret")
+	}
+
+	eventTypes := stringArray{}
+	for _, eventType := range w.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return &webhookDB{
+		ID:         w.ID,
+		Namespace:  w.Namespace,
+		Team:       w.Team,
+		URL:        w.URL,
+		Secret:     *encryptedSecret,
+		EventTypes: eventTypes,
+		Tags:       append(stringArray{}, w.Tags...),
+		Selector:   w.Selector,
+		Format:     string(w.Format),
+		Enabled:    w.Enabled,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}, nil
+}
+
+func (w *webhookDB) ToModel() (*model.Webhook, error) {
+	decryptedSecret, err := codec.Decrypt(w.Namespace, w.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt webhook secret")
+	}
+
+	webhook := &model.Webhook{
+		ID:         w.ID,
+		Namespace:  w.Namespace,
+		Team:       w.Team,
+		URL:        w.URL,
+		Secret:     *decryptedSecret,
+		EventTypes: []model.EventType{},
+		Tags:       append([]string{}, w.Tags...),
+		Selector:   w.Selector,
+		Format:     model.WebhookFormat(w.Format),
+		Enabled:    w.Enabled,
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+
+	for _, eventType := range w.EventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, model.EventType(eventType))
+	}
+
+	return webhook, nil
+}
+
+type webhookDeliveryDB struct {
+	ID            int         `db:"id"`
+	WebhookID     uuid.UUID   `db:"webhook_id"`
+	EventID       uuid.UUID   `db:"event_id"`
+	EventType     string      `db:"event_type"`
+	Payload       jsonDoc     `db:"payload"`
+	Status        string      `db:"status"`
+	Attempts      int         `db:"attempts"`
+	ResponseCode  null.Int    `db:"response_code"`
+	Error         null.String `db:"error"`
+	NextAttemptAt null.Time   `db:"next_attempt_at"`
+	CreatedAt     time.Time   `db:"created_at"`
+	UpdatedAt     time.Time   `db:"updated_at"`
+}
+
+func (d *webhookDeliveryDB) ToModel() model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		EventID:       d.EventID,
+		EventType:     model.EventType(d.EventType),
+		Payload:       json.RawMessage(d.Payload),
+		Status:        model.WebhookDeliveryStatus(d.Status),
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		NextAttemptAt: d.NextAttemptAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+type notificationRuleDB struct {
+	ID         uuid.UUID   `db:"id"`
+	Namespace  string      `db:"namespace"`
+	Team       null.String `db:"team"`
+	Name       string      `db:"name"`
+	Channel    string      `db:"channel"`
+	URL        string      `db:"url"`
+	Recipients stringArray `db:"recipients"`
+	Triggers   stringArray `db:"triggers"`
+	JobID      *uuid.UUID  `db:"job_id"`
+	Tags       stringArray `db:"tags"`
+	Selector   string      `db:"selector"`
+	Throttle   int         `db:"throttle"`
+	Enabled    bool        `db:"enabled"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at"`
+}
+
+func toNotificationRuleDB(r *model.NotificationRule) *notificationRuleDB {
+	triggers := stringArray{}
+	for _, trigger := range r.Triggers {
+		triggers = append(triggers, string(trigger))
+	}
+
+	return &notificationRuleDB{
+		ID:         r.ID,
+		Namespace:  r.Namespace,
+		Team:       r.Team,
+		Name:       r.Name,
+		Channel:    string(r.Channel),
+		URL:        r.URL,
+		Recipients: append(stringArray{}, r.Recipients...),
+		Triggers:   triggers,
+		JobID:      r.JobID,
+		Tags:       append(stringArray{}, r.Tags...),
+		Selector:   r.Selector,
+		Throttle:   r.Throttle,
+		Enabled:    r.Enabled,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+func (r *notificationRuleDB) ToModel() model.NotificationRule {
+	rule := model.NotificationRule{
+		ID:         r.ID,
+		Namespace:  r.Namespace,
+		Team:       r.Team,
+		Name:       r.Name,
+		Channel:    model.NotificationChannel(r.Channel),
+		URL:        r.URL,
+		Recipients: append([]string{}, r.Recipients...),
+		Triggers:   []model.NotificationTrigger{},
+		JobID:      r.JobID,
+		Tags:       append([]string{}, r.Tags...),
+		Selector:   r.Selector,
+		Throttle:   r.Throttle,
+		Enabled:    r.Enabled,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+
+	for _, trigger := range r.Triggers {
+		rule.Triggers = append(rule.Triggers, model.NotificationTrigger(trigger))
+	}
+
+	return rule
+}
+
+type notificationStateDB struct {
+	RuleID         uuid.UUID `db:"rule_id"`
+	JobID          uuid.UUID `db:"job_id"`
+	Alerting       bool      `db:"alerting"`
+	LastNotifiedAt null.Time `db:"last_notified_at"`
+	Suppressed     int       `db:"suppressed"`
+}
+
+func (s *notificationStateDB) ToModel() *model.NotificationState {
+	return &model.NotificationState{
+		RuleID:         s.RuleID,
+		JobID:          s.JobID,
+		Alerting:       s.Alerting,
+		LastNotifiedAt: s.LastNotifiedAt,
+		Suppressed:     s.Suppressed,
+	}
+}
+
+type notificationDB struct {
+	ID            int         `db:"id"`
+	RuleID        uuid.UUID   `db:"rule_id"`
+	JobID         uuid.UUID   `db:"job_id"`
+	Trigger       string      `db:"trigger"`
+	Event         jsonDoc     `db:"event"`
+	Suppressed    int         `db:"suppressed"`
+	Status        string      `db:"status"`
+	Attempts      int         `db:"attempts"`
+	Error         null.String `db:"error"`
+	NextAttemptAt null.Time   `db:"next_attempt_at"`
+	CreatedAt     time.Time   `db:"created_at"`
+	UpdatedAt     time.Time   `db:"updated_at"`
+}
+
+func (n *notificationDB) ToModel() model.Notification {
+	return model.Notification{
+		ID:            n.ID,
+		RuleID:        n.RuleID,
+		JobID:         n.JobID,
+		Trigger:       model.NotificationTrigger(n.Trigger),
+		Event:         json.RawMessage(n.Event),
+		Suppressed:    n.Suppressed,
+		Status:        model.NotificationStatus(n.Status),
+		Attempts:      n.Attempts,
+		Error:         n.Error,
+		NextAttemptAt: n.NextAttemptAt,
+		CreatedAt:     n.CreatedAt,
+		UpdatedAt:     n.UpdatedAt,
+	}
+}
+
+type apiKeyDB struct {
+	ID         uuid.UUID   `db:"id"`
+	Name       string      `db:"name"`
+	Prefix     string      `db:"prefix"`
+	KeyHash    string      `db:"key_hash"`
+	Admin      bool        `db:"admin"`
+	Namespace  string      `db:"namespace"`
+	Team       null.String `db:"team"`
+	CreatedAt  time.Time   `db:"created_at"`
+	LastUsedAt null.Time   `db:"last_used_at"`
+	RevokedAt  null.Time   `db:"revoked_at"`
+}
+
+func (k *apiKeyDB) ToModel() *model.APIKey {
+	return &model.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Admin:      k.Admin,
+		Namespace:  k.Namespace,
+		Team:       k.Team,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+}
+
+type namespaceDB struct {
+	Name                    string    `db:"name"`
+	MaxJobs                 null.Int  `db:"max_jobs"`
+	MaxConcurrentExecutions null.Int  `db:"max_concurrent_executions"`
+	Jobs                    uint64    `db:"jobs"`
+	CreatedAt               null.Time `db:"created_at"`
+	UpdatedAt               null.Time `db:"updated_at"`
+}
+
+func (n *namespaceDB) ToModel() *model.Namespace {
+	return &model.Namespace{
+		Name:                    n.Name,
+		MaxJobs:                 n.MaxJobs,
+		MaxConcurrentExecutions: n.MaxConcurrentExecutions,
+		Jobs:                    n.Jobs,
+		CreatedAt:               n.CreatedAt,
+		UpdatedAt:               n.UpdatedAt,
+	}
+}
+
+type instanceDB struct {
+	ID         string      `db:"id"`
+	Hostname   string      `db:"hostname"`
+	Version    string      `db:"version"`
+	Namespaces stringArray `db:"namespaces"`
+	Capacity   int         `db:"capacity"`
+	Load       int         `db:"load"`
+	StartedAt  time.Time   `db:"started_at"`
+	LastSeenAt time.Time   `db:"last_seen_at"`
+	ExpiresAt  time.Time   `db:"expires_at"`
+	// Alive is computed by the query, against the clock of the database
+	Alive bool `db:"alive"`
+}
+
+func (i *instanceDB) ToModel() model.Instance {
+	namespaces := []string(i.Namespaces)
+	if namespaces == nil {
+		namespaces = []string{}
+	}
+
+	return model.Instance{
+		ID:         i.ID,
+		Hostname:   i.Hostname,
+		Version:    i.Version,
+		Namespaces: namespaces,
+		Capacity:   i.Capacity,
+		Load:       i.Load,
+		StartedAt:  i.StartedAt,
+		LastSeenAt: i.LastSeenAt,
+		Alive:      i.Alive,
+	}
+}
+
+type templateDB struct {
+	Namespace   string    `db:"namespace"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	Parameters  jsonDoc   `db:"parameters"`
+	Definition  string    `db:"definition"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func toTemplateDB(t *model.JobTemplate) (*templateDB, error) {
+	parameters, err := json.Marshal(t.Parameters)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal template parameters")
+	}
+
+	// Encrypt the definition before storing it as it can contain credentials
+	encryptedDefinition, err := codec.Encrypt(t.Namespace, string(t.Definition))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt template definition")
+	}
+
+	return &templateDB{
+		Namespace:   t.Namespace,
+		Name:        t.Name,
+		Description: t.Description,
+		Parameters:  parameters,
+		Definition:  *encryptedDefinition,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}, nil
+}
+
+func (t *templateDB) ToModel() (*model.JobTemplate, error) {
+	decryptedDefinition, err := codec.Decrypt(t.Namespace, t.Definition)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt template definition")
+	}
+
+	template := &model.JobTemplate{
+		Namespace:   t.Namespace,
+		Name:        t.Name,
+		Description: t.Description,
+		Parameters:  []model.TemplateParameter{},
+		Definition:  json.RawMessage(*decryptedDefinition),
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+
+	if err := json.Unmarshal(t.Parameters, &template.Parameters); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal template parameters")
+	}
+
+	return template, nil
+}
@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+)
+
+// The namespace keys aren't supported on SQLite: the keyring creates the keys on a connection of its own while the
+// credentials are encrypted, which would wait forever for the write lock held by the transaction encrypting them.
+// The credentials are encrypted with the encryption key of the scheduler instead.
+
+func (s *sqliteStore) ListNamespaceKeys(ctx context.Context, namespace string) ([]model.NamespaceKey, error) {
+	return []model.NamespaceKey{}, nil
+}
+
+func (s *sqliteStore) RotateNamespaceKey(ctx context.Context, namespace string) (*model.NamespaceKey, error) {
+	return nil, errs.ErrNamespaceKeysDisabled
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// namespacesQuery lists every namespace having jobs or quotas, along with its number of active jobs.
+const namespacesQuery = `
+	WITH job_counts AS (
+		SELECT namespace, count(*) FILTER (WHERE status <> 'ARCHIVED') AS jobs
+		FROM jobs
+		GROUP BY namespace
+	)
+	SELECT coalesce(namespaces.name, job_counts.namespace) AS name,
+	       namespaces.max_jobs,
+	       namespaces.max_concurrent_executions,
+	       coalesce(job_counts.jobs, 0) AS jobs,
+	       namespaces.created_at,
+	       namespaces.updated_at
+	FROM namespaces
+	FULL JOIN job_counts ON job_counts.namespace = namespaces.name
+`
+
+// GetNamespace returns the namespace with the given name. Namespaces are created implicitly, so a
+// namespace without jobs nor quotas is returned empty rather than as not found.
+func (s *sqliteStore) GetNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetNamespace")
+	defer cancel()
+
+	var dbNamespaces []namespaceDB
+	query := namespacesQuery + ` WHERE coalesce(namespaces.name, job_counts.namespace) = $1`
+	if err := s.q(ctx).SelectContext(ctx, &dbNamespaces, query, name); err != nil {
+		return nil, fmt.Errorf("failed to get namespace from database: %w", err)
+	}
+
+	if len(dbNamespaces) == 0 {
+		return &model.Namespace{Name: name}, nil
+	}
+
+	return dbNamespaces[0].ToModel(), nil
+}
+
+func (s *sqliteStore) ListNamespaces(ctx context.Context) ([]model.Namespace, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListNamespaces")
+	defer cancel()
+
+	var dbNamespaces []namespaceDB
+	query := namespacesQuery + ` ORDER BY name`
+	if err := s.q(ctx).SelectContext(ctx, &dbNamespaces, query); err != nil {
+		return nil, fmt.Errorf("failed to get namespaces from database: %w", err)
+	}
+
+	namespaces := []model.Namespace{}
+	for _, dbNamespace := range dbNamespaces {
+		namespaces = append(namespaces, *dbNamespace.ToModel())
+	}
+
+	return namespaces, nil
+}
+
+func (s *sqliteStore) SetNamespaceQuotas(ctx context.Context, name string, quotas model.NamespaceQuotas) (*model.Namespace, error) {
+	ctx, cancel := s.withTimeout(ctx, "SetNamespaceQuotas")
+	defer cancel()
+
+	query := `
+		INSERT INTO namespaces (name, max_jobs, max_concurrent_executions)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name) DO UPDATE SET
+			max_jobs = excluded.max_jobs,
+			max_concurrent_executions = excluded.max_concurrent_executions,
+			updated_at = now()
+	`
+	if _, err := s.q(ctx).ExecContext(ctx, query, name, quotas.MaxJobs, quotas.MaxConcurrentExecutions); err != nil {
+		return nil, fmt.Errorf("failed to set namespace quotas in database: %w", err)
+	}
+
+	return s.GetNamespace(ctx, name)
+}
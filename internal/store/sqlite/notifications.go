@@ -0,0 +1,284 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// NewNotificationStore creates a new SQLite notification store.
+func NewNotificationStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.NotificationStorer {
+	return newStore(db, log, options...)
+}
+
+func (s *sqliteStore) CreateNotificationRule(ctx context.Context, rule *model.NotificationRule) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateNotificationRule")
+	defer cancel()
+
+	query := `
+		INSERT INTO notification_rules (id, namespace, team, name, channel, url, recipients, triggers, job_id, tags, selector, throttle, enabled, created_at, updated_at)
+		VALUES (:id, :namespace, :team, :name, :channel, :url, :recipients, :triggers, :job_id, :tags, :selector, :throttle, :enabled, :created_at, :updated_at)
+	`
+	if _, err := s.q(ctx).NamedExecContext(ctx, query, toNotificationRuleDB(rule)); err != nil {
+		return fmt.Errorf("failed to insert notification rule into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) GetNotificationRule(ctx context.Context, id uuid.UUID) (*model.NotificationRule, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetNotificationRule")
+	defer cancel()
+
+	var dbRule notificationRuleDB
+	if err := s.q(ctx).GetContext(ctx, &dbRule, `SELECT * FROM notification_rules WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrNotificationRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get notification rule from database: %w", err)
+	}
+
+	rule := dbRule.ToModel()
+	return &rule, nil
+}
+
+func (s *sqliteStore) ListNotificationRules(ctx context.Context, namespace, team string) ([]model.NotificationRule, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListNotificationRules")
+	defer cancel()
+
+	var dbRules []notificationRuleDB
+	query := `SELECT * FROM notification_rules WHERE namespace = $1 AND ($2 = '' OR team = $2) ORDER BY created_at DESC, id DESC`
+	if err := s.q(ctx).SelectContext(ctx, &dbRules, query, namespace, team); err != nil {
+		return nil, fmt.Errorf("failed to get notification rules from database: %w", err)
+	}
+
+	rules := []model.NotificationRule{}
+	for _, dbRule := range dbRules {
+		rules = append(rules, dbRule.ToModel())
+	}
+
+	return rules, nil
+}
+
+func (s *sqliteStore) DeleteNotificationRule(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := s.withTimeout(ctx, "DeleteNotificationRule")
+	defer cancel()
+
+	res, err := s.q(ctx).ExecContext(ctx, `DELETE FROM notification_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification rule from database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete notification rule from database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrNotificationRuleNotFound
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) GetEventNotificationRules(ctx context.Context, event model.Event) ([]model.NotificationRule, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetEventNotificationRules")
+	defer cancel()
+
+	// tag selectors are evaluated here rather than in the query
+	query := `
+		SELECT * FROM notification_rules
+		WHERE enabled
+		  AND (job_id IS NULL OR job_id = $1)
+		  AND NOT EXISTS (SELECT 1 FROM json_each(tags) WHERE value NOT IN (SELECT value FROM json_each($2)))
+		  AND namespace = $3
+		  AND (team IS NULL OR team = $4)
+	`
+	var dbRules []notificationRuleDB
+	tags := append(stringArray{}, event.Tags...)
+	if err := s.q(ctx).SelectContext(ctx, &dbRules, query, event.JobID, tags, event.Namespace, event.Team); err != nil {
+		return nil, fmt.Errorf("failed to get notification rules from database: %w", err)
+	}
+
+	rules := []model.NotificationRule{}
+	for _, dbRule := range dbRules {
+		rule := dbRule.ToModel()
+		if rule.Matches(event) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// GetNotificationState returns the state of the notifications of the job by the rule, and a blank state if the job was never notified of.
+func (s *sqliteStore) GetNotificationState(ctx context.Context, ruleID, jobID uuid.UUID) (*model.NotificationState, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetNotificationState")
+	defer cancel()
+
+	var dbState notificationStateDB
+	query := `SELECT * FROM notification_states WHERE rule_id = $1 AND job_id = $2`
+	if err := s.q(ctx).GetContext(ctx, &dbState, query, ruleID, jobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &model.NotificationState{RuleID: ruleID, JobID: jobID}, nil
+		}
+		return nil, fmt.Errorf("failed to get notification state from database: %w", err)
+	}
+
+	return dbState.ToModel(), nil
+}
+
+func (s *sqliteStore) SaveNotificationState(ctx context.Context, state *model.NotificationState) error {
+	ctx, cancel := s.withTimeout(ctx, "SaveNotificationState")
+	defer cancel()
+
+	query := `
+		INSERT INTO notification_states (rule_id, job_id, alerting, last_notified_at, suppressed)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (rule_id, job_id) DO UPDATE
+		SET alerting = excluded.alerting, last_notified_at = excluded.last_notified_at, suppressed = excluded.suppressed
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query, state.RuleID, state.JobID, state.Alerting, state.LastNotifiedAt, state.Suppressed)
+	if err != nil {
+		return fmt.Errorf("failed to save notification state in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) EnqueueNotification(ctx context.Context, notification *model.Notification) error {
+	ctx, cancel := s.withTimeout(ctx, "EnqueueNotification")
+	defer cancel()
+
+	query := `
+		INSERT INTO notifications (rule_id, job_id, trigger, event, suppressed, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, 'PENDING', now())
+		RETURNING *
+	`
+	var dbNotification notificationDB
+	err := s.q(ctx).GetContext(ctx, &dbNotification, query,
+		notification.RuleID,
+		notification.JobID,
+		string(notification.Trigger),
+		string(notification.Event),
+		notification.Suppressed,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue notification in database: %w", err)
+	}
+
+	*notification = dbNotification.ToModel()
+	return nil
+}
+
+// ClaimNotifications returns the pending notifications that are due at the given time and postpones their next
+// attempt until leaseUntil, so that other instances don't pick them up while they are being sent.
+func (s *sqliteStore) ClaimNotifications(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.Notification, error) {
+	ctx, cancel := s.withTimeout(ctx, "ClaimNotifications")
+	defer cancel()
+
+	query := `
+		UPDATE notifications SET next_attempt_at = $2, updated_at = now()
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+		)
+		RETURNING *
+	`
+
+	var dbNotifications []notificationDB
+	if err := s.q(ctx).SelectContext(ctx, &dbNotifications, query, at, leaseUntil, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim notifications in database: %w", err)
+	}
+
+	notifications := []model.Notification{}
+	for _, dbNotification := range dbNotifications {
+		notifications = append(notifications, dbNotification.ToModel())
+	}
+
+	return notifications, nil
+}
+
+func (s *sqliteStore) FinishNotification(ctx context.Context, notification model.Notification) error {
+	ctx, cancel := s.withTimeout(ctx, "FinishNotification")
+	defer cancel()
+
+	query := `
+		UPDATE notifications
+		SET status = $2, attempts = $3, error = $4, next_attempt_at = $5, updated_at = now()
+		WHERE id = $1
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query,
+		notification.ID,
+		string(notification.Status),
+		notification.Attempts,
+		notification.Error,
+		notification.NextAttemptAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update notification in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) GetNotifications(ctx context.Context, ruleID uuid.UUID, limit uint64, cursor *model.Cursor) ([]model.Notification, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetNotifications")
+	defer cancel()
+
+	args := []interface{}{ruleID, limit}
+	extraFilter := ""
+
+	// keyset pagination on (created_at, id)
+	if cursor != nil {
+		id, err := strconv.Atoi(cursor.ID)
+		if err != nil {
+			return nil, errs.ErrInvalidCursor
+		}
+
+		args = append(args, cursor.Time, id)
+		extraFilter = fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	query := `
+		SELECT * FROM notifications
+		WHERE rule_id = $1` + extraFilter +
+		` ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+
+	var dbNotifications []notificationDB
+	if err := s.q(ctx).SelectContext(ctx, &dbNotifications, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get notifications from database: %w", err)
+	}
+
+	notifications := []model.Notification{}
+	for _, dbNotification := range dbNotifications {
+		notifications = append(notifications, dbNotification.ToModel())
+	}
+
+	return notifications, nil
+}
+
+func (s *sqliteStore) CountNotifications(ctx context.Context, ruleID uuid.UUID) (uint64, error) {
+	ctx, cancel := s.withTimeout(ctx, "CountNotifications")
+	defer cancel()
+
+	var count uint64
+	if err := s.q(ctx).GetContext(ctx, &count, `SELECT count(*) FROM notifications WHERE rule_id = $1`, ruleID); err != nil {
+		return 0, fmt.Errorf("failed to count notifications in database: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// payloadEncryptionBatchSize is the number of rows encrypted at once by EncryptPayloads.
+const payloadEncryptionBatchSize = 500
+
+// EncryptPayloads encrypts the headers and bodies of the HTTP jobs and of the definitions recorded by their
+// executions which are stored in plain text, e.g. the ones stored before payload encryption was enabled. It returns
+// the number of rows encrypted. The rows updated in the meantime are skipped, as their update encrypted them.
+func EncryptPayloads(ctx context.Context, db *sqlx.DB, log *otelzap.Logger) (int64, error) {
+	s := newStore(db, log)
+
+	jobs, err := s.encryptPayloads(ctx, "jobs")
+	if err != nil {
+		return jobs, err
+	}
+
+	executions, err := s.encryptPayloads(ctx, "job_executions")
+	return jobs + executions, err
+}
+
+// encryptPayloads encrypts the plain text payloads of the HTTP jobs of the table, by batches, until a batch has no
+// row left to encrypt.
+func (s *sqliteStore) encryptPayloads(ctx context.Context, table string) (int64, error) {
+	selectQuery := fmt.Sprintf(`
+		SELECT id, namespace, http_job
+		FROM %s
+		WHERE http_job IS NOT NULL AND json_type(http_job, '$.encrypted_payload') IS NULL
+		LIMIT $1
+	`, table)
+	updateQuery := fmt.Sprintf(`UPDATE %s SET http_job = $3 WHERE id = $1 AND http_job = $2`, table)
+
+	var total int64
+	for {
+		var rows []struct {
+			ID        string  `db:"id"`
+			Namespace string  `db:"namespace"`
+			HTTPJob   jsonDoc `db:"http_job"`
+		}
+		if err := s.db.SelectContext(ctx, &rows, selectQuery, payloadEncryptionBatchSize); err != nil {
+			return total, fmt.Errorf("failed to get plain text payloads from %s: %w", table, err)
+		}
+
+		var encrypted int64
+		for _, row := range rows {
+			httpJob, err := codec.EncryptHTTPPayload(row.Namespace, row.HTTPJob)
+			if err != nil {
+				return total, fmt.Errorf("failed to encrypt http job %s of %s: %w", row.ID, table, err)
+			}
+
+			result, err := s.db.ExecContext(ctx, updateQuery, row.ID, row.HTTPJob, jsonDoc(httpJob))
+			if err != nil {
+				return total, fmt.Errorf("failed to update http job %s of %s: %w", row.ID, table, err)
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return total, err
+			}
+			encrypted += affected
+		}
+
+		total += encrypted
+		s.log.Debug("Encrypted HTTP job payloads", zap.String("table", table), zap.Int64("rows", encrypted))
+
+		if len(rows) < payloadEncryptionBatchSize || encrypted == 0 {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// GetExpiredJobExecutions returns up to limit finished executions, oldest first, which were either created before the
+// given time or are not among the keepLast latest executions of their job, or both if the expiry matches all the
+// limits. Unset limits don't expire executions.
+func (s *sqliteStore) GetExpiredJobExecutions(ctx context.Context, expiry model.ExecutionExpiry, limit uint) ([]*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetExpiredJobExecutions")
+	defer cancel()
+
+	// with MatchAll, an unset limit doesn't exclude executions instead of not expiring them
+	query := `
+		SELECT e.* FROM job_executions e
+		WHERE e.end_time IS NOT NULL AND ($4 IS NULL OR e.job_id = $4) AND
+		CASE WHEN $5 THEN
+			($1 IS NULL OR e.created_at < $1) AND
+			($2 = 0 OR (e.start_time, e.id) < (
+				SELECT k.start_time, k.id FROM job_executions k
+				WHERE k.job_id = e.job_id
+				ORDER BY k.start_time DESC, k.id DESC
+				LIMIT 1 OFFSET max($2 - 1, 0)
+			))
+		ELSE
+			($1 IS NOT NULL AND e.created_at < $1) OR
+			($2 > 0 AND (e.start_time, e.id) < (
+				SELECT k.start_time, k.id FROM job_executions k
+				WHERE k.job_id = e.job_id
+				ORDER BY k.start_time DESC, k.id DESC
+				LIMIT 1 OFFSET max($2 - 1, 0)
+			))
+		END
+		ORDER BY e.id
+		LIMIT $3
+	`
+
+	var dbExecutions []executionDB
+	if err := s.q(ctx).SelectContext(ctx, &dbExecutions, query, expiry.Before, expiry.KeepLast, limit, expiry.JobID, expiry.MatchAll); err != nil {
+		return nil, fmt.Errorf("failed to get expired job executions from database: %w", err)
+	}
+
+	executions := make([]*model.JobExecution, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		executions = append(executions, dbExecution.ToModel())
+	}
+
+	return executions, nil
+}
+
+// DeleteJobExecutions deletes the finished executions among the given ones along with their logs, outputs and timelines.
+func (s *sqliteStore) DeleteJobExecutions(ctx context.Context, executionIDs []int) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx, "DeleteJobExecutions")
+	defer cancel()
+
+	ids := intArray(executionIDs)
+
+	var deleted int64
+	err := s.InTx(ctx, func(ctx context.Context) error {
+		res, err := s.q(ctx).ExecContext(ctx, `DELETE FROM job_executions WHERE id IN (SELECT value FROM json_each($1)) AND end_time IS NOT NULL`, ids)
+		if err != nil {
+			return fmt.Errorf("failed to delete job executions from database: %w", err)
+		}
+
+		// the logs, outputs and timelines only reference the jobs
+		if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM job_execution_logs WHERE execution_id IN (SELECT value FROM json_each($1))`, ids); err != nil {
+			return fmt.Errorf("failed to delete job execution logs from database: %w", err)
+		}
+
+		if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM job_execution_outputs WHERE execution_id IN (SELECT value FROM json_each($1))`, ids); err != nil {
+			return fmt.Errorf("failed to delete job execution outputs from database: %w", err)
+		}
+
+		if _, err := s.q(ctx).ExecContext(ctx, `DELETE FROM job_execution_events WHERE execution_id IN (SELECT value FROM json_each($1))`, ids); err != nil {
+			return fmt.Errorf("failed to delete job execution events from database: %w", err)
+		}
+
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
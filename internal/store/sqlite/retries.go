@@ -0,0 +1,248 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/google/uuid"
+)
+
+// RetryJobExecution creates a pending execution re-running the execution of the namespace, with either the
+// job definition the execution ran with or the current one. Pending executions are claimed by the runners.
+func (s *sqliteStore) RetryJobExecution(ctx context.Context, namespace string, executionID int, current bool) (*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "RetryJobExecution")
+	defer cancel()
+
+	query := `
+		INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, retry_of, scheduled_time, start_time, status, created_at)
+		SELECT e.job_id, e.namespace,
+		       CASE WHEN $3 THEN j.version ELSE e.job_version END,
+		       CASE WHEN $3 THEN j.type ELSE e.job_type END,
+		       CASE WHEN $3 THEN j.http_job ELSE e.http_job END,
+		       CASE WHEN $3 THEN j.amqp_job ELSE e.amqp_job END,
+		       CASE WHEN $3 THEN j.custom_job ELSE e.custom_job END,
+		       e.id, now(), now(), 'PENDING', now()
+		FROM job_executions e
+		JOIN jobs j ON j.id = e.job_id
+		WHERE e.id = $1 AND e.namespace = $2 AND ($3 OR e.job_type IS NOT NULL)
+		RETURNING *
+	`
+
+	var dbExecution executionDB
+	err := s.q(ctx).GetContext(ctx, &dbExecution, query, executionID, namespace, current)
+	if err == nil {
+		return dbExecution.ToModel(), nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to retry job execution in database: %w", err)
+	}
+
+	// distinguish between a missing execution and one without a recorded job definition
+	var exists bool
+	if err := s.q(ctx).GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM job_executions WHERE id = $1 AND namespace = $2)`, executionID, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get job execution from database: %w", err)
+	}
+
+	if !exists {
+		return nil, errs.ErrExecutionNotFound
+	}
+
+	return nil, errs.ErrExecutionNotRetryable
+}
+
+// CreatePendingJobExecution creates a pending execution of the job with its current definition. Pending executions
+// are claimed by the runners.
+func (s *sqliteStore) CreatePendingJobExecution(ctx context.Context, jobID uuid.UUID) (*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreatePendingJobExecution")
+	defer cancel()
+
+	query := `
+		INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, scheduled_time, start_time, status, created_at)
+		SELECT id, namespace, version, type, http_job, amqp_job, custom_job, now(), now(), 'PENDING', now()
+		FROM jobs WHERE id = $1
+		RETURNING *
+	`
+
+	var dbExecution executionDB
+	if err := s.q(ctx).GetContext(ctx, &dbExecution, query, jobID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to create job execution in database: %w", err)
+	}
+
+	return dbExecution.ToModel(), nil
+}
+
+// CreateOverriddenJobExecution creates a pending execution of the job with the given definition instead of its
+// current one, e.g. a manual run with a tweaked payload. The definition is encrypted like the one of the job.
+func (s *sqliteStore) CreateOverriddenJobExecution(ctx context.Context, definition *model.Job) (*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateOverriddenJobExecution")
+	defer cancel()
+
+	dbJob, err := toJobDB(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, overridden, scheduled_time, start_time, status, created_at)
+		SELECT id, namespace, version, $2, $3, $4, $5, true, now(), now(), 'PENDING', now()
+		FROM jobs WHERE id = $1
+		RETURNING *
+	`
+
+	var dbExecution executionDB
+	if err := s.q(ctx).GetContext(ctx, &dbExecution, query, dbJob.ID, dbJob.Type, dbJob.HTTPJob, dbJob.AMQPJob, dbJob.CustomJob); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to create job execution in database: %w", err)
+	}
+
+	return dbExecution.ToModel(), nil
+}
+
+func (s *sqliteStore) CreateBackfillJobExecutions(ctx context.Context, jobID uuid.UUID, scheduledTimes []time.Time) ([]*model.JobExecution, error) {
+	ctx, cancel := s.withTimeout(ctx, "CreateBackfillJobExecutions")
+	defer cancel()
+
+	query := `
+		INSERT INTO job_executions (job_id, namespace, job_version, job_type, http_job, amqp_job, custom_job, scheduled_time, start_time, status, created_at)
+		SELECT id, namespace, version, type, http_job, amqp_job, custom_job, $2, now(), 'PENDING', now()
+		FROM jobs WHERE id = $1
+		RETURNING *
+	`
+
+	var dbExecutions []executionDB
+	err := s.InTx(ctx, func(ctx context.Context) error {
+		for _, scheduledTime := range scheduledTimes {
+			var created []executionDB
+			if err := s.q(ctx).SelectContext(ctx, &created, query, jobID, scheduledTime); err != nil {
+				return err
+			}
+			dbExecutions = append(dbExecutions, created...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job executions in database: %w", err)
+	}
+
+	if len(dbExecutions) == 0 && len(scheduledTimes) > 0 {
+		return nil, errs.ErrJobNotFound
+	}
+
+	executions := make([]*model.JobExecution, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		executions = append(executions, dbExecution.ToModel())
+	}
+
+	// the executions are returned in any order, the IDs follow the order of the scheduled times
+	slices.SortFunc(executions, func(a, b *model.JobExecution) int { return a.ID - b.ID })
+
+	return executions, nil
+}
+
+func (s *sqliteStore) GetJobExecutionScheduledTimes(ctx context.Context, jobID uuid.UUID, from, to time.Time) ([]time.Time, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetJobExecutionScheduledTimes")
+	defer cancel()
+
+	query := `
+		SELECT DISTINCT scheduled_time FROM job_executions
+		WHERE job_id = $1 AND scheduled_time >= $2 AND scheduled_time < $3
+		ORDER BY scheduled_time
+	`
+
+	times := []time.Time{}
+	if err := s.q(ctx).SelectContext(ctx, &times, query, jobID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get job execution scheduled times from database: %w", err)
+	}
+
+	return times, nil
+}
+
+// ClaimJobExecutionRetries starts up to limit pending executions of the given namespaces (all of them if empty),
+// and returns them with the job definition they run with.
+func (s *sqliteStore) ClaimJobExecutionRetries(ctx context.Context, at time.Time, namespaces []string, limit uint) ([]model.ExecutionRetry, error) {
+	ctx, cancel := s.withTimeout(ctx, "ClaimJobExecutionRetries")
+	defer cancel()
+
+	query := `
+		UPDATE job_executions SET status = 'RUNNING', start_time = $1
+		WHERE id IN (
+			SELECT id FROM job_executions
+			WHERE end_time IS NULL AND status = 'PENDING'
+			  AND (json_array_length($2) = 0 OR namespace IN (SELECT value FROM json_each($2)))
+			ORDER BY id
+			LIMIT $3
+		)
+		RETURNING *
+	`
+
+	var dbExecutions []executionDB
+	if err := s.q(ctx).SelectContext(ctx, &dbExecutions, query, at, append(stringArray{}, namespaces...), limit); err != nil {
+		return nil, fmt.Errorf("failed to claim job execution retries: %w", err)
+	}
+
+	if len(dbExecutions) == 0 {
+		return nil, nil
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(dbExecutions))
+	for _, dbExecution := range dbExecutions {
+		jobIDs = append(jobIDs, dbExecution.JobID)
+	}
+
+	var dbJobs []jobDB
+	if err := s.q(ctx).SelectContext(ctx, &dbJobs, `SELECT * FROM jobs WHERE id IN (SELECT value FROM json_each($1))`, uuidArray(jobIDs)); err != nil {
+		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
+	}
+
+	jobsByID := map[uuid.UUID]*jobDB{}
+	for i := range dbJobs {
+		jobsByID[dbJobs[i].ID] = &dbJobs[i]
+	}
+
+	retries := []model.ExecutionRetry{}
+	for _, dbExecution := range dbExecutions {
+		// the job can't be missing, its executions are deleted along with it
+		dbJob, ok := jobsByID[dbExecution.JobID]
+		if !ok {
+			continue
+		}
+
+		job, err := dbExecution.ToJob(dbJob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+
+		retries = append(retries, model.ExecutionRetry{ExecutionID: dbExecution.ID, Job: job})
+	}
+
+	return retries, nil
+}
+
+func (s *sqliteStore) ReleaseJobExecutionRetry(ctx context.Context, executionID int) error {
+	ctx, cancel := s.withTimeout(ctx, "ReleaseJobExecutionRetry")
+	defer cancel()
+
+	query := `
+		UPDATE job_executions SET status = 'PENDING'
+		WHERE id = $1 AND status = 'RUNNING' AND end_time IS NULL
+	`
+
+	if _, err := s.q(ctx).ExecContext(ctx, query, executionID); err != nil {
+		return fmt.Errorf("failed to release job execution retry: %w", err)
+	}
+
+	return nil
+}
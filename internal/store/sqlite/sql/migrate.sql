@@ -0,0 +1,369 @@
+-- Version: 1.01
+-- Description: Create the schema
+
+-- Times are stored in UTC as text with nanoseconds, like the times written by the store, so that they compare in
+-- order. UUIDs, arrays and JSON documents are stored as text.
+
+CREATE TABLE jobs (
+    id TEXT PRIMARY KEY,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    name TEXT,
+    type TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'RUNNING',
+    version INTEGER NOT NULL DEFAULT 1,
+
+    execute_at TIMESTAMP,
+    cron_schedule TEXT,
+
+    http_job TEXT,
+    amqp_job TEXT,
+    custom_job TEXT,
+
+    next_run TIMESTAMP,
+    locked_until TIMESTAMP,
+    locked_by TEXT,
+
+    tags TEXT,
+    priority TEXT NOT NULL DEFAULT 'NORMAL',
+    ttl INTEGER,
+    completed_at TIMESTAMP,
+    last_execution_status TEXT,
+
+    required_capabilities TEXT NOT NULL DEFAULT '[]',
+    execution_timeout INTEGER,
+    misfire_policy TEXT NOT NULL DEFAULT 'RUN_ONCE',
+
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+
+    -- Ensure that only the definition matching the type of the job is set
+    CONSTRAINT check_job_type CHECK (
+        (type = 'HTTP' AND http_job IS NOT NULL AND amqp_job IS NULL AND custom_job IS NULL) OR
+        (type = 'AMQP' AND http_job IS NULL AND amqp_job IS NOT NULL AND custom_job IS NULL) OR
+        (type NOT IN ('HTTP', 'AMQP') AND http_job IS NULL AND amqp_job IS NULL AND custom_job IS NOT NULL)
+    ),
+
+    -- Ensure that only one of execute_at or cron_schedule is set
+    CONSTRAINT check_job_schedule CHECK (
+        (execute_at IS NOT NULL AND cron_schedule IS NULL) OR
+        (execute_at IS NULL AND cron_schedule IS NOT NULL)
+    )
+);
+
+CREATE INDEX next_run_index ON jobs (next_run);
+
+CREATE INDEX locked_until_index ON jobs (locked_until);
+
+CREATE INDEX completed_at_index ON jobs (completed_at) WHERE ttl IS NOT NULL;
+
+CREATE INDEX jobs_created_at_id_index ON jobs (created_at DESC, id DESC);
+
+CREATE INDEX jobs_status_index ON jobs (status);
+
+CREATE INDEX jobs_type_index ON jobs (type);
+
+CREATE INDEX jobs_last_execution_status_index ON jobs (last_execution_status);
+
+CREATE INDEX jobs_namespace_created_at_id_index ON jobs (namespace, created_at DESC, id DESC);
+
+CREATE INDEX jobs_namespace_locked_until_index ON jobs (namespace, locked_until);
+
+CREATE UNIQUE INDEX jobs_namespace_name_unique_index ON jobs (namespace, name) WHERE name IS NOT NULL AND status <> 'ARCHIVED';
+
+CREATE TABLE job_executions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id TEXT NOT NULL,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    status TEXT NOT NULL,
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP,
+    error_message TEXT,
+    scheduled_time TIMESTAMP,
+    cancel_requested_at TIMESTAMP,
+    instance_id TEXT,
+
+    -- the job version and target an execution ran with, so it can be re-run as it was
+    job_version INTEGER,
+    job_type TEXT,
+    http_job TEXT,
+    amqp_job TEXT,
+    custom_job TEXT,
+
+    retry_of INTEGER,
+
+    progress_percent INTEGER,
+    progress_message TEXT,
+    progress_updated_at TIMESTAMP,
+
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX job_id_index ON job_executions (job_id);
+
+CREATE INDEX job_executions_start_time_index ON job_executions (start_time);
+
+CREATE INDEX job_executions_job_id_start_time_id_index ON job_executions (job_id, start_time DESC, id DESC);
+
+CREATE INDEX job_executions_unfinished_index ON job_executions (id) WHERE end_time IS NULL;
+
+CREATE TABLE job_execution_logs (
+    execution_id INTEGER PRIMARY KEY,
+    job_id TEXT NOT NULL,
+    entries TEXT NOT NULL,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX job_execution_logs_job_id_index ON job_execution_logs (job_id);
+
+CREATE TABLE webhooks (
+    id TEXT PRIMARY KEY,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '[]',
+    tags TEXT NOT NULL DEFAULT '[]',
+    selector TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now'))
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER,
+    error TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE,
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX webhook_deliveries_pending_index ON webhook_deliveries (next_attempt_at) WHERE status = 'PENDING';
+
+CREATE INDEX webhook_deliveries_webhook_id_created_at_id_index ON webhook_deliveries (webhook_id, created_at DESC, id DESC);
+
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    admin BOOLEAN NOT NULL DEFAULT FALSE,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE TABLE namespaces (
+    name TEXT PRIMARY KEY,
+    max_jobs INTEGER,
+    max_concurrent_executions INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now'))
+);
+
+CREATE TABLE instances (
+    id TEXT PRIMARY KEY,
+    hostname TEXT NOT NULL,
+    version TEXT NOT NULL,
+    namespaces TEXT NOT NULL DEFAULT '[]',
+    capacity INTEGER NOT NULL,
+    load INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE job_templates (
+    namespace TEXT NOT NULL DEFAULT 'default',
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    parameters TEXT NOT NULL DEFAULT '[]',
+    definition TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    PRIMARY KEY (namespace, name)
+);
+
+-- the notifications of the events, wakeups and cancellations to the other connections, polled by their listeners in
+-- place of the LISTEN and NOTIFY of Postgres
+CREATE TABLE signals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now'))
+);
+
+CREATE INDEX signals_channel_id_index ON signals (channel, id);
+
+-- Version: 1.02
+-- Description: Add the outbox of the job lifecycle events
+
+-- events are written in the transaction of the change of the job, and removed once relayed
+CREATE TABLE event_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now'))
+);
+
+-- Version: 1.03
+-- Description: Add the outputs of the executions, compressed apart from the executions
+
+CREATE TABLE job_execution_outputs (
+    execution_id INTEGER PRIMARY KEY,
+    job_id TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    encoding TEXT NOT NULL,
+    data BLOB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX job_execution_outputs_job_id_index ON job_execution_outputs (job_id);
+
+-- Version: 1.04
+-- Description: Add the timelines of the executions
+
+CREATE TABLE job_execution_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    execution_id INTEGER NOT NULL,
+    job_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    time TIMESTAMP NOT NULL,
+    attempt INTEGER,
+    status TEXT,
+    message TEXT,
+    instance_id TEXT,
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX job_execution_events_execution_id_index ON job_execution_events (execution_id, time);
+
+CREATE INDEX job_execution_events_job_id_index ON job_execution_events (job_id);
+
+-- Version: 1.05
+-- Description: Add the notification rules and their notifications
+
+CREATE TABLE notification_rules (
+    id TEXT PRIMARY KEY,
+    namespace TEXT NOT NULL DEFAULT 'default',
+    name TEXT NOT NULL DEFAULT '',
+    channel TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    recipients TEXT NOT NULL DEFAULT '[]',
+    triggers TEXT NOT NULL DEFAULT '[]',
+    job_id TEXT,
+    tags TEXT NOT NULL DEFAULT '[]',
+    selector TEXT NOT NULL DEFAULT '',
+    throttle INTEGER NOT NULL DEFAULT 3600,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notification_rules_namespace_index ON notification_rules (namespace);
+
+CREATE TABLE notification_states (
+    rule_id TEXT NOT NULL,
+    job_id TEXT NOT NULL,
+    alerting BOOLEAN NOT NULL DEFAULT FALSE,
+    last_notified_at TIMESTAMP,
+    suppressed INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (rule_id, job_id),
+    FOREIGN KEY (rule_id) REFERENCES notification_rules (id) ON DELETE CASCADE,
+    FOREIGN KEY (job_id) REFERENCES jobs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notification_states_job_id_index ON notification_states (job_id);
+
+CREATE TABLE notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id TEXT NOT NULL,
+    -- kept after the job is deleted, as a log of the notifications
+    job_id TEXT NOT NULL,
+    trigger TEXT NOT NULL,
+    event TEXT NOT NULL,
+    suppressed INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f000000', 'now')),
+    FOREIGN KEY (rule_id) REFERENCES notification_rules (id) ON DELETE CASCADE
+);
+
+CREATE INDEX notifications_pending_index ON notifications (next_attempt_at) WHERE status = 'PENDING';
+
+CREATE INDEX notifications_rule_id_created_at_id_index ON notifications (rule_id, created_at DESC, id DESC);
+
+-- Version: 1.06
+-- Description: Add the SLAs of the jobs
+
+ALTER TABLE jobs ADD COLUMN sla TEXT;
+
+CREATE INDEX jobs_sla_index ON jobs (id) WHERE sla IS NOT NULL;
+
+-- Version: 1.07
+-- Description: Add the labels the jobs opt into adding to their execution metrics
+
+ALTER TABLE jobs ADD COLUMN metric_labels TEXT NOT NULL DEFAULT '[]';
+
+-- Version: 1.08
+-- Description: Add the payload format of the webhooks
+
+ALTER TABLE webhooks ADD COLUMN format TEXT NOT NULL DEFAULT 'json';
+
+-- Version: 1.09
+-- Description: Add the owner and team of the jobs, and the team of the API keys
+
+ALTER TABLE jobs ADD COLUMN owner TEXT;
+
+ALTER TABLE jobs ADD COLUMN team TEXT;
+
+ALTER TABLE api_keys ADD COLUMN team TEXT;
+
+CREATE INDEX jobs_namespace_team_index ON jobs (namespace, team);
+
+-- Version: 1.10
+-- Description: Record the executions of manual runs whose definition was overridden
+
+ALTER TABLE job_executions ADD COLUMN overridden BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Version: 1.11
+-- Description: Add the metadata of the jobs
+
+ALTER TABLE jobs ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
+
+-- Version: 1.12
+-- Description: Add the result sinks of the jobs
+
+ALTER TABLE jobs ADD COLUMN result_sink TEXT;
+
+-- Version: 1.13
+-- Description: Add the external IDs of the jobs
+
+ALTER TABLE jobs ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX jobs_namespace_external_id_unique_index ON jobs (namespace, external_id) WHERE external_id IS NOT NULL AND status <> 'ARCHIVED';
+
+-- Version: 1.14
+-- Description: Add the teams of the webhooks and notification rules
+
+ALTER TABLE webhooks ADD COLUMN team TEXT;
+
+ALTER TABLE notification_rules ADD COLUMN team TEXT;
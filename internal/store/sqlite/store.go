@@ -0,0 +1,62 @@
+// Package sqlite is the storage backend of the scheduler on SQLite, for local development and small installs running
+// a single runner, e.g. the manager in all-in-one mode.
+//
+// SQLite has a single writer: every transaction takes the write lock of the database when it begins, so the jobs
+// claimed by a transaction can't be claimed by another one, in place of the row locks skipped by the runners on
+// Postgres. The notifications of the events, wakeups and cancellations are written to a table polled by the
+// listeners, in place of LISTEN and NOTIFY.
+package sqlite
+
+import (
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// defaultPollInterval is how often the listeners poll for notifications by default.
+const defaultPollInterval = 250 * time.Millisecond
+
+type sqliteStore struct {
+	db           *sqlx.DB
+	log          *otelzap.Logger
+	timeouts     database.QueryTimeouts
+	pollInterval time.Duration
+}
+
+// Option configures the SQLite stores.
+type Option func(*sqliteStore)
+
+// WithQueryTimeouts bounds the duration of the operations of the stores.
+func WithQueryTimeouts(timeouts database.QueryTimeouts) Option {
+	return func(s *sqliteStore) {
+		s.timeouts = timeouts
+	}
+}
+
+// WithPollInterval sets how often the listeners poll for notifications.
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *sqliteStore) {
+		s.pollInterval = interval
+	}
+}
+
+func newStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) *sqliteStore {
+	s := &sqliteStore{
+		db:           db,
+		log:          log,
+		pollInterval: defaultPollInterval,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// New creates a new SQLite store on a database handle opened with Open.
+func New(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.Storer {
+	return newStore(db, log, options...)
+}
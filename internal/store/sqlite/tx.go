@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// txKey is the context key of the transaction the operations of the store run in.
+type txKey struct{}
+
+// querier runs the queries of the store, either on the pool or in a transaction.
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+// q returns the transaction of the context, or the pool if there is none.
+func (s *sqliteStore) q(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+
+	return s.db
+}
+
+// InTx runs fn in a transaction, which is committed if fn returns nil and rolled back otherwise. The operations of
+// the store called with the context passed to fn run in the transaction, and so do the events they publish. Nested
+// calls join the transaction in progress. Transactions hold the write lock of the database until they end, so the
+// operations called with another context wait for it.
+func (s *sqliteStore) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// jsonDoc is a JSON document, stored as text. SQLite reads blobs passed to its JSON functions as its binary JSON
+// format, so the documents must not be passed as []byte.
+type jsonDoc []byte
+
+func (d jsonDoc) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	return string(d), nil
+}
+
+func (d *jsonDoc) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = nil
+	case string:
+		*d = jsonDoc(v)
+	case []byte:
+		*d = append(jsonDoc{}, v...)
+	default:
+		return fmt.Errorf("unsupported JSON document type %T", src)
+	}
+
+	return nil
+}
+
+// stringArray is an array of strings, stored as a JSON array. Like the arrays of Postgres, nil arrays are NULL.
+type stringArray []string
+
+func (a stringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal([]string(a))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(encoded), nil
+}
+
+func (a *stringArray) Scan(src any) error {
+	var doc jsonDoc
+	if err := doc.Scan(src); err != nil {
+		return err
+	}
+
+	if doc == nil {
+		*a = nil
+		return nil
+	}
+
+	return json.Unmarshal(doc, (*[]string)(a))
+}
+
+// uuidArray converts the IDs to an array parameter, read with json_each in the query.
+func uuidArray(ids []uuid.UUID) stringArray {
+	array := make(stringArray, 0, len(ids))
+	for _, id := range ids {
+		array = append(array, id.String())
+	}
+
+	return array
+}
+
+// intArray converts the IDs to an array parameter, read with json_each in the query.
+func intArray(ids []int) string {
+	encoded, _ := json.Marshal(ids)
+	if ids == nil {
+		return "[]"
+	}
+
+	return string(encoded)
+}
+
+// nullTime is a nullable time computed by a query, e.g. an aggregate of a time column, which SQLite returns as text.
+type nullTime struct {
+	null.Time
+}
+
+func (t *nullTime) Scan(src any) error {
+	switch v := src.(type) {
+	case string, []byte:
+		parsed, err := parseTime(v)
+		if err != nil {
+			return err
+		}
+
+		t.Time = null.TimeFrom(parsed)
+		return nil
+	default:
+		return t.Time.Scan(src)
+	}
+}
@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	errs "github.com/TimeSnap/distributed-scheduler/internal/pkg/error"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+)
+
+// NewWebhookStore creates a new SQLite webhook store.
+func NewWebhookStore(db *sqlx.DB, log *otelzap.Logger, options ...Option) store.WebhookStorer {
+	return newStore(db, log, options...)
+}
+
+func (s *sqliteStore) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	ctx, cancel := s.withTimeout(ctx, "CreateWebhook")
+	defer cancel()
+
+	dbWebhook, err := toWebhookDB(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to convert webhook to db webhook: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhooks (id, namespace, team, url, secret, event_types, tags, selector, format, enabled, created_at, updated_at)
+		VALUES (:id, :namespace, :team, :url, :secret, :event_types, :tags, :selector, :format, :enabled, :created_at, :updated_at)
+	`
+	if _, err := s.q(ctx).NamedExecContext(ctx, query, dbWebhook); err != nil {
+		return fmt.Errorf("failed to insert webhook into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) GetWebhook(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetWebhook")
+	defer cancel()
+
+	var dbWebhook webhookDB
+	if err := s.q(ctx).GetContext(ctx, &dbWebhook, `SELECT * FROM webhooks WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook from database: %w", err)
+	}
+
+	webhook, err := dbWebhook.ToModel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db webhook to webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (s *sqliteStore) ListWebhooks(ctx context.Context, namespace, team string) ([]model.Webhook, error) {
+	ctx, cancel := s.withTimeout(ctx, "ListWebhooks")
+	defer cancel()
+
+	var dbWebhooks []webhookDB
+	query := `SELECT * FROM webhooks WHERE namespace = $1 AND ($2 = '' OR team = $2) ORDER BY created_at DESC, id DESC`
+	if err := s.q(ctx).SelectContext(ctx, &dbWebhooks, query, namespace, team); err != nil {
+		return nil, fmt.Errorf("failed to get webhooks from database: %w", err)
+	}
+
+	webhooks := []model.Webhook{}
+	for _, dbWebhook := range dbWebhooks {
+		webhook, err := dbWebhook.ToModel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db webhook to webhook: %w", err)
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, nil
+}
+
+func (s *sqliteStore) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	ctx, cancel := s.withTimeout(ctx, "DeleteWebhook")
+	defer cancel()
+
+	res, err := s.q(ctx).ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook from database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook from database: %w", err)
+	}
+
+	if affected == 0 {
+		return errs.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries creates a pending delivery of the event for every enabled webhook of its namespace subscribed to it,
+// except the webhooks of other teams than the one of the event. Deliveries are unique per webhook and event, so
+// enqueueing the same event more than once is a no-op.
+func (s *sqliteStore) EnqueueWebhookDeliveries(ctx context.Context, event model.Event) (int64, error) {
+	ctx, cancel := s.withTimeout(ctx, "EnqueueWebhookDeliveries")
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	// tag selectors are evaluated here rather than in the query
+	candidatesQuery := `
+		SELECT id, selector FROM webhooks
+		WHERE enabled
+		  AND (json_array_length(event_types) = 0 OR $1 IN (SELECT value FROM json_each(event_types)))
+		  AND NOT EXISTS (SELECT 1 FROM json_each(tags) WHERE value NOT IN (SELECT value FROM json_each($2)))
+		  AND namespace = $3
+		  AND (team IS NULL OR team = $4)
+	`
+	var candidates []struct {
+		ID       uuid.UUID `db:"id"`
+		Selector string    `db:"selector"`
+	}
+	tags := append(stringArray{}, event.Tags...)
+	if err := s.q(ctx).SelectContext(ctx, &candidates, candidatesQuery, string(event.Type), tags, event.Namespace, event.Team); err != nil {
+		return 0, fmt.Errorf("failed to get webhooks from database: %w", err)
+	}
+
+	webhookIDs := []uuid.UUID{}
+	for _, candidate := range candidates {
+		webhook := model.Webhook{Selector: candidate.Selector}
+		if webhook.MatchesSelector(event) {
+			webhookIDs = append(webhookIDs, candidate.ID)
+		}
+	}
+
+	if len(webhookIDs) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at)
+		SELECT id, $1, $2, $3, 'PENDING', now()
+		FROM webhooks
+		WHERE id IN (SELECT value FROM json_each($4))
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+	res, err := s.q(ctx).ExecContext(ctx, query, event.ID, string(event.Type), string(payload), uuidArray(webhookIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries in database: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// ClaimWebhookDeliveries returns the pending deliveries that are due at the given time and postpones their
+// next attempt until leaseUntil, so that other instances don't pick them up while they are being delivered.
+func (s *sqliteStore) ClaimWebhookDeliveries(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.WebhookDelivery, error) {
+	ctx, cancel := s.withTimeout(ctx, "ClaimWebhookDeliveries")
+	defer cancel()
+
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2, updated_at = now()
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+		)
+		RETURNING *
+	`
+
+	var dbDeliveries []webhookDeliveryDB
+	if err := s.q(ctx).SelectContext(ctx, &dbDeliveries, query, at, leaseUntil, limit); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries in database: %w", err)
+	}
+
+	deliveries := []model.WebhookDelivery{}
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, dbDelivery.ToModel())
+	}
+
+	return deliveries, nil
+}
+
+func (s *sqliteStore) FinishWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	ctx, cancel := s.withTimeout(ctx, "FinishWebhookDelivery")
+	defer cancel()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_code = $4, error = $5, next_attempt_at = $6, updated_at = now()
+		WHERE id = $1
+	`
+	_, err := s.q(ctx).ExecContext(ctx, query,
+		delivery.ID,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+		delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery in database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sqliteStore) GetWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, limit uint64, cursor *model.Cursor) ([]model.WebhookDelivery, error) {
+	ctx, cancel := s.withTimeout(ctx, "GetWebhookDeliveries")
+	defer cancel()
+
+	args := []interface{}{webhookID, limit}
+	extraFilter := ""
+
+	// keyset pagination on (created_at, id)
+	if cursor != nil {
+		id, err := strconv.Atoi(cursor.ID)
+		if err != nil {
+			return nil, errs.ErrInvalidCursor
+		}
+
+		args = append(args, cursor.Time, id)
+		extraFilter = fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE webhook_id = $1` + extraFilter +
+		` ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+
+	var dbDeliveries []webhookDeliveryDB
+	if err := s.q(ctx).SelectContext(ctx, &dbDeliveries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries from database: %w", err)
+	}
+
+	deliveries := []model.WebhookDelivery{}
+	for _, dbDelivery := range dbDeliveries {
+		deliveries = append(deliveries, dbDelivery.ToModel())
+	}
+
+	return deliveries, nil
+}
+
+func (s *sqliteStore) CountWebhookDeliveries(ctx context.Context, webhookID uuid.UUID) (uint64, error) {
+	ctx, cancel := s.withTimeout(ctx, "CountWebhookDeliveries")
+	defer cancel()
+
+	var count uint64
+	if err := s.q(ctx).GetContext(ctx, &count, `SELECT count(*) FROM webhook_deliveries WHERE webhook_id = $1`, webhookID); err != nil {
+		return 0, fmt.Errorf("failed to count webhook deliveries in database: %w", err)
+	}
+
+	return count, nil
+}
@@ -9,17 +9,246 @@ import (
 	"gopkg.in/guregu/null.v4"
 )
 
+type WebhookStorer interface {
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) error
+	GetWebhook(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	// ListWebhooks returns the webhooks of the namespace, only the ones of the team if set
+	ListWebhooks(ctx context.Context, namespace, team string) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+
+	// Delivery log
+	EnqueueWebhookDeliveries(ctx context.Context, event model.Event) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.WebhookDelivery, error)
+	FinishWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, limit uint64, cursor *model.Cursor) ([]model.WebhookDelivery, error)
+	CountWebhookDeliveries(ctx context.Context, webhookID uuid.UUID) (uint64, error)
+}
+
+type NotificationStorer interface {
+	CreateNotificationRule(ctx context.Context, rule *model.NotificationRule) error
+	GetNotificationRule(ctx context.Context, id uuid.UUID) (*model.NotificationRule, error)
+	// ListNotificationRules returns the notification rules of the namespace, only the ones of the team if set
+	ListNotificationRules(ctx context.Context, namespace, team string) ([]model.NotificationRule, error)
+	DeleteNotificationRule(ctx context.Context, id uuid.UUID) error
+	// GetEventNotificationRules returns the enabled rules notifying of the event, whatever their triggers, except the
+	// rules of other teams than the one of the event
+	GetEventNotificationRules(ctx context.Context, event model.Event) ([]model.NotificationRule, error)
+
+	// Throttling, GetNotificationState locks the state of the job until the end of the transaction
+	GetNotificationState(ctx context.Context, ruleID, jobID uuid.UUID) (*model.NotificationState, error)
+	SaveNotificationState(ctx context.Context, state *model.NotificationState) error
+
+	// Notification log
+	EnqueueNotification(ctx context.Context, notification *model.Notification) error
+	ClaimNotifications(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.Notification, error)
+	FinishNotification(ctx context.Context, notification model.Notification) error
+	GetNotifications(ctx context.Context, ruleID uuid.UUID, limit uint64, cursor *model.Cursor) ([]model.Notification, error)
+	CountNotifications(ctx context.Context, ruleID uuid.UUID) (uint64, error)
+}
+
+type APIKeyStorer interface {
+	CreateAPIKey(ctx context.Context, key *model.APIKey, keyHash string) error
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// GetAPIKeyByHash returns the active API key with the given hash and records its use.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*model.APIKey, error)
+}
+
+type InstanceStorer interface {
+	// SaveInstance registers the instance or updates its registration, which expires after the TTL.
+	SaveInstance(ctx context.Context, instance model.Instance, ttl time.Duration) error
+	DeleteInstance(ctx context.Context, id string) error
+	ListInstances(ctx context.Context) ([]model.Instance, error)
+	// DeleteExpiredInstances deletes the instances whose registration expired before the given time.
+	DeleteExpiredInstances(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DiagnosticsStorer reads the state of the scheduler the diagnostics look for problems in, across all namespaces.
+type DiagnosticsStorer interface {
+	// GetDueJobs counts the jobs due at the given time that no runner picked up yet, and returns the oldest due time.
+	GetDueJobs(ctx context.Context, at time.Time) (uint64, null.Time, error)
+	ListInstances(ctx context.Context) ([]model.Instance, error)
+	// GetStaleJobLocks returns up to limit locks of jobs which expired at the given time without being released, or
+	// which are held by runners that are no longer registered, oldest first.
+	GetStaleJobLocks(ctx context.Context, at time.Time, limit uint) ([]model.JobLock, error)
+	// ListJobSchedules returns up to limit jobs after the given ID, ordered by ID, with only their status and schedule.
+	ListJobSchedules(ctx context.Context, after uuid.UUID, limit uint) ([]model.Job, error)
+	// CheckJobCredentials decrypts the credentials of the limit most recently updated jobs storing some, and returns
+	// the number of jobs checked and the errors of those whose credentials couldn't be decrypted.
+	CheckJobCredentials(ctx context.Context, limit uint) (int, []model.JobCredentialsError, error)
+}
+
+// ExecutionPartitioner is implemented by the stores partitioning the job executions by month, whose partitions are
+// created ahead of time and dropped once their executions expired.
+type ExecutionPartitioner interface {
+	// CreateExecutionPartitions creates the missing partitions of the months from the one of from to the one of until,
+	// and returns their names.
+	CreateExecutionPartitions(ctx context.Context, from, until time.Time) ([]string, error)
+	// DropExecutionPartitions drops the partitions of the months ending before the given time, with the logs, outputs
+	// and timelines of their executions, and returns their names.
+	DropExecutionPartitions(ctx context.Context, before time.Time) ([]string, error)
+}
+
+// NamespaceKeyRotator is implemented by the stores supporting namespace keys, whose credentials are encrypted again
+// with the new key of their namespace in the background after a rotation.
+type NamespaceKeyRotator interface {
+	// ReencryptRotatedNamespaces encrypts the credentials of the namespaces whose key was rotated again with the new
+	// key, from where it stopped, and deletes the previous versions of their keys once no credential is encrypted with
+	// them anymore. It returns the namespaces whose rotation completed.
+	ReencryptRotatedNamespaces(ctx context.Context) ([]string, error)
+}
+
 type Storer interface {
 	// CRUD operations for jobs
 	CreateJob(ctx context.Context, job *model.Job) error
 	GetJob(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	// GetJobs returns the jobs of the namespace with the given IDs, in no particular order
+	GetJobs(ctx context.Context, namespace string, ids []uuid.UUID) ([]model.Job, error)
+	// GetJobByExternalID returns the job of the namespace with the given external ID, except archived jobs
+	GetJobByExternalID(ctx context.Context, namespace string, externalID string) (*model.Job, error)
 	DeleteJob(ctx context.Context, id uuid.UUID) error
-	ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error)
+	ListJobs(ctx context.Context, limit uint64, cursor *model.Cursor, filter model.JobFilter) ([]model.Job, error)
+	CountJobs(ctx context.Context, filter model.JobFilter) (uint64, error)
+	// EstimateJobs estimates the number of jobs matching the filter from the table statistics, without counting them
+	EstimateJobs(ctx context.Context, filter model.JobFilter) (uint64, error)
 	UpdateJob(ctx context.Context, job *model.Job) error
 
+	// Bulk operations, each executed in a single transaction
+	CreateJobs(ctx context.Context, jobs []*model.Job) error
+	UpdateJobs(ctx context.Context, jobs []*model.Job) error
+	SetJobsStatus(ctx context.Context, selector model.JobSelector, status model.JobStatus) ([]uuid.UUID, error)
+	DeleteJobs(ctx context.Context, selector model.JobSelector) ([]uuid.UUID, error)
+
+	// Manifests, identifying jobs by name
+	GetNamedJobs(ctx context.Context, namespace string) ([]model.Job, error)
+	ApplyJobs(ctx context.Context, created []*model.Job, updated []*model.Job, deleted []uuid.UUID) error
+
 	// Get jobs to run
-	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error)
+	// GetJobsToRun locks the jobs due by dueBy of the given namespaces and types (all of them if empty) whose required
+	// capabilities are among the given ones, within their quotas at time at, preferring the jobs of the shard. The
+	// locks, quotas and shard grace period are checked at time at, so jobs fetched ahead are never taken from the
+	// instances holding their lock.
+	GetJobsToRun(ctx context.Context, at time.Time, dueBy time.Time, lockedUntil time.Time, instanceID string, namespaces []string, jobTypes []string, capabilities []string, shard model.JobShard, limit uint) ([]*model.Job, error)
 	FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error
-	CreateJobExecution(ctx context.Context, jobID uuid.UUID, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error
-	GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error)
+	// ExtendJobLock extends the lock of a job being executed, as long as the instance still holds it
+	ExtendJobLock(ctx context.Context, jobID uuid.UUID, instanceID string, lockedUntil time.Time) error
+	// ReleaseJobLock unlocks a job locked by the instance without rescheduling it
+	ReleaseJobLock(ctx context.Context, jobID uuid.UUID, instanceID string) error
+	// GetDueJobs counts the jobs due at the given time that no runner picked up yet, and returns the oldest due time.
+	GetDueJobs(ctx context.Context, at time.Time) (uint64, null.Time, error)
+	// GetBacklog counts the due jobs, pending retries and running executions of all namespaces at the given time
+	GetBacklog(ctx context.Context, at time.Time) (*model.Backlog, error)
+	// GetMissedJobs returns up to limit jobs of all namespaces due before the given time that no runner picked up at
+	// the given time, most overdue first
+	GetMissedJobs(ctx context.Context, at, before time.Time, limit uint) ([]*model.Job, error)
+	// DisableFailingJob stops the running job if its last executions failed or timed out failures times in a row, and
+	// returns it, nil if it wasn't stopped
+	DisableFailingJob(ctx context.Context, jobID uuid.UUID, failures int) (*model.Job, error)
+	CreateJobExecution(ctx context.Context, jobID uuid.UUID, scheduledTime null.Time, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error
+	// CreateJobExecutions records finished executions of existing jobs, e.g. a generated history, created at their
+	// start time
+	CreateJobExecutions(ctx context.Context, executions []*model.JobExecution) error
+	StartJobExecution(ctx context.Context, jobID uuid.UUID, scheduledTime null.Time, startTime time.Time) (int, error)
+	FinishJobExecution(ctx context.Context, executionID int, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error
+	CancelJobExecution(ctx context.Context, namespace string, executionID int) error
+	IsJobExecutionCancelled(ctx context.Context, executionID int) (bool, error)
+	// GetCancelledJobExecutions returns the executions among the given ones whose cancellation was requested
+	GetCancelledJobExecutions(ctx context.Context, executionIDs []int) ([]int, error)
+	// CreatePendingJobExecution creates an execution of the job with its current definition, claimed like retries
+	CreatePendingJobExecution(ctx context.Context, jobID uuid.UUID) (*model.JobExecution, error)
+	// CreateOverriddenJobExecution creates an execution of the job with an overridden definition, claimed like retries
+	CreateOverriddenJobExecution(ctx context.Context, definition *model.Job) (*model.JobExecution, error)
+	// CreateBackfillJobExecutions creates a pending execution of the job for each of the scheduled times, oldest first
+	CreateBackfillJobExecutions(ctx context.Context, jobID uuid.UUID, scheduledTimes []time.Time) ([]*model.JobExecution, error)
+	// GetJobExecutionScheduledTimes returns the scheduled times of the executions of the job in [from, to)
+	GetJobExecutionScheduledTimes(ctx context.Context, jobID uuid.UUID, from, to time.Time) ([]time.Time, error)
+	// Retries of past executions, with either the job definition the execution ran with or the current one
+	RetryJobExecution(ctx context.Context, namespace string, executionID int, current bool) (*model.JobExecution, error)
+	ClaimJobExecutionRetries(ctx context.Context, at time.Time, namespaces []string, limit uint) ([]model.ExecutionRetry, error)
+	// ReleaseJobExecutionRetry returns a claimed retry that wasn't started to the pending retries
+	ReleaseJobExecutionRetry(ctx context.Context, executionID int) error
+	// ReapZombieJobExecutions fails the executions started before the given time whose runner stopped renewing
+	// the lock of the job before that time
+	ReapZombieJobExecutions(ctx context.Context, before time.Time, errorMessage string, limit uint) ([]model.ZombieExecution, error)
+	// RescheduleMisfiredJob moves the next run of a job from a missed occurrence, unless a runner locked it since
+	RescheduleMisfiredJob(ctx context.Context, jobID uuid.UUID, missedRun time.Time, nextRun null.Time) error
+	// UpdateJobExecutionProgress records the latest progress of a running execution
+	UpdateJobExecutionProgress(ctx context.Context, executionID int, progress model.ExecutionProgress) error
+	SaveJobExecutionLogs(ctx context.Context, logs model.ExecutionLogs) error
+	GetJobExecutionLogs(ctx context.Context, jobID uuid.UUID, executionID int) (*model.ExecutionLogs, error)
+	// Outputs of the executions, stored compressed apart from the executions
+	SaveJobExecutionOutput(ctx context.Context, output model.ExecutionOutput) error
+	GetJobExecutionOutput(ctx context.Context, jobID uuid.UUID, executionID int) (*model.ExecutionOutput, error)
+	// Timelines of the executions. Events of missing executions are dropped, the events of an execution of another
+	// namespace are not returned
+	AddJobExecutionEvents(ctx context.Context, events ...model.ExecutionEvent) error
+	GetJobExecutionEvents(ctx context.Context, namespace string, executionID int) ([]model.ExecutionEvent, error)
+	// GetJobExecutionJobID returns the ID of the job of an execution of the namespace
+	GetJobExecutionJobID(ctx context.Context, namespace string, executionID int) (uuid.UUID, error)
+	GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit uint64, cursor *model.Cursor) ([]*model.JobExecution, error)
+	// ExportJobExecutions calls fn with every execution of the job started within the range, oldest first
+	ExportJobExecutions(ctx context.Context, jobID uuid.UUID, r model.ExecutionExportRange, fn func(*model.JobExecution) error) error
+	GetLatestJobExecutions(ctx context.Context, jobIDs []uuid.UUID, limit uint64) ([]*model.JobExecution, error)
+	GetJobExecutionStats(ctx context.Context, jobIDs []uuid.UUID) ([]model.JobExecutionStats, error)
+	// GetSLAJobs returns up to limit jobs of all namespaces with an SLA, with an ID after the given one, by ID
+	GetSLAJobs(ctx context.Context, after uuid.UUID, limit uint) ([]*model.Job, error)
+	// GetJobSLAStatuses aggregates the executions started within the window of the SLA at the given time of each of
+	// the jobs with an SLA. Jobs without an SLA are omitted.
+	GetJobSLAStatuses(ctx context.Context, at time.Time, jobIDs []uuid.UUID) ([]model.JobSLAStatus, error)
+	CountJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool) (uint64, error)
+	// EstimateJobExecutions estimates the number of executions of the job from the table statistics, without counting
+	// them
+	EstimateJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool) (uint64, error)
+
+	// InTx runs fn in a transaction, committed if fn returns nil and rolled back otherwise. The operations called
+	// with the context passed to fn run in the transaction, nested calls join it.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// Job lifecycle events
+	// PublishEvent writes the event to the outbox, from which it is relayed once the transaction of the context
+	// is committed
+	PublishEvent(ctx context.Context, event model.Event) error
+	// RelayEvents passes up to limit events of the outbox, oldest first, to fn and removes them from the outbox
+	// if it returns nil. fn runs in the transaction removing the events, which are broadcast to the listeners
+	// once it is committed.
+	RelayEvents(ctx context.Context, limit uint, fn func(ctx context.Context, events []model.Event) error) (int, error)
+	ListenEvents(ctx context.Context, handler func(model.Event)) error
+
+	// Wakeups of the runners for jobs due soon
+	NotifyJobWakeup(ctx context.Context, wakeup model.JobWakeup) error
+	ListenJobWakeups(ctx context.Context, handler func(model.JobWakeup)) error
+
+	// Cancellations of running executions, delivered to the runners executing them
+	NotifyJobCancellation(ctx context.Context, cancellation model.JobCancellation) error
+	ListenJobCancellations(ctx context.Context, handler func(model.JobCancellation)) error
+
+	// Tags of the jobs of a namespace, ListTags only counting the jobs of the team unless it is empty
+	ListTags(ctx context.Context, namespace string, team string) ([]model.TagCount, error)
+	RenameTag(ctx context.Context, namespace string, from, to string) ([]uuid.UUID, error)
+
+	// Job templates, identified by name within their namespace
+	CreateTemplate(ctx context.Context, template *model.JobTemplate) error
+	GetTemplate(ctx context.Context, namespace, name string) (*model.JobTemplate, error)
+	ListTemplates(ctx context.Context, namespace string) ([]model.JobTemplate, error)
+	UpdateTemplate(ctx context.Context, template *model.JobTemplate) error
+	DeleteTemplate(ctx context.Context, namespace, name string) error
+
+	// Namespaces and their quotas
+	GetNamespace(ctx context.Context, name string) (*model.Namespace, error)
+	ListNamespaces(ctx context.Context) ([]model.Namespace, error)
+	SetNamespaceQuotas(ctx context.Context, name string, quotas model.NamespaceQuotas) (*model.Namespace, error)
+	// ListNamespaceKeys returns the versions of the data key of the namespace, the active one first
+	ListNamespaceKeys(ctx context.Context, namespace string) ([]model.NamespaceKey, error)
+	// RotateNamespaceKey creates a new version of the data key of the namespace, see NamespaceKeyRotator
+	RotateNamespaceKey(ctx context.Context, namespace string) (*model.NamespaceKey, error)
+
+	// Retention of completed one-off jobs with a TTL
+	ArchiveExpiredJobs(ctx context.Context, at time.Time) (int64, error)
+	DeleteExpiredJobs(ctx context.Context, at time.Time) (int64, error)
+
+	// Retention of finished executions
+	// GetExpiredJobExecutions returns up to limit finished executions, oldest first, expired by age or by count
+	GetExpiredJobExecutions(ctx context.Context, expiry model.ExecutionExpiry, limit uint) ([]*model.JobExecution, error)
+	// DeleteJobExecutions deletes the finished executions among the given ones along with their logs, outputs and timelines
+	DeleteJobExecutions(ctx context.Context, executionIDs []int) (int64, error)
 }
@@ -0,0 +1,127 @@
+package sweeper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+// Mode determines what happens with jobs whose TTL has elapsed.
+type Mode string
+
+const (
+	ModeArchive Mode = "archive"
+	ModeDelete  Mode = "delete"
+)
+
+// Sweeper periodically archives or deletes completed one-off jobs whose TTL has elapsed.
+type Sweeper struct {
+	jobService JobService
+	log        *otelzap.Logger
+	mode       Mode
+	task       *periodic.Task
+}
+
+type JobService interface {
+	ArchiveExpiredJobs(ctx context.Context, at time.Time) (int64, error)
+	DeleteExpiredJobs(ctx context.Context, at time.Time) (int64, error)
+}
+
+type Config struct {
+	JobService JobService
+	// Leader restricts the sweeps to the leader instance, every instance sweeps if nil
+	Leader   leader.Leader
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	Mode     Mode          `mapstructure:"mode" yaml:"mode" json:"mode,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive and the mode archive or delete, archive if empty.
+func (s Settings) Validate() error {
+	if err := periodic.ValidateInterval(s.Interval); err != nil {
+		return err
+	}
+
+	switch s.Mode {
+	case "", ModeArchive, ModeDelete:
+		return nil
+	default:
+		return fmt.Errorf("invalid mode %q, must be either archive or delete", s.Mode)
+	}
+}
+
+func New(cfg Config) (*Sweeper, error) {
+	if err := cfg.Settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	mode := cfg.Settings.Mode
+	if mode == "" {
+		mode = ModeArchive
+	}
+
+	s := &Sweeper{
+		jobService: cfg.JobService,
+		log:        cfg.Log,
+		mode:       mode,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "job retention sweeper",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      s.sweep,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.task = task
+
+	return s, nil
+}
+
+// Start starts the sweeper in a separate goroutine.
+// Only the first call will start the sweeper, subsequent calls are ignored.
+func (s *Sweeper) Start() {
+	s.task.Start()
+}
+
+// Stop stops the sweeper and waits for the current sweep to finish or the context to expire.
+func (s *Sweeper) Stop(ctx context.Context) {
+	s.task.Stop(ctx)
+}
+
+func (s *Sweeper) sweep(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	var (
+		affected int64
+		err      error
+	)
+
+	switch s.mode {
+	case ModeDelete:
+		affected, err = s.jobService.DeleteExpiredJobs(ctx, time.Now())
+	default:
+		affected, err = s.jobService.ArchiveExpiredJobs(ctx, time.Now())
+	}
+
+	if err != nil {
+		s.log.Error("Failed to sweep expired jobs", zap.Error(err))
+		return
+	}
+
+	s.log.Debug("Swept expired jobs", zap.String("mode", string(s.mode)), zap.Int64("count", affected))
+}
@@ -0,0 +1,112 @@
+package sweeper
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockJobService struct {
+	sync.Mutex
+	archived int
+	deleted  int
+}
+
+func (m *mockJobService) ArchiveExpiredJobs(_ context.Context, _ time.Time) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.archived++
+	return 1, nil
+}
+
+func (m *mockJobService) DeleteExpiredJobs(_ context.Context, _ time.Time) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.deleted++
+	return 1, nil
+}
+
+func createSweeper(t *testing.T, mode Mode) (*Sweeper, *mockJobService) {
+	jobService := &mockJobService{}
+	zapL, _ := zap.NewDevelopment()
+
+	s, err := New(Config{
+		JobService: jobService,
+		Log:        otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:  true,
+			Interval: time.Millisecond * 20,
+			Mode:     mode,
+		},
+	})
+	assert.NoError(t, err)
+
+	return s, jobService
+}
+
+func TestSweeper(t *testing.T) {
+	t.Run("Archive mode", func(t *testing.T) {
+		s, jobService := createSweeper(t, ModeArchive)
+		s.sweep(context.Background())
+
+		assert.Equal(t, 1, jobService.archived)
+		assert.Equal(t, 0, jobService.deleted)
+	})
+
+	t.Run("Delete mode", func(t *testing.T) {
+		s, jobService := createSweeper(t, ModeDelete)
+		s.sweep(context.Background())
+
+		assert.Equal(t, 1, jobService.deleted)
+		assert.Equal(t, 0, jobService.archived)
+	})
+
+	t.Run("Only the leader sweeps", func(t *testing.T) {
+		jobService := &mockJobService{}
+		s, err := New(Config{
+			JobService: jobService,
+			Leader:     leader.Static(false),
+			Log:        otelzap.New(zap.NewNop()),
+			Settings:   Settings{Enabled: true, Interval: time.Millisecond * 20, Mode: ModeArchive},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		s.Start()
+		time.Sleep(time.Millisecond * 100)
+		s.Stop(context.Background())
+
+		assert.Equal(t, 0, jobService.archived)
+	})
+
+	t.Run("Sweeps on every tick until stopped", func(t *testing.T) {
+		s, jobService := createSweeper(t, ModeArchive)
+		s.Start()
+
+		assert.Eventually(t, func() bool {
+			jobService.Lock()
+			defer jobService.Unlock()
+			return jobService.archived > 0
+		}, time.Second, time.Millisecond*10)
+		s.Stop(context.Background())
+	})
+
+	t.Run("Empty mode defaults to archive", func(t *testing.T) {
+		s, _ := createSweeper(t, "")
+		assert.Equal(t, ModeArchive, s.mode)
+	})
+
+	t.Run("Invalid settings are rejected", func(t *testing.T) {
+		_, err := New(Config{Settings: Settings{Enabled: true, Interval: time.Minute, Mode: "unknown"}})
+		assert.Error(t, err)
+
+		_, err = New(Config{Settings: Settings{Enabled: true, Mode: ModeDelete}})
+		assert.Error(t, err)
+	})
+}
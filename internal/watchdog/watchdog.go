@@ -0,0 +1,138 @@
+package watchdog
+
+import (
+	"context"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// missedJobsLimit limits the number of missed jobs checked at once.
+const missedJobsLimit = 1000
+
+// Watchdog periodically looks for the jobs that are overdue by more than the threshold without being executed,
+// e.g. because the runners are down or can't keep up, and reports each missed run once, as a job.missed event
+// and a metric. It acts as a dead man's switch of the scheduler.
+type Watchdog struct {
+	jobService JobService
+	metrics    *metrics.WatchdogMetrics
+	log        *otelzap.Logger
+	threshold  time.Duration
+	task       *periodic.Task
+
+	// reported holds the next run of the missed jobs already reported, so each missed run is only reported once
+	reported map[uuid.UUID]time.Time
+}
+
+type JobService interface {
+	GetMissedJobs(ctx context.Context, at time.Time, threshold time.Duration, limit uint) ([]*model.Job, error)
+	ReportMissedJobs(ctx context.Context, jobs ...*model.Job) error
+}
+
+type Config struct {
+	JobService JobService
+	// Leader restricts the checks to the leader instance, every instance checks if nil
+	Leader   leader.Leader
+	Metrics  *metrics.WatchdogMetrics
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// Threshold is how long past its next run a job that wasn't executed is considered missed
+	Threshold time.Duration `mapstructure:"threshold" yaml:"threshold" json:"threshold,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Watchdog, error) {
+	w := &Watchdog{
+		jobService: cfg.JobService,
+		metrics:    cfg.Metrics,
+		log:        cfg.Log,
+		threshold:  cfg.Settings.Threshold,
+		reported:   map[uuid.UUID]time.Time{},
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "missed run watchdog",
+		Interval: cfg.Settings.Interval,
+		Leader:   cfg.Leader,
+		Log:      cfg.Log,
+		Run:      w.check,
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.task = task
+
+	return w, nil
+}
+
+// Start starts the watchdog in a separate goroutine.
+// Only the first call will start the watchdog, subsequent calls are ignored.
+func (w *Watchdog) Start() {
+	w.task.Start()
+}
+
+// Stop stops the watchdog and waits for the current check to finish or the context to expire.
+func (w *Watchdog) Stop(ctx context.Context) {
+	w.task.Stop(ctx)
+}
+
+func (w *Watchdog) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	jobs, err := w.jobService.GetMissedJobs(ctx, time.Now(), w.threshold, missedJobsLimit)
+	if err != nil {
+		w.log.Error("Failed to get missed jobs", zap.Error(err))
+		return
+	}
+
+	w.metrics.RecordMissedJobs(ctx, len(jobs))
+
+	// only the runs missed since the previous check are reported, the jobs that were executed since are forgotten
+	reported := make(map[uuid.UUID]time.Time, len(jobs))
+	var missed []*model.Job
+	for _, job := range jobs {
+		nextRun := job.NextRun.ValueOrZero()
+		if previous, ok := w.reported[job.ID]; !ok || !previous.Equal(nextRun) {
+			missed = append(missed, job)
+		}
+		reported[job.ID] = nextRun
+	}
+
+	if len(missed) == 0 {
+		w.reported = reported
+		return
+	}
+
+	if err := w.jobService.ReportMissedJobs(ctx, missed...); err != nil {
+		// the missed runs are reported again on the next check
+		w.log.Error("Failed to report missed jobs", zap.Error(err))
+		return
+	}
+	w.reported = reported
+
+	for _, job := range missed {
+		w.metrics.IncreaseMissedRunCount(ctx,
+			attribute.String("job_type", string(job.Type)),
+			attribute.String("namespace", job.Namespace),
+		)
+	}
+
+	w.log.Warn("Reported missed job runs", zap.Int("count", len(missed)))
+}
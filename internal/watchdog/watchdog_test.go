@@ -0,0 +1,124 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/leader"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+type mockJobService struct {
+	sync.Mutex
+	missed    []*model.Job
+	reportErr error
+	checks    int
+	reported  []*model.Job
+}
+
+func (m *mockJobService) GetMissedJobs(_ context.Context, _ time.Time, _ time.Duration, _ uint) ([]*model.Job, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.checks++
+
+	return m.missed, nil
+}
+
+func (m *mockJobService) ReportMissedJobs(_ context.Context, jobs ...*model.Job) error {
+	m.Lock()
+	defer m.Unlock()
+	if m.reportErr != nil {
+		return m.reportErr
+	}
+
+	m.reported = append(m.reported, jobs...)
+	return nil
+}
+
+func createWatchdog(t *testing.T, jobService *mockJobService) *Watchdog {
+	zapL, _ := zap.NewDevelopment()
+
+	w, err := New(Config{
+		JobService: jobService,
+		Metrics:    metrics.NewWatchdogMetrics(observability.MetricsConfig{}),
+		Log:        otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:   true,
+			Interval:  time.Millisecond * 20,
+			Threshold: time.Minute,
+		},
+	})
+	assert.NoError(t, err)
+
+	return w
+}
+
+func missedJob(nextRun time.Time) *model.Job {
+	return &model.Job{ID: uuid.New(), Type: model.JobTypeHTTP, NextRun: null.TimeFrom(nextRun)}
+}
+
+func TestWatchdog(t *testing.T) {
+	t.Run("Reports each missed run once", func(t *testing.T) {
+		job := missedJob(time.Now().Add(-time.Hour))
+		jobService := &mockJobService{missed: []*model.Job{job}}
+
+		w := createWatchdog(t, jobService)
+		w.check(context.Background())
+		w.check(context.Background())
+		assert.Equal(t, []*model.Job{job}, jobService.reported)
+
+		// the next run of the job is missed too
+		rescheduled := *job
+		rescheduled.NextRun = null.TimeFrom(time.Now().Add(-time.Minute * 2))
+		jobService.missed = []*model.Job{&rescheduled}
+		w.check(context.Background())
+		assert.Equal(t, []*model.Job{job, &rescheduled}, jobService.reported)
+	})
+
+	t.Run("Reports the missed runs again after a failed report", func(t *testing.T) {
+		job := missedJob(time.Now().Add(-time.Hour))
+		jobService := &mockJobService{missed: []*model.Job{job}, reportErr: errors.New("database down")}
+
+		w := createWatchdog(t, jobService)
+		w.check(context.Background())
+		assert.Empty(t, jobService.reported)
+
+		jobService.reportErr = nil
+		w.check(context.Background())
+		assert.Equal(t, []*model.Job{job}, jobService.reported)
+	})
+
+	t.Run("Only the leader checks", func(t *testing.T) {
+		jobService := &mockJobService{}
+		w, err := New(Config{
+			JobService: jobService,
+			Leader:     leader.Static(false),
+			Metrics:    metrics.NewWatchdogMetrics(observability.MetricsConfig{}),
+			Log:        otelzap.New(zap.NewNop()),
+			Settings:   Settings{Enabled: true, Interval: time.Millisecond * 20, Threshold: time.Minute},
+		})
+		assert.NoError(t, err)
+
+		// the ticks are skipped by the instances which are not the leader
+		w.Start()
+		time.Sleep(time.Millisecond * 100)
+		w.Stop(context.Background())
+
+		assert.Equal(t, 0, jobService.checks)
+	})
+}
+
+func TestNewRejectsNonPositiveInterval(t *testing.T) {
+	_, err := New(Config{Settings: Settings{Enabled: true, Threshold: time.Minute}})
+	assert.Error(t, err)
+}
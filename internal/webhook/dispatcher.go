@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/periodic"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/retry"
+	"github.com/google/uuid"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	// maxErrorLength limits the size of the response body stored with a failed attempt.
+	maxErrorLength = 1024
+)
+
+// Dispatcher enqueues a delivery for every webhook subscribed to a job lifecycle event relayed from the outbox
+// and delivers them, retrying failed deliveries with an exponential backoff.
+type Dispatcher struct {
+	webhookService WebhookService
+	log            *otelzap.Logger
+	client         *http.Client
+	maxAttempts    int
+	interval       time.Duration
+	cloudEvents    CloudEventsSettings
+	task           *periodic.Task
+}
+
+type WebhookService interface {
+	GetWebhook(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	EnqueueDeliveries(ctx context.Context, event model.Event) (int64, error)
+	ClaimDeliveries(ctx context.Context, at time.Time, leaseUntil time.Time, limit uint) ([]model.WebhookDelivery, error)
+	FinishDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+}
+
+type Config struct {
+	WebhookService WebhookService
+	// Client delivers the webhooks, e.g. a client enforcing the egress policy of the manager. A client with the
+	// timeout of the settings is used if nil.
+	Client   *http.Client
+	Log      *otelzap.Logger
+	Settings Settings
+}
+
+type Settings struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled" json:"enabled"`
+	// Interval between checks for due deliveries
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" json:"interval,omitempty"`
+	// Number of attempts after which a delivery is marked as failed
+	MaxAttempts int `mapstructure:"maxAttempts" yaml:"maxAttempts" json:"maxAttempts,omitempty"`
+	// Timeout of a single delivery request
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout" json:"timeout,omitempty"`
+	// CloudEvents configure the payloads of the webhooks in the cloudevents format
+	CloudEvents CloudEventsSettings `mapstructure:"cloudEvents" yaml:"cloudEvents" json:"cloudEvents"`
+}
+
+// CloudEventsSettings configure the attributes of the CloudEvents delivered to the webhooks.
+type CloudEventsSettings struct {
+	// Source of the CloudEvents, identifying the scheduler deployment, e.g. /scheduler/prod
+	Source string `mapstructure:"source" yaml:"source" json:"source,omitempty"`
+	// TypePrefix is prepended to the types of the events to form the types of the CloudEvents
+	TypePrefix string `mapstructure:"typePrefix" yaml:"typePrefix" json:"typePrefix,omitempty"`
+}
+
+// Validate validates the settings: the interval must be positive.
+func (s Settings) Validate() error {
+	return periodic.ValidateInterval(s.Interval)
+}
+
+func New(cfg Config) (*Dispatcher, error) {
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Settings.Timeout}
+	}
+
+	d := &Dispatcher{
+		webhookService: cfg.WebhookService,
+		log:            cfg.Log,
+		client:         client,
+		maxAttempts:    max(cfg.Settings.MaxAttempts, 1),
+		interval:       cfg.Settings.Interval,
+		cloudEvents:    cfg.Settings.CloudEvents,
+	}
+
+	task, err := periodic.New(periodic.Config{
+		Name:     "webhook dispatcher",
+		Interval: cfg.Settings.Interval,
+		Log:      cfg.Log,
+		Run:      d.deliverDue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.task = task
+
+	return d, nil
+}
+
+// Start starts delivering the enqueued events in a separate goroutine.
+// Only the first call will start the dispatcher, subsequent calls are ignored.
+func (d *Dispatcher) Start() {
+	d.task.Start()
+}
+
+// Stop stops the dispatcher and waits for the current deliveries to finish or the context to expire.
+func (d *Dispatcher) Stop(ctx context.Context) {
+	d.task.Stop(ctx)
+}
+
+// Enqueue enqueues a delivery of the event for every webhook subscribed to it. It is the sink of the outbox relay,
+// so the deliveries are enqueued in the transaction removing the event from the outbox.
+func (d *Dispatcher) Enqueue(ctx context.Context, event model.Event) error {
+	count, err := d.webhookService.EnqueueDeliveries(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries of event %s: %w", event.ID, err)
+	}
+
+	d.log.Debug("Enqueued webhook deliveries", zap.Any("event_id", event.ID), zap.Int64("count", count))
+	return nil
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	deliver := func(delivery model.WebhookDelivery) { d.deliver(ctx, delivery) }
+	if err := retry.AttemptDue(ctx, d.client.Timeout, d.interval, d.webhookService.ClaimDeliveries, deliver); err != nil {
+		d.log.Error("Failed to claim webhook deliveries", zap.Error(err))
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) {
+	webhook, err := d.webhookService.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		d.log.Error("Failed to get webhook", zap.Any("webhook_id", delivery.WebhookID), zap.Error(err))
+		return
+	}
+
+	delivery.Attempts++
+	code, err := d.send(ctx, webhook, delivery)
+
+	result := retry.NewResult(delivery.Attempts, d.maxAttempts, err)
+	delivery.ResponseCode = null.NewInt(int64(code), code != 0)
+	delivery.Error = result.Error
+	delivery.NextAttemptAt = result.NextAttemptAt
+
+	switch result.Outcome {
+	case retry.Succeeded:
+		delivery.Status = model.WebhookDeliveryStatusSucceeded
+	case retry.Failed:
+		delivery.Status = model.WebhookDeliveryStatusFailed
+	}
+
+	if err != nil {
+		d.log.Warn("Failed to deliver webhook", zap.Any("webhook_id", webhook.ID), zap.Int("delivery_id", delivery.ID),
+			zap.Int("attempt", delivery.Attempts), zap.Error(err))
+	}
+
+	// record the outcome even if the dispatcher is stopping
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	if err := d.webhookService.FinishDelivery(ctx, delivery); err != nil {
+		d.log.Error("Failed to update webhook delivery", zap.Int("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// send posts the event to the webhook and returns the response status code.
+func (d *Dispatcher) send(ctx context.Context, webhook *model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	body, contentType, err := d.encode(webhook, delivery)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// encode returns the body of the delivery in the format of the webhook, and its content type. The deliveries store the
+// events as they are, so the format of a webhook can change while its deliveries are pending.
+func (d *Dispatcher) encode(webhook *model.Webhook, delivery model.WebhookDelivery) ([]byte, string, error) {
+	if webhook.Format != model.WebhookFormatCloudEvents {
+		return delivery.Payload, "application/json", nil
+	}
+
+	var event model.Event
+	if err := json.Unmarshal(delivery.Payload, &event); err != nil {
+		return nil, "", fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	body, err := json.Marshal(model.NewCloudEvent(event, d.cloudEvents.Source, d.cloudEvents.TypePrefix))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode CloudEvent: %w", err)
+	}
+
+	return body, model.CloudEventContentType, nil
+}
+
+// Sign returns the signature of a delivery: the hex encoded HMAC-SHA256 of the timestamp and the body,
+// joined by a dot, keyed with the webhook secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type mockWebhookService struct {
+	sync.Mutex
+	webhook    *model.Webhook
+	deliveries map[int]model.WebhookDelivery
+	lastID     int
+}
+
+func (m *mockWebhookService) GetWebhook(_ context.Context, _ uuid.UUID) (*model.Webhook, error) {
+	return m.webhook, nil
+}
+
+func (m *mockWebhookService) EnqueueDeliveries(_ context.Context, event model.Event) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	payload, _ := json.Marshal(event)
+	m.lastID++
+	m.deliveries[m.lastID] = model.WebhookDelivery{
+		ID:        m.lastID,
+		WebhookID: m.webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   payload,
+		Status:    model.WebhookDeliveryStatusPending,
+	}
+
+	return 1, nil
+}
+
+func (m *mockWebhookService) ClaimDeliveries(_ context.Context, at time.Time, _ time.Time, _ uint) ([]model.WebhookDelivery, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	var due []model.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == model.WebhookDeliveryStatusPending && !delivery.NextAttemptAt.Time.After(at) {
+			due = append(due, delivery)
+		}
+	}
+
+	return due, nil
+}
+
+func (m *mockWebhookService) FinishDelivery(_ context.Context, delivery model.WebhookDelivery) error {
+	m.Lock()
+	defer m.Unlock()
+
+	// retry immediately in tests
+	delivery.NextAttemptAt.Time = time.Time{}
+	m.deliveries[delivery.ID] = delivery
+
+	return nil
+}
+
+func createDispatcher(t *testing.T, url string, maxAttempts int) (*Dispatcher, *mockWebhookService) {
+	webhookService := &mockWebhookService{
+		webhook: &model.Webhook{
+			ID:     uuid.New(),
+			URL:    url,
+			Secret: "0123456789abcdef",
+		},
+		deliveries: map[int]model.WebhookDelivery{},
+	}
+	zapL, _ := zap.NewDevelopment()
+
+	d, err := New(Config{
+		WebhookService: webhookService,
+		Log:            otelzap.New(zapL),
+		Settings: Settings{
+			Enabled:     true,
+			Interval:    time.Millisecond * 20,
+			MaxAttempts: maxAttempts,
+			Timeout:     time.Second,
+		},
+	})
+	require.NoError(t, err)
+
+	return d, webhookService
+}
+
+func TestDispatcher(t *testing.T) {
+	event := model.Event{ID: uuid.New(), Type: model.EventJobCreated, Time: time.Now(), JobID: uuid.New()}
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			headers http.Header
+			body    []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			headers = r.Header.Clone()
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		d, webhookService := createDispatcher(t, server.URL, 3)
+		d.Start()
+		require.NoError(t, d.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			webhookService.Lock()
+			defer webhookService.Unlock()
+			return webhookService.deliveries[1].Status == model.WebhookDeliveryStatusSucceeded
+		}, time.Second, time.Millisecond*10)
+		d.Stop(context.Background())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, string(model.EventJobCreated), headers.Get(EventHeader))
+		assert.Equal(t, "1", headers.Get(DeliveryHeader))
+		assert.Equal(t, Sign("0123456789abcdef", headers.Get(TimestampHeader), body), headers.Get(SignatureHeader))
+
+		var received model.Event
+		require.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, event.ID, received.ID)
+	})
+
+	t.Run("CloudEvents delivery", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			headers http.Header
+			body    []byte
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			headers = r.Header.Clone()
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer server.Close()
+
+		d, webhookService := createDispatcher(t, server.URL, 3)
+		d.cloudEvents = CloudEventsSettings{Source: "/scheduler/prod", TypePrefix: "scheduler."}
+		webhookService.webhook.Format = model.WebhookFormatCloudEvents
+		d.Start()
+		require.NoError(t, d.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			webhookService.Lock()
+			defer webhookService.Unlock()
+			return webhookService.deliveries[1].Status == model.WebhookDeliveryStatusSucceeded
+		}, time.Second, time.Millisecond*10)
+		d.Stop(context.Background())
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, model.CloudEventContentType, headers.Get("Content-Type"))
+		assert.Equal(t, Sign("0123456789abcdef", headers.Get(TimestampHeader), body), headers.Get(SignatureHeader))
+
+		var received model.CloudEvent
+		require.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, "1.0", received.SpecVersion)
+		assert.Equal(t, event.ID.String(), received.ID)
+		assert.Equal(t, "/scheduler/prod", received.Source)
+		assert.Equal(t, "scheduler."+string(model.EventJobCreated), received.Type)
+		assert.Equal(t, event.JobID, received.Data.JobID)
+	})
+
+	t.Run("Failed delivery", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		d, webhookService := createDispatcher(t, server.URL, 2)
+		d.Start()
+		require.NoError(t, d.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			webhookService.Lock()
+			defer webhookService.Unlock()
+			return webhookService.deliveries[1].Status == model.WebhookDeliveryStatusFailed
+		}, time.Second, time.Millisecond*10)
+		d.Stop(context.Background())
+
+		webhookService.Lock()
+		defer webhookService.Unlock()
+		delivery := webhookService.deliveries[1]
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, int64(http.StatusInternalServerError), delivery.ResponseCode.Int64)
+		assert.True(t, delivery.Error.Valid)
+	})
+
+	t.Run("Destination denied by the egress policy", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+		}))
+		defer server.Close()
+
+		d, webhookService := createDispatcher(t, server.URL, 1)
+		client, err := egress.NewClient(egress.Settings{Enabled: true}, time.Second)
+		require.NoError(t, err)
+		d.client = client
+		d.Start()
+		require.NoError(t, d.Enqueue(context.Background(), event))
+
+		require.Eventually(t, func() bool {
+			webhookService.Lock()
+			defer webhookService.Unlock()
+			return webhookService.deliveries[1].Status == model.WebhookDeliveryStatusFailed
+		}, time.Second, time.Millisecond*10)
+		d.Stop(context.Background())
+
+		webhookService.Lock()
+		defer webhookService.Unlock()
+		assert.Contains(t, webhookService.deliveries[1].Error.String, egress.ErrDenied.Error())
+		assert.Zero(t, requests.Load())
+	})
+	t.Run("Zero interval", func(t *testing.T) {
+		_, err := New(Config{
+			WebhookService: &mockWebhookService{},
+			Log:            otelzap.New(zap.NewNop()),
+			Settings:       Settings{Enabled: true, MaxAttempts: 3, Timeout: time.Second},
+		})
+		assert.Error(t, err)
+	})
+}
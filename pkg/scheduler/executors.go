@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+)
+
+// ExecutorFunc adapts a function to an Executor.
+type ExecutorFunc func(ctx context.Context, job *Job) error
+
+func (f ExecutorFunc) Execute(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// executorFactory creates the executors of the embedding service for their job types, and the built-in
+// executors for the other job types.
+type executorFactory struct {
+	builtin   executor.Factory
+	executors map[JobType]Executor
+}
+
+func newExecutorFactory(builtin executor.Factory, executors map[JobType]Executor) executor.Factory {
+	return &executorFactory{builtin: builtin, executors: executors}
+}
+
+func (f *executorFactory) NewExecutor(job *model.Job, options ...executor.Option) (executor.Executor, error) {
+	exec, ok := f.executors[job.Type]
+	if !ok {
+		return f.builtin.NewExecutor(job, options...)
+	}
+
+	for _, option := range options {
+		exec = option(exec)
+	}
+
+	return exec, nil
+}
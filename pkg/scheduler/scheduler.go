@@ -0,0 +1,326 @@
+// Package scheduler embeds the scheduler in a Go service: the job service and the runner run in-process on the
+// database handle of the service, instead of being deployed as separate manager and runner binaries.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/logsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/outbox"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/database/dbmigrate"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/metrics"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/security"
+	"github.com/TimeSnap/distributed-scheduler/internal/resultsink"
+	"github.com/TimeSnap/distributed-scheduler/internal/runner"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/instance"
+	"github.com/TimeSnap/distributed-scheduler/internal/service/job"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/codec"
+	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	_ "github.com/TimeSnap/distributed-scheduler/internal/store/sqlite"
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/xBlaz3kx/DevX/observability"
+	"go.uber.org/zap"
+)
+
+type (
+	Job               = model.Job
+	JobCreate         = model.JobCreate
+	JobUpdate         = model.JobUpdate
+	JobType           = model.JobType
+	JobExecution      = model.JobExecution
+	ExecutionProgress = model.ExecutionProgress
+	HTTPJob           = model.HTTPJob
+	AMQPJob           = model.AMQPJob
+	JobService        = job.Service
+	Executor          = executor.Executor
+	RunnerSettings    = runner.JobExecutionSettings
+
+	// ExecutorConstructor creates the executor of a job of a custom type.
+	ExecutorConstructor = executor.Constructor
+	// Middleware wraps the executors to add a cross-cutting behavior to the executions.
+	Middleware         = executor.Middleware
+	MiddlewareSettings = executor.MiddlewareSettings
+)
+
+const (
+	JobTypeHTTP = model.JobTypeHTTP
+	JobTypeAMQP = model.JobTypeAMQP
+)
+
+// DefaultRunnerSettings are the intervals and limits of the runner binary, used for the unset ones of Config.Runner.
+var DefaultRunnerSettings = RunnerSettings{
+	Interval:                 10 * time.Second,
+	MaxConcurrentJobs:        100,
+	MaxJobLockTime:           time.Minute,
+	CancellationPollInterval: 5 * time.Second,
+	MaxExecutionLogSize:      64 * 1024,
+	MaxExecutionOutputSize:   1024 * 1024,
+	MaxLabelledJobs:          1000,
+	HeartbeatInterval:        10 * time.Second,
+	ShutdownGracePeriod:      20 * time.Second,
+}
+
+type Config struct {
+	// DB is the handle of the Postgres database of the scheduler. It is owned by the caller and not closed by the scheduler.
+	DB *sql.DB
+	// Store is the storage backend of the scheduler, e.g. opened with store.Open from the "sqlite" driver or a
+	// third-party one, used instead of the Postgres database. It is owned by the caller and not closed by the scheduler.
+	Store *store.Backend
+	// EncryptionKey encrypts the credentials of the jobs stored by the Postgres and SQLite backends at rest, and must
+	// be 16, 24 or 32 bytes long. It is required with DB. The key is shared by all the schedulers of the process.
+	EncryptionKey string
+	// Logger of the scheduler, logging is disabled if nil.
+	Logger *zap.Logger
+	// Migrate applies the migrations of the Postgres database when the scheduler is created.
+	Migrate bool
+	// InstanceID identifies the runner in job locks and the instance registry, generated if empty.
+	InstanceID string
+	// Version of the embedding service reported in the instance registry.
+	Version string
+	// DisableRunner only embeds the job service, e.g. to manage jobs executed by separately deployed runners.
+	DisableRunner bool
+	// RelayEvents relays the job lifecycle events written to the outbox to the event listeners, without delivering
+	// them to the webhooks. The manager relays them otherwise, and the outbox grows until it does.
+	RelayEvents bool
+	// Runner settings, the unset intervals and limits default to DefaultRunnerSettings.
+	Runner RunnerSettings
+	// Metrics enables the runner metrics on the global meter provider.
+	Metrics bool
+	// HTTPClient is used by the HTTP executor, a client with a 30s timeout is used if nil. It can't be combined with
+	// the egress policy of the runner, which requires its own client.
+	HTTPClient *http.Client
+	// Executors execute the jobs of their job type instead of the built-in executors, e.g. to publish
+	// AMQP jobs on the connection of the service. Other job types are registered as custom job types.
+	Executors map[JobType]Executor
+}
+
+// Scheduler is an embedded scheduler.
+type Scheduler struct {
+	log    *otelzap.Logger
+	jobs   *job.Service
+	runner *runner.Runner
+	relay  *outbox.Relay
+}
+
+// New creates the scheduler. The runner doesn't execute jobs until Start is called.
+func New(ctx context.Context, cfg Config) (*Scheduler, error) {
+	if cfg.DB == nil && cfg.Store == nil {
+		return nil, fmt.Errorf("a database handle or a storage backend is required")
+	}
+
+	if cfg.Store != nil && cfg.Store.Instances == nil && !cfg.DisableRunner {
+		return nil, fmt.Errorf("the storage backend doesn't store the instance registry of the runner")
+	}
+
+	if cfg.Store == nil || cfg.EncryptionKey != "" {
+		switch len(cfg.EncryptionKey) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("the encryption key must be 16, 24 or 32 bytes long")
+		}
+	}
+
+	for jobType := range cfg.Executors {
+		if jobType == JobTypeHTTP || jobType == JobTypeAMQP {
+			continue
+		}
+
+		if err := model.RegisterJobType(jobType); err != nil {
+			return nil, fmt.Errorf("register job type %s: %w", jobType, err)
+		}
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+	log := otelzap.New(cfg.Logger)
+
+	if cfg.EncryptionKey != "" {
+		codec.SetEncryptor(security.NewEncryptor(cfg.EncryptionKey))
+	}
+
+	backend := cfg.Store
+	if backend == nil {
+		db := sqlx.NewDb(cfg.DB, "postgres")
+		if cfg.Migrate {
+			if err := dbmigrate.Migrate(ctx, db); err != nil {
+				return nil, fmt.Errorf("migrate database: %w", err)
+			}
+		}
+
+		backend = postgres.NewBackend(db, log)
+	}
+
+	s := &Scheduler{
+		log:  log,
+		jobs: job.NewService(backend.Jobs, log),
+	}
+
+	if cfg.RelayEvents {
+		relay, err := outbox.New(outbox.Config{
+			Store:    backend.Jobs,
+			Log:      log,
+			Settings: outbox.Settings{Interval: time.Second, BatchSize: 100},
+		})
+		if err != nil {
+			return nil, err
+		}
+		s.relay = relay
+	}
+
+	if cfg.DisableRunner {
+		return s, nil
+	}
+
+	if err := model.ValidateCapabilities(cfg.Runner.Capabilities); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Runner.ValidateMiddleware(); err != nil {
+		return nil, err
+	}
+
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = uuid.NewString()
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	logSink, err := logsink.New(cfg.Runner.LogShipping, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	jobClient := httpClient
+	if cfg.Runner.Egress.Enabled {
+		if cfg.HTTPClient != nil {
+			return nil, fmt.Errorf("the egress policy can't be applied to a custom HTTP client")
+		}
+
+		if jobClient, err = egress.NewClient(cfg.Runner.Egress, httpClient.Timeout); err != nil {
+			return nil, err
+		}
+	}
+
+	s.runner = runner.New(runner.Config{
+		JobService:      s.jobs,
+		InstanceService: instance.NewService(backend.Instances, log),
+		Metrics:         metrics.NewRunnerMetrics(observability.MetricsConfig{Enabled: cfg.Metrics}),
+		Log:             log,
+		ExecutorFactory: newExecutorFactory(executor.NewFactory(jobClient), cfg.Executors),
+		LogSink:         logSink,
+		ResultPublisher: resultsink.New(jobClient),
+		InstanceId:      cfg.InstanceID,
+		Version:         cfg.Version,
+		JobExecution:    withDefaults(cfg.Runner),
+	})
+
+	return s, nil
+}
+
+// Jobs returns the job service to manage the jobs and their executions.
+func (s *Scheduler) Jobs() *JobService {
+	return s.jobs
+}
+
+// Start starts executing the due jobs, if the runner is enabled, and relaying the events, if enabled.
+func (s *Scheduler) Start() {
+	if s.relay != nil {
+		s.relay.Start()
+	}
+
+	if s.runner == nil {
+		return
+	}
+
+	s.log.Info("Starting the embedded runner")
+	s.runner.Start()
+}
+
+// Stop stops the runner. The running jobs are left to finish for the shutdown grace period of the runner, or until
+// the context is done if it is earlier, before they are interrupted.
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s.runner != nil {
+		s.runner.Stop(ctx)
+	}
+
+	if s.relay != nil {
+		s.relay.Stop(ctx)
+	}
+}
+
+// RegisterExecutor registers a custom job type, whose jobs are executed by the executors created by the constructor
+// with the custom_job definition of the jobs. Job types are registered before creating the scheduler, e.g. from the
+// init function of an executor plugin, and must be registered by the manager validating the jobs as well.
+func RegisterExecutor(jobType JobType, constructor ExecutorConstructor) error {
+	return executor.Register(jobType, constructor)
+}
+
+// RegisterMiddleware registers a custom middleware, which the middleware settings of the runner refer to by its name.
+func RegisterMiddleware(name string, middleware Middleware) error {
+	return executor.RegisterMiddleware(name, middleware)
+}
+
+// ReportProgress reports the progress of the execution running the executor, as a percentage of the work done and
+// an optional message. It is a no-op outside of an execution.
+func ReportProgress(ctx context.Context, percent int, message string) error {
+	return executor.ReportProgress(ctx, percent, message)
+}
+
+// WithNamespace returns a context scoping the calls of the job service to the namespace.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return model.WithNamespace(ctx, namespace)
+}
+
+func withDefaults(settings RunnerSettings) RunnerSettings {
+	if settings.Interval <= 0 {
+		settings.Interval = DefaultRunnerSettings.Interval
+	}
+
+	if settings.MaxConcurrentJobs <= 0 {
+		settings.MaxConcurrentJobs = DefaultRunnerSettings.MaxConcurrentJobs
+	}
+
+	if settings.MaxJobLockTime <= 0 {
+		settings.MaxJobLockTime = DefaultRunnerSettings.MaxJobLockTime
+	}
+
+	if settings.CancellationPollInterval <= 0 {
+		settings.CancellationPollInterval = DefaultRunnerSettings.CancellationPollInterval
+	}
+
+	if settings.MaxExecutionLogSize <= 0 {
+		settings.MaxExecutionLogSize = DefaultRunnerSettings.MaxExecutionLogSize
+	}
+
+	if settings.MaxExecutionOutputSize <= 0 {
+		settings.MaxExecutionOutputSize = DefaultRunnerSettings.MaxExecutionOutputSize
+	}
+
+	if settings.MaxLabelledJobs <= 0 {
+		settings.MaxLabelledJobs = DefaultRunnerSettings.MaxLabelledJobs
+	}
+
+	if settings.HeartbeatInterval <= 0 {
+		settings.HeartbeatInterval = DefaultRunnerSettings.HeartbeatInterval
+	}
+
+	if settings.ShutdownGracePeriod <= 0 {
+		settings.ShutdownGracePeriod = DefaultRunnerSettings.ShutdownGracePeriod
+	}
+
+	return settings
+}
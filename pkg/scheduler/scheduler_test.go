@@ -0,0 +1,107 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/executor"
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/pkg/egress"
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), Config{EncryptionKey: "0123456789abcdef"})
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), Config{DB: &sql.DB{}, EncryptionKey: "too short"})
+	assert.Error(t, err)
+}
+
+func TestNewSQLite(t *testing.T) {
+	ctx := context.Background()
+
+	backend, err := store.Open(ctx, "sqlite", store.Config{DSN: filepath.Join(t.TempDir(), "scheduler.db")})
+	if err != nil {
+		t.Fatalf("Should be able to open the SQLite backend: %s", err)
+	}
+	defer backend.Close()
+
+	s, err := New(ctx, Config{Store: backend, DisableRunner: true})
+	if err != nil {
+		t.Fatalf("Should be able to create the scheduler: %s", err)
+	}
+
+	created, err := s.Jobs().CreateJob(ctx, &JobCreate{
+		Type:         JobTypeHTTP,
+		CronSchedule: null.StringFrom("@every 1m"),
+		HTTPJob:      &model.HTTPJob{URL: "https://example.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	job, err := s.Jobs().GetJob(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, created.HTTPJob.URL, job.HTTPJob.URL)
+}
+
+func TestExecutorFactory(t *testing.T) {
+	executed := false
+	factory := newExecutorFactory(executor.NewFactory(&http.Client{}), map[JobType]Executor{
+		JobTypeAMQP: ExecutorFunc(func(ctx context.Context, job *Job) error {
+			executed = true
+			return nil
+		}),
+	})
+
+	exec, err := factory.NewExecutor(&Job{Type: JobTypeAMQP})
+	assert.NoError(t, err)
+	assert.NoError(t, exec.Execute(context.Background(), &Job{Type: JobTypeAMQP}))
+	assert.True(t, executed)
+
+	// the built-in executors run the other job types
+	exec, err = factory.NewExecutor(&Job{Type: JobTypeHTTP})
+	assert.NoError(t, err)
+	assert.NotNil(t, exec)
+	_, isFunc := exec.(ExecutorFunc)
+	assert.False(t, isFunc)
+
+	_, err = factory.NewExecutor(&Job{Type: "unknown"})
+	assert.Error(t, err)
+
+	// options wrap the executors of the service too
+	wrapped := false
+	_, err = factory.NewExecutor(&Job{Type: JobTypeAMQP}, func(e executor.Executor) executor.Executor {
+		wrapped = true
+		return e
+	})
+	assert.NoError(t, err)
+	assert.True(t, wrapped)
+}
+
+func TestWithDefaults(t *testing.T) {
+	settings := withDefaults(RunnerSettings{Interval: time.Second, Namespaces: []string{"team-a"}})
+	assert.Equal(t, time.Second, settings.Interval)
+	assert.Equal(t, []string{"team-a"}, settings.Namespaces)
+	assert.Equal(t, DefaultRunnerSettings.MaxConcurrentJobs, settings.MaxConcurrentJobs)
+	assert.Equal(t, DefaultRunnerSettings.MaxJobLockTime, settings.MaxJobLockTime)
+	assert.Equal(t, DefaultRunnerSettings.HeartbeatInterval, settings.HeartbeatInterval)
+	assert.Equal(t, DefaultRunnerSettings.ShutdownGracePeriod, settings.ShutdownGracePeriod)
+}
+
+func TestNewEgressWithCustomClient(t *testing.T) {
+	_, err := New(context.Background(), Config{
+		DB:            &sql.DB{},
+		EncryptionKey: "0123456789abcdef",
+		Runner:        RunnerSettings{Egress: egress.Settings{Enabled: true}},
+		HTTPClient:    &http.Client{},
+	})
+	assert.ErrorContains(t, err, "egress policy")
+}
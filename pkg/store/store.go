@@ -0,0 +1,183 @@
+// Package store is the storage contract of the scheduler, and the registry of the storage backends implementing it.
+//
+// Backends register a driver from the init function of their package, like database/sql drivers, and are opened by
+// the name they registered with:
+//
+//	import _ "example.com/scheduler-mongo"
+//
+//	backend, err := store.Open(ctx, "mongo", store.Config{DSN: "mongodb://localhost:27017/scheduler"})
+//
+// The Postgres backend is registered as "postgres" and the SQLite backend as "sqlite", which opens the database
+// file its DSN is the path of.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/model"
+	"github.com/TimeSnap/distributed-scheduler/internal/store"
+	"go.uber.org/zap"
+)
+
+type (
+	// Store stores the jobs, their executions and the other resources of the namespaces.
+	Store = store.Storer
+	// InstanceStore stores the instance registry of the runners.
+	InstanceStore = store.InstanceStorer
+	// WebhookStore stores the webhooks and their deliveries.
+	WebhookStore = store.WebhookStorer
+	// NotificationStore stores the notification rules and their notifications.
+	NotificationStore = store.NotificationStorer
+	// APIKeyStore stores the API keys of the Management API.
+	APIKeyStore = store.APIKeyStorer
+	// ExecutionPartitioner is optionally implemented by the Store to manage the monthly partitions of the executions.
+	ExecutionPartitioner = store.ExecutionPartitioner
+	// NamespaceKeyRotator is optionally implemented by the Store to encrypt the credentials again after a key rotation.
+	NamespaceKeyRotator = store.NamespaceKeyRotator
+)
+
+// Types of the storage contract.
+type (
+	APIKey               = model.APIKey
+	Backlog              = model.Backlog
+	Cursor               = model.Cursor
+	Event                = model.Event
+	ExecutionExportRange = model.ExecutionExportRange
+	ExecutionLogs        = model.ExecutionLogs
+	ExecutionOutput      = model.ExecutionOutput
+	ExecutionEvent       = model.ExecutionEvent
+	ExecutionProgress    = model.ExecutionProgress
+	ExecutionRetry       = model.ExecutionRetry
+	Instance             = model.Instance
+	Job                  = model.Job
+	JobCancellation      = model.JobCancellation
+	JobExecution         = model.JobExecution
+	JobExecutionStats    = model.JobExecutionStats
+	JobExecutionStatus   = model.JobExecutionStatus
+	JobFilter            = model.JobFilter
+	JobSelector          = model.JobSelector
+	JobShard             = model.JobShard
+	JobStatus            = model.JobStatus
+	JobTemplate          = model.JobTemplate
+	JobWakeup            = model.JobWakeup
+	Namespace            = model.Namespace
+	NamespaceQuotas      = model.NamespaceQuotas
+	Notification         = model.Notification
+	NotificationRule     = model.NotificationRule
+	NotificationState    = model.NotificationState
+	TagCount             = model.TagCount
+	Webhook              = model.Webhook
+	WebhookDelivery      = model.WebhookDelivery
+	ZombieExecution      = model.ZombieExecution
+)
+
+// Backend is a storage backend opened by a driver.
+type Backend struct {
+	Jobs          Store
+	Instances     InstanceStore
+	Webhooks      WebhookStore
+	Notifications NotificationStore
+	APIKeys       APIKeyStore
+	// Closer releases the resources opened by the driver, nil if there are none.
+	Closer func() error
+}
+
+// Close releases the resources opened by the driver.
+func (b *Backend) Close() error {
+	if b.Closer == nil {
+		return nil
+	}
+
+	return b.Closer()
+}
+
+// Config configures the backend opened by a driver.
+type Config struct {
+	// DB is an open database handle, used by SQL backends instead of connecting with the DSN. It is owned by the
+	// caller and not closed with the backend.
+	DB *sql.DB
+	// DSN locates the database of the backend.
+	DSN string
+	// Logger of the backend, logging is disabled if nil.
+	Logger *zap.Logger
+	// Options are the driver-specific settings of the backend.
+	Options map[string]string
+}
+
+// Driver opens the backends of a storage engine.
+type Driver interface {
+	Open(ctx context.Context, cfg Config) (*Backend, error)
+}
+
+// DriverFunc adapts a function to a Driver.
+type DriverFunc func(ctx context.Context, cfg Config) (*Backend, error)
+
+func (f DriverFunc) Open(ctx context.Context, cfg Config) (*Backend, error) {
+	return f(ctx, cfg)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{}
+)
+
+// Register makes a driver available by the name. It panics if the driver is nil or a driver is already registered
+// with the name.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if driver == nil {
+		panic("store: register driver is nil")
+	}
+
+	if _, ok := drivers[name]; ok {
+		panic("store: register called twice for driver " + name)
+	}
+
+	drivers[name] = driver
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Open opens a backend with the driver registered by the name. Backends must at least store the jobs.
+func Open(ctx context.Context, name string, cfg Config) (*Backend, error) {
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("store: unknown driver %q (forgotten import?)", name)
+	}
+
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+
+	backend, err := driver.Open(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("store: open %s backend: %w", name, err)
+	}
+
+	if backend == nil || backend.Jobs == nil {
+		return nil, fmt.Errorf("store: the %s backend doesn't store jobs", name)
+	}
+
+	return backend, nil
+}
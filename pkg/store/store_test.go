@@ -0,0 +1,61 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TimeSnap/distributed-scheduler/internal/store/postgres"
+	"github.com/TimeSnap/distributed-scheduler/pkg/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	var opened store.Config
+	store.Register("test", store.DriverFunc(func(_ context.Context, cfg store.Config) (*store.Backend, error) {
+		opened = cfg
+		return &store.Backend{Jobs: postgres.NewBackend(nil, nil).Jobs}, nil
+	}))
+	store.Register("empty", store.DriverFunc(func(_ context.Context, _ store.Config) (*store.Backend, error) {
+		return &store.Backend{}, nil
+	}))
+
+	assert.Contains(t, store.Drivers(), "test")
+	assert.Contains(t, store.Drivers(), postgres.DriverName)
+
+	backend, err := store.Open(context.Background(), "test", store.Config{DSN: "test://", Options: map[string]string{"a": "b"}})
+	assert.NoError(t, err)
+	assert.NotNil(t, backend.Jobs)
+	assert.Equal(t, "test://", opened.DSN)
+	assert.Equal(t, map[string]string{"a": "b"}, opened.Options)
+	assert.NotNil(t, opened.Logger)
+
+	_, err = store.Open(context.Background(), "empty", store.Config{})
+	assert.ErrorContains(t, err, "doesn't store jobs")
+
+	_, err = store.Open(context.Background(), "unknown", store.Config{})
+	assert.ErrorContains(t, err, `unknown driver "unknown"`)
+
+	// Drivers can't be registered twice
+	assert.Panics(t, func() {
+		store.Register("test", store.DriverFunc(func(_ context.Context, _ store.Config) (*store.Backend, error) {
+			return nil, nil
+		}))
+	})
+}
+
+func TestOpenPostgres(t *testing.T) {
+	_, err := store.Open(context.Background(), postgres.DriverName, store.Config{})
+	assert.ErrorContains(t, err, "a database handle or DSN is required")
+}
+
+func TestBackendClose(t *testing.T) {
+	assert.NoError(t, (&store.Backend{}).Close())
+
+	closed := false
+	backend := &store.Backend{Closer: func() error {
+		closed = true
+		return nil
+	}}
+	assert.NoError(t, backend.Close())
+	assert.True(t, closed)
+}